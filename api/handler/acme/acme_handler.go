@@ -0,0 +1,260 @@
+package acme
+
+import (
+	"domain-admin/internal/repository"
+	"domain-admin/internal/service"
+	"domain-admin/model"
+	"domain-admin/pkg/config"
+	"domain-admin/pkg/db"
+	"domain-admin/pkg/logger"
+	"domain-admin/pkg/pagination"
+	"domain-admin/pkg/response"
+	"domain-admin/pkg/validator"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AcmeHandler ACME证书处理器
+type AcmeHandler struct {
+	acmeService service.AcmeService
+}
+
+// NewAcmeHandler 创建ACME证书处理器
+func NewAcmeHandler() *AcmeHandler {
+	acmeRepo := repository.NewAcmeRepository(db.GetDB("default"))
+	return &AcmeHandler{
+		acmeService: service.NewAcmeService(acmeRepo, config.GetConfig().ACME, config.GetConfig().Vault),
+	}
+}
+
+// CreateCertificate 申请证书
+// @Summary 申请证书
+// @Description 通过ACME DNS-01验证申请证书，签发在后台进行，可通过证书详情查看进度
+// @Tags ACME证书
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.AcmeCertificateCreateRequest true "证书信息"
+// @Success 200 {object} response.Response{data=model.AcmeCertificate}
+// @Failure 400 {object} response.Response
+// @Router /api/acme/certificates [post]
+func (h *AcmeHandler) CreateCertificate(c *gin.Context) {
+	var req model.AcmeCertificateCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Warnf("参数绑定失败: %v", err)
+		response.Error(c, http.StatusBadRequest, "参数格式错误")
+		return
+	}
+
+	// 参数验证
+	if err := validator.ValidateStruct(&req); err != nil {
+		logger.Warnf("参数验证失败: %v", err)
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	cert, err := h.acmeService.Create(&req)
+	if err != nil {
+		logger.Errorf("申请证书失败: %v", err)
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, cert)
+}
+
+// GetCertificate 获取证书详情
+// @Summary 获取证书详情
+// @Description 根据ID获取证书的签发状态，不包含证书和私钥内容
+// @Tags ACME证书
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "证书ID"
+// @Success 200 {object} response.Response{data=model.AcmeCertificate}
+// @Failure 404 {object} response.Response
+// @Router /api/acme/certificates/{id} [get]
+func (h *AcmeHandler) GetCertificate(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的证书ID")
+		return
+	}
+
+	cert, err := h.acmeService.GetByID(uint(id))
+	if err != nil {
+		response.Error(c, http.StatusNotFound, "证书不存在")
+		return
+	}
+
+	response.Success(c, cert)
+}
+
+// UpdateCertificate 更新证书设置
+// @Summary 更新证书设置
+// @Description 更新证书的云服务商账号和自动续期设置
+// @Tags ACME证书
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "证书ID"
+// @Param request body model.AcmeCertificateUpdateRequest true "更新信息"
+// @Success 200 {object} response.Response{data=model.AcmeCertificate}
+// @Failure 400 {object} response.Response
+// @Router /api/acme/certificates/{id} [put]
+func (h *AcmeHandler) UpdateCertificate(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的证书ID")
+		return
+	}
+
+	var req model.AcmeCertificateUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Warnf("参数绑定失败: %v", err)
+		response.Error(c, http.StatusBadRequest, "参数格式错误")
+		return
+	}
+
+	// 参数验证
+	if err := validator.ValidateStruct(&req); err != nil {
+		logger.Warnf("参数验证失败: %v", err)
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	cert, err := h.acmeService.Update(uint(id), &req)
+	if err != nil {
+		logger.Errorf("更新证书设置失败: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, cert)
+}
+
+// DeleteCertificate 删除证书
+// @Summary 删除证书
+// @Description 删除证书及加密保存的私钥
+// @Tags ACME证书
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "证书ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Router /api/acme/certificates/{id} [delete]
+func (h *AcmeHandler) DeleteCertificate(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的证书ID")
+		return
+	}
+
+	if err := h.acmeService.Delete(uint(id)); err != nil {
+		logger.Errorf("删除证书失败: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// ListCertificates 获取证书列表
+// @Summary 获取证书列表
+// @Description 分页获取ACME证书列表，可按状态过滤
+// @Tags ACME证书
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "证书状态(pending,issuing,issued,failed)"
+// @Param offset query int false "偏移量" default(0)
+// @Param limit query int false "每页数量" default(10)
+// @Param orderBy query string false "排序字段" default(id)
+// @Param sort query string false "排序方式" default(asc)
+// @Success 200 {object} response.Response{data=pagination.PageResult}
+// @Failure 500 {object} response.Response
+// @Router /api/acme/certificates [get]
+func (h *AcmeHandler) ListCertificates(c *gin.Context) {
+	page := pagination.New(c)
+
+	certs, total, err := h.acmeService.List(c.Query("status"), page)
+	if err != nil {
+		logger.Errorf("获取证书列表失败: %v", err)
+		response.Error(c, http.StatusInternalServerError, "获取证书列表失败")
+		return
+	}
+
+	result := pagination.NewPageResult(total, certs)
+	response.Success(c, result)
+}
+
+// RenewCertificate 续期证书
+// @Summary 续期证书
+// @Description 立即在后台重新签发证书
+// @Tags ACME证书
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "证书ID"
+// @Success 200 {object} response.Response{data=model.AcmeCertificate}
+// @Failure 400 {object} response.Response
+// @Router /api/acme/certificates/{id}/renew [post]
+func (h *AcmeHandler) RenewCertificate(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的证书ID")
+		return
+	}
+
+	cert, err := h.acmeService.Renew(uint(id))
+	if err != nil {
+		logger.Warnf("续期证书失败: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, cert)
+}
+
+// DownloadCertificate 下载证书
+// @Summary 下载证书
+// @Description 获取证书链和解密后的私钥
+// @Tags ACME证书
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "证书ID"
+// @Success 200 {object} response.Response{data=model.AcmeCertificateBundle}
+// @Failure 400 {object} response.Response
+// @Router /api/acme/certificates/{id}/download [get]
+func (h *AcmeHandler) DownloadCertificate(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的证书ID")
+		return
+	}
+
+	bundle, err := h.acmeService.Download(uint(id))
+	if err != nil {
+		logger.Warnf("下载证书失败: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	uid, _ := c.Get("userID")
+	logger.Infof("用户 %v 下载了证书 %s", uid, bundle.CommonName)
+	response.Success(c, bundle)
+}
+
+// respondError 证书不存在时返回404，其余返回400
+func respondError(c *gin.Context, err error) {
+	if strings.Contains(err.Error(), "证书不存在") {
+		response.Error(c, http.StatusNotFound, err.Error())
+		return
+	}
+	response.Error(c, http.StatusBadRequest, err.Error())
+}
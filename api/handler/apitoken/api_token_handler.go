@@ -0,0 +1,146 @@
+package apitoken
+
+import (
+	"domain-admin/internal/repository"
+	"domain-admin/internal/service"
+	"domain-admin/model"
+	"domain-admin/pkg/db"
+	"domain-admin/pkg/logger"
+	"domain-admin/pkg/middleware"
+	"domain-admin/pkg/response"
+	"domain-admin/pkg/validator"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APITokenHandler API令牌处理器
+type APITokenHandler struct {
+	tokenService service.APITokenService
+}
+
+// NewAPITokenHandler 创建API令牌处理器
+func NewAPITokenHandler() *APITokenHandler {
+	database := db.GetDB("default")
+	return &APITokenHandler{
+		tokenService: service.NewAPITokenService(
+			repository.NewAPITokenRepository(database),
+			repository.NewUserRepository(database),
+			repository.NewRoleRepository(database),
+			repository.NewPermissionRepository(database),
+		),
+	}
+}
+
+// Authenticate 校验API令牌，作为 middleware.TokenAuthenticator 使用
+func (h *APITokenHandler) Authenticate(token, clientIP string) (*middleware.TokenIdentity, error) {
+	return h.tokenService.Authenticate(token, clientIP)
+}
+
+// Load 按ID加载API令牌，作为 middleware.TokenLoader 使用
+func (h *APITokenHandler) Load(tokenID uint) (*middleware.TokenIdentity, error) {
+	return h.tokenService.Load(tokenID)
+}
+
+// CreateToken 创建API令牌
+// @Summary 创建API令牌
+// @Description 为当前用户创建有有效期的API令牌，权限必须在当前用户的权限范围内。令牌明文只在本次响应中返回，请求头使用 Authorization: Bearer <token>。不能使用API令牌创建新令牌
+// @Tags API令牌
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.APITokenCreateRequest true "令牌信息"
+// @Success 200 {object} response.Response{data=model.APITokenCreateResult}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /api/tokens [post]
+func (h *APITokenHandler) CreateToken(c *gin.Context) {
+	if _, ok := middleware.GetTokenIdentity(c); ok {
+		response.Error(c, http.StatusForbidden, "不能使用API令牌创建新的令牌")
+		return
+	}
+
+	var req model.APITokenCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Warnf("参数绑定失败: %v", err)
+		response.Error(c, http.StatusBadRequest, "参数格式错误")
+		return
+	}
+
+	// 参数验证
+	if err := validator.ValidateStruct(&req); err != nil {
+		logger.Warnf("参数验证失败: %v", err)
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := h.tokenService.Create(currentUserID(c), &req)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// ListTokens 获取API令牌列表
+// @Summary 获取API令牌列表
+// @Description 获取当前用户的API令牌，包含已过期和已吊销的令牌，不返回令牌明文
+// @Tags API令牌
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]model.APIToken}
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/tokens [get]
+func (h *APITokenHandler) ListTokens(c *gin.Context) {
+	tokens, err := h.tokenService.List(currentUserID(c))
+	if err != nil {
+		logger.Errorf("获取API令牌列表失败: %v", err)
+		response.Error(c, http.StatusInternalServerError, "获取API令牌列表失败")
+		return
+	}
+
+	response.Success(c, tokens)
+}
+
+// RevokeToken 吊销API令牌
+// @Summary 吊销API令牌
+// @Description 吊销当前用户的API令牌，吊销后立即失效
+// @Tags API令牌
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "令牌ID"
+// @Success 200 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/tokens/{id} [delete]
+func (h *APITokenHandler) RevokeToken(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的令牌ID")
+		return
+	}
+
+	if err := h.tokenService.Revoke(currentUserID(c), uint(id)); err != nil {
+		if strings.Contains(err.Error(), "API令牌不存在") {
+			response.Error(c, http.StatusNotFound, err.Error())
+		} else {
+			response.Error(c, http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// currentUserID 返回当前登录用户的ID
+func currentUserID(c *gin.Context) uint {
+	uid, _ := c.Get("userID")
+	userID, _ := uid.(uint)
+	return userID
+}
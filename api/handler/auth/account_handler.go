@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"domain-admin/model"
+	"domain-admin/pkg/logger"
+	"domain-admin/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ForgotPassword 忘记密码
+// @Summary 忘记密码
+// @Description 向邮箱发送重置密码链接（<account.base_url>/reset-password?token=...）。无论邮箱是否注册都返回成功，同一用户一分钟内只发送一次
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body model.ForgotPasswordRequest true "邮箱"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Router /api/auth/forgot-password [post]
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req model.ForgotPasswordRequest
+	if !bindTwoFactorRequest(c, &req) {
+		return
+	}
+
+	if err := h.accountService.ForgotPassword(req.Email); err != nil {
+		logger.Errorf("发送重置密码邮件失败: %v", err)
+		response.Error(c, 500, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{"message": "如果该邮箱已注册，重置密码邮件已发送"})
+}
+
+// ResetPassword 重置密码
+// @Summary 重置密码
+// @Description 使用重置密码邮件中的令牌设置新密码。令牌只能使用一次，重置后该用户已登录的会话全部失效
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body model.ResetPasswordRequest true "令牌和新密码"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Router /api/auth/reset-password [post]
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req model.ResetPasswordRequest
+	if !bindTwoFactorRequest(c, &req) {
+		return
+	}
+
+	if err := h.accountService.ResetPassword(&req); err != nil {
+		logger.Warnf("重置密码失败: %v", err)
+		response.Error(c, 400, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{"message": "密码已重置，请使用新密码登录"})
+}
+
+// VerifyEmail 验证邮箱
+// @Summary 验证邮箱
+// @Description 使用验证邮件中的令牌完成邮箱验证，令牌只能使用一次
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body model.VerifyEmailRequest true "令牌"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Router /api/auth/verify-email [post]
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req model.VerifyEmailRequest
+	if !bindTwoFactorRequest(c, &req) {
+		return
+	}
+
+	if err := h.accountService.VerifyEmail(req.Token); err != nil {
+		logger.Warnf("验证邮箱失败: %v", err)
+		response.Error(c, 400, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{"message": "邮箱验证成功"})
+}
+
+// ResendVerification 重新发送验证邮件
+// @Summary 重新发送验证邮件
+// @Description 向未验证的邮箱重新发送验证邮件，之前的验证链接随即失效。无论邮箱是否注册都返回成功，同一用户一分钟内只发送一次
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body model.ResendVerificationRequest true "邮箱"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Router /api/auth/verify-email/resend [post]
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	var req model.ResendVerificationRequest
+	if !bindTwoFactorRequest(c, &req) {
+		return
+	}
+
+	if err := h.accountService.ResendVerification(req.Email); err != nil {
+		logger.Errorf("发送验证邮件失败: %v", err)
+		response.Error(c, 500, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{"message": "如果该邮箱已注册且未验证，验证邮件已发送"})
+}
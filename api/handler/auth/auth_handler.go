@@ -1,32 +1,91 @@
 package auth
 
 import (
+	"context"
+	"domain-admin/internal/repository"
 	"domain-admin/internal/service"
 	"domain-admin/model"
+	"domain-admin/pkg/config"
 	"domain-admin/pkg/db"
 	"domain-admin/pkg/logger"
+	"domain-admin/pkg/middleware"
 	"domain-admin/pkg/response"
 	"domain-admin/pkg/validator"
+	"errors"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
 // AuthHandler 认证处理器
 type AuthHandler struct {
-	userService service.UserService
+	userService      service.UserService
+	tokenService     service.AuthTokenService
+	twoFactorService service.TwoFactorService
+	webAuthnService  service.WebAuthnService
+	oidcService      service.OIDCService
+	accountService   service.AccountService
 }
 
 // NewAuthHandler 创建认证处理器
 func NewAuthHandler() *AuthHandler {
-	userService := service.NewUserService(db.GetDB("default"))
+	database := db.GetDB("default")
+	ldapService := service.NewLDAPService(
+		repository.NewUserIdentityRepository(database),
+		repository.NewUserRepository(database),
+		repository.NewRoleRepository(database),
+		config.GetConfig().LDAP,
+	)
+	userService := service.NewUserService(database, ldapService)
+	tokenService := service.NewAuthTokenService(
+		repository.NewUserSessionRepository(database),
+		repository.NewRefreshTokenRepository(database),
+		repository.NewUserRepository(database),
+		config.GetConfig().JWT.RefreshTTL(),
+	)
+	twoFactorService := service.NewTwoFactorService(
+		repository.NewTwoFactorRepository(database),
+		repository.NewWebAuthnRepository(database),
+		repository.NewUserRepository(database),
+		repository.NewRoleRepository(database),
+		config.GetConfig().Vault,
+	)
+	webAuthnService := service.NewWebAuthnService(
+		repository.NewWebAuthnRepository(database),
+		repository.NewTwoFactorRepository(database),
+		repository.NewUserRepository(database),
+		repository.NewRoleRepository(database),
+		config.GetConfig().WebAuthn,
+	)
+	oidcService := service.NewOIDCService(
+		repository.NewOIDCRepository(database),
+		repository.NewUserIdentityRepository(database),
+		repository.NewUserRepository(database),
+		repository.NewRoleRepository(database),
+		config.GetConfig().OIDC,
+	)
+	accountService := service.NewAccountService(
+		repository.NewUserRepository(database),
+		repository.NewUserTokenRepository(database),
+		repository.NewUserIdentityRepository(database),
+		tokenService,
+		config.GetConfig().Mail,
+		config.GetConfig().Account,
+	)
 	return &AuthHandler{
-		userService: userService,
+		userService:      userService,
+		tokenService:     tokenService,
+		twoFactorService: twoFactorService,
+		webAuthnService:  webAuthnService,
+		oidcService:      oidcService,
+		accountService:   accountService,
 	}
 }
 
 // Register 用户注册
 // @Summary 用户注册
-// @Description 用户注册接口
+// @Description 用户注册接口，注册后向邮箱发送验证邮件。配置要求验证邮箱时，完成验证后才能登录
 // @Tags 认证
 // @Accept json
 // @Produce json
@@ -56,18 +115,26 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
+	// 验证邮件发送失败不影响注册，用户可重新发送
+	if err := h.accountService.SendVerification(user.ID); err != nil {
+		logger.Warnf("发送验证邮件失败: %v", err)
+	}
+
 	response.Success(c, user)
 }
 
 // Login 用户登录
 // @Summary 用户登录
-// @Description 用户登录接口
+// @Description 用户登录接口，为本次登录创建会话，返回短期有效的访问令牌 token 和用于换取新令牌的 refresh_token，expires_in 为访问令牌的有效秒数。
+// @Description 用户启用了两步验证或角色要求两步验证时，只返回 mfa_required、enroll_required 和 challenge_token，需调用 /api/auth/login/2fa 完成登录。
+// @Description 配置要求验证邮箱且用户未验证时返回403
 // @Tags 认证
 // @Accept json
 // @Produce json
 // @Param request body model.UserLoginRequest true "登录信息"
 // @Success 200 {object} response.Response{data=map[string]interface{}}
 // @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Router /api/auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req model.UserLoginRequest
@@ -84,22 +151,99 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	token, user, err := h.userService.Login(&req)
+	user, err := h.userService.Login(&req)
 	if err != nil {
 		logger.Errorf("用户登录失败: %v", err)
 		response.Error(c, 401, err.Error())
 		return
 	}
 
-	response.Success(c, gin.H{
-		"token": token,
-		"user":  user,
-	})
+	h.finishLogin(c, user)
+}
+
+// finishLogin 密码登录和单点登录认证通过后的统一处理
+func (h *AuthHandler) finishLogin(c *gin.Context, user *model.UserResponse) {
+	// 要求验证邮箱时，未验证的用户需先通过验证邮件完成验证
+	if err := h.accountService.CheckLogin(user); err != nil {
+		response.Error(c, 403, err.Error())
+		return
+	}
+
+	// 启用或角色要求两步验证时返回登录挑战，由 /api/auth/login/2fa 完成登录
+	challenge, err := h.twoFactorService.BeginLogin(user)
+	if err != nil {
+		response.Error(c, 500, err.Error())
+		return
+	}
+	if challenge != nil {
+		response.Success(c, challenge)
+		return
+	}
+
+	h.startSession(c, user, nil)
+}
+
+// startSession 创建登录会话并返回令牌和用户信息，recoveryCodes 为刚绑定两步验证时生成的恢复码
+func (h *AuthHandler) startSession(c *gin.Context, user *model.UserResponse, recoveryCodes []string) {
+	pair, err := h.tokenService.StartSession(user, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		response.Error(c, 500, err.Error())
+		return
+	}
+
+	data := gin.H{
+		"token":         pair.Token,
+		"refresh_token": pair.RefreshToken,
+		"expires_in":    pair.ExpiresIn,
+		"user":          user,
+	}
+	if len(recoveryCodes) > 0 {
+		data["recovery_codes"] = recoveryCodes
+	}
+	response.Success(c, data)
+}
+
+// Refresh 刷新访问令牌
+// @Summary 刷新访问令牌
+// @Description 使用刷新令牌换取新的访问令牌和刷新令牌，旧刷新令牌随即失效。已使用过的刷新令牌再次提交时，该次登录签发的全部刷新令牌都会被吊销
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body model.RefreshTokenRequest true "刷新令牌"
+// @Success 200 {object} response.Response{data=model.TokenPair}
+// @Failure 401 {object} response.Response
+// @Router /api/auth/refresh [post]
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req model.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Warnf("参数绑定失败: %v", err)
+		response.Error(c, 400, "参数格式错误")
+		return
+	}
+
+	// 参数验证
+	if err := validator.ValidateStruct(&req); err != nil {
+		logger.Warnf("参数验证失败: %v", err)
+		response.Error(c, 400, err.Error())
+		return
+	}
+
+	pair, err := h.tokenService.Refresh(req.RefreshToken, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		if errors.Is(err, service.ErrRefreshTokenInvalid) {
+			response.Error(c, 401, err.Error())
+		} else {
+			response.Error(c, 500, err.Error())
+		}
+		return
+	}
+
+	response.Success(c, pair)
 }
 
 // Logout 用户登出
 // @Summary 用户登出
-// @Description 用户登出，清除会话缓存
+// @Description 用户登出，吊销当前会话的访问令牌和刷新令牌，其他设备上的会话不受影响
 // @Tags 认证
 // @Accept json
 // @Produce json
@@ -120,7 +264,13 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 		return
 	}
 
-	if err := h.userService.Logout(uid); err != nil {
+	sessionID := middleware.GetSessionID(c)
+	if sessionID == "" {
+		response.Error(c, 400, "当前请求不属于登录会话")
+		return
+	}
+
+	if err := h.tokenService.Logout(uid, sessionID); err != nil {
 		logger.Errorf("用户登出失败: %v", err)
 		response.Error(c, 500, err.Error())
 		return
@@ -129,6 +279,109 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 	response.Success(c, "登出成功")
 }
 
+// ValidateSession 校验访问令牌所属的会话，作为 middleware.SessionValidator 使用
+func (h *AuthHandler) ValidateSession(ctx context.Context, sessionID, clientIP string) (*middleware.SessionInfo, error) {
+	return h.tokenService.ValidateSession(ctx, sessionID, clientIP)
+}
+
+// ListSessions 获取登录会话列表
+// @Summary 获取登录会话列表
+// @Description 获取当前用户未过期的登录会话，包含设备、IP、User-Agent、创建时间和最近访问时间，current 标记当前请求所属的会话
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]model.UserSession}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /api/auth/sessions [get]
+func (h *AuthHandler) ListSessions(c *gin.Context) {
+	uid, ok := currentUserID(c)
+	if !ok {
+		response.Error(c, 401, "用户未登录")
+		return
+	}
+
+	sessions, err := h.tokenService.ListSessions(uid, middleware.GetSessionID(c))
+	if err != nil {
+		logger.Errorf("获取会话列表失败: %v", err)
+		response.Error(c, 500, "获取会话列表失败")
+		return
+	}
+
+	response.Success(c, sessions)
+}
+
+// RevokeSession 吊销登录会话
+// @Summary 吊销登录会话
+// @Description 吊销当前用户的指定会话，该会话的访问令牌和刷新令牌立即失效
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "会话ID"
+// @Success 200 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /api/auth/sessions/{id} [delete]
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	uid, ok := currentUserID(c)
+	if !ok {
+		response.Error(c, 401, "用户未登录")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, 400, "无效的会话ID")
+		return
+	}
+
+	if err := h.tokenService.RevokeSession(uid, uint(id)); err != nil {
+		if strings.Contains(err.Error(), "会话不存在") {
+			response.Error(c, 404, err.Error())
+		} else {
+			response.Error(c, 500, err.Error())
+		}
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// RevokeOtherSessions 吊销其他登录会话
+// @Summary 吊销其他登录会话
+// @Description 吊销当前用户除当前会话外的全部会话，返回吊销的数量
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=map[string]int}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /api/auth/sessions [delete]
+func (h *AuthHandler) RevokeOtherSessions(c *gin.Context) {
+	uid, ok := currentUserID(c)
+	if !ok {
+		response.Error(c, 401, "用户未登录")
+		return
+	}
+
+	sessionID := middleware.GetSessionID(c)
+	if sessionID == "" {
+		response.Error(c, 400, "当前请求不属于登录会话")
+		return
+	}
+
+	count, err := h.tokenService.RevokeOtherSessions(uid, sessionID)
+	if err != nil {
+		response.Error(c, 500, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{"revoked": count})
+}
+
 // GetProfile 获取用户资料
 // @Summary 获取用户资料
 // @Description 获取当前登录用户的资料
@@ -164,7 +417,7 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 
 // UpdateProfile 更新用户资料
 // @Summary 更新用户资料
-// @Description 更新当前登录用户的资料
+// @Description 更新当前登录用户的资料，需要登录会话，不能使用API令牌
 // @Tags 认证
 // @Accept json
 // @Produce json
@@ -172,6 +425,7 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 // @Param request body model.UserUpdateRequest true "更新信息"
 // @Success 200 {object} response.Response{data=model.UserResponse}
 // @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Router /api/auth/profile [put]
 func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 	userID, exists := c.Get("userID")
@@ -212,4 +466,14 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 	}
 
 	response.Success(c, user)
-}
\ No newline at end of file
+}
+
+// currentUserID 返回当前登录用户的ID
+func currentUserID(c *gin.Context) (uint, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		return 0, false
+	}
+	uid, ok := userID.(uint)
+	return uid, ok
+}
@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"domain-admin/internal/service"
+	"domain-admin/pkg/logger"
+	"domain-admin/pkg/response"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// oidcStateCookie 保存 state 的 Cookie，回调时与查询参数比对，防止登录 CSRF
+	oidcStateCookie = "oidc_state"
+	oidcCookiePath  = "/api/auth/oidc"
+	oidcCookieAge   = 600
+)
+
+// ListOIDCProviders 获取单点登录身份提供方
+// @Summary 获取单点登录身份提供方
+// @Description 返回已配置的身份提供方，前端跳转 login_url 发起登录
+// @Tags 认证
+// @Produce json
+// @Success 200 {object} response.Response{data=[]model.OIDCProviderInfo}
+// @Router /api/auth/oidc/providers [get]
+func (h *AuthHandler) ListOIDCProviders(c *gin.Context) {
+	response.Success(c, h.oidcService.Providers())
+}
+
+// OIDCLogin 发起单点登录
+// @Summary 发起单点登录
+// @Description 生成 state、nonce 和 PKCE 参数后重定向到身份提供方的授权地址
+// @Tags 认证
+// @Param provider path string true "身份提供方名称"
+// @Success 302
+// @Failure 404 {object} response.Response
+// @Failure 502 {object} response.Response
+// @Router /api/auth/oidc/{provider}/login [get]
+func (h *AuthHandler) OIDCLogin(c *gin.Context) {
+	authURL, state, err := h.oidcService.BeginLogin(c.Request.Context(), c.Param("provider"))
+	if err != nil {
+		if errors.Is(err, service.ErrOIDCProviderNotFound) {
+			response.Error(c, 404, err.Error())
+			return
+		}
+		response.Error(c, 502, err.Error())
+		return
+	}
+
+	setOIDCStateCookie(c, state, oidcCookieAge)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// OIDCCallback 单点登录回调
+// @Summary 单点登录回调
+// @Description 身份提供方授权后回调，校验 state 和 ID Token，首次登录自动创建用户。与密码登录相同，未验证邮箱时拒绝登录，需要两步验证时返回登录挑战，由 /api/auth/login/2fa 完成登录，否则返回本系统的令牌
+// @Tags 认证
+// @Produce json
+// @Param provider path string true "身份提供方名称"
+// @Param code query string true "授权码"
+// @Param state query string true "state"
+// @Success 200 {object} response.Response{data=map[string]interface{}}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/auth/oidc/{provider}/callback [get]
+func (h *AuthHandler) OIDCCallback(c *gin.Context) {
+	provider := c.Param("provider")
+	if errCode := c.Query("error"); errCode != "" {
+		logger.Warnf("身份提供方 %s 拒绝授权: %s %s", provider, errCode, c.Query("error_description"))
+		setOIDCStateCookie(c, "", -1)
+		response.Error(c, 401, "身份提供方拒绝了登录请求")
+		return
+	}
+
+	state := c.Query("state")
+	cookie, err := c.Cookie(oidcStateCookie)
+	setOIDCStateCookie(c, "", -1)
+	if err != nil || state == "" || cookie != state {
+		response.Error(c, 401, "登录请求无效或已过期，请重新登录")
+		return
+	}
+
+	user, err := h.oidcService.CompleteLogin(c.Request.Context(), provider, state, c.Query("code"))
+	if err != nil {
+		if errors.Is(err, service.ErrOIDCProviderNotFound) {
+			response.Error(c, 404, err.Error())
+			return
+		}
+		response.Error(c, 401, err.Error())
+		return
+	}
+
+	h.finishLogin(c, user)
+}
+
+// setOIDCStateCookie 写入或清除（maxAge 为负数时）state Cookie
+func setOIDCStateCookie(c *gin.Context, state string, maxAge int) {
+	secure := c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, state, maxAge, oidcCookiePath, "", secure, true)
+}
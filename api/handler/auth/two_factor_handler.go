@@ -0,0 +1,236 @@
+package auth
+
+import (
+	"domain-admin/internal/service"
+	"domain-admin/model"
+	"domain-admin/pkg/logger"
+	"domain-admin/pkg/response"
+	"domain-admin/pkg/validator"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LoginTwoFactor 完成两步验证登录
+// @Summary 完成两步验证登录
+// @Description 使用登录返回的 challenge_token 和验证码（或恢复码）完成登录。enroll_required 为 true 时，先调用 /api/auth/login/2fa/setup 获取密钥，此处的验证码用于确认绑定，响应中额外返回恢复码
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body model.TwoFactorLoginRequest true "挑战令牌和验证码"
+// @Success 200 {object} response.Response{data=map[string]interface{}}
+// @Failure 401 {object} response.Response
+// @Router /api/auth/login/2fa [post]
+func (h *AuthHandler) LoginTwoFactor(c *gin.Context) {
+	var req model.TwoFactorLoginRequest
+	if !bindTwoFactorRequest(c, &req) {
+		return
+	}
+
+	user, recoveryCodes, err := h.twoFactorService.CompleteLogin(&req)
+	if err != nil {
+		logger.Warnf("两步验证登录失败: %v", err)
+		response.Error(c, 401, err.Error())
+		return
+	}
+
+	h.startSession(c, user, recoveryCodes)
+}
+
+// SetupLoginTwoFactor 登录时绑定两步验证
+// @Summary 登录时绑定两步验证
+// @Description 角色要求两步验证但用户尚未绑定时，使用登录返回的 challenge_token 生成TOTP密钥和 otpauth URI
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body model.TwoFactorChallengeRequest true "挑战令牌"
+// @Success 200 {object} response.Response{data=model.TwoFactorSetupResult}
+// @Failure 401 {object} response.Response
+// @Router /api/auth/login/2fa/setup [post]
+func (h *AuthHandler) SetupLoginTwoFactor(c *gin.Context) {
+	var req model.TwoFactorChallengeRequest
+	if !bindTwoFactorRequest(c, &req) {
+		return
+	}
+
+	result, err := h.twoFactorService.SetupWithChallenge(req.ChallengeToken)
+	if err != nil {
+		if errors.Is(err, service.ErrLoginChallengeInvalid) {
+			response.Error(c, 401, err.Error())
+		} else {
+			response.Error(c, 400, err.Error())
+		}
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// GetTwoFactorStatus 获取两步验证状态
+// @Summary 获取两步验证状态
+// @Description 获取当前用户是否启用两步验证、角色是否要求两步验证以及剩余恢复码数量
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=model.TwoFactorStatus}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /api/auth/2fa [get]
+func (h *AuthHandler) GetTwoFactorStatus(c *gin.Context) {
+	uid, ok := currentUserID(c)
+	if !ok {
+		response.Error(c, 401, "用户未登录")
+		return
+	}
+
+	status, err := h.twoFactorService.Status(uid)
+	if err != nil {
+		response.Error(c, 404, err.Error())
+		return
+	}
+
+	response.Success(c, status)
+}
+
+// SetupTwoFactor 绑定两步验证
+// @Summary 绑定两步验证
+// @Description 生成TOTP密钥和 otpauth URI，使用验证器扫码后调用 /api/auth/2fa/confirm 确认。重复调用会生成新密钥
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=model.TwoFactorSetupResult}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /api/auth/2fa/setup [post]
+func (h *AuthHandler) SetupTwoFactor(c *gin.Context) {
+	uid, ok := currentUserID(c)
+	if !ok {
+		response.Error(c, 401, "用户未登录")
+		return
+	}
+
+	result, err := h.twoFactorService.Setup(uid)
+	if err != nil {
+		response.Error(c, 400, err.Error())
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// ConfirmTwoFactor 确认绑定两步验证
+// @Summary 确认绑定两步验证
+// @Description 使用验证器生成的验证码确认绑定并启用两步验证，返回一次性恢复码，恢复码只显示这一次
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.TwoFactorCodeRequest true "验证码"
+// @Success 200 {object} response.Response{data=map[string][]string}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /api/auth/2fa/confirm [post]
+func (h *AuthHandler) ConfirmTwoFactor(c *gin.Context) {
+	uid, ok := currentUserID(c)
+	if !ok {
+		response.Error(c, 401, "用户未登录")
+		return
+	}
+
+	var req model.TwoFactorCodeRequest
+	if !bindTwoFactorRequest(c, &req) {
+		return
+	}
+
+	codes, err := h.twoFactorService.Confirm(uid, req.Code)
+	if err != nil {
+		response.Error(c, 400, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{"recovery_codes": codes})
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码
+// @Summary 重新生成恢复码
+// @Description 使用验证码重新生成恢复码，旧恢复码全部失效
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.TwoFactorCodeRequest true "验证码"
+// @Success 200 {object} response.Response{data=map[string][]string}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /api/auth/2fa/recovery-codes [post]
+func (h *AuthHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	uid, ok := currentUserID(c)
+	if !ok {
+		response.Error(c, 401, "用户未登录")
+		return
+	}
+
+	var req model.TwoFactorCodeRequest
+	if !bindTwoFactorRequest(c, &req) {
+		return
+	}
+
+	codes, err := h.twoFactorService.RegenerateRecoveryCodes(uid, req.Code)
+	if err != nil {
+		response.Error(c, 400, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{"recovery_codes": codes})
+}
+
+// DisableTwoFactor 停用两步验证
+// @Summary 停用两步验证
+// @Description 使用验证码或恢复码停用两步验证，角色要求两步验证时不能停用
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.TwoFactorCodeRequest true "验证码或恢复码"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /api/auth/2fa [delete]
+func (h *AuthHandler) DisableTwoFactor(c *gin.Context) {
+	uid, ok := currentUserID(c)
+	if !ok {
+		response.Error(c, 401, "用户未登录")
+		return
+	}
+
+	var req model.TwoFactorCodeRequest
+	if !bindTwoFactorRequest(c, &req) {
+		return
+	}
+
+	if err := h.twoFactorService.Disable(uid, req.Code); err != nil {
+		response.Error(c, 400, err.Error())
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// bindTwoFactorRequest 绑定并验证请求参数，失败时写入响应并返回 false
+func bindTwoFactorRequest(c *gin.Context, req interface{}) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		logger.Warnf("参数绑定失败: %v", err)
+		response.Error(c, 400, "参数格式错误")
+		return false
+	}
+
+	// 参数验证
+	if err := validator.ValidateStruct(req); err != nil {
+		logger.Warnf("参数验证失败: %v", err)
+		response.Error(c, 400, err.Error())
+		return false
+	}
+	return true
+}
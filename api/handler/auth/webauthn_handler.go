@@ -0,0 +1,274 @@
+package auth
+
+import (
+	"domain-admin/internal/service"
+	"domain-admin/model"
+	"domain-admin/pkg/logger"
+	"domain-admin/pkg/response"
+	"domain-admin/pkg/webauthn"
+	"errors"
+	"io"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// webAuthnRegisterRequest 注册安全密钥请求，credential 为 navigator.credentials.create() 结果的 toJSON()
+type webAuthnRegisterRequest struct {
+	Name       string                       `json:"name" validate:"max=64"`
+	Credential webauthn.AttestationResponse `json:"credential"`
+}
+
+// webAuthnLoginRequest 安全密钥登录请求，credential 为 navigator.credentials.get() 结果的 toJSON()
+type webAuthnLoginRequest struct {
+	Credential webauthn.AssertionResponse `json:"credential"`
+}
+
+// webAuthnTwoFactorRequest 使用安全密钥完成登录第二步的请求
+type webAuthnTwoFactorRequest struct {
+	ChallengeToken string                     `json:"challenge_token" validate:"required"`
+	Credential     webauthn.AssertionResponse `json:"credential"`
+}
+
+// BeginWebAuthnRegistration 开始注册安全密钥
+// @Summary 开始注册安全密钥
+// @Description 返回 PublicKeyCredentialCreationOptions 的JSON形式，前端用 PublicKeyCredential.parseCreationOptionsFromJSON 转换后调用 navigator.credentials.create()
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=webauthn.CreationOptions}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /api/auth/webauthn/register/begin [post]
+func (h *AuthHandler) BeginWebAuthnRegistration(c *gin.Context) {
+	uid, ok := currentUserID(c)
+	if !ok {
+		response.Error(c, 401, "用户未登录")
+		return
+	}
+
+	options, err := h.webAuthnService.BeginRegistration(uid)
+	if err != nil {
+		response.Error(c, 400, err.Error())
+		return
+	}
+
+	response.Success(c, options)
+}
+
+// FinishWebAuthnRegistration 完成注册安全密钥
+// @Summary 完成注册安全密钥
+// @Description 校验认证器返回的注册结果并保存凭据，注册后可用于无密码登录和两步验证
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body webAuthnRegisterRequest true "凭据名称和注册结果"
+// @Success 200 {object} response.Response{data=model.WebAuthnCredential}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /api/auth/webauthn/register/finish [post]
+func (h *AuthHandler) FinishWebAuthnRegistration(c *gin.Context) {
+	uid, ok := currentUserID(c)
+	if !ok {
+		response.Error(c, 401, "用户未登录")
+		return
+	}
+
+	var req webAuthnRegisterRequest
+	if !bindTwoFactorRequest(c, &req) {
+		return
+	}
+
+	credential, err := h.webAuthnService.FinishRegistration(uid, req.Name, &req.Credential)
+	if err != nil {
+		response.Error(c, 400, err.Error())
+		return
+	}
+
+	response.Success(c, credential)
+}
+
+// ListWebAuthnCredentials 获取安全密钥列表
+// @Summary 获取安全密钥列表
+// @Description 获取当前用户注册的安全密钥
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]model.WebAuthnCredential}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /api/auth/webauthn/credentials [get]
+func (h *AuthHandler) ListWebAuthnCredentials(c *gin.Context) {
+	uid, ok := currentUserID(c)
+	if !ok {
+		response.Error(c, 401, "用户未登录")
+		return
+	}
+
+	credentials, err := h.webAuthnService.ListCredentials(uid)
+	if err != nil {
+		logger.Errorf("获取安全密钥列表失败: %v", err)
+		response.Error(c, 500, "获取安全密钥列表失败")
+		return
+	}
+
+	response.Success(c, credentials)
+}
+
+// DeleteWebAuthnCredential 删除安全密钥
+// @Summary 删除安全密钥
+// @Description 删除当前用户的安全密钥，角色要求两步验证时不能删除最后一个验证方式
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "凭据ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /api/auth/webauthn/credentials/{id} [delete]
+func (h *AuthHandler) DeleteWebAuthnCredential(c *gin.Context) {
+	uid, ok := currentUserID(c)
+	if !ok {
+		response.Error(c, 401, "用户未登录")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, 400, "凭据ID格式错误")
+		return
+	}
+
+	if err := h.webAuthnService.DeleteCredential(uid, uint(id)); err != nil {
+		if err.Error() == "凭据不存在" {
+			response.Error(c, 404, err.Error())
+		} else {
+			response.Error(c, 400, err.Error())
+		}
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// BeginWebAuthnLogin 开始安全密钥登录
+// @Summary 开始安全密钥登录
+// @Description 无密码登录的第一步，返回 PublicKeyCredentialRequestOptions 的JSON形式。不传用户名时由认证器选择通行密钥
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body model.WebAuthnLoginBeginRequest false "用户名（可选）"
+// @Success 200 {object} response.Response{data=webauthn.RequestOptions}
+// @Failure 400 {object} response.Response
+// @Router /api/auth/webauthn/login/begin [post]
+func (h *AuthHandler) BeginWebAuthnLogin(c *gin.Context) {
+	var req model.WebAuthnLoginBeginRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		logger.Warnf("参数绑定失败: %v", err)
+		response.Error(c, 400, "参数格式错误")
+		return
+	}
+
+	options, err := h.webAuthnService.BeginLogin(req.Username)
+	if err != nil {
+		response.Error(c, 400, err.Error())
+		return
+	}
+
+	response.Success(c, options)
+}
+
+// FinishWebAuthnLogin 完成安全密钥登录
+// @Summary 完成安全密钥登录
+// @Description 校验认证器返回的认证结果，要求完成用户验证（PIN或生物识别），成功后返回令牌，不再需要两步验证
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body webAuthnLoginRequest true "认证结果"
+// @Success 200 {object} response.Response{data=map[string]interface{}}
+// @Failure 401 {object} response.Response
+// @Router /api/auth/webauthn/login/finish [post]
+func (h *AuthHandler) FinishWebAuthnLogin(c *gin.Context) {
+	var req webAuthnLoginRequest
+	if !bindTwoFactorRequest(c, &req) {
+		return
+	}
+
+	user, err := h.webAuthnService.FinishLogin(&req.Credential)
+	if err != nil {
+		response.Error(c, 401, err.Error())
+		return
+	}
+	// 安全密钥已完成用户验证，只检查邮箱验证
+	if err := h.accountService.CheckLogin(user); err != nil {
+		response.Error(c, 403, err.Error())
+		return
+	}
+
+	h.startSession(c, user, nil)
+}
+
+// BeginWebAuthnTwoFactor 开始安全密钥两步验证
+// @Summary 开始安全密钥两步验证
+// @Description 密码登录返回的 methods 包含 webauthn 时，使用 challenge_token 获取认证选项
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body model.TwoFactorChallengeRequest true "挑战令牌"
+// @Success 200 {object} response.Response{data=webauthn.RequestOptions}
+// @Failure 401 {object} response.Response
+// @Router /api/auth/webauthn/2fa/begin [post]
+func (h *AuthHandler) BeginWebAuthnTwoFactor(c *gin.Context) {
+	var req model.TwoFactorChallengeRequest
+	if !bindTwoFactorRequest(c, &req) {
+		return
+	}
+
+	uid, err := h.twoFactorService.ChallengeUserID(req.ChallengeToken)
+	if err != nil {
+		response.Error(c, 401, err.Error())
+		return
+	}
+
+	options, err := h.webAuthnService.BeginSecondFactor(uid)
+	if err != nil {
+		response.Error(c, 400, err.Error())
+		return
+	}
+
+	response.Success(c, options)
+}
+
+// FinishWebAuthnTwoFactor 完成安全密钥两步验证
+// @Summary 完成安全密钥两步验证
+// @Description 使用 challenge_token 和认证器返回的认证结果完成登录
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body webAuthnTwoFactorRequest true "挑战令牌和认证结果"
+// @Success 200 {object} response.Response{data=map[string]interface{}}
+// @Failure 401 {object} response.Response
+// @Router /api/auth/webauthn/2fa/finish [post]
+func (h *AuthHandler) FinishWebAuthnTwoFactor(c *gin.Context) {
+	var req webAuthnTwoFactorRequest
+	if !bindTwoFactorRequest(c, &req) {
+		return
+	}
+
+	user, err := h.twoFactorService.CompleteLoginWith(req.ChallengeToken, func(userID uint) error {
+		return h.webAuthnService.VerifySecondFactor(userID, &req.Credential)
+	})
+	if err != nil {
+		if !errors.Is(err, service.ErrLoginChallengeInvalid) {
+			logger.Warnf("安全密钥两步验证失败: %v", err)
+		}
+		response.Error(c, 401, err.Error())
+		return
+	}
+
+	h.startSession(c, user, nil)
+}
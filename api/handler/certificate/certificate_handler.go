@@ -0,0 +1,230 @@
+package certificate
+
+import (
+	"domain-admin/internal/repository"
+	"domain-admin/internal/service"
+	"domain-admin/model"
+	"domain-admin/pkg/config"
+	"domain-admin/pkg/db"
+	"domain-admin/pkg/logger"
+	"domain-admin/pkg/pagination"
+	"domain-admin/pkg/response"
+	"domain-admin/pkg/validator"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CertificateHandler 证书处理器
+type CertificateHandler struct {
+	certService service.CertificateService
+}
+
+// NewCertificateHandler 创建证书处理器
+func NewCertificateHandler() *CertificateHandler {
+	certRepo := repository.NewCertificateRepository(db.GetDB("default"))
+	return &CertificateHandler{
+		certService: service.NewCertificateService(certRepo, config.GetConfig().Certificate),
+	}
+}
+
+// CreateCertificate 添加证书探测目标
+// @Summary 添加证书探测目标
+// @Description 添加需要监控的 host:port，添加后立即探测一次
+// @Tags 证书管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.CertificateCreateRequest true "探测目标"
+// @Success 200 {object} response.Response{data=model.Certificate}
+// @Failure 400 {object} response.Response
+// @Router /api/certificates [post]
+func (h *CertificateHandler) CreateCertificate(c *gin.Context) {
+	var req model.CertificateCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Warnf("参数绑定失败: %v", err)
+		response.Error(c, http.StatusBadRequest, "参数格式错误")
+		return
+	}
+
+	// 参数验证
+	if err := validator.ValidateStruct(&req); err != nil {
+		logger.Warnf("参数验证失败: %v", err)
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	cert, err := h.certService.Create(c.Request.Context(), &req)
+	if err != nil {
+		logger.Errorf("添加证书探测目标失败: %v", err)
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, cert)
+}
+
+// GetCertificate 获取证书详情
+// @Summary 获取证书详情
+// @Description 根据ID获取证书及最近一次探测结果
+// @Tags 证书管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "证书ID"
+// @Success 200 {object} response.Response{data=model.Certificate}
+// @Failure 404 {object} response.Response
+// @Router /api/certificates/{id} [get]
+func (h *CertificateHandler) GetCertificate(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的证书ID")
+		return
+	}
+
+	cert, err := h.certService.GetByID(uint(id))
+	if err != nil {
+		response.Error(c, http.StatusNotFound, "证书不存在")
+		return
+	}
+
+	response.Success(c, cert)
+}
+
+// UpdateCertificate 更新证书探测目标
+// @Summary 更新证书探测目标
+// @Description 更新主机、端口或备注，主机或端口变更后重新探测
+// @Tags 证书管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "证书ID"
+// @Param request body model.CertificateUpdateRequest true "更新信息"
+// @Success 200 {object} response.Response{data=model.Certificate}
+// @Failure 400 {object} response.Response
+// @Router /api/certificates/{id} [put]
+func (h *CertificateHandler) UpdateCertificate(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的证书ID")
+		return
+	}
+
+	var req model.CertificateUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Warnf("参数绑定失败: %v", err)
+		response.Error(c, http.StatusBadRequest, "参数格式错误")
+		return
+	}
+
+	// 参数验证
+	if err := validator.ValidateStruct(&req); err != nil {
+		logger.Warnf("参数验证失败: %v", err)
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	cert, err := h.certService.Update(c.Request.Context(), uint(id), &req)
+	if err != nil {
+		logger.Errorf("更新证书探测目标失败: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, cert)
+}
+
+// DeleteCertificate 删除证书探测目标
+// @Summary 删除证书探测目标
+// @Description 删除证书探测目标及其探测结果
+// @Tags 证书管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "证书ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Router /api/certificates/{id} [delete]
+func (h *CertificateHandler) DeleteCertificate(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的证书ID")
+		return
+	}
+
+	if err := h.certService.Delete(uint(id)); err != nil {
+		logger.Errorf("删除证书探测目标失败: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// ListCertificates 获取证书列表
+// @Summary 获取证书列表
+// @Description 分页获取证书列表，可按状态过滤
+// @Tags 证书管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "证书状态(pending,valid,expiring,expired,mismatch,untrusted,error)"
+// @Param offset query int false "偏移量" default(0)
+// @Param limit query int false "每页数量" default(10)
+// @Param orderBy query string false "排序字段" default(id)
+// @Param sort query string false "排序方式" default(asc)
+// @Success 200 {object} response.Response{data=pagination.PageResult}
+// @Failure 500 {object} response.Response
+// @Router /api/certificates [get]
+func (h *CertificateHandler) ListCertificates(c *gin.Context) {
+	page := pagination.New(c)
+
+	certs, total, err := h.certService.List(c.Query("status"), page)
+	if err != nil {
+		logger.Errorf("获取证书列表失败: %v", err)
+		response.Error(c, http.StatusInternalServerError, "获取证书列表失败")
+		return
+	}
+
+	result := pagination.NewPageResult(total, certs)
+	response.Success(c, result)
+}
+
+// ProbeCertificate 立即探测证书
+// @Summary 立即探测证书
+// @Description 立即连接目标并更新证书信息
+// @Tags 证书管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "证书ID"
+// @Success 200 {object} response.Response{data=model.Certificate}
+// @Failure 404 {object} response.Response
+// @Router /api/certificates/{id}/probe [post]
+func (h *CertificateHandler) ProbeCertificate(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的证书ID")
+		return
+	}
+
+	cert, err := h.certService.Probe(c.Request.Context(), uint(id))
+	if err != nil {
+		logger.Errorf("探测证书失败: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, cert)
+}
+
+// respondError 证书不存在时返回404，其余返回400
+func respondError(c *gin.Context, err error) {
+	if strings.Contains(err.Error(), "证书不存在") {
+		response.Error(c, http.StatusNotFound, err.Error())
+		return
+	}
+	response.Error(c, http.StatusBadRequest, err.Error())
+}
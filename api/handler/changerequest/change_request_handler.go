@@ -0,0 +1,241 @@
+package changerequest
+
+import (
+	"context"
+	"domain-admin/internal/repository"
+	"domain-admin/internal/service"
+	"domain-admin/model"
+	"domain-admin/pkg/config"
+	"domain-admin/pkg/db"
+	"domain-admin/pkg/logger"
+	"domain-admin/pkg/middleware"
+	"domain-admin/pkg/pagination"
+	"domain-admin/pkg/response"
+	"domain-admin/pkg/validator"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ChangeRequestHandler 变更请求处理器
+type ChangeRequestHandler struct {
+	changeService service.ChangeRequestService
+}
+
+// NewChangeRequestHandler 创建变更请求处理器
+func NewChangeRequestHandler() *ChangeRequestHandler {
+	changeRepo := repository.NewChangeRequestRepository(db.GetDB("default"))
+	userRepo := repository.NewUserRepository(db.GetDB("default"))
+	return &ChangeRequestHandler{
+		changeService: service.NewChangeRequestService(changeRepo, userRepo, config.GetConfig().Approval.ExpireAfter()),
+	}
+}
+
+// Submit 保存被 ChangeApproval 中间件拦截的请求，作为 middleware.ChangeSubmitter 使用
+func (h *ChangeRequestHandler) Submit(c *gin.Context, change *middleware.PendingChange) {
+	uid, _ := c.Get("userID")
+	userID, _ := uid.(uint)
+	username := c.GetString("username")
+
+	req, err := h.changeService.Submit(change, userID, username)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Accepted(c, "变更已提交，等待审批", req)
+}
+
+// ListChangeRequests 获取变更请求列表
+// @Summary 获取变更请求列表
+// @Description 分页获取变更请求列表，可按状态筛选或只看自己提交的请求
+// @Tags 变更审批
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "状态(pending/approved/executed/failed/rejected/cancelled/expired)"
+// @Param mine query bool false "只看自己提交的请求"
+// @Param offset query int false "偏移量" default(0)
+// @Param limit query int false "每页数量" default(10)
+// @Param orderBy query string false "排序字段" default(id)
+// @Param sort query string false "排序方式" default(asc)
+// @Success 200 {object} response.Response{data=pagination.PageResult}
+// @Failure 500 {object} response.Response
+// @Router /api/change-requests [get]
+func (h *ChangeRequestHandler) ListChangeRequests(c *gin.Context) {
+	page := pagination.New(c)
+
+	var requesterID uint
+	if mine, _ := strconv.ParseBool(c.Query("mine")); mine {
+		uid, _ := c.Get("userID")
+		requesterID, _ = uid.(uint)
+	}
+
+	changes, total, err := h.changeService.List(c.Query("status"), requesterID, page)
+	if err != nil {
+		logger.Errorf("获取变更请求列表失败: %v", err)
+		response.Error(c, http.StatusInternalServerError, "获取变更请求列表失败")
+		return
+	}
+
+	result := pagination.NewPageResult(total, changes)
+	response.Success(c, result)
+}
+
+// GetChangeRequest 获取变更请求详情
+// @Summary 获取变更请求详情
+// @Description 获取变更请求的原始请求、变更前后数据以及审批和执行结果
+// @Tags 变更审批
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "变更请求ID"
+// @Success 200 {object} response.Response{data=model.ChangeRequest}
+// @Failure 404 {object} response.Response
+// @Router /api/change-requests/{id} [get]
+func (h *ChangeRequestHandler) GetChangeRequest(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的变更请求ID")
+		return
+	}
+
+	change, err := h.changeService.GetByID(uint(id))
+	if err != nil {
+		response.Error(c, http.StatusNotFound, "变更请求不存在")
+		return
+	}
+
+	response.Success(c, change)
+}
+
+// ApproveChangeRequest 批准并执行变更请求
+// @Summary 批准变更请求
+// @Description 批准后立即以提交人身份执行原始请求，执行结果见 status 和 result，提交人不能审批自己的请求
+// @Tags 变更审批
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "变更请求ID"
+// @Param request body model.ChangeDecisionRequest false "审批意见"
+// @Success 200 {object} response.Response{data=model.ChangeRequest}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /api/change-requests/{id}/approve [post]
+func (h *ChangeRequestHandler) ApproveChangeRequest(c *gin.Context) {
+	id, req, ok := bindDecision(c)
+	if !ok {
+		return
+	}
+	uid, _ := c.Get("userID")
+	approverID, _ := uid.(uint)
+
+	// 客户端断开时仍需完成执行并保存结果
+	ctx := context.WithoutCancel(c.Request.Context())
+	change, err := h.changeService.Approve(ctx, id, approverID, c.GetString("username"), req.Comment)
+	if err != nil {
+		logger.Warnf("批准变更请求失败: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, change)
+}
+
+// RejectChangeRequest 拒绝变更请求
+// @Summary 拒绝变更请求
+// @Description 拒绝待审批的变更请求，提交人不能审批自己的请求
+// @Tags 变更审批
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "变更请求ID"
+// @Param request body model.ChangeDecisionRequest false "审批意见"
+// @Success 200 {object} response.Response{data=model.ChangeRequest}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /api/change-requests/{id}/reject [post]
+func (h *ChangeRequestHandler) RejectChangeRequest(c *gin.Context) {
+	id, req, ok := bindDecision(c)
+	if !ok {
+		return
+	}
+	uid, _ := c.Get("userID")
+	approverID, _ := uid.(uint)
+
+	change, err := h.changeService.Reject(id, approverID, c.GetString("username"), req.Comment)
+	if err != nil {
+		logger.Warnf("拒绝变更请求失败: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, change)
+}
+
+// CancelChangeRequest 撤回变更请求
+// @Summary 撤回变更请求
+// @Description 提交人撤回自己提交的待审批变更请求
+// @Tags 变更审批
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "变更请求ID"
+// @Success 200 {object} response.Response{data=model.ChangeRequest}
+// @Failure 400 {object} response.Response
+// @Router /api/change-requests/{id} [delete]
+func (h *ChangeRequestHandler) CancelChangeRequest(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的变更请求ID")
+		return
+	}
+	uid, _ := c.Get("userID")
+	userID, _ := uid.(uint)
+
+	change, err := h.changeService.Cancel(uint(id), userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, change)
+}
+
+// bindDecision 解析变更请求ID和审批意见，审批意见可以为空
+func bindDecision(c *gin.Context) (uint, *model.ChangeDecisionRequest, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的变更请求ID")
+		return 0, nil, false
+	}
+
+	var req model.ChangeDecisionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			logger.Warnf("参数绑定失败: %v", err)
+			response.Error(c, http.StatusBadRequest, "参数格式错误")
+			return 0, nil, false
+		}
+	}
+	if err := validator.ValidateStruct(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return 0, nil, false
+	}
+	return uint(id), &req, true
+}
+
+// respondError 自行审批返回403，请求不存在返回404，其余返回400
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrSelfApproval):
+		response.Error(c, http.StatusForbidden, err.Error())
+	case strings.Contains(err.Error(), "变更请求不存在"):
+		response.Error(c, http.StatusNotFound, err.Error())
+	default:
+		response.Error(c, http.StatusBadRequest, err.Error())
+	}
+}
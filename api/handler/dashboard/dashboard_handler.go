@@ -2,6 +2,8 @@ package dashboard
 
 import (
 	"domain-admin/internal/repository"
+	"domain-admin/internal/service"
+	"domain-admin/pkg/config"
 	"domain-admin/pkg/db"
 	"domain-admin/pkg/response"
 	"net/http"
@@ -13,6 +15,7 @@ type DashboardHandler struct {
 	userRepo       repository.UserRepository
 	roleRepo       repository.RoleRepository
 	permissionRepo repository.PermissionRepository
+	certService    service.CertificateService
 }
 
 // NewDashboardHandler 创建仪表盘处理器
@@ -22,15 +25,17 @@ func NewDashboardHandler() *DashboardHandler {
 		userRepo:       repository.NewUserRepository(db),
 		roleRepo:       repository.NewRoleRepository(db),
 		permissionRepo: repository.NewPermissionRepository(db),
+		certService:    service.NewCertificateService(repository.NewCertificateRepository(db), config.GetConfig().Certificate),
 	}
 }
 
 // DashboardStats 仪表盘统计数据结构
 type DashboardStats struct {
-	UserCount       int64 `json:"userCount"`
-	RoleCount       int64 `json:"roleCount"`
-	PermissionCount int64 `json:"permissionCount"`
-	OnlineCount     int64 `json:"onlineCount"`
+	UserCount         int64 `json:"userCount"`
+	RoleCount         int64 `json:"roleCount"`
+	PermissionCount   int64 `json:"permissionCount"`
+	OnlineCount       int64 `json:"onlineCount"`
+	ExpiringCertCount int64 `json:"expiringCertCount"`
 }
 
 // GetStats 获取仪表盘统计数据
@@ -61,6 +66,14 @@ func (h *DashboardHandler) GetStats(c *gin.Context) {
 	}
 	stats.PermissionCount = permissionCount
 
+	// 获取即将到期（含已过期）的证书数
+	expiringCertCount, err := h.certService.CountExpiring()
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取证书统计失败")
+		return
+	}
+	stats.ExpiringCertCount = expiringCertCount
+
 	// 在线用户数（暂时设为固定值，后续可以通过Redis或其他方式实现）
 	stats.OnlineCount = 1 // 当前登录用户至少为1
 
@@ -0,0 +1,205 @@
+package domain
+
+import (
+	"domain-admin/internal/repository"
+	"domain-admin/internal/service"
+	"domain-admin/model"
+	"domain-admin/pkg/db"
+	"domain-admin/pkg/logger"
+	"domain-admin/pkg/pagination"
+	"domain-admin/pkg/response"
+	"domain-admin/pkg/validator"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DomainHandler 域名处理器
+type DomainHandler struct {
+	domainService service.DomainService
+}
+
+// NewDomainHandler 创建域名处理器
+func NewDomainHandler() *DomainHandler {
+	domainRepo := repository.NewDomainRepository(db.GetDB("default"))
+	userRepo := repository.NewUserRepository(db.GetDB("default"))
+	domainService := service.NewDomainService(domainRepo, userRepo)
+
+	return &DomainHandler{
+		domainService: domainService,
+	}
+}
+
+// CreateDomain 创建域名
+// @Summary 创建域名
+// @Description 登记新的域名资产
+// @Tags 域名管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.DomainCreateRequest true "域名信息"
+// @Success 200 {object} response.Response{data=model.Domain}
+// @Failure 400 {object} response.Response
+// @Router /api/domains [post]
+func (h *DomainHandler) CreateDomain(c *gin.Context) {
+	var req model.DomainCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Warnf("参数绑定失败: %v", err)
+		response.Error(c, http.StatusBadRequest, "参数格式错误")
+		return
+	}
+
+	// 参数验证
+	if err := validator.ValidateStruct(&req); err != nil {
+		logger.Warnf("参数验证失败: %v", err)
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	domain, err := h.domainService.Create(&req)
+	if err != nil {
+		logger.Errorf("创建域名失败: %v", err)
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, domain)
+}
+
+// GetDomain 获取域名详情
+// @Summary 获取域名详情
+// @Description 根据ID获取域名详情
+// @Tags 域名管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "域名ID"
+// @Success 200 {object} response.Response{data=model.Domain}
+// @Failure 404 {object} response.Response
+// @Router /api/domains/{id} [get]
+func (h *DomainHandler) GetDomain(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的域名ID")
+		return
+	}
+
+	domain, err := h.domainService.GetByID(uint(id))
+	if err != nil {
+		response.Error(c, http.StatusNotFound, "域名不存在")
+		return
+	}
+
+	response.Success(c, domain)
+}
+
+// UpdateDomain 更新域名
+// @Summary 更新域名
+// @Description 更新域名信息
+// @Tags 域名管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "域名ID"
+// @Param request body model.DomainUpdateRequest true "更新信息"
+// @Success 200 {object} response.Response{data=model.Domain}
+// @Failure 400 {object} response.Response
+// @Router /api/domains/{id} [put]
+func (h *DomainHandler) UpdateDomain(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的域名ID")
+		return
+	}
+
+	var req model.DomainUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Warnf("参数绑定失败: %v", err)
+		response.Error(c, http.StatusBadRequest, "参数格式错误")
+		return
+	}
+
+	// 参数验证
+	if err := validator.ValidateStruct(&req); err != nil {
+		logger.Warnf("参数验证失败: %v", err)
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	domain, err := h.domainService.Update(uint(id), &req)
+	if err != nil {
+		logger.Errorf("更新域名失败: %v", err)
+		if strings.Contains(err.Error(), "域名不存在") {
+			response.Error(c, http.StatusNotFound, err.Error())
+		} else {
+			response.Error(c, http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+
+	response.Success(c, domain)
+}
+
+// DeleteDomain 删除域名
+// @Summary 删除域名
+// @Description 删除域名
+// @Tags 域名管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "域名ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Router /api/domains/{id} [delete]
+func (h *DomainHandler) DeleteDomain(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的域名ID")
+		return
+	}
+
+	if err := h.domainService.Delete(uint(id)); err != nil {
+		logger.Errorf("删除域名失败: %v", err)
+		if strings.Contains(err.Error(), "域名不存在") {
+			response.Error(c, http.StatusNotFound, err.Error())
+		} else {
+			response.Error(c, http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// ListDomains 获取域名列表
+// @Summary 获取域名列表
+// @Description 分页获取域名列表
+// @Tags 域名管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param offset query int false "偏移量" default(0)
+// @Param limit query int false "每页数量" default(10)
+// @Param orderBy query string false "排序字段" default(id)
+// @Param sort query string false "排序方式" default(asc)
+// @Success 200 {object} response.Response{data=pagination.PageResult}
+// @Failure 500 {object} response.Response
+// @Router /api/domains [get]
+func (h *DomainHandler) ListDomains(c *gin.Context) {
+	page := pagination.New(c)
+
+	domains, total, err := h.domainService.List(page)
+	if err != nil {
+		logger.Errorf("获取域名列表失败: %v", err)
+		response.Error(c, http.StatusInternalServerError, "获取域名列表失败")
+		return
+	}
+
+	result := pagination.NewPageResult(total, domains)
+	response.Success(c, result)
+}
@@ -0,0 +1,156 @@
+package job
+
+import (
+	"domain-admin/internal/repository"
+	"domain-admin/internal/service"
+	"domain-admin/pkg/db"
+	"domain-admin/pkg/logger"
+	"domain-admin/pkg/pagination"
+	"domain-admin/pkg/response"
+	"domain-admin/pkg/scheduler"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JobHandler 定时任务处理器
+type JobHandler struct {
+	jobService service.JobService
+}
+
+// NewJobHandler 创建定时任务处理器
+func NewJobHandler() *JobHandler {
+	jobRepo := repository.NewJobRepository(db.GetDB("default"))
+	return &JobHandler{
+		jobService: service.NewJobService(jobRepo),
+	}
+}
+
+// ListJobs 获取任务列表
+// @Summary 获取任务列表
+// @Description 获取全部定时任务及其调度状态
+// @Tags 定时任务
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]scheduler.JobInfo}
+// @Failure 503 {object} response.Response
+// @Router /api/jobs [get]
+func (h *JobHandler) ListJobs(c *gin.Context) {
+	jobs, err := h.jobService.List()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, jobs)
+}
+
+// ListJobRuns 获取任务执行记录
+// @Summary 获取任务执行记录
+// @Description 分页获取指定任务的执行记录
+// @Tags 定时任务
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "任务名称"
+// @Param offset query int false "偏移量" default(0)
+// @Param limit query int false "每页数量" default(10)
+// @Param orderBy query string false "排序字段" default(id)
+// @Param sort query string false "排序方式" default(asc)
+// @Success 200 {object} response.Response{data=pagination.PageResult}
+// @Failure 500 {object} response.Response
+// @Router /api/jobs/{name}/runs [get]
+func (h *JobHandler) ListJobRuns(c *gin.Context) {
+	page := pagination.New(c)
+
+	runs, total, err := h.jobService.ListRuns(c.Param("name"), page)
+	if err != nil {
+		logger.Errorf("获取任务执行记录失败: %v", err)
+		response.Error(c, http.StatusInternalServerError, "获取任务执行记录失败")
+		return
+	}
+
+	result := pagination.NewPageResult(total, runs)
+	response.Success(c, result)
+}
+
+// TriggerJob 手动执行任务
+// @Summary 手动执行任务
+// @Description 立即在后台执行任务，返回本次执行记录
+// @Tags 定时任务
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "任务名称"
+// @Success 200 {object} response.Response{data=model.JobRun}
+// @Failure 409 {object} response.Response
+// @Router /api/jobs/{name}/trigger [post]
+func (h *JobHandler) TriggerJob(c *gin.Context) {
+	uid, _ := c.Get("userID")
+	userID, _ := uid.(uint)
+
+	run, err := h.jobService.Trigger(c.Param("name"), userID)
+	if err != nil {
+		logger.Warnf("手动执行任务失败: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, run)
+}
+
+// PauseJob 暂停任务
+// @Summary 暂停任务
+// @Description 暂停任务的定时调度，所有实例生效
+// @Tags 定时任务
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "任务名称"
+// @Success 200 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/jobs/{name}/pause [post]
+func (h *JobHandler) PauseJob(c *gin.Context) {
+	if err := h.jobService.Pause(c.Param("name")); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// ResumeJob 恢复任务
+// @Summary 恢复任务
+// @Description 恢复任务的定时调度
+// @Tags 定时任务
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "任务名称"
+// @Success 200 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/jobs/{name}/resume [post]
+func (h *JobHandler) ResumeJob(c *gin.Context) {
+	if err := h.jobService.Resume(c.Param("name")); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// respondError 根据调度器错误返回对应的状态码
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, scheduler.ErrJobNotFound):
+		response.Error(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, scheduler.ErrJobRunning), errors.Is(err, scheduler.ErrJobLocked):
+		response.Error(c, http.StatusConflict, err.Error())
+	case errors.Is(err, scheduler.ErrNotConfigured), errors.Is(err, scheduler.ErrNotStarted):
+		response.Error(c, http.StatusServiceUnavailable, err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, err.Error())
+	}
+}
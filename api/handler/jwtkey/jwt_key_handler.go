@@ -0,0 +1,136 @@
+package jwtkey
+
+import (
+	"domain-admin/internal/repository"
+	"domain-admin/internal/service"
+	"domain-admin/model"
+	"domain-admin/pkg/config"
+	"domain-admin/pkg/db"
+	"domain-admin/pkg/jwt"
+	"domain-admin/pkg/logger"
+	"domain-admin/pkg/response"
+	"domain-admin/pkg/validator"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JWTKeyHandler JWT签名密钥处理器
+type JWTKeyHandler struct {
+	keyService service.JWTKeyService
+}
+
+// NewJWTKeyHandler 创建JWT签名密钥处理器
+func NewJWTKeyHandler() *JWTKeyHandler {
+	keyRepo := repository.NewJWTKeyRepository(db.GetDB("default"))
+	return &JWTKeyHandler{
+		keyService: service.NewJWTKeyService(keyRepo, config.GetConfig().JWT, config.GetConfig().Vault),
+	}
+}
+
+// JWKS 发布验证访问令牌的公钥
+// @Summary JWKS公钥集合
+// @Description 返回当前用于验证访问令牌的全部公钥（JSON Web Key Set），其他服务按令牌头中的 kid 选择公钥验证签名
+// @Tags JWT签名密钥
+// @Produce json
+// @Success 200 {object} jwt.JWKSet
+// @Router /.well-known/jwks.json [get]
+func (h *JWTKeyHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, jwt.JWKS())
+}
+
+// ListKeys 获取签名密钥列表
+// @Summary 获取签名密钥列表
+// @Description 获取数据库中保存的JWT签名密钥，不返回私钥，signing 标记当前签名密钥
+// @Tags JWT签名密钥
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]model.JWTKey}
+// @Failure 500 {object} response.Response
+// @Router /api/jwt-keys [get]
+func (h *JWTKeyHandler) ListKeys(c *gin.Context) {
+	keys, err := h.keyService.List()
+	if err != nil {
+		logger.Errorf("获取签名密钥列表失败: %v", err)
+		response.Error(c, http.StatusInternalServerError, "获取签名密钥列表失败")
+		return
+	}
+
+	response.Success(c, keys)
+}
+
+// RotateKey 轮换签名密钥
+// @Summary 轮换签名密钥
+// @Description 生成新的签名密钥并立即用于签发访问令牌，旧密钥继续用于验证，超过访问令牌有效期后可以停用
+// @Tags JWT签名密钥
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.JWTKeyRotateRequest false "签名算法"
+// @Success 200 {object} response.Response{data=model.JWTKey}
+// @Failure 400 {object} response.Response
+// @Router /api/jwt-keys/rotate [post]
+func (h *JWTKeyHandler) RotateKey(c *gin.Context) {
+	var req model.JWTKeyRotateRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			logger.Warnf("参数绑定失败: %v", err)
+			response.Error(c, http.StatusBadRequest, "参数格式错误")
+			return
+		}
+	}
+
+	// 参数验证
+	if err := validator.ValidateStruct(&req); err != nil {
+		logger.Warnf("参数验证失败: %v", err)
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	key, err := h.keyService.Rotate(&req)
+	if err != nil {
+		logger.Errorf("轮换签名密钥失败: %v", err)
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	uid, _ := c.Get("userID")
+	logger.Infof("用户 %v 轮换了JWT签名密钥，新密钥: %s", uid, key.Kid)
+	response.Success(c, key)
+}
+
+// RetireKey 停用签名密钥
+// @Summary 停用签名密钥
+// @Description 停用不再签名的旧密钥，该密钥签发的访问令牌立即失效，且不再出现在JWKS中
+// @Tags JWT签名密钥
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "密钥ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/jwt-keys/{id}/retire [post]
+func (h *JWTKeyHandler) RetireKey(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的密钥ID")
+		return
+	}
+
+	if err := h.keyService.Retire(uint(id)); err != nil {
+		logger.Errorf("停用签名密钥失败: %v", err)
+		if strings.Contains(err.Error(), "签名密钥不存在") {
+			response.Error(c, http.StatusNotFound, err.Error())
+		} else {
+			response.Error(c, http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+
+	response.Success(c, nil)
+}
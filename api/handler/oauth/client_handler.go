@@ -0,0 +1,167 @@
+package oauth
+
+import (
+	"domain-admin/model"
+	"domain-admin/pkg/logger"
+	"domain-admin/pkg/response"
+	"domain-admin/pkg/validator"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListClients 获取应用列表
+// @Summary 获取应用列表
+// @Description 获取接入本系统登录的全部应用，不返回客户端密钥
+// @Tags OAuth2应用
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]model.OAuthClient}
+// @Failure 500 {object} response.Response
+// @Router /api/oauth/clients [get]
+func (h *OAuthHandler) ListClients(c *gin.Context) {
+	clients, err := h.oauthService.ListClients()
+	if err != nil {
+		logger.Errorf("获取应用列表失败: %v", err)
+		response.Error(c, http.StatusInternalServerError, "获取应用列表失败")
+		return
+	}
+	response.Success(c, clients)
+}
+
+// CreateClient 注册应用
+// @Summary 注册应用
+// @Description 注册接入本系统登录的应用。机密客户端的密钥只在本次响应中返回；公开客户端没有密钥，只能使用授权码+PKCE
+// @Tags OAuth2应用
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.OAuthClientRequest true "应用信息"
+// @Success 200 {object} response.Response{data=model.OAuthClientSecretResult}
+// @Failure 400 {object} response.Response
+// @Router /api/oauth/clients [post]
+func (h *OAuthHandler) CreateClient(c *gin.Context) {
+	var req model.OAuthClientRequest
+	if !bindClientRequest(c, &req) {
+		return
+	}
+
+	result, err := h.oauthService.CreateClient(currentUserID(c), &req)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	response.Success(c, result)
+}
+
+// UpdateClient 更新应用
+// @Summary 更新应用
+// @Description 更新应用的名称、回调地址、授权类型、作用域、角色和状态，不能修改客户端类型
+// @Tags OAuth2应用
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "应用ID"
+// @Param request body model.OAuthClientRequest true "应用信息"
+// @Success 200 {object} response.Response{data=model.OAuthClient}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/oauth/clients/{id} [put]
+func (h *OAuthHandler) UpdateClient(c *gin.Context) {
+	id, ok := clientIDParam(c)
+	if !ok {
+		return
+	}
+	var req model.OAuthClientRequest
+	if !bindClientRequest(c, &req) {
+		return
+	}
+
+	client, err := h.oauthService.UpdateClient(id, &req)
+	if err != nil {
+		clientError(c, err)
+		return
+	}
+	response.Success(c, client)
+}
+
+// DeleteClient 删除应用
+// @Summary 删除应用
+// @Description 删除应用，已颁发的访问令牌立即失效
+// @Tags OAuth2应用
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "应用ID"
+// @Success 200 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/oauth/clients/{id} [delete]
+func (h *OAuthHandler) DeleteClient(c *gin.Context) {
+	id, ok := clientIDParam(c)
+	if !ok {
+		return
+	}
+	if err := h.oauthService.DeleteClient(id); err != nil {
+		clientError(c, err)
+		return
+	}
+	response.Success(c, nil)
+}
+
+// ResetClientSecret 重置应用密钥
+// @Summary 重置应用密钥
+// @Description 为机密客户端生成新密钥，旧密钥立即失效。新密钥只在本次响应中返回
+// @Tags OAuth2应用
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "应用ID"
+// @Success 200 {object} response.Response{data=model.OAuthClientSecretResult}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/oauth/clients/{id}/secret [post]
+func (h *OAuthHandler) ResetClientSecret(c *gin.Context) {
+	id, ok := clientIDParam(c)
+	if !ok {
+		return
+	}
+	result, err := h.oauthService.ResetClientSecret(id)
+	if err != nil {
+		clientError(c, err)
+		return
+	}
+	response.Success(c, result)
+}
+
+func bindClientRequest(c *gin.Context, req *model.OAuthClientRequest) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		logger.Warnf("参数绑定失败: %v", err)
+		response.Error(c, http.StatusBadRequest, "参数格式错误")
+		return false
+	}
+
+	// 参数验证
+	if err := validator.ValidateStruct(req); err != nil {
+		logger.Warnf("参数验证失败: %v", err)
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return false
+	}
+	return true
+}
+
+func clientIDParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的应用ID")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func clientError(c *gin.Context, err error) {
+	if strings.Contains(err.Error(), "应用不存在") {
+		response.Error(c, http.StatusNotFound, err.Error())
+		return
+	}
+	response.Error(c, http.StatusBadRequest, err.Error())
+}
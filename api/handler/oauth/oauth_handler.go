@@ -0,0 +1,296 @@
+package oauth
+
+import (
+	"domain-admin/internal/repository"
+	"domain-admin/internal/service"
+	"domain-admin/model"
+	"domain-admin/pkg/config"
+	"domain-admin/pkg/db"
+	"domain-admin/pkg/logger"
+	"domain-admin/pkg/middleware"
+	"domain-admin/pkg/response"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OAuthHandler OAuth2授权服务处理器，协议端点按 RFC 6749 返回 {error, error_description} 格式的错误
+type OAuthHandler struct {
+	oauthService service.OAuthService
+}
+
+// NewOAuthHandler 创建OAuth2授权服务处理器
+func NewOAuthHandler() *OAuthHandler {
+	database := db.GetDB("default")
+	return &OAuthHandler{
+		oauthService: service.NewOAuthService(
+			repository.NewOAuthRepository(database),
+			repository.NewUserRepository(database),
+			repository.NewRoleRepository(database),
+			config.GetConfig().OAuth,
+		),
+	}
+}
+
+// Discovery OpenID Connect发现文档
+// @Summary OpenID Connect发现文档
+// @Description 返回授权服务的端点、支持的作用域和签名算法，内部系统据此自动配置
+// @Tags OAuth2
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Router /.well-known/openid-configuration [get]
+func (h *OAuthHandler) Discovery(c *gin.Context) {
+	doc, err := h.oauthService.Discovery()
+	if err != nil {
+		protocolError(c, err)
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, doc)
+}
+
+// Authorize 授权端点
+// @Summary 授权端点
+// @Description 校验授权请求后重定向到前端授权页，用户登录并同意后由授权页提交 /api/oauth/authorize。必须使用 S256 方式的 PKCE
+// @Tags OAuth2
+// @Param response_type query string true "固定为 code"
+// @Param client_id query string true "客户端ID"
+// @Param redirect_uri query string true "回调地址，必须与注册的地址完全一致"
+// @Param scope query string false "作用域，以空格分隔"
+// @Param state query string false "state"
+// @Param nonce query string false "ID Token的nonce"
+// @Param code_challenge query string true "PKCE挑战"
+// @Param code_challenge_method query string true "固定为 S256"
+// @Success 302
+// @Failure 400 {object} map[string]string
+// @Router /oauth/authorize [get]
+func (h *OAuthHandler) Authorize(c *gin.Context) {
+	var req model.OAuthAuthorizeRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		protocolError(c, &service.OAuthError{Code: "invalid_request", Description: "参数格式错误", Status: http.StatusBadRequest})
+		return
+	}
+
+	// 应用或回调地址无效时不能重定向，否则会成为开放重定向
+	location, err := h.oauthService.AuthorizeRedirect(&req, c.Request.URL.RawQuery)
+	if err != nil {
+		protocolError(c, err)
+		return
+	}
+	c.Redirect(http.StatusFound, location)
+}
+
+// Token 令牌端点
+// @Summary 令牌端点
+// @Description 使用授权码（需要 code_verifier）或客户端凭据换取访问令牌，作用域包含 openid 时同时返回 ID Token。
+// @Description 机密客户端使用 HTTP Basic 或表单中的 client_id/client_secret 认证，公开客户端只提交 client_id
+// @Tags OAuth2
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param grant_type formData string true "authorization_code 或 client_credentials"
+// @Param code formData string false "授权码"
+// @Param redirect_uri formData string false "回调地址"
+// @Param code_verifier formData string false "PKCE验证码"
+// @Param scope formData string false "客户端凭据模式申请的作用域"
+// @Success 200 {object} model.OAuthTokenResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /oauth/token [post]
+func (h *OAuthHandler) Token(c *gin.Context) {
+	clientID, clientSecret, ok := clientCredentials(c)
+	if !ok {
+		protocolError(c, &service.OAuthError{Code: "invalid_request", Description: "参数格式错误", Status: http.StatusBadRequest})
+		return
+	}
+	token, err := h.oauthService.Token(clientID, clientSecret, c.Request.PostForm)
+	if err != nil {
+		protocolError(c, err)
+		return
+	}
+	noStore(c)
+	c.JSON(http.StatusOK, token)
+}
+
+// Introspect 令牌自省
+// @Summary 令牌自省
+// @Description 资源服务使用机密客户端的凭据查询访问令牌是否有效，令牌包含 roles 作用域时返回角色和权限
+// @Tags OAuth2
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param token formData string true "访问令牌"
+// @Success 200 {object} model.OAuthIntrospection
+// @Failure 401 {object} map[string]string
+// @Router /oauth/introspect [post]
+func (h *OAuthHandler) Introspect(c *gin.Context) {
+	clientID, clientSecret, ok := clientCredentials(c)
+	if !ok {
+		protocolError(c, &service.OAuthError{Code: "invalid_request", Description: "参数格式错误", Status: http.StatusBadRequest})
+		return
+	}
+	result, err := h.oauthService.Introspect(clientID, clientSecret, c.PostForm("token"))
+	if err != nil {
+		protocolError(c, err)
+		return
+	}
+	noStore(c)
+	c.JSON(http.StatusOK, result)
+}
+
+// Revoke 吊销令牌
+// @Summary 吊销令牌
+// @Description 应用吊销自己获得的访问令牌，令牌不存在时同样返回成功
+// @Tags OAuth2
+// @Accept x-www-form-urlencoded
+// @Param token formData string true "访问令牌"
+// @Success 200
+// @Failure 401 {object} map[string]string
+// @Router /oauth/revoke [post]
+func (h *OAuthHandler) Revoke(c *gin.Context) {
+	clientID, clientSecret, ok := clientCredentials(c)
+	if !ok {
+		protocolError(c, &service.OAuthError{Code: "invalid_request", Description: "参数格式错误", Status: http.StatusBadRequest})
+		return
+	}
+	if err := h.oauthService.Revoke(clientID, clientSecret, c.PostForm("token")); err != nil {
+		protocolError(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// UserInfo 用户信息端点
+// @Summary 用户信息端点
+// @Description 使用包含 openid 作用域的访问令牌获取用户信息，返回的声明由令牌的作用域决定
+// @Tags OAuth2
+// @Produce json
+// @Param Authorization header string true "Bearer 访问令牌"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]string
+// @Router /oauth/userinfo [get]
+func (h *OAuthHandler) UserInfo(c *gin.Context) {
+	token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !ok {
+		c.Header("WWW-Authenticate", `Bearer`)
+		protocolError(c, &service.OAuthError{Code: "invalid_token", Description: "缺少访问令牌", Status: http.StatusUnauthorized})
+		return
+	}
+	info, err := h.oauthService.UserInfo(strings.TrimSpace(token))
+	if err != nil {
+		var oerr *service.OAuthError
+		if errors.As(err, &oerr) {
+			c.Header("WWW-Authenticate", `Bearer error="`+oerr.Code+`"`)
+			if oerr.Code == "invalid_token" {
+				oerr.Status = http.StatusUnauthorized
+			}
+		}
+		protocolError(c, err)
+		return
+	}
+	noStore(c)
+	c.JSON(http.StatusOK, info)
+}
+
+// GetAuthorization 获取授权页信息
+// @Summary 获取授权页信息
+// @Description 前端授权页提交 /oauth/authorize 的查询参数，返回应用名称和申请的作用域
+// @Tags OAuth2
+// @Produce json
+// @Security BearerAuth
+// @Param client_id query string true "客户端ID"
+// @Param redirect_uri query string true "回调地址"
+// @Success 200 {object} response.Response{data=model.OAuthAuthorizeInfo}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /api/oauth/authorize [get]
+func (h *OAuthHandler) GetAuthorization(c *gin.Context) {
+	var req model.OAuthAuthorizeRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数格式错误")
+		return
+	}
+	info, err := h.oauthService.AuthorizeInfo(&req)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	response.Success(c, info)
+}
+
+// SubmitAuthorization 同意或拒绝授权
+// @Summary 同意或拒绝授权
+// @Description 当前登录用户同意或拒绝应用的授权请求，前端跳转到返回的 redirect_to。不能使用API令牌授权
+// @Tags OAuth2
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.OAuthAuthorizeRequest true "授权请求参数"
+// @Success 200 {object} response.Response{data=model.OAuthAuthorizeResult}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /api/oauth/authorize [post]
+func (h *OAuthHandler) SubmitAuthorization(c *gin.Context) {
+	if _, ok := middleware.GetTokenIdentity(c); ok {
+		response.Error(c, http.StatusForbidden, "不能使用API令牌授权应用")
+		return
+	}
+
+	var req model.OAuthAuthorizeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Warnf("参数绑定失败: %v", err)
+		response.Error(c, http.StatusBadRequest, "参数格式错误")
+		return
+	}
+
+	result, err := h.oauthService.Authorize(currentUserID(c), &req)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	response.Success(c, result)
+}
+
+// clientCredentials 读取客户端凭据，优先使用 HTTP Basic 认证
+func clientCredentials(c *gin.Context) (string, string, bool) {
+	if err := c.Request.ParseForm(); err != nil {
+		return "", "", false
+	}
+	if id, secret, ok := c.Request.BasicAuth(); ok {
+		// RFC 6749 2.3.1：Basic 认证中的客户端ID和密钥先经过表单编码
+		clientID, err1 := url.QueryUnescape(id)
+		clientSecret, err2 := url.QueryUnescape(secret)
+		return clientID, clientSecret, err1 == nil && err2 == nil
+	}
+	return c.Request.PostForm.Get("client_id"), c.Request.PostForm.Get("client_secret"), true
+}
+
+// protocolError 按 OAuth2 协议格式返回错误
+func protocolError(c *gin.Context, err error) {
+	noStore(c)
+	var oerr *service.OAuthError
+	switch {
+	case errors.As(err, &oerr):
+		if oerr.Code == "invalid_client" {
+			c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+		}
+		c.JSON(oerr.Status, gin.H{"error": oerr.Code, "error_description": oerr.Description})
+	case errors.Is(err, service.ErrOAuthDisabled):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not_found", "error_description": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_request", "error_description": err.Error()})
+	}
+}
+
+func noStore(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+}
+
+func currentUserID(c *gin.Context) uint {
+	uid, _ := c.Get("userID")
+	userID, _ := uid.(uint)
+	return userID
+}
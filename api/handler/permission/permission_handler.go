@@ -106,7 +106,7 @@ func (h *PermissionHandler) UpdatePermission(c *gin.Context) {
 	}
 
 	permission.ID = uint(id)
-	if err := h.permissionService.Update(&permission); err != nil {
+	if err := h.permissionService.Update(c, &permission); err != nil {
 		response.Error(c, http.StatusBadRequest, "更新权限失败")
 		return
 	}
@@ -132,7 +132,7 @@ func (h *PermissionHandler) DeletePermission(c *gin.Context) {
 		return
 	}
 
-	if err := h.permissionService.Delete(uint(id)); err != nil {
+	if err := h.permissionService.Delete(c, uint(id)); err != nil {
 		response.Error(c, http.StatusBadRequest, "删除权限失败")
 		return
 	}
@@ -0,0 +1,266 @@
+package provideraccount
+
+import (
+	"domain-admin/internal/repository"
+	"domain-admin/internal/service"
+	"domain-admin/model"
+	"domain-admin/pkg/config"
+	"domain-admin/pkg/db"
+	"domain-admin/pkg/logger"
+	"domain-admin/pkg/pagination"
+	"domain-admin/pkg/response"
+	"domain-admin/pkg/validator"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProviderAccountHandler 云服务商账号处理器
+type ProviderAccountHandler struct {
+	accountService service.ProviderAccountService
+	vaultService   service.VaultService
+}
+
+// NewProviderAccountHandler 创建云服务商账号处理器
+// 主密钥轮换覆盖所有使用 vault 主密钥加密数据的服务
+func NewProviderAccountHandler() *ProviderAccountHandler {
+	database := db.GetDB("default")
+	cfg := config.GetConfig()
+	accountService := service.NewProviderAccountService(repository.NewProviderAccountRepository(database), cfg.Vault)
+	twoFactorService := service.NewTwoFactorService(
+		repository.NewTwoFactorRepository(database),
+		repository.NewWebAuthnRepository(database),
+		repository.NewUserRepository(database),
+		repository.NewRoleRepository(database),
+		cfg.Vault,
+	)
+	jwtKeyService := service.NewJWTKeyService(repository.NewJWTKeyRepository(database), cfg.JWT, cfg.Vault)
+	acmeService := service.NewAcmeService(repository.NewAcmeRepository(database), cfg.ACME, cfg.Vault)
+	return &ProviderAccountHandler{
+		accountService: accountService,
+		vaultService:   service.NewVaultService(cfg.Vault, accountService, twoFactorService, jwtKeyService, acmeService),
+	}
+}
+
+// CreateAccount 创建云服务商账号
+// @Summary 创建云服务商账号
+// @Description 创建云服务商账号，访问密钥加密保存且不会在接口中返回。options 只能包含该类型允许通过接口设置的参数，读写本地文件等参数只能在配置文件中设置
+// @Tags 云服务商账号
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.ProviderAccountCreateRequest true "账号信息"
+// @Success 200 {object} response.Response{data=model.ProviderAccount}
+// @Failure 400 {object} response.Response
+// @Router /api/provider-accounts [post]
+func (h *ProviderAccountHandler) CreateAccount(c *gin.Context) {
+	var req model.ProviderAccountCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Warnf("参数绑定失败: %v", err)
+		response.Error(c, http.StatusBadRequest, "参数格式错误")
+		return
+	}
+
+	// 参数验证
+	if err := validator.ValidateStruct(&req); err != nil {
+		logger.Warnf("参数验证失败: %v", err)
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	account, err := h.accountService.Create(&req)
+	if err != nil {
+		logger.Errorf("创建云服务商账号失败: %v", err)
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, account)
+}
+
+// GetAccount 获取云服务商账号详情
+// @Summary 获取云服务商账号详情
+// @Description 根据ID获取云服务商账号，不包含访问密钥
+// @Tags 云服务商账号
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "账号ID"
+// @Success 200 {object} response.Response{data=model.ProviderAccount}
+// @Failure 404 {object} response.Response
+// @Router /api/provider-accounts/{id} [get]
+func (h *ProviderAccountHandler) GetAccount(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的账号ID")
+		return
+	}
+
+	account, err := h.accountService.GetByID(uint(id))
+	if err != nil {
+		response.Error(c, http.StatusNotFound, "云服务商账号不存在")
+		return
+	}
+
+	response.Success(c, account)
+}
+
+// UpdateAccount 更新云服务商账号
+// @Summary 更新云服务商账号
+// @Description 更新访问密钥、参数和备注，未传的字段保持不变，账号名称和类型不可修改
+// @Tags 云服务商账号
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "账号ID"
+// @Param request body model.ProviderAccountUpdateRequest true "更新信息"
+// @Success 200 {object} response.Response{data=model.ProviderAccount}
+// @Failure 400 {object} response.Response
+// @Router /api/provider-accounts/{id} [put]
+func (h *ProviderAccountHandler) UpdateAccount(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的账号ID")
+		return
+	}
+
+	var req model.ProviderAccountUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Warnf("参数绑定失败: %v", err)
+		response.Error(c, http.StatusBadRequest, "参数格式错误")
+		return
+	}
+
+	// 参数验证
+	if err := validator.ValidateStruct(&req); err != nil {
+		logger.Warnf("参数验证失败: %v", err)
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	account, err := h.accountService.Update(uint(id), &req)
+	if err != nil {
+		logger.Errorf("更新云服务商账号失败: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, account)
+}
+
+// DeleteAccount 删除云服务商账号
+// @Summary 删除云服务商账号
+// @Description 删除云服务商账号及加密保存的访问密钥
+// @Tags 云服务商账号
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "账号ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Router /api/provider-accounts/{id} [delete]
+func (h *ProviderAccountHandler) DeleteAccount(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的账号ID")
+		return
+	}
+
+	if err := h.accountService.Delete(uint(id)); err != nil {
+		logger.Errorf("删除云服务商账号失败: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// ListAccounts 获取云服务商账号列表
+// @Summary 获取云服务商账号列表
+// @Description 分页获取云服务商账号列表，不包含访问密钥
+// @Tags 云服务商账号
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param offset query int false "偏移量" default(0)
+// @Param limit query int false "每页数量" default(10)
+// @Param orderBy query string false "排序字段" default(id)
+// @Param sort query string false "排序方式" default(asc)
+// @Success 200 {object} response.Response{data=pagination.PageResult}
+// @Failure 500 {object} response.Response
+// @Router /api/provider-accounts [get]
+func (h *ProviderAccountHandler) ListAccounts(c *gin.Context) {
+	page := pagination.New(c)
+
+	accounts, total, err := h.accountService.List(page)
+	if err != nil {
+		logger.Errorf("获取云服务商账号列表失败: %v", err)
+		response.Error(c, http.StatusInternalServerError, "获取云服务商账号列表失败")
+		return
+	}
+
+	result := pagination.NewPageResult(total, accounts)
+	response.Success(c, result)
+}
+
+// TestAccount 测试云服务商账号连接
+// @Summary 测试云服务商账号连接
+// @Description 使用保存的凭据连接云服务商并记录测试结果，结果见 last_test_ok 和 last_test_error
+// @Tags 云服务商账号
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "账号ID"
+// @Success 200 {object} response.Response{data=model.ProviderAccount}
+// @Failure 400 {object} response.Response
+// @Router /api/provider-accounts/{id}/test [post]
+func (h *ProviderAccountHandler) TestAccount(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的账号ID")
+		return
+	}
+
+	account, err := h.accountService.Test(c.Request.Context(), uint(id))
+	if err != nil {
+		logger.Errorf("测试云服务商账号连接失败: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, account)
+}
+
+// RotateKey 轮换加密主密钥
+// @Summary 轮换加密主密钥
+// @Description 使用当前主密钥重新加密所有由旧主密钥加密的数据，包括云服务商访问密钥、两步验证密钥、JWT签名私钥和ACME账号及证书私钥。只有返回 complete 为 true 时才可以从密钥列表中移除旧主密钥
+// @Tags 云服务商账号
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=model.VaultRotateResult}
+// @Failure 400 {object} response.Response
+// @Router /api/provider-accounts/rotate-key [post]
+func (h *ProviderAccountHandler) RotateKey(c *gin.Context) {
+	result, err := h.vaultService.RotateKey(c.Request.Context())
+	if err != nil {
+		logger.Errorf("轮换加密主密钥失败: %v", err)
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	uid, _ := c.Get("userID")
+	logger.Infof("用户 %v 轮换了加密主密钥，轮换完成: %v", uid, result.Complete)
+	response.Success(c, result)
+}
+
+// respondError 账号不存在时返回404，其余返回400
+func respondError(c *gin.Context, err error) {
+	if strings.Contains(err.Error(), "云服务商账号不存在") {
+		response.Error(c, http.StatusNotFound, err.Error())
+		return
+	}
+	response.Error(c, http.StatusBadRequest, err.Error())
+}
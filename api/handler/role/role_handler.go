@@ -5,8 +5,13 @@ import (
 	"domain-admin/internal/service"
 	"domain-admin/model"
 	"domain-admin/pkg/db"
+	"domain-admin/pkg/logger"
+	"domain-admin/pkg/middleware"
 	"domain-admin/pkg/pagination"
 	"domain-admin/pkg/response"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 
@@ -14,17 +19,20 @@ import (
 )
 
 type RoleHandler struct {
-	roleService service.RoleService
+	roleService       service.RoleService
+	permissionService service.PermissionService
 }
 
 // NewRoleHandler 创建角色处理器
 func NewRoleHandler() *RoleHandler {
 	roleRepo := repository.NewRoleRepository(db.GetDB("default"))
 	permissionRepo := repository.NewPermissionRepository(db.GetDB("default"))
-	roleService := service.NewRoleService(roleRepo, permissionRepo)
+	revisionRepo := repository.NewRevisionRepository(db.GetDB("default"))
+	roleService := service.NewRoleService(roleRepo, permissionRepo, revisionRepo)
 
 	return &RoleHandler{
-		roleService: roleService,
+		roleService:       roleService,
+		permissionService: service.NewPermissionService(permissionRepo, roleRepo),
 	}
 }
 
@@ -106,7 +114,7 @@ func (h *RoleHandler) UpdateRole(c *gin.Context) {
 	}
 
 	role.ID = uint(id)
-	if err := h.roleService.Update(&role); err != nil {
+	if err := h.roleService.Update(c, &role); err != nil {
 		response.Error(c, http.StatusBadRequest, "更新角色失败")
 		return
 	}
@@ -227,7 +235,7 @@ func (h *RoleHandler) AssignPermissions(c *gin.Context) {
 		return
 	}
 
-	if err := h.roleService.AssignPermissions(uint(id), req.PermissionIDs); err != nil {
+	if err := h.roleService.AssignPermissions(c, uint(id), req.PermissionIDs); err != nil {
 		response.Error(c, http.StatusBadRequest, "分配权限失败")
 		return
 	}
@@ -260,4 +268,130 @@ func (h *RoleHandler) GetRolePermissions(c *gin.Context) {
 	}
 
 	response.Success(c, permissions)
-}
\ No newline at end of file
+}
+
+// GetRoleHistory 获取角色修订历史
+// @Summary 获取角色修订历史
+// @Description 分页获取角色的修订记录（按修订号倒序），包含修改前后的角色字段和权限集合快照
+// @Tags 角色管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "角色ID"
+// @Param offset query int false "偏移量" default(0)
+// @Param limit query int false "每页数量" default(10)
+// @Success 200 {object} response.Response{data=pagination.PageResult}
+// @Failure 400 {object} response.Response
+// @Router /api/roles/{id}/history [get]
+func (h *RoleHandler) GetRoleHistory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的角色ID")
+		return
+	}
+
+	page := pagination.New(c)
+	revisions, total, err := h.roleService.History(uint(id), page)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "获取角色修订历史失败")
+		return
+	}
+
+	result := pagination.NewPageResult(total, revisions)
+	response.Success(c, result)
+}
+
+// RollbackRole 回滚角色
+// @Summary 回滚角色
+// @Description 将角色字段和权限集合恢复为指定修订号之后的状态（to=0 恢复为首次修订之前），回滚后重新同步RBAC策略
+// @Tags 角色管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "角色ID"
+// @Param to query int true "目标修订号"
+// @Success 200 {object} response.Response{data=model.RoleRollbackResult}
+// @Failure 400 {object} response.Response
+// @Router /api/roles/{id}/rollback [post]
+func (h *RoleHandler) RollbackRole(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的角色ID")
+		return
+	}
+	rev, err := strconv.Atoi(c.Query("to"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的修订号")
+		return
+	}
+
+	result, err := h.roleService.Rollback(c, uint(id), rev)
+	if err != nil {
+		logger.Errorf("回滚角色失败: %v", err)
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := middleware.SyncRBACPolicies(db.GetDB("default")); err != nil {
+		logger.Errorf("回滚角色后同步RBAC策略失败: %v", err)
+		response.Error(c, http.StatusInternalServerError, "角色已回滚，但同步RBAC策略失败")
+		return
+	}
+
+	logger.Infof("用户 %v 将角色 %s 回滚到修订 %d", c.GetString("username"), result.Role.Name, rev)
+	response.Success(c, result)
+}
+
+// DiffDeleteRole 删除角色的变更内容，供审批人查看被删除的角色及其权限
+func (h *RoleHandler) DiffDeleteRole(c *gin.Context, body []byte) (interface{}, interface{}, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return nil, nil, errors.New("无效的角色ID")
+	}
+	role, err := h.roleService.GetByID(uint(id))
+	if err != nil {
+		return nil, nil, err
+	}
+	permissions, err := h.roleService.GetRolePermissions(role.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return gin.H{"role": role, "permissions": permissionNames(permissions)}, nil, nil
+}
+
+// DiffAssignPermissions 分配权限的变更内容，比较角色当前权限和请求分配的权限
+func (h *RoleHandler) DiffAssignPermissions(c *gin.Context, body []byte) (interface{}, interface{}, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return nil, nil, errors.New("无效的角色ID")
+	}
+	var req struct {
+		PermissionIDs []uint `json:"permission_ids"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, nil, errors.New("参数错误")
+	}
+
+	current, err := h.roleService.GetRolePermissions(uint(id))
+	if err != nil {
+		return nil, nil, err
+	}
+	requested := make([]*model.Permission, 0, len(req.PermissionIDs))
+	for _, pid := range req.PermissionIDs {
+		permission, err := h.permissionService.GetByID(pid)
+		if err != nil {
+			return nil, nil, fmt.Errorf("权限 %d 不存在", pid)
+		}
+		requested = append(requested, permission)
+	}
+	return permissionNames(current), permissionNames(requested), nil
+}
+
+// permissionNames 返回权限名称列表
+func permissionNames(permissions []*model.Permission) []string {
+	names := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		names = append(names, permission.Name)
+	}
+	return names
+}
@@ -0,0 +1,435 @@
+package scim
+
+import (
+	"domain-admin/internal/repository"
+	"domain-admin/internal/service"
+	"domain-admin/model"
+	"domain-admin/pkg/config"
+	"domain-admin/pkg/db"
+	"domain-admin/pkg/logger"
+	"domain-admin/pkg/scim"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// basePath SCIM 接口的路径前缀
+	basePath = "/scim/v2"
+	// 列表查询的默认和最大分页大小
+	defaultCount = 100
+	maxCount     = 200
+)
+
+// SCIMHandler SCIM 2.0 处理器，响应使用 application/scim+json，错误使用 SCIM 错误格式
+type SCIMHandler struct {
+	scimService service.SCIMService
+}
+
+// NewSCIMHandler 创建SCIM处理器
+func NewSCIMHandler() *SCIMHandler {
+	database := db.GetDB("default")
+	userRepo := repository.NewUserRepository(database)
+	return &SCIMHandler{
+		scimService: service.NewSCIMService(
+			userRepo,
+			repository.NewRoleRepository(database),
+			repository.NewUserIdentityRepository(database),
+			service.NewAuthTokenService(
+				repository.NewUserSessionRepository(database),
+				repository.NewRefreshTokenRepository(database),
+				userRepo,
+				config.GetConfig().JWT.RefreshTTL(),
+			),
+		),
+	}
+}
+
+// ServiceProviderConfig SCIM服务配置
+// @Summary SCIM服务配置
+// @Description 返回支持的SCIM功能：PATCH、过滤（最多返回200条）和修改密码，不支持批量操作、排序和ETag
+// @Tags SCIM
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /scim/v2/ServiceProviderConfig [get]
+func (h *SCIMHandler) ServiceProviderConfig(c *gin.Context) {
+	render(c, http.StatusOK, map[string]interface{}{
+		"schemas":          []string{scim.SchemaServiceProviderConfig},
+		"documentationUri": "",
+		"patch":            map[string]bool{"supported": true},
+		"bulk":             map[string]interface{}{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":           map[string]interface{}{"supported": true, "maxResults": maxCount},
+		"changePassword":   map[string]bool{"supported": true},
+		"sort":             map[string]bool{"supported": false},
+		"etag":             map[string]bool{"supported": false},
+		"authenticationSchemes": []map[string]interface{}{{
+			"type":        "oauthbearertoken",
+			"name":        "API令牌",
+			"description": "使用具有SCIM权限的API令牌，请求头 Authorization: Bearer <token>",
+			"primary":     true,
+		}},
+		"meta": map[string]string{"resourceType": "ServiceProviderConfig", "location": baseURL(c) + "/ServiceProviderConfig"},
+	})
+}
+
+// ResourceTypes SCIM资源类型
+// @Summary SCIM资源类型
+// @Description 返回支持的资源类型 User 和 Group
+// @Tags SCIM
+// @Produce json
+// @Success 200 {object} scim.ListResponse
+// @Router /scim/v2/ResourceTypes [get]
+func (h *SCIMHandler) ResourceTypes(c *gin.Context) {
+	base := baseURL(c)
+	types := []interface{}{
+		map[string]interface{}{
+			"schemas": []string{scim.SchemaResourceType}, "id": "User", "name": "User",
+			"endpoint": "/Users", "schema": scim.SchemaUser,
+			"meta": map[string]string{"resourceType": "ResourceType", "location": base + "/ResourceTypes/User"},
+		},
+		map[string]interface{}{
+			"schemas": []string{scim.SchemaResourceType}, "id": "Group", "name": "Group",
+			"endpoint": "/Groups", "schema": scim.SchemaGroup,
+			"meta": map[string]string{"resourceType": "ResourceType", "location": base + "/ResourceTypes/Group"},
+		},
+	}
+	render(c, http.StatusOK, scim.NewListResponse(types, scim.Page{StartIndex: 1, Count: len(types)}))
+}
+
+// ListUsers 查询用户
+// @Summary 查询用户
+// @Description 按 RFC 7644 过滤条件查询用户，如 userName eq "alice"，支持 startIndex、count、attributes 和 excludedAttributes
+// @Tags SCIM
+// @Produce json
+// @Security BearerAuth
+// @Param filter query string false "过滤条件"
+// @Param startIndex query int false "起始位置，从1开始"
+// @Param count query int false "每页数量，默认100，最多200"
+// @Success 200 {object} scim.ListResponse
+// @Failure 400 {object} map[string]interface{}
+// @Router /scim/v2/Users [get]
+func (h *SCIMHandler) ListUsers(c *gin.Context) {
+	list, err := h.scimService.ListUsers(baseURL(c), c.Query("filter"), listPage(c))
+	if err != nil {
+		scimError(c, err)
+		return
+	}
+	render(c, http.StatusOK, list)
+}
+
+// GetUser 获取用户
+// @Summary 获取用户
+// @Tags SCIM
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "用户ID"
+// @Success 200 {object} model.SCIMUser
+// @Failure 404 {object} map[string]interface{}
+// @Router /scim/v2/Users/{id} [get]
+func (h *SCIMHandler) GetUser(c *gin.Context) {
+	user, err := h.scimService.GetUser(baseURL(c), c.Param("id"))
+	if err != nil {
+		scimError(c, err)
+		return
+	}
+	render(c, http.StatusOK, user)
+}
+
+// CreateUser 创建用户
+// @Summary 创建用户
+// @Description 用户名或邮箱已存在时返回409。未提供密码的用户不能使用密码登录，可通过单点登录登录；新用户的角色为 user，通过 Group 的成员修改
+// @Tags SCIM
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.SCIMUser true "用户"
+// @Success 201 {object} model.SCIMUser
+// @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /scim/v2/Users [post]
+func (h *SCIMHandler) CreateUser(c *gin.Context) {
+	var req model.SCIMUser
+	if !bindBody(c, &req) {
+		return
+	}
+	user, err := h.scimService.CreateUser(baseURL(c), &req)
+	if err != nil {
+		scimError(c, err)
+		return
+	}
+	c.Header("Location", user.Meta.Location)
+	render(c, http.StatusCreated, user)
+}
+
+// ReplaceUser 替换用户
+// @Summary 替换用户
+// @Description 使用完整的资源替换用户信息，active 为 false 时禁用用户并吊销其会话
+// @Tags SCIM
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "用户ID"
+// @Param request body model.SCIMUser true "用户"
+// @Success 200 {object} model.SCIMUser
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /scim/v2/Users/{id} [put]
+func (h *SCIMHandler) ReplaceUser(c *gin.Context) {
+	var req model.SCIMUser
+	if !bindBody(c, &req) {
+		return
+	}
+	user, err := h.scimService.ReplaceUser(baseURL(c), c.Param("id"), &req)
+	if err != nil {
+		scimError(c, err)
+		return
+	}
+	render(c, http.StatusOK, user)
+}
+
+// PatchUser 修改用户
+// @Summary 修改用户
+// @Description 按 RFC 7644 3.5.2 的 add、replace、remove 操作修改用户，如 {"op":"replace","path":"active","value":false}
+// @Tags SCIM
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "用户ID"
+// @Param request body scim.PatchRequest true "PATCH操作"
+// @Success 200 {object} model.SCIMUser
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /scim/v2/Users/{id} [patch]
+func (h *SCIMHandler) PatchUser(c *gin.Context) {
+	var req scim.PatchRequest
+	if !bindBody(c, &req) {
+		return
+	}
+	user, err := h.scimService.PatchUser(baseURL(c), c.Param("id"), &req)
+	if err != nil {
+		scimError(c, err)
+		return
+	}
+	render(c, http.StatusOK, user)
+}
+
+// DeleteUser 删除用户
+// @Summary 删除用户
+// @Description 删除用户并吊销其全部会话
+// @Tags SCIM
+// @Security BearerAuth
+// @Param id path string true "用户ID"
+// @Success 204
+// @Failure 404 {object} map[string]interface{}
+// @Router /scim/v2/Users/{id} [delete]
+func (h *SCIMHandler) DeleteUser(c *gin.Context) {
+	if err := h.scimService.DeleteUser(c.Param("id")); err != nil {
+		scimError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListGroups 查询用户组
+// @Summary 查询用户组
+// @Description 用户组对应角色，displayName 为角色名称，members 为该角色的用户。支持过滤、分页和 excludedAttributes=members
+// @Tags SCIM
+// @Produce json
+// @Security BearerAuth
+// @Param filter query string false "过滤条件"
+// @Param startIndex query int false "起始位置，从1开始"
+// @Param count query int false "每页数量，默认100，最多200"
+// @Success 200 {object} scim.ListResponse
+// @Failure 400 {object} map[string]interface{}
+// @Router /scim/v2/Groups [get]
+func (h *SCIMHandler) ListGroups(c *gin.Context) {
+	list, err := h.scimService.ListGroups(baseURL(c), c.Query("filter"), listPage(c))
+	if err != nil {
+		scimError(c, err)
+		return
+	}
+	render(c, http.StatusOK, list)
+}
+
+// GetGroup 获取用户组
+// @Summary 获取用户组
+// @Tags SCIM
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "角色ID"
+// @Success 200 {object} model.SCIMGroup
+// @Failure 404 {object} map[string]interface{}
+// @Router /scim/v2/Groups/{id} [get]
+func (h *SCIMHandler) GetGroup(c *gin.Context) {
+	group, err := h.scimService.GetGroup(baseURL(c), c.Param("id"))
+	if err != nil {
+		scimError(c, err)
+		return
+	}
+	render(c, http.StatusOK, group)
+}
+
+// CreateGroup 创建用户组
+// @Summary 创建用户组
+// @Description 创建没有任何权限的同名角色，权限需由管理员分配。每个用户只属于一个角色，加入该组的成员会离开原角色
+// @Tags SCIM
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.SCIMGroup true "用户组"
+// @Success 201 {object} model.SCIMGroup
+// @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /scim/v2/Groups [post]
+func (h *SCIMHandler) CreateGroup(c *gin.Context) {
+	var req model.SCIMGroup
+	if !bindBody(c, &req) {
+		return
+	}
+	group, err := h.scimService.CreateGroup(baseURL(c), &req)
+	if err != nil {
+		scimError(c, err)
+		return
+	}
+	c.Header("Location", group.Meta.Location)
+	render(c, http.StatusCreated, group)
+}
+
+// ReplaceGroup 替换用户组
+// @Summary 替换用户组
+// @Description 替换用户组成员，不再是成员的用户恢复为 user 角色。不能修改 displayName
+// @Tags SCIM
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "角色ID"
+// @Param request body model.SCIMGroup true "用户组"
+// @Success 200 {object} model.SCIMGroup
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /scim/v2/Groups/{id} [put]
+func (h *SCIMHandler) ReplaceGroup(c *gin.Context) {
+	var req model.SCIMGroup
+	if !bindBody(c, &req) {
+		return
+	}
+	group, err := h.scimService.ReplaceGroup(baseURL(c), c.Param("id"), &req)
+	if err != nil {
+		scimError(c, err)
+		return
+	}
+	render(c, http.StatusOK, group)
+}
+
+// PatchGroup 修改用户组
+// @Summary 修改用户组
+// @Description 添加或移除成员，如 {"op":"remove","path":"members[value eq \"2\"]"}，移除的用户恢复为 user 角色
+// @Tags SCIM
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "角色ID"
+// @Param request body scim.PatchRequest true "PATCH操作"
+// @Success 200 {object} model.SCIMGroup
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /scim/v2/Groups/{id} [patch]
+func (h *SCIMHandler) PatchGroup(c *gin.Context) {
+	var req scim.PatchRequest
+	if !bindBody(c, &req) {
+		return
+	}
+	group, err := h.scimService.PatchGroup(baseURL(c), c.Param("id"), &req)
+	if err != nil {
+		scimError(c, err)
+		return
+	}
+	render(c, http.StatusOK, group)
+}
+
+// DeleteGroup 删除用户组
+// @Summary 删除用户组
+// @Description 删除对应的角色，成员恢复为 user 角色。系统内置角色不能删除
+// @Tags SCIM
+// @Security BearerAuth
+// @Param id path string true "角色ID"
+// @Success 204
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /scim/v2/Groups/{id} [delete]
+func (h *SCIMHandler) DeleteGroup(c *gin.Context) {
+	if err := h.scimService.DeleteGroup(c.Param("id")); err != nil {
+		scimError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// baseURL 返回当前请求的 SCIM 接口地址，用于 meta.location 和 $ref
+func baseURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host + basePath
+}
+
+func listPage(c *gin.Context) scim.Page {
+	return scim.ParsePage(c.Query("startIndex"), c.Query("count"), defaultCount, maxCount)
+}
+
+// bindBody 解析请求体，身份提供方使用 application/scim+json，不能依赖 Content-Type 选择绑定方式
+func bindBody(c *gin.Context, out interface{}) bool {
+	data, err := c.GetRawData()
+	if err == nil {
+		err = json.Unmarshal(data, out)
+	}
+	if err != nil {
+		logger.Warnf("SCIM请求体解析失败: %v", err)
+		scimError(c, scim.BadRequest(scim.ErrInvalidSyntax, "请求体不是有效的JSON"))
+		return false
+	}
+	return true
+}
+
+// render 输出 SCIM 响应，按 attributes 和 excludedAttributes 查询参数裁剪资源
+func render(c *gin.Context, status int, body interface{}) {
+	attributes, excluded := c.Query("attributes"), c.Query("excludedAttributes")
+	if attributes != "" || excluded != "" {
+		if list, ok := body.(*scim.ListResponse); ok {
+			for i, resource := range list.Resources {
+				list.Resources[i] = project(resource, attributes, excluded)
+			}
+		} else {
+			body = project(body, attributes, excluded)
+		}
+	}
+	c.Header("Content-Type", scim.ContentType)
+	c.JSON(status, body)
+}
+
+func project(resource interface{}, attributes, excluded string) interface{} {
+	data, err := json.Marshal(resource)
+	if err != nil {
+		return resource
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return resource
+	}
+	scim.Project(m, attributes, excluded)
+	return m
+}
+
+// scimError 输出 SCIM 错误，非协议错误按500处理
+func scimError(c *gin.Context, err error) {
+	var scimErr *scim.Error
+	if !errors.As(err, &scimErr) {
+		logger.Errorf("SCIM请求处理失败: %v", err)
+		scimErr = &scim.Error{Status: http.StatusInternalServerError, Detail: err.Error()}
+	}
+	c.Header("Content-Type", scim.ContentType)
+	c.JSON(scimErr.Status, scimErr.Body())
+}
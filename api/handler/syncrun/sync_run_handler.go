@@ -0,0 +1,133 @@
+package syncrun
+
+import (
+	"domain-admin/internal/repository"
+	"domain-admin/internal/service"
+	"domain-admin/model"
+	"domain-admin/pkg/db"
+	"domain-admin/pkg/logger"
+	"domain-admin/pkg/pagination"
+	"domain-admin/pkg/response"
+	"domain-admin/pkg/validator"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SyncRunHandler 区域同步处理器
+type SyncRunHandler struct {
+	syncService service.SyncService
+}
+
+// NewSyncRunHandler 创建区域同步处理器
+func NewSyncRunHandler() *SyncRunHandler {
+	runRepo := repository.NewSyncRunRepository(db.GetDB("default"))
+	return &SyncRunHandler{
+		syncService: service.NewSyncService(runRepo),
+	}
+}
+
+// ListSyncRuns 获取同步任务列表
+// @Summary 获取同步任务列表
+// @Description 分页获取区域同步任务及其新增、删除、变更统计
+// @Tags 区域同步
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param provider_account query string false "云服务商账号"
+// @Param offset query int false "偏移量" default(0)
+// @Param limit query int false "每页数量" default(10)
+// @Param orderBy query string false "排序字段" default(id)
+// @Param sort query string false "排序方式" default(asc)
+// @Success 200 {object} response.Response{data=pagination.PageResult}
+// @Failure 500 {object} response.Response
+// @Router /api/sync-runs [get]
+func (h *SyncRunHandler) ListSyncRuns(c *gin.Context) {
+	page := pagination.New(c)
+
+	runs, total, err := h.syncService.List(c.Query("provider_account"), page)
+	if err != nil {
+		logger.Errorf("获取同步任务列表失败: %v", err)
+		response.Error(c, http.StatusInternalServerError, "获取同步任务列表失败")
+		return
+	}
+
+	result := pagination.NewPageResult(total, runs)
+	response.Success(c, result)
+}
+
+// GetSyncRun 获取同步任务详情
+// @Summary 获取同步任务详情
+// @Description 获取同步任务及其记录变更明细
+// @Tags 区域同步
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "同步任务ID"
+// @Success 200 {object} response.Response{data=model.SyncRun}
+// @Failure 404 {object} response.Response
+// @Router /api/sync-runs/{id} [get]
+func (h *SyncRunHandler) GetSyncRun(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的同步任务ID")
+		return
+	}
+
+	run, err := h.syncService.GetByID(uint(id))
+	if err != nil {
+		response.Error(c, http.StatusNotFound, "同步任务不存在")
+		return
+	}
+
+	response.Success(c, run)
+}
+
+// TriggerSyncRun 触发同步
+// @Summary 触发同步
+// @Description 立即同步指定云服务商账号，未指定账号时同步全部账号
+// @Tags 区域同步
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.SyncRunRequest false "同步参数"
+// @Success 200 {object} response.Response{data=[]model.SyncRun}
+// @Failure 400 {object} response.Response
+// @Router /api/sync-runs [post]
+func (h *SyncRunHandler) TriggerSyncRun(c *gin.Context) {
+	var req model.SyncRunRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			logger.Warnf("参数绑定失败: %v", err)
+			response.Error(c, http.StatusBadRequest, "参数格式错误")
+			return
+		}
+	}
+
+	// 参数验证
+	if err := validator.ValidateStruct(&req); err != nil {
+		logger.Warnf("参数验证失败: %v", err)
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	uid, _ := c.Get("userID")
+	userID, _ := uid.(uint)
+
+	if req.ProviderAccount == "" {
+		response.Success(c, h.syncService.RunAll(c.Request.Context(), userID))
+		return
+	}
+
+	run, err := h.syncService.Run(c.Request.Context(), req.ProviderAccount, userID)
+	if err != nil && run == nil {
+		logger.Errorf("同步失败: %v", err)
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// 同步失败时任务状态和错误信息已记录在任务中
+	response.Success(c, []*model.SyncRun{run})
+}
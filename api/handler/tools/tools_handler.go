@@ -0,0 +1,102 @@
+package tools
+
+import (
+	"domain-admin/internal/service"
+	"domain-admin/pkg/config"
+	"domain-admin/pkg/logger"
+	"domain-admin/pkg/response"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ToolsHandler 域名工具处理器
+type ToolsHandler struct {
+	whoisService    service.WhoisService
+	dnsCheckService service.DNSCheckService
+}
+
+// NewToolsHandler 创建域名工具处理器
+func NewToolsHandler() *ToolsHandler {
+	return &ToolsHandler{
+		whoisService:    service.NewWhoisService(config.GetConfig().Whois),
+		dnsCheckService: service.NewDNSCheckService(config.GetConfig().DNSCheck),
+	}
+}
+
+// Whois 查询域名注册信息
+// @Summary 查询域名注册信息
+// @Description 通过RDAP查询域名的注册商、注册及到期时间、名称服务器和状态，RDAP不可用时回退到WHOIS
+// @Tags 域名工具
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param domain query string true "域名"
+// @Param refresh query bool false "跳过缓存"
+// @Success 200 {object} response.Response{data=whois.Result}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Router /api/tools/whois [get]
+func (h *ToolsHandler) Whois(c *gin.Context) {
+	domain := c.Query("domain")
+	if domain == "" {
+		response.Error(c, http.StatusBadRequest, "域名不能为空")
+		return
+	}
+	refresh, _ := strconv.ParseBool(c.Query("refresh"))
+
+	result, err := h.whoisService.Lookup(c.Request.Context(), domain, refresh)
+	if err != nil {
+		logger.Warnf("查询域名注册信息失败: %v", err)
+		switch {
+		case strings.Contains(err.Error(), "不存在"):
+			response.Error(c, http.StatusNotFound, err.Error())
+		case strings.Contains(err.Error(), "查询域名注册信息失败"):
+			response.Error(c, http.StatusBadGateway, err.Error())
+		default:
+			response.Error(c, http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// DNSCheck DNS解析诊断
+// @Summary DNS解析诊断
+// @Description 向配置的递归解析器及区域的权威服务器查询同一记录，返回各服务器的应答、TTL、DNSSEC验证(AD)标志以及应答是否一致
+// @Tags 域名工具
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param name query string true "域名"
+// @Param type query string false "记录类型(A,AAAA,CNAME,MX,NS,TXT,SRV,SOA,PTR,CAA)" default(A)
+// @Success 200 {object} response.Response{data=dnscheck.Report}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Router /api/tools/dns-check [get]
+func (h *ToolsHandler) DNSCheck(c *gin.Context) {
+	name := c.Query("name")
+	if name == "" {
+		response.Error(c, http.StatusBadRequest, "域名不能为空")
+		return
+	}
+
+	report, err := h.dnsCheckService.Check(c.Request.Context(), name, c.Query("type"))
+	if err != nil {
+		logger.Warnf("DNS解析诊断失败: %v", err)
+		if strings.Contains(err.Error(), "DNS解析诊断失败") {
+			response.Error(c, http.StatusBadGateway, err.Error())
+			return
+		}
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, report)
+}
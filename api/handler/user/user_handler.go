@@ -1,13 +1,21 @@
 package user
 
 import (
+	"bytes"
+	"domain-admin/internal/repository"
 	"domain-admin/internal/service"
 	"domain-admin/model"
+	"domain-admin/pkg/config"
 	"domain-admin/pkg/db"
 	"domain-admin/pkg/logger"
+	"domain-admin/pkg/middleware"
 	"domain-admin/pkg/pagination"
 	"domain-admin/pkg/response"
 	"domain-admin/pkg/validator"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
 	"strconv"
 	"strings"
 
@@ -16,14 +24,32 @@ import (
 
 // UserHandler 用户处理器
 type UserHandler struct {
-	userService service.UserService
+	userService      service.UserService
+	twoFactorService service.TwoFactorService
+	tokenService     service.AuthTokenService
 }
 
 // NewUserHandler 创建用户处理器
 func NewUserHandler() *UserHandler {
-	userService := service.NewUserService(db.GetDB("default"))
+	database := db.GetDB("default")
+	userService := service.NewUserService(database)
+	twoFactorService := service.NewTwoFactorService(
+		repository.NewTwoFactorRepository(database),
+		repository.NewWebAuthnRepository(database),
+		repository.NewUserRepository(database),
+		repository.NewRoleRepository(database),
+		config.GetConfig().Vault,
+	)
+	tokenService := service.NewAuthTokenService(
+		repository.NewUserSessionRepository(database),
+		repository.NewRefreshTokenRepository(database),
+		repository.NewUserRepository(database),
+		config.GetConfig().JWT.RefreshTTL(),
+	)
 	return &UserHandler{
-		userService: userService,
+		userService:      userService,
+		twoFactorService: twoFactorService,
+		tokenService:     tokenService,
 	}
 }
 
@@ -155,6 +181,17 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 		return
 	}
 
+	// 修改角色或状态需要管理权限，角色未变化时不做额外校验
+	roleChanged := req.Role != ""
+	if obj := middleware.GetObject(c); obj != nil && obj.Role == req.Role {
+		roleChanged = false
+	}
+	if roleChanged || req.Status != nil {
+		if !authorizeManagement(c, req.Role, "无权修改用户角色或状态") {
+			return
+		}
+	}
+
 	user, err := h.userService.UpdateUser(uint(id), &req)
 	if err != nil {
 		logger.Errorf("更新用户失败: %v", err)
@@ -171,7 +208,7 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 
 // DeleteUser 删除用户（管理员功能）
 // @Summary 删除用户
-// @Description 删除用户，仅管理员可访问
+// @Description 删除用户并吊销其全部登录会话，仅管理员可访问
 // @Tags 用户管理
 // @Accept json
 // @Produce json
@@ -197,13 +234,14 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 		}
 		return
 	}
+	h.revokeSessions(uint(id))
 
 	response.Success(c, gin.H{"message": "删除成功"})
 }
 
 // UpdateUserStatus 更新用户状态（管理员功能）
 // @Summary 更新用户状态
-// @Description 启用或禁用用户，仅管理员可访问
+// @Description 启用或禁用用户，禁用时吊销其全部登录会话，仅管理员可访问
 // @Tags 用户管理
 // @Accept json
 // @Produce json
@@ -237,6 +275,10 @@ func (h *UserHandler) UpdateUserStatus(c *gin.Context) {
 		return
 	}
 
+	if !authorizeManagement(c, "", "无权修改用户角色或状态") {
+		return
+	}
+
 	if err := h.userService.UpdateUserStatus(uint(id), req.Status); err != nil {
 		logger.Errorf("更新用户状态失败: %v", err)
 		if strings.Contains(err.Error(), "用户不存在") {
@@ -250,6 +292,127 @@ func (h *UserHandler) UpdateUserStatus(c *gin.Context) {
 	statusText := "启用"
 	if req.Status == 0 {
 		statusText = "禁用"
+		h.revokeSessions(uint(id))
 	}
 	response.Success(c, gin.H{"message": statusText + "成功"})
-}
\ No newline at end of file
+}
+
+// ResetTwoFactor 重置用户的两步验证（管理员功能）
+// @Summary 重置两步验证
+// @Description 清除用户的两步验证密钥、恢复码和安全密钥，用于用户丢失验证器时恢复登录。角色要求两步验证时，用户下次登录需重新绑定
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /api/users/{id}/2fa [delete]
+func (h *UserHandler) ResetTwoFactor(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		response.Error(c, 400, "用户ID格式错误")
+		return
+	}
+
+	// 重置后可绕过两步验证登录，不能通过“仅限本人”条件重置自己的两步验证
+	if !authorizeManagement(c, "", "无权重置该用户的两步验证") {
+		return
+	}
+
+	if err := h.twoFactorService.Reset(uint(id)); err != nil {
+		logger.Errorf("重置两步验证失败: %v", err)
+		if strings.Contains(err.Error(), "用户不存在") {
+			response.Error(c, 404, err.Error())
+		} else {
+			response.Error(c, 400, err.Error())
+		}
+		return
+	}
+
+	response.Success(c, gin.H{"message": "重置成功"})
+}
+
+// revokeSessions 用户被禁用或删除后吊销其全部会话和刷新令牌
+func (h *UserHandler) revokeSessions(userID uint) {
+	if _, err := h.tokenService.RevokeUser(userID); err != nil {
+		logger.Warnf("吊销用户 %d 的会话失败: %v", userID, err)
+	}
+}
+
+// ResolveUser 加载路径中的目标用户属性，供RBAC中间件判断本人及下级角色条件
+func (h *UserHandler) ResolveUser(c *gin.Context) (*middleware.ObjectAttributes, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return nil, nil
+	}
+
+	user, err := h.userService.GetUserByID(uint(id))
+	if err != nil {
+		if strings.Contains(err.Error(), "用户不存在") {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &middleware.ObjectAttributes{OwnerID: user.ID, Role: user.Role}, nil
+}
+
+// ResolveNewUser 从创建用户的请求体中读取目标角色，供RBAC中间件判断下级角色条件
+func (h *UserHandler) ResolveNewUser(c *gin.Context) (*middleware.ObjectAttributes, error) {
+	if c.Request.Method != http.MethodPost || c.Request.Body == nil {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return nil, err
+	}
+	// 还原请求体，处理器仍需完整绑定参数
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	var req struct {
+		Role string `json:"role"`
+	}
+	_ = json.Unmarshal(body, &req)
+	if req.Role == "" {
+		req.Role = "user"
+	}
+	return &middleware.ObjectAttributes{Role: req.Role}, nil
+}
+
+// authorizeManagement 修改用户角色、状态或重置两步验证属于管理操作，不适用“仅限本人”条件
+// 以修改后的角色（未修改时为用户当前角色）作为目标对象重新鉴权，防止用户提升自己或他人的角色
+func authorizeManagement(c *gin.Context, role, deniedMessage string) bool {
+	if role == "" {
+		if obj := middleware.GetObject(c); obj != nil {
+			role = obj.Role
+		}
+	}
+
+	allowed, err := middleware.Authorize(c, &middleware.ObjectAttributes{Role: role})
+	if err != nil {
+		logger.Errorf("权限校验失败: %v", err)
+		response.Error(c, 500, "权限校验失败")
+		return false
+	}
+	if !allowed {
+		response.Error(c, 403, deniedMessage)
+		return false
+	}
+	return true
+}
+
+// DiffDeleteUser 删除用户的变更内容，供审批人查看被删除的用户
+func (h *UserHandler) DiffDeleteUser(c *gin.Context, body []byte) (interface{}, interface{}, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return nil, nil, errors.New("用户ID格式错误")
+	}
+	user, err := h.userService.GetUserByID(uint(id))
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, nil, nil
+}
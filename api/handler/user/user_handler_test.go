@@ -0,0 +1,87 @@
+package user
+
+import (
+	"domain-admin/internal/service"
+	"domain-admin/model"
+	"domain-admin/pkg/logger"
+	"io"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+func newTestUserHandler(t *testing.T) *UserHandler {
+	t.Helper()
+	logger.Log = zap.NewNop()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger:         gormlogger.Discard,
+		NamingStrategy: schema.NamingStrategy{TablePrefix: "domain_", SingularTable: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&model.User{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&model.User{Username: "alice", Email: "alice@example.com", Password: "x", Role: "manager", Status: 1}).Error; err != nil {
+		t.Fatal(err)
+	}
+	return &UserHandler{userService: service.NewUserService(db)}
+}
+
+func newTestContext(method, body string, params ...gin.Param) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(method, "/api/users", strings.NewReader(body))
+	c.Params = params
+	return c
+}
+
+func TestResolveUser(t *testing.T) {
+	h := newTestUserHandler(t)
+
+	obj, err := h.ResolveUser(newTestContext("GET", "", gin.Param{Key: "id", Value: "1"}))
+	if err != nil || obj == nil || obj.OwnerID != 1 || obj.Role != "manager" {
+		t.Fatalf("ResolveUser = %+v, %v", obj, err)
+	}
+
+	// 用户不存在或ID格式错误时没有目标对象，带条件的策略不生效
+	for _, id := range []string{"99", "abc"} {
+		obj, err := h.ResolveUser(newTestContext("GET", "", gin.Param{Key: "id", Value: id}))
+		if err != nil || obj != nil {
+			t.Fatalf("ResolveUser(%s) = %+v, %v", id, obj, err)
+		}
+	}
+}
+
+func TestResolveNewUser(t *testing.T) {
+	h := newTestUserHandler(t)
+
+	body := `{"username":"bob","role":"admin"}`
+	c := newTestContext("POST", body)
+	obj, err := h.ResolveNewUser(c)
+	if err != nil || obj == nil || obj.Role != "admin" || obj.OwnerID != 0 {
+		t.Fatalf("ResolveNewUser = %+v, %v", obj, err)
+	}
+	// 处理器仍能读取完整的请求体
+	if restored, _ := io.ReadAll(c.Request.Body); string(restored) != body {
+		t.Fatalf("request body = %q", restored)
+	}
+
+	// 未指定角色时按默认角色判断
+	obj, err = h.ResolveNewUser(newTestContext("POST", `{"username":"bob"}`))
+	if err != nil || obj == nil || obj.Role != "user" {
+		t.Fatalf("ResolveNewUser without role = %+v, %v", obj, err)
+	}
+
+	if obj, err := h.ResolveNewUser(newTestContext("GET", "")); err != nil || obj != nil {
+		t.Fatalf("ResolveNewUser for list = %+v, %v", obj, err)
+	}
+}
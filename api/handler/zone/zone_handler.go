@@ -0,0 +1,468 @@
+package zone
+
+import (
+	"domain-admin/internal/repository"
+	"domain-admin/internal/service"
+	"domain-admin/model"
+	"domain-admin/pkg/db"
+	"domain-admin/pkg/logger"
+	"domain-admin/pkg/pagination"
+	"domain-admin/pkg/response"
+	"domain-admin/pkg/validator"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ZoneHandler DNS区域及记录处理器
+type ZoneHandler struct {
+	zoneService     service.DNSZoneService
+	recordService   service.DNSRecordService
+	zoneFileService service.ZoneFileService
+}
+
+// NewZoneHandler 创建DNS区域处理器
+func NewZoneHandler() *ZoneHandler {
+	zoneRepo := repository.NewDNSZoneRepository(db.GetDB("default"))
+	recordRepo := repository.NewDNSRecordRepository(db.GetDB("default"))
+	domainRepo := repository.NewDomainRepository(db.GetDB("default"))
+
+	return &ZoneHandler{
+		zoneService:     service.NewDNSZoneService(zoneRepo, domainRepo),
+		recordService:   service.NewDNSRecordService(zoneRepo, recordRepo),
+		zoneFileService: service.NewZoneFileService(zoneRepo, recordRepo),
+	}
+}
+
+// CreateZone 创建区域
+// @Summary 创建区域
+// @Description 创建新的DNS区域
+// @Tags DNS管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.DNSZoneCreateRequest true "区域信息"
+// @Success 200 {object} response.Response{data=model.DNSZone}
+// @Failure 400 {object} response.Response
+// @Router /api/zones [post]
+func (h *ZoneHandler) CreateZone(c *gin.Context) {
+	var req model.DNSZoneCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Warnf("参数绑定失败: %v", err)
+		response.Error(c, http.StatusBadRequest, "参数格式错误")
+		return
+	}
+
+	// 参数验证
+	if err := validator.ValidateStruct(&req); err != nil {
+		logger.Warnf("参数验证失败: %v", err)
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	zone, err := h.zoneService.Create(&req)
+	if err != nil {
+		logger.Errorf("创建区域失败: %v", err)
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, zone)
+}
+
+// GetZone 获取区域详情
+// @Summary 获取区域详情
+// @Description 根据ID获取DNS区域详情
+// @Tags DNS管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "区域ID"
+// @Success 200 {object} response.Response{data=model.DNSZone}
+// @Failure 404 {object} response.Response
+// @Router /api/zones/{id} [get]
+func (h *ZoneHandler) GetZone(c *gin.Context) {
+	id, ok := parseID(c, "id", "无效的区域ID")
+	if !ok {
+		return
+	}
+
+	zone, err := h.zoneService.GetByID(id)
+	if err != nil {
+		response.Error(c, http.StatusNotFound, "区域不存在")
+		return
+	}
+
+	response.Success(c, zone)
+}
+
+// UpdateZone 更新区域
+// @Summary 更新区域
+// @Description 更新DNS区域信息
+// @Tags DNS管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "区域ID"
+// @Param request body model.DNSZoneUpdateRequest true "更新信息"
+// @Success 200 {object} response.Response{data=model.DNSZone}
+// @Failure 400 {object} response.Response
+// @Router /api/zones/{id} [put]
+func (h *ZoneHandler) UpdateZone(c *gin.Context) {
+	id, ok := parseID(c, "id", "无效的区域ID")
+	if !ok {
+		return
+	}
+
+	var req model.DNSZoneUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Warnf("参数绑定失败: %v", err)
+		response.Error(c, http.StatusBadRequest, "参数格式错误")
+		return
+	}
+
+	// 参数验证
+	if err := validator.ValidateStruct(&req); err != nil {
+		logger.Warnf("参数验证失败: %v", err)
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	zone, err := h.zoneService.Update(id, &req)
+	if err != nil {
+		logger.Errorf("更新区域失败: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, zone)
+}
+
+// DeleteZone 删除区域
+// @Summary 删除区域
+// @Description 删除DNS区域及其全部记录
+// @Tags DNS管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "区域ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Router /api/zones/{id} [delete]
+func (h *ZoneHandler) DeleteZone(c *gin.Context) {
+	id, ok := parseID(c, "id", "无效的区域ID")
+	if !ok {
+		return
+	}
+
+	if err := h.zoneService.Delete(id); err != nil {
+		logger.Errorf("删除区域失败: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// ListZones 获取区域列表
+// @Summary 获取区域列表
+// @Description 分页获取DNS区域列表
+// @Tags DNS管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param offset query int false "偏移量" default(0)
+// @Param limit query int false "每页数量" default(10)
+// @Param orderBy query string false "排序字段" default(id)
+// @Param sort query string false "排序方式" default(asc)
+// @Success 200 {object} response.Response{data=pagination.PageResult}
+// @Failure 500 {object} response.Response
+// @Router /api/zones [get]
+func (h *ZoneHandler) ListZones(c *gin.Context) {
+	page := pagination.New(c)
+
+	zones, total, err := h.zoneService.List(page)
+	if err != nil {
+		logger.Errorf("获取区域列表失败: %v", err)
+		response.Error(c, http.StatusInternalServerError, "获取区域列表失败")
+		return
+	}
+
+	result := pagination.NewPageResult(total, zones)
+	response.Success(c, result)
+}
+
+// ListRecords 获取记录列表
+// @Summary 获取记录列表
+// @Description 分页获取区域下的DNS记录
+// @Tags DNS管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "区域ID"
+// @Param offset query int false "偏移量" default(0)
+// @Param limit query int false "每页数量" default(10)
+// @Param orderBy query string false "排序字段" default(id)
+// @Param sort query string false "排序方式" default(asc)
+// @Success 200 {object} response.Response{data=pagination.PageResult}
+// @Failure 404 {object} response.Response
+// @Router /api/zones/{id}/records [get]
+func (h *ZoneHandler) ListRecords(c *gin.Context) {
+	zoneID, ok := parseID(c, "id", "无效的区域ID")
+	if !ok {
+		return
+	}
+
+	page := pagination.New(c)
+	records, total, err := h.recordService.List(zoneID, page)
+	if err != nil {
+		logger.Errorf("获取记录列表失败: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	result := pagination.NewPageResult(total, records)
+	response.Success(c, result)
+}
+
+// GetRecord 获取记录详情
+// @Summary 获取记录详情
+// @Description 获取区域下指定DNS记录
+// @Tags DNS管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "区域ID"
+// @Param record_id path int true "记录ID"
+// @Success 200 {object} response.Response{data=model.DNSRecord}
+// @Failure 404 {object} response.Response
+// @Router /api/zones/{id}/records/{record_id} [get]
+func (h *ZoneHandler) GetRecord(c *gin.Context) {
+	zoneID, ok := parseID(c, "id", "无效的区域ID")
+	if !ok {
+		return
+	}
+	recordID, ok := parseID(c, "record_id", "无效的记录ID")
+	if !ok {
+		return
+	}
+
+	record, err := h.recordService.GetByID(zoneID, recordID)
+	if err != nil {
+		response.Error(c, http.StatusNotFound, "记录不存在")
+		return
+	}
+
+	response.Success(c, record)
+}
+
+// CreateRecord 创建记录
+// @Summary 创建记录
+// @Description 在区域下创建DNS记录
+// @Tags DNS管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "区域ID"
+// @Param request body model.DNSRecordRequest true "记录信息"
+// @Success 200 {object} response.Response{data=model.DNSRecord}
+// @Failure 400 {object} response.Response
+// @Router /api/zones/{id}/records [post]
+func (h *ZoneHandler) CreateRecord(c *gin.Context) {
+	zoneID, ok := parseID(c, "id", "无效的区域ID")
+	if !ok {
+		return
+	}
+
+	req, ok := bindRecordRequest(c)
+	if !ok {
+		return
+	}
+
+	record, err := h.recordService.Create(zoneID, req)
+	if err != nil {
+		logger.Errorf("创建记录失败: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, record)
+}
+
+// UpdateRecord 更新记录
+// @Summary 更新记录
+// @Description 更新区域下的DNS记录
+// @Tags DNS管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "区域ID"
+// @Param record_id path int true "记录ID"
+// @Param request body model.DNSRecordRequest true "记录信息"
+// @Success 200 {object} response.Response{data=model.DNSRecord}
+// @Failure 400 {object} response.Response
+// @Router /api/zones/{id}/records/{record_id} [put]
+func (h *ZoneHandler) UpdateRecord(c *gin.Context) {
+	zoneID, ok := parseID(c, "id", "无效的区域ID")
+	if !ok {
+		return
+	}
+	recordID, ok := parseID(c, "record_id", "无效的记录ID")
+	if !ok {
+		return
+	}
+
+	req, ok := bindRecordRequest(c)
+	if !ok {
+		return
+	}
+
+	record, err := h.recordService.Update(zoneID, recordID, req)
+	if err != nil {
+		logger.Errorf("更新记录失败: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, record)
+}
+
+// DeleteRecord 删除记录
+// @Summary 删除记录
+// @Description 删除区域下的DNS记录
+// @Tags DNS管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "区域ID"
+// @Param record_id path int true "记录ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Router /api/zones/{id}/records/{record_id} [delete]
+func (h *ZoneHandler) DeleteRecord(c *gin.Context) {
+	zoneID, ok := parseID(c, "id", "无效的区域ID")
+	if !ok {
+		return
+	}
+	recordID, ok := parseID(c, "record_id", "无效的记录ID")
+	if !ok {
+		return
+	}
+
+	if err := h.recordService.Delete(zoneID, recordID); err != nil {
+		logger.Errorf("删除记录失败: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// ImportZone 导入区域文件
+// @Summary 导入区域文件
+// @Description 从BIND区域文件导入DNS记录，dry_run为true时只返回预览结果
+// @Tags DNS管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "区域ID"
+// @Param request body model.ZoneImportRequest true "区域文件内容"
+// @Success 200 {object} response.Response{data=model.ZoneImportResult}
+// @Failure 400 {object} response.Response
+// @Router /api/zones/{id}/import [post]
+func (h *ZoneHandler) ImportZone(c *gin.Context) {
+	zoneID, ok := parseID(c, "id", "无效的区域ID")
+	if !ok {
+		return
+	}
+
+	var req model.ZoneImportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Warnf("参数绑定失败: %v", err)
+		response.Error(c, http.StatusBadRequest, "参数格式错误")
+		return
+	}
+
+	// 参数验证
+	if err := validator.ValidateStruct(&req); err != nil {
+		logger.Warnf("参数验证失败: %v", err)
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := h.zoneFileService.Import(zoneID, &req)
+	if err != nil {
+		logger.Errorf("导入区域文件失败: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// ExportZone 导出区域文件
+// @Summary 导出区域文件
+// @Description 将DNS区域导出为BIND区域文件
+// @Tags DNS管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "区域ID"
+// @Success 200 {object} response.Response{data=model.ZoneExportResult}
+// @Failure 404 {object} response.Response
+// @Router /api/zones/{id}/export [get]
+func (h *ZoneHandler) ExportZone(c *gin.Context) {
+	zoneID, ok := parseID(c, "id", "无效的区域ID")
+	if !ok {
+		return
+	}
+
+	result, err := h.zoneFileService.Export(zoneID)
+	if err != nil {
+		logger.Errorf("导出区域文件失败: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// bindRecordRequest 绑定并校验记录请求
+func bindRecordRequest(c *gin.Context) (*model.DNSRecordRequest, bool) {
+	var req model.DNSRecordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Warnf("参数绑定失败: %v", err)
+		response.Error(c, http.StatusBadRequest, "参数格式错误")
+		return nil, false
+	}
+
+	// 记录类型统一为大写后再做基础校验，各类型的具体规则由服务层校验
+	req.Type = strings.ToUpper(strings.TrimSpace(req.Type))
+	if err := validator.ValidateStruct(&req); err != nil {
+		logger.Warnf("参数验证失败: %v", err)
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+	return &req, true
+}
+
+// parseID 解析路径中的ID参数
+func parseID(c *gin.Context, name, msg string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, msg)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// respondError 根据错误信息返回404或400
+func respondError(c *gin.Context, err error) {
+	if strings.Contains(err.Error(), "区域不存在") || strings.Contains(err.Error(), "记录不存在") {
+		response.Error(c, http.StatusNotFound, err.Error())
+		return
+	}
+	response.Error(c, http.StatusBadRequest, err.Error())
+}
@@ -1,12 +1,27 @@
 package api
 
 import (
+	"domain-admin/api/handler/acme"
+	"domain-admin/api/handler/apitoken"
 	"domain-admin/api/handler/auth"
+	"domain-admin/api/handler/certificate"
+	"domain-admin/api/handler/changerequest"
 	"domain-admin/api/handler/dashboard"
+	"domain-admin/api/handler/domain"
+	"domain-admin/api/handler/job"
+	"domain-admin/api/handler/jwtkey"
+	"domain-admin/api/handler/oauth"
 	"domain-admin/api/handler/permission"
+	"domain-admin/api/handler/provideraccount"
 	"domain-admin/api/handler/role"
+	"domain-admin/api/handler/scim"
+	"domain-admin/api/handler/syncrun"
+	"domain-admin/api/handler/tools"
 	"domain-admin/api/handler/user"
+	"domain-admin/api/handler/zone"
+	"domain-admin/pkg/config"
 	"domain-admin/pkg/middleware"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -18,6 +33,67 @@ func RegisterRoutes(r *gin.Engine) {
 	roleHandler := role.NewRoleHandler()
 	permissionHandler := permission.NewPermissionHandler()
 	dashboardHandler := dashboard.NewDashboardHandler()
+	domainHandler := domain.NewDomainHandler()
+	zoneHandler := zone.NewZoneHandler()
+	syncRunHandler := syncrun.NewSyncRunHandler()
+	jobHandler := job.NewJobHandler()
+	toolsHandler := tools.NewToolsHandler()
+	certificateHandler := certificate.NewCertificateHandler()
+	acmeHandler := acme.NewAcmeHandler()
+	providerAccountHandler := provideraccount.NewProviderAccountHandler()
+	changeRequestHandler := changerequest.NewChangeRequestHandler()
+	apiTokenHandler := apitoken.NewAPITokenHandler()
+	jwtKeyHandler := jwtkey.NewJWTKeyHandler()
+	oauthHandler := oauth.NewOAuthHandler()
+	scimHandler := scim.NewSCIMHandler()
+
+	// 登录会话：JWTAuth 按访问令牌的 jti 校验会话是否已吊销
+	middleware.SetSessionValidator(authHandler.ValidateSession)
+
+	// API令牌：JWTAuth 将带有令牌前缀的凭据交给令牌处理器校验
+	middleware.SetTokenAuthenticator(apiTokenHandler.Authenticate)
+	middleware.SetTokenLoader(apiTokenHandler.Load)
+
+	// 变更审批：配置的路由提交为变更请求，审批通过后由路由引擎以提交人身份重放
+	middleware.InitChangeApproval(config.GetConfig().Approval.Routes, r, changeRequestHandler.Submit)
+	middleware.RegisterChangeDiffer("DELETE", "/api/users/:id", userHandler.DiffDeleteUser)
+	middleware.RegisterChangeDiffer("DELETE", "/api/roles/:id", roleHandler.DiffDeleteRole)
+	middleware.RegisterChangeDiffer("POST", "/api/roles/:id/permissions", roleHandler.DiffAssignPermissions)
+
+	// 验证访问令牌的公钥（无需登录）
+	r.GET("/.well-known/jwks.json", jwtKeyHandler.JWKS)
+
+	// OAuth2/OpenID Connect 授权服务协议端点（无需登录，应用使用客户端凭据或访问令牌认证）
+	r.GET("/.well-known/openid-configuration", oauthHandler.Discovery)
+	oauthEndpoints := r.Group("/oauth")
+	{
+		oauthEndpoints.GET("/authorize", oauthHandler.Authorize)
+		oauthEndpoints.POST("/token", oauthHandler.Token)
+		oauthEndpoints.POST("/introspect", oauthHandler.Introspect)
+		oauthEndpoints.POST("/revoke", oauthHandler.Revoke)
+		oauthEndpoints.GET("/userinfo", oauthHandler.UserInfo)
+		oauthEndpoints.POST("/userinfo", oauthHandler.UserInfo)
+	}
+
+	// SCIM 2.0 用户和用户组同步（身份提供方使用具有SCIM权限的API令牌），服务配置无需认证
+	r.GET("/scim/v2/ServiceProviderConfig", scimHandler.ServiceProviderConfig)
+	r.GET("/scim/v2/ResourceTypes", scimHandler.ResourceTypes)
+	scimRoutes := r.Group("/scim/v2")
+	scimRoutes.Use(middleware.JWTAuth(), middleware.RBACMiddleware())
+	{
+		scimRoutes.GET("/Users", scimHandler.ListUsers)
+		scimRoutes.GET("/Users/:id", scimHandler.GetUser)
+		scimRoutes.POST("/Users", scimHandler.CreateUser)
+		scimRoutes.PUT("/Users/:id", scimHandler.ReplaceUser)
+		scimRoutes.PATCH("/Users/:id", scimHandler.PatchUser)
+		scimRoutes.DELETE("/Users/:id", scimHandler.DeleteUser)
+		scimRoutes.GET("/Groups", scimHandler.ListGroups)
+		scimRoutes.GET("/Groups/:id", scimHandler.GetGroup)
+		scimRoutes.POST("/Groups", scimHandler.CreateGroup)
+		scimRoutes.PUT("/Groups/:id", scimHandler.ReplaceGroup)
+		scimRoutes.PATCH("/Groups/:id", scimHandler.PatchGroup)
+		scimRoutes.DELETE("/Groups/:id", scimHandler.DeleteGroup)
+	}
 
 	// API 路由组
 	api := r.Group("/api")
@@ -27,17 +103,54 @@ func RegisterRoutes(r *gin.Engine) {
 		{
 			auth.POST("/register", authHandler.Register)
 			auth.POST("/login", authHandler.Login)
+			auth.POST("/refresh", authHandler.Refresh)
+			auth.POST("/login/2fa", authHandler.LoginTwoFactor)
+			auth.POST("/login/2fa/setup", authHandler.SetupLoginTwoFactor)
+			// 找回密码和邮箱验证
+			auth.POST("/forgot-password", authHandler.ForgotPassword)
+			auth.POST("/reset-password", authHandler.ResetPassword)
+			auth.POST("/verify-email", authHandler.VerifyEmail)
+			auth.POST("/verify-email/resend", authHandler.ResendVerification)
 			// 登出需要认证
 			auth.POST("/logout", middleware.JWTAuth(), authHandler.Logout)
 			// 个人资料相关（需要认证）
 			auth.GET("/profile", middleware.JWTAuth(), authHandler.GetProfile)
-			auth.PUT("/profile", middleware.JWTAuth(), authHandler.UpdateProfile)
-			auth.PUT("/password", middleware.JWTAuth(), authHandler.UpdateProfile) // 修改密码接口
+			// 修改资料和密码需要登录会话，不能使用API令牌
+			auth.PUT("/profile", middleware.JWTAuth(), middleware.RequireSession(), authHandler.UpdateProfile)
+			auth.PUT("/password", middleware.JWTAuth(), middleware.RequireSession(), authHandler.UpdateProfile) // 修改密码接口
+			// 登录会话管理（需要登录会话，不能使用API令牌）
+			auth.GET("/sessions", middleware.JWTAuth(), middleware.RequireSession(), authHandler.ListSessions)
+			auth.DELETE("/sessions", middleware.JWTAuth(), middleware.RequireSession(), authHandler.RevokeOtherSessions)
+			auth.DELETE("/sessions/:id", middleware.JWTAuth(), middleware.RequireSession(), authHandler.RevokeSession)
+			// 两步验证（需要登录会话，不能使用API令牌）
+			auth.GET("/2fa", middleware.JWTAuth(), middleware.RequireSession(), authHandler.GetTwoFactorStatus)
+			auth.POST("/2fa/setup", middleware.JWTAuth(), middleware.RequireSession(), authHandler.SetupTwoFactor)
+			auth.POST("/2fa/confirm", middleware.JWTAuth(), middleware.RequireSession(), authHandler.ConfirmTwoFactor)
+			auth.POST("/2fa/recovery-codes", middleware.JWTAuth(), middleware.RequireSession(), authHandler.RegenerateRecoveryCodes)
+			auth.DELETE("/2fa", middleware.JWTAuth(), middleware.RequireSession(), authHandler.DisableTwoFactor)
+			// WebAuthn安全密钥：无密码登录或密码登录后的第二步验证
+			// 安全密钥的注册和管理需要登录会话，不能使用API令牌
+			auth.POST("/webauthn/register/begin", middleware.JWTAuth(), middleware.RequireSession(), authHandler.BeginWebAuthnRegistration)
+			auth.POST("/webauthn/register/finish", middleware.JWTAuth(), middleware.RequireSession(), authHandler.FinishWebAuthnRegistration)
+			auth.GET("/webauthn/credentials", middleware.JWTAuth(), middleware.RequireSession(), authHandler.ListWebAuthnCredentials)
+			auth.DELETE("/webauthn/credentials/:id", middleware.JWTAuth(), middleware.RequireSession(), authHandler.DeleteWebAuthnCredential)
+			auth.POST("/webauthn/login/begin", authHandler.BeginWebAuthnLogin)
+			auth.POST("/webauthn/login/finish", authHandler.FinishWebAuthnLogin)
+			auth.POST("/webauthn/2fa/begin", authHandler.BeginWebAuthnTwoFactor)
+			auth.POST("/webauthn/2fa/finish", authHandler.FinishWebAuthnTwoFactor)
+
+			auth.GET("/oidc/providers", authHandler.ListOIDCProviders)
+			auth.GET("/oidc/:provider/login", authHandler.OIDCLogin)
+			auth.GET("/oidc/:provider/callback", authHandler.OIDCCallback)
 		}
 
-		// 用户管理路由（需要认证和权限）
+		// 用户管理路由（需要认证和权限），加载目标用户以支持本人及下级角色条件
+		middleware.RegisterObjectResolver("/api/users", userHandler.ResolveNewUser)
+		middleware.RegisterObjectResolver("/api/users/:id", userHandler.ResolveUser)
+		middleware.RegisterObjectResolver("/api/users/:id/status", userHandler.ResolveUser)
+		middleware.RegisterObjectResolver("/api/users/:id/2fa", userHandler.ResolveUser)
 		users := api.Group("/users")
-		users.Use(middleware.JWTAuth(), middleware.RBACMiddleware())
+		users.Use(middleware.JWTAuth(), middleware.RBACMiddleware(), middleware.ChangeApproval())
 		{
 			users.GET("", userHandler.GetUserList)
 			users.GET("/:id", userHandler.GetUserByID)
@@ -45,11 +158,12 @@ func RegisterRoutes(r *gin.Engine) {
 			users.PUT("/:id", userHandler.UpdateUser)
 			users.DELETE("/:id", userHandler.DeleteUser)
 			users.PUT("/:id/status", userHandler.UpdateUserStatus)
+			users.DELETE("/:id/2fa", userHandler.ResetTwoFactor)
 		}
 
 		// 角色管理路由（需要认证和权限）
 		roles := api.Group("/roles")
-		roles.Use(middleware.JWTAuth(), middleware.RBACMiddleware())
+		roles.Use(middleware.JWTAuth(), middleware.RBACMiddleware(), middleware.ChangeApproval())
 		{
 			roles.GET("", roleHandler.ListRoles)
 			roles.GET("/:id", roleHandler.GetRole)
@@ -59,11 +173,13 @@ func RegisterRoutes(r *gin.Engine) {
 			roles.PUT("/:id/status", roleHandler.UpdateRoleStatus)
 			roles.GET("/:id/permissions", roleHandler.GetRolePermissions)
 			roles.POST("/:id/permissions", roleHandler.AssignPermissions)
+			roles.GET("/:id/history", roleHandler.GetRoleHistory)
+			roles.POST("/:id/rollback", roleHandler.RollbackRole)
 		}
 
 		// 权限管理路由（需要认证和权限）
 		permissions := api.Group("/permissions")
-		permissions.Use(middleware.JWTAuth(), middleware.RBACMiddleware())
+		permissions.Use(middleware.JWTAuth(), middleware.RBACMiddleware(), middleware.ChangeApproval())
 		{
 			permissions.GET("", permissionHandler.ListPermissions)
 			permissions.GET("/:id", permissionHandler.GetPermission)
@@ -73,9 +189,148 @@ func RegisterRoutes(r *gin.Engine) {
 			permissions.PUT("/:id/status", permissionHandler.UpdatePermissionStatus)
 		}
 
-		// 仪表盘统计路由（需要认证）
+		// 域名管理路由（需要认证和权限）
+		domains := api.Group("/domains")
+		domains.Use(middleware.JWTAuth(), middleware.RBACMiddleware(), middleware.ChangeApproval())
+		{
+			domains.GET("", domainHandler.ListDomains)
+			domains.GET("/:id", domainHandler.GetDomain)
+			domains.POST("", domainHandler.CreateDomain)
+			domains.PUT("/:id", domainHandler.UpdateDomain)
+			domains.DELETE("/:id", domainHandler.DeleteDomain)
+		}
+
+		// DNS区域及记录管理路由（需要认证和权限）
+		zones := api.Group("/zones")
+		zones.Use(middleware.JWTAuth(), middleware.RBACMiddleware(), middleware.ChangeApproval())
+		{
+			zones.GET("", zoneHandler.ListZones)
+			zones.GET("/:id", zoneHandler.GetZone)
+			zones.POST("", zoneHandler.CreateZone)
+			zones.PUT("/:id", zoneHandler.UpdateZone)
+			zones.DELETE("/:id", zoneHandler.DeleteZone)
+			zones.GET("/:id/records", zoneHandler.ListRecords)
+			zones.GET("/:id/records/:record_id", zoneHandler.GetRecord)
+			zones.POST("/:id/records", zoneHandler.CreateRecord)
+			zones.PUT("/:id/records/:record_id", zoneHandler.UpdateRecord)
+			zones.DELETE("/:id/records/:record_id", zoneHandler.DeleteRecord)
+			zones.POST("/:id/import", zoneHandler.ImportZone)
+			zones.GET("/:id/export", zoneHandler.ExportZone)
+		}
+
+		// 区域同步路由（需要认证和权限）
+		syncRuns := api.Group("/sync-runs")
+		syncRuns.Use(middleware.JWTAuth(), middleware.RBACMiddleware(), middleware.ChangeApproval())
+		{
+			syncRuns.GET("", syncRunHandler.ListSyncRuns)
+			syncRuns.GET("/:id", syncRunHandler.GetSyncRun)
+			syncRuns.POST("", syncRunHandler.TriggerSyncRun)
+		}
+
+		// 定时任务路由（需要认证和权限）
+		jobs := api.Group("/jobs")
+		jobs.Use(middleware.JWTAuth(), middleware.RBACMiddleware(), middleware.ChangeApproval())
+		{
+			jobs.GET("", jobHandler.ListJobs)
+			jobs.GET("/:name/runs", jobHandler.ListJobRuns)
+			jobs.POST("/:name/trigger", jobHandler.TriggerJob)
+			jobs.POST("/:name/pause", jobHandler.PauseJob)
+			jobs.POST("/:name/resume", jobHandler.ResumeJob)
+		}
+
+		// 证书管理路由（需要认证和权限）
+		certificates := api.Group("/certificates")
+		certificates.Use(middleware.JWTAuth(), middleware.RBACMiddleware(), middleware.ChangeApproval())
+		{
+			certificates.GET("", certificateHandler.ListCertificates)
+			certificates.GET("/:id", certificateHandler.GetCertificate)
+			certificates.POST("", certificateHandler.CreateCertificate)
+			certificates.PUT("/:id", certificateHandler.UpdateCertificate)
+			certificates.DELETE("/:id", certificateHandler.DeleteCertificate)
+			certificates.POST("/:id/probe", certificateHandler.ProbeCertificate)
+		}
+
+		// ACME证书签发路由（需要认证和权限）
+		acmeCerts := api.Group("/acme/certificates")
+		acmeCerts.Use(middleware.JWTAuth(), middleware.RBACMiddleware(), middleware.ChangeApproval())
+		{
+			acmeCerts.GET("", acmeHandler.ListCertificates)
+			acmeCerts.GET("/:id", acmeHandler.GetCertificate)
+			acmeCerts.POST("", acmeHandler.CreateCertificate)
+			acmeCerts.PUT("/:id", acmeHandler.UpdateCertificate)
+			acmeCerts.DELETE("/:id", acmeHandler.DeleteCertificate)
+			acmeCerts.POST("/:id/renew", acmeHandler.RenewCertificate)
+			acmeCerts.GET("/:id/download", acmeHandler.DownloadCertificate)
+		}
+
+		// 云服务商账号路由
+		providerAccounts := api.Group("/provider-accounts")
+		providerAccounts.Use(middleware.JWTAuth(), middleware.RBACMiddleware(), middleware.ChangeApproval())
+		{
+			providerAccounts.GET("", providerAccountHandler.ListAccounts)
+			providerAccounts.GET("/:id", providerAccountHandler.GetAccount)
+			providerAccounts.POST("", providerAccountHandler.CreateAccount)
+			providerAccounts.PUT("/:id", providerAccountHandler.UpdateAccount)
+			providerAccounts.DELETE("/:id", providerAccountHandler.DeleteAccount)
+			providerAccounts.POST("/:id/test", providerAccountHandler.TestAccount)
+			providerAccounts.POST("/rotate-key", providerAccountHandler.RotateKey)
+		}
+
+		// 变更审批路由（需要认证和权限）
+		changeRequests := api.Group("/change-requests")
+		changeRequests.Use(middleware.JWTAuth(), middleware.RBACMiddleware())
+		{
+			changeRequests.GET("", changeRequestHandler.ListChangeRequests)
+			changeRequests.GET("/:id", changeRequestHandler.GetChangeRequest)
+			changeRequests.POST("/:id/approve", changeRequestHandler.ApproveChangeRequest)
+			changeRequests.POST("/:id/reject", changeRequestHandler.RejectChangeRequest)
+			changeRequests.DELETE("/:id", changeRequestHandler.CancelChangeRequest)
+		}
+
+		// API令牌路由（需要登录会话，只能管理自己的令牌，不能使用API令牌）
+		tokens := api.Group("/tokens")
+		tokens.Use(middleware.JWTAuth(), middleware.RequireSession())
+		{
+			tokens.GET("", apiTokenHandler.ListTokens)
+			tokens.POST("", apiTokenHandler.CreateToken)
+			tokens.DELETE("/:id", apiTokenHandler.RevokeToken)
+		}
+
+		// JWT签名密钥路由（需要认证和权限）
+		jwtKeys := api.Group("/jwt-keys")
+		jwtKeys.Use(middleware.JWTAuth(), middleware.RBACMiddleware(), middleware.ChangeApproval())
+		{
+			jwtKeys.GET("", jwtKeyHandler.ListKeys)
+			jwtKeys.POST("/rotate", jwtKeyHandler.RotateKey)
+			jwtKeys.POST("/:id/retire", jwtKeyHandler.RetireKey)
+		}
+
+		// OAuth2授权页：当前登录用户同意或拒绝应用的授权请求（需要登录会话，不能使用API令牌）
+		api.GET("/oauth/authorize", middleware.JWTAuth(), middleware.RequireSession(), oauthHandler.GetAuthorization)
+		api.POST("/oauth/authorize", middleware.JWTAuth(), middleware.RequireSession(), oauthHandler.SubmitAuthorization)
+
+		// OAuth2应用管理路由（需要认证和权限）
+		oauthClients := api.Group("/oauth/clients")
+		oauthClients.Use(middleware.JWTAuth(), middleware.RBACMiddleware(), middleware.ChangeApproval())
+		{
+			oauthClients.GET("", oauthHandler.ListClients)
+			oauthClients.POST("", oauthHandler.CreateClient)
+			oauthClients.PUT("/:id", oauthHandler.UpdateClient)
+			oauthClients.DELETE("/:id", oauthHandler.DeleteClient)
+			oauthClients.POST("/:id/secret", oauthHandler.ResetClientSecret)
+		}
+
+		// 域名工具路由（需要认证和权限，API令牌需具有工具权限才能发起外部查询）
+		tools := api.Group("/tools")
+		tools.Use(middleware.JWTAuth(), middleware.RBACMiddleware())
+		{
+			tools.GET("/whois", middleware.RateLimit("whois", config.GetConfig().Whois.Limit(), time.Minute), toolsHandler.Whois)
+			tools.GET("/dns-check", middleware.RateLimit("dns-check", config.GetConfig().DNSCheck.Limit(), time.Minute), toolsHandler.DNSCheck)
+		}
+
+		// 仪表盘统计路由（需要认证，API令牌需在权限范围内）
 		dashboard := api.Group("/dashboard")
-		dashboard.Use(middleware.JWTAuth())
+		dashboard.Use(middleware.JWTAuth(), middleware.RequireTokenScope())
 		{
 			dashboard.GET("/stats", dashboardHandler.GetStats)
 		}
@@ -1,13 +1,19 @@
 package main
 
 import (
+	"context"
 	"domain-admin/api"
+	"domain-admin/internal/jobs"
 	"domain-admin/internal/migration"
+	"domain-admin/internal/repository"
+	"domain-admin/internal/service"
 	"domain-admin/pkg/cache"
 	"domain-admin/pkg/config"
 	"domain-admin/pkg/db"
+	"domain-admin/pkg/jwt"
 	"domain-admin/pkg/logger"
 	"domain-admin/pkg/middleware"
+	"domain-admin/pkg/provider"
 	"fmt"
 
 	"github.com/gin-gonic/gin"
@@ -18,15 +24,48 @@ func main() {
 	cfg := config.GetConfig()
 
 	logger.InitLogger(cfg.Log)
+
+	// 初始化JWT签名密钥和访问令牌有效期
+	jwt.Init(cfg.JWT.Secret, cfg.JWT.AccessTTL())
 	db.InitDB(cfg.Database)
 	cache.InitCache(cfg.Redis)
 
+	// 初始化云服务商DNS客户端
+	if err := provider.InitProviders(cfg.CloudProvider); err != nil {
+		logger.Errorf("初始化云服务商客户端失败: %v", err)
+		panic(err)
+	}
+	logger.Infof("已加载云服务商账号: %v", provider.Accounts())
+
 	// 数据库迁移
 	if err := migration.AutoMigrate(db.GetDB("default")); err != nil {
 		logger.Errorf("数据库迁移失败: %v", err)
 		panic(err)
 	}
 
+	// 加载数据库中保存的云服务商账号
+	accountService := service.NewProviderAccountService(repository.NewProviderAccountRepository(db.GetDB("default")), cfg.Vault)
+	if n, err := accountService.LoadProviders(); err != nil {
+		logger.Errorf("加载云服务商账号失败: %v", err)
+	} else if n > 0 {
+		logger.Infof("已从数据库加载 %d 个云服务商账号", n)
+	}
+
+	// 加载JWT签名密钥
+	keyService := service.NewJWTKeyService(repository.NewJWTKeyRepository(db.GetDB("default")), cfg.JWT, cfg.Vault)
+	if err := keyService.Load(); err != nil {
+		logger.Errorf("加载JWT签名密钥失败: %v", err)
+		panic(err)
+	}
+	if kid := jwt.SigningKeyID(); kid != "" {
+		logger.Infof("访问令牌使用签名密钥 %s", kid)
+	} else if cfg.JWT.Secret == "" {
+		logger.Error("未配置 jwt.secret 且没有非对称签名密钥，无法签发访问令牌")
+		panic(jwt.ErrNoSecret)
+	}
+	// 多实例部署时加载其他实例轮换或停用的密钥
+	go keyService.Watch(context.Background(), cfg.JWT.KeyReloadInterval())
+
 	// 创建默认管理员
 	if err := migration.CreateDefaultAdmin(db.GetDB("default")); err != nil {
 		logger.Errorf("创建默认管理员失败: %v", err)
@@ -51,6 +90,17 @@ func main() {
 		panic(err)
 	}
 
+	// 启动定时任务
+	if !cfg.Scheduler.Disabled {
+		sched, err := jobs.Init(cfg.Scheduler, db.GetDB("default"))
+		if err != nil {
+			logger.Errorf("初始化定时任务失败: %v", err)
+			panic(err)
+		}
+		sched.Start()
+		defer sched.Stop()
+	}
+
 	r := gin.Default()
 	api.RegisterRoutes(r)
 
@@ -0,0 +1,223 @@
+package jobs
+
+import (
+	"context"
+	"domain-admin/internal/repository"
+	"domain-admin/internal/service"
+	"domain-admin/model"
+	"domain-admin/pkg/config"
+	"domain-admin/pkg/logger"
+	"domain-admin/pkg/scheduler"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 内置任务名称
+const (
+	ProviderSync     = "provider-sync"
+	DomainExpiryScan = "domain-expiry-scan"
+	JobRunCleanup    = "job-run-cleanup"
+	CertificateProbe = "certificate-probe"
+	AcmeRenew        = "acme-renew"
+	LDAPSync         = "ldap-sync"
+	OAuthCleanup     = "oauth-token-cleanup"
+	UserTokenCleanup = "user-token-cleanup"
+)
+
+// 内置任务默认参数
+const (
+	domainExpiryWindow      = 30 * 24 * time.Hour
+	defaultRunRetentionDays = 30
+	// oauthTokenRetention 过期的授权码和访问令牌保留一段时间，便于排查
+	oauthTokenRetention = 7 * 24 * time.Hour
+	// userTokenRetention 过期的邮箱验证和重置密码令牌保留一段时间，便于排查
+	userTokenRetention = 7 * 24 * time.Hour
+)
+
+// Init 初始化默认调度器并注册内置任务
+func Init(cfg config.SchedulerConfig, db *gorm.DB) (*scheduler.Scheduler, error) {
+	jobRepo := repository.NewJobRepository(db)
+	domainRepo := repository.NewDomainRepository(db)
+	userTokenRepo := repository.NewUserTokenRepository(db)
+	syncService := service.NewSyncService(repository.NewSyncRunRepository(db))
+	certService := service.NewCertificateService(repository.NewCertificateRepository(db), config.GetConfig().Certificate)
+	acmeService := service.NewAcmeService(repository.NewAcmeRepository(db), config.GetConfig().ACME, config.GetConfig().Vault)
+	ldapService := service.NewLDAPService(
+		repository.NewUserIdentityRepository(db),
+		repository.NewUserRepository(db),
+		repository.NewRoleRepository(db),
+		config.GetConfig().LDAP,
+	)
+	tokenService := service.NewAuthTokenService(
+		repository.NewUserSessionRepository(db),
+		repository.NewRefreshTokenRepository(db),
+		repository.NewUserRepository(db),
+		config.GetConfig().JWT.RefreshTTL(),
+	)
+
+	retention := cfg.RunRetentionDays
+	if retention <= 0 {
+		retention = defaultRunRetentionDays
+	}
+
+	builtin := []scheduler.Job{
+		{
+			Name:        ProviderSync,
+			Description: "同步全部云服务商账号的区域记录",
+			Spec:        "0 * * * *",
+			Timeout:     30 * time.Minute,
+			Run: func(ctx context.Context) error {
+				for _, run := range syncService.RunAll(ctx, 0) {
+					if run.Error != "" {
+						return fmt.Errorf("账号 %s 同步失败: %s", run.ProviderAccount, run.Error)
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Name:        DomainExpiryScan,
+			Description: "标记已过期的域名并提示即将到期的域名",
+			Spec:        "0 8 * * *",
+			Run: func(ctx context.Context) error {
+				now := time.Now()
+				expired, err := domainRepo.MarkExpired(now)
+				if err != nil {
+					return fmt.Errorf("标记过期域名失败: %w", err)
+				}
+				if expired > 0 {
+					logger.Warnf("已将 %d 个域名标记为过期", expired)
+				}
+
+				domains, err := domainRepo.ListExpiringBefore(now.Add(domainExpiryWindow))
+				if err != nil {
+					return fmt.Errorf("查询即将到期的域名失败: %w", err)
+				}
+				for _, domain := range domains {
+					logger.Warnf("域名 %s 将于 %s 到期", domain.Name, domain.ExpiresAt.Format("2006-01-02"))
+				}
+				return nil
+			},
+		},
+		{
+			Name:        CertificateProbe,
+			Description: "探测全部TLS证书并提示即将到期或异常的证书",
+			Spec:        "15 */6 * * *",
+			Timeout:     30 * time.Minute,
+			Run: func(ctx context.Context) error {
+				problems, err := certService.ProbeAll(ctx)
+				if err != nil {
+					return fmt.Errorf("探测证书失败: %w", err)
+				}
+				failed := 0
+				for _, cert := range problems {
+					if cert.Status == model.CertificateStatusError {
+						failed++
+						continue
+					}
+					logger.Warnf("证书 %s:%d 状态异常: %s", cert.Host, cert.Port, cert.Status)
+				}
+				if failed > 0 {
+					return fmt.Errorf("%d 个证书探测失败", failed)
+				}
+				return nil
+			},
+		},
+		{
+			Name:        AcmeRenew,
+			Description: "自动续期即将到期的ACME证书",
+			Spec:        "0 4 * * *",
+			Timeout:     2 * time.Hour,
+			Run: func(ctx context.Context) error {
+				renewed, err := acmeService.RenewDue(ctx)
+				if renewed > 0 {
+					logger.Infof("已续期 %d 个证书", renewed)
+				}
+				return err
+			},
+		},
+		{
+			Name:        JobRunCleanup,
+			Description: fmt.Sprintf("清理%d天前的任务执行记录", retention),
+			Spec:        "30 3 * * *",
+			Run: func(ctx context.Context) error {
+				deleted, err := jobRepo.DeleteRunsBefore(time.Now().AddDate(0, 0, -retention))
+				if err != nil {
+					return fmt.Errorf("清理任务执行记录失败: %w", err)
+				}
+				logger.Infof("已清理 %d 条任务执行记录", deleted)
+				return nil
+			},
+		},
+		{
+			Name:        UserTokenCleanup,
+			Description: "清理已过期的邮箱验证和重置密码令牌",
+			Spec:        "50 3 * * *",
+			Run: func(ctx context.Context) error {
+				deleted, err := userTokenRepo.DeleteExpired(time.Now().Add(-userTokenRetention))
+				if err != nil {
+					return fmt.Errorf("清理邮箱验证和重置密码令牌失败: %w", err)
+				}
+				logger.Infof("已清理 %d 条过期的邮箱验证和重置密码令牌", deleted)
+				return nil
+			},
+		},
+	}
+
+	if ldapService.Enabled() {
+		builtin = append(builtin, scheduler.Job{
+			Name:        LDAPSync,
+			Description: "按目录服务同步用户角色并禁用已从目录删除的用户",
+			Spec:        "20 * * * *",
+			Timeout:     30 * time.Minute,
+			Run: func(ctx context.Context) error {
+				result, err := ldapService.Sync(ctx)
+				if err != nil {
+					return err
+				}
+				// 已禁用用户的会话立即吊销，不等待会话缓存过期
+				for _, userID := range result.DisabledUserIDs {
+					if _, err := tokenService.RevokeUser(userID); err != nil {
+						logger.Warnf("吊销用户 %d 的会话失败: %v", userID, err)
+					}
+				}
+				logger.Infof("目录同步完成，同步 %d 个用户，禁用 %d 个用户", result.Synced, result.Disabled)
+				return nil
+			},
+		})
+	}
+
+	if config.GetConfig().OAuth.Enabled() {
+		oauthRepo := repository.NewOAuthRepository(db)
+		builtin = append(builtin, scheduler.Job{
+			Name:        OAuthCleanup,
+			Description: "清理已过期的OAuth2授权码和访问令牌",
+			Spec:        "45 3 * * *",
+			Run: func(ctx context.Context) error {
+				deleted, err := oauthRepo.DeleteExpired(time.Now().Add(-oauthTokenRetention))
+				if err != nil {
+					return fmt.Errorf("清理OAuth2令牌失败: %w", err)
+				}
+				logger.Infof("已清理 %d 条过期的OAuth2授权码和访问令牌", deleted)
+				return nil
+			},
+		})
+	}
+
+	sched := scheduler.Init(service.NewJobStore(jobRepo))
+	for _, job := range builtin {
+		if spec, ok := cfg.Jobs[job.Name]; ok {
+			if spec == "-" {
+				logger.Infof("任务 %s 已在配置中禁用", job.Name)
+				continue
+			}
+			job.Spec = spec
+		}
+		if err := sched.Register(job); err != nil {
+			return nil, fmt.Errorf("注册任务 %s 失败: %w", job.Name, err)
+		}
+	}
+	return sched, nil
+}
@@ -3,6 +3,7 @@ package migration
 import (
 	"domain-admin/model"
 	"domain-admin/pkg/logger"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -12,10 +13,19 @@ func AutoMigrate(db *gorm.DB) error {
 	logger.Info("开始数据库迁移...")
 
 	// 迁移用户表
+	hasEmailVerifiedAt := db.Migrator().HasColumn(&model.User{}, "EmailVerifiedAt")
 	if err := db.AutoMigrate(&model.User{}); err != nil {
 		logger.Errorf("用户表迁移失败: %v", err)
 		return err
 	}
+	// 新增邮箱验证时间时，已有用户视为已验证，开启邮箱验证后仍可登录
+	if !hasEmailVerifiedAt {
+		if err := db.Model(&model.User{}).Where("email_verified_at IS NULL").
+			Update("email_verified_at", gorm.Expr("created_at")).Error; err != nil {
+			logger.Errorf("初始化用户邮箱验证时间失败: %v", err)
+			return err
+		}
+	}
 
 	// 迁移角色表
 	if err := db.AutoMigrate(&model.Role{}); err != nil {
@@ -29,12 +39,163 @@ func AutoMigrate(db *gorm.DB) error {
 		return err
 	}
 
+	// 迁移域名表
+	if err := db.AutoMigrate(&model.Domain{}); err != nil {
+		logger.Errorf("域名表迁移失败: %v", err)
+		return err
+	}
+	// 没有负责人的域名曾以 owner_id=0 保存，改为 NULL 以满足外键约束
+	if err := db.Model(&model.Domain{}).Unscoped().Where("owner_id = ?", 0).Update("owner_id", nil).Error; err != nil {
+		logger.Errorf("清理域名负责人失败: %v", err)
+		return err
+	}
+
+	// 迁移DNS区域及记录表
+	if err := db.AutoMigrate(&model.DNSZone{}, &model.DNSRecord{}); err != nil {
+		logger.Errorf("DNS区域及记录表迁移失败: %v", err)
+		return err
+	}
+
+	// 迁移区域同步相关表
+	if err := db.AutoMigrate(&model.SyncRun{}, &model.ZoneSnapshot{}, &model.SyncChange{}); err != nil {
+		logger.Errorf("区域同步表迁移失败: %v", err)
+		return err
+	}
+
+	// 迁移定时任务相关表
+	if err := db.AutoMigrate(&model.JobRun{}, &model.JobState{}); err != nil {
+		logger.Errorf("定时任务表迁移失败: %v", err)
+		return err
+	}
+
+	// 迁移证书表
+	if err := db.AutoMigrate(&model.Certificate{}); err != nil {
+		logger.Errorf("证书表迁移失败: %v", err)
+		return err
+	}
+
+	// 迁移ACME账号及证书表
+	if err := db.AutoMigrate(&model.AcmeAccount{}, &model.AcmeCertificate{}); err != nil {
+		logger.Errorf("ACME证书表迁移失败: %v", err)
+		return err
+	}
+	if err := dropLegacyAcmeKeys(db); err != nil {
+		logger.Errorf("清理ACME旧版私钥失败: %v", err)
+		return err
+	}
+
+	// 迁移云服务商账号表
+	if err := db.AutoMigrate(&model.ProviderAccount{}); err != nil {
+		logger.Errorf("云服务商账号表迁移失败: %v", err)
+		return err
+	}
+
+	// 迁移变更请求表
+	if err := db.AutoMigrate(&model.ChangeRequest{}); err != nil {
+		logger.Errorf("变更请求表迁移失败: %v", err)
+		return err
+	}
+
+	// 迁移修订记录表
+	if err := db.AutoMigrate(&model.Revision{}); err != nil {
+		logger.Errorf("修订记录表迁移失败: %v", err)
+		return err
+	}
+
+	// 迁移API令牌表
+	if err := db.AutoMigrate(&model.APIToken{}); err != nil {
+		logger.Errorf("API令牌表迁移失败: %v", err)
+		return err
+	}
+
+	// 迁移登录会话表
+	if err := db.AutoMigrate(&model.UserSession{}); err != nil {
+		logger.Errorf("登录会话表迁移失败: %v", err)
+		return err
+	}
+
+	// 迁移刷新令牌表
+	if err := db.AutoMigrate(&model.RefreshToken{}); err != nil {
+		logger.Errorf("刷新令牌表迁移失败: %v", err)
+		return err
+	}
+
+	// 迁移JWT签名密钥表
+	if err := db.AutoMigrate(&model.JWTKey{}); err != nil {
+		logger.Errorf("JWT签名密钥表迁移失败: %v", err)
+		return err
+	}
 
+	// 迁移两步验证表
+	if err := db.AutoMigrate(&model.UserTOTP{}, &model.LoginChallenge{}); err != nil {
+		logger.Errorf("两步验证表迁移失败: %v", err)
+		return err
+	}
+
+	// 迁移WebAuthn凭据表
+	if err := db.AutoMigrate(&model.WebAuthnCredential{}, &model.WebAuthnChallenge{}); err != nil {
+		logger.Errorf("WebAuthn凭据表迁移失败: %v", err)
+		return err
+	}
+
+	// 迁移单点登录表
+	if err := db.AutoMigrate(&model.OIDCLoginState{}, &model.UserIdentity{}); err != nil {
+		logger.Errorf("单点登录表迁移失败: %v", err)
+		return err
+	}
+
+	// 迁移邮箱验证和重置密码令牌表
+	if err := db.AutoMigrate(&model.UserToken{}); err != nil {
+		logger.Errorf("邮箱验证和重置密码令牌表迁移失败: %v", err)
+		return err
+	}
+
+	// 迁移OAuth2授权服务表
+	if err := db.AutoMigrate(&model.OAuthClient{}, &model.OAuthAuthorizationCode{}, &model.OAuthAccessToken{}); err != nil {
+		logger.Errorf("OAuth2授权服务表迁移失败: %v", err)
+		return err
+	}
 
 	logger.Info("数据库迁移完成")
 	return nil
 }
 
+// dropLegacyAcmeKeys 删除早期版本 ACME 表中的 private_key 列。
+// 旧数据使用已移除的 acme.encryption_key 加密，无法转为 vault 主密钥加密：
+// 账号记录被删除，下次签发时重新注册；已签发的证书清除证书内容并标记为签发失败，需要重新签发
+func dropLegacyAcmeKeys(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if migrator.HasColumn(&model.AcmeAccount{}, "private_key") {
+		result := db.Where("private_ciphertext IS NULL OR private_ciphertext = ?", "").Delete(&model.AcmeAccount{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if err := migrator.DropColumn(&model.AcmeAccount{}, "private_key"); err != nil {
+			return err
+		}
+		logger.Infof("已删除 %d 个旧版加密的ACME账号，下次签发证书时重新注册", result.RowsAffected)
+	}
+	if migrator.HasColumn(&model.AcmeCertificate{}, "private_key") {
+		result := db.Model(&model.AcmeCertificate{}).
+			Where("(private_ciphertext IS NULL OR private_ciphertext = ?) AND certificate <> ?", "", "").
+			Updates(map[string]interface{}{
+				"certificate": "",
+				"status":      model.AcmeCertificateStatusFailed,
+				"last_error":  "旧版本加密的证书私钥无法迁移，请重新签发",
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if err := migrator.DropColumn(&model.AcmeCertificate{}, "private_key"); err != nil {
+			return err
+		}
+		if result.RowsAffected > 0 {
+			logger.Warnf("%d 个ACME证书的私钥使用旧版本加密，已标记为签发失败，请重新签发", result.RowsAffected)
+		}
+	}
+	return nil
+}
+
 // CreateDefaultAdmin 创建默认管理员账户
 func CreateDefaultAdmin(db *gorm.DB) error {
 	// 检查是否已存在管理员账户
@@ -46,6 +207,7 @@ func CreateDefaultAdmin(db *gorm.DB) error {
 	}
 
 	// 创建默认管理员
+	now := time.Now()
 	admin := &model.User{
 		Username: "admin",
 		Email:    "admin@example.com",
@@ -53,6 +215,8 @@ func CreateDefaultAdmin(db *gorm.DB) error {
 		Nickname: "系统管理员",
 		Role:     "admin",
 		Status:   1,
+		// 默认管理员视为已验证邮箱，开启邮箱验证后仍可登录
+		EmailVerifiedAt: &now,
 	}
 
 	if err := db.Create(admin).Error; err != nil {
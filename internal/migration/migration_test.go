@@ -0,0 +1,145 @@
+package migration
+
+import (
+	"domain-admin/model"
+	"domain-admin/pkg/logger"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// legacyAcmeAccount 早期版本的ACME账号表，私钥保存在 private_key 列
+type legacyAcmeAccount struct {
+	ID           uint   `gorm:"primarykey"`
+	DirectoryURL string `gorm:"uniqueIndex:idx_acme_account;size:255;not null"`
+	Email        string `gorm:"uniqueIndex:idx_acme_account;size:100"`
+	URL          string `gorm:"size:255"`
+	PrivateKey   string `gorm:"type:text;not null"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func (legacyAcmeAccount) TableName() string { return "acme_accounts" }
+
+// legacyAcmeCertificate 早期版本的ACME证书表，证书和私钥都使用 acme.encryption_key 加密
+type legacyAcmeCertificate struct {
+	ID          uint   `gorm:"primarykey"`
+	CommonName  string `gorm:"size:253;index;not null"`
+	Status      string `gorm:"size:20;index;default:pending"`
+	Certificate string `gorm:"type:text"`
+	PrivateKey  string `gorm:"type:text"`
+	LastError   string `gorm:"type:text"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (legacyAcmeCertificate) TableName() string { return "acme_certificates" }
+
+func TestDropLegacyAcmeKeys(t *testing.T) {
+	logger.Log = zap.NewNop()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&legacyAcmeAccount{}, &legacyAcmeCertificate{}); err != nil {
+		t.Fatal(err)
+	}
+	db.Create(&legacyAcmeAccount{DirectoryURL: "https://acme.example.com/directory", Email: "admin@example.com", PrivateKey: "v1:old"})
+	db.Create(&legacyAcmeCertificate{CommonName: "issued.example.com", Status: "issued", Certificate: "v1:cert", PrivateKey: "v1:key"})
+	db.Create(&legacyAcmeCertificate{CommonName: "pending.example.com", Status: "pending"})
+
+	if err := db.AutoMigrate(&model.AcmeAccount{}, &model.AcmeCertificate{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := dropLegacyAcmeKeys(db); err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []interface{}{&model.AcmeAccount{}, &model.AcmeCertificate{}} {
+		if db.Migrator().HasColumn(m, "private_key") {
+			t.Fatalf("%T still has private_key", m)
+		}
+	}
+
+	// 旧账号无法解密，删除后可以按新格式重新注册
+	account := &model.AcmeAccount{DirectoryURL: "https://acme.example.com/directory", Email: "admin@example.com", PrivateCiphertext: "c", PrivateDataKey: "d", PrivateKeyID: "k"}
+	if err := db.Create(account).Error; err != nil {
+		t.Fatalf("insert account after migration: %v", err)
+	}
+
+	var issued, pending model.AcmeCertificate
+	db.Where("common_name = ?", "issued.example.com").First(&issued)
+	if issued.Status != model.AcmeCertificateStatusFailed || issued.Certificate != "" || issued.LastError == "" {
+		t.Fatalf("legacy certificate = %+v", issued)
+	}
+	db.Where("common_name = ?", "pending.example.com").First(&pending)
+	if pending.Status != model.AcmeCertificateStatusPending || pending.LastError != "" {
+		t.Fatalf("pending certificate = %+v", pending)
+	}
+
+	// 再次迁移不做处理
+	if err := dropLegacyAcmeKeys(db); err != nil {
+		t.Fatal(err)
+	}
+	var count int64
+	db.Model(&model.AcmeAccount{}).Count(&count)
+	if count != 1 {
+		t.Fatalf("accounts = %d", count)
+	}
+}
+
+func TestInitRBACDataUpgradesUserPermissions(t *testing.T) {
+	logger.Log = zap.NewNop()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&model.Role{}, &model.Permission{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := InitRBACData(db); err != nil {
+		t.Fatal(err)
+	}
+
+	// 模拟升级前的数据：用户权限使用通配符，自定义角色持有更新和删除下级用户的权限
+	for name, resource := range legacyPermissionResources {
+		db.Model(&model.Permission{}).Where("name = ?", name).Update("resource", resource)
+	}
+	var legacy []model.Permission
+	db.Where("name IN ?", []string{"user.update", "user.lower.delete"}).Find(&legacy)
+	helpdesk := &model.Role{Name: "helpdesk", DisplayName: "服务台", Level: 50, Status: 1}
+	if err := db.Create(helpdesk).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Model(helpdesk).Association("Permissions").Append(&legacy); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := InitRBACData(db); err != nil {
+		t.Fatal(err)
+	}
+	var perms []model.Permission
+	db.Where("name LIKE ?", "user.%").Find(&perms)
+	for _, p := range perms {
+		if strings.Contains(p.Resource, "*") {
+			t.Errorf("%s still uses wildcard resource %s", p.Name, p.Resource)
+		}
+	}
+
+	// 更新用户的权限拆分出启用禁用权限，删除权限不会获得重置两步验证的权限
+	db.Preload("Permissions").First(helpdesk, helpdesk.ID)
+	var names []string
+	for _, p := range helpdesk.Permissions {
+		names = append(names, p.Name)
+	}
+	slices.Sort(names)
+	if want := []string{"user.lower.delete", "user.status", "user.update"}; !slices.Equal(names, want) {
+		t.Fatalf("helpdesk permissions = %v, want %v", names, want)
+	}
+}
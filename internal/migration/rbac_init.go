@@ -37,12 +37,14 @@ func initRoles(db *gorm.DB) error {
 			Name:        "admin",
 			DisplayName: "系统管理员",
 			Description: "拥有系统所有权限的超级管理员",
+			Level:       100,
 			Status:      1,
 		},
 		{
 			Name:        "user",
 			DisplayName: "普通用户",
 			Description: "系统普通用户，拥有基础功能权限",
+			Level:       10,
 			Status:      1,
 		},
 		{
@@ -64,6 +66,12 @@ func initRoles(db *gorm.DB) error {
 			logger.Infof("创建角色成功: %s", role.Name)
 		} else if result.Error != nil {
 			return result.Error
+		} else if existingRole.Level == 0 && role.Level > 0 {
+			// 为升级前创建的内置角色补充等级
+			if err := db.Model(&existingRole).Update("level", role.Level).Error; err != nil {
+				logger.Errorf("更新角色等级失败: %s, error: %v", role.Name, err)
+				return err
+			}
 		}
 	}
 
@@ -76,9 +84,19 @@ func initPermissions(db *gorm.DB) error {
 		// 用户管理权限
 		{Name: "user.list", DisplayName: "查看用户列表", Description: "查看系统用户列表", Resource: "/api/users", Action: "GET", Status: 1},
 		{Name: "user.create", DisplayName: "创建用户", Description: "创建新用户", Resource: "/api/users", Action: "POST", Status: 1},
-		{Name: "user.update", DisplayName: "更新用户", Description: "更新用户信息", Resource: "/api/users/*", Action: "PUT", Status: 1},
-		{Name: "user.delete", DisplayName: "删除用户", Description: "删除用户", Resource: "/api/users/*", Action: "DELETE", Status: 1},
-		{Name: "user.detail", DisplayName: "查看用户详情", Description: "查看用户详细信息", Resource: "/api/users/*", Action: "GET", Status: 1},
+		{Name: "user.update", DisplayName: "更新用户", Description: "更新用户信息", Resource: "/api/users/:id", Action: "PUT", Status: 1},
+		{Name: "user.delete", DisplayName: "删除用户", Description: "删除用户", Resource: "/api/users/:id", Action: "DELETE", Status: 1},
+		{Name: "user.detail", DisplayName: "查看用户详情", Description: "查看用户详细信息", Resource: "/api/users/:id", Action: "GET", Status: 1},
+		{Name: "user.self.detail", DisplayName: "查看本人信息", Description: "只能查看自己的用户信息", Resource: "/api/users/:id", Action: "GET", Condition: "self", Status: 1},
+		{Name: "user.self.update", DisplayName: "更新本人信息", Description: "只能更新自己的用户信息，不能修改角色和状态", Resource: "/api/users/:id", Action: "PUT", Condition: "self", Status: 1},
+		{Name: "user.lower.create", DisplayName: "创建下级用户", Description: "只能创建角色等级低于自己的用户", Resource: "/api/users", Action: "POST", Condition: "lower_role", Status: 1},
+		{Name: "user.lower.update", DisplayName: "更新下级用户", Description: "只能更新角色等级低于自己的用户", Resource: "/api/users/:id", Action: "PUT", Condition: "lower_role", Status: 1},
+		{Name: "user.lower.delete", DisplayName: "删除下级用户", Description: "只能删除角色等级低于自己的用户", Resource: "/api/users/:id", Action: "DELETE", Condition: "lower_role", Status: 1},
+		{Name: "user.lower.detail", DisplayName: "查看下级用户", Description: "只能查看角色等级低于自己的用户", Resource: "/api/users/:id", Action: "GET", Condition: "lower_role", Status: 1},
+		{Name: "user.status", DisplayName: "启用禁用用户", Description: "启用或禁用用户", Resource: "/api/users/:id/status", Action: "PUT", Status: 1},
+		{Name: "user.lower.status", DisplayName: "启用禁用下级用户", Description: "只能启用或禁用角色等级低于自己的用户", Resource: "/api/users/:id/status", Action: "PUT", Condition: "lower_role", Status: 1},
+		{Name: "user.reset_2fa", DisplayName: "重置两步验证", Description: "清除用户的两步验证绑定和恢复码", Resource: "/api/users/:id/2fa", Action: "DELETE", Status: 1},
+		{Name: "user.lower.reset_2fa", DisplayName: "重置下级用户两步验证", Description: "只能重置角色等级低于自己的用户的两步验证", Resource: "/api/users/:id/2fa", Action: "DELETE", Condition: "lower_role", Status: 1},
 
 		// 角色管理权限
 		{Name: "role.list", DisplayName: "查看角色列表", Description: "查看系统角色列表", Resource: "/api/roles", Action: "GET", Status: 1},
@@ -86,6 +104,7 @@ func initPermissions(db *gorm.DB) error {
 		{Name: "role.update", DisplayName: "更新角色", Description: "更新角色信息", Resource: "/api/roles/*", Action: "PUT", Status: 1},
 		{Name: "role.delete", DisplayName: "删除角色", Description: "删除角色", Resource: "/api/roles/*", Action: "DELETE", Status: 1},
 		{Name: "role.detail", DisplayName: "查看角色详情", Description: "查看角色详细信息", Resource: "/api/roles/*", Action: "GET", Status: 1},
+		{Name: "role.rollback", DisplayName: "回滚角色", Description: "将角色及其权限恢复到历史修订", Resource: "/api/roles/*/rollback", Action: "POST", Status: 1},
 
 		// 权限管理权限
 		{Name: "permission.list", DisplayName: "查看权限列表", Description: "查看系统权限列表", Resource: "/api/permissions", Action: "GET", Status: 1},
@@ -94,6 +113,102 @@ func initPermissions(db *gorm.DB) error {
 		{Name: "permission.delete", DisplayName: "删除权限", Description: "删除权限", Resource: "/api/permissions/*", Action: "DELETE", Status: 1},
 		{Name: "permission.detail", DisplayName: "查看权限详情", Description: "查看权限详细信息", Resource: "/api/permissions/*", Action: "GET", Status: 1},
 
+		// 域名管理权限
+		{Name: "domain.list", DisplayName: "查看域名列表", Description: "查看域名资产列表", Resource: "/api/domains", Action: "GET", Status: 1},
+		{Name: "domain.create", DisplayName: "创建域名", Description: "登记新域名", Resource: "/api/domains", Action: "POST", Status: 1},
+		{Name: "domain.update", DisplayName: "更新域名", Description: "更新域名信息", Resource: "/api/domains/*", Action: "PUT", Status: 1},
+		{Name: "domain.delete", DisplayName: "删除域名", Description: "删除域名", Resource: "/api/domains/*", Action: "DELETE", Status: 1},
+		{Name: "domain.detail", DisplayName: "查看域名详情", Description: "查看域名详细信息", Resource: "/api/domains/*", Action: "GET", Status: 1},
+
+		// DNS管理权限
+		{Name: "dns.zone.list", DisplayName: "查看区域列表", Description: "查看DNS区域列表", Resource: "/api/zones", Action: "GET", Status: 1},
+		{Name: "dns.zone.create", DisplayName: "创建区域", Description: "创建DNS区域", Resource: "/api/zones", Action: "POST", Status: 1},
+		{Name: "dns.zone.update", DisplayName: "更新区域", Description: "更新DNS区域信息", Resource: "/api/zones/*", Action: "PUT", Status: 1},
+		{Name: "dns.zone.delete", DisplayName: "删除区域", Description: "删除DNS区域", Resource: "/api/zones/*", Action: "DELETE", Status: 1},
+		{Name: "dns.zone.detail", DisplayName: "查看区域详情", Description: "查看DNS区域详细信息", Resource: "/api/zones/*", Action: "GET", Status: 1},
+		{Name: "dns.record.list", DisplayName: "查看记录列表", Description: "查看DNS记录列表", Resource: "/api/zones/*/records", Action: "GET", Status: 1},
+		{Name: "dns.record.detail", DisplayName: "查看记录详情", Description: "查看DNS记录详细信息", Resource: "/api/zones/*/records/*", Action: "GET", Status: 1},
+		{Name: "dns.record.create", DisplayName: "创建记录", Description: "创建DNS记录", Resource: "/api/zones/*/records", Action: "POST", Status: 1},
+		{Name: "dns.record.update", DisplayName: "更新记录", Description: "更新DNS记录", Resource: "/api/zones/*/records/*", Action: "PUT", Status: 1},
+		{Name: "dns.record.delete", DisplayName: "删除记录", Description: "删除DNS记录", Resource: "/api/zones/*/records/*", Action: "DELETE", Status: 1},
+		{Name: "zone.import", DisplayName: "导入区域文件", Description: "从BIND区域文件导入DNS记录", Resource: "/api/zones/*/import", Action: "POST", Status: 1},
+		{Name: "zone.export", DisplayName: "导出区域文件", Description: "将DNS区域导出为BIND区域文件", Resource: "/api/zones/*/export", Action: "GET", Status: 1},
+
+		// 区域同步权限
+		{Name: "sync.list", DisplayName: "查看同步记录", Description: "查看区域同步任务列表", Resource: "/api/sync-runs", Action: "GET", Status: 1},
+		{Name: "sync.detail", DisplayName: "查看同步详情", Description: "查看区域同步变更明细", Resource: "/api/sync-runs/*", Action: "GET", Status: 1},
+		{Name: "sync.run", DisplayName: "触发同步", Description: "立即同步云服务商区域", Resource: "/api/sync-runs", Action: "POST", Status: 1},
+
+		// 定时任务权限
+		{Name: "job.list", DisplayName: "查看任务列表", Description: "查看定时任务及其调度状态", Resource: "/api/jobs", Action: "GET", Status: 1},
+		{Name: "job.runs", DisplayName: "查看执行记录", Description: "查看定时任务执行记录", Resource: "/api/jobs/*/runs", Action: "GET", Status: 1},
+		{Name: "job.trigger", DisplayName: "手动执行任务", Description: "立即执行定时任务", Resource: "/api/jobs/*/trigger", Action: "POST", Status: 1},
+		{Name: "job.pause", DisplayName: "暂停任务", Description: "暂停定时任务调度", Resource: "/api/jobs/*/pause", Action: "POST", Status: 1},
+		{Name: "job.resume", DisplayName: "恢复任务", Description: "恢复定时任务调度", Resource: "/api/jobs/*/resume", Action: "POST", Status: 1},
+
+		// 证书管理权限
+		{Name: "certificate.list", DisplayName: "查看证书列表", Description: "查看TLS证书列表", Resource: "/api/certificates", Action: "GET", Status: 1},
+		{Name: "certificate.create", DisplayName: "添加证书", Description: "添加证书探测目标", Resource: "/api/certificates", Action: "POST", Status: 1},
+		{Name: "certificate.update", DisplayName: "更新证书", Description: "更新证书探测目标", Resource: "/api/certificates/*", Action: "PUT", Status: 1},
+		{Name: "certificate.delete", DisplayName: "删除证书", Description: "删除证书探测目标", Resource: "/api/certificates/*", Action: "DELETE", Status: 1},
+		{Name: "certificate.detail", DisplayName: "查看证书详情", Description: "查看TLS证书详细信息", Resource: "/api/certificates/*", Action: "GET", Status: 1},
+		{Name: "certificate.probe", DisplayName: "探测证书", Description: "立即探测TLS证书", Resource: "/api/certificates/*/probe", Action: "POST", Status: 1},
+
+		// ACME证书权限
+		{Name: "acme.list", DisplayName: "查看签发证书列表", Description: "查看ACME签发的证书列表", Resource: "/api/acme/certificates", Action: "GET", Status: 1},
+		{Name: "acme.create", DisplayName: "申请证书", Description: "通过ACME申请证书", Resource: "/api/acme/certificates", Action: "POST", Status: 1},
+		{Name: "acme.update", DisplayName: "更新签发证书", Description: "更新证书续期设置", Resource: "/api/acme/certificates/*", Action: "PUT", Status: 1},
+		{Name: "acme.delete", DisplayName: "删除签发证书", Description: "删除ACME签发的证书", Resource: "/api/acme/certificates/*", Action: "DELETE", Status: 1},
+		{Name: "acme.detail", DisplayName: "查看签发证书详情", Description: "查看证书签发状态", Resource: "/api/acme/certificates/*", Action: "GET", Status: 1},
+		{Name: "acme.renew", DisplayName: "续期证书", Description: "立即重新签发证书", Resource: "/api/acme/certificates/*/renew", Action: "POST", Status: 1},
+		{Name: "acme.download", DisplayName: "下载证书", Description: "下载证书链和私钥", Resource: "/api/acme/certificates/*/download", Action: "GET", Status: 1},
+
+		// 云服务商账号权限
+		{Name: "provider_account.list", DisplayName: "查看云服务商账号列表", Description: "查看云服务商账号列表", Resource: "/api/provider-accounts", Action: "GET", Status: 1},
+		{Name: "provider_account.create", DisplayName: "创建云服务商账号", Description: "添加云服务商账号及访问密钥", Resource: "/api/provider-accounts", Action: "POST", Status: 1},
+		{Name: "provider_account.update", DisplayName: "更新云服务商账号", Description: "更新云服务商账号及访问密钥", Resource: "/api/provider-accounts/*", Action: "PUT", Status: 1},
+		{Name: "provider_account.delete", DisplayName: "删除云服务商账号", Description: "删除云服务商账号", Resource: "/api/provider-accounts/*", Action: "DELETE", Status: 1},
+		{Name: "provider_account.detail", DisplayName: "查看云服务商账号详情", Description: "查看云服务商账号详情", Resource: "/api/provider-accounts/*", Action: "GET", Status: 1},
+		{Name: "provider_account.test", DisplayName: "测试云服务商账号", Description: "测试云服务商账号连接", Resource: "/api/provider-accounts/*/test", Action: "POST", Status: 1},
+		{Name: "provider_account.rotate", DisplayName: "轮换加密主密钥", Description: "使用新主密钥重新加密所有由旧主密钥加密的数据", Resource: "/api/provider-accounts/rotate-key", Action: "POST", Status: 1},
+
+		// 变更审批权限
+		{Name: "change.list", DisplayName: "查看变更请求列表", Description: "查看待审批及历史变更请求", Resource: "/api/change-requests", Action: "GET", Status: 1},
+		{Name: "change.detail", DisplayName: "查看变更请求详情", Description: "查看变更请求内容及执行结果", Resource: "/api/change-requests/*", Action: "GET", Status: 1},
+		{Name: "change.approve", DisplayName: "审批变更请求", Description: "批准并执行或拒绝他人提交的变更请求", Resource: "/api/change-requests/*", Action: "POST", Status: 1},
+		{Name: "change.cancel", DisplayName: "撤回变更请求", Description: "撤回自己提交的变更请求", Resource: "/api/change-requests/*", Action: "DELETE", Status: 1},
+
+		// JWT签名密钥权限
+		{Name: "jwt_key.list", DisplayName: "查看签名密钥列表", Description: "查看JWT签名密钥列表", Resource: "/api/jwt-keys", Action: "GET", Status: 1},
+		{Name: "jwt_key.rotate", DisplayName: "轮换签名密钥", Description: "生成新的JWT签名密钥", Resource: "/api/jwt-keys/rotate", Action: "POST", Status: 1},
+		{Name: "jwt_key.retire", DisplayName: "停用签名密钥", Description: "停用旧的JWT签名密钥", Resource: "/api/jwt-keys/*/retire", Action: "POST", Status: 1},
+
+		// OAuth2应用权限
+		{Name: "oauth_client.list", DisplayName: "查看应用列表", Description: "查看接入本系统登录的应用", Resource: "/api/oauth/clients", Action: "GET", Status: 1},
+		{Name: "oauth_client.create", DisplayName: "注册应用", Description: "注册接入本系统登录的应用", Resource: "/api/oauth/clients", Action: "POST", Status: 1},
+		{Name: "oauth_client.update", DisplayName: "更新应用", Description: "更新应用的回调地址、作用域和状态", Resource: "/api/oauth/clients/*", Action: "PUT", Status: 1},
+		{Name: "oauth_client.delete", DisplayName: "删除应用", Description: "删除应用并吊销其访问令牌", Resource: "/api/oauth/clients/*", Action: "DELETE", Status: 1},
+		{Name: "oauth_client.reset_secret", DisplayName: "重置应用密钥", Description: "重新生成机密客户端的密钥", Resource: "/api/oauth/clients/*/secret", Action: "POST", Status: 1},
+
+		// SCIM用户同步权限，scim.all 供管理员为身份提供方创建API令牌
+		{Name: "scim.user.list", DisplayName: "SCIM查询用户", Description: "通过SCIM查询用户", Resource: "/scim/v2/Users", Action: "GET", Status: 1},
+		{Name: "scim.user.detail", DisplayName: "SCIM获取用户", Description: "通过SCIM获取用户", Resource: "/scim/v2/Users/*", Action: "GET", Status: 1},
+		{Name: "scim.user.create", DisplayName: "SCIM创建用户", Description: "通过SCIM创建用户", Resource: "/scim/v2/Users", Action: "POST", Status: 1},
+		{Name: "scim.user.replace", DisplayName: "SCIM替换用户", Description: "通过SCIM替换用户信息", Resource: "/scim/v2/Users/*", Action: "PUT", Status: 1},
+		{Name: "scim.user.patch", DisplayName: "SCIM修改用户", Description: "通过SCIM修改用户信息或状态", Resource: "/scim/v2/Users/*", Action: "PATCH", Status: 1},
+		{Name: "scim.user.delete", DisplayName: "SCIM删除用户", Description: "通过SCIM删除用户", Resource: "/scim/v2/Users/*", Action: "DELETE", Status: 1},
+		{Name: "scim.group.list", DisplayName: "SCIM查询用户组", Description: "通过SCIM查询角色及成员", Resource: "/scim/v2/Groups", Action: "GET", Status: 1},
+		{Name: "scim.group.detail", DisplayName: "SCIM获取用户组", Description: "通过SCIM获取角色及成员", Resource: "/scim/v2/Groups/*", Action: "GET", Status: 1},
+		{Name: "scim.group.create", DisplayName: "SCIM创建用户组", Description: "通过SCIM创建角色", Resource: "/scim/v2/Groups", Action: "POST", Status: 1},
+		{Name: "scim.group.replace", DisplayName: "SCIM替换用户组", Description: "通过SCIM替换角色成员", Resource: "/scim/v2/Groups/*", Action: "PUT", Status: 1},
+		{Name: "scim.group.patch", DisplayName: "SCIM修改用户组", Description: "通过SCIM添加或移除角色成员", Resource: "/scim/v2/Groups/*", Action: "PATCH", Status: 1},
+		{Name: "scim.group.delete", DisplayName: "SCIM删除用户组", Description: "通过SCIM删除角色", Resource: "/scim/v2/Groups/*", Action: "DELETE", Status: 1},
+		{Name: "scim.all", DisplayName: "SCIM全部权限", Description: "通过SCIM管理用户和用户组", Resource: "/scim/v2/*", Action: "*", Status: 1},
+
+		// 域名工具权限
+		{Name: "tools.whois", DisplayName: "WHOIS查询", Description: "查询域名的WHOIS/RDAP注册信息", Resource: "/api/tools/whois", Action: "GET", Status: 1},
+		{Name: "tools.dns_check", DisplayName: "DNS解析诊断", Description: "向递归解析器和权威服务器查询并比较解析结果", Resource: "/api/tools/dns-check", Action: "GET", Status: 1},
+
 		// 认证相关权限
 		{Name: "auth.login", DisplayName: "用户登录", Description: "用户登录系统", Resource: "/api/auth/login", Action: "POST", Status: 1},
 		{Name: "auth.logout", DisplayName: "用户登出", Description: "用户登出系统", Resource: "/api/auth/logout", Action: "POST", Status: 1},
@@ -105,6 +220,7 @@ func initPermissions(db *gorm.DB) error {
 		{Name: "system.all", DisplayName: "系统全部权限", Description: "系统所有功能的访问权限", Resource: "/api/*", Action: "*", Status: 1},
 	}
 
+	upgraded := false
 	for _, permission := range permissions {
 		var existingPermission model.Permission
 		result := db.Where("name = ?", permission.Name).First(&existingPermission)
@@ -116,12 +232,70 @@ func initPermissions(db *gorm.DB) error {
 			logger.Infof("创建权限成功: %s", permission.Name)
 		} else if result.Error != nil {
 			return result.Error
+		} else if legacy, ok := legacyPermissionResources[permission.Name]; ok && existingPermission.Resource == legacy {
+			// 升级前的用户权限使用通配符，会同时匹配状态和两步验证等子资源
+			if err := db.Model(&existingPermission).Update("resource", permission.Resource).Error; err != nil {
+				logger.Errorf("更新权限资源失败: %s, error: %v", permission.Name, err)
+				return err
+			}
+			logger.Infof("更新权限资源成功: %s -> %s", permission.Name, permission.Resource)
+			upgraded = true
 		}
 	}
 
+	if upgraded {
+		return grantSplitPermissions(db)
+	}
+	return nil
+}
+
+// grantSplitPermissions 升级前更新用户的权限也能启用禁用用户，为持有该权限的角色补充拆分出的状态权限
+func grantSplitPermissions(db *gorm.DB) error {
+	split := map[string]string{
+		"user.update":       "user.status",
+		"user.lower.update": "user.lower.status",
+	}
+
+	var roles []model.Role
+	if err := db.Preload("Permissions").Find(&roles).Error; err != nil {
+		return err
+	}
+	for _, role := range roles {
+		held := make(map[string]bool, len(role.Permissions))
+		for _, perm := range role.Permissions {
+			held[perm.Name] = true
+		}
+		for from, to := range split {
+			if !held[from] || held[to] {
+				continue
+			}
+			var permission model.Permission
+			if err := db.Where("name = ?", to).First(&permission).Error; err != nil {
+				return err
+			}
+			if err := db.Model(&role).Association("Permissions").Append(&permission); err != nil {
+				logger.Errorf("补充角色权限失败: %s, error: %v", role.Name, err)
+				return err
+			}
+			logger.Infof("为角色 %s 补充权限 %s", role.Name, to)
+		}
+	}
 	return nil
 }
 
+// legacyPermissionResources 升级前内置权限的资源路径，仍为旧值时更新为当前路径
+var legacyPermissionResources = map[string]string{
+	"user.update":       "/api/users/*",
+	"user.delete":       "/api/users/*",
+	"user.detail":       "/api/users/*",
+	"user.self.detail":  "/api/users/*",
+	"user.self.update":  "/api/users/*",
+	"user.lower.update": "/api/users/*",
+	"user.lower.delete": "/api/users/*",
+	"user.lower.detail": "/api/users/*",
+	"user.reset_2fa":    "/api/users/*/2fa",
+}
+
 // initRolePermissions 初始化角色权限关联
 func initRolePermissions(db *gorm.DB) error {
 	// 管理员角色拥有所有权限
@@ -130,26 +304,29 @@ func initRolePermissions(db *gorm.DB) error {
 		return err
 	}
 
-	var systemAllPermission model.Permission
-	if err := db.Where("name = ?", "system.all").First(&systemAllPermission).Error; err != nil {
-		return err
-	}
+	// system.all 只覆盖 /api/*，SCIM 接口需要单独授权
+	for _, permName := range []string{"system.all", "scim.all"} {
+		var allPermission model.Permission
+		if err := db.Where("name = ?", permName).First(&allPermission).Error; err != nil {
+			return err
+		}
 
-	// 检查是否已存在关联
-	hasPermission := false
-	for _, perm := range adminRole.Permissions {
-		if perm.ID == systemAllPermission.ID {
-			hasPermission = true
-			break
+		// 检查是否已存在关联
+		hasPermission := false
+		for _, perm := range adminRole.Permissions {
+			if perm.ID == allPermission.ID {
+				hasPermission = true
+				break
+			}
 		}
-	}
 
-	if !hasPermission {
-		if err := db.Model(&adminRole).Association("Permissions").Append(&systemAllPermission); err != nil {
-			logger.Errorf("创建管理员角色权限关联失败: %v", err)
-			return err
+		if !hasPermission {
+			if err := db.Model(&adminRole).Association("Permissions").Append(&allPermission); err != nil {
+				logger.Errorf("创建管理员角色权限关联失败: %v", err)
+				return err
+			}
+			logger.Info("创建管理员角色权限关联成功")
 		}
-		logger.Info("创建管理员角色权限关联成功")
 	}
 
 	// 普通用户角色的基础权限
@@ -158,7 +335,7 @@ func initRolePermissions(db *gorm.DB) error {
 		return err
 	}
 
-	userPermissions := []string{"auth.login", "auth.logout", "auth.profile", "auth.update_profile", "auth.change_password"}
+	userPermissions := []string{"auth.login", "auth.logout", "auth.profile", "auth.update_profile", "auth.change_password", "user.self.detail", "user.self.update", "tools.whois", "tools.dns_check"}
 	var userPermsToAdd []model.Permission
 	for _, permName := range userPermissions {
 		var permission model.Permission
@@ -194,7 +371,7 @@ func initRolePermissions(db *gorm.DB) error {
 		return err
 	}
 
-	guestPermissions := []string{"auth.login", "auth.logout", "auth.profile"}
+	guestPermissions := []string{"auth.login", "auth.logout", "auth.profile", "tools.whois", "tools.dns_check"}
 	var guestPermsToAdd []model.Permission
 	for _, permName := range guestPermissions {
 		var permission model.Permission
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"domain-admin/model"
+	"domain-admin/pkg/pagination"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AcmeRepository ACME账号及证书仓储接口
+type AcmeRepository interface {
+	GetAccount(directoryURL, email string) (*model.AcmeAccount, error)
+	SaveAccount(account *model.AcmeAccount) error
+	ListAccounts() ([]*model.AcmeAccount, error)
+	UpdateAccountKey(account *model.AcmeAccount) error
+	CreateCertificate(cert *model.AcmeCertificate) error
+	GetCertificate(id uint) (*model.AcmeCertificate, error)
+	UpdateCertificate(cert *model.AcmeCertificate) error
+	DeleteCertificate(id uint) error
+	ListCertificates(status string, page pagination.Pagination) ([]*model.AcmeCertificate, int64, error)
+	ListRenewable(before time.Time) ([]*model.AcmeCertificate, error)
+	ListAllCertificates() ([]*model.AcmeCertificate, error)
+	UpdateCertificateKey(cert *model.AcmeCertificate) error
+}
+
+type acmeRepository struct {
+	db *gorm.DB
+}
+
+// NewAcmeRepository 创建ACME仓储实例
+func NewAcmeRepository(db *gorm.DB) AcmeRepository {
+	return &acmeRepository{db: db}
+}
+
+// GetAccount 根据目录地址和邮箱获取账号
+func (r *acmeRepository) GetAccount(directoryURL, email string) (*model.AcmeAccount, error) {
+	var account model.AcmeAccount
+	err := r.db.Where("directory_url = ? AND email = ?", directoryURL, email).First(&account).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("ACME账号不存在")
+		}
+		return nil, err
+	}
+	return &account, nil
+}
+
+// SaveAccount 保存账号
+func (r *acmeRepository) SaveAccount(account *model.AcmeAccount) error {
+	return r.db.Save(account).Error
+}
+
+// ListAccounts 获取全部ACME账号
+func (r *acmeRepository) ListAccounts() ([]*model.AcmeAccount, error) {
+	var accounts []*model.AcmeAccount
+	err := r.db.Order("id asc").Find(&accounts).Error
+	return accounts, err
+}
+
+// UpdateAccountKey 只更新加密的账号私钥
+func (r *acmeRepository) UpdateAccountKey(account *model.AcmeAccount) error {
+	return r.db.Model(account).Select("private_ciphertext", "private_data_key", "private_key_id").Updates(account).Error
+}
+
+// CreateCertificate 创建证书
+func (r *acmeRepository) CreateCertificate(cert *model.AcmeCertificate) error {
+	return r.db.Create(cert).Error
+}
+
+// GetCertificate 根据ID获取证书
+func (r *acmeRepository) GetCertificate(id uint) (*model.AcmeCertificate, error) {
+	var cert model.AcmeCertificate
+	err := r.db.Where("id = ?", id).First(&cert).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("证书不存在")
+		}
+		return nil, err
+	}
+	return &cert, nil
+}
+
+// UpdateCertificate 更新证书
+func (r *acmeRepository) UpdateCertificate(cert *model.AcmeCertificate) error {
+	return r.db.Save(cert).Error
+}
+
+// DeleteCertificate 删除证书
+func (r *acmeRepository) DeleteCertificate(id uint) error {
+	return r.db.Delete(&model.AcmeCertificate{}, id).Error
+}
+
+// ListCertificates 获取证书列表，status 为空时返回全部状态
+func (r *acmeRepository) ListCertificates(status string, page pagination.Pagination) ([]*model.AcmeCertificate, int64, error) {
+	var certs []*model.AcmeCertificate
+	var total int64
+
+	query := r.db.Model(&model.AcmeCertificate{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	// 获取总数
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 分页查询
+	if err := query.Offset(page.Offset).Limit(page.Limit).Order(page.GetOrderClause()).Find(&certs).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return certs, total, nil
+}
+
+// ListRenewable 获取开启自动续期且在指定时间前到期的证书，上次续期失败的证书也会被返回以便重试
+func (r *acmeRepository) ListRenewable(before time.Time) ([]*model.AcmeCertificate, error) {
+	var certs []*model.AcmeCertificate
+	err := r.db.Where("auto_renew = ? AND not_after IS NOT NULL AND not_after < ?", true, before).
+		Order("not_after asc").Find(&certs).Error
+	return certs, err
+}
+
+// ListAllCertificates 获取全部证书
+func (r *acmeRepository) ListAllCertificates() ([]*model.AcmeCertificate, error) {
+	var certs []*model.AcmeCertificate
+	err := r.db.Order("id asc").Find(&certs).Error
+	return certs, err
+}
+
+// UpdateCertificateKey 只更新加密的证书私钥，不覆盖并发签发写入的证书内容
+func (r *acmeRepository) UpdateCertificateKey(cert *model.AcmeCertificate) error {
+	return r.db.Model(cert).Select("private_ciphertext", "private_data_key", "private_key_id").Updates(cert).Error
+}
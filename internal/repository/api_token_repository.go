@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"domain-admin/model"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// APITokenRepository API令牌仓储接口
+type APITokenRepository interface {
+	Create(token *model.APIToken) error
+	GetByID(id uint) (*model.APIToken, error)
+	GetByHash(hash string) (*model.APIToken, error)
+	ListByUser(userID uint) ([]*model.APIToken, error)
+	CountActive(userID uint, now time.Time) (int64, error)
+	Revoke(id uint, at time.Time) error
+	TouchLastUsed(id uint, at time.Time, ip string) error
+}
+
+type apiTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewAPITokenRepository 创建API令牌仓储实例
+func NewAPITokenRepository(db *gorm.DB) APITokenRepository {
+	return &apiTokenRepository{db: db}
+}
+
+// Create 创建令牌
+func (r *apiTokenRepository) Create(token *model.APIToken) error {
+	return r.db.Create(token).Error
+}
+
+// GetByID 根据ID获取令牌
+func (r *apiTokenRepository) GetByID(id uint) (*model.APIToken, error) {
+	var token model.APIToken
+	err := r.db.Where("id = ?", id).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("API令牌不存在")
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// GetByHash 根据令牌哈希获取令牌
+func (r *apiTokenRepository) GetByHash(hash string) (*model.APIToken, error) {
+	var token model.APIToken
+	err := r.db.Where("token_hash = ?", hash).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("API令牌不存在")
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// ListByUser 获取用户的全部令牌，按创建时间倒序
+func (r *apiTokenRepository) ListByUser(userID uint) ([]*model.APIToken, error) {
+	var tokens []*model.APIToken
+	err := r.db.Where("user_id = ?", userID).Order("id desc").Find(&tokens).Error
+	return tokens, err
+}
+
+// CountActive 统计用户未吊销且未过期的令牌数量
+func (r *apiTokenRepository) CountActive(userID uint, now time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.APIToken{}).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Count(&count).Error
+	return count, err
+}
+
+// Revoke 吊销令牌
+func (r *apiTokenRepository) Revoke(id uint, at time.Time) error {
+	return r.db.Model(&model.APIToken{}).Where("id = ? AND revoked_at IS NULL", id).Update("revoked_at", at).Error
+}
+
+// TouchLastUsed 记录令牌最近使用时间和IP
+func (r *apiTokenRepository) TouchLastUsed(id uint, at time.Time, ip string) error {
+	return r.db.Model(&model.APIToken{}).Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{"last_used_at": at, "last_used_ip": ip}).Error
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"domain-admin/model"
+	"domain-admin/pkg/pagination"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CertificateRepository 证书仓储接口
+type CertificateRepository interface {
+	Create(cert *model.Certificate) error
+	GetByID(id uint) (*model.Certificate, error)
+	GetByEndpoint(host string, port int) (*model.Certificate, error)
+	Update(cert *model.Certificate) error
+	Delete(id uint) error
+	List(status string, page pagination.Pagination) ([]*model.Certificate, int64, error)
+	ListAll() ([]*model.Certificate, error)
+	CountExpiringBefore(t time.Time) (int64, error)
+}
+
+type certificateRepository struct {
+	db *gorm.DB
+}
+
+// NewCertificateRepository 创建证书仓储实例
+func NewCertificateRepository(db *gorm.DB) CertificateRepository {
+	return &certificateRepository{db: db}
+}
+
+// Create 创建证书探测目标
+func (r *certificateRepository) Create(cert *model.Certificate) error {
+	return r.db.Create(cert).Error
+}
+
+// GetByID 根据ID获取证书
+func (r *certificateRepository) GetByID(id uint) (*model.Certificate, error) {
+	var cert model.Certificate
+	err := r.db.Where("id = ?", id).First(&cert).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("证书不存在")
+		}
+		return nil, err
+	}
+	return &cert, nil
+}
+
+// GetByEndpoint 根据主机名和端口获取证书
+func (r *certificateRepository) GetByEndpoint(host string, port int) (*model.Certificate, error) {
+	var cert model.Certificate
+	err := r.db.Where("host = ? AND port = ?", host, port).First(&cert).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("证书不存在")
+		}
+		return nil, err
+	}
+	return &cert, nil
+}
+
+// Update 更新证书
+func (r *certificateRepository) Update(cert *model.Certificate) error {
+	return r.db.Save(cert).Error
+}
+
+// Delete 删除证书
+func (r *certificateRepository) Delete(id uint) error {
+	return r.db.Delete(&model.Certificate{}, id).Error
+}
+
+// List 获取证书列表，status 为空时返回全部状态
+func (r *certificateRepository) List(status string, page pagination.Pagination) ([]*model.Certificate, int64, error) {
+	var certs []*model.Certificate
+	var total int64
+
+	query := r.db.Model(&model.Certificate{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	// 获取总数
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 分页查询
+	if err := query.Offset(page.Offset).Limit(page.Limit).Order(page.GetOrderClause()).Find(&certs).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return certs, total, nil
+}
+
+// ListAll 获取全部证书
+func (r *certificateRepository) ListAll() ([]*model.Certificate, error) {
+	var certs []*model.Certificate
+	err := r.db.Order("id asc").Find(&certs).Error
+	return certs, err
+}
+
+// CountExpiringBefore 统计在指定时间前到期的证书数量（包含已过期）
+func (r *certificateRepository) CountExpiringBefore(t time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Certificate{}).Where("not_after IS NOT NULL AND not_after < ?", t).Count(&count).Error
+	return count, err
+}
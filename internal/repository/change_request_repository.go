@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"domain-admin/model"
+	"domain-admin/pkg/pagination"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ChangeRequestRepository 变更请求仓储接口
+type ChangeRequestRepository interface {
+	Create(change *model.ChangeRequest) error
+	GetByID(id uint) (*model.ChangeRequest, error)
+	Update(change *model.ChangeRequest) error
+	Transition(id uint, from string, updates map[string]interface{}) (bool, error)
+	List(status string, requesterID uint, page pagination.Pagination) ([]*model.ChangeRequest, int64, error)
+	ExpirePending(now time.Time) (int64, error)
+}
+
+type changeRequestRepository struct {
+	db *gorm.DB
+}
+
+// NewChangeRequestRepository 创建变更请求仓储实例
+func NewChangeRequestRepository(db *gorm.DB) ChangeRequestRepository {
+	return &changeRequestRepository{db: db}
+}
+
+// Create 创建变更请求
+func (r *changeRequestRepository) Create(change *model.ChangeRequest) error {
+	return r.db.Create(change).Error
+}
+
+// GetByID 根据ID获取变更请求
+func (r *changeRequestRepository) GetByID(id uint) (*model.ChangeRequest, error) {
+	var change model.ChangeRequest
+	err := r.db.Where("id = ?", id).First(&change).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("变更请求不存在")
+		}
+		return nil, err
+	}
+	return &change, nil
+}
+
+// Update 更新变更请求
+func (r *changeRequestRepository) Update(change *model.ChangeRequest) error {
+	return r.db.Save(change).Error
+}
+
+// Transition 仅当变更请求处于 from 状态时更新，返回是否更新成功，用于防止重复审批
+func (r *changeRequestRepository) Transition(id uint, from string, updates map[string]interface{}) (bool, error) {
+	result := r.db.Model(&model.ChangeRequest{}).Where("id = ? AND status = ?", id, from).Updates(updates)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// List 获取变更请求列表，status 为空时返回全部状态，requesterID 为0时返回所有人的请求
+func (r *changeRequestRepository) List(status string, requesterID uint, page pagination.Pagination) ([]*model.ChangeRequest, int64, error) {
+	var changes []*model.ChangeRequest
+	var total int64
+
+	query := r.db.Model(&model.ChangeRequest{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if requesterID != 0 {
+		query = query.Where("requester_id = ?", requesterID)
+	}
+
+	// 获取总数
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 分页查询
+	if err := query.Offset(page.Offset).Limit(page.Limit).Order(page.GetOrderClause()).Find(&changes).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return changes, total, nil
+}
+
+// ExpirePending 将已过期的待审批请求标记为过期，返回更新数量
+func (r *changeRequestRepository) ExpirePending(now time.Time) (int64, error) {
+	result := r.db.Model(&model.ChangeRequest{}).
+		Where("status = ? AND expires_at < ?", model.ChangeStatusPending, now).
+		Update("status", model.ChangeStatusExpired)
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"domain-admin/model"
+	"domain-admin/pkg/pagination"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// DNSRecordRepository DNS记录仓储接口
+type DNSRecordRepository interface {
+	Create(record *model.DNSRecord) error
+	GetByID(zoneID, id uint) (*model.DNSRecord, error)
+	Update(record *model.DNSRecord) error
+	Delete(zoneID, id uint) error
+	List(zoneID uint, page pagination.Pagination) ([]*model.DNSRecord, int64, error)
+	ListByName(zoneID uint, name string) ([]*model.DNSRecord, error)
+	ListAll(zoneID uint) ([]*model.DNSRecord, error)
+	CreateBatch(records []*model.DNSRecord) error
+}
+
+type dnsRecordRepository struct {
+	db *gorm.DB
+}
+
+// NewDNSRecordRepository 创建DNS记录仓储实例
+func NewDNSRecordRepository(db *gorm.DB) DNSRecordRepository {
+	return &dnsRecordRepository{db: db}
+}
+
+// Create 创建记录
+func (r *dnsRecordRepository) Create(record *model.DNSRecord) error {
+	return r.db.Create(record).Error
+}
+
+// GetByID 根据区域ID和记录ID获取记录
+func (r *dnsRecordRepository) GetByID(zoneID, id uint) (*model.DNSRecord, error) {
+	var record model.DNSRecord
+	err := r.db.Where("zone_id = ? AND id = ?", zoneID, id).First(&record).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("记录不存在")
+		}
+		return nil, err
+	}
+	return &record, nil
+}
+
+// Update 更新记录
+func (r *dnsRecordRepository) Update(record *model.DNSRecord) error {
+	return r.db.Save(record).Error
+}
+
+// Delete 删除记录
+func (r *dnsRecordRepository) Delete(zoneID, id uint) error {
+	return r.db.Where("zone_id = ?", zoneID).Delete(&model.DNSRecord{}, id).Error
+}
+
+// List 分页获取区域下的记录
+func (r *dnsRecordRepository) List(zoneID uint, page pagination.Pagination) ([]*model.DNSRecord, int64, error) {
+	var records []*model.DNSRecord
+	var total int64
+
+	query := r.db.Model(&model.DNSRecord{}).Where("zone_id = ?", zoneID)
+
+	// 获取总数
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 分页查询
+	if err := query.Offset(page.Offset).Limit(page.Limit).Order(page.GetOrderClause()).Find(&records).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return records, total, nil
+}
+
+// ListByName 获取区域下指定主机记录的全部记录
+func (r *dnsRecordRepository) ListByName(zoneID uint, name string) ([]*model.DNSRecord, error) {
+	var records []*model.DNSRecord
+	err := r.db.Where("zone_id = ? AND name = ?", zoneID, name).Find(&records).Error
+	return records, err
+}
+
+// ListAll 获取区域下的全部记录
+func (r *dnsRecordRepository) ListAll(zoneID uint) ([]*model.DNSRecord, error) {
+	var records []*model.DNSRecord
+	err := r.db.Where("zone_id = ?", zoneID).Order("name asc, type asc, id asc").Find(&records).Error
+	return records, err
+}
+
+// CreateBatch 在同一事务中批量创建记录
+func (r *dnsRecordRepository) CreateBatch(records []*model.DNSRecord) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return tx.CreateInBatches(records, 100).Error
+	})
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"domain-admin/model"
+	"domain-admin/pkg/pagination"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// DNSZoneRepository DNS区域仓储接口
+type DNSZoneRepository interface {
+	Create(zone *model.DNSZone) error
+	GetByID(id uint) (*model.DNSZone, error)
+	GetByName(name string) (*model.DNSZone, error)
+	Update(zone *model.DNSZone) error
+	Delete(id uint) error
+	PurgeDeleted(name string) error
+	List(page pagination.Pagination) ([]*model.DNSZone, int64, error)
+	Count() (int64, error)
+}
+
+type dnsZoneRepository struct {
+	db *gorm.DB
+}
+
+// NewDNSZoneRepository 创建DNS区域仓储实例
+func NewDNSZoneRepository(db *gorm.DB) DNSZoneRepository {
+	return &dnsZoneRepository{db: db}
+}
+
+// Create 创建区域
+func (r *dnsZoneRepository) Create(zone *model.DNSZone) error {
+	return r.db.Create(zone).Error
+}
+
+// GetByID 根据ID获取区域
+func (r *dnsZoneRepository) GetByID(id uint) (*model.DNSZone, error) {
+	var zone model.DNSZone
+	err := r.db.Where("id = ?", id).First(&zone).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("区域不存在")
+		}
+		return nil, err
+	}
+	return &zone, nil
+}
+
+// GetByName 根据名称获取区域
+func (r *dnsZoneRepository) GetByName(name string) (*model.DNSZone, error) {
+	var zone model.DNSZone
+	err := r.db.Where("name = ?", name).First(&zone).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("区域不存在")
+		}
+		return nil, err
+	}
+	return &zone, nil
+}
+
+// Update 更新区域
+func (r *dnsZoneRepository) Update(zone *model.DNSZone) error {
+	return r.db.Omit("Records").Save(zone).Error
+}
+
+// Delete 删除区域及其下的全部记录
+func (r *dnsZoneRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("zone_id = ?", id).Delete(&model.DNSRecord{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.DNSZone{}, id).Error
+	})
+}
+
+// PurgeDeleted 彻底删除已软删除的同名区域及其记录，释放名称的唯一索引
+func (r *dnsZoneRepository) PurgeDeleted(name string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var ids []uint
+		if err := tx.Unscoped().Model(&model.DNSZone{}).Where("name = ? AND deleted_at IS NOT NULL", name).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		if err := tx.Unscoped().Where("zone_id IN ?", ids).Delete(&model.DNSRecord{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&model.DNSZone{}, ids).Error
+	})
+}
+
+// List 获取区域列表
+func (r *dnsZoneRepository) List(page pagination.Pagination) ([]*model.DNSZone, int64, error) {
+	var zones []*model.DNSZone
+	var total int64
+
+	query := r.db.Model(&model.DNSZone{})
+
+	// 获取总数
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 分页查询
+	if err := query.Offset(page.Offset).Limit(page.Limit).Order(page.GetOrderClause()).Find(&zones).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return zones, total, nil
+}
+
+// Count 获取区域总数
+func (r *dnsZoneRepository) Count() (int64, error) {
+	var count int64
+	err := r.db.Model(&model.DNSZone{}).Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"domain-admin/model"
+	"domain-admin/pkg/pagination"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DomainRepository 域名仓储接口
+type DomainRepository interface {
+	Create(domain *model.Domain) error
+	GetByID(id uint) (*model.Domain, error)
+	GetByName(name string) (*model.Domain, error)
+	Update(domain *model.Domain) error
+	Delete(id uint) error
+	PurgeDeleted(name string) error
+	List(page pagination.Pagination) ([]*model.Domain, int64, error)
+	Count() (int64, error)
+	ListExpiringBefore(t time.Time) ([]*model.Domain, error)
+	MarkExpired(before time.Time) (int64, error)
+}
+
+type domainRepository struct {
+	db *gorm.DB
+}
+
+// NewDomainRepository 创建域名仓储实例
+func NewDomainRepository(db *gorm.DB) DomainRepository {
+	return &domainRepository{db: db}
+}
+
+// Create 创建域名
+func (r *domainRepository) Create(domain *model.Domain) error {
+	return r.db.Create(domain).Error
+}
+
+// GetByID 根据ID获取域名
+func (r *domainRepository) GetByID(id uint) (*model.Domain, error) {
+	var domain model.Domain
+	err := r.db.Preload("Owner").Where("id = ?", id).First(&domain).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("域名不存在")
+		}
+		return nil, err
+	}
+	return &domain, nil
+}
+
+// GetByName 根据名称获取域名
+func (r *domainRepository) GetByName(name string) (*model.Domain, error) {
+	var domain model.Domain
+	err := r.db.Where("name = ?", name).First(&domain).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("域名不存在")
+		}
+		return nil, err
+	}
+	return &domain, nil
+}
+
+// Update 更新域名
+func (r *domainRepository) Update(domain *model.Domain) error {
+	return r.db.Omit("Owner").Save(domain).Error
+}
+
+// Delete 删除域名
+func (r *domainRepository) Delete(id uint) error {
+	return r.db.Delete(&model.Domain{}, id).Error
+}
+
+// PurgeDeleted 彻底删除已软删除的同名域名，释放名称的唯一索引
+func (r *domainRepository) PurgeDeleted(name string) error {
+	return r.db.Unscoped().Where("name = ? AND deleted_at IS NOT NULL", name).Delete(&model.Domain{}).Error
+}
+
+// List 获取域名列表
+func (r *domainRepository) List(page pagination.Pagination) ([]*model.Domain, int64, error) {
+	var domains []*model.Domain
+	var total int64
+
+	query := r.db.Model(&model.Domain{})
+
+	// 获取总数
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 分页查询
+	if err := query.Preload("Owner").Offset(page.Offset).Limit(page.Limit).Order(page.GetOrderClause()).Find(&domains).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return domains, total, nil
+}
+
+// Count 获取域名总数
+func (r *domainRepository) Count() (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Domain{}).Count(&count).Error
+	return count, err
+}
+
+// ListExpiringBefore 获取在指定时间前到期且未标记为过期的域名
+func (r *domainRepository) ListExpiringBefore(t time.Time) ([]*model.Domain, error) {
+	var domains []*model.Domain
+	err := r.db.Where("expires_at IS NOT NULL AND expires_at < ? AND status <> ?", t, model.DomainStatusExpired).
+		Order("expires_at asc").Find(&domains).Error
+	return domains, err
+}
+
+// MarkExpired 将在指定时间前到期的正常域名标记为过期
+func (r *domainRepository) MarkExpired(before time.Time) (int64, error) {
+	result := r.db.Model(&model.Domain{}).
+		Where("expires_at IS NOT NULL AND expires_at < ? AND status = ?", before, model.DomainStatusActive).
+		Update("status", model.DomainStatusExpired)
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"domain-admin/model"
+	"domain-admin/pkg/pagination"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// JobRepository 定时任务仓储接口
+type JobRepository interface {
+	CreateRun(run *model.JobRun) error
+	FinishRun(run *model.JobRun) error
+	ListRuns(jobName string, page pagination.Pagination) ([]*model.JobRun, int64, error)
+	DeleteRunsBefore(t time.Time) (int64, error)
+	IsPaused(jobName string) (bool, error)
+	SetPaused(jobName string, paused bool) error
+}
+
+type jobRepository struct {
+	db *gorm.DB
+}
+
+// NewJobRepository 创建定时任务仓储实例
+func NewJobRepository(db *gorm.DB) JobRepository {
+	return &jobRepository{db: db}
+}
+
+// CreateRun 创建执行记录
+func (r *jobRepository) CreateRun(run *model.JobRun) error {
+	return r.db.Create(run).Error
+}
+
+// FinishRun 保存执行结果
+func (r *jobRepository) FinishRun(run *model.JobRun) error {
+	return r.db.Model(&model.JobRun{ID: run.ID}).
+		Select("status", "error", "finished_at", "duration").
+		Updates(run).Error
+}
+
+// ListRuns 获取执行记录列表，jobName 为空时返回全部任务
+func (r *jobRepository) ListRuns(jobName string, page pagination.Pagination) ([]*model.JobRun, int64, error) {
+	var runs []*model.JobRun
+	var total int64
+
+	query := r.db.Model(&model.JobRun{})
+	if jobName != "" {
+		query = query.Where("job_name = ?", jobName)
+	}
+
+	// 获取总数
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 分页查询
+	if err := query.Offset(page.Offset).Limit(page.Limit).Order(page.GetOrderClause()).Find(&runs).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return runs, total, nil
+}
+
+// DeleteRunsBefore 删除指定时间前开始的执行记录
+func (r *jobRepository) DeleteRunsBefore(t time.Time) (int64, error) {
+	result := r.db.Where("started_at < ?", t).Delete(&model.JobRun{})
+	return result.RowsAffected, result.Error
+}
+
+// IsPaused 查询任务是否已暂停
+func (r *jobRepository) IsPaused(jobName string) (bool, error) {
+	var state model.JobState
+	err := r.db.Where("job_name = ?", jobName).First(&state).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return state.Paused, nil
+}
+
+// SetPaused 设置任务暂停状态
+func (r *jobRepository) SetPaused(jobName string, paused bool) error {
+	var state model.JobState
+	err := r.db.Where("job_name = ?", jobName).First(&state).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return r.db.Create(&model.JobState{JobName: jobName, Paused: paused}).Error
+	}
+	if err != nil {
+		return err
+	}
+	return r.db.Model(&state).Update("paused", paused).Error
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"domain-admin/model"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// JWTKeyRepository JWT签名密钥仓储接口
+type JWTKeyRepository interface {
+	Create(key *model.JWTKey) error
+	GetByID(id uint) (*model.JWTKey, error)
+	List() ([]*model.JWTKey, error)
+	ListActive() ([]*model.JWTKey, error)
+	Retire(id uint, at time.Time) error
+	UpdatePrivateKey(key *model.JWTKey) error
+}
+
+type jwtKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewJWTKeyRepository 创建JWT签名密钥仓储实例
+func NewJWTKeyRepository(db *gorm.DB) JWTKeyRepository {
+	return &jwtKeyRepository{db: db}
+}
+
+// Create 创建密钥
+func (r *jwtKeyRepository) Create(key *model.JWTKey) error {
+	return r.db.Create(key).Error
+}
+
+// GetByID 根据ID获取密钥
+func (r *jwtKeyRepository) GetByID(id uint) (*model.JWTKey, error) {
+	var key model.JWTKey
+	err := r.db.Where("id = ?", id).First(&key).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("签名密钥不存在")
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+// List 获取全部密钥，按创建时间倒序
+func (r *jwtKeyRepository) List() ([]*model.JWTKey, error) {
+	var keys []*model.JWTKey
+	err := r.db.Order("id desc").Find(&keys).Error
+	return keys, err
+}
+
+// ListActive 获取未停用的密钥，按创建时间倒序
+func (r *jwtKeyRepository) ListActive() ([]*model.JWTKey, error) {
+	var keys []*model.JWTKey
+	err := r.db.Where("retired_at IS NULL").Order("id desc").Find(&keys).Error
+	return keys, err
+}
+
+// Retire 停用密钥
+func (r *jwtKeyRepository) Retire(id uint, at time.Time) error {
+	return r.db.Model(&model.JWTKey{}).Where("id = ? AND retired_at IS NULL", id).Update("retired_at", at).Error
+}
+
+// UpdatePrivateKey 只更新加密的私钥
+func (r *jwtKeyRepository) UpdatePrivateKey(key *model.JWTKey) error {
+	return r.db.Model(key).Select("private_ciphertext", "private_data_key", "private_key_id").Updates(key).Error
+}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"domain-admin/model"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// OAuthRepository OAuth2授权服务仓储接口
+type OAuthRepository interface {
+	CreateClient(client *model.OAuthClient) error
+	GetClientByID(id uint) (*model.OAuthClient, error)
+	GetClientByClientID(clientID string) (*model.OAuthClient, error)
+	ListClients() ([]*model.OAuthClient, error)
+	UpdateClient(client *model.OAuthClient) error
+	DeleteClient(client *model.OAuthClient, at time.Time) error
+
+	CreateCode(code *model.OAuthAuthorizationCode) error
+	GetCodeByHash(hash string) (*model.OAuthAuthorizationCode, error)
+	UseCode(id uint, at time.Time) error
+
+	CreateToken(token *model.OAuthAccessToken) error
+	GetTokenByHash(hash string) (*model.OAuthAccessToken, error)
+	RevokeToken(id uint, at time.Time) error
+	RevokeTokensByCode(codeID uint, at time.Time) (int64, error)
+	DeleteExpired(before time.Time) (int64, error)
+}
+
+type oauthRepository struct {
+	db *gorm.DB
+}
+
+// NewOAuthRepository 创建OAuth2授权服务仓储实例
+func NewOAuthRepository(db *gorm.DB) OAuthRepository {
+	return &oauthRepository{db: db}
+}
+
+// CreateClient 创建应用
+func (r *oauthRepository) CreateClient(client *model.OAuthClient) error {
+	return r.db.Create(client).Error
+}
+
+// GetClientByID 根据ID获取应用
+func (r *oauthRepository) GetClientByID(id uint) (*model.OAuthClient, error) {
+	var client model.OAuthClient
+	if err := r.db.First(&client, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("应用不存在")
+		}
+		return nil, err
+	}
+	return &client, nil
+}
+
+// GetClientByClientID 根据客户端ID获取应用
+func (r *oauthRepository) GetClientByClientID(clientID string) (*model.OAuthClient, error) {
+	var client model.OAuthClient
+	if err := r.db.Where("client_id = ?", clientID).First(&client).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("应用不存在")
+		}
+		return nil, err
+	}
+	return &client, nil
+}
+
+// ListClients 获取全部应用
+func (r *oauthRepository) ListClients() ([]*model.OAuthClient, error) {
+	var clients []*model.OAuthClient
+	err := r.db.Order("id").Find(&clients).Error
+	return clients, err
+}
+
+// UpdateClient 更新应用
+func (r *oauthRepository) UpdateClient(client *model.OAuthClient) error {
+	return r.db.Save(client).Error
+}
+
+// DeleteClient 删除应用并吊销其全部访问令牌
+func (r *oauthRepository) DeleteClient(client *model.OAuthClient, at time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.OAuthAccessToken{}).
+			Where("client_id = ? AND revoked_at IS NULL", client.ClientID).
+			Update("revoked_at", at).Error; err != nil {
+			return err
+		}
+		return tx.Delete(client).Error
+	})
+}
+
+// CreateCode 保存授权码
+func (r *oauthRepository) CreateCode(code *model.OAuthAuthorizationCode) error {
+	return r.db.Create(code).Error
+}
+
+// GetCodeByHash 根据授权码哈希获取授权码
+func (r *oauthRepository) GetCodeByHash(hash string) (*model.OAuthAuthorizationCode, error) {
+	var code model.OAuthAuthorizationCode
+	if err := r.db.Where("code_hash = ?", hash).First(&code).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("授权码不存在")
+		}
+		return nil, err
+	}
+	return &code, nil
+}
+
+// UseCode 标记授权码已使用，已被使用时返回错误
+func (r *oauthRepository) UseCode(id uint, at time.Time) error {
+	result := r.db.Model(&model.OAuthAuthorizationCode{}).Where("id = ? AND used_at IS NULL", id).Update("used_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("授权码已使用")
+	}
+	return nil
+}
+
+// CreateToken 保存访问令牌
+func (r *oauthRepository) CreateToken(token *model.OAuthAccessToken) error {
+	return r.db.Create(token).Error
+}
+
+// GetTokenByHash 根据令牌哈希获取访问令牌
+func (r *oauthRepository) GetTokenByHash(hash string) (*model.OAuthAccessToken, error) {
+	var token model.OAuthAccessToken
+	if err := r.db.Where("token_hash = ?", hash).First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("访问令牌不存在")
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// RevokeToken 吊销访问令牌
+func (r *oauthRepository) RevokeToken(id uint, at time.Time) error {
+	return r.db.Model(&model.OAuthAccessToken{}).Where("id = ? AND revoked_at IS NULL", id).Update("revoked_at", at).Error
+}
+
+// RevokeTokensByCode 吊销由授权码换取的全部令牌，返回吊销数量
+func (r *oauthRepository) RevokeTokensByCode(codeID uint, at time.Time) (int64, error) {
+	result := r.db.Model(&model.OAuthAccessToken{}).
+		Where("authorization_code_id = ? AND revoked_at IS NULL", codeID).
+		Update("revoked_at", at)
+	return result.RowsAffected, result.Error
+}
+
+// DeleteExpired 删除在指定时间之前过期的授权码和访问令牌，返回删除数量
+func (r *oauthRepository) DeleteExpired(before time.Time) (int64, error) {
+	var total int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("expires_at < ?", before).Delete(&model.OAuthAuthorizationCode{})
+		if result.Error != nil {
+			return result.Error
+		}
+		total += result.RowsAffected
+		result = tx.Where("expires_at < ?", before).Delete(&model.OAuthAccessToken{})
+		if result.Error != nil {
+			return result.Error
+		}
+		total += result.RowsAffected
+		return nil
+	})
+	return total, err
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"domain-admin/model"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// OIDCRepository 单点登录仓储接口
+type OIDCRepository interface {
+	CreateState(state *model.OIDCLoginState) error
+	UseState(hash string, at time.Time) (*model.OIDCLoginState, error)
+}
+
+type oidcRepository struct {
+	db *gorm.DB
+}
+
+// NewOIDCRepository 创建单点登录仓储实例
+func NewOIDCRepository(db *gorm.DB) OIDCRepository {
+	return &oidcRepository{db: db}
+}
+
+// CreateState 保存授权请求状态
+func (r *oidcRepository) CreateState(state *model.OIDCLoginState) error {
+	return r.db.Create(state).Error
+}
+
+// UseState 根据 state 哈希获取授权请求状态并标记为已使用，不存在或已被使用时返回错误
+func (r *oidcRepository) UseState(hash string, at time.Time) (*model.OIDCLoginState, error) {
+	var state model.OIDCLoginState
+	if err := r.db.Where("state_hash = ?", hash).First(&state).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("授权请求不存在")
+		}
+		return nil, err
+	}
+	result := r.db.Model(&model.OIDCLoginState{}).Where("id = ? AND used_at IS NULL", state.ID).Update("used_at", at)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("授权请求已使用")
+	}
+	state.UsedAt = &at
+	return &state, nil
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"domain-admin/model"
 	"domain-admin/pkg/pagination"
 	"errors"
@@ -13,8 +14,8 @@ type PermissionRepository interface {
 	Create(permission *model.Permission) error
 	GetByID(id uint) (*model.Permission, error)
 	GetByName(name string) (*model.Permission, error)
-	Update(permission *model.Permission) error
-	Delete(id uint) error
+	Update(ctx context.Context, permission *model.Permission) error
+	Delete(ctx context.Context, id uint) error
 	List(page pagination.Pagination) ([]*model.Permission, int64, error)
 	UpdateStatus(id uint, status int) error
 	GetPermissionsByRole(roleID uint) ([]*model.Permission, error)
@@ -61,14 +62,36 @@ func (r *permissionRepository) GetByName(name string) (*model.Permission, error)
 	return &permission, nil
 }
 
-// Update 更新权限
-func (r *permissionRepository) Update(permission *model.Permission) error {
-	return r.db.Save(permission).Error
+// Update 更新权限，同时写入修订记录
+func (r *permissionRepository) Update(ctx context.Context, permission *model.Permission) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var before model.Permission
+		if err := tx.First(&before, permission.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Save(permission).Error; err != nil {
+			return err
+		}
+		var after model.Permission
+		if err := tx.First(&after, permission.ID).Error; err != nil {
+			return err
+		}
+		return recordRevision(ctx, tx, model.RevisionEntityPermission, permission.ID, model.RevisionActionUpdate, &before, &after)
+	})
 }
 
-// Delete 删除权限
-func (r *permissionRepository) Delete(id uint) error {
-	return r.db.Delete(&model.Permission{}, id).Error
+// Delete 删除权限，同时写入修订记录
+func (r *permissionRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var before model.Permission
+		if err := tx.First(&before, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&model.Permission{}, id).Error; err != nil {
+			return err
+		}
+		return recordRevision(ctx, tx, model.RevisionEntityPermission, id, model.RevisionActionDelete, &before, nil)
+	})
 }
 
 // List 获取权限列表
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"domain-admin/model"
+	"domain-admin/pkg/pagination"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ProviderAccountRepository 云服务商账号仓储接口
+type ProviderAccountRepository interface {
+	Create(account *model.ProviderAccount) error
+	GetByID(id uint) (*model.ProviderAccount, error)
+	GetByName(name string) (*model.ProviderAccount, error)
+	Update(account *model.ProviderAccount) error
+	Delete(id uint) error
+	List(page pagination.Pagination) ([]*model.ProviderAccount, int64, error)
+	ListAll() ([]*model.ProviderAccount, error)
+}
+
+type providerAccountRepository struct {
+	db *gorm.DB
+}
+
+// NewProviderAccountRepository 创建云服务商账号仓储实例
+func NewProviderAccountRepository(db *gorm.DB) ProviderAccountRepository {
+	return &providerAccountRepository{db: db}
+}
+
+// Create 创建账号
+func (r *providerAccountRepository) Create(account *model.ProviderAccount) error {
+	return r.db.Create(account).Error
+}
+
+// GetByID 根据ID获取账号
+func (r *providerAccountRepository) GetByID(id uint) (*model.ProviderAccount, error) {
+	var account model.ProviderAccount
+	err := r.db.Where("id = ?", id).First(&account).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("云服务商账号不存在")
+		}
+		return nil, err
+	}
+	return &account, nil
+}
+
+// GetByName 根据名称获取账号
+func (r *providerAccountRepository) GetByName(name string) (*model.ProviderAccount, error) {
+	var account model.ProviderAccount
+	err := r.db.Where("name = ?", name).First(&account).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("云服务商账号不存在")
+		}
+		return nil, err
+	}
+	return &account, nil
+}
+
+// Update 更新账号
+func (r *providerAccountRepository) Update(account *model.ProviderAccount) error {
+	return r.db.Save(account).Error
+}
+
+// Delete 删除账号
+func (r *providerAccountRepository) Delete(id uint) error {
+	return r.db.Delete(&model.ProviderAccount{}, id).Error
+}
+
+// List 分页获取账号列表
+func (r *providerAccountRepository) List(page pagination.Pagination) ([]*model.ProviderAccount, int64, error) {
+	var accounts []*model.ProviderAccount
+	var total int64
+
+	query := r.db.Model(&model.ProviderAccount{})
+
+	// 获取总数
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 分页查询
+	if err := query.Offset(page.Offset).Limit(page.Limit).Order(page.GetOrderClause()).Find(&accounts).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return accounts, total, nil
+}
+
+// ListAll 获取全部账号
+func (r *providerAccountRepository) ListAll() ([]*model.ProviderAccount, error) {
+	var accounts []*model.ProviderAccount
+	err := r.db.Order("id asc").Find(&accounts).Error
+	return accounts, err
+}
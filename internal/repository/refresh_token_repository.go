@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"domain-admin/model"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrRefreshTokenUsed 刷新令牌已被轮换
+var ErrRefreshTokenUsed = errors.New("刷新令牌已被使用")
+
+// RefreshTokenRepository 刷新令牌仓储接口
+type RefreshTokenRepository interface {
+	Create(token *model.RefreshToken) error
+	GetByHash(hash string) (*model.RefreshToken, error)
+	Rotate(old *model.RefreshToken, next *model.RefreshToken, at time.Time) error
+	RevokeFamily(familyID string, at time.Time) error
+	RevokeByUser(userID uint, at time.Time) error
+}
+
+type refreshTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewRefreshTokenRepository 创建刷新令牌仓储实例
+func NewRefreshTokenRepository(db *gorm.DB) RefreshTokenRepository {
+	return &refreshTokenRepository{db: db}
+}
+
+// Create 创建令牌
+func (r *refreshTokenRepository) Create(token *model.RefreshToken) error {
+	return r.db.Create(token).Error
+}
+
+// GetByHash 根据令牌哈希获取令牌
+func (r *refreshTokenRepository) GetByHash(hash string) (*model.RefreshToken, error) {
+	var token model.RefreshToken
+	err := r.db.Where("token_hash = ?", hash).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("刷新令牌不存在")
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Rotate 将旧令牌标记为已使用并创建新令牌，旧令牌已被使用或吊销时返回 ErrRefreshTokenUsed
+func (r *refreshTokenRepository) Rotate(old *model.RefreshToken, next *model.RefreshToken, at time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.RefreshToken{}).
+			Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", old.ID).
+			Update("used_at", at)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrRefreshTokenUsed
+		}
+		return tx.Create(next).Error
+	})
+}
+
+// RevokeFamily 吊销令牌族中的全部令牌
+func (r *refreshTokenRepository) RevokeFamily(familyID string, at time.Time) error {
+	return r.db.Model(&model.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", at).Error
+}
+
+// RevokeByUser 吊销用户的全部令牌
+func (r *refreshTokenRepository) RevokeByUser(userID uint, at time.Time) error {
+	return r.db.Model(&model.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", at).Error
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"domain-admin/model"
+	"domain-admin/pkg/pagination"
+	"encoding/json"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// RevisionRepository 修订记录仓储接口，修订记录由各实体仓储在修改时写入
+type RevisionRepository interface {
+	List(entityType string, entityID uint, page pagination.Pagination) ([]*model.Revision, int64, error)
+	GetByRev(entityType string, entityID uint, rev int) (*model.Revision, error)
+}
+
+type revisionRepository struct {
+	db *gorm.DB
+}
+
+// NewRevisionRepository 创建修订记录仓储实例
+func NewRevisionRepository(db *gorm.DB) RevisionRepository {
+	return &revisionRepository{db: db}
+}
+
+// List 获取实体的修订记录，按修订号倒序
+func (r *revisionRepository) List(entityType string, entityID uint, page pagination.Pagination) ([]*model.Revision, int64, error) {
+	var revisions []*model.Revision
+	var total int64
+
+	query := r.db.Model(&model.Revision{}).Where("entity_type = ? AND entity_id = ?", entityType, entityID)
+
+	// 获取总数
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 分页查询
+	if err := query.Offset(page.Offset).Limit(page.Limit).Order("rev desc").Find(&revisions).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return revisions, total, nil
+}
+
+// GetByRev 根据修订号获取修订记录
+func (r *revisionRepository) GetByRev(entityType string, entityID uint, rev int) (*model.Revision, error) {
+	var revision model.Revision
+	err := r.db.Where("entity_type = ? AND entity_id = ? AND rev = ?", entityType, entityID, rev).First(&revision).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("修订记录不存在")
+		}
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// recordRevision 在修改实体的事务中写入修订记录
+// 操作人取自 ctx 中的 userID 和 username（gin.Context 可直接作为 ctx 传入）
+func recordRevision(ctx context.Context, tx *gorm.DB, entityType string, entityID uint, action string, before, after interface{}) error {
+	beforeJSON, err := json.Marshal(before)
+	if err != nil {
+		return err
+	}
+	afterJSON, err := json.Marshal(after)
+	if err != nil {
+		return err
+	}
+
+	var last int
+	if err := tx.Model(&model.Revision{}).Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Select("COALESCE(MAX(rev), 0)").Scan(&last).Error; err != nil {
+		return err
+	}
+
+	revision := &model.Revision{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Rev:        last + 1,
+		Action:     action,
+		Before:     beforeJSON,
+		After:      afterJSON,
+	}
+	if ctx != nil {
+		revision.ActorID, _ = ctx.Value("userID").(uint)
+		revision.ActorName, _ = ctx.Value("username").(string)
+	}
+	return tx.Create(revision).Error
+}
@@ -1,9 +1,11 @@
 package repository
 
 import (
+	"context"
 	"domain-admin/model"
 	"domain-admin/pkg/pagination"
 	"errors"
+	"sort"
 
 	"gorm.io/gorm"
 )
@@ -13,11 +15,13 @@ type RoleRepository interface {
 	Create(role *model.Role) error
 	GetByID(id uint) (*model.Role, error)
 	GetByName(name string) (*model.Role, error)
-	Update(role *model.Role) error
+	Update(ctx context.Context, role *model.Role) error
 	Delete(id uint) error
 	List(page pagination.Pagination) ([]*model.Role, int64, error)
+	ListAll() ([]*model.Role, error)
 	UpdateStatus(id uint, status int) error
-	AssignPermissions(roleID uint, permissionIDs []uint) error
+	AssignPermissions(ctx context.Context, roleID uint, permissionIDs []uint) error
+	Restore(ctx context.Context, roleID uint, snapshot *model.RoleSnapshot) error
 	GetRolePermissions(roleID uint) ([]*model.Permission, error)
 	Count() (int64, error)
 }
@@ -62,9 +66,22 @@ func (r *roleRepository) GetByName(name string) (*model.Role, error) {
 	return &role, nil
 }
 
-// Update 更新角色
-func (r *roleRepository) Update(role *model.Role) error {
-	return r.db.Save(role).Error
+// Update 更新角色，同时写入修订记录
+func (r *roleRepository) Update(ctx context.Context, role *model.Role) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		before, err := roleSnapshot(tx, role.ID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Save(role).Error; err != nil {
+			return err
+		}
+		after, err := roleSnapshot(tx, role.ID)
+		if err != nil {
+			return err
+		}
+		return recordRevision(ctx, tx, model.RevisionEntityRole, role.ID, model.RevisionActionUpdate, before, after)
+	})
 }
 
 // Delete 删除角色
@@ -92,13 +109,20 @@ func (r *roleRepository) List(page pagination.Pagination) ([]*model.Role, int64,
 	return roles, total, nil
 }
 
+// ListAll 获取全部角色
+func (r *roleRepository) ListAll() ([]*model.Role, error) {
+	var roles []*model.Role
+	err := r.db.Order("id").Find(&roles).Error
+	return roles, err
+}
+
 // UpdateStatus 更新角色状态
 func (r *roleRepository) UpdateStatus(id uint, status int) error {
 	return r.db.Model(&model.Role{}).Where("id = ?", id).Update("status", status).Error
 }
 
-// AssignPermissions 为角色分配权限
-func (r *roleRepository) AssignPermissions(roleID uint, permissionIDs []uint) error {
+// AssignPermissions 为角色分配权限，同时写入修订记录
+func (r *roleRepository) AssignPermissions(ctx context.Context, roleID uint, permissionIDs []uint) error {
 	return r.db.Transaction(func(tx *gorm.DB) error {
 		// 获取角色
 		var role model.Role
@@ -106,6 +130,11 @@ func (r *roleRepository) AssignPermissions(roleID uint, permissionIDs []uint) er
 			return err
 		}
 
+		before, err := roleSnapshot(tx, roleID)
+		if err != nil {
+			return err
+		}
+
 		// 获取权限列表
 		var permissions []model.Permission
 		if len(permissionIDs) > 0 {
@@ -119,10 +148,85 @@ func (r *roleRepository) AssignPermissions(roleID uint, permissionIDs []uint) er
 			return err
 		}
 
-		return nil
+		after, err := roleSnapshot(tx, roleID)
+		if err != nil {
+			return err
+		}
+		return recordRevision(ctx, tx, model.RevisionEntityRole, roleID, model.RevisionActionAssignPermissions, before, after)
+	})
+}
+
+// Restore 将角色字段和权限集合恢复为快照内容，同时写入回滚修订记录
+// 快照中已被删除的权限会被忽略
+func (r *roleRepository) Restore(ctx context.Context, roleID uint, snapshot *model.RoleSnapshot) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var role model.Role
+		if err := tx.First(&role, roleID).Error; err != nil {
+			return err
+		}
+
+		before, err := roleSnapshot(tx, roleID)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&role).Select("name", "display_name", "description", "level", "status", "require_mfa").Updates(map[string]interface{}{
+			"name":         snapshot.Name,
+			"display_name": snapshot.DisplayName,
+			"description":  snapshot.Description,
+			"level":        snapshot.Level,
+			"status":       snapshot.Status,
+			"require_mfa":  snapshot.RequireMFA,
+		}).Error; err != nil {
+			return err
+		}
+
+		var permissions []model.Permission
+		if len(snapshot.PermissionIDs) > 0 {
+			if err := tx.Find(&permissions, snapshot.PermissionIDs).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&role).Association("Permissions").Replace(&permissions); err != nil {
+			return err
+		}
+
+		after, err := roleSnapshot(tx, roleID)
+		if err != nil {
+			return err
+		}
+		return recordRevision(ctx, tx, model.RevisionEntityRole, roleID, model.RevisionActionRollback, before, after)
 	})
 }
 
+// roleSnapshot 读取角色及其全部权限（含已禁用的权限）的快照
+func roleSnapshot(tx *gorm.DB, roleID uint) (*model.RoleSnapshot, error) {
+	var role model.Role
+	if err := tx.Preload("Permissions").First(&role, roleID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("角色不存在")
+		}
+		return nil, err
+	}
+
+	sort.Slice(role.Permissions, func(i, j int) bool { return role.Permissions[i].ID < role.Permissions[j].ID })
+	snapshot := &model.RoleSnapshot{
+		Name:          role.Name,
+		DisplayName:   role.DisplayName,
+		Description:   role.Description,
+		Level:         role.Level,
+		Status:        role.Status,
+		RequireMFA:    role.RequireMFA,
+		PermissionIDs: make([]uint, 0, len(role.Permissions)),
+		Permissions:   make([]string, 0, len(role.Permissions)),
+	}
+	for _, permission := range role.Permissions {
+		snapshot.PermissionIDs = append(snapshot.PermissionIDs, permission.ID)
+		snapshot.Permissions = append(snapshot.Permissions, permission.Name)
+	}
+	return snapshot, nil
+}
+
 // GetRolePermissions 获取角色的权限列表
 func (r *roleRepository) GetRolePermissions(roleID uint) ([]*model.Permission, error) {
 	var permissions []*model.Permission
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"domain-admin/model"
+	"domain-admin/pkg/pagination"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// SyncRunRepository 同步任务仓储接口
+type SyncRunRepository interface {
+	Create(run *model.SyncRun) error
+	GetByID(id uint) (*model.SyncRun, error)
+	List(account string, page pagination.Pagination) ([]*model.SyncRun, int64, error)
+	Fail(run *model.SyncRun) error
+	Complete(run *model.SyncRun, snapshots []*model.ZoneSnapshot, changes []*model.SyncChange) error
+	LastSuccessful(account string) (*model.SyncRun, error)
+	ListSnapshots(runID uint) ([]*model.ZoneSnapshot, error)
+}
+
+type syncRunRepository struct {
+	db *gorm.DB
+}
+
+// NewSyncRunRepository 创建同步任务仓储实例
+func NewSyncRunRepository(db *gorm.DB) SyncRunRepository {
+	return &syncRunRepository{db: db}
+}
+
+// Create 创建同步任务
+func (r *syncRunRepository) Create(run *model.SyncRun) error {
+	return r.db.Create(run).Error
+}
+
+// GetByID 根据ID获取同步任务及其变更明细
+func (r *syncRunRepository) GetByID(id uint) (*model.SyncRun, error) {
+	var run model.SyncRun
+	err := r.db.Preload("Changes", func(db *gorm.DB) *gorm.DB {
+		return db.Order("zone_name asc, record_name asc, id asc")
+	}).Where("id = ?", id).First(&run).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("同步任务不存在")
+		}
+		return nil, err
+	}
+	return &run, nil
+}
+
+// List 获取同步任务列表，account 为空时返回全部账号
+func (r *syncRunRepository) List(account string, page pagination.Pagination) ([]*model.SyncRun, int64, error) {
+	var runs []*model.SyncRun
+	var total int64
+
+	query := r.db.Model(&model.SyncRun{})
+	if account != "" {
+		query = query.Where("provider_account = ?", account)
+	}
+
+	// 获取总数
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 分页查询
+	if err := query.Offset(page.Offset).Limit(page.Limit).Order(page.GetOrderClause()).Find(&runs).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return runs, total, nil
+}
+
+// Fail 保存失败的同步任务
+func (r *syncRunRepository) Fail(run *model.SyncRun) error {
+	return r.db.Omit("Changes").Save(run).Error
+}
+
+// Complete 在同一事务中保存快照、变更明细和任务结果
+func (r *syncRunRepository) Complete(run *model.SyncRun, snapshots []*model.ZoneSnapshot, changes []*model.SyncChange) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if len(snapshots) > 0 {
+			if err := tx.CreateInBatches(snapshots, 100).Error; err != nil {
+				return err
+			}
+		}
+		if len(changes) > 0 {
+			if err := tx.CreateInBatches(changes, 100).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Omit("Changes").Save(run).Error
+	})
+}
+
+// LastSuccessful 获取账号最近一次成功的同步任务
+func (r *syncRunRepository) LastSuccessful(account string) (*model.SyncRun, error) {
+	var run model.SyncRun
+	err := r.db.Where("provider_account = ? AND status = ?", account, model.SyncRunStatusSuccess).
+		Order("id desc").First(&run).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &run, nil
+}
+
+// ListSnapshots 获取同步任务的区域快照
+func (r *syncRunRepository) ListSnapshots(runID uint) ([]*model.ZoneSnapshot, error) {
+	var snapshots []*model.ZoneSnapshot
+	err := r.db.Where("run_id = ?", runID).Find(&snapshots).Error
+	return snapshots, err
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"domain-admin/model"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TwoFactorRepository 两步验证仓储接口
+type TwoFactorRepository interface {
+	GetByUserID(userID uint) (*model.UserTOTP, error)
+	Save(totp *model.UserTOTP) error
+	DeleteByUserID(userID uint) error
+	ListAll() ([]*model.UserTOTP, error)
+	UpdateSecret(totp *model.UserTOTP) error
+	CreateChallenge(challenge *model.LoginChallenge) error
+	GetChallengeByHash(hash string) (*model.LoginChallenge, error)
+	IncrementChallengeAttempts(id uint) error
+	UseChallenge(id uint, at time.Time) (bool, error)
+}
+
+type twoFactorRepository struct {
+	db *gorm.DB
+}
+
+// NewTwoFactorRepository 创建两步验证仓储实例
+func NewTwoFactorRepository(db *gorm.DB) TwoFactorRepository {
+	return &twoFactorRepository{db: db}
+}
+
+// GetByUserID 获取用户的TOTP配置
+func (r *twoFactorRepository) GetByUserID(userID uint) (*model.UserTOTP, error) {
+	var totp model.UserTOTP
+	err := r.db.Where("user_id = ?", userID).First(&totp).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("未绑定两步验证")
+		}
+		return nil, err
+	}
+	return &totp, nil
+}
+
+// Save 创建或更新用户的TOTP配置
+func (r *twoFactorRepository) Save(totp *model.UserTOTP) error {
+	return r.db.Save(totp).Error
+}
+
+// DeleteByUserID 删除用户的TOTP配置
+func (r *twoFactorRepository) DeleteByUserID(userID uint) error {
+	return r.db.Where("user_id = ?", userID).Delete(&model.UserTOTP{}).Error
+}
+
+// ListAll 获取全部用户的TOTP配置
+func (r *twoFactorRepository) ListAll() ([]*model.UserTOTP, error) {
+	var totps []*model.UserTOTP
+	err := r.db.Order("id asc").Find(&totps).Error
+	return totps, err
+}
+
+// UpdateSecret 只更新加密的TOTP密钥，不覆盖并发更新的验证状态
+func (r *twoFactorRepository) UpdateSecret(totp *model.UserTOTP) error {
+	return r.db.Model(totp).Select("secret_ciphertext", "secret_data_key", "secret_key_id").Updates(totp).Error
+}
+
+// CreateChallenge 创建登录挑战
+func (r *twoFactorRepository) CreateChallenge(challenge *model.LoginChallenge) error {
+	return r.db.Create(challenge).Error
+}
+
+// GetChallengeByHash 根据令牌哈希获取登录挑战
+func (r *twoFactorRepository) GetChallengeByHash(hash string) (*model.LoginChallenge, error) {
+	var challenge model.LoginChallenge
+	err := r.db.Where("token_hash = ?", hash).First(&challenge).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("登录挑战不存在")
+		}
+		return nil, err
+	}
+	return &challenge, nil
+}
+
+// IncrementChallengeAttempts 记录一次验证失败
+func (r *twoFactorRepository) IncrementChallengeAttempts(id uint) error {
+	return r.db.Model(&model.LoginChallenge{}).Where("id = ?", id).
+		UpdateColumn("attempts", gorm.Expr("attempts + 1")).Error
+}
+
+// UseChallenge 将登录挑战标记为已使用，挑战已被使用时返回 false
+func (r *twoFactorRepository) UseChallenge(id uint, at time.Time) (bool, error) {
+	result := r.db.Model(&model.LoginChallenge{}).Where("id = ? AND used_at IS NULL", id).Update("used_at", at)
+	return result.RowsAffected == 1, result.Error
+}
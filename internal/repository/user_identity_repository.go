@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"domain-admin/model"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// UserIdentityRepository 外部账号关联仓储接口
+type UserIdentityRepository interface {
+	GetIdentity(provider, subject string) (*model.UserIdentity, error)
+	GetByUser(provider string, userID uint) (*model.UserIdentity, error)
+	ListByProvider(provider string) ([]*model.UserIdentity, error)
+	SaveIdentity(identity *model.UserIdentity) error
+	DeleteIdentity(identity *model.UserIdentity) error
+}
+
+type userIdentityRepository struct {
+	db *gorm.DB
+}
+
+// NewUserIdentityRepository 创建外部账号关联仓储实例
+func NewUserIdentityRepository(db *gorm.DB) UserIdentityRepository {
+	return &userIdentityRepository{db: db}
+}
+
+// GetIdentity 获取外部账号关联
+func (r *userIdentityRepository) GetIdentity(provider, subject string) (*model.UserIdentity, error) {
+	var identity model.UserIdentity
+	err := r.db.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("外部账号未关联")
+		}
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// GetByUser 获取用户在指定身份提供方的关联
+func (r *userIdentityRepository) GetByUser(provider string, userID uint) (*model.UserIdentity, error) {
+	var identity model.UserIdentity
+	err := r.db.Where("provider = ? AND user_id = ?", provider, userID).First(&identity).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("外部账号未关联")
+		}
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// ListByProvider 获取身份提供方的全部账号关联
+func (r *userIdentityRepository) ListByProvider(provider string) ([]*model.UserIdentity, error) {
+	var identities []*model.UserIdentity
+	err := r.db.Where("provider = ?", provider).Order("id").Find(&identities).Error
+	return identities, err
+}
+
+// SaveIdentity 创建或更新外部账号关联
+func (r *userIdentityRepository) SaveIdentity(identity *model.UserIdentity) error {
+	return r.db.Save(identity).Error
+}
+
+// DeleteIdentity 删除外部账号关联
+func (r *userIdentityRepository) DeleteIdentity(identity *model.UserIdentity) error {
+	return r.db.Delete(identity).Error
+}
@@ -4,6 +4,7 @@ import (
 	"domain-admin/model"
 	"domain-admin/pkg/pagination"
 	"errors"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -17,8 +18,11 @@ type UserRepository interface {
 	Update(user *model.User) error
 	Delete(id uint) error
 	List(page pagination.Pagination) ([]*model.User, int64, error)
+	ListAll() ([]*model.User, error)
 	UpdateLastLogin(id uint) error
 	UpdateStatus(id uint, status int) error
+	UpdatePassword(id uint, hashedPassword string) error
+	MarkEmailVerified(id uint, at time.Time) error
 	Count() (int64, error)
 }
 
@@ -104,6 +108,13 @@ func (r *userRepository) List(page pagination.Pagination) ([]*model.User, int64,
 	return users, total, err
 }
 
+// ListAll 获取全部用户
+func (r *userRepository) ListAll() ([]*model.User, error) {
+	var users []*model.User
+	err := r.db.Order("id").Find(&users).Error
+	return users, err
+}
+
 // UpdateLastLogin 更新最后登录时间
 func (r *userRepository) UpdateLastLogin(id uint) error {
 	return r.db.Model(&model.User{}).Where("id = ?", id).
@@ -116,6 +127,16 @@ func (r *userRepository) UpdateStatus(id uint, status int) error {
 		Update("status", status).Error
 }
 
+// UpdatePassword 更新密码哈希
+func (r *userRepository) UpdatePassword(id uint, hashedPassword string) error {
+	return r.db.Model(&model.User{}).Where("id = ?", id).Update("password", hashedPassword).Error
+}
+
+// MarkEmailVerified 记录邮箱验证时间，已验证时保持原时间
+func (r *userRepository) MarkEmailVerified(id uint, at time.Time) error {
+	return r.db.Model(&model.User{}).Where("id = ? AND email_verified_at IS NULL", id).Update("email_verified_at", at).Error
+}
+
 // Count 获取用户总数
 func (r *userRepository) Count() (int64, error) {
 	var count int64
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"domain-admin/model"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserSessionRepository 登录会话仓储接口
+type UserSessionRepository interface {
+	Create(session *model.UserSession) error
+	GetByID(id uint) (*model.UserSession, error)
+	GetByJTI(jti string) (*model.UserSession, error)
+	ListActive(userID uint, now time.Time) ([]*model.UserSession, error)
+	Touch(id uint, at time.Time, ip string) error
+	Extend(id uint, expiresAt time.Time) error
+	Revoke(id uint, at time.Time) error
+}
+
+type userSessionRepository struct {
+	db *gorm.DB
+}
+
+// NewUserSessionRepository 创建登录会话仓储实例
+func NewUserSessionRepository(db *gorm.DB) UserSessionRepository {
+	return &userSessionRepository{db: db}
+}
+
+// Create 创建会话
+func (r *userSessionRepository) Create(session *model.UserSession) error {
+	return r.db.Create(session).Error
+}
+
+// GetByID 根据ID获取会话
+func (r *userSessionRepository) GetByID(id uint) (*model.UserSession, error) {
+	var session model.UserSession
+	err := r.db.Where("id = ?", id).First(&session).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("会话不存在")
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+// GetByJTI 根据访问令牌jti获取会话
+func (r *userSessionRepository) GetByJTI(jti string) (*model.UserSession, error) {
+	var session model.UserSession
+	err := r.db.Where("jti = ?", jti).First(&session).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("会话不存在")
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+// ListActive 获取用户未吊销且未过期的会话，按最近访问时间倒序
+func (r *userSessionRepository) ListActive(userID uint, now time.Time) ([]*model.UserSession, error) {
+	var sessions []*model.UserSession
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("last_seen_at desc").Find(&sessions).Error
+	return sessions, err
+}
+
+// Touch 记录会话最近访问时间和IP
+func (r *userSessionRepository) Touch(id uint, at time.Time, ip string) error {
+	return r.db.Model(&model.UserSession{}).Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{"last_seen_at": at, "client_ip": ip}).Error
+}
+
+// Extend 延长会话过期时间
+func (r *userSessionRepository) Extend(id uint, expiresAt time.Time) error {
+	return r.db.Model(&model.UserSession{}).Where("id = ?", id).UpdateColumn("expires_at", expiresAt).Error
+}
+
+// Revoke 吊销会话
+func (r *userSessionRepository) Revoke(id uint, at time.Time) error {
+	return r.db.Model(&model.UserSession{}).Where("id = ? AND revoked_at IS NULL", id).Update("revoked_at", at).Error
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"domain-admin/model"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrUserTokenUsed 一次性令牌已被使用或作废
+var ErrUserTokenUsed = errors.New("令牌已被使用")
+
+// UserTokenRepository 邮箱验证和重置密码令牌仓储接口
+type UserTokenRepository interface {
+	Create(token *model.UserToken) error
+	GetByHash(hash string) (*model.UserToken, error)
+	GetLatest(userID uint, purpose string) (*model.UserToken, error)
+	Use(token *model.UserToken, at time.Time) error
+	Invalidate(userID uint, purpose string, at time.Time) error
+	DeleteExpired(before time.Time) (int64, error)
+}
+
+type userTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewUserTokenRepository 创建一次性令牌仓储实例
+func NewUserTokenRepository(db *gorm.DB) UserTokenRepository {
+	return &userTokenRepository{db: db}
+}
+
+// Create 创建令牌
+func (r *userTokenRepository) Create(token *model.UserToken) error {
+	return r.db.Create(token).Error
+}
+
+// GetByHash 根据令牌哈希获取令牌
+func (r *userTokenRepository) GetByHash(hash string) (*model.UserToken, error) {
+	var token model.UserToken
+	err := r.db.Where("token_hash = ?", hash).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("令牌不存在")
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// GetLatest 获取用户最近签发的指定用途令牌
+func (r *userTokenRepository) GetLatest(userID uint, purpose string) (*model.UserToken, error) {
+	var token model.UserToken
+	err := r.db.Where("user_id = ? AND purpose = ?", userID, purpose).Order("id DESC").First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("令牌不存在")
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Use 将令牌标记为已使用，令牌已被使用或作废时返回 ErrUserTokenUsed
+func (r *userTokenRepository) Use(token *model.UserToken, at time.Time) error {
+	result := r.db.Model(&model.UserToken{}).
+		Where("id = ? AND used_at IS NULL", token.ID).
+		Update("used_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrUserTokenUsed
+	}
+	token.UsedAt = &at
+	return nil
+}
+
+// Invalidate 作废用户指定用途的全部未使用令牌
+func (r *userTokenRepository) Invalidate(userID uint, purpose string, at time.Time) error {
+	return r.db.Model(&model.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", at).Error
+}
+
+// DeleteExpired 删除在指定时间之前过期的令牌，返回删除数量
+func (r *userTokenRepository) DeleteExpired(before time.Time) (int64, error) {
+	result := r.db.Where("expires_at < ?", before).Delete(&model.UserToken{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"domain-admin/model"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WebAuthnRepository WebAuthn 凭据仓储接口
+type WebAuthnRepository interface {
+	CreateCredential(credential *model.WebAuthnCredential) error
+	GetCredentialByHash(hash string) (*model.WebAuthnCredential, error)
+	ListByUserID(userID uint) ([]*model.WebAuthnCredential, error)
+	CountByUserID(userID uint) (int64, error)
+	UpdateUsage(id uint, signCount uint32, backupState bool, at time.Time) error
+	DeleteCredential(userID, id uint) error
+	DeleteByUserID(userID uint) error
+	CreateChallenge(challenge *model.WebAuthnChallenge) error
+	UseChallenge(hash string, at time.Time) (*model.WebAuthnChallenge, error)
+}
+
+type webAuthnRepository struct {
+	db *gorm.DB
+}
+
+// NewWebAuthnRepository 创建 WebAuthn 凭据仓储实例
+func NewWebAuthnRepository(db *gorm.DB) WebAuthnRepository {
+	return &webAuthnRepository{db: db}
+}
+
+// CreateCredential 保存注册的凭据
+func (r *webAuthnRepository) CreateCredential(credential *model.WebAuthnCredential) error {
+	return r.db.Create(credential).Error
+}
+
+// GetCredentialByHash 根据凭据ID哈希获取凭据
+func (r *webAuthnRepository) GetCredentialByHash(hash string) (*model.WebAuthnCredential, error) {
+	var credential model.WebAuthnCredential
+	err := r.db.Where("credential_id_hash = ?", hash).First(&credential).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("凭据不存在")
+		}
+		return nil, err
+	}
+	return &credential, nil
+}
+
+// ListByUserID 获取用户的全部凭据
+func (r *webAuthnRepository) ListByUserID(userID uint) ([]*model.WebAuthnCredential, error) {
+	var credentials []*model.WebAuthnCredential
+	err := r.db.Where("user_id = ?", userID).Order("id").Find(&credentials).Error
+	return credentials, err
+}
+
+// CountByUserID 统计用户的凭据数量
+func (r *webAuthnRepository) CountByUserID(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.WebAuthnCredential{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// UpdateUsage 认证成功后更新签名计数、备份状态和最近使用时间
+func (r *webAuthnRepository) UpdateUsage(id uint, signCount uint32, backupState bool, at time.Time) error {
+	return r.db.Model(&model.WebAuthnCredential{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"sign_count":   signCount,
+		"backup_state": backupState,
+		"last_used_at": at,
+	}).Error
+}
+
+// DeleteCredential 删除用户的指定凭据
+func (r *webAuthnRepository) DeleteCredential(userID, id uint) error {
+	result := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&model.WebAuthnCredential{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("凭据不存在")
+	}
+	return nil
+}
+
+// DeleteByUserID 删除用户的全部凭据
+func (r *webAuthnRepository) DeleteByUserID(userID uint) error {
+	return r.db.Where("user_id = ?", userID).Delete(&model.WebAuthnCredential{}).Error
+}
+
+// CreateChallenge 创建认证会话
+func (r *webAuthnRepository) CreateChallenge(challenge *model.WebAuthnChallenge) error {
+	return r.db.Create(challenge).Error
+}
+
+// UseChallenge 根据挑战哈希获取认证会话并标记为已使用，会话不存在或已被使用时返回错误
+func (r *webAuthnRepository) UseChallenge(hash string, at time.Time) (*model.WebAuthnChallenge, error) {
+	var challenge model.WebAuthnChallenge
+	if err := r.db.Where("challenge_hash = ?", hash).First(&challenge).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("认证会话不存在")
+		}
+		return nil, err
+	}
+	result := r.db.Model(&model.WebAuthnChallenge{}).Where("id = ? AND used_at IS NULL", challenge.ID).Update("used_at", at)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("认证会话已使用")
+	}
+	challenge.UsedAt = &at
+	return &challenge, nil
+}
@@ -0,0 +1,303 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"domain-admin/internal/repository"
+	"domain-admin/model"
+	"domain-admin/pkg/cache"
+	"domain-admin/pkg/config"
+	"domain-admin/pkg/logger"
+	"domain-admin/pkg/mailer"
+	"domain-admin/pkg/utils"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// accountMailInterval 同一用户两次发送同类邮件的最短间隔，防止接口被用来轰炸邮箱
+const accountMailInterval = time.Minute
+
+// ErrEmailNotVerified 要求验证邮箱时，未验证邮箱的用户不能登录
+var ErrEmailNotVerified = errors.New("邮箱未验证，请先通过验证邮件完成验证")
+
+// errInvalidUserToken 令牌不存在、已使用、已过期或邮箱已变更，不区分具体原因
+var errInvalidUserToken = errors.New("链接无效或已过期")
+
+// AccountService 邮箱验证和找回密码服务接口
+type AccountService interface {
+	SendVerification(userID uint) error
+	ResendVerification(email string) error
+	VerifyEmail(token string) error
+	ForgotPassword(email string) error
+	ResetPassword(req *model.ResetPasswordRequest) error
+	CheckLogin(user *model.UserResponse) error
+}
+
+// accountService 邮箱验证和找回密码服务实现
+type accountService struct {
+	userRepo     repository.UserRepository
+	tokenRepo    repository.UserTokenRepository
+	identityRepo repository.UserIdentityRepository
+	tokenService AuthTokenService
+	sender       mailer.Sender
+	templates    *mailer.Templates
+	mailTimeout  time.Duration
+	cfg          config.AccountConfig
+}
+
+// NewAccountService 创建邮箱验证和找回密码服务实例
+func NewAccountService(userRepo repository.UserRepository, tokenRepo repository.UserTokenRepository, identityRepo repository.UserIdentityRepository, tokenService AuthTokenService, mailCfg config.MailConfig, cfg config.AccountConfig) AccountService {
+	return &accountService{
+		userRepo:     userRepo,
+		tokenRepo:    tokenRepo,
+		identityRepo: identityRepo,
+		tokenService: tokenService,
+		sender:       newMailSender(mailCfg),
+		templates:    mailTemplates(mailCfg),
+		mailTimeout:  mailCfg.SendTimeout(),
+		cfg:          cfg,
+	}
+}
+
+// newMailSender 按配置创建邮件发送方式，未配置或无法识别时只写入日志
+func newMailSender(cfg config.MailConfig) mailer.Sender {
+	switch cfg.Driver {
+	case "smtp":
+		return mailer.NewSMTPSender(mailer.SMTPConfig{
+			Host:       cfg.Host,
+			Port:       cfg.Port,
+			Username:   cfg.Username,
+			Password:   cfg.Password,
+			From:       cfg.Sender(),
+			Encryption: cfg.Encryption,
+			Timeout:    cfg.SendTimeout(),
+		})
+	case "file":
+		return mailer.NewFileSender(cfg.Directory(), cfg.Sender())
+	case "", "log":
+	default:
+		logger.Warnf("不支持的邮件发送方式 %s，邮件只写入日志", cfg.Driver)
+	}
+	return mailer.NewLogSender(logger.Infof)
+}
+
+// mailTemplates 返回邮件模板，自定义模板目录解析失败时使用内置模板
+func mailTemplates(cfg config.MailConfig) *mailer.Templates {
+	if cfg.TemplateDir == "" {
+		return mailer.DefaultTemplates()
+	}
+	templates, err := mailer.ParseTemplates(os.DirFS(cfg.TemplateDir))
+	if err != nil {
+		logger.Errorf("解析邮件模板目录 %s 失败，使用内置模板: %v", cfg.TemplateDir, err)
+		return mailer.DefaultTemplates()
+	}
+	return templates
+}
+
+// SendVerification 向注册的用户发送邮箱验证邮件，邮箱已验证时不发送
+func (s *accountService) SendVerification(userID uint) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+	return s.sendToken(user, model.UserTokenVerifyEmail, s.cfg.VerifyTTL(), "/verify-email", "verify_email")
+}
+
+// ResendVerification 重新发送邮箱验证邮件。邮箱未注册、已验证或发送过于频繁时静默忽略，不暴露邮箱是否注册
+func (s *accountService) ResendVerification(email string) error {
+	user, err := s.userRepo.GetByEmail(email)
+	if err != nil || user.Status == 0 || user.EmailVerifiedAt != nil {
+		return nil
+	}
+	if s.sentRecently(user.ID, model.UserTokenVerifyEmail) {
+		return nil
+	}
+	return s.sendToken(user, model.UserTokenVerifyEmail, s.cfg.VerifyTTL(), "/verify-email", "verify_email")
+}
+
+// VerifyEmail 使用验证邮件中的令牌完成邮箱验证
+func (s *accountService) VerifyEmail(secret string) error {
+	user, err := s.useToken(secret, model.UserTokenVerifyEmail)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.MarkEmailVerified(user.ID, time.Now()); err != nil {
+		logger.Errorf("记录邮箱验证时间失败: %v", err)
+		return errors.New("验证邮箱失败")
+	}
+	s.dropUserCache(user.ID)
+	logger.Infof("用户 %s 已验证邮箱", user.Username)
+	return nil
+}
+
+// ForgotPassword 发送重置密码邮件。邮箱未注册、用户已禁用、由目录服务管理密码或发送过于频繁时静默忽略，不暴露邮箱是否注册
+func (s *accountService) ForgotPassword(email string) error {
+	user, err := s.userRepo.GetByEmail(email)
+	if err != nil || user.Status == 0 {
+		return nil
+	}
+	if s.managedByDirectory(user.ID) {
+		logger.Infof("用户 %s 的密码由目录服务管理，不发送重置密码邮件", user.Username)
+		return nil
+	}
+	if s.sentRecently(user.ID, model.UserTokenResetPassword) {
+		return nil
+	}
+	return s.sendToken(user, model.UserTokenResetPassword, s.cfg.ResetTTL(), "/reset-password", "reset_password")
+}
+
+// ResetPassword 使用重置密码邮件中的令牌设置新密码，并吊销该用户的全部登录会话
+func (s *accountService) ResetPassword(req *model.ResetPasswordRequest) error {
+	user, err := s.useToken(req.Token, model.UserTokenResetPassword)
+	if err != nil {
+		return err
+	}
+	if user.Status == 0 {
+		return errors.New("用户已被禁用")
+	}
+	if s.managedByDirectory(user.ID) {
+		return errors.New("该用户的密码由目录服务管理，请联系管理员")
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		logger.Errorf("密码加密失败: %v", err)
+		return errors.New("密码加密失败")
+	}
+	if err := s.userRepo.UpdatePassword(user.ID, string(hashedPassword)); err != nil {
+		logger.Errorf("更新密码失败: %v", err)
+		return errors.New("重置密码失败")
+	}
+
+	// 能收到重置邮件说明邮箱属于该用户
+	now := time.Now()
+	if err := s.userRepo.MarkEmailVerified(user.ID, now); err != nil {
+		logger.Warnf("记录邮箱验证时间失败: %v", err)
+	}
+	if err := s.tokenRepo.Invalidate(user.ID, model.UserTokenResetPassword, now); err != nil {
+		logger.Warnf("作废重置密码令牌失败: %v", err)
+	}
+	if _, err := s.tokenService.RevokeUser(user.ID); err != nil {
+		logger.Warnf("吊销用户 %d 的会话失败: %v", user.ID, err)
+	}
+	s.dropUserCache(user.ID)
+	logger.Infof("用户 %s 已通过邮件重置密码", user.Username)
+	return nil
+}
+
+// CheckLogin 要求验证邮箱时拒绝未验证邮箱的用户登录
+func (s *accountService) CheckLogin(user *model.UserResponse) error {
+	if s.cfg.RequireEmailVerification && user.EmailVerifiedAt == nil {
+		return ErrEmailNotVerified
+	}
+	return nil
+}
+
+// sendToken 作废同一用途的旧令牌，签发新令牌并发送包含链接的邮件
+func (s *accountService) sendToken(user *model.User, purpose string, ttl time.Duration, path, template string) error {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return errors.New("生成令牌失败")
+	}
+	secret := base64.RawURLEncoding.EncodeToString(buf)
+
+	now := time.Now()
+	if err := s.tokenRepo.Invalidate(user.ID, purpose, now); err != nil {
+		logger.Errorf("作废旧令牌失败: %v", err)
+		return errors.New("生成令牌失败")
+	}
+	token := &model.UserToken{
+		UserID:    user.ID,
+		Purpose:   purpose,
+		TokenHash: utils.SHA256(secret),
+		Email:     user.Email,
+		ExpiresAt: now.Add(ttl),
+	}
+	if err := s.tokenRepo.Create(token); err != nil {
+		logger.Errorf("保存令牌失败: %v", err)
+		return errors.New("生成令牌失败")
+	}
+
+	name := user.Nickname
+	if name == "" {
+		name = user.Username
+	}
+	msg, err := s.templates.Render(template, map[string]string{
+		"AppName":   s.cfg.Name(),
+		"Username":  name,
+		"Link":      strings.TrimRight(s.cfg.BaseURL, "/") + path + "?token=" + url.QueryEscape(secret),
+		"ExpiresIn": formatTTL(ttl),
+	}, user.Email)
+	if err != nil {
+		logger.Errorf("渲染邮件失败: %v", err)
+		return errors.New("发送邮件失败")
+	}
+
+	// 异步发送，接口的响应时间不因邮箱是否注册而不同
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), s.mailTimeout)
+		defer cancel()
+		if err := s.sender.Send(ctx, msg); err != nil {
+			logger.Errorf("向用户 %s 发送 %s 邮件失败: %v", user.Username, template, err)
+			return
+		}
+		logger.Infof("已向用户 %s 发送 %s 邮件", user.Username, template)
+	}()
+	return nil
+}
+
+// sentRecently 是否在最短发送间隔内已向该用户发送过同类邮件
+func (s *accountService) sentRecently(userID uint, purpose string) bool {
+	latest, err := s.tokenRepo.GetLatest(userID, purpose)
+	return err == nil && time.Since(latest.CreatedAt) < accountMailInterval
+}
+
+// useToken 校验并使用一次性令牌，返回令牌所属用户
+func (s *accountService) useToken(secret, purpose string) (*model.User, error) {
+	token, err := s.tokenRepo.GetByHash(utils.SHA256(secret))
+	if err != nil || token.Purpose != purpose || token.UsedAt != nil || time.Now().After(token.ExpiresAt) {
+		return nil, errInvalidUserToken
+	}
+	user, err := s.userRepo.GetByID(token.UserID)
+	if err != nil || !strings.EqualFold(user.Email, token.Email) {
+		return nil, errInvalidUserToken
+	}
+	if err := s.tokenRepo.Use(token, time.Now()); err != nil {
+		if errors.Is(err, repository.ErrUserTokenUsed) {
+			return nil, errInvalidUserToken
+		}
+		logger.Errorf("使用令牌失败: %v", err)
+		return nil, errors.New("验证令牌失败")
+	}
+	return user, nil
+}
+
+// managedByDirectory 用户是否由LDAP目录服务管理密码
+func (s *accountService) managedByDirectory(userID uint) bool {
+	_, err := s.identityRepo.GetByUser(ldapProvider, userID)
+	return err == nil
+}
+
+func (s *accountService) dropUserCache(userID uint) {
+	if err := cache.DelUserCache(context.Background(), userID); err != nil {
+		logger.Warnf("清除用户缓存失败: %v", err)
+	}
+}
+
+// formatTTL 返回邮件中显示的有效期，如 24小时、30分钟
+func formatTTL(d time.Duration) string {
+	if d >= time.Hour && d%time.Hour == 0 {
+		return fmt.Sprintf("%d小时", int(d/time.Hour))
+	}
+	return fmt.Sprintf("%d分钟", int(d.Round(time.Minute)/time.Minute))
+}
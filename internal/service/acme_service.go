@@ -0,0 +1,560 @@
+package service
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"domain-admin/internal/repository"
+	"domain-admin/model"
+	"domain-admin/pkg/acme"
+	"domain-admin/pkg/config"
+	"domain-admin/pkg/logger"
+	"domain-admin/pkg/pagination"
+	"domain-admin/pkg/provider"
+	"domain-admin/pkg/vault"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AcmeService ACME证书签发服务接口
+type AcmeService interface {
+	Create(req *model.AcmeCertificateCreateRequest) (*model.AcmeCertificate, error)
+	GetByID(id uint) (*model.AcmeCertificate, error)
+	Update(id uint, req *model.AcmeCertificateUpdateRequest) (*model.AcmeCertificate, error)
+	Delete(id uint) error
+	List(status string, page pagination.Pagination) ([]*model.AcmeCertificate, int64, error)
+	Renew(id uint) (*model.AcmeCertificate, error)
+	Download(id uint) (*model.AcmeCertificateBundle, error)
+	Issue(ctx context.Context, id uint) (*model.AcmeCertificate, error)
+	RenewDue(ctx context.Context) (int, error)
+	RotateVaultKey(ctx context.Context) (*model.VaultRotateItem, error)
+}
+
+type acmeService struct {
+	acmeRepo   repository.AcmeRepository
+	cfg        config.ACMEConfig
+	keyring    *vault.Keyring
+	httpClient *http.Client
+	initErr    error
+}
+
+// issuingCertificates 正在签发的证书，防止同一证书并发签发
+var (
+	issuingCertificates = make(map[uint]bool)
+	issuingMux          sync.Mutex
+	// acmeAccountMux 串行化ACME账号的注册
+	acmeAccountMux sync.Mutex
+)
+
+// NewAcmeService 创建ACME证书签发服务实例，账号私钥和证书私钥使用 vault 主密钥加密
+// 主密钥或CA证书配置错误时服务仍可查询，签发和下载时返回错误
+func NewAcmeService(acmeRepo repository.AcmeRepository, cfg config.ACMEConfig, vaultCfg config.VaultConfig) AcmeService {
+	s := &acmeService{acmeRepo: acmeRepo, cfg: cfg}
+
+	keyring, err := vault.Load(vaultCfg.KeyEnv, vaultCfg.KeyFile)
+	if err != nil {
+		if errors.Is(err, vault.ErrNoKey) {
+			s.initErr = errors.New("未配置证书私钥的加密主密钥")
+		} else {
+			s.initErr = fmt.Errorf("加密主密钥无效: %w", err)
+		}
+		return s
+	}
+	s.keyring = keyring
+
+	if cfg.CACertFile != "" {
+		pem, err := os.ReadFile(cfg.CACertFile)
+		if err != nil {
+			s.initErr = fmt.Errorf("读取ACME服务端CA证书失败: %w", err)
+			return s
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			s.initErr = errors.New("ACME服务端CA证书格式错误")
+			return s
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+		s.httpClient = &http.Client{Transport: transport}
+	}
+	return s
+}
+
+// Create 申请证书，记录创建后在后台签发
+func (s *acmeService) Create(req *model.AcmeCertificateCreateRequest) (*model.AcmeCertificate, error) {
+	if s.initErr != nil {
+		return nil, s.initErr
+	}
+
+	domains, err := normalizeCertificateDomains(req.Domains)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkProviderAccount(req.ProviderAccount); err != nil {
+		return nil, err
+	}
+
+	autoRenew := true
+	if req.AutoRenew != nil {
+		autoRenew = *req.AutoRenew
+	}
+
+	cert := &model.AcmeCertificate{
+		CommonName:      domains[0],
+		Domains:         domains,
+		ProviderAccount: req.ProviderAccount,
+		AutoRenew:       autoRenew,
+		Status:          model.AcmeCertificateStatusPending,
+	}
+	if err := s.acmeRepo.CreateCertificate(cert); err != nil {
+		logger.Errorf("创建证书失败: %v", err)
+		return nil, errors.New("创建证书失败")
+	}
+
+	logger.Infof("申请证书: %s", strings.Join(domains, ","))
+	markIssuing(cert.ID)
+	go s.issueInBackground(cert.ID)
+	return cert, nil
+}
+
+// GetByID 根据ID获取证书
+func (s *acmeService) GetByID(id uint) (*model.AcmeCertificate, error) {
+	if id == 0 {
+		return nil, errors.New("证书ID不能为空")
+	}
+	return s.acmeRepo.GetCertificate(id)
+}
+
+// Update 更新证书的续期设置
+func (s *acmeService) Update(id uint, req *model.AcmeCertificateUpdateRequest) (*model.AcmeCertificate, error) {
+	if id == 0 {
+		return nil, errors.New("证书ID不能为空")
+	}
+
+	cert, err := s.acmeRepo.GetCertificate(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.ProviderAccount != nil {
+		if err := s.checkProviderAccount(*req.ProviderAccount); err != nil {
+			return nil, err
+		}
+		cert.ProviderAccount = *req.ProviderAccount
+	}
+	if req.AutoRenew != nil {
+		cert.AutoRenew = *req.AutoRenew
+	}
+
+	if err := s.acmeRepo.UpdateCertificate(cert); err != nil {
+		logger.Errorf("更新证书失败: %v", err)
+		return nil, errors.New("更新证书失败")
+	}
+	return cert, nil
+}
+
+// Delete 删除证书，正在签发的证书不能删除
+func (s *acmeService) Delete(id uint) error {
+	if id == 0 {
+		return errors.New("证书ID不能为空")
+	}
+
+	cert, err := s.acmeRepo.GetCertificate(id)
+	if err != nil {
+		return err
+	}
+	if isIssuing(id) {
+		return errors.New("证书正在签发中")
+	}
+
+	if err := s.acmeRepo.DeleteCertificate(id); err != nil {
+		logger.Errorf("删除证书失败: %v", err)
+		return errors.New("删除证书失败")
+	}
+
+	logger.Infof("删除证书成功: %s", cert.CommonName)
+	return nil
+}
+
+// List 获取证书列表
+func (s *acmeService) List(status string, page pagination.Pagination) ([]*model.AcmeCertificate, int64, error) {
+	return s.acmeRepo.ListCertificates(status, page)
+}
+
+// Renew 在后台重新签发证书
+func (s *acmeService) Renew(id uint) (*model.AcmeCertificate, error) {
+	if id == 0 {
+		return nil, errors.New("证书ID不能为空")
+	}
+	if s.initErr != nil {
+		return nil, s.initErr
+	}
+
+	cert, err := s.acmeRepo.GetCertificate(id)
+	if err != nil {
+		return nil, err
+	}
+	if !markIssuing(id) {
+		return nil, errors.New("证书正在签发中")
+	}
+
+	go s.issueInBackground(id)
+	return cert, nil
+}
+
+// Download 获取解密后的证书和私钥
+func (s *acmeService) Download(id uint) (*model.AcmeCertificateBundle, error) {
+	if id == 0 {
+		return nil, errors.New("证书ID不能为空")
+	}
+	if s.initErr != nil {
+		return nil, s.initErr
+	}
+
+	cert, err := s.acmeRepo.GetCertificate(id)
+	if err != nil {
+		return nil, err
+	}
+	if cert.Certificate == "" {
+		return nil, errors.New("证书尚未签发")
+	}
+
+	keyPEM, err := s.keyring.Open(&vault.Sealed{
+		KeyID:      cert.PrivateKeyID,
+		DataKey:    cert.PrivateDataKey,
+		Ciphertext: cert.PrivateCiphertext,
+	})
+	if err != nil {
+		logger.Errorf("解密证书 %d 私钥失败: %v", id, err)
+		if errors.Is(err, vault.ErrUnknownKey) {
+			return nil, fmt.Errorf("证书私钥使用的主密钥 %s 不在密钥列表中", cert.PrivateKeyID)
+		}
+		return nil, errors.New("解密证书私钥失败")
+	}
+
+	return &model.AcmeCertificateBundle{
+		ID:          cert.ID,
+		CommonName:  cert.CommonName,
+		Domains:     cert.Domains,
+		NotAfter:    cert.NotAfter,
+		Certificate: cert.Certificate,
+		PrivateKey:  string(keyPEM),
+	}, nil
+}
+
+// RenewDue 依次续期即将到期的证书，返回成功续期的数量
+func (s *acmeService) RenewDue(ctx context.Context) (int, error) {
+	certs, err := s.acmeRepo.ListRenewable(time.Now().Add(s.cfg.RenewWindow()))
+	if err != nil {
+		return 0, err
+	}
+	if len(certs) == 0 {
+		return 0, nil
+	}
+	if s.initErr != nil {
+		return 0, s.initErr
+	}
+
+	renewed, failed := 0, 0
+	for _, cert := range certs {
+		if ctx.Err() != nil {
+			return renewed, ctx.Err()
+		}
+		if _, err := s.Issue(ctx, cert.ID); err != nil {
+			failed++
+			continue
+		}
+		renewed++
+	}
+	if failed > 0 {
+		return renewed, fmt.Errorf("%d 个证书续期失败", failed)
+	}
+	return renewed, nil
+}
+
+// Issue 签发证书并加密保存，失败时保留已有的证书
+func (s *acmeService) Issue(ctx context.Context, id uint) (*model.AcmeCertificate, error) {
+	if s.initErr != nil {
+		return nil, s.initErr
+	}
+
+	if !markIssuing(id) {
+		return nil, errors.New("证书正在签发中")
+	}
+	defer unmarkIssuing(id)
+	return s.issue(ctx, id)
+}
+
+// issue 签发证书，调用方需已标记证书为签发中
+func (s *acmeService) issue(ctx context.Context, id uint) (*model.AcmeCertificate, error) {
+	cert, err := s.acmeRepo.GetCertificate(id)
+	if err != nil {
+		return nil, err
+	}
+	cert.Status = model.AcmeCertificateStatusIssuing
+	if err := s.acmeRepo.UpdateCertificate(cert); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.IssueTimeout())
+	defer cancel()
+
+	if err := s.obtain(ctx, cert); err != nil {
+		logger.Errorf("签发证书 %s 失败: %v", cert.CommonName, err)
+		cert.Status = model.AcmeCertificateStatusFailed
+		cert.LastError = err.Error()
+		if err := s.acmeRepo.UpdateCertificate(cert); err != nil {
+			logger.Errorf("保存证书 %s 签发结果失败: %v", cert.CommonName, err)
+		}
+		return cert, err
+	}
+
+	if err := s.acmeRepo.UpdateCertificate(cert); err != nil {
+		return nil, err
+	}
+	logger.Infof("签发证书成功: %s，到期时间 %s", cert.CommonName, cert.NotAfter.Format("2006-01-02"))
+	return cert, nil
+}
+
+// obtain 通过ACME签发证书并写入证书和加密后的私钥
+func (s *acmeService) obtain(ctx context.Context, cert *model.AcmeCertificate) error {
+	solver, err := s.solver(cert)
+	if err != nil {
+		return err
+	}
+	client, err := s.client(ctx, solver)
+	if err != nil {
+		return err
+	}
+
+	issued, err := client.Obtain(ctx, cert.Domains)
+	if err != nil {
+		return err
+	}
+
+	sealedKey, err := s.keyring.Seal(issued.PrivateKeyPEM)
+	if err != nil {
+		return fmt.Errorf("加密私钥失败: %w", err)
+	}
+
+	now := time.Now()
+	notBefore, notAfter := issued.Leaf.NotBefore, issued.Leaf.NotAfter
+	cert.Certificate = string(issued.CertificatePEM)
+	cert.PrivateCiphertext = sealedKey.Ciphertext
+	cert.PrivateDataKey = sealedKey.DataKey
+	cert.PrivateKeyID = sealedKey.KeyID
+	cert.Issuer = issued.Leaf.Issuer.String()
+	cert.SerialNumber = issued.Leaf.SerialNumber.Text(16)
+	cert.NotBefore = &notBefore
+	cert.NotAfter = &notAfter
+	cert.IssuedAt = &now
+	cert.Status = model.AcmeCertificateStatusIssued
+	cert.LastError = ""
+	return nil
+}
+
+// solver 返回证书使用的DNS-01求解器
+func (s *acmeService) solver(cert *model.AcmeCertificate) (acme.Solver, error) {
+	if s.cfg.ChallTestSrvURL != "" {
+		return &acme.ChallTestSrvSolver{BaseURL: s.cfg.ChallTestSrvURL}, nil
+	}
+	dns, err := provider.GetProvider(cert.ProviderAccount)
+	if err != nil {
+		return nil, fmt.Errorf("云服务商账号不存在: %s", cert.ProviderAccount)
+	}
+	return acme.NewProviderSolver(dns, s.cfg.Propagation()), nil
+}
+
+// client 创建ACME客户端，首次使用时注册账号并加密保存账号私钥
+func (s *acmeService) client(ctx context.Context, solver acme.Solver) (*acme.Client, error) {
+	directory := s.cfg.DirectoryURL
+	if directory == "" {
+		directory = acme.LetsEncryptURL
+	}
+
+	acmeAccountMux.Lock()
+	defer acmeAccountMux.Unlock()
+
+	account, err := s.acmeRepo.GetAccount(directory, s.cfg.Email)
+	if err == nil {
+		keyPEM, err := s.keyring.Open(&vault.Sealed{
+			KeyID:      account.PrivateKeyID,
+			DataKey:    account.PrivateDataKey,
+			Ciphertext: account.PrivateCiphertext,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("解密ACME账号私钥失败: %w", err)
+		}
+		key, err := acme.ParsePrivateKey(keyPEM)
+		if err != nil {
+			return nil, err
+		}
+		return acme.New(acme.Config{
+			DirectoryURL: directory,
+			Email:        s.cfg.Email,
+			AccountKey:   key,
+			AccountURL:   account.URL,
+			HTTPClient:   s.httpClient,
+			Solver:       solver,
+		})
+	}
+
+	client, err := acme.New(acme.Config{
+		DirectoryURL: directory,
+		Email:        s.cfg.Email,
+		HTTPClient:   s.httpClient,
+		Solver:       solver,
+	})
+	if err != nil {
+		return nil, err
+	}
+	url, err := client.Register(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	keyPEM, err := acme.MarshalPrivateKey(client.AccountKey())
+	if err != nil {
+		return nil, err
+	}
+	sealedKey, err := s.keyring.Seal(keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("加密ACME账号私钥失败: %w", err)
+	}
+	if err := s.acmeRepo.SaveAccount(&model.AcmeAccount{
+		DirectoryURL:      directory,
+		Email:             s.cfg.Email,
+		URL:               url,
+		PrivateCiphertext: sealedKey.Ciphertext,
+		PrivateDataKey:    sealedKey.DataKey,
+		PrivateKeyID:      sealedKey.KeyID,
+	}); err != nil {
+		return nil, fmt.Errorf("保存ACME账号失败: %w", err)
+	}
+
+	logger.Infof("注册ACME账号成功: %s", url)
+	return client, nil
+}
+
+func (s *acmeService) issueInBackground(id uint) {
+	defer unmarkIssuing(id)
+	if _, err := s.issue(context.Background(), id); err != nil {
+		logger.Warnf("后台签发证书 %d 失败: %v", id, err)
+	}
+}
+
+// checkProviderAccount 检查完成DNS验证的云服务商账号，使用challtestsrv时不需要
+func (s *acmeService) checkProviderAccount(account string) error {
+	if s.cfg.ChallTestSrvURL != "" {
+		return nil
+	}
+	if account == "" {
+		return errors.New("云服务商账号不能为空")
+	}
+	if _, err := provider.GetProvider(account); err != nil {
+		return fmt.Errorf("云服务商账号不存在: %s", account)
+	}
+	return nil
+}
+
+func isIssuing(id uint) bool {
+	issuingMux.Lock()
+	defer issuingMux.Unlock()
+	return issuingCertificates[id]
+}
+
+// RotateVaultKey 使用当前主密钥重新加密由其他主密钥加密的账号私钥和证书私钥
+// 正在签发的证书计为失败，签发结束后重新轮换即可
+func (s *acmeService) RotateVaultKey(ctx context.Context) (*model.VaultRotateItem, error) {
+	item := &model.VaultRotateItem{Name: "acme_keys"}
+	accounts, err := s.acmeRepo.ListAccounts()
+	if err != nil {
+		return item, err
+	}
+	certs, err := s.acmeRepo.ListAllCertificates()
+	if err != nil {
+		return item, err
+	}
+	if len(accounts)+len(certs) == 0 {
+		return item, nil
+	}
+	if s.keyring == nil {
+		return item, s.initErr
+	}
+
+	acmeAccountMux.Lock()
+	for _, account := range accounts {
+		sealed := &vault.Sealed{KeyID: account.PrivateKeyID, DataKey: account.PrivateDataKey, Ciphertext: account.PrivateCiphertext}
+		rotateSealed(s.keyring, item, "ACME账号 "+account.Email, sealed, func(rotated *vault.Sealed) error {
+			account.PrivateCiphertext = rotated.Ciphertext
+			account.PrivateDataKey = rotated.DataKey
+			account.PrivateKeyID = rotated.KeyID
+			return s.acmeRepo.UpdateAccountKey(account)
+		})
+	}
+	acmeAccountMux.Unlock()
+
+	for _, listed := range certs {
+		if err := ctx.Err(); err != nil {
+			return item, err
+		}
+		if listed.PrivateCiphertext == "" || !s.keyring.NeedsRotation(&vault.Sealed{KeyID: listed.PrivateKeyID}) {
+			continue
+		}
+		if !markIssuing(listed.ID) {
+			logger.Warnf("证书 %d 正在签发，跳过私钥轮换", listed.ID)
+			item.Failed++
+			continue
+		}
+		// 标记后重新读取，避免覆盖刚签发完成的私钥
+		cert, err := s.acmeRepo.GetCertificate(listed.ID)
+		if err == nil {
+			sealed := &vault.Sealed{KeyID: cert.PrivateKeyID, DataKey: cert.PrivateDataKey, Ciphertext: cert.PrivateCiphertext}
+			rotateSealed(s.keyring, item, fmt.Sprintf("证书 %d", cert.ID), sealed, func(rotated *vault.Sealed) error {
+				cert.PrivateCiphertext = rotated.Ciphertext
+				cert.PrivateDataKey = rotated.DataKey
+				cert.PrivateKeyID = rotated.KeyID
+				return s.acmeRepo.UpdateCertificateKey(cert)
+			})
+		}
+		unmarkIssuing(listed.ID)
+	}
+	return item, nil
+}
+
+// markIssuing 标记证书为签发中，已在签发时返回 false
+func markIssuing(id uint) bool {
+	issuingMux.Lock()
+	defer issuingMux.Unlock()
+	if issuingCertificates[id] {
+		return false
+	}
+	issuingCertificates[id] = true
+	return true
+}
+
+func unmarkIssuing(id uint) {
+	issuingMux.Lock()
+	defer issuingMux.Unlock()
+	delete(issuingCertificates, id)
+}
+
+// normalizeCertificateDomains 规范化证书域名，允许 "*." 开头的通配符域名
+func normalizeCertificateDomains(domains []string) ([]string, error) {
+	domains = acme.NormalizeDomains(domains)
+	if len(domains) == 0 {
+		return nil, errors.New("域名不能为空")
+	}
+	for _, domain := range domains {
+		name := strings.TrimPrefix(domain, "*.")
+		if len(name) > 253 || !strings.Contains(name, ".") || !hostnamePattern.MatchString(name) {
+			return nil, fmt.Errorf("域名格式错误: %s", domain)
+		}
+	}
+	return domains, nil
+}
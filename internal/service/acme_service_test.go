@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"crypto/tls"
+	"domain-admin/internal/repository"
+	"domain-admin/model"
+	"domain-admin/pkg/acme/acmetest"
+	"domain-admin/pkg/config"
+	"domain-admin/pkg/provider"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// newTestDNSProvider 注册只保存在内存中的本地服务商账号，zones 为空时没有任何区域
+func newTestDNSProvider(t *testing.T, name, zones string) provider.DNSProvider {
+	t.Helper()
+	dns, err := provider.NewLocalProvider(config.CloudProviderConfig{Name: name, Type: provider.LocalType, Options: map[string]string{"zones": zones}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider.SetProvider(name, dns)
+	t.Cleanup(func() { provider.RemoveProvider(name) })
+	return dns
+}
+
+// txtLookup 从服务商账号中读取 fqdn 上的 TXT 记录，供测试CA完成 dns-01 验证
+func txtLookup(dns provider.DNSProvider, zone string) acmetest.LookupFunc {
+	return func(fqdn string) []string {
+		records, err := dns.ListRecords(context.Background(), zone)
+		if err != nil {
+			return nil
+		}
+		var values []string
+		for _, r := range records {
+			if r.Type == "TXT" && r.Name == strings.TrimSuffix(fqdn, "."+zone) {
+				values = append(values, r.Value)
+			}
+		}
+		return values
+	}
+}
+
+func newTestAcmeService(t *testing.T) (AcmeService, *gorm.DB) {
+	t.Helper()
+	dns := newTestDNSProvider(t, "acme-dns", "example.com")
+	ca := acmetest.NewServer(t, txtLookup(dns, "example.com"))
+
+	vaultCfg := config.VaultConfig{KeyEnv: "ACME_TEST_VAULT_KEY"}
+	t.Setenv(vaultCfg.KeyEnv, newTestVaultKey(t))
+	db := newTestDB(t, &model.AcmeAccount{}, &model.AcmeCertificate{})
+	svc := NewAcmeService(repository.NewAcmeRepository(db), config.ACMEConfig{
+		DirectoryURL:     ca.DirectoryURL(),
+		Email:            "admin@example.com",
+		PropagationDelay: "0s",
+		Timeout:          "30s",
+	}, vaultCfg)
+	return svc, db
+}
+
+func newTestAcmeCertificate(t *testing.T, db *gorm.DB, account string, domains ...string) *model.AcmeCertificate {
+	t.Helper()
+	cert := &model.AcmeCertificate{
+		CommonName:      domains[0],
+		Domains:         domains,
+		ProviderAccount: account,
+		AutoRenew:       true,
+		Status:          model.AcmeCertificateStatusPending,
+	}
+	if err := db.Create(cert).Error; err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestAcmeIssueAndRenewDue(t *testing.T) {
+	svc, db := newTestAcmeService(t)
+	cert := newTestAcmeCertificate(t, db, "acme-dns", "example.com", "*.example.com")
+	ctx := context.Background()
+
+	issued, err := svc.Issue(ctx, cert.ID)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	if issued.Status != model.AcmeCertificateStatusIssued || issued.SerialNumber == "" || issued.NotAfter == nil {
+		t.Fatalf("issued certificate = %+v", issued)
+	}
+
+	// 私钥只以密文保存，下载时解密
+	var stored model.AcmeCertificate
+	db.First(&stored, cert.ID)
+	if stored.PrivateCiphertext == "" || strings.Contains(stored.PrivateCiphertext, "PRIVATE KEY") {
+		t.Fatalf("private key stored as %q", stored.PrivateCiphertext)
+	}
+	bundle, err := svc.Download(cert.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tls.X509KeyPair([]byte(bundle.Certificate), []byte(bundle.PrivateKey)); err != nil {
+		t.Fatalf("certificate and key do not match: %v", err)
+	}
+
+	// 验证记录签发后清理
+	dns, _ := provider.GetProvider("acme-dns")
+	if left := txtLookup(dns, "example.com")("_acme-challenge.example.com"); len(left) != 0 {
+		t.Fatalf("challenge records not cleaned up: %v", left)
+	}
+
+	// 未进入续期窗口时不续期
+	if renewed, err := svc.RenewDue(ctx); err != nil || renewed != 0 {
+		t.Fatalf("RenewDue = %d, %v", renewed, err)
+	}
+
+	soon := time.Now().Add(24 * time.Hour)
+	db.Model(&model.AcmeCertificate{}).Where("id = ?", cert.ID).Update("not_after", soon)
+	if renewed, err := svc.RenewDue(ctx); err != nil || renewed != 1 {
+		t.Fatalf("RenewDue = %d, %v", renewed, err)
+	}
+	var renewed model.AcmeCertificate
+	db.First(&renewed, cert.ID)
+	if renewed.SerialNumber == issued.SerialNumber || !renewed.NotAfter.After(soon) {
+		t.Fatalf("renewed certificate = %+v", renewed)
+	}
+
+	// 续期复用已注册的ACME账号
+	var accounts []model.AcmeAccount
+	db.Find(&accounts)
+	if len(accounts) != 1 || accounts[0].PrivateCiphertext == "" || accounts[0].URL == "" {
+		t.Fatalf("accounts = %+v", accounts)
+	}
+}
+
+func TestAcmeIssueFailureKeepsCertificate(t *testing.T) {
+	svc, db := newTestAcmeService(t)
+	newTestDNSProvider(t, "acme-empty", "")
+	cert := newTestAcmeCertificate(t, db, "acme-dns", "www.example.com")
+	ctx := context.Background()
+
+	issued, err := svc.Issue(ctx, cert.ID)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+
+	// 服务商账号中没有对应区域时签发失败，已有证书仍可下载
+	db.Model(&model.AcmeCertificate{}).Where("id = ?", cert.ID).Update("provider_account", "acme-empty")
+	failed, err := svc.Issue(ctx, cert.ID)
+	if err == nil {
+		t.Fatal("Issue succeeded without a zone for the challenge record")
+	}
+	if failed.Status != model.AcmeCertificateStatusFailed || failed.LastError == "" {
+		t.Fatalf("failed certificate = %+v", failed)
+	}
+	bundle, err := svc.Download(cert.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bundle.Certificate != issued.Certificate {
+		t.Fatal("certificate was replaced by a failed issue")
+	}
+
+	db.Model(&model.AcmeCertificate{}).Where("id = ?", cert.ID).Update("provider_account", "missing")
+	if _, err := svc.Issue(ctx, cert.ID); err == nil || !strings.Contains(err.Error(), "云服务商账号不存在") {
+		t.Fatalf("Issue with unknown provider account: %v", err)
+	}
+}
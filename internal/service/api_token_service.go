@@ -0,0 +1,249 @@
+package service
+
+import (
+	"crypto/rand"
+	"domain-admin/internal/repository"
+	"domain-admin/model"
+	"domain-admin/pkg/logger"
+	"domain-admin/pkg/middleware"
+	"domain-admin/pkg/utils"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/casbin/casbin/v2/util"
+)
+
+const (
+	// apiTokenDefaultDays 未指定有效期时的默认有效天数
+	apiTokenDefaultDays = 30
+	// apiTokenMaxActive 每个用户最多持有的有效令牌数量
+	apiTokenMaxActive = 50
+	// apiTokenTouchInterval 记录最近使用时间的最小间隔，IP 变化时立即记录
+	apiTokenTouchInterval = time.Minute
+)
+
+// APITokenService API令牌服务接口
+type APITokenService interface {
+	Create(userID uint, req *model.APITokenCreateRequest) (*model.APITokenCreateResult, error)
+	List(userID uint) ([]*model.APIToken, error)
+	Revoke(userID, id uint) error
+	Authenticate(token, clientIP string) (*middleware.TokenIdentity, error)
+	Load(id uint) (*middleware.TokenIdentity, error)
+}
+
+type apiTokenService struct {
+	tokenRepo      repository.APITokenRepository
+	userRepo       repository.UserRepository
+	roleRepo       repository.RoleRepository
+	permissionRepo repository.PermissionRepository
+}
+
+// NewAPITokenService 创建API令牌服务实例
+func NewAPITokenService(tokenRepo repository.APITokenRepository, userRepo repository.UserRepository, roleRepo repository.RoleRepository, permissionRepo repository.PermissionRepository) APITokenService {
+	return &apiTokenService{
+		tokenRepo:      tokenRepo,
+		userRepo:       userRepo,
+		roleRepo:       roleRepo,
+		permissionRepo: permissionRepo,
+	}
+}
+
+// Create 创建令牌，权限必须在用户角色的权限范围内，明文令牌只在结果中返回一次
+func (s *apiTokenService) Create(userID uint, req *model.APITokenCreateRequest) (*model.APITokenCreateResult, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	count, err := s.tokenRepo.CountActive(userID, now)
+	if err != nil {
+		return nil, err
+	}
+	if count >= apiTokenMaxActive {
+		return nil, fmt.Errorf("每个用户最多持有 %d 个有效的API令牌", apiTokenMaxActive)
+	}
+
+	granted, err := s.rolePermissions(user.Role)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(req.Permissions))
+	seen := make(map[string]bool, len(req.Permissions))
+	for _, name := range req.Permissions {
+		name = strings.TrimSpace(name)
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		permission, err := s.permissionRepo.GetByName(name)
+		if err != nil || permission.Status != 1 {
+			return nil, fmt.Errorf("权限 %s 不存在或已禁用", name)
+		}
+		if !coveredBy(permission, granted) {
+			return nil, fmt.Errorf("权限 %s 超出了当前用户的权限范围", name)
+		}
+		names = append(names, name)
+	}
+
+	days := req.ExpiresInDays
+	if days == 0 {
+		days = apiTokenDefaultDays
+	}
+	secret, err := newAPITokenSecret()
+	if err != nil {
+		return nil, errors.New("生成API令牌失败")
+	}
+
+	token := &model.APIToken{
+		UserID:      userID,
+		Name:        strings.TrimSpace(req.Name),
+		Prefix:      secret[:len(middleware.TokenPrefix)+8],
+		TokenHash:   utils.SHA256(secret),
+		Permissions: names,
+		ExpiresAt:   now.AddDate(0, 0, days),
+	}
+	if err := s.tokenRepo.Create(token); err != nil {
+		logger.Errorf("创建API令牌失败: %v", err)
+		return nil, errors.New("创建API令牌失败")
+	}
+
+	logger.Infof("用户 %s 创建API令牌 %s (#%d)，权限: %v", user.Username, token.Name, token.ID, names)
+	return &model.APITokenCreateResult{APIToken: token, Token: secret}, nil
+}
+
+// List 获取用户的令牌列表
+func (s *apiTokenService) List(userID uint) ([]*model.APIToken, error) {
+	return s.tokenRepo.ListByUser(userID)
+}
+
+// Revoke 吊销用户自己的令牌
+func (s *apiTokenService) Revoke(userID, id uint) error {
+	token, err := s.tokenRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if token.UserID != userID {
+		return errors.New("API令牌不存在")
+	}
+	if token.RevokedAt != nil {
+		return nil
+	}
+
+	if err := s.tokenRepo.Revoke(id, time.Now()); err != nil {
+		logger.Errorf("吊销API令牌失败: %v", err)
+		return errors.New("吊销API令牌失败")
+	}
+	logger.Infof("API令牌 %s (#%d) 已吊销", token.Name, token.ID)
+	return nil
+}
+
+// Authenticate 校验令牌，返回令牌所属用户当前的角色和令牌的权限范围
+func (s *apiTokenService) Authenticate(secret, clientIP string) (*middleware.TokenIdentity, error) {
+	token, err := s.tokenRepo.GetByHash(utils.SHA256(secret))
+	if err != nil {
+		return nil, errors.New("API令牌不存在")
+	}
+	now := time.Now()
+	identity, err := s.identity(token, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= apiTokenTouchInterval || token.LastUsedIP != clientIP {
+		if err := s.tokenRepo.TouchLastUsed(token.ID, now, clientIP); err != nil {
+			logger.Warnf("记录API令牌使用时间失败: %v", err)
+		}
+	}
+	return identity, nil
+}
+
+// Load 按ID加载令牌当前的身份和权限范围，用于重放使用该令牌提交的变更，不记录使用时间
+func (s *apiTokenService) Load(id uint) (*middleware.TokenIdentity, error) {
+	token, err := s.tokenRepo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("API令牌不存在")
+	}
+	return s.identity(token, time.Now())
+}
+
+// identity 检查令牌及其用户的状态，返回用户当前的角色和令牌的权限范围
+func (s *apiTokenService) identity(token *model.APIToken, now time.Time) (*middleware.TokenIdentity, error) {
+	if token.RevokedAt != nil {
+		return nil, fmt.Errorf("API令牌 #%d 已吊销", token.ID)
+	}
+	if !now.Before(token.ExpiresAt) {
+		return nil, fmt.Errorf("API令牌 #%d 已过期", token.ID)
+	}
+
+	user, err := s.userRepo.GetByID(token.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("API令牌 #%d 的用户不存在", token.ID)
+	}
+	if user.Status != 1 {
+		return nil, fmt.Errorf("API令牌 #%d 的用户已被禁用", token.ID)
+	}
+
+	identity := &middleware.TokenIdentity{
+		TokenID:  token.ID,
+		UserID:   user.ID,
+		Username: user.Username,
+		Role:     user.Role,
+		Scopes:   make([]middleware.TokenScope, 0, len(token.Permissions)),
+	}
+	// 已删除或禁用的权限不再生效
+	for _, name := range token.Permissions {
+		permission, err := s.permissionRepo.GetByName(name)
+		if err != nil || permission.Status != 1 {
+			continue
+		}
+		identity.Scopes = append(identity.Scopes, middleware.TokenScope{
+			Resource:  permission.Resource,
+			Action:    permission.Action,
+			Condition: permission.Condition,
+		})
+	}
+	return identity, nil
+}
+
+// rolePermissions 获取角色已启用的权限
+func (s *apiTokenService) rolePermissions(roleName string) ([]*model.Permission, error) {
+	role, err := s.roleRepo.GetByName(roleName)
+	if err != nil {
+		return nil, err
+	}
+	if role.Status != 1 {
+		return nil, errors.New("当前用户的角色已被禁用")
+	}
+	return s.roleRepo.GetRolePermissions(role.ID)
+}
+
+// coveredBy 判断权限是否被角色的某个权限覆盖：资源路径匹配、操作相同或为*，且条件不比令牌权限更严格
+func coveredBy(permission *model.Permission, granted []*model.Permission) bool {
+	for _, g := range granted {
+		if !util.KeyMatch2(permission.Resource, g.Resource) {
+			continue
+		}
+		if g.Action != "*" && g.Action != permission.Action {
+			continue
+		}
+		if g.Condition != "" && g.Condition != middleware.ConditionAny && g.Condition != permission.Condition {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// newAPITokenSecret 生成带前缀的随机令牌
+func newAPITokenSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return middleware.TokenPrefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
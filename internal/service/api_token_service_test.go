@@ -0,0 +1,115 @@
+package service
+
+import (
+	"domain-admin/internal/repository"
+	"domain-admin/model"
+	"domain-admin/pkg/middleware"
+	"domain-admin/pkg/utils"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func newTestAPITokenService(t *testing.T) (APITokenService, *gorm.DB) {
+	t.Helper()
+	db := newTestDB(t, &model.User{}, &model.Role{}, &model.Permission{}, &model.APIToken{})
+	list := &model.Permission{Name: "domain.list", DisplayName: "域名列表", Resource: "/api/domains", Action: "GET", Status: 1}
+	update := &model.Permission{Name: "domain.update", DisplayName: "更新域名", Resource: "/api/domains/:id", Action: "PUT", Status: 1}
+	users := &model.Permission{Name: "user.list", DisplayName: "用户列表", Resource: "/api/users", Action: "GET", Status: 1}
+	for _, p := range []*model.Permission{list, update, users} {
+		if err := db.Create(p).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	role := &model.Role{Name: "operator", DisplayName: "运维", Level: 50, Status: 1}
+	if err := db.Create(role).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Model(role).Association("Permissions").Append(list, update); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&model.User{Username: "alice", Email: "alice@example.com", Password: "x", Role: "operator", Status: 1}).Error; err != nil {
+		t.Fatal(err)
+	}
+	svc := NewAPITokenService(
+		repository.NewAPITokenRepository(db),
+		repository.NewUserRepository(db),
+		repository.NewRoleRepository(db),
+		repository.NewPermissionRepository(db),
+	)
+	return svc, db
+}
+
+func TestAPITokenHashedLookup(t *testing.T) {
+	svc, db := newTestAPITokenService(t)
+	result, err := svc.Create(1, &model.APITokenCreateRequest{Name: "ci", Permissions: []string{"domain.list", "domain.update"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(result.Token, middleware.TokenPrefix) || !strings.HasPrefix(result.Token, result.Prefix) {
+		t.Fatalf("token = %s, prefix = %s", result.Token, result.Prefix)
+	}
+
+	// 数据库只保存令牌哈希
+	var stored model.APIToken
+	db.First(&stored, result.ID)
+	if stored.TokenHash != utils.SHA256(result.Token) || stored.TokenHash == result.Token {
+		t.Fatalf("stored hash = %s", stored.TokenHash)
+	}
+
+	identity, err := svc.Authenticate(result.Token, "10.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.UserID != 1 || identity.Role != "operator" || len(identity.Scopes) != 2 {
+		t.Fatalf("identity = %+v", identity)
+	}
+	db.First(&stored, result.ID)
+	if stored.LastUsedAt == nil || stored.LastUsedIP != "10.0.0.1" {
+		t.Fatalf("last used = %v %s", stored.LastUsedAt, stored.LastUsedIP)
+	}
+
+	if _, err := svc.Authenticate(result.Token+"x", ""); err == nil {
+		t.Fatal("unknown token was accepted")
+	}
+	if _, err := svc.Authenticate(stored.TokenHash, ""); err == nil {
+		t.Fatal("token hash was accepted as token")
+	}
+
+	// 已禁用的权限不再出现在令牌权限范围中
+	db.Model(&model.Permission{}).Where("name = ?", "domain.update").Update("status", 0)
+	identity, err = svc.Authenticate(result.Token, "10.0.0.1")
+	if err != nil || len(identity.Scopes) != 1 || identity.Scopes[0].Resource != "/api/domains" {
+		t.Fatalf("identity = %+v, %v", identity, err)
+	}
+
+	if err := svc.Revoke(2, result.ID); err == nil {
+		t.Fatal("another user revoked the token")
+	}
+	if err := svc.Revoke(1, result.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Authenticate(result.Token, ""); err == nil {
+		t.Fatal("revoked token was accepted")
+	}
+}
+
+func TestAPITokenScopeLimitedToRole(t *testing.T) {
+	svc, db := newTestAPITokenService(t)
+	if _, err := svc.Create(1, &model.APITokenCreateRequest{Name: "ci", Permissions: []string{"user.list"}}); err == nil {
+		t.Fatal("token was granted a permission outside the role")
+	}
+	if _, err := svc.Create(1, &model.APITokenCreateRequest{Name: "ci", Permissions: []string{"missing"}}); err == nil {
+		t.Fatal("token was granted an unknown permission")
+	}
+
+	result, err := svc.Create(1, &model.APITokenCreateRequest{Name: "ci", Permissions: []string{"domain.list"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Model(&model.User{}).Where("id = ?", 1).Update("status", 0)
+	if _, err := svc.Authenticate(result.Token, ""); err == nil {
+		t.Fatal("token of disabled user was accepted")
+	}
+}
@@ -0,0 +1,419 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"domain-admin/internal/repository"
+	"domain-admin/model"
+	"domain-admin/pkg/cache"
+	"domain-admin/pkg/jwt"
+	"domain-admin/pkg/logger"
+	"domain-admin/pkg/middleware"
+	"domain-admin/pkg/utils"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// sessionTouchInterval 记录会话最近访问时间的最小间隔，IP 变化时立即记录
+const sessionTouchInterval = time.Minute
+
+// ErrRefreshTokenInvalid 刷新令牌无效，客户端需要重新登录
+var ErrRefreshTokenInvalid = errors.New("刷新令牌无效或已过期，请重新登录")
+
+// AuthTokenService 登录令牌服务接口，负责登录会话、访问令牌签发和刷新令牌轮换
+type AuthTokenService interface {
+	StartSession(user *model.UserResponse, clientIP, userAgent string) (*model.TokenPair, error)
+	Refresh(refreshToken, clientIP, userAgent string) (*model.TokenPair, error)
+	ValidateSession(ctx context.Context, sessionID, clientIP string) (*middleware.SessionInfo, error)
+	ListSessions(userID uint, currentSessionID string) ([]*model.UserSession, error)
+	RevokeSession(userID, id uint) error
+	RevokeOtherSessions(userID uint, currentSessionID string) (int, error)
+	RevokeUser(userID uint) (int, error)
+	Logout(userID uint, sessionID string) error
+}
+
+type authTokenService struct {
+	sessionRepo repository.UserSessionRepository
+	refreshRepo repository.RefreshTokenRepository
+	userRepo    repository.UserRepository
+	refreshTTL  time.Duration
+}
+
+// sessionState 缓存在 Redis 中的会话状态
+type sessionState struct {
+	ID         uint      `json:"id"`
+	UserID     uint      `json:"user_id"`
+	Username   string    `json:"username"`
+	Role       string    `json:"role"`
+	ClientIP   string    `json:"client_ip"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// NewAuthTokenService 创建登录令牌服务实例
+func NewAuthTokenService(sessionRepo repository.UserSessionRepository, refreshRepo repository.RefreshTokenRepository, userRepo repository.UserRepository, refreshTTL time.Duration) AuthTokenService {
+	return &authTokenService{
+		sessionRepo: sessionRepo,
+		refreshRepo: refreshRepo,
+		userRepo:    userRepo,
+		refreshTTL:  refreshTTL,
+	}
+}
+
+// StartSession 登录成功后创建会话，签发带有会话jti的访问令牌和该会话的刷新令牌
+func (s *authTokenService) StartSession(user *model.UserResponse, clientIP, userAgent string) (*model.TokenPair, error) {
+	jti, err := randomHex(16)
+	if err != nil {
+		return nil, errors.New("创建会话失败")
+	}
+	now := time.Now()
+	userAgent = truncate(userAgent, 255)
+	session := &model.UserSession{
+		UserID:     user.ID,
+		JTI:        jti,
+		Device:     deviceName(userAgent),
+		ClientIP:   clientIP,
+		UserAgent:  userAgent,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(s.refreshTTL),
+	}
+	if err := s.sessionRepo.Create(session); err != nil {
+		logger.Errorf("创建会话失败: %v", err)
+		return nil, errors.New("创建会话失败")
+	}
+
+	secret, refresh, err := s.newRefreshToken(user.ID, jti, clientIP, userAgent, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.refreshRepo.Create(refresh); err != nil {
+		logger.Errorf("保存刷新令牌失败: %v", err)
+		return nil, errors.New("生成刷新令牌失败")
+	}
+
+	token, err := jwt.GenerateToken(user.ID, user.Username, user.Role, jti)
+	if err != nil {
+		logger.Errorf("生成token失败: %v", err)
+		return nil, errors.New("生成访问令牌失败")
+	}
+	return &model.TokenPair{
+		Token:        token,
+		RefreshToken: secret,
+		ExpiresIn:    int64(jwt.Expiration / time.Second),
+	}, nil
+}
+
+// Refresh 使用刷新令牌换取新的访问令牌和刷新令牌，旧刷新令牌随即失效。
+// 已轮换的刷新令牌再次使用时吊销整个令牌族和所属会话
+func (s *authTokenService) Refresh(secret, clientIP, userAgent string) (*model.TokenPair, error) {
+	old, err := s.refreshRepo.GetByHash(utils.SHA256(secret))
+	if err != nil {
+		return nil, ErrRefreshTokenInvalid
+	}
+	now := time.Now()
+	if old.RevokedAt != nil || !now.Before(old.ExpiresAt) {
+		return nil, ErrRefreshTokenInvalid
+	}
+	if old.UsedAt != nil {
+		s.revokeFamily(old, clientIP, now)
+		return nil, ErrRefreshTokenInvalid
+	}
+
+	session, err := s.sessionRepo.GetByJTI(old.FamilyID)
+	if err != nil || session.RevokedAt != nil {
+		return nil, ErrRefreshTokenInvalid
+	}
+	user, err := s.userRepo.GetByID(old.UserID)
+	if err != nil || user.Status != 1 {
+		s.revokeFamily(old, clientIP, now)
+		return nil, ErrRefreshTokenInvalid
+	}
+
+	nextSecret, next, err := s.newRefreshToken(user.ID, old.FamilyID, clientIP, truncate(userAgent, 255), now)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.refreshRepo.Rotate(old, next, now); err != nil {
+		if errors.Is(err, repository.ErrRefreshTokenUsed) {
+			// 并发请求中另一个已完成轮换，同样视为重复使用
+			s.revokeFamily(old, clientIP, now)
+			return nil, ErrRefreshTokenInvalid
+		}
+		logger.Errorf("轮换刷新令牌失败: %v", err)
+		return nil, errors.New("刷新令牌失败")
+	}
+
+	if err := s.sessionRepo.Extend(session.ID, next.ExpiresAt); err != nil {
+		logger.Warnf("延长会话有效期失败: %v", err)
+	}
+	if err := s.sessionRepo.Touch(session.ID, now, clientIP); err != nil {
+		logger.Warnf("记录会话访问时间失败: %v", err)
+	}
+	// 角色等信息可能已变化，下次请求时重新从数据库读取
+	s.dropSessionCache(session.JTI)
+
+	token, err := jwt.GenerateToken(user.ID, user.Username, user.Role, session.JTI)
+	if err != nil {
+		logger.Errorf("生成token失败: %v", err)
+		return nil, errors.New("生成访问令牌失败")
+	}
+	return &model.TokenPair{
+		Token:        token,
+		RefreshToken: nextSecret,
+		ExpiresIn:    int64(jwt.Expiration / time.Second),
+	}, nil
+}
+
+// ValidateSession 校验访问令牌所属的会话，作为 middleware.SessionValidator 使用。
+// 优先读取 Redis 中的会话状态，未命中或 Redis 不可用时查询数据库
+func (s *authTokenService) ValidateSession(ctx context.Context, sessionID, clientIP string) (*middleware.SessionInfo, error) {
+	if sessionID == "" {
+		return nil, errors.New("访问令牌缺少会话ID")
+	}
+
+	var state sessionState
+	if err := cache.GetSessionCache(ctx, sessionID, &state); err != nil {
+		if !errors.Is(err, redis.Nil) {
+			logger.Warnf("获取会话缓存失败，从数据库校验会话: %v", err)
+		}
+		loaded, err := s.loadSessionState(sessionID)
+		if err != nil {
+			return nil, err
+		}
+		state = *loaded
+		if err := cache.SetSessionCache(ctx, sessionID, &state); err != nil {
+			logger.Debugf("缓存会话状态失败: %v", err)
+		}
+	}
+
+	now := time.Now()
+	if !now.Before(state.ExpiresAt) {
+		return nil, errors.New("会话已过期")
+	}
+	if now.Sub(state.LastSeenAt) >= sessionTouchInterval || state.ClientIP != clientIP {
+		if err := s.sessionRepo.Touch(state.ID, now, clientIP); err != nil {
+			logger.Warnf("记录会话访问时间失败: %v", err)
+		}
+		state.LastSeenAt = now
+		state.ClientIP = clientIP
+		if err := cache.SetSessionCache(ctx, sessionID, &state); err != nil {
+			logger.Debugf("缓存会话状态失败: %v", err)
+		}
+	}
+
+	return &middleware.SessionInfo{
+		UserID:   state.UserID,
+		Username: state.Username,
+		Role:     state.Role,
+	}, nil
+}
+
+// ListSessions 获取用户的有效会话，并标记当前请求所属的会话
+func (s *authTokenService) ListSessions(userID uint, currentSessionID string) ([]*model.UserSession, error) {
+	sessions, err := s.sessionRepo.ListActive(userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	for _, session := range sessions {
+		session.Current = session.JTI == currentSessionID
+	}
+	return sessions, nil
+}
+
+// RevokeSession 吊销用户自己的会话，会话的访问令牌和刷新令牌立即失效
+func (s *authTokenService) RevokeSession(userID, id uint) error {
+	session, err := s.sessionRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if session.UserID != userID {
+		return errors.New("会话不存在")
+	}
+	return s.revoke(session, time.Now())
+}
+
+// RevokeOtherSessions 吊销用户除当前会话外的全部会话，返回吊销的数量
+func (s *authTokenService) RevokeOtherSessions(userID uint, currentSessionID string) (int, error) {
+	now := time.Now()
+	sessions, err := s.sessionRepo.ListActive(userID, now)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, session := range sessions {
+		if session.JTI == currentSessionID {
+			continue
+		}
+		if err := s.revoke(session, now); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// RevokeUser 吊销用户的全部会话和刷新令牌，用于禁用、删除用户或重置密码，返回吊销的会话数量
+func (s *authTokenService) RevokeUser(userID uint) (int, error) {
+	count, err := s.RevokeOtherSessions(userID, "")
+	if err != nil {
+		return count, err
+	}
+	// 会话已过期但仍未吊销的刷新令牌一并吊销
+	if err := s.refreshRepo.RevokeByUser(userID, time.Now()); err != nil {
+		logger.Errorf("吊销用户 %d 的刷新令牌失败: %v", userID, err)
+		return count, errors.New("吊销刷新令牌失败")
+	}
+	return count, nil
+}
+
+// Logout 吊销当前会话，其他设备上的会话不受影响
+func (s *authTokenService) Logout(userID uint, sessionID string) error {
+	session, err := s.sessionRepo.GetByJTI(sessionID)
+	if err != nil || session.UserID != userID {
+		return errors.New("会话不存在")
+	}
+	if err := s.revoke(session, time.Now()); err != nil {
+		return errors.New("登出失败")
+	}
+	logger.Infof("用户登出成功，用户ID: %d，会话: %d", userID, session.ID)
+	return nil
+}
+
+// revoke 吊销会话及其刷新令牌，并删除会话缓存
+func (s *authTokenService) revoke(session *model.UserSession, at time.Time) error {
+	if err := s.sessionRepo.Revoke(session.ID, at); err != nil {
+		logger.Errorf("吊销会话失败: %v", err)
+		return errors.New("吊销会话失败")
+	}
+	if err := s.refreshRepo.RevokeFamily(session.JTI, at); err != nil {
+		logger.Errorf("吊销刷新令牌失败: %v", err)
+		return errors.New("吊销会话失败")
+	}
+	s.dropSessionCache(session.JTI)
+	return nil
+}
+
+// revokeFamily 刷新令牌被重复使用或用户不可用时吊销令牌族和所属会话
+func (s *authTokenService) revokeFamily(token *model.RefreshToken, clientIP string, at time.Time) {
+	logger.Warnf("刷新令牌 #%d 被重复使用或用户不可用，吊销会话 %s，用户ID: %d，IP: %s", token.ID, token.FamilyID, token.UserID, clientIP)
+	if err := s.refreshRepo.RevokeFamily(token.FamilyID, at); err != nil {
+		logger.Errorf("吊销令牌族失败: %v", err)
+	}
+	if session, err := s.sessionRepo.GetByJTI(token.FamilyID); err == nil {
+		if err := s.sessionRepo.Revoke(session.ID, at); err != nil {
+			logger.Errorf("吊销会话失败: %v", err)
+		}
+		s.dropSessionCache(session.JTI)
+	}
+}
+
+// loadSessionState 从数据库读取会话和用户的最新状态
+func (s *authTokenService) loadSessionState(sessionID string) (*sessionState, error) {
+	session, err := s.sessionRepo.GetByJTI(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.RevokedAt != nil {
+		return nil, errors.New("会话已吊销")
+	}
+	user, err := s.userRepo.GetByID(session.UserID)
+	if err != nil {
+		return nil, errors.New("会话的用户不存在")
+	}
+	if user.Status != 1 {
+		return nil, errors.New("会话的用户已被禁用")
+	}
+	return &sessionState{
+		ID:         session.ID,
+		UserID:     user.ID,
+		Username:   user.Username,
+		Role:       user.Role,
+		ClientIP:   session.ClientIP,
+		LastSeenAt: session.LastSeenAt,
+		ExpiresAt:  session.ExpiresAt,
+	}, nil
+}
+
+// dropSessionCache 删除会话缓存，删除失败时缓存最多在 cache.SessionCacheExpire 后过期
+func (s *authTokenService) dropSessionCache(sessionID string) {
+	if err := cache.DelSessionCache(context.Background(), sessionID); err != nil {
+		logger.Warnf("删除会话缓存失败: %v", err)
+	}
+}
+
+// newRefreshToken 生成刷新令牌明文和待保存的记录
+func (s *authTokenService) newRefreshToken(userID uint, familyID, clientIP, userAgent string, now time.Time) (string, *model.RefreshToken, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", nil, errors.New("生成刷新令牌失败")
+	}
+	secret := base64.RawURLEncoding.EncodeToString(buf)
+	return secret, &model.RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: utils.SHA256(secret),
+		ExpiresAt: now.Add(s.refreshTTL),
+		ClientIP:  clientIP,
+		UserAgent: userAgent,
+	}, nil
+}
+
+// deviceName 根据 User-Agent 推断设备名称，如 "Chrome on macOS"
+func deviceName(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	if ua == "" {
+		return "未知设备"
+	}
+
+	browser := ""
+	switch {
+	case strings.Contains(ua, "edg/"):
+		browser = "Edge"
+	case strings.Contains(ua, "firefox/"):
+		browser = "Firefox"
+	case strings.Contains(ua, "chrome/"):
+		browser = "Chrome"
+	case strings.Contains(ua, "safari/"):
+		browser = "Safari"
+	case strings.HasPrefix(ua, "curl/"):
+		return "curl"
+	default:
+		return truncate(userAgent, 100)
+	}
+
+	switch {
+	case strings.Contains(ua, "iphone"), strings.Contains(ua, "ipad"):
+		return browser + " on iOS"
+	case strings.Contains(ua, "android"):
+		return browser + " on Android"
+	case strings.Contains(ua, "windows"):
+		return browser + " on Windows"
+	case strings.Contains(ua, "mac os"):
+		return browser + " on macOS"
+	case strings.Contains(ua, "linux"):
+		return browser + " on Linux"
+	}
+	return browser
+}
+
+// truncate 截断字符串到 n 字节，不保留被截断的半个字符
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return strings.ToValidUTF8(s[:n], "")
+	}
+	return s
+}
+
+// randomHex 生成 n 字节的十六进制随机串
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"domain-admin/internal/repository"
+	"domain-admin/model"
+	"domain-admin/pkg/jwt"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func newTestAuthTokenService(t *testing.T) (AuthTokenService, *gorm.DB, *model.UserResponse) {
+	t.Helper()
+	jwt.Init("test-secret", time.Minute)
+	db := newTestDB(t, &model.User{}, &model.UserSession{}, &model.RefreshToken{})
+	user := &model.User{Username: "alice", Email: "alice@example.com", Password: "x", Role: "user", Status: 1}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatal(err)
+	}
+	svc := NewAuthTokenService(
+		repository.NewUserSessionRepository(db),
+		repository.NewRefreshTokenRepository(db),
+		repository.NewUserRepository(db),
+		time.Hour,
+	)
+	return svc, db, &model.UserResponse{ID: user.ID, Username: user.Username, Role: user.Role}
+}
+
+// sessionRevoked 返回会话及其全部刷新令牌是否都已吊销
+func sessionRevoked(t *testing.T, db *gorm.DB, jti string) bool {
+	t.Helper()
+	var session model.UserSession
+	if err := db.Where("jti = ?", jti).First(&session).Error; err != nil {
+		t.Fatal(err)
+	}
+	var active int64
+	db.Model(&model.RefreshToken{}).Where("family_id = ? AND revoked_at IS NULL", jti).Count(&active)
+	return session.RevokedAt != nil && active == 0
+}
+
+func TestRefreshRotation(t *testing.T) {
+	svc, db, user := newTestAuthTokenService(t)
+	pair, err := svc.StartSession(user, "10.0.0.1", "curl/8.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := jwt.ParseToken(pair.Token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	next, err := svc.Refresh(pair.RefreshToken, "10.0.0.2", "curl/8.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.RefreshToken == pair.RefreshToken {
+		t.Fatal("refresh token was not rotated")
+	}
+	nextClaims, err := jwt.ParseToken(next.Token)
+	if err != nil || nextClaims.ID != claims.ID || nextClaims.UserID != user.ID {
+		t.Fatalf("refreshed claims = %+v, %v", nextClaims, err)
+	}
+
+	// 新刷新令牌继续可用，同一令牌族只有一个未使用的令牌
+	if _, err := svc.Refresh(next.RefreshToken, "10.0.0.2", "curl/8.0"); err != nil {
+		t.Fatal(err)
+	}
+	var unused int64
+	db.Model(&model.RefreshToken{}).Where("family_id = ? AND used_at IS NULL", claims.ID).Count(&unused)
+	if unused != 1 {
+		t.Fatalf("unused refresh tokens = %d", unused)
+	}
+	if sessionRevoked(t, db, claims.ID) {
+		t.Fatal("session revoked after normal rotation")
+	}
+
+	if _, err := svc.Refresh("unknown", "", ""); err != ErrRefreshTokenInvalid {
+		t.Fatalf("unknown refresh token: %v", err)
+	}
+}
+
+func TestRefreshReuseRevokesFamily(t *testing.T) {
+	svc, db, user := newTestAuthTokenService(t)
+	stolen, err := svc.StartSession(user, "10.0.0.1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := svc.StartSession(user, "10.0.0.1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, _ := jwt.ParseToken(stolen.Token)
+	otherClaims, _ := jwt.ParseToken(other.Token)
+
+	next, err := svc.Refresh(stolen.RefreshToken, "10.0.0.1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 已轮换的刷新令牌再次使用时吊销整个令牌族，合法客户端的新令牌也随之失效
+	if _, err := svc.Refresh(stolen.RefreshToken, "203.0.113.1", ""); err != ErrRefreshTokenInvalid {
+		t.Fatalf("reused refresh token: %v", err)
+	}
+	if !sessionRevoked(t, db, claims.ID) {
+		t.Fatal("session not revoked after refresh token reuse")
+	}
+	if _, err := svc.Refresh(next.RefreshToken, "10.0.0.1", ""); err != ErrRefreshTokenInvalid {
+		t.Fatalf("refresh token of revoked family: %v", err)
+	}
+
+	// 同一用户的其他会话不受影响
+	if sessionRevoked(t, db, otherClaims.ID) {
+		t.Fatal("other session was revoked")
+	}
+	if _, err := svc.Refresh(other.RefreshToken, "10.0.0.1", ""); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRefreshDisabledUser(t *testing.T) {
+	svc, db, user := newTestAuthTokenService(t)
+	pair, err := svc.StartSession(user, "10.0.0.1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, _ := jwt.ParseToken(pair.Token)
+
+	db.Model(&model.User{}).Where("id = ?", user.ID).Update("status", 0)
+	if _, err := svc.Refresh(pair.RefreshToken, "10.0.0.1", ""); err != ErrRefreshTokenInvalid {
+		t.Fatalf("refresh of disabled user: %v", err)
+	}
+	if !sessionRevoked(t, db, claims.ID) {
+		t.Fatal("session of disabled user was not revoked")
+	}
+
+	// 重新启用后原刷新令牌仍然无效
+	db.Model(&model.User{}).Where("id = ?", user.ID).Update("status", 1)
+	if _, err := svc.Refresh(pair.RefreshToken, "10.0.0.1", ""); err != ErrRefreshTokenInvalid {
+		t.Fatalf("refresh after re-enable: %v", err)
+	}
+}
+
+func TestRevokeUser(t *testing.T) {
+	svc, db, user := newTestAuthTokenService(t)
+	first, err := svc.StartSession(user, "10.0.0.1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := svc.StartSession(user, "10.0.0.2", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 会话已过期时其刷新令牌也要吊销
+	db.Model(&model.UserSession{}).Where("client_ip = ?", "10.0.0.2").Update("expires_at", time.Now().Add(-time.Minute))
+
+	count, err := svc.RevokeUser(user.ID)
+	if err != nil || count != 1 {
+		t.Fatalf("RevokeUser = %d, %v", count, err)
+	}
+	var active int64
+	db.Model(&model.RefreshToken{}).Where("user_id = ? AND revoked_at IS NULL", user.ID).Count(&active)
+	if active != 0 {
+		t.Fatalf("active refresh tokens = %d", active)
+	}
+	for _, pair := range []*model.TokenPair{first, second} {
+		if _, err := svc.Refresh(pair.RefreshToken, "", ""); err != ErrRefreshTokenInvalid {
+			t.Fatalf("refresh after RevokeUser: %v", err)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package service
+
+import (
+	"context"
+	"domain-admin/internal/repository"
+	"domain-admin/model"
+	"domain-admin/pkg/config"
+	"domain-admin/pkg/logger"
+	"domain-admin/pkg/pagination"
+	"domain-admin/pkg/tlsprobe"
+	"errors"
+	"net"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// defaultCertificatePort 未指定端口时探测的端口
+const defaultCertificatePort = 443
+
+// probeConcurrency 批量探测时的并发数
+const probeConcurrency = 8
+
+// hostnamePattern 主机名格式（不允许通配符）
+var hostnamePattern = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?)(\.[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?)*$`)
+
+// CertificateService 证书服务接口
+type CertificateService interface {
+	Create(ctx context.Context, req *model.CertificateCreateRequest) (*model.Certificate, error)
+	GetByID(id uint) (*model.Certificate, error)
+	Update(ctx context.Context, id uint, req *model.CertificateUpdateRequest) (*model.Certificate, error)
+	Delete(id uint) error
+	List(status string, page pagination.Pagination) ([]*model.Certificate, int64, error)
+	Probe(ctx context.Context, id uint) (*model.Certificate, error)
+	ProbeAll(ctx context.Context) ([]*model.Certificate, error)
+	CountExpiring() (int64, error)
+}
+
+type certificateService struct {
+	certRepo     repository.CertificateRepository
+	prober       *tlsprobe.Prober
+	expiryWindow time.Duration
+}
+
+// NewCertificateService 创建证书服务实例
+func NewCertificateService(certRepo repository.CertificateRepository, cfg config.CertificateConfig) CertificateService {
+	return &certificateService{
+		certRepo:     certRepo,
+		prober:       tlsprobe.New(cfg.ProbeTimeout()),
+		expiryWindow: cfg.ExpiryWindow(),
+	}
+}
+
+// Create 添加探测目标并立即探测一次，探测失败不影响添加
+func (s *certificateService) Create(ctx context.Context, req *model.CertificateCreateRequest) (*model.Certificate, error) {
+	host, err := normalizeCertificateHost(req.Host)
+	if err != nil {
+		return nil, err
+	}
+	port := req.Port
+	if port == 0 {
+		port = defaultCertificatePort
+	}
+
+	if _, err := s.certRepo.GetByEndpoint(host, port); err == nil {
+		return nil, errors.New("该主机和端口已存在")
+	}
+
+	cert := &model.Certificate{
+		Host:   host,
+		Port:   port,
+		Status: model.CertificateStatusPending,
+		Remark: req.Remark,
+	}
+	if err := s.certRepo.Create(cert); err != nil {
+		logger.Errorf("创建证书失败: %v", err)
+		return nil, errors.New("创建证书失败")
+	}
+
+	logger.Infof("添加证书探测目标成功: %s:%d", cert.Host, cert.Port)
+	return s.probe(ctx, cert)
+}
+
+// GetByID 根据ID获取证书
+func (s *certificateService) GetByID(id uint) (*model.Certificate, error) {
+	if id == 0 {
+		return nil, errors.New("证书ID不能为空")
+	}
+	return s.certRepo.GetByID(id)
+}
+
+// Update 更新探测目标，主机或端口变更后重新探测
+func (s *certificateService) Update(ctx context.Context, id uint, req *model.CertificateUpdateRequest) (*model.Certificate, error) {
+	if id == 0 {
+		return nil, errors.New("证书ID不能为空")
+	}
+
+	cert, err := s.certRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	host, port := cert.Host, cert.Port
+	if req.Host != "" {
+		if host, err = normalizeCertificateHost(req.Host); err != nil {
+			return nil, err
+		}
+	}
+	if req.Port != 0 {
+		port = req.Port
+	}
+
+	endpointChanged := host != cert.Host || port != cert.Port
+	if endpointChanged {
+		if existing, err := s.certRepo.GetByEndpoint(host, port); err == nil && existing.ID != cert.ID {
+			return nil, errors.New("该主机和端口已存在")
+		}
+		// 原有的探测结果不再适用于新的目标
+		*cert = model.Certificate{
+			ID:        cert.ID,
+			Remark:    cert.Remark,
+			CreatedAt: cert.CreatedAt,
+		}
+		cert.Host, cert.Port, cert.Status = host, port, model.CertificateStatusPending
+	}
+	if req.Remark != nil {
+		cert.Remark = *req.Remark
+	}
+
+	if err := s.certRepo.Update(cert); err != nil {
+		logger.Errorf("更新证书失败: %v", err)
+		return nil, errors.New("更新证书失败")
+	}
+
+	logger.Infof("更新证书探测目标成功: %s:%d", cert.Host, cert.Port)
+	if endpointChanged {
+		return s.probe(ctx, cert)
+	}
+	return cert, nil
+}
+
+// Delete 删除探测目标
+func (s *certificateService) Delete(id uint) error {
+	if id == 0 {
+		return errors.New("证书ID不能为空")
+	}
+
+	cert, err := s.certRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.certRepo.Delete(id); err != nil {
+		logger.Errorf("删除证书失败: %v", err)
+		return errors.New("删除证书失败")
+	}
+
+	logger.Infof("删除证书探测目标成功: %s:%d", cert.Host, cert.Port)
+	return nil
+}
+
+// List 获取证书列表
+func (s *certificateService) List(status string, page pagination.Pagination) ([]*model.Certificate, int64, error) {
+	return s.certRepo.List(status, page)
+}
+
+// Probe 立即探测单个证书
+func (s *certificateService) Probe(ctx context.Context, id uint) (*model.Certificate, error) {
+	if id == 0 {
+		return nil, errors.New("证书ID不能为空")
+	}
+
+	cert, err := s.certRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.probe(ctx, cert)
+}
+
+// ProbeAll 并发探测全部证书，返回状态不是 valid 的证书
+func (s *certificateService) ProbeAll(ctx context.Context) ([]*model.Certificate, error) {
+	certs, err := s.certRepo.ListAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		problems []*model.Certificate
+	)
+	sem := make(chan struct{}, probeConcurrency)
+	for _, cert := range certs {
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(cert *model.Certificate) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			probed, err := s.probe(ctx, cert)
+			if err != nil {
+				logger.Errorf("保存证书 %s:%d 探测结果失败: %v", cert.Host, cert.Port, err)
+				return
+			}
+			if probed.Status != model.CertificateStatusValid {
+				mu.Lock()
+				problems = append(problems, probed)
+				mu.Unlock()
+			}
+		}(cert)
+	}
+	wg.Wait()
+
+	return problems, ctx.Err()
+}
+
+// CountExpiring 统计即将到期或已过期的证书数量
+func (s *certificateService) CountExpiring() (int64, error) {
+	return s.certRepo.CountExpiringBefore(time.Now().Add(s.expiryWindow))
+}
+
+// probe 探测证书并保存结果，连接失败时保留上一次的证书信息
+func (s *certificateService) probe(ctx context.Context, cert *model.Certificate) (*model.Certificate, error) {
+	now := time.Now()
+	cert.LastProbedAt = &now
+
+	result, err := s.prober.Probe(ctx, cert.Host, cert.Port)
+	if err != nil {
+		logger.Warnf("探测证书 %s:%d 失败: %v", cert.Host, cert.Port, err)
+		cert.Status = model.CertificateStatusError
+		cert.LastError = err.Error()
+	} else {
+		notBefore, notAfter := result.NotBefore, result.NotAfter
+		cert.Subject = result.Subject
+		cert.Issuer = result.Issuer
+		cert.SANs = result.SANs
+		cert.SerialNumber = result.SerialNumber
+		cert.Fingerprint = result.Fingerprint
+		cert.NotBefore = &notBefore
+		cert.NotAfter = &notAfter
+		cert.ChainValid = result.ChainValid
+		cert.ChainError = result.ChainError
+		cert.HostnameMatch = result.HostnameMatch
+		cert.LastError = ""
+		cert.Status = s.certificateStatus(cert, now)
+	}
+
+	if err := s.certRepo.Update(cert); err != nil {
+		return nil, err
+	}
+	return cert, nil
+}
+
+// certificateStatus 根据探测结果计算证书状态，按严重程度依次判断
+func (s *certificateService) certificateStatus(cert *model.Certificate, now time.Time) string {
+	switch {
+	case cert.NotAfter != nil && now.After(*cert.NotAfter):
+		return model.CertificateStatusExpired
+	case !cert.HostnameMatch:
+		return model.CertificateStatusMismatch
+	case !cert.ChainValid:
+		return model.CertificateStatusUntrusted
+	case cert.NotAfter != nil && now.Add(s.expiryWindow).After(*cert.NotAfter):
+		return model.CertificateStatusExpiring
+	default:
+		return model.CertificateStatusValid
+	}
+}
+
+// normalizeCertificateHost 校验并规范化探测的主机名或IP地址
+func normalizeCertificateHost(host string) (string, error) {
+	host = normalizeDomainName(host)
+	if host == "" {
+		return "", errors.New("主机名不能为空")
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.String(), nil
+	}
+	if len(host) > 253 || !hostnamePattern.MatchString(host) {
+		return "", errors.New("主机名格式错误")
+	}
+	return host, nil
+}
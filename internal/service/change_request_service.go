@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"domain-admin/internal/repository"
+	"domain-admin/model"
+	"domain-admin/pkg/logger"
+	"domain-admin/pkg/middleware"
+	"domain-admin/pkg/pagination"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// changeResultLimit 保存的执行结果最大长度
+const changeResultLimit = 4096
+
+// ErrSelfApproval 提交人不能审批自己的变更请求
+var ErrSelfApproval = errors.New("不能审批自己提交的变更请求")
+
+// ChangeRequestService 变更请求服务接口
+type ChangeRequestService interface {
+	Submit(change *middleware.PendingChange, requesterID uint, requesterName string) (*model.ChangeRequest, error)
+	GetByID(id uint) (*model.ChangeRequest, error)
+	List(status string, requesterID uint, page pagination.Pagination) ([]*model.ChangeRequest, int64, error)
+	Approve(ctx context.Context, id, approverID uint, approverName, comment string) (*model.ChangeRequest, error)
+	Reject(id, approverID uint, approverName, comment string) (*model.ChangeRequest, error)
+	Cancel(id, userID uint) (*model.ChangeRequest, error)
+}
+
+type changeRequestService struct {
+	changeRepo repository.ChangeRequestRepository
+	userRepo   repository.UserRepository
+	expire     time.Duration
+}
+
+// NewChangeRequestService 创建变更请求服务实例
+func NewChangeRequestService(changeRepo repository.ChangeRequestRepository, userRepo repository.UserRepository, expire time.Duration) ChangeRequestService {
+	return &changeRequestService{
+		changeRepo: changeRepo,
+		userRepo:   userRepo,
+		expire:     expire,
+	}
+}
+
+// Submit 保存被拦截的请求为待审批的变更请求
+func (s *changeRequestService) Submit(change *middleware.PendingChange, requesterID uint, requesterName string) (*model.ChangeRequest, error) {
+	diff, err := json.Marshal(model.ChangeDiff{Before: change.Before, After: change.After})
+	if err != nil {
+		return nil, errors.New("保存变更内容失败")
+	}
+
+	reason := change.Reason
+	if len([]rune(reason)) > 500 {
+		reason = string([]rune(reason)[:500])
+	}
+	req := &model.ChangeRequest{
+		Method:        change.Method,
+		Route:         change.Route,
+		Path:          change.Path,
+		Query:         change.Query,
+		Body:          string(change.Body),
+		Diff:          diff,
+		Reason:        reason,
+		Status:        model.ChangeStatusPending,
+		RequesterID:   requesterID,
+		RequesterName: requesterName,
+		ExpiresAt:     time.Now().Add(s.expire),
+	}
+	if change.TokenID != 0 {
+		req.APITokenID = &change.TokenID
+	}
+	if err := s.changeRepo.Create(req); err != nil {
+		logger.Errorf("保存变更请求失败: %v", err)
+		return nil, errors.New("保存变更请求失败")
+	}
+
+	logger.Infof("用户 %s 提交变更请求 #%d: %s %s", requesterName, req.ID, req.Method, req.Path)
+	return req, nil
+}
+
+// GetByID 获取变更请求详情
+func (s *changeRequestService) GetByID(id uint) (*model.ChangeRequest, error) {
+	s.expirePending()
+	return s.changeRepo.GetByID(id)
+}
+
+// List 获取变更请求列表
+func (s *changeRequestService) List(status string, requesterID uint, page pagination.Pagination) ([]*model.ChangeRequest, int64, error) {
+	s.expirePending()
+	return s.changeRepo.List(status, requesterID, page)
+}
+
+// Approve 批准变更请求并以提交人身份执行
+// 提交人不能审批自己的请求；执行时重新校验提交人的状态和权限
+func (s *changeRequestService) Approve(ctx context.Context, id, approverID uint, approverName, comment string) (*model.ChangeRequest, error) {
+	change, err := s.pending(id)
+	if err != nil {
+		return nil, err
+	}
+	if change.RequesterID == approverID {
+		return nil, ErrSelfApproval
+	}
+
+	if err := s.decide(change, model.ChangeStatusApproved, approverID, approverName, comment); err != nil {
+		return nil, err
+	}
+
+	code, body, execErr := s.execute(ctx, change)
+	now := time.Now()
+	change.ExecutedAt = &now
+	change.ResultCode = code
+	change.Result = truncateResult(body)
+	change.Status = model.ChangeStatusExecuted
+	if execErr != nil {
+		change.Status = model.ChangeStatusFailed
+		change.Result = execErr.Error()
+	} else if !changeSucceeded(code, body) {
+		change.Status = model.ChangeStatusFailed
+	}
+	if err := s.changeRepo.Update(change); err != nil {
+		logger.Errorf("保存变更请求 #%d 的执行结果失败: %v", change.ID, err)
+		return nil, errors.New("保存执行结果失败")
+	}
+
+	logger.Infof("用户 %s 批准变更请求 #%d，执行结果: %s (%d)", approverName, change.ID, change.Status, code)
+	return change, nil
+}
+
+// Reject 拒绝变更请求
+func (s *changeRequestService) Reject(id, approverID uint, approverName, comment string) (*model.ChangeRequest, error) {
+	change, err := s.pending(id)
+	if err != nil {
+		return nil, err
+	}
+	if change.RequesterID == approverID {
+		return nil, ErrSelfApproval
+	}
+
+	if err := s.decide(change, model.ChangeStatusRejected, approverID, approverName, comment); err != nil {
+		return nil, err
+	}
+
+	logger.Infof("用户 %s 拒绝变更请求 #%d", approverName, change.ID)
+	return change, nil
+}
+
+// Cancel 提交人撤回待审批的变更请求
+func (s *changeRequestService) Cancel(id, userID uint) (*model.ChangeRequest, error) {
+	change, err := s.pending(id)
+	if err != nil {
+		return nil, err
+	}
+	if change.RequesterID != userID {
+		return nil, errors.New("只能撤回自己提交的变更请求")
+	}
+
+	ok, err := s.changeRepo.Transition(change.ID, model.ChangeStatusPending, map[string]interface{}{
+		"status": model.ChangeStatusCancelled,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New("变更请求已被处理")
+	}
+	change.Status = model.ChangeStatusCancelled
+	return change, nil
+}
+
+// pending 获取待审批的变更请求，已过期的请求标记为过期
+func (s *changeRequestService) pending(id uint) (*model.ChangeRequest, error) {
+	change, err := s.changeRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if change.Status != model.ChangeStatusPending {
+		return nil, errors.New("变更请求已被处理")
+	}
+	if time.Now().After(change.ExpiresAt) {
+		s.expirePending()
+		return nil, errors.New("变更请求已过期")
+	}
+	return change, nil
+}
+
+// decide 记录审批结果，状态更新失败说明请求已被其他审批人处理
+func (s *changeRequestService) decide(change *model.ChangeRequest, status string, approverID uint, approverName, comment string) error {
+	now := time.Now()
+	ok, err := s.changeRepo.Transition(change.ID, model.ChangeStatusPending, map[string]interface{}{
+		"status":        status,
+		"approver_id":   approverID,
+		"approver_name": approverName,
+		"comment":       comment,
+		"decided_at":    now,
+	})
+	if err != nil {
+		logger.Errorf("更新变更请求 #%d 失败: %v", change.ID, err)
+		return errors.New("更新变更请求失败")
+	}
+	if !ok {
+		return errors.New("变更请求已被处理")
+	}
+
+	change.Status = status
+	change.ApproverID = &approverID
+	change.ApproverName = approverName
+	change.Comment = comment
+	change.DecidedAt = &now
+	return nil
+}
+
+// execute 以提交人当前的身份重放请求，使用API令牌提交的请求同时受令牌当前的权限范围约束
+func (s *changeRequestService) execute(ctx context.Context, change *model.ChangeRequest) (int, []byte, error) {
+	requester, err := s.userRepo.GetByID(change.RequesterID)
+	if err != nil {
+		return 0, nil, errors.New("提交人不存在")
+	}
+	if requester.Status != 1 {
+		return 0, nil, errors.New("提交人已被禁用")
+	}
+
+	identity := middleware.Identity{UserID: requester.ID, Username: requester.Username, Role: requester.Role}
+	if change.APITokenID != nil {
+		identity.TokenID = *change.APITokenID
+	}
+	return middleware.ReplayChange(ctx, identity, change.Method, change.Path, change.Query, []byte(change.Body))
+}
+
+// expirePending 将过期的待审批请求标记为过期
+func (s *changeRequestService) expirePending() {
+	if n, err := s.changeRepo.ExpirePending(time.Now()); err != nil {
+		logger.Warnf("更新过期变更请求失败: %v", err)
+	} else if n > 0 {
+		logger.Infof("%d 个变更请求已过期", n)
+	}
+}
+
+// changeSucceeded 判断重放请求是否执行成功，处理器的响应体中 code 为200表示成功
+func changeSucceeded(code int, body []byte) bool {
+	if code < 200 || code >= 300 {
+		return false
+	}
+	var resp struct {
+		Code *int `json:"code"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil || resp.Code == nil {
+		return true
+	}
+	return *resp.Code == 200
+}
+
+// truncateResult 截断过长的执行结果
+func truncateResult(body []byte) string {
+	if len(body) > changeResultLimit {
+		return string(body[:changeResultLimit])
+	}
+	return string(body)
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"domain-admin/internal/repository"
+	"domain-admin/model"
+	"domain-admin/pkg/middleware"
+	"domain-admin/pkg/pagination"
+	"domain-admin/pkg/response"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// changeTestEnv 变更审批测试环境，alice 提交变更，bob 审批
+type changeTestEnv struct {
+	db       *gorm.DB
+	changes  ChangeRequestService
+	tokens   APITokenService
+	alice    uint
+	bob      uint
+	replayed []string
+}
+
+func newChangeTestEnv(t *testing.T) *changeTestEnv {
+	t.Helper()
+	db := newTestDB(t, &model.User{}, &model.Role{}, &model.Permission{}, &model.APIToken{}, &model.ChangeRequest{})
+	list := &model.Permission{Name: "domain.list", DisplayName: "域名列表", Resource: "/api/domains", Action: "GET", Status: 1}
+	update := &model.Permission{Name: "domain.update", DisplayName: "更新域名", Resource: "/api/domains/:id", Action: "PUT", Status: 1}
+	role := &model.Role{Name: "operator", DisplayName: "运维", Level: 50, Status: 1}
+	for _, v := range []any{list, update, role} {
+		if err := db.Create(v).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.Model(role).Association("Permissions").Append(list, update); err != nil {
+		t.Fatal(err)
+	}
+	alice := &model.User{Username: "alice", Email: "alice@example.com", Password: "x", Role: "operator", Status: 1}
+	bob := &model.User{Username: "bob", Email: "bob@example.com", Password: "x", Role: "operator", Status: 1}
+	for _, u := range []*model.User{alice, bob} {
+		if err := db.Create(u).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	env := &changeTestEnv{
+		db:      db,
+		changes: NewChangeRequestService(repository.NewChangeRequestRepository(db), repository.NewUserRepository(db), time.Hour),
+		tokens: NewAPITokenService(
+			repository.NewAPITokenRepository(db),
+			repository.NewUserRepository(db),
+			repository.NewRoleRepository(db),
+			repository.NewPermissionRepository(db),
+		),
+		alice: alice.ID,
+		bob:   bob.ID,
+	}
+
+	// 重放引擎只校验登录身份和令牌权限范围，记录执行变更的用户
+	r := gin.New()
+	r.PUT("/api/domains/:id", middleware.JWTAuth(), middleware.RequireTokenScope(), func(c *gin.Context) {
+		env.replayed = append(env.replayed, c.GetString("username"))
+		response.Success(c, gin.H{"id": c.Param("id")})
+	})
+	middleware.InitChangeApproval(nil, r, nil)
+	middleware.SetTokenLoader(env.tokens.Load)
+	t.Cleanup(func() {
+		middleware.InitChangeApproval(nil, nil, nil)
+		middleware.SetTokenLoader(nil)
+	})
+	return env
+}
+
+// submit 以 alice 的身份提交更新域名的变更，tokenID 为0表示使用登录会话提交
+func (env *changeTestEnv) submit(t *testing.T, tokenID uint) *model.ChangeRequest {
+	t.Helper()
+	change, err := env.changes.Submit(&middleware.PendingChange{
+		Method:  "PUT",
+		Route:   "/api/domains/:id",
+		Path:    "/api/domains/1",
+		Body:    []byte(`{"remark":"renewed"}`),
+		Reason:  "续费",
+		TokenID: tokenID,
+	}, env.alice, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return change
+}
+
+func (env *changeTestEnv) newToken(t *testing.T, permissions ...string) uint {
+	t.Helper()
+	result, err := env.tokens.Create(env.alice, &model.APITokenCreateRequest{Name: "ci", Permissions: permissions})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result.ID
+}
+
+func TestChangeRequestApprove(t *testing.T) {
+	env := newChangeTestEnv(t)
+	ctx := context.Background()
+	change := env.submit(t, 0)
+	if change.Status != model.ChangeStatusPending || change.APITokenID != nil {
+		t.Fatalf("submitted change = %+v", change)
+	}
+
+	// 提交人不能审批自己的变更
+	if _, err := env.changes.Approve(ctx, change.ID, env.alice, "alice", ""); err != ErrSelfApproval {
+		t.Fatalf("self approval: %v", err)
+	}
+	if _, err := env.changes.Reject(change.ID, env.alice, "alice", ""); err != ErrSelfApproval {
+		t.Fatalf("self rejection: %v", err)
+	}
+
+	approved, err := env.changes.Approve(ctx, change.ID, env.bob, "bob", "同意")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if approved.Status != model.ChangeStatusExecuted || approved.ResultCode != 200 || approved.ApproverName != "bob" {
+		t.Fatalf("approved change = %+v", approved)
+	}
+	if len(env.replayed) != 1 || env.replayed[0] != "alice" {
+		t.Fatalf("replayed as %v, want the requester", env.replayed)
+	}
+
+	if _, err := env.changes.Approve(ctx, change.ID, env.bob, "bob", ""); err == nil {
+		t.Fatal("change was approved twice")
+	}
+	stored, _ := env.changes.GetByID(change.ID)
+	if stored.Status != model.ChangeStatusExecuted || stored.ExecutedAt == nil {
+		t.Fatalf("stored change = %+v", stored)
+	}
+}
+
+func TestChangeRequestRejectAndCancel(t *testing.T) {
+	env := newChangeTestEnv(t)
+
+	rejected := env.submit(t, 0)
+	result, err := env.changes.Reject(rejected.ID, env.bob, "bob", "不需要")
+	if err != nil || result.Status != model.ChangeStatusRejected || result.Comment != "不需要" {
+		t.Fatalf("Reject = %+v, %v", result, err)
+	}
+	if _, err := env.changes.Approve(context.Background(), rejected.ID, env.bob, "bob", ""); err == nil {
+		t.Fatal("rejected change was approved")
+	}
+
+	// 只有提交人可以撤回
+	cancelled := env.submit(t, 0)
+	if _, err := env.changes.Cancel(cancelled.ID, env.bob); err == nil {
+		t.Fatal("change was cancelled by another user")
+	}
+	if result, err := env.changes.Cancel(cancelled.ID, env.alice); err != nil || result.Status != model.ChangeStatusCancelled {
+		t.Fatalf("Cancel = %+v, %v", result, err)
+	}
+
+	if len(env.replayed) != 0 {
+		t.Fatalf("rejected or cancelled changes were executed: %v", env.replayed)
+	}
+	changes, total, err := env.changes.List("", env.alice, pagination.Pagination{Limit: 10, OrderBy: "id", Sort: "asc"})
+	if err != nil || total != 2 || len(changes) != 2 {
+		t.Fatalf("List = %d, %d, %v", len(changes), total, err)
+	}
+}
+
+func TestChangeRequestExpired(t *testing.T) {
+	env := newChangeTestEnv(t)
+	change := env.submit(t, 0)
+	env.db.Model(&model.ChangeRequest{}).Where("id = ?", change.ID).Update("expires_at", time.Now().Add(-time.Minute))
+
+	if _, err := env.changes.Approve(context.Background(), change.ID, env.bob, "bob", ""); err == nil || !strings.Contains(err.Error(), "过期") {
+		t.Fatalf("approve expired change: %v", err)
+	}
+	stored, _ := env.changes.GetByID(change.ID)
+	if stored.Status != model.ChangeStatusExpired {
+		t.Fatalf("status = %s", stored.Status)
+	}
+}
+
+func TestChangeRequestReplayChecksTokenScope(t *testing.T) {
+	env := newChangeTestEnv(t)
+	ctx := context.Background()
+
+	// 令牌只能查询域名，角色的更新权限不能通过审批绕过令牌权限范围
+	narrow := env.submit(t, env.newToken(t, "domain.list"))
+	if narrow.APITokenID == nil {
+		t.Fatal("token of the requester was not recorded")
+	}
+	result, err := env.changes.Approve(ctx, narrow.ID, env.bob, "bob", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != model.ChangeStatusFailed || result.ResultCode != 403 {
+		t.Fatalf("change outside token scope = %s (%d)", result.Status, result.ResultCode)
+	}
+
+	scoped := env.submit(t, env.newToken(t, "domain.update"))
+	if result, err := env.changes.Approve(ctx, scoped.ID, env.bob, "bob", ""); err != nil || result.Status != model.ChangeStatusExecuted {
+		t.Fatalf("change within token scope = %+v, %v", result, err)
+	}
+
+	// 审批期间令牌被吊销时不再执行
+	tokenID := env.newToken(t, "domain.update")
+	revoked := env.submit(t, tokenID)
+	if err := env.tokens.Revoke(env.alice, tokenID); err != nil {
+		t.Fatal(err)
+	}
+	if result, err := env.changes.Approve(ctx, revoked.ID, env.bob, "bob", ""); err != nil || result.Status != model.ChangeStatusFailed || result.ResultCode != 401 {
+		t.Fatalf("change with revoked token = %+v, %v", result, err)
+	}
+
+	// 审批期间提交人被禁用时不再执行
+	disabled := env.submit(t, 0)
+	env.db.Model(&model.User{}).Where("id = ?", env.alice).Update("status", 0)
+	if result, err := env.changes.Approve(ctx, disabled.ID, env.bob, "bob", ""); err != nil || result.Status != model.ChangeStatusFailed {
+		t.Fatalf("change of disabled requester = %+v, %v", result, err)
+	}
+
+	if len(env.replayed) != 1 {
+		t.Fatalf("replayed %d changes, want 1", len(env.replayed))
+	}
+}
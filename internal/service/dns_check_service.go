@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"domain-admin/pkg/config"
+	"domain-admin/pkg/dnscheck"
+	"domain-admin/pkg/logger"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// dnsNamePattern 查询名称格式，允许 _dmarc、_sip._tcp 等带下划线的标签
+var dnsNamePattern = regexp.MustCompile(`^([a-z0-9_]([a-z0-9_-]{0,61}[a-z0-9_])?)(\.[a-z0-9_]([a-z0-9_-]{0,61}[a-z0-9_])?)*$`)
+
+// DNSCheckService DNS解析诊断服务接口
+type DNSCheckService interface {
+	Check(ctx context.Context, name, recordType string) (*dnscheck.Report, error)
+}
+
+type dnsCheckService struct {
+	checker *dnscheck.Checker
+}
+
+// NewDNSCheckService 创建DNS解析诊断服务实例
+func NewDNSCheckService(cfg config.DNSCheckConfig) DNSCheckService {
+	return &dnsCheckService{
+		checker: dnscheck.New(cfg.Resolvers, cfg.QueryTimeout()),
+	}
+}
+
+// Check 向配置的解析器及区域权威服务器查询记录并对比结果，recordType 为空时查询A记录
+func (s *dnsCheckService) Check(ctx context.Context, name, recordType string) (*dnscheck.Report, error) {
+	name = normalizeDomainName(name)
+	if name == "" {
+		return nil, errors.New("域名不能为空")
+	}
+	if len(name) > 253 || !dnsNamePattern.MatchString(name) {
+		return nil, errors.New("域名格式错误")
+	}
+
+	if recordType == "" {
+		recordType = "A"
+	}
+	qtype, err := dnscheck.ParseType(recordType)
+	if err != nil {
+		return nil, fmt.Errorf("不支持的记录类型，可选值: %s", strings.Join(dnscheck.SupportedTypes(), ","))
+	}
+
+	report, err := s.checker.Check(ctx, name, qtype)
+	if err != nil {
+		logger.Errorf("DNS解析诊断 %s %s 失败: %v", name, recordType, err)
+		return nil, errors.New("DNS解析诊断失败")
+	}
+	if report.ZoneError != "" {
+		logger.Warnf("查找 %s 的权威服务器失败: %s", name, report.ZoneError)
+	}
+	return report, nil
+}
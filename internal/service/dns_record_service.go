@@ -0,0 +1,218 @@
+package service
+
+import (
+	"domain-admin/internal/repository"
+	"domain-admin/model"
+	"domain-admin/pkg/logger"
+	"domain-admin/pkg/pagination"
+	"domain-admin/pkg/validator"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DNSRecordService DNS记录服务接口
+type DNSRecordService interface {
+	Create(zoneID uint, req *model.DNSRecordRequest) (*model.DNSRecord, error)
+	GetByID(zoneID, id uint) (*model.DNSRecord, error)
+	Update(zoneID, id uint, req *model.DNSRecordRequest) (*model.DNSRecord, error)
+	Delete(zoneID, id uint) error
+	List(zoneID uint, page pagination.Pagination) ([]*model.DNSRecord, int64, error)
+}
+
+type dnsRecordService struct {
+	zoneRepo   repository.DNSZoneRepository
+	recordRepo repository.DNSRecordRepository
+}
+
+// NewDNSRecordService 创建DNS记录服务实例
+func NewDNSRecordService(zoneRepo repository.DNSZoneRepository, recordRepo repository.DNSRecordRepository) DNSRecordService {
+	return &dnsRecordService{
+		zoneRepo:   zoneRepo,
+		recordRepo: recordRepo,
+	}
+}
+
+// Create 创建记录
+func (s *dnsRecordService) Create(zoneID uint, req *model.DNSRecordRequest) (*model.DNSRecord, error) {
+	zone, err := s.zoneRepo.GetByID(zoneID)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &model.DNSRecord{ZoneID: zone.ID}
+	applyDNSRecordRequest(zone, record, req)
+
+	if err := s.check(zone, record); err != nil {
+		return nil, err
+	}
+
+	if err := s.recordRepo.Create(record); err != nil {
+		logger.Errorf("创建记录失败: %v", err)
+		return nil, errors.New("创建记录失败")
+	}
+
+	logger.Infof("创建记录成功: %s %s %s", zone.Name, record.Name, record.Type)
+	return record, nil
+}
+
+// GetByID 获取记录详情
+func (s *dnsRecordService) GetByID(zoneID, id uint) (*model.DNSRecord, error) {
+	if id == 0 {
+		return nil, errors.New("记录ID不能为空")
+	}
+	return s.recordRepo.GetByID(zoneID, id)
+}
+
+// Update 更新记录
+func (s *dnsRecordService) Update(zoneID, id uint, req *model.DNSRecordRequest) (*model.DNSRecord, error) {
+	zone, err := s.zoneRepo.GetByID(zoneID)
+	if err != nil {
+		return nil, err
+	}
+
+	record, err := s.recordRepo.GetByID(zoneID, id)
+	if err != nil {
+		return nil, err
+	}
+	applyDNSRecordRequest(zone, record, req)
+
+	if err := s.check(zone, record); err != nil {
+		return nil, err
+	}
+
+	if err := s.recordRepo.Update(record); err != nil {
+		logger.Errorf("更新记录失败: %v", err)
+		return nil, errors.New("更新记录失败")
+	}
+
+	logger.Infof("更新记录成功: %s %s %s", zone.Name, record.Name, record.Type)
+	return record, nil
+}
+
+// Delete 删除记录
+func (s *dnsRecordService) Delete(zoneID, id uint) error {
+	record, err := s.recordRepo.GetByID(zoneID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.recordRepo.Delete(zoneID, id); err != nil {
+		logger.Errorf("删除记录失败: %v", err)
+		return errors.New("删除记录失败")
+	}
+
+	logger.Infof("删除记录成功: zone=%d %s %s", zoneID, record.Name, record.Type)
+	return nil
+}
+
+// List 获取区域下的记录列表
+func (s *dnsRecordService) List(zoneID uint, page pagination.Pagination) ([]*model.DNSRecord, int64, error) {
+	if _, err := s.zoneRepo.GetByID(zoneID); err != nil {
+		return nil, 0, err
+	}
+	return s.recordRepo.List(zoneID, page)
+}
+
+// check 校验记录内容，并检查与同名记录的冲突
+func (s *dnsRecordService) check(zone *model.DNSZone, record *model.DNSRecord) error {
+	if err := validateRecord(record); err != nil {
+		return err
+	}
+
+	siblings, err := s.recordRepo.ListByName(zone.ID, record.Name)
+	if err != nil {
+		return fmt.Errorf("检查记录冲突失败: %w", err)
+	}
+
+	for _, other := range siblings {
+		if other.ID == record.ID {
+			continue
+		}
+		if err := recordConflict(record, other); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateRecord 按记录类型校验记录内容
+func validateRecord(record *model.DNSRecord) error {
+	return validator.ValidateDNSRecord(validator.DNSRecord{
+		Name:     record.Name,
+		Type:     record.Type,
+		Value:    record.Value,
+		TTL:      record.TTL,
+		Priority: record.Priority,
+		Weight:   record.Weight,
+		Port:     record.Port,
+	})
+}
+
+// errDuplicateRecord 相同的记录已存在
+var errDuplicateRecord = errors.New("相同的记录已存在")
+
+// recordConflict 检查两条同名记录是否冲突
+func recordConflict(record, other *model.DNSRecord) error {
+	if other.Type == record.Type && sameRecordValue(record.Type, other.Value, record.Value) &&
+		other.Priority == record.Priority && other.Weight == record.Weight && other.Port == record.Port {
+		return errDuplicateRecord
+	}
+	// CNAME 不能与同名的其他记录共存
+	if record.Type == model.DNSRecordTypeCNAME || other.Type == model.DNSRecordTypeCNAME {
+		return fmt.Errorf("主机记录 %s 已存在 %s 记录，CNAME不能与其他记录共存", record.Name, other.Type)
+	}
+	return nil
+}
+
+// sameRecordValue 比较记录值，主机名忽略大小写和末尾的点
+func sameRecordValue(recordType, a, b string) bool {
+	switch recordType {
+	case model.DNSRecordTypeCNAME, model.DNSRecordTypeNS, model.DNSRecordTypeMX, model.DNSRecordTypeSRV:
+		return strings.EqualFold(strings.TrimSuffix(a, "."), strings.TrimSuffix(b, "."))
+	}
+	return a == b
+}
+
+// applyDNSRecordRequest 将请求规范化后写入记录
+func applyDNSRecordRequest(zone *model.DNSZone, record *model.DNSRecord, req *model.DNSRecordRequest) {
+	record.Name = RelativeRecordName(zone.Name, req.Name)
+	record.Type = strings.ToUpper(req.Type)
+	record.Value = strings.TrimSpace(req.Value)
+	record.TTL = req.TTL
+	if record.TTL == 0 {
+		record.TTL = zone.DefaultTTL
+	}
+	record.Remark = req.Remark
+
+	// 仅 MX/SRV 使用优先级，仅 SRV 使用权重和端口
+	record.Priority, record.Weight, record.Port = 0, 0, 0
+	switch record.Type {
+	case model.DNSRecordTypeMX:
+		record.Priority = req.Priority
+	case model.DNSRecordTypeSRV:
+		record.Priority = req.Priority
+		record.Weight = req.Weight
+		record.Port = req.Port
+	}
+}
+
+// RelativeRecordName 将主机记录转换为相对区域的名称，区域顶点返回 "@"
+func RelativeRecordName(zone, name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	zone = normalizeDomainName(zone)
+
+	if name == "" || name == "@" {
+		return "@"
+	}
+	if strings.HasSuffix(name, ".") {
+		name = strings.TrimSuffix(name, ".")
+		if name == zone {
+			return "@"
+		}
+		if strings.HasSuffix(name, "."+zone) {
+			return strings.TrimSuffix(name, "."+zone)
+		}
+	}
+	return name
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"domain-admin/internal/repository"
+	"domain-admin/model"
+	"domain-admin/pkg/logger"
+	"domain-admin/pkg/pagination"
+	"errors"
+	"fmt"
+)
+
+// DNSZoneService DNS区域服务接口
+type DNSZoneService interface {
+	Create(req *model.DNSZoneCreateRequest) (*model.DNSZone, error)
+	GetByID(id uint) (*model.DNSZone, error)
+	Update(id uint, req *model.DNSZoneUpdateRequest) (*model.DNSZone, error)
+	Delete(id uint) error
+	List(page pagination.Pagination) ([]*model.DNSZone, int64, error)
+}
+
+type dnsZoneService struct {
+	zoneRepo   repository.DNSZoneRepository
+	domainRepo repository.DomainRepository
+}
+
+// NewDNSZoneService 创建DNS区域服务实例
+func NewDNSZoneService(zoneRepo repository.DNSZoneRepository, domainRepo repository.DomainRepository) DNSZoneService {
+	return &dnsZoneService{
+		zoneRepo:   zoneRepo,
+		domainRepo: domainRepo,
+	}
+}
+
+// Create 创建区域
+func (s *dnsZoneService) Create(req *model.DNSZoneCreateRequest) (*model.DNSZone, error) {
+	name := normalizeDomainName(req.Name)
+	if name == "" {
+		return nil, errors.New("区域名称不能为空")
+	}
+
+	// 检查区域是否已存在
+	if _, err := s.zoneRepo.GetByName(name); err == nil {
+		return nil, errors.New("区域已存在")
+	}
+	// 已删除的同名区域仍占用唯一索引，重新创建前彻底清除
+	if err := s.zoneRepo.PurgeDeleted(name); err != nil {
+		logger.Errorf("清除已删除的区域 %s 失败: %v", name, err)
+		return nil, errors.New("创建区域失败")
+	}
+
+	// 检查关联域名是否存在
+	if req.DomainID != 0 {
+		if _, err := s.domainRepo.GetByID(req.DomainID); err != nil {
+			return nil, fmt.Errorf("关联域名不存在: %w", err)
+		}
+	}
+
+	defaultTTL := req.DefaultTTL
+	if defaultTTL == 0 {
+		defaultTTL = 600
+	}
+
+	zone := &model.DNSZone{
+		Name:            name,
+		DomainID:        req.DomainID,
+		ProviderAccount: req.ProviderAccount,
+		DefaultTTL:      defaultTTL,
+		Remark:          req.Remark,
+	}
+
+	if err := s.zoneRepo.Create(zone); err != nil {
+		logger.Errorf("创建区域失败: %v", err)
+		return nil, errors.New("创建区域失败")
+	}
+
+	logger.Infof("创建区域成功: %s", zone.Name)
+	return zone, nil
+}
+
+// GetByID 根据ID获取区域
+func (s *dnsZoneService) GetByID(id uint) (*model.DNSZone, error) {
+	if id == 0 {
+		return nil, errors.New("区域ID不能为空")
+	}
+	return s.zoneRepo.GetByID(id)
+}
+
+// Update 更新区域
+func (s *dnsZoneService) Update(id uint, req *model.DNSZoneUpdateRequest) (*model.DNSZone, error) {
+	if id == 0 {
+		return nil, errors.New("区域ID不能为空")
+	}
+
+	zone, err := s.zoneRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// 更新字段
+	if req.DomainID != nil {
+		if *req.DomainID != 0 {
+			if _, err := s.domainRepo.GetByID(*req.DomainID); err != nil {
+				return nil, fmt.Errorf("关联域名不存在: %w", err)
+			}
+		}
+		zone.DomainID = *req.DomainID
+	}
+	if req.ProviderAccount != nil {
+		zone.ProviderAccount = *req.ProviderAccount
+	}
+	if req.DefaultTTL != 0 {
+		zone.DefaultTTL = req.DefaultTTL
+	}
+	if req.Remark != nil {
+		zone.Remark = *req.Remark
+	}
+
+	if err := s.zoneRepo.Update(zone); err != nil {
+		logger.Errorf("更新区域失败: %v", err)
+		return nil, errors.New("更新区域失败")
+	}
+
+	logger.Infof("更新区域成功: %s", zone.Name)
+	return zone, nil
+}
+
+// Delete 删除区域
+func (s *dnsZoneService) Delete(id uint) error {
+	if id == 0 {
+		return errors.New("区域ID不能为空")
+	}
+
+	// 检查区域是否存在
+	zone, err := s.zoneRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.zoneRepo.Delete(id); err != nil {
+		logger.Errorf("删除区域失败: %v", err)
+		return errors.New("删除区域失败")
+	}
+
+	logger.Infof("删除区域成功: %s", zone.Name)
+	return nil
+}
+
+// List 获取区域列表
+func (s *dnsZoneService) List(page pagination.Pagination) ([]*model.DNSZone, int64, error) {
+	return s.zoneRepo.List(page)
+}
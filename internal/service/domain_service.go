@@ -0,0 +1,174 @@
+package service
+
+import (
+	"domain-admin/internal/repository"
+	"domain-admin/model"
+	"domain-admin/pkg/logger"
+	"domain-admin/pkg/pagination"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DomainService 域名服务接口
+type DomainService interface {
+	Create(req *model.DomainCreateRequest) (*model.Domain, error)
+	GetByID(id uint) (*model.Domain, error)
+	Update(id uint, req *model.DomainUpdateRequest) (*model.Domain, error)
+	Delete(id uint) error
+	List(page pagination.Pagination) ([]*model.Domain, int64, error)
+}
+
+type domainService struct {
+	domainRepo repository.DomainRepository
+	userRepo   repository.UserRepository
+}
+
+// NewDomainService 创建域名服务实例
+func NewDomainService(domainRepo repository.DomainRepository, userRepo repository.UserRepository) DomainService {
+	return &domainService{
+		domainRepo: domainRepo,
+		userRepo:   userRepo,
+	}
+}
+
+// Create 创建域名
+func (s *domainService) Create(req *model.DomainCreateRequest) (*model.Domain, error) {
+	name := normalizeDomainName(req.Name)
+	if name == "" {
+		return nil, errors.New("域名不能为空")
+	}
+
+	// 检查域名是否已存在
+	if _, err := s.domainRepo.GetByName(name); err == nil {
+		return nil, errors.New("域名已存在")
+	}
+	// 已删除的同名域名仍占用唯一索引，重新创建前彻底清除
+	if err := s.domainRepo.PurgeDeleted(name); err != nil {
+		logger.Errorf("清除已删除的域名 %s 失败: %v", name, err)
+		return nil, errors.New("创建域名失败")
+	}
+
+	// 检查负责人是否存在，没有负责人时保存为 NULL 以满足外键约束
+	var ownerID *uint
+	if req.OwnerID != 0 {
+		if _, err := s.userRepo.GetByID(req.OwnerID); err != nil {
+			return nil, fmt.Errorf("负责人不存在: %w", err)
+		}
+		ownerID = &req.OwnerID
+	}
+
+	status := req.Status
+	if status == "" {
+		status = model.DomainStatusActive
+	}
+
+	domain := &model.Domain{
+		Name:            name,
+		Registrar:       req.Registrar,
+		ProviderAccount: req.ProviderAccount,
+		OwnerID:         ownerID,
+		ExpiresAt:       req.ExpiresAt,
+		Status:          status,
+		Tags:            req.Tags,
+		Remark:          req.Remark,
+	}
+
+	if err := s.domainRepo.Create(domain); err != nil {
+		logger.Errorf("创建域名失败: %v", err)
+		return nil, errors.New("创建域名失败")
+	}
+
+	logger.Infof("创建域名成功: %s", domain.Name)
+	return domain, nil
+}
+
+// GetByID 根据ID获取域名
+func (s *domainService) GetByID(id uint) (*model.Domain, error) {
+	if id == 0 {
+		return nil, errors.New("域名ID不能为空")
+	}
+	return s.domainRepo.GetByID(id)
+}
+
+// Update 更新域名
+func (s *domainService) Update(id uint, req *model.DomainUpdateRequest) (*model.Domain, error) {
+	if id == 0 {
+		return nil, errors.New("域名ID不能为空")
+	}
+
+	domain, err := s.domainRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// 更新字段
+	if req.Registrar != nil {
+		domain.Registrar = *req.Registrar
+	}
+	if req.ProviderAccount != nil {
+		domain.ProviderAccount = *req.ProviderAccount
+	}
+	if req.OwnerID != nil {
+		// owner_id 为0时清除负责人
+		domain.OwnerID = nil
+		if *req.OwnerID != 0 {
+			if _, err := s.userRepo.GetByID(*req.OwnerID); err != nil {
+				return nil, fmt.Errorf("负责人不存在: %w", err)
+			}
+			domain.OwnerID = req.OwnerID
+		}
+		domain.Owner = nil
+	}
+	if req.ExpiresAt != nil {
+		domain.ExpiresAt = req.ExpiresAt
+	}
+	if req.Status != "" {
+		domain.Status = req.Status
+	}
+	if req.Tags != nil {
+		domain.Tags = req.Tags
+	}
+	if req.Remark != nil {
+		domain.Remark = *req.Remark
+	}
+
+	if err := s.domainRepo.Update(domain); err != nil {
+		logger.Errorf("更新域名失败: %v", err)
+		return nil, errors.New("更新域名失败")
+	}
+
+	logger.Infof("更新域名成功: %s", domain.Name)
+	return s.domainRepo.GetByID(id)
+}
+
+// Delete 删除域名
+func (s *domainService) Delete(id uint) error {
+	if id == 0 {
+		return errors.New("域名ID不能为空")
+	}
+
+	// 检查域名是否存在
+	domain, err := s.domainRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.domainRepo.Delete(id); err != nil {
+		logger.Errorf("删除域名失败: %v", err)
+		return errors.New("删除域名失败")
+	}
+
+	logger.Infof("删除域名成功: %s", domain.Name)
+	return nil
+}
+
+// List 获取域名列表
+func (s *domainService) List(page pagination.Pagination) ([]*model.Domain, int64, error) {
+	return s.domainRepo.List(page)
+}
+
+// normalizeDomainName 规范化域名（小写、去除首尾空白和末尾的点）
+func normalizeDomainName(name string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+}
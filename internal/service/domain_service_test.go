@@ -0,0 +1,117 @@
+package service
+
+import (
+	"domain-admin/internal/repository"
+	"domain-admin/model"
+	"domain-admin/pkg/pagination"
+	"testing"
+	"time"
+)
+
+func newTestDomainService(t *testing.T) (DomainService, uint) {
+	t.Helper()
+	db := newTestDB(t, &model.User{}, &model.Domain{})
+	owner := &model.User{Username: "alice", Email: "alice@example.com", Password: "x", Role: "user", Status: 1}
+	if err := db.Create(owner).Error; err != nil {
+		t.Fatal(err)
+	}
+	return NewDomainService(repository.NewDomainRepository(db), repository.NewUserRepository(db)), owner.ID
+}
+
+func TestDomainCreate(t *testing.T) {
+	svc, ownerID := newTestDomainService(t)
+
+	// 域名规范化为小写并去除末尾的点，未指定状态时为 active
+	domain, err := svc.Create(&model.DomainCreateRequest{Name: " Example.COM. ", OwnerID: ownerID, Tags: []string{"prod"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if domain.Name != "example.com" || domain.Status != model.DomainStatusActive || domain.OwnerID == nil || *domain.OwnerID != ownerID {
+		t.Fatalf("created domain = %+v", domain)
+	}
+
+	if _, err := svc.Create(&model.DomainCreateRequest{Name: "EXAMPLE.com"}); err == nil {
+		t.Fatal("duplicate domain was created")
+	}
+	if _, err := svc.Create(&model.DomainCreateRequest{Name: "  "}); err == nil {
+		t.Fatal("empty domain was created")
+	}
+	if _, err := svc.Create(&model.DomainCreateRequest{Name: "example.net", OwnerID: 99}); err == nil {
+		t.Fatal("domain with missing owner was created")
+	}
+
+	// 没有负责人的域名保存为 NULL
+	unowned, err := svc.Create(&model.DomainCreateRequest{Name: "example.org", Status: model.DomainStatusPending})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := svc.GetByID(unowned.ID)
+	if err != nil || stored.OwnerID != nil || stored.Owner != nil || stored.Status != model.DomainStatusPending {
+		t.Fatalf("unowned domain = %+v, %v", stored, err)
+	}
+}
+
+func TestDomainUpdate(t *testing.T) {
+	svc, ownerID := newTestDomainService(t)
+	domain, err := svc.Create(&model.DomainCreateRequest{Name: "example.com", Registrar: "godaddy", Tags: []string{"prod"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	registrar := "namecheap"
+	expiresAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	updated, err := svc.Update(domain.ID, &model.DomainUpdateRequest{Registrar: &registrar, OwnerID: &ownerID, ExpiresAt: &expiresAt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 未传入的字段保持不变，返回的域名包含负责人
+	if updated.Registrar != "namecheap" || updated.Owner == nil || updated.Owner.Username != "alice" ||
+		!updated.ExpiresAt.Equal(expiresAt) || len(updated.Tags) != 1 || updated.Tags[0] != "prod" {
+		t.Fatalf("updated domain = %+v", updated)
+	}
+
+	// owner_id 为0时清除负责人
+	none := uint(0)
+	updated, err = svc.Update(domain.ID, &model.DomainUpdateRequest{OwnerID: &none})
+	if err != nil || updated.OwnerID != nil || updated.Owner != nil {
+		t.Fatalf("clear owner = %+v, %v", updated, err)
+	}
+
+	missing := uint(99)
+	if _, err := svc.Update(domain.ID, &model.DomainUpdateRequest{OwnerID: &missing}); err == nil {
+		t.Fatal("domain was assigned to a missing owner")
+	}
+	if _, err := svc.Update(99, &model.DomainUpdateRequest{Registrar: &registrar}); err == nil {
+		t.Fatal("missing domain was updated")
+	}
+}
+
+func TestDomainDeleteAndRecreate(t *testing.T) {
+	svc, _ := newTestDomainService(t)
+	domain, err := svc.Create(&model.DomainCreateRequest{Name: "example.com", Registrar: "godaddy"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Delete(domain.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.GetByID(domain.ID); err == nil {
+		t.Fatal("deleted domain was found")
+	}
+	if err := svc.Delete(domain.ID); err == nil {
+		t.Fatal("deleted domain was deleted again")
+	}
+
+	// 删除后可以重新创建同名域名
+	recreated, err := svc.Create(&model.DomainCreateRequest{Name: "example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recreated.Registrar != "" {
+		t.Fatalf("recreated domain = %+v", recreated)
+	}
+	domains, total, err := svc.List(pagination.Pagination{Limit: 10, OrderBy: "id", Sort: "asc"})
+	if err != nil || total != 1 || len(domains) != 1 || domains[0].ID != recreated.ID {
+		t.Fatalf("List = %d, %v", total, err)
+	}
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"domain-admin/internal/repository"
+	"domain-admin/model"
+	"domain-admin/pkg/logger"
+	"domain-admin/pkg/pagination"
+	"domain-admin/pkg/scheduler"
+	"errors"
+)
+
+// JobService 定时任务管理服务接口
+type JobService interface {
+	List() ([]scheduler.JobInfo, error)
+	Trigger(name string, triggeredBy uint) (*model.JobRun, error)
+	Pause(name string) error
+	Resume(name string) error
+	ListRuns(name string, page pagination.Pagination) ([]*model.JobRun, int64, error)
+}
+
+type jobService struct {
+	jobRepo repository.JobRepository
+}
+
+// NewJobService 创建定时任务管理服务实例
+func NewJobService(jobRepo repository.JobRepository) JobService {
+	return &jobService{jobRepo: jobRepo}
+}
+
+// List 获取全部任务及其调度状态
+func (s *jobService) List() ([]scheduler.JobInfo, error) {
+	sched, err := scheduler.Default()
+	if err != nil {
+		return nil, err
+	}
+	return sched.Jobs(), nil
+}
+
+// Trigger 手动执行任务，任务在后台执行，返回本次执行记录
+func (s *jobService) Trigger(name string, triggeredBy uint) (*model.JobRun, error) {
+	sched, err := scheduler.Default()
+	if err != nil {
+		return nil, err
+	}
+
+	exec, err := sched.Trigger(name, triggeredBy)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof("用户 %d 手动执行任务 %s", triggeredBy, name)
+	return jobRunFromExecution(exec), nil
+}
+
+// Pause 暂停任务
+func (s *jobService) Pause(name string) error {
+	sched, err := scheduler.Default()
+	if err != nil {
+		return err
+	}
+	if err := sched.Pause(name); err != nil {
+		return s.stateError(err)
+	}
+	logger.Infof("任务 %s 已暂停", name)
+	return nil
+}
+
+// Resume 恢复任务
+func (s *jobService) Resume(name string) error {
+	sched, err := scheduler.Default()
+	if err != nil {
+		return err
+	}
+	if err := sched.Resume(name); err != nil {
+		return s.stateError(err)
+	}
+	logger.Infof("任务 %s 已恢复", name)
+	return nil
+}
+
+// ListRuns 获取任务执行记录
+func (s *jobService) ListRuns(name string, page pagination.Pagination) ([]*model.JobRun, int64, error) {
+	return s.jobRepo.ListRuns(name, page)
+}
+
+func (s *jobService) stateError(err error) error {
+	if errors.Is(err, scheduler.ErrJobNotFound) {
+		return err
+	}
+	logger.Errorf("保存任务状态失败: %v", err)
+	return errors.New("保存任务状态失败")
+}
+
+// jobStore 基于数据库的调度器存储
+type jobStore struct {
+	jobRepo repository.JobRepository
+}
+
+// NewJobStore 创建调度器存储，暂停状态和执行记录保存在数据库中
+func NewJobStore(jobRepo repository.JobRepository) scheduler.Store {
+	return &jobStore{jobRepo: jobRepo}
+}
+
+// IsPaused 查询任务是否已暂停
+func (s *jobStore) IsPaused(job string) (bool, error) {
+	return s.jobRepo.IsPaused(job)
+}
+
+// SetPaused 设置任务暂停状态
+func (s *jobStore) SetPaused(job string, paused bool) error {
+	return s.jobRepo.SetPaused(job, paused)
+}
+
+// CreateExecution 保存任务开始执行的记录
+func (s *jobStore) CreateExecution(e *scheduler.Execution) error {
+	run := jobRunFromExecution(e)
+	if err := s.jobRepo.CreateRun(run); err != nil {
+		return err
+	}
+	e.ID = run.ID
+	return nil
+}
+
+// FinishExecution 保存任务执行结果
+func (s *jobStore) FinishExecution(e *scheduler.Execution) error {
+	return s.jobRepo.FinishRun(jobRunFromExecution(e))
+}
+
+func jobRunFromExecution(e *scheduler.Execution) *model.JobRun {
+	run := &model.JobRun{
+		ID:          e.ID,
+		JobName:     e.Job,
+		Trigger:     e.Trigger,
+		TriggeredBy: e.TriggeredBy,
+		Instance:    e.Instance,
+		Status:      e.Status,
+		Error:       e.Error,
+		StartedAt:   e.StartedAt,
+		FinishedAt:  e.FinishedAt,
+		CreatedAt:   e.StartedAt,
+		UpdatedAt:   e.StartedAt,
+	}
+	if e.FinishedAt != nil {
+		run.Duration = e.FinishedAt.Sub(e.StartedAt).Milliseconds()
+	}
+	return run
+}
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"context"
+	"domain-admin/internal/repository"
+	"domain-admin/model"
+	"domain-admin/pkg/config"
+	"domain-admin/pkg/jwt"
+	"domain-admin/pkg/logger"
+	"domain-admin/pkg/vault"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// JWTKeyService JWT签名密钥服务接口
+type JWTKeyService interface {
+	List() ([]*model.JWTKey, error)
+	Rotate(req *model.JWTKeyRotateRequest) (*model.JWTKey, error)
+	Retire(id uint) error
+	Load() error
+	Watch(ctx context.Context, interval time.Duration)
+	RotateVaultKey(ctx context.Context) (*model.VaultRotateItem, error)
+}
+
+// jwtKeyMissReloadInterval 验证令牌遇到未知 kid 时两次重新加载密钥的最小间隔
+const jwtKeyMissReloadInterval = 5 * time.Second
+
+type jwtKeyService struct {
+	keyRepo repository.JWTKeyRepository
+	cfg     config.JWTConfig
+	keys    *jwt.Keyring
+	keyring *vault.Keyring
+	keyErr  error
+}
+
+// NewJWTKeyService 创建JWT签名密钥服务实例，数据库中的私钥使用 vault 主密钥加密，加载的密钥用于签发和验证访问令牌
+func NewJWTKeyService(keyRepo repository.JWTKeyRepository, cfg config.JWTConfig, vaultCfg config.VaultConfig) JWTKeyService {
+	s := &jwtKeyService{keyRepo: keyRepo, cfg: cfg, keys: jwt.Default()}
+	s.keyring, s.keyErr = vault.Load(vaultCfg.KeyEnv, vaultCfg.KeyFile)
+	return s
+}
+
+// List 获取数据库中的全部密钥，并标记当前签名密钥
+func (s *jwtKeyService) List() ([]*model.JWTKey, error) {
+	keys, err := s.keyRepo.List()
+	if err != nil {
+		return nil, err
+	}
+	signing := s.keys.SigningKeyID()
+	for _, key := range keys {
+		key.Signing = key.Kid == signing
+	}
+	return keys, nil
+}
+
+// Rotate 生成新的签名密钥并立即用于签名，旧密钥继续用于验证直到被停用
+func (s *jwtKeyService) Rotate(req *model.JWTKeyRotateRequest) (*model.JWTKey, error) {
+	if s.cfg.SigningKey != "" {
+		return nil, errors.New("配置文件已指定 jwt.signing_key，不能通过接口轮换签名密钥")
+	}
+	if s.keyErr != nil {
+		return nil, s.keyError()
+	}
+
+	algorithm := req.Algorithm
+	if algorithm == "" {
+		algorithm = jwt.AlgES256
+	}
+	suffix, err := randomHex(4)
+	if err != nil {
+		return nil, errors.New("生成签名密钥失败")
+	}
+	kid := time.Now().Format("20060102") + "-" + suffix
+
+	key, err := jwt.GenerateKey(kid, algorithm)
+	if err != nil {
+		logger.Errorf("生成签名密钥失败: %v", err)
+		return nil, errors.New("生成签名密钥失败")
+	}
+	privatePEM, err := key.PrivatePEM()
+	if err != nil {
+		return nil, errors.New("生成签名密钥失败")
+	}
+	publicPEM, err := key.PublicPEM()
+	if err != nil {
+		return nil, errors.New("生成签名密钥失败")
+	}
+	sealed, err := s.keyring.Seal(privatePEM)
+	if err != nil {
+		logger.Errorf("加密签名密钥失败: %v", err)
+		return nil, errors.New("加密签名密钥失败")
+	}
+
+	record := &model.JWTKey{
+		Kid:               kid,
+		Algorithm:         algorithm,
+		PublicKey:         string(publicPEM),
+		PrivateCiphertext: sealed.Ciphertext,
+		PrivateDataKey:    sealed.DataKey,
+		PrivateKeyID:      sealed.KeyID,
+	}
+	if err := s.keyRepo.Create(record); err != nil {
+		logger.Errorf("保存签名密钥失败: %v", err)
+		return nil, errors.New("保存签名密钥失败")
+	}
+	if err := s.Load(); err != nil {
+		return nil, err
+	}
+
+	logger.Infof("JWT签名密钥已轮换为 %s (%s)", kid, algorithm)
+	record.Signing = s.keys.SigningKeyID() == kid
+	return record, nil
+}
+
+// Retire 停用密钥，停用后该密钥签发的令牌在当前实例立即失效，其他实例在下次重新加载密钥后失效
+func (s *jwtKeyService) Retire(id uint) error {
+	key, err := s.keyRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if key.RetiredAt != nil {
+		return nil
+	}
+	if key.Kid == s.keys.SigningKeyID() {
+		return errors.New("不能停用当前的签名密钥，请先轮换签名密钥")
+	}
+
+	if err := s.keyRepo.Retire(id, time.Now()); err != nil {
+		logger.Errorf("停用签名密钥失败: %v", err)
+		return errors.New("停用签名密钥失败")
+	}
+	logger.Infof("JWT签名密钥 %s 已停用", key.Kid)
+	return s.Load()
+}
+
+// Load 加载配置文件和数据库中的密钥并设置签名密钥。
+// 未指定 jwt.signing_key 时使用数据库中最新的可签名密钥，都没有时使用 HS256
+func (s *jwtKeyService) Load() error {
+	keys := make([]*jwt.Key, 0, len(s.cfg.Keys))
+	for _, kc := range s.cfg.Keys {
+		key, err := loadKeyFile(kc)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, key)
+	}
+
+	records, err := s.keyRepo.ListActive()
+	if err != nil {
+		return fmt.Errorf("读取签名密钥失败: %w", err)
+	}
+	signing := s.cfg.SigningKey
+	for _, record := range records {
+		key, err := s.openKey(record)
+		if err != nil {
+			return err
+		}
+		if signing == "" && key.CanSign() {
+			signing = key.ID
+		}
+		keys = append(keys, key)
+	}
+
+	if err := s.keys.SetKeys(keys, signing); err != nil {
+		return fmt.Errorf("设置JWT签名密钥失败: %w", err)
+	}
+	return nil
+}
+
+// Watch 每隔 interval 从数据库重新加载密钥，并在验证令牌遇到未知 kid 时立即重新加载，
+// 使其他实例轮换的新密钥和停用的旧密钥在当前实例生效；ctx 取消后停止
+func (s *jwtKeyService) Watch(ctx context.Context, interval time.Duration) {
+	s.keys.SetReloader(s.Load, jwtKeyMissReloadInterval)
+	defer s.keys.SetReloader(nil, 0)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Load(); err != nil {
+				logger.Errorf("重新加载JWT签名密钥失败: %v", err)
+			}
+		}
+	}
+}
+
+// openKey 解密数据库中的私钥，主密钥不可用时只加载公钥用于验证
+func (s *jwtKeyService) openKey(record *model.JWTKey) (*jwt.Key, error) {
+	if s.keyErr == nil && record.PrivateCiphertext != "" {
+		privatePEM, err := s.keyring.Open(&vault.Sealed{
+			KeyID:      record.PrivateKeyID,
+			DataKey:    record.PrivateDataKey,
+			Ciphertext: record.PrivateCiphertext,
+		})
+		if err == nil {
+			return jwt.ParseKeyPEM(record.Kid, record.Algorithm, privatePEM)
+		}
+		logger.Warnf("解密签名密钥 %s 失败，只用于验证: %v", record.Kid, err)
+	}
+	return jwt.ParseKeyPEM(record.Kid, record.Algorithm, []byte(record.PublicKey))
+}
+
+// RotateVaultKey 使用当前主密钥重新加密由其他主密钥加密的签名私钥
+func (s *jwtKeyService) RotateVaultKey(ctx context.Context) (*model.VaultRotateItem, error) {
+	item := &model.VaultRotateItem{Name: "jwt_keys"}
+	keys, err := s.keyRepo.List()
+	if err != nil {
+		return item, err
+	}
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return item, err
+		}
+		if key.PrivateCiphertext == "" {
+			continue
+		}
+		if s.keyErr != nil {
+			return item, s.keyError()
+		}
+		sealed := &vault.Sealed{KeyID: key.PrivateKeyID, DataKey: key.PrivateDataKey, Ciphertext: key.PrivateCiphertext}
+		rotateSealed(s.keyring, item, "签名密钥 "+key.Kid, sealed, func(rotated *vault.Sealed) error {
+			key.PrivateCiphertext = rotated.Ciphertext
+			key.PrivateDataKey = rotated.DataKey
+			key.PrivateKeyID = rotated.KeyID
+			return s.keyRepo.UpdatePrivateKey(key)
+		})
+	}
+	return item, nil
+}
+
+// keyError 返回主密钥不可用的错误信息
+func (s *jwtKeyService) keyError() error {
+	if errors.Is(s.keyErr, vault.ErrNoKey) {
+		return errors.New("未配置签名密钥的加密主密钥")
+	}
+	return fmt.Errorf("加密主密钥无效: %w", s.keyErr)
+}
+
+// loadKeyFile 读取配置文件中的密钥，优先读取私钥
+func loadKeyFile(kc config.JWTKeyConfig) (*jwt.Key, error) {
+	file := kc.PrivateKeyFile
+	if file == "" {
+		file = kc.PublicKeyFile
+	}
+	if file == "" {
+		return nil, fmt.Errorf("签名密钥 %s 未配置密钥文件", kc.Kid)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("读取签名密钥 %s 失败: %w", kc.Kid, err)
+	}
+	return jwt.ParseKeyPEM(kc.Kid, kc.Algorithm, data)
+}
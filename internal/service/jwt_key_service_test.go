@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"domain-admin/internal/repository"
+	"domain-admin/model"
+	"domain-admin/pkg/config"
+	"domain-admin/pkg/jwt"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// newTestJWTKeyService 创建使用独立密钥环的签名密钥服务，模拟一个实例
+func newTestJWTKeyService(t *testing.T, db *gorm.DB, cfg config.VaultConfig) *jwtKeyService {
+	t.Helper()
+	s := NewJWTKeyService(repository.NewJWTKeyRepository(db), config.JWTConfig{}, cfg).(*jwtKeyService)
+	s.keys = jwt.NewKeyring()
+	if err := s.Load(); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestJWTKeysSharedBetweenInstances(t *testing.T) {
+	db := newTestDB(t, &model.JWTKey{})
+	cfg := config.VaultConfig{KeyEnv: "JWT_KEY_TEST_VAULT_KEY"}
+	t.Setenv(cfg.KeyEnv, newTestVaultKey(t))
+	a := newTestJWTKeyService(t, db, cfg)
+	b := newTestJWTKeyService(t, db, cfg)
+
+	first, err := a.Rotate(&model.JWTKeyRotateRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := a.keys.GenerateToken(1, "admin", "admin", "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// b 遇到未知 kid 时从数据库重新加载，随后也使用新密钥签名
+	b.keys.SetReloader(b.Load, time.Hour)
+	if claims, err := b.keys.ParseToken(token); err != nil || claims.UserID != 1 {
+		t.Fatalf("token signed on a rejected by b: %v", err)
+	}
+	if b.keys.SigningKeyID() != first.Kid {
+		t.Fatalf("b signs with %q, want %s", b.keys.SigningKeyID(), first.Kid)
+	}
+
+	// a 轮换并停用旧密钥后，b 在下次定期加载后拒绝旧密钥签发的令牌
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go b.Watch(ctx, 20*time.Millisecond)
+
+	second, err := a.Rotate(&model.JWTKeyRotateRequest{Algorithm: jwt.AlgEdDSA})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Retire(first.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.keys.ParseToken(token); err == nil {
+		t.Fatal("a accepted a token signed by a retired key")
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		_, err := b.keys.ParseToken(token)
+		if err != nil && b.keys.SigningKeyID() == second.Kid {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("b still accepts the retired key (signing %s): %v", b.keys.SigningKeyID(), err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,292 @@
+package service
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"domain-admin/internal/repository"
+	"domain-admin/model"
+	"domain-admin/pkg/cache"
+	"domain-admin/pkg/config"
+	"domain-admin/pkg/ldapauth"
+	"domain-admin/pkg/logger"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// ldapProvider 目录服务用户在外部账号关联中的身份提供方名称
+const ldapProvider = "ldap"
+
+// ErrNotExternalUser 用户不由外部认证器管理，应使用本地密码校验
+var ErrNotExternalUser = errors.New("用户不由外部认证器管理")
+
+// PasswordAuthenticator 外部密码认证器，用户登录时优先委托给它校验用户名和密码
+type PasswordAuthenticator interface {
+	// Authenticate 校验成功时返回本地用户；返回 ErrNotExternalUser 时改用本地密码校验
+	Authenticate(username, password string) (*model.User, error)
+}
+
+// LDAPSyncResult 目录同步结果
+type LDAPSyncResult struct {
+	Synced          int    `json:"synced"`
+	Disabled        int    `json:"disabled"`
+	DisabledUserIDs []uint `json:"disabled_user_ids"`
+}
+
+// LDAPService 目录服务认证和同步接口
+type LDAPService interface {
+	PasswordAuthenticator
+	Enabled() bool
+	Sync(ctx context.Context) (*LDAPSyncResult, error)
+}
+
+type ldapService struct {
+	identityRepo repository.UserIdentityRepository
+	userRepo     repository.UserRepository
+	roleRepo     repository.RoleRepository
+	cfg          config.LDAPConfig
+	client       *ldapauth.Client
+	initErr      error
+}
+
+// NewLDAPService 创建目录服务实例，未配置 url 时不启用
+func NewLDAPService(identityRepo repository.UserIdentityRepository, userRepo repository.UserRepository, roleRepo repository.RoleRepository, cfg config.LDAPConfig) LDAPService {
+	s := &ldapService{identityRepo: identityRepo, userRepo: userRepo, roleRepo: roleRepo, cfg: cfg}
+	if !cfg.Enabled() {
+		return s
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: cfg.InsecureSkipVerify}
+	if cfg.CACertFile != "" {
+		pem, err := os.ReadFile(cfg.CACertFile)
+		if err != nil {
+			s.initErr = fmt.Errorf("读取目录服务CA证书失败: %w", err)
+			return s
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			s.initErr = errors.New("目录服务CA证书格式错误")
+			return s
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if cfg.BaseDN == "" {
+		s.initErr = errors.New("未配置目录服务的 base_dn")
+		return s
+	}
+
+	s.client = ldapauth.New(ldapauth.Config{
+		URL:                cfg.URL,
+		StartTLS:           cfg.StartTLS,
+		TLSConfig:          tlsConfig,
+		BindDN:             cfg.BindDN,
+		BindPassword:       cfg.BindPassword,
+		BaseDN:             cfg.BaseDN,
+		UserFilter:         cfg.UserFilter,
+		UsernameAttribute:  cfg.UsernameAttribute,
+		EmailAttribute:     cfg.EmailAttribute,
+		NameAttribute:      cfg.NameAttribute,
+		GroupBaseDN:        cfg.GroupBaseDN,
+		GroupFilter:        cfg.GroupFilter,
+		GroupNameAttribute: cfg.GroupNameAttribute,
+		Timeout:            cfg.RequestTimeout(),
+	})
+	return s
+}
+
+// Enabled 是否启用目录服务认证
+func (s *ldapService) Enabled() bool {
+	return s.cfg.Enabled()
+}
+
+// Authenticate 使用目录服务校验密码。本地已有且未关联目录的用户（如初始管理员）只使用本地密码，
+// 避免目录中的同名账号接管本地账号
+func (s *ldapService) Authenticate(username, password string) (*model.User, error) {
+	if !s.cfg.Enabled() {
+		return nil, ErrNotExternalUser
+	}
+	local, localErr := s.userRepo.GetByUsername(username)
+	if localErr == nil {
+		if _, err := s.identityRepo.GetByUser(ldapProvider, local.ID); err != nil {
+			return nil, ErrNotExternalUser
+		}
+	}
+	if s.initErr != nil {
+		logger.Errorf("目录服务配置错误: %v", s.initErr)
+		return nil, errors.New("目录服务配置错误，请联系管理员")
+	}
+
+	entry, err := s.client.Authenticate(username, password)
+	switch {
+	case err == nil:
+	case errors.Is(err, ldapauth.ErrUserNotFound) && localErr != nil:
+		return nil, ErrNotExternalUser
+	case errors.Is(err, ldapauth.ErrUserNotFound), errors.Is(err, ldapauth.ErrInvalidCredentials):
+		return nil, errors.New("用户名或密码错误")
+	default:
+		logger.Errorf("目录服务认证失败: %v", err)
+		return nil, errors.New("目录服务暂时不可用，请稍后重试")
+	}
+
+	now := time.Now()
+	return s.syncUser(entry, &now)
+}
+
+// Sync 按目录同步已关联用户的邮箱、昵称和角色，并禁用已从目录中删除的用户。
+// 目录中尚未登录过的用户不会被创建，首次登录时再创建
+func (s *ldapService) Sync(ctx context.Context) (*LDAPSyncResult, error) {
+	if !s.cfg.Enabled() {
+		return nil, errors.New("未启用目录服务认证")
+	}
+	if s.initErr != nil {
+		return nil, s.initErr
+	}
+	entries, err := s.client.Users()
+	if err != nil {
+		return nil, fmt.Errorf("查询目录用户失败: %w", err)
+	}
+	// 查询条件或权限配置错误时目录可能返回空结果，此时不能禁用全部用户
+	if len(entries) == 0 {
+		return nil, errors.New("目录服务未返回任何用户，已跳过同步")
+	}
+	directory := make(map[string]*ldapauth.Entry, len(entries))
+	for _, entry := range entries {
+		directory[strings.ToLower(entry.Username)] = entry
+	}
+
+	identities, err := s.identityRepo.ListByProvider(ldapProvider)
+	if err != nil {
+		return nil, fmt.Errorf("查询目录用户关联失败: %w", err)
+	}
+	result := &LDAPSyncResult{}
+	for _, identity := range identities {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		if entry, ok := directory[identity.Subject]; ok {
+			if _, err := s.syncUser(entry, nil); err != nil {
+				logger.Warnf("同步目录用户 %s 失败: %v", identity.Subject, err)
+				continue
+			}
+			result.Synced++
+			continue
+		}
+
+		user, err := s.userRepo.GetByID(identity.UserID)
+		if err != nil || user.Status == 0 {
+			continue
+		}
+		if err := s.userRepo.UpdateStatus(user.ID, 0); err != nil {
+			return result, fmt.Errorf("禁用用户 %s 失败: %w", user.Username, err)
+		}
+		if err := cache.DelUserCache(ctx, user.ID); err != nil {
+			logger.Warnf("删除用户缓存失败: %v", err)
+		}
+		logger.Warnf("用户 %s 已从目录中删除，已禁用", user.Username)
+		result.Disabled++
+		result.DisabledUserIDs = append(result.DisabledUserIDs, user.ID)
+	}
+	if result.Disabled > 0 {
+		if err := cache.DelUserListCache(ctx, "*"); err != nil {
+			logger.Warnf("清除用户列表缓存失败: %v", err)
+		}
+	}
+	return result, nil
+}
+
+// syncUser 按目录条目创建或更新本地用户和关联，loginAt 不为空时记录为登录
+func (s *ldapService) syncUser(entry *ldapauth.Entry, loginAt *time.Time) (*model.User, error) {
+	role, err := mapGroupRole(s.roleRepo, "目录服务", s.cfg.RoleMappings, s.cfg.Role(), entry.Groups)
+	if err != nil {
+		return nil, err
+	}
+
+	subject := strings.ToLower(entry.Username)
+	identity, err := s.identityRepo.GetIdentity(ldapProvider, subject)
+	var user *model.User
+	if err == nil {
+		if user, err = s.userRepo.GetByID(identity.UserID); err != nil {
+			return nil, errors.New("关联的用户不存在")
+		}
+		if err := s.updateUser(user, entry, role); err != nil {
+			return nil, err
+		}
+	} else {
+		if user, err = s.provision(entry, role); err != nil {
+			return nil, err
+		}
+		identity = &model.UserIdentity{UserID: user.ID, Provider: ldapProvider, Subject: subject}
+	}
+
+	identity.Email = entry.Email
+	identity.Groups = entry.Groups
+	if loginAt != nil {
+		identity.LastLoginAt = loginAt
+	}
+	if err := s.identityRepo.SaveIdentity(identity); err != nil {
+		logger.Errorf("保存目录用户关联失败: %v", err)
+		return nil, errors.New("保存外部账号关联失败")
+	}
+	return user, nil
+}
+
+// provision 首次登录时创建目录用户，用户名或邮箱已被本地账号使用时拒绝
+func (s *ldapService) provision(entry *ldapauth.Entry, role string) (*model.User, error) {
+	if entry.Email == "" {
+		return nil, errors.New("目录中的用户没有邮箱，无法创建用户")
+	}
+	if _, err := s.userRepo.GetByUsername(entry.Username); err == nil {
+		return nil, errors.New("用户名已被本地账号使用")
+	}
+	if _, err := s.userRepo.GetByEmail(entry.Email); err == nil {
+		return nil, errors.New("邮箱已被其他账号使用")
+	}
+
+	user := &model.User{
+		Username: entry.Username,
+		Email:    entry.Email,
+		Nickname: truncateRunes(entry.Name, 50),
+		Role:     role,
+	}
+	if err := createExternalUser(s.userRepo, user); err != nil {
+		return nil, err
+	}
+	logger.Infof("通过目录服务创建用户 %s，角色 %s", user.Username, role)
+	return user, nil
+}
+
+// updateUser 按目录更新邮箱和昵称，配置了用户组映射时同步角色
+func (s *ldapService) updateUser(user *model.User, entry *ldapauth.Entry, role string) error {
+	changed := false
+	if entry.Email != "" && entry.Email != user.Email {
+		if other, err := s.userRepo.GetByEmail(entry.Email); err == nil && other.ID != user.ID {
+			logger.Warnf("目录用户 %s 的邮箱 %s 已被其他账号使用，未同步", entry.Username, entry.Email)
+		} else {
+			user.Email = entry.Email
+			changed = true
+		}
+	}
+	if name := truncateRunes(entry.Name, 50); name != "" && name != user.Nickname {
+		user.Nickname = name
+		changed = true
+	}
+	if len(s.cfg.RoleMappings) > 0 && user.Role != role {
+		logger.Infof("用户 %s 的角色按目录用户组从 %s 同步为 %s", user.Username, user.Role, role)
+		user.Role = role
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	if err := s.userRepo.Update(user); err != nil {
+		logger.Errorf("同步目录用户失败: %v", err)
+		return errors.New("同步目录用户失败")
+	}
+	if err := cache.DelUserCache(context.Background(), user.ID); err != nil {
+		logger.Warnf("删除用户缓存失败: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"domain-admin/pkg/logger"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+func TestMain(m *testing.M) {
+	logger.Log = zap.NewNop()
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
+
+// newTestDB 创建临时 SQLite 数据库并迁移 models，表名使用仓储中直接引用的 domain_ 前缀和单数形式
+func newTestDB(t *testing.T, models ...any) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger:         gormlogger.Discard,
+		NamingStrategy: schema.NamingStrategy{TablePrefix: "domain_", SingularTable: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(models...); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
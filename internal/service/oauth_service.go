@@ -0,0 +1,673 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"domain-admin/internal/repository"
+	"domain-admin/model"
+	"domain-admin/pkg/config"
+	"domain-admin/pkg/jwt"
+	"domain-admin/pkg/logger"
+	"domain-admin/pkg/oidc"
+	"domain-admin/pkg/utils"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// oauthCodeTTL 授权码有效期
+	oauthCodeTTL = time.Minute
+	// 访问令牌和客户端密钥前缀，便于识别泄露的凭据
+	oauthAccessTokenPrefix  = "oat_"
+	oauthClientSecretPrefix = "ocs_"
+)
+
+// ErrOAuthDisabled 未配置签发方地址时不提供OAuth2授权服务
+var ErrOAuthDisabled = errors.New("OAuth2授权服务未启用")
+
+// OAuthError OAuth2 协议错误，Code 为 RFC 6749 定义的错误码，Status 为令牌端点返回的HTTP状态码
+type OAuthError struct {
+	Code        string
+	Description string
+	Status      int
+}
+
+func (e *OAuthError) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+func oauthError(code, description string) *OAuthError {
+	status := http.StatusBadRequest
+	if code == "invalid_client" {
+		status = http.StatusUnauthorized
+	}
+	return &OAuthError{Code: code, Description: description, Status: status}
+}
+
+// OAuthService OAuth2授权服务接口
+type OAuthService interface {
+	ListClients() ([]*model.OAuthClient, error)
+	CreateClient(userID uint, req *model.OAuthClientRequest) (*model.OAuthClientSecretResult, error)
+	UpdateClient(id uint, req *model.OAuthClientRequest) (*model.OAuthClient, error)
+	DeleteClient(id uint) error
+	ResetClientSecret(id uint) (*model.OAuthClientSecretResult, error)
+
+	Discovery() (map[string]interface{}, error)
+	AuthorizeRedirect(req *model.OAuthAuthorizeRequest, query string) (string, error)
+	AuthorizeInfo(req *model.OAuthAuthorizeRequest) (*model.OAuthAuthorizeInfo, error)
+	Authorize(userID uint, req *model.OAuthAuthorizeRequest) (*model.OAuthAuthorizeResult, error)
+	Token(clientID, clientSecret string, form url.Values) (*model.OAuthTokenResponse, error)
+	Introspect(clientID, clientSecret, token string) (*model.OAuthIntrospection, error)
+	Revoke(clientID, clientSecret, token string) error
+	UserInfo(token string) (map[string]interface{}, error)
+}
+
+type oauthService struct {
+	oauthRepo repository.OAuthRepository
+	userRepo  repository.UserRepository
+	roleRepo  repository.RoleRepository
+	cfg       config.OAuthConfig
+}
+
+// NewOAuthService 创建OAuth2授权服务实例
+func NewOAuthService(oauthRepo repository.OAuthRepository, userRepo repository.UserRepository, roleRepo repository.RoleRepository, cfg config.OAuthConfig) OAuthService {
+	return &oauthService{oauthRepo: oauthRepo, userRepo: userRepo, roleRepo: roleRepo, cfg: cfg}
+}
+
+// ListClients 获取应用列表
+func (s *oauthService) ListClients() ([]*model.OAuthClient, error) {
+	return s.oauthRepo.ListClients()
+}
+
+// CreateClient 注册应用，机密客户端的密钥只在结果中返回一次
+func (s *oauthService) CreateClient(userID uint, req *model.OAuthClientRequest) (*model.OAuthClientSecretResult, error) {
+	clientID, err := randomHex(16)
+	if err != nil {
+		return nil, errors.New("生成客户端ID失败")
+	}
+	client := &model.OAuthClient{ClientID: clientID, Status: 1, CreatedBy: userID}
+	if err := s.applyClientRequest(client, req); err != nil {
+		return nil, err
+	}
+
+	result := &model.OAuthClientSecretResult{OAuthClient: client}
+	if !client.Public {
+		if result.ClientSecret, err = newOAuthSecret(oauthClientSecretPrefix); err != nil {
+			return nil, errors.New("生成客户端密钥失败")
+		}
+		client.SecretHash = utils.SHA256(result.ClientSecret)
+	}
+	if err := s.oauthRepo.CreateClient(client); err != nil {
+		logger.Errorf("创建应用失败: %v", err)
+		return nil, errors.New("创建应用失败")
+	}
+	logger.Infof("用户 %d 注册应用 %s (%s)", userID, client.Name, client.ClientID)
+	return result, nil
+}
+
+// UpdateClient 更新应用，不能在公开客户端和机密客户端之间切换
+func (s *oauthService) UpdateClient(id uint, req *model.OAuthClientRequest) (*model.OAuthClient, error) {
+	client, err := s.oauthRepo.GetClientByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Public != client.Public {
+		return nil, errors.New("不能修改应用的客户端类型，请重新注册应用")
+	}
+	if err := s.applyClientRequest(client, req); err != nil {
+		return nil, err
+	}
+	if err := s.oauthRepo.UpdateClient(client); err != nil {
+		logger.Errorf("更新应用失败: %v", err)
+		return nil, errors.New("更新应用失败")
+	}
+	return client, nil
+}
+
+// DeleteClient 删除应用，已颁发的访问令牌同时吊销
+func (s *oauthService) DeleteClient(id uint) error {
+	client, err := s.oauthRepo.GetClientByID(id)
+	if err != nil {
+		return err
+	}
+	if err := s.oauthRepo.DeleteClient(client, time.Now()); err != nil {
+		logger.Errorf("删除应用失败: %v", err)
+		return errors.New("删除应用失败")
+	}
+	logger.Infof("应用 %s (%s) 已删除", client.Name, client.ClientID)
+	return nil
+}
+
+// ResetClientSecret 重置机密客户端的密钥，旧密钥立即失效
+func (s *oauthService) ResetClientSecret(id uint) (*model.OAuthClientSecretResult, error) {
+	client, err := s.oauthRepo.GetClientByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if client.Public {
+		return nil, errors.New("公开客户端没有密钥")
+	}
+	secret, err := newOAuthSecret(oauthClientSecretPrefix)
+	if err != nil {
+		return nil, errors.New("生成客户端密钥失败")
+	}
+	client.SecretHash = utils.SHA256(secret)
+	if err := s.oauthRepo.UpdateClient(client); err != nil {
+		logger.Errorf("重置应用密钥失败: %v", err)
+		return nil, errors.New("重置应用密钥失败")
+	}
+	logger.Infof("应用 %s (%s) 的密钥已重置", client.Name, client.ClientID)
+	return &model.OAuthClientSecretResult{OAuthClient: client, ClientSecret: secret}, nil
+}
+
+// applyClientRequest 校验并写入应用配置
+func (s *oauthService) applyClientRequest(client *model.OAuthClient, req *model.OAuthClientRequest) error {
+	grants := uniqueStrings(req.GrantTypes)
+	if containsString(grants, model.OAuthGrantAuthorizationCode) && len(req.RedirectURIs) == 0 {
+		return errors.New("授权码模式至少需要一个回调地址")
+	}
+	if containsString(grants, model.OAuthGrantClientCredentials) {
+		if req.Public {
+			return errors.New("公开客户端不能使用客户端凭据模式")
+		}
+		if req.Role == "" {
+			return errors.New("客户端凭据模式需要指定角色")
+		}
+	}
+	for _, uri := range req.RedirectURIs {
+		u, err := url.Parse(uri)
+		if err != nil || u.Fragment != "" || u.Host == "" {
+			return fmt.Errorf("回调地址 %s 无效", uri)
+		}
+		// 只允许 https，本机调试地址除外
+		if u.Scheme != "https" && !(u.Scheme == "http" && isLoopbackHost(u.Hostname())) {
+			return fmt.Errorf("回调地址 %s 必须使用 https", uri)
+		}
+	}
+	if req.Role != "" {
+		if _, err := s.roleRepo.GetByName(req.Role); err != nil {
+			return fmt.Errorf("角色 %s 不存在", req.Role)
+		}
+	}
+
+	client.Name = strings.TrimSpace(req.Name)
+	client.Public = req.Public
+	client.RedirectURIs = uniqueStrings(req.RedirectURIs)
+	client.GrantTypes = grants
+	client.Scopes = uniqueStrings(req.Scopes)
+	client.Role = req.Role
+	if req.Status != nil {
+		client.Status = *req.Status
+	}
+	return nil
+}
+
+// Discovery 返回OpenID Connect发现文档
+func (s *oauthService) Discovery() (map[string]interface{}, error) {
+	if !s.cfg.Enabled() {
+		return nil, ErrOAuthDisabled
+	}
+	issuer := s.cfg.IssuerURL()
+	algs := []string{}
+	signing := jwt.SigningKeyID()
+	for _, key := range jwt.JWKS().Keys {
+		if key.Kid == signing {
+			algs = append(algs, key.Alg)
+		}
+	}
+	return map[string]interface{}{
+		"issuer":                                issuer,
+		"authorization_endpoint":                issuer + "/oauth/authorize",
+		"token_endpoint":                        issuer + "/oauth/token",
+		"userinfo_endpoint":                     issuer + "/oauth/userinfo",
+		"introspection_endpoint":                issuer + "/oauth/introspect",
+		"revocation_endpoint":                   issuer + "/oauth/revoke",
+		"jwks_uri":                              issuer + "/.well-known/jwks.json",
+		"response_types_supported":              []string{"code"},
+		"grant_types_supported":                 []string{model.OAuthGrantAuthorizationCode, model.OAuthGrantClientCredentials},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": algs,
+		"scopes_supported":                      []string{model.OAuthScopeOpenID, model.OAuthScopeProfile, model.OAuthScopeEmail, model.OAuthScopeRoles},
+		"token_endpoint_auth_methods_supported": []string{"client_secret_basic", "client_secret_post", "none"},
+		"code_challenge_methods_supported":      []string{"S256"},
+		"claims_supported":                      []string{"sub", "iss", "aud", "exp", "iat", "nonce", "name", "preferred_username", "email", "roles", "permissions"},
+	}, nil
+}
+
+// AuthorizeRedirect 校验授权请求，返回前端授权页地址；应用可识别但参数错误时返回带错误的回调地址。
+// 返回 error 时应用或回调地址无效，不能重定向
+func (s *oauthService) AuthorizeRedirect(req *model.OAuthAuthorizeRequest, query string) (string, error) {
+	if _, err := s.validateAuthorize(req); err != nil {
+		var oerr *OAuthError
+		if errors.As(err, &oerr) {
+			return errorRedirect(req, oerr), nil
+		}
+		return "", err
+	}
+	consent := s.cfg.Consent()
+	sep := "?"
+	if strings.Contains(consent, "?") {
+		sep = "&"
+	}
+	return consent + sep + query, nil
+}
+
+// AuthorizeInfo 返回授权页展示的应用和作用域
+func (s *oauthService) AuthorizeInfo(req *model.OAuthAuthorizeRequest) (*model.OAuthAuthorizeInfo, error) {
+	client, err := s.validateAuthorize(req)
+	if err != nil {
+		return nil, err
+	}
+	return &model.OAuthAuthorizeInfo{ClientID: client.ClientID, ClientName: client.Name, Scopes: splitScope(req.Scope)}, nil
+}
+
+// Authorize 用户同意或拒绝授权，同意时签发授权码
+func (s *oauthService) Authorize(userID uint, req *model.OAuthAuthorizeRequest) (*model.OAuthAuthorizeResult, error) {
+	client, err := s.validateAuthorize(req)
+	if err != nil {
+		var oerr *OAuthError
+		if errors.As(err, &oerr) {
+			return &model.OAuthAuthorizeResult{RedirectTo: errorRedirect(req, oerr)}, nil
+		}
+		return nil, err
+	}
+	if !req.Approve {
+		return &model.OAuthAuthorizeResult{RedirectTo: errorRedirect(req, oauthError("access_denied", "用户拒绝授权"))}, nil
+	}
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil || user.Status != 1 {
+		return nil, errors.New("用户不存在或已被禁用")
+	}
+
+	code, err := newOAuthSecret("")
+	if err != nil {
+		return nil, errors.New("生成授权码失败")
+	}
+	record := &model.OAuthAuthorizationCode{
+		CodeHash:      utils.SHA256(code),
+		ClientID:      client.ClientID,
+		UserID:        user.ID,
+		RedirectURI:   req.RedirectURI,
+		Scope:         strings.Join(splitScope(req.Scope), " "),
+		Nonce:         req.Nonce,
+		CodeChallenge: req.CodeChallenge,
+		ExpiresAt:     time.Now().Add(oauthCodeTTL),
+	}
+	if err := s.oauthRepo.CreateCode(record); err != nil {
+		logger.Errorf("保存授权码失败: %v", err)
+		return nil, errors.New("生成授权码失败")
+	}
+	logger.Infof("用户 %s 授权应用 %s，作用域: %s", user.Username, client.Name, record.Scope)
+
+	params := url.Values{"code": {code}}
+	if req.State != "" {
+		params.Set("state", req.State)
+	}
+	return &model.OAuthAuthorizeResult{RedirectTo: appendQuery(req.RedirectURI, params)}, nil
+}
+
+// validateAuthorize 校验授权请求。应用或回调地址无效时返回普通错误，其他参数错误返回 *OAuthError
+func (s *oauthService) validateAuthorize(req *model.OAuthAuthorizeRequest) (*model.OAuthClient, error) {
+	if !s.cfg.Enabled() {
+		return nil, ErrOAuthDisabled
+	}
+	client, err := s.oauthRepo.GetClientByClientID(req.ClientID)
+	if err != nil || client.Status != 1 {
+		return nil, errors.New("应用不存在或已禁用")
+	}
+	if req.RedirectURI == "" || !containsString(client.RedirectURIs, req.RedirectURI) {
+		return nil, errors.New("回调地址未在应用中注册")
+	}
+
+	if req.ResponseType != "code" {
+		return nil, oauthError("unsupported_response_type", "只支持授权码模式")
+	}
+	if !containsString(client.GrantTypes, model.OAuthGrantAuthorizationCode) {
+		return nil, oauthError("unauthorized_client", "应用不允许使用授权码模式")
+	}
+	if req.CodeChallengeMethod != "S256" || len(req.CodeChallenge) != 43 {
+		return nil, oauthError("invalid_request", "必须使用 S256 方式的 PKCE")
+	}
+	if err := checkScopes(client, splitScope(req.Scope)); err != nil {
+		return nil, err
+	}
+	if len(req.Nonce) > 255 || len(req.State) > 1024 {
+		return nil, oauthError("invalid_request", "state 或 nonce 过长")
+	}
+	return client, nil
+}
+
+// Token 令牌端点，支持授权码和客户端凭据模式
+func (s *oauthService) Token(clientID, clientSecret string, form url.Values) (*model.OAuthTokenResponse, error) {
+	if !s.cfg.Enabled() {
+		return nil, ErrOAuthDisabled
+	}
+	client, err := s.authenticateClient(clientID, clientSecret, true)
+	if err != nil {
+		return nil, err
+	}
+	grant := form.Get("grant_type")
+	if !containsString(client.GrantTypes, grant) {
+		if grant != model.OAuthGrantAuthorizationCode && grant != model.OAuthGrantClientCredentials {
+			return nil, oauthError("unsupported_grant_type", "不支持的授权类型")
+		}
+		return nil, oauthError("unauthorized_client", "应用不允许使用该授权类型")
+	}
+
+	if grant == model.OAuthGrantClientCredentials {
+		scopes := splitScope(form.Get("scope"))
+		if containsString(scopes, model.OAuthScopeOpenID) || containsString(scopes, model.OAuthScopeProfile) || containsString(scopes, model.OAuthScopeEmail) {
+			return nil, oauthError("invalid_scope", "客户端凭据模式不能申请用户相关的作用域")
+		}
+		if err := checkScopes(client, scopes); err != nil {
+			return nil, err
+		}
+		return s.issueToken(client, nil, strings.Join(scopes, " "), nil, "")
+	}
+	return s.exchangeCode(client, form)
+}
+
+// exchangeCode 使用授权码换取令牌，授权码被重复使用时吊销已颁发的令牌。
+// 过期时间、回调地址和 PKCE 校验通过后才消耗授权码，不知道 code_verifier 的请求既不能使授权码失效也不能吊销令牌
+func (s *oauthService) exchangeCode(client *model.OAuthClient, form url.Values) (*model.OAuthTokenResponse, error) {
+	now := time.Now()
+	code, err := s.oauthRepo.GetCodeByHash(utils.SHA256(form.Get("code")))
+	if err != nil || code.ClientID != client.ClientID {
+		return nil, oauthError("invalid_grant", "授权码无效")
+	}
+	if !now.Before(code.ExpiresAt) {
+		return nil, oauthError("invalid_grant", "授权码已过期")
+	}
+	if form.Get("redirect_uri") != code.RedirectURI {
+		return nil, oauthError("invalid_grant", "回调地址与授权请求不一致")
+	}
+	verifier := form.Get("code_verifier")
+	if len(verifier) < 43 || len(verifier) > 128 ||
+		subtle.ConstantTimeCompare([]byte(oidc.CodeChallenge(verifier)), []byte(code.CodeChallenge)) != 1 {
+		return nil, oauthError("invalid_grant", "PKCE 校验失败")
+	}
+	if code.UsedAt != nil || s.oauthRepo.UseCode(code.ID, now) != nil {
+		revoked, err := s.oauthRepo.RevokeTokensByCode(code.ID, now)
+		if err != nil {
+			logger.Errorf("吊销授权码 #%d 颁发的令牌失败: %v", code.ID, err)
+		}
+		logger.Warnf("应用 %s 的授权码 #%d 被重复使用，已吊销 %d 个令牌", client.ClientID, code.ID, revoked)
+		return nil, oauthError("invalid_grant", "授权码已使用")
+	}
+
+	user, err := s.userRepo.GetByID(code.UserID)
+	if err != nil || user.Status != 1 {
+		return nil, oauthError("invalid_grant", "用户不存在或已被禁用")
+	}
+	return s.issueToken(client, user, code.Scope, &code.ID, code.Nonce)
+}
+
+// issueToken 颁发访问令牌，作用域包含 openid 时同时签发 ID Token
+func (s *oauthService) issueToken(client *model.OAuthClient, user *model.User, scope string, codeID *uint, nonce string) (*model.OAuthTokenResponse, error) {
+	now := time.Now()
+	ttl := s.cfg.AccessTTL()
+	secret, err := newOAuthSecret(oauthAccessTokenPrefix)
+	if err != nil {
+		return nil, oauthError("server_error", "生成访问令牌失败")
+	}
+	token := &model.OAuthAccessToken{
+		TokenHash:           utils.SHA256(secret),
+		ClientID:            client.ClientID,
+		Scope:               scope,
+		AuthorizationCodeID: codeID,
+		ExpiresAt:           now.Add(ttl),
+	}
+	if user != nil {
+		token.UserID = &user.ID
+	}
+
+	resp := &model.OAuthTokenResponse{AccessToken: secret, TokenType: "Bearer", ExpiresIn: int64(ttl.Seconds()), Scope: scope}
+	scopes := splitScope(scope)
+	if user != nil && containsString(scopes, model.OAuthScopeOpenID) {
+		claims := gojwt.MapClaims{
+			"iss": s.cfg.IssuerURL(),
+			"sub": strconv.FormatUint(uint64(user.ID), 10),
+			"aud": client.ClientID,
+			"iat": now.Unix(),
+			"exp": now.Add(ttl).Unix(),
+		}
+		if nonce != "" {
+			claims["nonce"] = nonce
+		}
+		for k, v := range s.userClaims(user, scopes) {
+			claims[k] = v
+		}
+		if resp.IDToken, err = jwt.Sign(claims); err != nil {
+			logger.Errorf("签发ID Token失败: %v", err)
+			return nil, oauthError("server_error", "未配置非对称签名密钥，无法签发ID Token")
+		}
+	}
+
+	if err := s.oauthRepo.CreateToken(token); err != nil {
+		logger.Errorf("保存访问令牌失败: %v", err)
+		return nil, oauthError("server_error", "生成访问令牌失败")
+	}
+	return resp, nil
+}
+
+// Introspect 令牌自省，只有机密客户端可以调用
+func (s *oauthService) Introspect(clientID, clientSecret, raw string) (*model.OAuthIntrospection, error) {
+	if !s.cfg.Enabled() {
+		return nil, ErrOAuthDisabled
+	}
+	if _, err := s.authenticateClient(clientID, clientSecret, false); err != nil {
+		return nil, err
+	}
+	token, client, user, ok := s.activeToken(raw)
+	if !ok {
+		return &model.OAuthIntrospection{Active: false}, nil
+	}
+
+	result := &model.OAuthIntrospection{
+		Active:    true,
+		Scope:     token.Scope,
+		ClientID:  token.ClientID,
+		TokenType: "Bearer",
+		Exp:       token.ExpiresAt.Unix(),
+		Iat:       token.CreatedAt.Unix(),
+		Sub:       client.ClientID,
+		Iss:       s.cfg.IssuerURL(),
+	}
+	role := client.Role
+	if user != nil {
+		result.Sub = strconv.FormatUint(uint64(user.ID), 10)
+		result.Username = user.Username
+		role = user.Role
+	}
+	if containsString(splitScope(token.Scope), model.OAuthScopeRoles) {
+		result.Roles, result.Permissions = s.roleClaims(role)
+	}
+	return result, nil
+}
+
+// Revoke 吊销令牌，令牌不存在或不属于该应用时也视为成功
+func (s *oauthService) Revoke(clientID, clientSecret, raw string) error {
+	if !s.cfg.Enabled() {
+		return ErrOAuthDisabled
+	}
+	client, err := s.authenticateClient(clientID, clientSecret, true)
+	if err != nil {
+		return err
+	}
+	token, err := s.oauthRepo.GetTokenByHash(utils.SHA256(raw))
+	if err != nil || token.ClientID != client.ClientID || token.RevokedAt != nil {
+		return nil
+	}
+	if err := s.oauthRepo.RevokeToken(token.ID, time.Now()); err != nil {
+		logger.Errorf("吊销访问令牌失败: %v", err)
+		return oauthError("server_error", "吊销访问令牌失败")
+	}
+	return nil
+}
+
+// UserInfo 返回访问令牌对应用户的信息，令牌需要包含 openid 作用域
+func (s *oauthService) UserInfo(raw string) (map[string]interface{}, error) {
+	if !s.cfg.Enabled() {
+		return nil, ErrOAuthDisabled
+	}
+	token, _, user, ok := s.activeToken(raw)
+	if !ok || user == nil {
+		return nil, oauthError("invalid_token", "访问令牌无效或已过期")
+	}
+	scopes := splitScope(token.Scope)
+	if !containsString(scopes, model.OAuthScopeOpenID) {
+		return nil, &OAuthError{Code: "insufficient_scope", Description: "访问令牌没有 openid 作用域", Status: http.StatusForbidden}
+	}
+	info := s.userClaims(user, scopes)
+	info["sub"] = strconv.FormatUint(uint64(user.ID), 10)
+	return info, nil
+}
+
+// activeToken 查找有效的访问令牌及其应用和用户，应用或用户被禁用的令牌视为无效
+func (s *oauthService) activeToken(raw string) (*model.OAuthAccessToken, *model.OAuthClient, *model.User, bool) {
+	if !strings.HasPrefix(raw, oauthAccessTokenPrefix) {
+		return nil, nil, nil, false
+	}
+	token, err := s.oauthRepo.GetTokenByHash(utils.SHA256(raw))
+	if err != nil || token.RevokedAt != nil || !time.Now().Before(token.ExpiresAt) {
+		return nil, nil, nil, false
+	}
+	client, err := s.oauthRepo.GetClientByClientID(token.ClientID)
+	if err != nil || client.Status != 1 {
+		return nil, nil, nil, false
+	}
+	if token.UserID == nil {
+		return token, client, nil, true
+	}
+	user, err := s.userRepo.GetByID(*token.UserID)
+	if err != nil || user.Status != 1 {
+		return nil, nil, nil, false
+	}
+	return token, client, user, true
+}
+
+// authenticateClient 校验客户端身份。allowPublic 为 true 时公开客户端只需提供客户端ID
+func (s *oauthService) authenticateClient(clientID, clientSecret string, allowPublic bool) (*model.OAuthClient, error) {
+	if clientID == "" {
+		return nil, oauthError("invalid_client", "缺少客户端认证信息")
+	}
+	client, err := s.oauthRepo.GetClientByClientID(clientID)
+	if err != nil || client.Status != 1 {
+		return nil, oauthError("invalid_client", "客户端认证失败")
+	}
+	if client.Public {
+		if !allowPublic {
+			return nil, oauthError("invalid_client", "公开客户端不能调用该接口")
+		}
+		return client, nil
+	}
+	if clientSecret == "" || subtle.ConstantTimeCompare([]byte(utils.SHA256(clientSecret)), []byte(client.SecretHash)) != 1 {
+		return nil, oauthError("invalid_client", "客户端认证失败")
+	}
+	return client, nil
+}
+
+// userClaims 按作用域返回用户声明
+func (s *oauthService) userClaims(user *model.User, scopes []string) map[string]interface{} {
+	claims := map[string]interface{}{}
+	if containsString(scopes, model.OAuthScopeProfile) {
+		name := user.Nickname
+		if name == "" {
+			name = user.Username
+		}
+		claims["name"] = name
+		claims["preferred_username"] = user.Username
+	}
+	if containsString(scopes, model.OAuthScopeEmail) {
+		claims["email"] = user.Email
+	}
+	if containsString(scopes, model.OAuthScopeRoles) {
+		claims["roles"], claims["permissions"] = s.roleClaims(user.Role)
+	}
+	return claims
+}
+
+// roleClaims 返回角色及其已启用的权限名称，角色不存在或已禁用时都为空
+func (s *oauthService) roleClaims(roleName string) ([]string, []string) {
+	roles, permissions := []string{}, []string{}
+	role, err := s.roleRepo.GetByName(roleName)
+	if err != nil || role.Status != 1 {
+		return roles, permissions
+	}
+	roles = append(roles, role.Name)
+	granted, err := s.roleRepo.GetRolePermissions(role.ID)
+	if err != nil {
+		logger.Warnf("获取角色 %s 的权限失败: %v", role.Name, err)
+		return roles, permissions
+	}
+	for _, p := range granted {
+		if p.Status == 1 {
+			permissions = append(permissions, p.Name)
+		}
+	}
+	return roles, permissions
+}
+
+// checkScopes 校验申请的作用域都在应用允许的范围内
+func checkScopes(client *model.OAuthClient, scopes []string) error {
+	for _, scope := range scopes {
+		if !containsString(client.Scopes, scope) {
+			return oauthError("invalid_scope", fmt.Sprintf("应用不允许申请作用域 %s", scope))
+		}
+	}
+	return nil
+}
+
+// errorRedirect 返回带错误信息的回调地址
+func errorRedirect(req *model.OAuthAuthorizeRequest, err *OAuthError) string {
+	params := url.Values{"error": {err.Code}, "error_description": {err.Description}}
+	if req.State != "" {
+		params.Set("state", req.State)
+	}
+	return appendQuery(req.RedirectURI, params)
+}
+
+// appendQuery 在地址后追加查询参数
+func appendQuery(rawURL string, params url.Values) string {
+	sep := "?"
+	if strings.Contains(rawURL, "?") {
+		sep = "&"
+	}
+	return rawURL + sep + params.Encode()
+}
+
+// splitScope 拆分以空格分隔的作用域并去重
+func splitScope(scope string) []string {
+	return uniqueStrings(strings.Fields(scope))
+}
+
+func uniqueStrings(list []string) []string {
+	result := make([]string, 0, len(list))
+	for _, item := range list {
+		if !containsString(result, item) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func isLoopbackHost(host string) bool {
+	return host == "localhost" || host == "127.0.0.1" || host == "::1"
+}
+
+// newOAuthSecret 生成带前缀的随机凭据
+func newOAuthSecret(prefix string) (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return prefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"domain-admin/internal/repository"
+	"domain-admin/model"
+	"domain-admin/pkg/config"
+	"domain-admin/pkg/oidc"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	testRedirectURI = "https://app.example.com/callback"
+	testVerifier    = "verifier-0123456789-0123456789-0123456789-0123456789"
+)
+
+func newTestOAuthService(t *testing.T) (OAuthService, *gorm.DB) {
+	t.Helper()
+	db := newTestDB(t, &model.User{}, &model.Role{}, &model.Permission{}, &model.OAuthClient{}, &model.OAuthAuthorizationCode{}, &model.OAuthAccessToken{})
+	perm := &model.Permission{Name: "domain.list", DisplayName: "域名列表", Resource: "/api/domains", Action: "GET", Status: 1}
+	role := &model.Role{Name: "reader", DisplayName: "只读", Level: 1, Status: 1}
+	if err := db.Create(perm).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(role).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Model(role).Association("Permissions").Append(perm); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&model.User{Username: "alice", Email: "alice@example.com", Password: "x", Role: "reader", Status: 1}).Error; err != nil {
+		t.Fatal(err)
+	}
+	svc := NewOAuthService(
+		repository.NewOAuthRepository(db),
+		repository.NewUserRepository(db),
+		repository.NewRoleRepository(db),
+		config.OAuthConfig{Issuer: "https://admin.example.com"},
+	)
+	return svc, db
+}
+
+func newTestOAuthClient(t *testing.T, svc OAuthService, public bool) *model.OAuthClientSecretResult {
+	t.Helper()
+	grants := []string{model.OAuthGrantAuthorizationCode}
+	if !public {
+		grants = append(grants, model.OAuthGrantClientCredentials)
+	}
+	client, err := svc.CreateClient(1, &model.OAuthClientRequest{
+		Name:         "app",
+		Public:       public,
+		RedirectURIs: []string{testRedirectURI},
+		GrantTypes:   grants,
+		Scopes:       []string{model.OAuthScopeProfile, model.OAuthScopeEmail, model.OAuthScopeRoles},
+		Role:         "reader",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+// authorizeCode 用户同意授权，返回回调地址中的授权码
+func authorizeCode(t *testing.T, svc OAuthService, clientID string) string {
+	t.Helper()
+	result, err := svc.Authorize(1, &model.OAuthAuthorizeRequest{
+		ResponseType:        "code",
+		ClientID:            clientID,
+		RedirectURI:         testRedirectURI,
+		Scope:               "profile roles",
+		State:               "xyz",
+		CodeChallenge:       oidc.CodeChallenge(testVerifier),
+		CodeChallengeMethod: "S256",
+		Approve:             true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	redirect, err := url.Parse(result.RedirectTo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if redirect.Query().Get("state") != "xyz" || redirect.Query().Get("code") == "" {
+		t.Fatalf("redirect = %s", result.RedirectTo)
+	}
+	return redirect.Query().Get("code")
+}
+
+func codeForm(code, redirectURI, verifier string) url.Values {
+	return url.Values{
+		"grant_type":    {model.OAuthGrantAuthorizationCode},
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+		"code_verifier": {verifier},
+	}
+}
+
+func assertOAuthError(t *testing.T, err error, code string) {
+	t.Helper()
+	var oerr *OAuthError
+	if !errors.As(err, &oerr) || oerr.Code != code {
+		t.Fatalf("err = %v, want %s", err, code)
+	}
+}
+
+func TestOAuthAuthorizeRequiresPKCE(t *testing.T) {
+	svc, _ := newTestOAuthService(t)
+	client := newTestOAuthClient(t, svc, true)
+
+	req := &model.OAuthAuthorizeRequest{ResponseType: "code", ClientID: client.ClientID, RedirectURI: testRedirectURI, Approve: true}
+	result, err := svc.Authorize(1, req)
+	if err != nil || !strings.Contains(result.RedirectTo, "error=invalid_request") {
+		t.Fatalf("authorize without PKCE = %+v, %v", result, err)
+	}
+
+	// 回调地址未注册时不能重定向
+	req.RedirectURI = "https://evil.example.com/callback"
+	if _, err := svc.Authorize(1, req); err == nil {
+		t.Fatal("unregistered redirect_uri was accepted")
+	}
+
+	req.RedirectURI = testRedirectURI
+	req.CodeChallenge, req.CodeChallengeMethod = oidc.CodeChallenge(testVerifier), "S256"
+	req.Approve = false
+	result, err = svc.Authorize(1, req)
+	if err != nil || !strings.Contains(result.RedirectTo, "error=access_denied") {
+		t.Fatalf("denied authorize = %+v, %v", result, err)
+	}
+}
+
+func TestOAuthExchangeCode(t *testing.T) {
+	svc, _ := newTestOAuthService(t)
+	client := newTestOAuthClient(t, svc, true)
+	code := authorizeCode(t, svc, client.ClientID)
+
+	// 校验失败的请求不消耗授权码
+	_, err := svc.Token(client.ClientID, "", codeForm(code, testRedirectURI, strings.Repeat("x", 43)))
+	assertOAuthError(t, err, "invalid_grant")
+	_, err = svc.Token(client.ClientID, "", codeForm(code, "https://app.example.com/other", testVerifier))
+	assertOAuthError(t, err, "invalid_grant")
+
+	token, err := svc.Token(client.ClientID, "", codeForm(code, testRedirectURI, testVerifier))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.Scope != "profile roles" || token.IDToken != "" {
+		t.Fatalf("token = %+v", token)
+	}
+	info, err := svc.UserInfo(token.AccessToken)
+	assertOAuthError(t, err, "insufficient_scope")
+	if info != nil {
+		t.Fatalf("userinfo = %v", info)
+	}
+}
+
+func TestOAuthCodeReplayRevokesTokens(t *testing.T) {
+	svc, _ := newTestOAuthService(t)
+	client := newTestOAuthClient(t, svc, false)
+	code := authorizeCode(t, svc, client.ClientID)
+
+	token, err := svc.Token(client.ClientID, client.ClientSecret, codeForm(code, testRedirectURI, testVerifier))
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := svc.Introspect(client.ClientID, client.ClientSecret, token.AccessToken)
+	if err != nil || !info.Active || info.Username != "alice" || len(info.Roles) != 1 || len(info.Permissions) != 1 {
+		t.Fatalf("introspection = %+v, %v", info, err)
+	}
+
+	// 不知道 code_verifier 的重放不影响已颁发的令牌
+	_, err = svc.Token(client.ClientID, client.ClientSecret, codeForm(code, testRedirectURI, strings.Repeat("x", 43)))
+	assertOAuthError(t, err, "invalid_grant")
+	if info, _ := svc.Introspect(client.ClientID, client.ClientSecret, token.AccessToken); !info.Active {
+		t.Fatal("token revoked by a replay without the verifier")
+	}
+
+	_, err = svc.Token(client.ClientID, client.ClientSecret, codeForm(code, testRedirectURI, testVerifier))
+	assertOAuthError(t, err, "invalid_grant")
+	if info, _ := svc.Introspect(client.ClientID, client.ClientSecret, token.AccessToken); info.Active {
+		t.Fatal("token still active after code replay")
+	}
+}
+
+func TestOAuthExpiredCode(t *testing.T) {
+	svc, db := newTestOAuthService(t)
+	client := newTestOAuthClient(t, svc, true)
+	code := authorizeCode(t, svc, client.ClientID)
+	db.Model(&model.OAuthAuthorizationCode{}).Where("1 = 1").Update("expires_at", time.Now().Add(-time.Second))
+
+	_, err := svc.Token(client.ClientID, "", codeForm(code, testRedirectURI, testVerifier))
+	assertOAuthError(t, err, "invalid_grant")
+	var stored model.OAuthAuthorizationCode
+	db.First(&stored)
+	if stored.UsedAt != nil {
+		t.Fatal("expired code was marked as used")
+	}
+}
+
+func TestOAuthClientCredentials(t *testing.T) {
+	svc, _ := newTestOAuthService(t)
+	client := newTestOAuthClient(t, svc, false)
+	form := url.Values{"grant_type": {model.OAuthGrantClientCredentials}, "scope": {"roles"}}
+
+	_, err := svc.Token(client.ClientID, "wrong", form)
+	assertOAuthError(t, err, "invalid_client")
+	_, err = svc.Token(client.ClientID, client.ClientSecret, url.Values{"grant_type": {model.OAuthGrantClientCredentials}, "scope": {"profile"}})
+	assertOAuthError(t, err, "invalid_scope")
+
+	token, err := svc.Token(client.ClientID, client.ClientSecret, form)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := svc.Introspect(client.ClientID, client.ClientSecret, token.AccessToken)
+	if err != nil || !info.Active || info.Sub != client.ClientID || info.Username != "" || len(info.Permissions) != 1 {
+		t.Fatalf("introspection = %+v, %v", info, err)
+	}
+	if _, err := svc.UserInfo(token.AccessToken); err == nil {
+		t.Fatal("client credentials token returned user info")
+	}
+}
+
+func TestOAuthIntrospectAndRevoke(t *testing.T) {
+	svc, _ := newTestOAuthService(t)
+	client := newTestOAuthClient(t, svc, false)
+	other := newTestOAuthClient(t, svc, false)
+	public := newTestOAuthClient(t, svc, true)
+	token, err := svc.Token(client.ClientID, client.ClientSecret, url.Values{"grant_type": {model.OAuthGrantClientCredentials}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 公开客户端不能自省令牌
+	_, err = svc.Introspect(public.ClientID, "", token.AccessToken)
+	assertOAuthError(t, err, "invalid_client")
+	if info, err := svc.Introspect(client.ClientID, client.ClientSecret, "oat_unknown"); err != nil || info.Active {
+		t.Fatalf("unknown token = %+v, %v", info, err)
+	}
+
+	// 其他应用吊销令牌时视为成功但不生效
+	if err := svc.Revoke(other.ClientID, other.ClientSecret, token.AccessToken); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := svc.Introspect(client.ClientID, client.ClientSecret, token.AccessToken); !info.Active {
+		t.Fatal("token revoked by another client")
+	}
+
+	if err := svc.Revoke(client.ClientID, client.ClientSecret, token.AccessToken); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := svc.Introspect(client.ClientID, client.ClientSecret, token.AccessToken); info.Active {
+		t.Fatal("revoked token is still active")
+	}
+}
@@ -0,0 +1,360 @@
+package service
+
+import (
+	"context"
+	"domain-admin/internal/repository"
+	"domain-admin/model"
+	"domain-admin/pkg/cache"
+	"domain-admin/pkg/config"
+	"domain-admin/pkg/logger"
+	"domain-admin/pkg/oidc"
+	"domain-admin/pkg/utils"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// oidcStateTTL 单点登录授权请求的有效期
+const oidcStateTTL = 10 * time.Minute
+
+var (
+	// ErrOIDCProviderNotFound 未配置的身份提供方
+	ErrOIDCProviderNotFound = errors.New("身份提供方不存在")
+	errOIDCState            = errors.New("登录请求无效或已过期，请重新登录")
+	errOIDCFailed           = errors.New("身份提供方登录失败")
+)
+
+// OIDCService 单点登录服务接口
+type OIDCService interface {
+	Providers() []*model.OIDCProviderInfo
+	BeginLogin(ctx context.Context, provider string) (authURL, state string, err error)
+	CompleteLogin(ctx context.Context, provider, state, code string) (*model.UserResponse, error)
+}
+
+// oidcProvider 配置和对应的身份提供方客户端
+type oidcProvider struct {
+	cfg    config.OIDCProviderConfig
+	client *oidc.Provider
+}
+
+type oidcService struct {
+	oidcRepo     repository.OIDCRepository
+	identityRepo repository.UserIdentityRepository
+	userRepo     repository.UserRepository
+	roleRepo     repository.RoleRepository
+	providers    map[string]*oidcProvider
+	order        []string
+}
+
+// NewOIDCService 创建单点登录服务实例，缺少 name、issuer 或 client_id 的身份提供方会被忽略
+func NewOIDCService(oidcRepo repository.OIDCRepository, identityRepo repository.UserIdentityRepository, userRepo repository.UserRepository, roleRepo repository.RoleRepository, cfg config.OIDCConfig) OIDCService {
+	s := &oidcService{
+		oidcRepo:     oidcRepo,
+		identityRepo: identityRepo,
+		userRepo:     userRepo,
+		roleRepo:     roleRepo,
+		providers:    make(map[string]*oidcProvider, len(cfg.Providers)),
+	}
+	for _, pc := range cfg.Providers {
+		if pc.Name == "" || pc.Issuer == "" || pc.ClientID == "" || pc.RedirectURL == "" {
+			logger.Warnf("身份提供方 %q 缺少 name、issuer、client_id 或 redirect_url，已忽略", pc.Name)
+			continue
+		}
+		if _, ok := s.providers[pc.Name]; ok {
+			logger.Warnf("身份提供方 %s 重复配置，已忽略", pc.Name)
+			continue
+		}
+		s.providers[pc.Name] = &oidcProvider{
+			cfg:    pc,
+			client: oidc.NewProvider(pc.Issuer, pc.ClientID, pc.ClientSecret, pc.RedirectURL, pc.Scopes),
+		}
+		s.order = append(s.order, pc.Name)
+	}
+	return s
+}
+
+// Providers 返回已配置的身份提供方
+func (s *oidcService) Providers() []*model.OIDCProviderInfo {
+	list := make([]*model.OIDCProviderInfo, 0, len(s.order))
+	for _, name := range s.order {
+		p := s.providers[name]
+		list = append(list, &model.OIDCProviderInfo{
+			Name:        name,
+			DisplayName: p.cfg.Label(),
+			LoginURL:    "/api/auth/oidc/" + name + "/login",
+		})
+	}
+	return list
+}
+
+// BeginLogin 生成 state、nonce 和 PKCE 验证码并返回授权地址
+func (s *oidcService) BeginLogin(ctx context.Context, provider string) (string, string, error) {
+	p, ok := s.providers[provider]
+	if !ok {
+		return "", "", ErrOIDCProviderNotFound
+	}
+	state, err1 := oidc.RandomString()
+	nonce, err2 := oidc.RandomString()
+	verifier, err3 := oidc.RandomString()
+	if err1 != nil || err2 != nil || err3 != nil {
+		return "", "", errors.New("生成登录请求失败")
+	}
+
+	authURL, err := p.client.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		logger.Errorf("获取身份提供方 %s 的发现文档失败: %v", provider, err)
+		return "", "", errors.New("无法连接身份提供方，请稍后重试")
+	}
+	record := &model.OIDCLoginState{
+		Provider:     provider,
+		StateHash:    utils.SHA256(state),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(oidcStateTTL),
+	}
+	if err := s.oidcRepo.CreateState(record); err != nil {
+		logger.Errorf("保存登录请求失败: %v", err)
+		return "", "", errors.New("生成登录请求失败")
+	}
+	return authURL, state, nil
+}
+
+// CompleteLogin 使用授权码换取并校验 ID Token，按外部账号查找或创建用户，并按用户组同步角色
+func (s *oidcService) CompleteLogin(ctx context.Context, provider, state, code string) (*model.UserResponse, error) {
+	p, ok := s.providers[provider]
+	if !ok {
+		return nil, ErrOIDCProviderNotFound
+	}
+	now := time.Now()
+	record, err := s.oidcRepo.UseState(utils.SHA256(state), now)
+	if err != nil || record.Provider != provider || !now.Before(record.ExpiresAt) {
+		return nil, errOIDCState
+	}
+
+	token, err := p.client.Exchange(ctx, code, record.CodeVerifier)
+	if err != nil {
+		logger.Warnf("身份提供方 %s 令牌交换失败: %v", provider, err)
+		return nil, errOIDCFailed
+	}
+	claims, err := p.client.VerifyIDToken(ctx, token.IDToken, record.Nonce)
+	if err != nil {
+		logger.Warnf("身份提供方 %s 的ID Token校验失败: %v", provider, err)
+		return nil, errOIDCFailed
+	}
+	s.mergeUserInfo(ctx, p, claims, token.AccessToken)
+
+	subject := claims.String("sub")
+	groups := claims.Strings(groupsClaim(p.cfg))
+	role, err := mapGroupRole(s.roleRepo, "身份提供方 "+provider, p.cfg.RoleMappings, p.cfg.Role(), groups)
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err := s.identityRepo.GetIdentity(provider, subject)
+	var user *model.User
+	if err == nil {
+		if user, err = s.userRepo.GetByID(identity.UserID); err != nil {
+			return nil, errors.New("关联的用户不存在")
+		}
+	} else {
+		var provisioned bool
+		if user, provisioned, err = s.provision(p, claims, role); err != nil {
+			return nil, err
+		}
+		identity = &model.UserIdentity{UserID: user.ID, Provider: provider, Subject: subject, Provisioned: provisioned}
+	}
+	if user.Status == 0 {
+		return nil, errors.New("用户已被禁用")
+	}
+
+	// 配置了用户组映射时以身份提供方为准同步角色，关联的已有本地账号不修改角色
+	if identity.Provisioned && len(p.cfg.RoleMappings) > 0 && user.Role != role {
+		logger.Infof("用户 %s 的角色按身份提供方 %s 的用户组从 %s 同步为 %s", user.Username, provider, user.Role, role)
+		user.Role = role
+		if err := s.userRepo.Update(user); err != nil {
+			logger.Errorf("同步用户角色失败: %v", err)
+			return nil, errors.New("同步用户角色失败")
+		}
+		if err := cache.DelUserCache(ctx, user.ID); err != nil {
+			logger.Warnf("删除用户缓存失败: %v", err)
+		}
+	}
+
+	identity.Email = claims.String("email")
+	identity.Groups = groups
+	identity.LastLoginAt = &now
+	if err := s.identityRepo.SaveIdentity(identity); err != nil {
+		logger.Errorf("保存外部账号关联失败: %v", err)
+		return nil, errors.New("保存外部账号关联失败")
+	}
+	if err := s.userRepo.UpdateLastLogin(user.ID); err != nil {
+		logger.Warnf("更新最后登录时间失败: %v", err)
+	}
+
+	logger.Infof("用户通过身份提供方 %s 登录成功: %s", provider, user.Username)
+	return user.ToResponse(), nil
+}
+
+// mergeUserInfo ID Token 缺少邮箱或用户组时从 UserInfo 补充，UserInfo 的 sub 必须一致
+func (s *oidcService) mergeUserInfo(ctx context.Context, p *oidcProvider, claims oidc.Claims, accessToken string) {
+	if _, ok := claims[groupsClaim(p.cfg)]; ok && claims.String("email") != "" {
+		return
+	}
+	info, err := p.client.UserInfo(ctx, accessToken)
+	if err != nil {
+		logger.Warnf("读取身份提供方 %s 的UserInfo失败: %v", p.cfg.Name, err)
+		return
+	}
+	if info == nil || info.String("sub") != claims.String("sub") {
+		return
+	}
+	for k, v := range info {
+		if _, ok := claims[k]; !ok {
+			claims[k] = v
+		}
+	}
+}
+
+// provision 首次登录时创建新用户，返回的 provisioned 表示用户是否为新创建。
+// 邮箱已被本地账号使用时默认拒绝登录；身份提供方开启 link_existing_users 且确认过该邮箱时关联已有账号
+func (s *oidcService) provision(p *oidcProvider, claims oidc.Claims, role string) (*model.User, bool, error) {
+	email := strings.TrimSpace(claims.String("email"))
+	if email == "" {
+		return nil, false, errors.New("身份提供方未返回邮箱，无法创建用户")
+	}
+	if existing, err := s.userRepo.GetByEmail(email); err == nil {
+		if !p.cfg.LinkExistingUsers || !claims.Bool("email_verified") {
+			logger.Warnf("身份提供方 %s 的账号 %s 的邮箱已被用户 %s 使用，拒绝自动关联", p.cfg.Name, claims.String("sub"), existing.Username)
+			return nil, false, errors.New("邮箱已被本地账号使用，请联系管理员")
+		}
+		logger.Infof("身份提供方 %s 的账号 %s 已关联到用户 %s", p.cfg.Name, claims.String("sub"), existing.Username)
+		return existing, false, nil
+	}
+
+	username, err := s.uniqueUsername(p, claims, email)
+	if err != nil {
+		return nil, false, err
+	}
+	user := &model.User{
+		Username: username,
+		Email:    email,
+		Nickname: truncateRunes(claims.String("name"), 50),
+		Role:     role,
+	}
+	if err := createExternalUser(s.userRepo, user); err != nil {
+		return nil, false, err
+	}
+	logger.Infof("通过身份提供方 %s 创建用户 %s，角色 %s", p.cfg.Name, username, role)
+	return user, true, nil
+}
+
+// uniqueUsername 从用户名声明或邮箱前缀生成用户名，已被占用时追加外部账号标识的哈希
+func (s *oidcService) uniqueUsername(p *oidcProvider, claims oidc.Claims, email string) (string, error) {
+	claim := p.cfg.UsernameClaim
+	if claim == "" {
+		claim = "preferred_username"
+	}
+	username := strings.TrimSpace(claims.String(claim))
+	if username == "" {
+		username = email
+		if i := strings.LastIndex(email, "@"); i > 0 {
+			username = email[:i]
+		}
+	}
+	username = truncateRunes(username, 40)
+	if utf8.RuneCountInString(username) < 3 {
+		username = "sso-" + username
+	}
+	if _, err := s.userRepo.GetByUsername(username); err != nil {
+		return username, nil
+	}
+
+	suffix := utils.SHA256(p.cfg.Name + ":" + claims.String("sub"))[:6]
+	candidate := username + "-" + suffix
+	if _, err := s.userRepo.GetByUsername(candidate); err != nil {
+		return candidate, nil
+	}
+	return "", fmt.Errorf("用户名 %s 已存在", username)
+}
+
+// mapGroupRole 按用户组映射角色，匹配多个时使用等级最高的启用角色，没有匹配时使用默认角色。source 用于日志
+func mapGroupRole(roleRepo repository.RoleRepository, source string, mappings []config.GroupRoleMapping, defaultRole string, groups []string) (string, error) {
+	var best *model.Role
+	for _, mapping := range mappings {
+		if !containsString(groups, mapping.Group) {
+			continue
+		}
+		role, err := roleRepo.GetByName(mapping.Role)
+		if err != nil || role.Status != 1 {
+			logger.Warnf("%s 的用户组 %s 映射的角色 %s 不存在或已禁用", source, mapping.Group, mapping.Role)
+			continue
+		}
+		if best == nil || role.Level > best.Level {
+			best = role
+		}
+	}
+	if best != nil {
+		return best.Name, nil
+	}
+
+	if _, err := roleRepo.GetByName(defaultRole); err != nil {
+		logger.Errorf("%s 的默认角色 %s 不存在", source, defaultRole)
+		return "", errors.New("未配置可用的默认角色，请联系管理员")
+	}
+	return defaultRole, nil
+}
+
+// createExternalUser 创建外部认证的用户。外部用户使用随机密码，不能通过本地密码登录
+func createExternalUser(userRepo repository.UserRepository, user *model.User) error {
+	random, err := randomHex(32)
+	if err != nil {
+		return errors.New("创建用户失败")
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(random), bcrypt.DefaultCost)
+	if err != nil {
+		logger.Errorf("密码加密失败: %v", err)
+		return errors.New("创建用户失败")
+	}
+	user.Password = string(hashedPassword)
+	user.Status = 1
+	// 外部身份提供方管理的用户视为已验证邮箱
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+	if err := userRepo.Create(user); err != nil {
+		logger.Errorf("创建外部用户失败: %v", err)
+		return errors.New("创建用户失败")
+	}
+	if err := cache.DelUserListCache(context.Background(), "*"); err != nil {
+		logger.Warnf("清除用户列表缓存失败: %v", err)
+	}
+	return nil
+}
+
+// groupsClaim 返回用户组声明名称，默认groups
+func groupsClaim(cfg config.OIDCProviderConfig) string {
+	if cfg.GroupsClaim != "" {
+		return cfg.GroupsClaim
+	}
+	return "groups"
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// truncateRunes 按字符截断字符串
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
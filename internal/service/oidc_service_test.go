@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"domain-admin/internal/repository"
+	"domain-admin/model"
+	"domain-admin/pkg/config"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+// testIdP 本地身份提供方，授权码换取的 ID Token 使用 claims 中的声明
+type testIdP struct {
+	*httptest.Server
+	key    *ecdsa.PrivateKey
+	mu     sync.Mutex
+	codes  map[string]string // 授权码 -> nonce
+	claims jwt.MapClaims
+}
+
+func newTestIdP(t *testing.T) *testIdP {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp := &testIdP{key: key, codes: map[string]string{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.URL,
+			"authorization_endpoint": idp.URL + "/authorize",
+			"token_endpoint":         idp.URL + "/token",
+			"jwks_uri":               idp.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		enc := base64.RawURLEncoding
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "EC", "kid": "k1", "use": "sig", "crv": "P-256",
+			"x": enc.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+			"y": enc.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		idp.mu.Lock()
+		nonce, ok := idp.codes[r.PostForm.Get("code")]
+		claims := jwt.MapClaims{"iss": idp.URL, "aud": "client", "nonce": nonce, "exp": time.Now().Add(time.Hour).Unix(), "iat": time.Now().Unix()}
+		for k, v := range idp.claims {
+			claims[k] = v
+		}
+		idp.mu.Unlock()
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+		token.Header["kid"] = "k1"
+		idToken, _ := token.SignedString(key)
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "at", "token_type": "Bearer", "expires_in": 3600, "id_token": idToken})
+	})
+	idp.Server = httptest.NewServer(mux)
+	t.Cleanup(idp.Close)
+	return idp
+}
+
+// login 模拟浏览器完成一次单点登录，claims 为身份提供方返回的用户声明
+func (idp *testIdP) login(t *testing.T, svc OIDCService, provider string, claims jwt.MapClaims) (*model.UserResponse, error) {
+	t.Helper()
+	ctx := context.Background()
+	authURL, state, err := svc.BeginLogin(ctx, provider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := "code-" + state
+	idp.mu.Lock()
+	idp.codes[code] = parsed.Query().Get("nonce")
+	idp.claims = claims
+	idp.mu.Unlock()
+	return svc.CompleteLogin(ctx, provider, state, code)
+}
+
+func newTestOIDCService(t *testing.T, idp *testIdP) (OIDCService, *gorm.DB) {
+	t.Helper()
+	db := newTestDB(t, &model.User{}, &model.Role{}, &model.Permission{}, &model.OIDCLoginState{}, &model.UserIdentity{})
+	for _, role := range []*model.Role{
+		{Name: "user", DisplayName: "用户", Level: 1, Status: 1},
+		{Name: "operator", DisplayName: "运维", Level: 50, Status: 1},
+		{Name: "admin", DisplayName: "管理员", Level: 100, Status: 1},
+	} {
+		if err := db.Create(role).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	provider := config.OIDCProviderConfig{
+		Issuer:       idp.URL,
+		ClientID:     "client",
+		RedirectURL:  "https://admin.example.com/api/auth/oidc/corp/callback",
+		RoleMappings: []config.GroupRoleMapping{{Group: "ops", Role: "operator"}, {Group: "staff", Role: "user"}},
+	}
+	corp, linked := provider, provider
+	corp.Name = "corp"
+	linked.Name = "linked"
+	linked.LinkExistingUsers = true
+	svc := NewOIDCService(
+		repository.NewOIDCRepository(db),
+		repository.NewUserIdentityRepository(db),
+		repository.NewUserRepository(db),
+		repository.NewRoleRepository(db),
+		config.OIDCConfig{Providers: []config.OIDCProviderConfig{corp, linked}},
+	)
+	return svc, db
+}
+
+func TestOIDCProvisionAndRoleSync(t *testing.T) {
+	idp := newTestIdP(t)
+	svc, db := newTestOIDCService(t, idp)
+
+	claims := jwt.MapClaims{"sub": "u-1", "email": "alice@example.com", "email_verified": true, "preferred_username": "alice", "groups": []string{"ops"}}
+	user, err := idp.login(t, svc, "corp", claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Username != "alice" || user.Role != "operator" || user.EmailVerifiedAt == nil {
+		t.Fatalf("provisioned user = %+v", user)
+	}
+	var identity model.UserIdentity
+	db.Where("provider = ? AND subject = ?", "corp", "u-1").First(&identity)
+	if identity.UserID != user.ID || !identity.Provisioned {
+		t.Fatalf("identity = %+v", identity)
+	}
+
+	// 由单点登录创建的用户每次登录按用户组同步角色
+	claims["groups"] = []string{"staff"}
+	user, err = idp.login(t, svc, "corp", claims)
+	if err != nil || user.Role != "user" {
+		t.Fatalf("synced user = %+v, %v", user, err)
+	}
+}
+
+func TestOIDCDoesNotTakeOverLocalAccounts(t *testing.T) {
+	idp := newTestIdP(t)
+	svc, db := newTestOIDCService(t, idp)
+	admin := &model.User{Username: "admin", Email: "admin@example.com", Password: "x", Role: "admin", Status: 1}
+	if err := db.Create(admin).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// 默认不关联同邮箱的本地账号
+	claims := jwt.MapClaims{"sub": "evil", "email": "admin@example.com", "email_verified": true, "groups": []string{"staff"}}
+	if _, err := idp.login(t, svc, "corp", claims); err == nil || !strings.Contains(err.Error(), "邮箱已被本地账号使用") {
+		t.Fatalf("corp login err = %v", err)
+	}
+	// 开启关联时仍要求身份提供方确认过邮箱
+	claims["email_verified"] = false
+	if _, err := idp.login(t, svc, "linked", claims); err == nil {
+		t.Fatal("unverified email was linked to a local account")
+	}
+	var count int64
+	db.Model(&model.UserIdentity{}).Count(&count)
+	if count != 0 {
+		t.Fatalf("identities = %d", count)
+	}
+
+	// 开启关联后可以登录，但不修改本地账号的角色
+	claims["email_verified"] = true
+	for i := 0; i < 2; i++ {
+		user, err := idp.login(t, svc, "linked", claims)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if user.ID != admin.ID || user.Role != "admin" {
+			t.Fatalf("linked user = %+v", user)
+		}
+	}
+	var identity model.UserIdentity
+	db.Where("provider = ?", "linked").First(&identity)
+	if identity.UserID != admin.ID || identity.Provisioned {
+		t.Fatalf("identity = %+v", identity)
+	}
+	var stored model.User
+	db.First(&stored, admin.ID)
+	if stored.Role != "admin" {
+		t.Fatalf("local admin role changed to %s", stored.Role)
+	}
+}
@@ -1,8 +1,10 @@
 package service
 
 import (
+	"context"
 	"domain-admin/internal/repository"
 	"domain-admin/model"
+	"domain-admin/pkg/middleware"
 	"domain-admin/pkg/pagination"
 	"errors"
 	"fmt"
@@ -15,8 +17,8 @@ type PermissionService interface {
 	Create(permission *model.Permission) error
 	GetByID(id uint) (*model.Permission, error)
 	GetByName(name string) (*model.Permission, error)
-	Update(permission *model.Permission) error
-	Delete(id uint) error
+	Update(ctx context.Context, permission *model.Permission) error
+	Delete(ctx context.Context, id uint) error
 	List(page pagination.Pagination) ([]*model.Permission, int64, error)
 	UpdateStatus(id uint, status int) error
 	GetPermissionsByRole(roleID uint) ([]*model.Permission, error)
@@ -65,6 +67,11 @@ func (s *permissionService) Create(permission *model.Permission) error {
 		return errors.New("无效的操作类型")
 	}
 
+	// 验证属性条件
+	if !middleware.ValidCondition(permission.Condition) {
+		return errors.New("无效的属性条件")
+	}
+
 	// 检查权限名称是否已存在
 	existingPermission, err := s.permissionRepo.GetByName(permission.Name)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
@@ -94,7 +101,7 @@ func (s *permissionService) GetByName(name string) (*model.Permission, error) {
 }
 
 // Update 更新权限
-func (s *permissionService) Update(permission *model.Permission) error {
+func (s *permissionService) Update(ctx context.Context, permission *model.Permission) error {
 	if permission.ID == 0 {
 		return errors.New("权限ID不能为空")
 	}
@@ -127,6 +134,11 @@ func (s *permissionService) Update(permission *model.Permission) error {
 		return errors.New("无效的操作类型")
 	}
 
+	// 验证属性条件
+	if !middleware.ValidCondition(permission.Condition) {
+		return errors.New("无效的属性条件")
+	}
+
 	// 检查权限是否存在
 	existingPermission, err := s.permissionRepo.GetByID(permission.ID)
 	if err != nil {
@@ -144,11 +156,11 @@ func (s *permissionService) Update(permission *model.Permission) error {
 		}
 	}
 
-	return s.permissionRepo.Update(permission)
+	return s.permissionRepo.Update(ctx, permission)
 }
 
 // Delete 删除权限
-func (s *permissionService) Delete(id uint) error {
+func (s *permissionService) Delete(ctx context.Context, id uint) error {
 	if id == 0 {
 		return errors.New("权限ID不能为空")
 	}
@@ -164,7 +176,7 @@ func (s *permissionService) Delete(id uint) error {
 		return errors.New("系统内置权限不允许删除")
 	}
 
-	return s.permissionRepo.Delete(id)
+	return s.permissionRepo.Delete(ctx, id)
 }
 
 // List 获取权限列表
@@ -0,0 +1,360 @@
+package service
+
+import (
+	"context"
+	"domain-admin/internal/repository"
+	"domain-admin/model"
+	"domain-admin/pkg/config"
+	"domain-admin/pkg/logger"
+	"domain-admin/pkg/pagination"
+	"domain-admin/pkg/provider"
+	"domain-admin/pkg/vault"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// providerAccountNamePattern 账号名称格式
+var providerAccountNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// providerTestTimeout 连接测试超时时间
+const providerTestTimeout = 15 * time.Second
+
+// ProviderAccountService 云服务商账号服务接口
+type ProviderAccountService interface {
+	Create(req *model.ProviderAccountCreateRequest) (*model.ProviderAccount, error)
+	GetByID(id uint) (*model.ProviderAccount, error)
+	Update(id uint, req *model.ProviderAccountUpdateRequest) (*model.ProviderAccount, error)
+	Delete(id uint) error
+	List(page pagination.Pagination) ([]*model.ProviderAccount, int64, error)
+	Test(ctx context.Context, id uint) (*model.ProviderAccount, error)
+	RotateVaultKey(ctx context.Context) (*model.VaultRotateItem, error)
+	LoadProviders() (int, error)
+}
+
+type providerAccountService struct {
+	accountRepo repository.ProviderAccountRepository
+	keyring     *vault.Keyring
+	keyErr      error
+}
+
+// NewProviderAccountService 创建云服务商账号服务实例
+// 未配置主密钥时仍可管理不需要密钥的账号，保存或读取密钥时返回错误
+func NewProviderAccountService(accountRepo repository.ProviderAccountRepository, cfg config.VaultConfig) ProviderAccountService {
+	s := &providerAccountService{accountRepo: accountRepo}
+	s.keyring, s.keyErr = vault.Load(cfg.KeyEnv, cfg.KeyFile)
+	return s
+}
+
+// Create 创建账号，创建前检查服务商类型和参数能否生成客户端
+func (s *providerAccountService) Create(req *model.ProviderAccountCreateRequest) (*model.ProviderAccount, error) {
+	name := strings.TrimSpace(req.Name)
+	if !providerAccountNamePattern.MatchString(name) {
+		return nil, errors.New("账号名称只能包含字母、数字、下划线、点和中划线")
+	}
+	if _, err := s.accountRepo.GetByName(name); err == nil {
+		return nil, errors.New("账号名称已存在")
+	}
+	// 配置文件中的账号已在启动时注册
+	if _, err := provider.GetProvider(name); err == nil {
+		return nil, errors.New("账号名称与配置文件中的账号重复")
+	}
+
+	account := &model.ProviderAccount{
+		Name:      name,
+		Type:      strings.ToLower(strings.TrimSpace(req.Type)),
+		AccessKey: req.AccessKey,
+		Options:   req.Options,
+		Remark:    req.Remark,
+	}
+	if err := s.setSecret(account, req.AccessSecret); err != nil {
+		return nil, err
+	}
+
+	client, err := s.buildClient(account, req.AccessSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.accountRepo.Create(account); err != nil {
+		logger.Errorf("创建云服务商账号失败: %v", err)
+		return nil, errors.New("创建云服务商账号失败")
+	}
+	provider.SetProvider(account.Name, client)
+
+	logger.Infof("创建云服务商账号成功: %s (%s)", account.Name, account.Type)
+	return withSecretFlag(account), nil
+}
+
+// GetByID 获取账号详情，不包含密钥
+func (s *providerAccountService) GetByID(id uint) (*model.ProviderAccount, error) {
+	account, err := s.accountRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return withSecretFlag(account), nil
+}
+
+// Update 更新账号，名称和类型不可修改
+func (s *providerAccountService) Update(id uint, req *model.ProviderAccountUpdateRequest) (*model.ProviderAccount, error) {
+	account, err := s.accountRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := ""
+	if req.AccessSecret != nil {
+		secret = *req.AccessSecret
+		if err := s.setSecret(account, secret); err != nil {
+			return nil, err
+		}
+	} else if secret, err = s.openSecret(account); err != nil {
+		return nil, err
+	}
+	if req.AccessKey != nil {
+		account.AccessKey = *req.AccessKey
+	}
+	if req.Options != nil {
+		account.Options = req.Options
+	}
+	if req.Remark != nil {
+		account.Remark = *req.Remark
+	}
+
+	client, err := s.buildClient(account, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.accountRepo.Update(account); err != nil {
+		logger.Errorf("更新云服务商账号失败: %v", err)
+		return nil, errors.New("更新云服务商账号失败")
+	}
+	provider.SetProvider(account.Name, client)
+
+	logger.Infof("更新云服务商账号成功: %s", account.Name)
+	return withSecretFlag(account), nil
+}
+
+// Delete 删除账号并移除已注册的客户端
+func (s *providerAccountService) Delete(id uint) error {
+	account, err := s.accountRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.accountRepo.Delete(id); err != nil {
+		logger.Errorf("删除云服务商账号失败: %v", err)
+		return errors.New("删除云服务商账号失败")
+	}
+	provider.RemoveProvider(account.Name)
+
+	logger.Infof("删除云服务商账号成功: %s", account.Name)
+	return nil
+}
+
+// List 分页获取账号列表
+func (s *providerAccountService) List(page pagination.Pagination) ([]*model.ProviderAccount, int64, error) {
+	accounts, total, err := s.accountRepo.List(page)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, account := range accounts {
+		withSecretFlag(account)
+	}
+	return accounts, total, nil
+}
+
+// Test 使用保存的凭据测试连接并记录结果
+// 服务商实现 provider.ConnectionTester 时调用其测试方法，否则尝试列出区域
+func (s *providerAccountService) Test(ctx context.Context, id uint) (*model.ProviderAccount, error) {
+	account, err := s.accountRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := s.openSecret(account)
+	if err != nil {
+		return nil, err
+	}
+	client, err := s.buildClient(account, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, providerTestTimeout)
+	defer cancel()
+	testErr := provider.TestConnection(ctx, client)
+
+	now := time.Now()
+	account.LastTestAt = &now
+	account.LastTestOK = testErr == nil
+	account.LastTestError = ""
+	if testErr != nil {
+		account.LastTestError = testErr.Error()
+		logger.Warnf("云服务商账号 %s 连接测试失败: %v", account.Name, testErr)
+	}
+	if err := s.accountRepo.Update(account); err != nil {
+		logger.Errorf("保存连接测试结果失败: %v", err)
+		return nil, errors.New("保存连接测试结果失败")
+	}
+	return withSecretFlag(account), nil
+}
+
+// RotateVaultKey 使用当前主密钥重新加密由其他主密钥加密的访问密钥
+func (s *providerAccountService) RotateVaultKey(ctx context.Context) (*model.VaultRotateItem, error) {
+	item := &model.VaultRotateItem{Name: "provider_accounts"}
+	if s.keyErr != nil {
+		return item, s.keyError()
+	}
+
+	accounts, err := s.accountRepo.ListAll()
+	if err != nil {
+		return item, err
+	}
+	for _, account := range accounts {
+		if err := ctx.Err(); err != nil {
+			return item, err
+		}
+		rotateSealed(s.keyring, item, "云服务商账号 "+account.Name, secretOf(account), func(rotated *vault.Sealed) error {
+			account.SecretCiphertext = rotated.Ciphertext
+			account.SecretDataKey = rotated.DataKey
+			account.SecretKeyID = rotated.KeyID
+			return s.accountRepo.Update(account)
+		})
+	}
+	return item, nil
+}
+
+// LoadProviders 为数据库中的账号创建客户端，启动时在加载配置文件账号之后调用
+// 单个账号失败时记录日志并继续，返回成功加载的数量
+func (s *providerAccountService) LoadProviders() (int, error) {
+	accounts, err := s.accountRepo.ListAll()
+	if err != nil {
+		return 0, err
+	}
+
+	loaded := 0
+	for _, account := range accounts {
+		secret, err := s.openSecret(account)
+		if err == nil {
+			var client provider.DNSProvider
+			if client, err = s.buildClient(account, secret); err == nil {
+				provider.SetProvider(account.Name, client)
+				loaded++
+				continue
+			}
+		}
+		logger.Errorf("加载云服务商账号 %s 失败: %v", account.Name, err)
+	}
+	return loaded, nil
+}
+
+// setSecret 加密保存访问密钥，secret 为空时清除
+func (s *providerAccountService) setSecret(account *model.ProviderAccount, secret string) error {
+	if secret == "" {
+		account.SecretCiphertext, account.SecretDataKey, account.SecretKeyID = "", "", ""
+		return nil
+	}
+	if s.keyErr != nil {
+		return s.keyError()
+	}
+
+	sealed, err := s.keyring.Seal([]byte(secret))
+	if err != nil {
+		logger.Errorf("加密访问密钥失败: %v", err)
+		return errors.New("加密访问密钥失败")
+	}
+	account.SecretCiphertext = sealed.Ciphertext
+	account.SecretDataKey = sealed.DataKey
+	account.SecretKeyID = sealed.KeyID
+	return nil
+}
+
+// openSecret 解密访问密钥，账号没有密钥时返回空字符串
+func (s *providerAccountService) openSecret(account *model.ProviderAccount) (string, error) {
+	if account.SecretCiphertext == "" {
+		return "", nil
+	}
+	if s.keyErr != nil {
+		return "", s.keyError()
+	}
+
+	plaintext, err := s.keyring.Open(secretOf(account))
+	if err != nil {
+		if errors.Is(err, vault.ErrUnknownKey) {
+			return "", fmt.Errorf("访问密钥使用的主密钥 %s 不在密钥列表中", account.SecretKeyID)
+		}
+		return "", errors.New("解密访问密钥失败")
+	}
+	return string(plaintext), nil
+}
+
+// buildClient 根据账号信息创建服务商客户端
+// 数据库中的账号来自接口，只允许接口可以设置的服务商类型和参数
+func (s *providerAccountService) buildClient(account *model.ProviderAccount, secret string) (provider.DNSProvider, error) {
+	if err := checkProviderOptions(account.Type, account.Options); err != nil {
+		return nil, err
+	}
+	client, err := provider.New(config.CloudProviderConfig{
+		Name:         account.Name,
+		Type:         account.Type,
+		AccessKey:    account.AccessKey,
+		AccessSecret: secret,
+		Options:      account.Options,
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "unsupported dns provider type") {
+			return nil, fmt.Errorf("不支持的服务商类型，可选值: %s", strings.Join(provider.Types(), ","))
+		}
+		return nil, fmt.Errorf("创建云服务商客户端失败: %w", err)
+	}
+	return client, nil
+}
+
+// checkProviderOptions 检查服务商类型能否通过接口创建，以及 options 是否都在允许的参数中
+func checkProviderOptions(typ string, options map[string]string) error {
+	allowed, ok := provider.APIOptions(typ)
+	if !ok {
+		for _, registered := range provider.Types() {
+			if registered == typ {
+				return fmt.Errorf("服务商类型 %s 只能在配置文件中配置", typ)
+			}
+		}
+		return fmt.Errorf("不支持的服务商类型，可选值: %s", strings.Join(provider.Types(), ","))
+	}
+	for key := range options {
+		if !slices.Contains(allowed, key) {
+			if len(allowed) == 0 {
+				return fmt.Errorf("服务商类型 %s 不支持参数 %s", typ, key)
+			}
+			return fmt.Errorf("服务商类型 %s 不支持参数 %s，可选参数: %s", typ, key, strings.Join(allowed, ","))
+		}
+	}
+	return nil
+}
+
+// keyError 返回主密钥不可用的错误信息
+func (s *providerAccountService) keyError() error {
+	if errors.Is(s.keyErr, vault.ErrNoKey) {
+		return errors.New("未配置云服务商密钥的加密主密钥")
+	}
+	return fmt.Errorf("加密主密钥无效: %w", s.keyErr)
+}
+
+// secretOf 返回账号保存的加密密钥
+func secretOf(account *model.ProviderAccount) *vault.Sealed {
+	return &vault.Sealed{
+		KeyID:      account.SecretKeyID,
+		DataKey:    account.SecretDataKey,
+		Ciphertext: account.SecretCiphertext,
+	}
+}
+
+// withSecretFlag 设置账号是否保存了访问密钥
+func withSecretFlag(account *model.ProviderAccount) *model.ProviderAccount {
+	account.HasSecret = account.SecretCiphertext != ""
+	return account
+}
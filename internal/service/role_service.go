@@ -1,13 +1,13 @@
 package service
 
 import (
+	"context"
 	"domain-admin/internal/repository"
 	"domain-admin/model"
 	"domain-admin/pkg/pagination"
+	"encoding/json"
 	"errors"
 	"fmt"
-
-	"gorm.io/gorm"
 )
 
 // RoleService 角色服务接口
@@ -15,24 +15,28 @@ type RoleService interface {
 	Create(role *model.Role) error
 	GetByID(id uint) (*model.Role, error)
 	GetByName(name string) (*model.Role, error)
-	Update(role *model.Role) error
+	Update(ctx context.Context, role *model.Role) error
 	Delete(id uint) error
 	List(page pagination.Pagination) ([]*model.Role, int64, error)
 	UpdateStatus(id uint, status int) error
-	AssignPermissions(roleID uint, permissionIDs []uint) error
+	AssignPermissions(ctx context.Context, roleID uint, permissionIDs []uint) error
 	GetRolePermissions(roleID uint) ([]*model.Permission, error)
+	History(roleID uint, page pagination.Pagination) ([]*model.Revision, int64, error)
+	Rollback(ctx context.Context, roleID uint, rev int) (*model.RoleRollbackResult, error)
 }
 
 type roleService struct {
 	roleRepo       repository.RoleRepository
 	permissionRepo repository.PermissionRepository
+	revisionRepo   repository.RevisionRepository
 }
 
 // NewRoleService 创建角色服务实例
-func NewRoleService(roleRepo repository.RoleRepository, permissionRepo repository.PermissionRepository) RoleService {
+func NewRoleService(roleRepo repository.RoleRepository, permissionRepo repository.PermissionRepository, revisionRepo repository.RevisionRepository) RoleService {
 	return &roleService{
 		roleRepo:       roleRepo,
 		permissionRepo: permissionRepo,
+		revisionRepo:   revisionRepo,
 	}
 }
 
@@ -46,12 +50,8 @@ func (s *roleService) Create(role *model.Role) error {
 		return errors.New("角色显示名称不能为空")
 	}
 
-	// 检查角色名称是否已存在
-	existingRole, err := s.roleRepo.GetByName(role.Name)
-	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		return fmt.Errorf("检查角色名称失败: %w", err)
-	}
-	if existingRole != nil {
+	// 检查角色名称是否已存在，仓储在角色不存在时返回错误，查到角色才说明名称已被使用
+	if existingRole, err := s.roleRepo.GetByName(role.Name); err == nil && existingRole != nil {
 		return errors.New("角色名称已存在")
 	}
 
@@ -75,7 +75,7 @@ func (s *roleService) GetByName(name string) (*model.Role, error) {
 }
 
 // Update 更新角色
-func (s *roleService) Update(role *model.Role) error {
+func (s *roleService) Update(ctx context.Context, role *model.Role) error {
 	if role.ID == 0 {
 		return errors.New("角色ID不能为空")
 	}
@@ -96,16 +96,12 @@ func (s *roleService) Update(role *model.Role) error {
 
 	// 如果角色名称发生变化，检查新名称是否已存在
 	if existingRole.Name != role.Name {
-		conflictRole, err := s.roleRepo.GetByName(role.Name)
-		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-			return fmt.Errorf("检查角色名称失败: %w", err)
-		}
-		if conflictRole != nil {
+		if conflictRole, err := s.roleRepo.GetByName(role.Name); err == nil && conflictRole != nil {
 			return errors.New("角色名称已存在")
 		}
 	}
 
-	return s.roleRepo.Update(role)
+	return s.roleRepo.Update(ctx, role)
 }
 
 // Delete 删除角色
@@ -153,7 +149,7 @@ func (s *roleService) UpdateStatus(id uint, status int) error {
 }
 
 // AssignPermissions 为角色分配权限
-func (s *roleService) AssignPermissions(roleID uint, permissionIDs []uint) error {
+func (s *roleService) AssignPermissions(ctx context.Context, roleID uint, permissionIDs []uint) error {
 	if roleID == 0 {
 		return errors.New("角色ID不能为空")
 	}
@@ -172,7 +168,7 @@ func (s *roleService) AssignPermissions(roleID uint, permissionIDs []uint) error
 		}
 	}
 
-	return s.roleRepo.AssignPermissions(roleID, permissionIDs)
+	return s.roleRepo.AssignPermissions(ctx, roleID, permissionIDs)
 }
 
 // GetRolePermissions 获取角色的权限列表
@@ -188,4 +184,71 @@ func (s *roleService) GetRolePermissions(roleID uint) ([]*model.Permission, erro
 	}
 
 	return s.roleRepo.GetRolePermissions(roleID)
-}
\ No newline at end of file
+}
+
+// History 获取角色的修订记录
+func (s *roleService) History(roleID uint, page pagination.Pagination) ([]*model.Revision, int64, error) {
+	if roleID == 0 {
+		return nil, 0, errors.New("角色ID不能为空")
+	}
+	return s.revisionRepo.List(model.RevisionEntityRole, roleID, page)
+}
+
+// Rollback 将角色恢复为指定修订号之后的状态，rev 为0时恢复为第一条修订记录之前的状态
+// 回滚本身也会写入一条修订记录，调用方需在回滚后重新同步RBAC策略
+func (s *roleService) Rollback(ctx context.Context, roleID uint, rev int) (*model.RoleRollbackResult, error) {
+	if roleID == 0 {
+		return nil, errors.New("角色ID不能为空")
+	}
+	if rev < 0 {
+		return nil, errors.New("修订号无效")
+	}
+
+	role, err := s.roleRepo.GetByID(roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 读取目标状态的快照
+	var raw json.RawMessage
+	if rev == 0 {
+		revision, err := s.revisionRepo.GetByRev(model.RevisionEntityRole, roleID, 1)
+		if err != nil {
+			return nil, err
+		}
+		raw = revision.Before
+	} else {
+		revision, err := s.revisionRepo.GetByRev(model.RevisionEntityRole, roleID, rev)
+		if err != nil {
+			return nil, err
+		}
+		raw = revision.After
+	}
+	var snapshot model.RoleSnapshot
+	if err := json.Unmarshal(raw, &snapshot); err != nil || snapshot.Name == "" {
+		return nil, errors.New("修订记录中没有可恢复的快照")
+	}
+
+	// 恢复角色名称前检查是否与其他角色冲突
+	if snapshot.Name != role.Name {
+		if _, err := s.roleRepo.GetByName(snapshot.Name); err == nil {
+			return nil, fmt.Errorf("角色名称 %s 已被其他角色使用", snapshot.Name)
+		}
+	}
+
+	result := &model.RoleRollbackResult{MissingPermissionIDs: []uint{}}
+	for _, permissionID := range snapshot.PermissionIDs {
+		if _, err := s.permissionRepo.GetByID(permissionID); err != nil {
+			result.MissingPermissionIDs = append(result.MissingPermissionIDs, permissionID)
+		}
+	}
+
+	if err := s.roleRepo.Restore(ctx, roleID, &snapshot); err != nil {
+		return nil, fmt.Errorf("回滚角色失败: %w", err)
+	}
+
+	if result.Role, err = s.roleRepo.GetByID(roleID); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
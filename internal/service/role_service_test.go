@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"domain-admin/internal/repository"
+	"domain-admin/model"
+	"domain-admin/pkg/pagination"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func newTestRoleService(t *testing.T) (RoleService, *gorm.DB, []*model.Permission) {
+	t.Helper()
+	db := newTestDB(t, &model.Role{}, &model.Permission{}, &model.Revision{})
+	var perms []*model.Permission
+	for _, name := range []string{"domain.list", "domain.update", "domain.delete"} {
+		p := &model.Permission{Name: name, DisplayName: name, Resource: "/api/domains", Action: "GET", Status: 1}
+		if err := db.Create(p).Error; err != nil {
+			t.Fatal(err)
+		}
+		perms = append(perms, p)
+	}
+	svc := NewRoleService(
+		repository.NewRoleRepository(db),
+		repository.NewPermissionRepository(db),
+		repository.NewRevisionRepository(db),
+	)
+	return svc, db, perms
+}
+
+// actorContext 修订记录从请求上下文中读取操作人
+func actorContext() context.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("PUT", "/api/roles/1", nil)
+	c.Set("userID", uint(9))
+	c.Set("username", "alice")
+	return c
+}
+
+func rolePermissionIDs(t *testing.T, svc RoleService, roleID uint) []uint {
+	t.Helper()
+	perms, err := svc.GetRolePermissions(roleID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]uint, 0, len(perms))
+	for _, p := range perms {
+		ids = append(ids, p.ID)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// newTestRoleHistory 创建角色并依次分配权限、修改角色、再次分配权限，产生3条修订记录
+func newTestRoleHistory(t *testing.T, svc RoleService, perms []*model.Permission) *model.Role {
+	t.Helper()
+	ctx := actorContext()
+	role := &model.Role{Name: "ops", DisplayName: "运维", Level: 50, Status: 1}
+	if err := svc.Create(role); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.AssignPermissions(ctx, role.ID, []uint{perms[0].ID, perms[1].ID}); err != nil {
+		t.Fatal(err)
+	}
+	role.DisplayName, role.Level = "值班运维", 60
+	if err := svc.Update(ctx, role); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.AssignPermissions(ctx, role.ID, []uint{perms[2].ID}); err != nil {
+		t.Fatal(err)
+	}
+	return role
+}
+
+func TestRoleRevisions(t *testing.T) {
+	svc, _, perms := newTestRoleService(t)
+	role := newTestRoleHistory(t, svc, perms)
+
+	// 修订记录按修订号倒序返回
+	revisions, total, err := svc.History(role.ID, pagination.Pagination{Limit: 10})
+	if err != nil || total != 3 || len(revisions) != 3 {
+		t.Fatalf("History = %d, %v", total, err)
+	}
+	actions := []string{model.RevisionActionAssignPermissions, model.RevisionActionUpdate, model.RevisionActionAssignPermissions}
+	for i, r := range revisions {
+		if r.Rev != 3-i || r.Action != actions[2-i] || r.ActorID != 9 || r.ActorName != "alice" {
+			t.Fatalf("revision %d = %+v", i, r)
+		}
+	}
+	// 修改角色字段的修订记录包含权限集合
+	update := string(revisions[1].After)
+	if !strings.Contains(update, `"display_name":"值班运维"`) || !strings.Contains(update, `"permissions":["domain.list","domain.update"]`) {
+		t.Fatalf("update revision = %s", update)
+	}
+}
+
+func TestRoleRollback(t *testing.T) {
+	svc, _, perms := newTestRoleService(t)
+	role := newTestRoleHistory(t, svc, perms)
+	ctx := actorContext()
+
+	// 恢复到第1条修订之后：原名称和前两个权限
+	result, err := svc.Rollback(ctx, role.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Role.DisplayName != "运维" || result.Role.Level != 50 || len(result.MissingPermissionIDs) != 0 {
+		t.Fatalf("rollback to rev 1 = %+v", result)
+	}
+	if got, want := rolePermissionIDs(t, svc, role.ID), []uint{perms[0].ID, perms[1].ID}; !slices.Equal(got, want) {
+		t.Fatalf("permissions = %v, want %v", got, want)
+	}
+
+	// 回滚本身写入修订记录，可以再回滚
+	revisions, total, _ := svc.History(role.ID, pagination.Pagination{Limit: 10})
+	if total != 4 || revisions[0].Action != model.RevisionActionRollback {
+		t.Fatalf("latest revision = %+v (total %d)", revisions[0], total)
+	}
+	if _, err := svc.Rollback(ctx, role.ID, 3); err != nil {
+		t.Fatal(err)
+	}
+	if got := rolePermissionIDs(t, svc, role.ID); !slices.Equal(got, []uint{perms[2].ID}) {
+		t.Fatalf("permissions after rollback to rev 3 = %v", got)
+	}
+
+	// 修订号0恢复为第一次修改之前的状态
+	result, err = svc.Rollback(ctx, role.ID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Role.DisplayName != "运维" || len(rolePermissionIDs(t, svc, role.ID)) != 0 {
+		t.Fatalf("rollback to rev 0 = %+v", result.Role)
+	}
+
+	for _, rev := range []int{-1, 99} {
+		if _, err := svc.Rollback(ctx, role.ID, rev); err == nil {
+			t.Fatalf("rollback to rev %d succeeded", rev)
+		}
+	}
+}
+
+func TestRoleRollbackMissingPermissions(t *testing.T) {
+	svc, db, perms := newTestRoleService(t)
+	role := newTestRoleHistory(t, svc, perms)
+	if err := db.Delete(&model.Permission{}, perms[1].ID).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// 已删除的权限不会恢复，在结果中列出
+	result, err := svc.Rollback(actorContext(), role.ID, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(result.MissingPermissionIDs, []uint{perms[1].ID}) {
+		t.Fatalf("missing permissions = %v", result.MissingPermissionIDs)
+	}
+	if got := rolePermissionIDs(t, svc, role.ID); !slices.Equal(got, []uint{perms[0].ID}) {
+		t.Fatalf("permissions = %v", got)
+	}
+}
+
+func TestRoleRollbackNameConflict(t *testing.T) {
+	svc, _, perms := newTestRoleService(t)
+	role := newTestRoleHistory(t, svc, perms)
+	ctx := actorContext()
+
+	// 改名后原名称被其他角色使用，不能回滚到原名称
+	role.Name = "sre"
+	if err := svc.Update(ctx, role); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Create(&model.Role{Name: "ops", DisplayName: "新运维", Status: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Rollback(ctx, role.ID, 1); err == nil || !strings.Contains(err.Error(), "已被其他角色使用") {
+		t.Fatalf("rollback with name conflict: %v", err)
+	}
+
+	current, _ := svc.GetByID(role.ID)
+	if current.Name != "sre" || current.DisplayName != "值班运维" {
+		t.Fatalf("role changed by refused rollback: %+v", current)
+	}
+	if _, total, _ := svc.History(role.ID, pagination.Pagination{Limit: 10}); total != 4 {
+		t.Fatalf("revisions = %d, want 4", total)
+	}
+}
@@ -0,0 +1,731 @@
+package service
+
+import (
+	"context"
+	"domain-admin/internal/repository"
+	"domain-admin/model"
+	"domain-admin/pkg/cache"
+	"domain-admin/pkg/logger"
+	"domain-admin/pkg/scim"
+	"domain-admin/pkg/validator"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// scimProvider SCIM 的 externalId 在外部账号关联中的身份提供方名称
+	scimProvider = "scim"
+	// scimDefaultRole 从用户组移除的用户恢复为的角色
+	scimDefaultRole = "user"
+	// scimAdminRole 不能通过SCIM分配或移除的管理员角色
+	scimAdminRole = "admin"
+	// scimMaxRoleName 用户组名称即角色名称，受用户表中角色字段长度限制
+	scimMaxRoleName = 20
+)
+
+// SCIMService SCIM 2.0 用户和用户组管理接口，用户组对应角色，每个用户只属于一个角色
+type SCIMService interface {
+	ListUsers(baseURL, filter string, page scim.Page) (*scim.ListResponse, error)
+	GetUser(baseURL, id string) (*model.SCIMUser, error)
+	CreateUser(baseURL string, req *model.SCIMUser) (*model.SCIMUser, error)
+	ReplaceUser(baseURL, id string, req *model.SCIMUser) (*model.SCIMUser, error)
+	PatchUser(baseURL, id string, req *scim.PatchRequest) (*model.SCIMUser, error)
+	DeleteUser(id string) error
+
+	ListGroups(baseURL, filter string, page scim.Page) (*scim.ListResponse, error)
+	GetGroup(baseURL, id string) (*model.SCIMGroup, error)
+	CreateGroup(baseURL string, req *model.SCIMGroup) (*model.SCIMGroup, error)
+	ReplaceGroup(baseURL, id string, req *model.SCIMGroup) (*model.SCIMGroup, error)
+	PatchGroup(baseURL, id string, req *scim.PatchRequest) (*model.SCIMGroup, error)
+	DeleteGroup(id string) error
+}
+
+type scimService struct {
+	userRepo     repository.UserRepository
+	roleRepo     repository.RoleRepository
+	identityRepo repository.UserIdentityRepository
+	tokenService AuthTokenService
+}
+
+// NewSCIMService 创建SCIM服务实例，tokenService 用于吊销被禁用或删除的用户的会话
+func NewSCIMService(userRepo repository.UserRepository, roleRepo repository.RoleRepository, identityRepo repository.UserIdentityRepository, tokenService AuthTokenService) SCIMService {
+	return &scimService{userRepo: userRepo, roleRepo: roleRepo, identityRepo: identityRepo, tokenService: tokenService}
+}
+
+// scimUserFields 从 SCIM 用户资源映射到本地用户的字段
+type scimUserFields struct {
+	Username string `validate:"required,min=3,max=50"`
+	Email    string `validate:"required,email,max=100"`
+	Nickname string `validate:"max=50"`
+	Phone    string `validate:"max=20"`
+	Password string `validate:"omitempty,min=6,max=72"`
+}
+
+// ListUsers 查询用户，按 filter 过滤后分页
+func (s *scimService) ListUsers(baseURL, filter string, page scim.Page) (*scim.ListResponse, error) {
+	match, err := parseSCIMFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	users, err := s.userRepo.ListAll()
+	if err != nil {
+		return nil, err
+	}
+	externalIDs, roleIDs, err := s.userLookups()
+	if err != nil {
+		return nil, err
+	}
+
+	resources := []interface{}{}
+	for _, user := range users {
+		resource := toSCIMUser(baseURL, user, externalIDs[user.ID], roleIDs)
+		if ok, err := matchSCIM(match, resource); err != nil {
+			return nil, err
+		} else if ok {
+			resources = append(resources, resource)
+		}
+	}
+	return scim.NewListResponse(resources, page), nil
+}
+
+// GetUser 获取用户
+func (s *scimService) GetUser(baseURL, id string) (*model.SCIMUser, error) {
+	user, err := s.findUser(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.userResource(baseURL, user)
+}
+
+// CreateUser 创建用户。没有提供密码时使用随机密码，用户只能通过单点登录登录
+func (s *scimService) CreateUser(baseURL string, req *model.SCIMUser) (*model.SCIMUser, error) {
+	fields, err := scimUserFromResource(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkUsername(fields.Username, 0); err != nil {
+		return nil, err
+	}
+	if _, err := s.userRepo.GetByEmail(fields.Email); err == nil {
+		return nil, scim.Conflict("邮箱 %s 已存在", fields.Email)
+	}
+	if req.ExternalID != "" {
+		if _, err := s.identityRepo.GetIdentity(scimProvider, req.ExternalID); err == nil {
+			return nil, scim.Conflict("externalId %s 已关联其他用户", req.ExternalID)
+		}
+	}
+
+	user := &model.User{
+		Username: fields.Username,
+		Email:    fields.Email,
+		Nickname: fields.Nickname,
+		Phone:    fields.Phone,
+		Role:     scimDefaultRole,
+	}
+	if err := createExternalUser(s.userRepo, user); err != nil {
+		return nil, err
+	}
+	if fields.Password != "" {
+		if err := s.setPassword(user, fields.Password); err != nil {
+			return nil, err
+		}
+	}
+	if req.Active != nil && !*req.Active {
+		if err := s.userRepo.UpdateStatus(user.ID, 0); err != nil {
+			logger.Errorf("禁用SCIM用户失败: %v", err)
+		}
+		user.Status = 0
+	}
+	if err := s.saveExternalID(user, req.ExternalID); err != nil {
+		return nil, err
+	}
+
+	logger.Infof("SCIM创建用户 %s", user.Username)
+	return s.userResource(baseURL, user)
+}
+
+// ReplaceUser 使用完整的资源替换用户，未提供 active 时保持原状态
+func (s *scimService) ReplaceUser(baseURL, id string, req *model.SCIMUser) (*model.SCIMUser, error) {
+	user, err := s.findUser(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.updateUser(baseURL, user, req)
+}
+
+// PatchUser 对用户的 SCIM 表示应用 PATCH 操作后按替换处理
+func (s *scimService) PatchUser(baseURL, id string, req *scim.PatchRequest) (*model.SCIMUser, error) {
+	user, err := s.findUser(id)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.userResource(baseURL, user)
+	if err != nil {
+		return nil, err
+	}
+	var patched model.SCIMUser
+	if err := applySCIMPatch(current, req, &patched); err != nil {
+		return nil, err
+	}
+	return s.updateUser(baseURL, user, &patched)
+}
+
+// DeleteUser 删除用户并吊销其会话
+func (s *scimService) DeleteUser(id string) error {
+	user, err := s.findUser(id)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.Delete(user.ID); err != nil {
+		logger.Errorf("删除用户失败: %v", err)
+		return errors.New("删除用户失败")
+	}
+	if identity, err := s.identityRepo.GetByUser(scimProvider, user.ID); err == nil {
+		if err := s.identityRepo.DeleteIdentity(identity); err != nil {
+			logger.Warnf("删除用户 %s 的SCIM关联失败: %v", user.Username, err)
+		}
+	}
+	s.afterUserChange(user.ID, true)
+	logger.Infof("SCIM删除用户 %s", user.Username)
+	return nil
+}
+
+// updateUser 按 SCIM 资源更新用户，用户被禁用时吊销其会话
+func (s *scimService) updateUser(baseURL string, user *model.User, req *model.SCIMUser) (*model.SCIMUser, error) {
+	fields, err := scimUserFromResource(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkUsername(fields.Username, user.ID); err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(fields.Email, user.Email) {
+		if other, err := s.userRepo.GetByEmail(fields.Email); err == nil && other.ID != user.ID {
+			return nil, scim.Conflict("邮箱 %s 已存在", fields.Email)
+		}
+	}
+
+	wasActive := user.Status == 1
+	user.Username = fields.Username
+	user.Email = fields.Email
+	user.Nickname = fields.Nickname
+	user.Phone = fields.Phone
+	if req.Active != nil {
+		user.Status = 0
+		if *req.Active {
+			user.Status = 1
+		}
+	}
+	if err := s.userRepo.Update(user); err != nil {
+		logger.Errorf("更新SCIM用户失败: %v", err)
+		return nil, errors.New("更新用户失败")
+	}
+	if fields.Password != "" {
+		if err := s.setPassword(user, fields.Password); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.saveExternalID(user, req.ExternalID); err != nil {
+		return nil, err
+	}
+	s.afterUserChange(user.ID, wasActive && user.Status != 1)
+	if wasActive && user.Status != 1 {
+		logger.Infof("SCIM禁用用户 %s", user.Username)
+	}
+	return s.userResource(baseURL, user)
+}
+
+// setPassword 设置用户提供的密码
+// checkUsername 检查用户名是否被其他用户使用，SCIM 的 userName 不区分大小写
+func (s *scimService) checkUsername(username string, userID uint) error {
+	users, err := s.userRepo.ListAll()
+	if err != nil {
+		return err
+	}
+	for _, other := range users {
+		if other.ID != userID && strings.EqualFold(other.Username, username) {
+			return scim.Conflict("用户名 %s 已存在", other.Username)
+		}
+	}
+	return nil
+}
+
+func (s *scimService) setPassword(user *model.User, password string) error {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		logger.Errorf("密码加密失败: %v", err)
+		return errors.New("密码加密失败")
+	}
+	user.Password = string(hashed)
+	if err := s.userRepo.Update(user); err != nil {
+		logger.Errorf("更新用户密码失败: %v", err)
+		return errors.New("更新用户密码失败")
+	}
+	return nil
+}
+
+// saveExternalID 保存、修改或删除用户的 externalId
+func (s *scimService) saveExternalID(user *model.User, externalID string) error {
+	identity, err := s.identityRepo.GetByUser(scimProvider, user.ID)
+	if err != nil {
+		identity = nil
+	}
+	switch {
+	case externalID == "" && identity == nil:
+		return nil
+	case externalID == "":
+		return s.identityRepo.DeleteIdentity(identity)
+	case identity != nil && identity.Subject == externalID:
+		return nil
+	}
+
+	if other, err := s.identityRepo.GetIdentity(scimProvider, externalID); err == nil && other.UserID != user.ID {
+		return scim.Conflict("externalId %s 已关联其他用户", externalID)
+	}
+	if identity == nil {
+		identity = &model.UserIdentity{UserID: user.ID, Provider: scimProvider}
+	}
+	identity.Subject = externalID
+	identity.Email = user.Email
+	if err := s.identityRepo.SaveIdentity(identity); err != nil {
+		logger.Errorf("保存SCIM关联失败: %v", err)
+		return errors.New("保存externalId失败")
+	}
+	return nil
+}
+
+// afterUserChange 清除用户缓存，revoke 为 true 时吊销用户的全部会话
+func (s *scimService) afterUserChange(userID uint, revoke bool) {
+	ctx := context.Background()
+	if err := cache.DelUserCache(ctx, userID); err != nil {
+		logger.Warnf("删除用户缓存失败: %v", err)
+	}
+	if err := cache.DelUserListCache(ctx, "*"); err != nil {
+		logger.Warnf("清除用户列表缓存失败: %v", err)
+	}
+	if revoke {
+		if _, err := s.tokenService.RevokeUser(userID); err != nil {
+			logger.Warnf("吊销用户 %d 的会话失败: %v", userID, err)
+		}
+	}
+}
+
+func (s *scimService) findUser(id string) (*model.User, error) {
+	userID, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return nil, scim.NotFound("用户 %s 不存在", id)
+	}
+	user, err := s.userRepo.GetByID(uint(userID))
+	if err != nil {
+		return nil, scim.NotFound("用户 %s 不存在", id)
+	}
+	return user, nil
+}
+
+func (s *scimService) userResource(baseURL string, user *model.User) (*model.SCIMUser, error) {
+	externalID := ""
+	if identity, err := s.identityRepo.GetByUser(scimProvider, user.ID); err == nil {
+		externalID = identity.Subject
+	}
+	roleIDs := map[string]uint{}
+	if role, err := s.roleRepo.GetByName(user.Role); err == nil {
+		roleIDs[role.Name] = role.ID
+	}
+	return toSCIMUser(baseURL, user, externalID, roleIDs), nil
+}
+
+// userLookups 返回用户ID到 externalId 和角色名称到角色ID的映射
+func (s *scimService) userLookups() (map[uint]string, map[string]uint, error) {
+	identities, err := s.identityRepo.ListByProvider(scimProvider)
+	if err != nil {
+		return nil, nil, err
+	}
+	externalIDs := make(map[uint]string, len(identities))
+	for _, identity := range identities {
+		externalIDs[identity.UserID] = identity.Subject
+	}
+	roles, err := s.roleRepo.ListAll()
+	if err != nil {
+		return nil, nil, err
+	}
+	roleIDs := make(map[string]uint, len(roles))
+	for _, role := range roles {
+		roleIDs[role.Name] = role.ID
+	}
+	return externalIDs, roleIDs, nil
+}
+
+// ListGroups 查询用户组，按 filter 过滤后分页
+func (s *scimService) ListGroups(baseURL, filter string, page scim.Page) (*scim.ListResponse, error) {
+	match, err := parseSCIMFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := s.roleRepo.ListAll()
+	if err != nil {
+		return nil, err
+	}
+	users, err := s.userRepo.ListAll()
+	if err != nil {
+		return nil, err
+	}
+
+	resources := []interface{}{}
+	for _, role := range roles {
+		resource := toSCIMGroup(baseURL, role, users)
+		if ok, err := matchSCIM(match, resource); err != nil {
+			return nil, err
+		} else if ok {
+			resources = append(resources, resource)
+		}
+	}
+	return scim.NewListResponse(resources, page), nil
+}
+
+// GetGroup 获取用户组
+func (s *scimService) GetGroup(baseURL, id string) (*model.SCIMGroup, error) {
+	role, err := s.findRole(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.groupResource(baseURL, role)
+}
+
+// CreateGroup 创建用户组，即创建没有任何权限的角色，并将成员设置为该角色
+func (s *scimService) CreateGroup(baseURL string, req *model.SCIMGroup) (*model.SCIMGroup, error) {
+	name := strings.TrimSpace(req.DisplayName)
+	if name == "" || len([]rune(name)) > scimMaxRoleName {
+		return nil, scim.BadRequest(scim.ErrInvalidValue, "displayName 不能为空且不能超过 %d 个字符", scimMaxRoleName)
+	}
+	if _, err := s.roleRepo.GetByName(name); err == nil {
+		return nil, scim.Conflict("角色 %s 已存在", name)
+	}
+	members, err := s.memberUsers(req.Members)
+	if err != nil {
+		return nil, err
+	}
+
+	role := &model.Role{Name: name, DisplayName: name, Description: "由SCIM创建", Status: 1}
+	if err := s.roleRepo.Create(role); err != nil {
+		logger.Errorf("创建SCIM角色失败: %v", err)
+		return nil, errors.New("创建角色失败")
+	}
+	if err := s.setMembers(role, members); err != nil {
+		return nil, err
+	}
+	logger.Infof("SCIM创建角色 %s，成员 %d 个", role.Name, len(members))
+	return s.groupResource(baseURL, role)
+}
+
+// ReplaceGroup 替换用户组成员，不能修改 displayName（角色名称）
+func (s *scimService) ReplaceGroup(baseURL, id string, req *model.SCIMGroup) (*model.SCIMGroup, error) {
+	role, err := s.findRole(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.updateGroup(baseURL, role, req)
+}
+
+// PatchGroup 对用户组的 SCIM 表示应用 PATCH 操作后按替换处理
+func (s *scimService) PatchGroup(baseURL, id string, req *scim.PatchRequest) (*model.SCIMGroup, error) {
+	role, err := s.findRole(id)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.groupResource(baseURL, role)
+	if err != nil {
+		return nil, err
+	}
+	var patched model.SCIMGroup
+	if err := applySCIMPatch(current, req, &patched); err != nil {
+		return nil, err
+	}
+	return s.updateGroup(baseURL, role, &patched)
+}
+
+// DeleteGroup 删除用户组对应的角色，成员恢复为默认角色。系统内置角色不能删除
+func (s *scimService) DeleteGroup(id string) error {
+	role, err := s.findRole(id)
+	if err != nil {
+		return err
+	}
+	if role.Name == scimAdminRole || role.Name == scimDefaultRole || role.Name == "guest" {
+		return &scim.Error{Status: http.StatusBadRequest, ScimType: scim.ErrMutability, Detail: "系统内置角色不允许删除"}
+	}
+	if err := s.setMembers(role, nil); err != nil {
+		return err
+	}
+	if err := s.roleRepo.Delete(role.ID); err != nil {
+		logger.Errorf("删除SCIM角色失败: %v", err)
+		return errors.New("删除角色失败")
+	}
+	logger.Infof("SCIM删除角色 %s", role.Name)
+	return nil
+}
+
+func (s *scimService) updateGroup(baseURL string, role *model.Role, req *model.SCIMGroup) (*model.SCIMGroup, error) {
+	if name := strings.TrimSpace(req.DisplayName); name != role.Name {
+		return nil, &scim.Error{Status: http.StatusBadRequest, ScimType: scim.ErrMutability, Detail: "不能修改用户组的 displayName（角色名称）"}
+	}
+	members, err := s.memberUsers(req.Members)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.setMembers(role, members); err != nil {
+		return nil, err
+	}
+	return s.groupResource(baseURL, role)
+}
+
+// memberUsers 按成员的 value（用户ID）查找用户
+func (s *scimService) memberUsers(members []model.SCIMMemberValue) ([]*model.User, error) {
+	users := make([]*model.User, 0, len(members))
+	seen := map[uint]bool{}
+	for _, member := range members {
+		user, err := s.findUser(member.Value)
+		if err != nil {
+			return nil, scim.BadRequest(scim.ErrInvalidValue, "成员 %s 不存在", member.Value)
+		}
+		if !seen[user.ID] {
+			seen[user.ID] = true
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+// setMembers 将成员的角色设置为该角色，不再是成员的用户恢复为默认角色
+func (s *scimService) setMembers(role *model.Role, members []*model.User) error {
+	users, err := s.userRepo.ListAll()
+	if err != nil {
+		return err
+	}
+	wanted := make(map[uint]bool, len(members))
+	for _, member := range members {
+		wanted[member.ID] = true
+	}
+
+	// 先计算全部变更，避免部分成员修改后才发现涉及管理员
+	targets := make(map[uint]string)
+	for _, user := range users {
+		target := user.Role
+		switch {
+		case wanted[user.ID]:
+			target = role.Name
+		case user.Role == role.Name && role.Name != scimDefaultRole:
+			target = scimDefaultRole
+		}
+		if target == user.Role {
+			continue
+		}
+		// 管理员角色只能在系统内分配，防止身份提供方的用户组配置误降级或提升管理员
+		if user.Role == scimAdminRole || target == scimAdminRole {
+			return &scim.Error{Status: http.StatusBadRequest, ScimType: scim.ErrMutability, Detail: fmt.Sprintf("不能通过SCIM修改用户 %s 的管理员角色", user.Username)}
+		}
+		targets[user.ID] = target
+	}
+
+	for _, user := range users {
+		target, ok := targets[user.ID]
+		if !ok {
+			continue
+		}
+		logger.Infof("SCIM将用户 %s 的角色从 %s 修改为 %s", user.Username, user.Role, target)
+		user.Role = target
+		if err := s.userRepo.Update(user); err != nil {
+			logger.Errorf("更新用户角色失败: %v", err)
+			return fmt.Errorf("更新用户 %s 的角色失败", user.Username)
+		}
+		s.afterUserChange(user.ID, false)
+	}
+	return nil
+}
+
+func (s *scimService) findRole(id string) (*model.Role, error) {
+	roleID, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return nil, scim.NotFound("用户组 %s 不存在", id)
+	}
+	role, err := s.roleRepo.GetByID(uint(roleID))
+	if err != nil {
+		return nil, scim.NotFound("用户组 %s 不存在", id)
+	}
+	return role, nil
+}
+
+func (s *scimService) groupResource(baseURL string, role *model.Role) (*model.SCIMGroup, error) {
+	users, err := s.userRepo.ListAll()
+	if err != nil {
+		return nil, err
+	}
+	return toSCIMGroup(baseURL, role, users), nil
+}
+
+// scimUserFromResource 校验 SCIM 用户资源并取出本地用户字段。
+// 使用主邮箱（没有时使用第一个邮箱）；昵称依次取 displayName、name.formatted 和 givenName familyName
+func scimUserFromResource(req *model.SCIMUser) (*scimUserFields, error) {
+	fields := &scimUserFields{
+		Username: strings.TrimSpace(req.UserName),
+		Email:    strings.TrimSpace(primaryValue(req.Emails)),
+		Phone:    strings.TrimSpace(primaryValue(req.PhoneNumbers)),
+		Nickname: strings.TrimSpace(req.DisplayName),
+		Password: req.Password,
+	}
+	if fields.Nickname == "" && req.Name != nil {
+		fields.Nickname = strings.TrimSpace(req.Name.Formatted)
+		if fields.Nickname == "" {
+			fields.Nickname = strings.TrimSpace(req.Name.GivenName + " " + req.Name.FamilyName)
+		}
+	}
+	fields.Nickname = truncateRunes(fields.Nickname, 50)
+	if fields.Username == "" {
+		return nil, scim.BadRequest(scim.ErrInvalidValue, "缺少 userName")
+	}
+	if fields.Email == "" {
+		return nil, scim.BadRequest(scim.ErrInvalidValue, "缺少 emails，用户必须有邮箱")
+	}
+	if err := validator.ValidateStruct(fields); err != nil {
+		return nil, scim.BadRequest(scim.ErrInvalidValue, "用户属性无效: %v", err)
+	}
+	return fields, nil
+}
+
+func primaryValue(values []model.SCIMMultiValue) string {
+	for _, v := range values {
+		if v.Primary {
+			return v.Value
+		}
+	}
+	if len(values) > 0 {
+		return values[0].Value
+	}
+	return ""
+}
+
+func toSCIMUser(baseURL string, user *model.User, externalID string, roleIDs map[string]uint) *model.SCIMUser {
+	id := strconv.FormatUint(uint64(user.ID), 10)
+	active := user.Status == 1
+	resource := &model.SCIMUser{
+		Schemas:     []string{scim.SchemaUser},
+		ID:          id,
+		ExternalID:  externalID,
+		UserName:    user.Username,
+		DisplayName: user.Nickname,
+		Emails:      []model.SCIMMultiValue{{Value: user.Email, Type: "work", Primary: true}},
+		Active:      &active,
+		Meta: &model.SCIMMeta{
+			ResourceType: "User",
+			Created:      user.CreatedAt,
+			LastModified: user.UpdatedAt,
+			Location:     baseURL + "/Users/" + id,
+		},
+	}
+	if user.Nickname != "" {
+		resource.Name = &model.SCIMName{Formatted: user.Nickname}
+	}
+	if user.Phone != "" {
+		resource.PhoneNumbers = []model.SCIMMultiValue{{Value: user.Phone, Type: "work"}}
+	}
+	if roleID, ok := roleIDs[user.Role]; ok {
+		groupID := strconv.FormatUint(uint64(roleID), 10)
+		resource.Groups = []model.SCIMMemberValue{{Value: groupID, Display: user.Role, Ref: baseURL + "/Groups/" + groupID}}
+	}
+	return resource
+}
+
+func toSCIMGroup(baseURL string, role *model.Role, users []*model.User) *model.SCIMGroup {
+	id := strconv.FormatUint(uint64(role.ID), 10)
+	resource := &model.SCIMGroup{
+		Schemas:     []string{scim.SchemaGroup},
+		ID:          id,
+		DisplayName: role.Name,
+		Meta: &model.SCIMMeta{
+			ResourceType: "Group",
+			Created:      role.CreatedAt,
+			LastModified: role.UpdatedAt,
+			Location:     baseURL + "/Groups/" + id,
+		},
+	}
+	for _, user := range users {
+		if user.Role != role.Name {
+			continue
+		}
+		userID := strconv.FormatUint(uint64(user.ID), 10)
+		resource.Members = append(resource.Members, model.SCIMMemberValue{Value: userID, Display: user.Username, Ref: baseURL + "/Users/" + userID})
+	}
+	return resource
+}
+
+func parseSCIMFilter(filter string) (scim.Filter, error) {
+	if strings.TrimSpace(filter) == "" {
+		return nil, nil
+	}
+	match, err := scim.ParseFilter(filter)
+	if err != nil {
+		return nil, scim.BadRequest(scim.ErrInvalidFilter, "过滤条件无效: %v", err)
+	}
+	return match, nil
+}
+
+// matchSCIM 对资源的 JSON 表示求值过滤条件，match 为空时全部匹配
+func matchSCIM(match scim.Filter, resource interface{}) (bool, error) {
+	if match == nil {
+		return true, nil
+	}
+	m, err := toSCIMMap(resource)
+	if err != nil {
+		return false, err
+	}
+	return match.Match(m), nil
+}
+
+// applySCIMPatch 对资源的 JSON 表示依次应用 PATCH 操作，结果写入 out
+func applySCIMPatch(current interface{}, req *scim.PatchRequest, out interface{}) error {
+	if len(req.Operations) == 0 {
+		return scim.BadRequest(scim.ErrInvalidSyntax, "缺少 Operations")
+	}
+	m, err := toSCIMMap(current)
+	if err != nil {
+		return err
+	}
+	for _, op := range req.Operations {
+		if err := scim.Apply(m, op); err != nil {
+			return err
+		}
+	}
+	// 部分身份提供方以字符串提交 active
+	for key, v := range m {
+		if str, ok := v.(string); ok && strings.EqualFold(key, "active") {
+			active, err := strconv.ParseBool(str)
+			if err != nil {
+				return scim.BadRequest(scim.ErrInvalidValue, "active 必须是布尔值")
+			}
+			m[key] = active
+		}
+	}
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return scim.BadRequest(scim.ErrInvalidValue, "PATCH 后的资源无效: %v", err)
+	}
+	return nil
+}
+
+func toSCIMMap(resource interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(resource)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	err = json.Unmarshal(data, &m)
+	return m, err
+}
@@ -0,0 +1,279 @@
+package service
+
+import (
+	"context"
+	"domain-admin/internal/repository"
+	"domain-admin/model"
+	"domain-admin/pkg/logger"
+	"domain-admin/pkg/pagination"
+	"domain-admin/pkg/provider"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// SyncService 云服务商区域同步服务接口
+type SyncService interface {
+	Run(ctx context.Context, account string, triggeredBy uint) (*model.SyncRun, error)
+	RunAll(ctx context.Context, triggeredBy uint) []*model.SyncRun
+	GetByID(id uint) (*model.SyncRun, error)
+	List(account string, page pagination.Pagination) ([]*model.SyncRun, int64, error)
+}
+
+type syncService struct {
+	runRepo repository.SyncRunRepository
+}
+
+// runningAccounts 正在同步的账号，防止同一账号并发同步
+var (
+	runningAccounts = make(map[string]bool)
+	runningMux      sync.Mutex
+)
+
+// NewSyncService 创建同步服务实例
+func NewSyncService(runRepo repository.SyncRunRepository) SyncService {
+	return &syncService{runRepo: runRepo}
+}
+
+// RunAll 依次同步所有已配置的账号，单个账号失败不影响其他账号
+func (s *syncService) RunAll(ctx context.Context, triggeredBy uint) []*model.SyncRun {
+	var runs []*model.SyncRun
+	for _, account := range provider.Accounts() {
+		run, err := s.Run(ctx, account, triggeredBy)
+		if run != nil {
+			runs = append(runs, run)
+		}
+		if err != nil {
+			logger.Warnf("同步账号 %s 失败: %v", account, err)
+		}
+	}
+	return runs
+}
+
+// Run 同步单个账号，并与该账号上一次成功同步的快照对比
+func (s *syncService) Run(ctx context.Context, account string, triggeredBy uint) (*model.SyncRun, error) {
+	client, err := provider.GetProvider(account)
+	if err != nil {
+		return nil, fmt.Errorf("云服务商账号不存在: %s", account)
+	}
+
+	runningMux.Lock()
+	if runningAccounts[account] {
+		runningMux.Unlock()
+		return nil, fmt.Errorf("账号 %s 正在同步中", account)
+	}
+	runningAccounts[account] = true
+	runningMux.Unlock()
+	defer func() {
+		runningMux.Lock()
+		delete(runningAccounts, account)
+		runningMux.Unlock()
+	}()
+
+	run := &model.SyncRun{
+		ProviderAccount: account,
+		Status:          model.SyncRunStatusRunning,
+		TriggeredBy:     triggeredBy,
+		StartedAt:       time.Now(),
+	}
+	if err := s.runRepo.Create(run); err != nil {
+		logger.Errorf("创建同步任务失败: %v", err)
+		return nil, errors.New("创建同步任务失败")
+	}
+
+	snapshots, err := fetchSnapshots(ctx, client, account, run.ID)
+	if err != nil {
+		return run, s.fail(run, err)
+	}
+
+	previous, err := s.previousSnapshots(account, run.ID)
+	if err != nil {
+		return run, s.fail(run, err)
+	}
+
+	var changes []*model.SyncChange
+	for _, snapshot := range snapshots {
+		run.RecordCount += snapshot.RecordCount
+		changes = append(changes, diffSnapshot(run.ID, snapshot.ZoneName, previous[snapshot.ZoneName], snapshot.Records)...)
+		delete(previous, snapshot.ZoneName)
+	}
+	// 上次存在但本次消失的区域，其记录全部视为删除
+	for zone, records := range previous {
+		changes = append(changes, diffSnapshot(run.ID, zone, records, nil)...)
+	}
+
+	for _, change := range changes {
+		switch change.Action {
+		case model.SyncChangeAdded:
+			run.Added++
+		case model.SyncChangeRemoved:
+			run.Removed++
+		case model.SyncChangeChanged:
+			run.Changed++
+		}
+	}
+
+	finished := time.Now()
+	run.ZoneCount = len(snapshots)
+	run.Status = model.SyncRunStatusSuccess
+	run.FinishedAt = &finished
+
+	if err := s.runRepo.Complete(run, snapshots, changes); err != nil {
+		logger.Errorf("保存同步结果失败: %v", err)
+		return run, s.fail(run, errors.New("保存同步结果失败"))
+	}
+
+	logger.Infof("同步账号 %s 完成: 区域 %d, 记录 %d, 新增 %d, 删除 %d, 变更 %d",
+		account, run.ZoneCount, run.RecordCount, run.Added, run.Removed, run.Changed)
+	return run, nil
+}
+
+// GetByID 获取同步任务详情
+func (s *syncService) GetByID(id uint) (*model.SyncRun, error) {
+	if id == 0 {
+		return nil, errors.New("同步任务ID不能为空")
+	}
+	return s.runRepo.GetByID(id)
+}
+
+// List 获取同步任务列表
+func (s *syncService) List(account string, page pagination.Pagination) ([]*model.SyncRun, int64, error) {
+	return s.runRepo.List(account, page)
+}
+
+// fail 将同步任务标记为失败
+func (s *syncService) fail(run *model.SyncRun, cause error) error {
+	finished := time.Now()
+	run.Status = model.SyncRunStatusFailed
+	run.Error = cause.Error()
+	run.FinishedAt = &finished
+	run.Added, run.Removed, run.Changed = 0, 0, 0
+
+	if err := s.runRepo.Fail(run); err != nil {
+		logger.Errorf("保存同步任务状态失败: %v", err)
+	}
+	logger.Warnf("同步账号 %s 失败: %v", run.ProviderAccount, cause)
+	return cause
+}
+
+// previousSnapshots 获取账号上一次成功同步的快照，按区域名称索引
+func (s *syncService) previousSnapshots(account string, currentRunID uint) (map[string][]model.SnapshotRecord, error) {
+	result := make(map[string][]model.SnapshotRecord)
+
+	last, err := s.runRepo.LastSuccessful(account)
+	if err != nil {
+		return nil, fmt.Errorf("查询上次同步结果失败: %w", err)
+	}
+	if last == nil || last.ID == currentRunID {
+		return result, nil
+	}
+
+	snapshots, err := s.runRepo.ListSnapshots(last.ID)
+	if err != nil {
+		return nil, fmt.Errorf("查询上次同步快照失败: %w", err)
+	}
+	for _, snapshot := range snapshots {
+		result[snapshot.ZoneName] = snapshot.Records
+	}
+	return result, nil
+}
+
+// fetchSnapshots 通过服务商客户端读取账号下全部区域的记录
+func fetchSnapshots(ctx context.Context, client provider.DNSProvider, account string, runID uint) ([]*model.ZoneSnapshot, error) {
+	zones, err := client.ListZones(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取区域列表失败: %w", err)
+	}
+
+	snapshots := make([]*model.ZoneSnapshot, 0, len(zones))
+	for _, zone := range zones {
+		records, err := client.ListRecords(ctx, zone.Name)
+		if err != nil {
+			return nil, fmt.Errorf("获取区域 %s 的记录失败: %w", zone.Name, err)
+		}
+
+		snapshot := &model.ZoneSnapshot{
+			RunID:           runID,
+			ProviderAccount: account,
+			ZoneName:        provider.NormalizeZone(zone.Name),
+			RecordCount:     len(records),
+			Records:         make([]model.SnapshotRecord, 0, len(records)),
+		}
+		for _, record := range records {
+			snapshot.Records = append(snapshot.Records, model.SnapshotRecord{
+				ID:       record.ID,
+				Name:     record.Name,
+				Type:     record.Type,
+				Value:    record.Value,
+				TTL:      record.TTL,
+				Priority: record.Priority,
+			})
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, nil
+}
+
+// diffSnapshot 对比区域前后两次快照
+// 记录优先按服务商记录ID匹配，没有ID时按名称、类型和值匹配
+func diffSnapshot(runID uint, zone string, before, after []model.SnapshotRecord) []*model.SyncChange {
+	oldByKey := make(map[string]model.SnapshotRecord, len(before))
+	for _, record := range before {
+		oldByKey[snapshotKey(record)] = record
+	}
+
+	var changes []*model.SyncChange
+	newKeys := make(map[string]bool, len(after))
+	for _, record := range after {
+		key := snapshotKey(record)
+		newKeys[key] = true
+
+		current := record
+		old, ok := oldByKey[key]
+		switch {
+		case !ok:
+			changes = append(changes, newSyncChange(runID, zone, model.SyncChangeAdded, nil, &current))
+		case old != record:
+			previous := old
+			changes = append(changes, newSyncChange(runID, zone, model.SyncChangeChanged, &previous, &current))
+		}
+	}
+
+	for _, record := range before {
+		if !newKeys[snapshotKey(record)] {
+			previous := record
+			changes = append(changes, newSyncChange(runID, zone, model.SyncChangeRemoved, &previous, nil))
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].RecordName < changes[j].RecordName
+	})
+	return changes
+}
+
+func newSyncChange(runID uint, zone, action string, before, after *model.SnapshotRecord) *model.SyncChange {
+	ref := after
+	if ref == nil {
+		ref = before
+	}
+	return &model.SyncChange{
+		RunID:      runID,
+		ZoneName:   zone,
+		Action:     action,
+		RecordName: ref.Name,
+		RecordType: ref.Type,
+		Before:     before,
+		After:      after,
+	}
+}
+
+func snapshotKey(record model.SnapshotRecord) string {
+	if record.ID != "" {
+		return "id:" + record.ID
+	}
+	return record.Name + "|" + record.Type + "|" + record.Value + "|" + strconv.Itoa(record.Priority)
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"domain-admin/internal/repository"
+	"domain-admin/model"
+	"domain-admin/pkg/config"
+	"domain-admin/pkg/provider"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestSyncService 使用临时 SQLite 数据库创建同步服务
+func newTestSyncService(t *testing.T) SyncService {
+	t.Helper()
+	db := newTestDB(t, &model.SyncRun{}, &model.ZoneSnapshot{}, &model.SyncChange{})
+	return NewSyncService(repository.NewSyncRunRepository(db))
+}
+
+// newTestLocalProvider 创建数据保存在临时文件中的本地服务商，并注册为 account 账号
+func newTestLocalProvider(t *testing.T, account, zones string) *provider.LocalProvider {
+	t.Helper()
+	client, err := provider.New(config.CloudProviderConfig{
+		Type:    provider.LocalType,
+		Options: map[string]string{"path": filepath.Join(t.TempDir(), "local.json"), "zones": zones},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider.SetProvider(account, client)
+	return client.(*provider.LocalProvider)
+}
+
+// failingProvider 读取指定区域的记录时返回错误
+type failingProvider struct {
+	*provider.LocalProvider
+	zone string
+}
+
+func (p *failingProvider) ListRecords(ctx context.Context, zone string) ([]provider.Record, error) {
+	if zone == p.zone {
+		return nil, errors.New("api rate limited")
+	}
+	return p.LocalProvider.ListRecords(ctx, zone)
+}
+
+func mustCreateRecord(t *testing.T, p *provider.LocalProvider, zone string, record provider.Record) *provider.Record {
+	t.Helper()
+	created, err := p.CreateRecord(context.Background(), zone, record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return created
+}
+
+// changeSummary 以 action:zone:name 的形式列出任务的变更明细
+func changeSummary(t *testing.T, svc SyncService, runID uint) []string {
+	t.Helper()
+	run, err := svc.GetByID(runID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary := make([]string, 0, len(run.Changes))
+	for _, change := range run.Changes {
+		summary = append(summary, change.Action+":"+change.ZoneName+":"+change.RecordName)
+	}
+	return summary
+}
+
+func TestSyncRunDiff(t *testing.T) {
+	svc := newTestSyncService(t)
+	ctx := context.Background()
+	local := newTestLocalProvider(t, "diff", "example.com")
+
+	www := mustCreateRecord(t, local, "example.com", provider.Record{Name: "www", Type: "A", Value: "192.0.2.1", TTL: 600})
+	mail := mustCreateRecord(t, local, "example.com", provider.Record{Name: "@", Type: "MX", Value: "mail.example.com", TTL: 600, Priority: 10})
+
+	first, err := svc.Run(ctx, "diff", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Status != model.SyncRunStatusSuccess || first.ZoneCount != 1 || first.RecordCount != 2 || first.Added != 2 || first.Removed != 0 || first.Changed != 0 {
+		t.Fatalf("first run = %+v", first)
+	}
+
+	// 修改、删除和新增各一条记录
+	www.Value = "192.0.2.2"
+	if _, err := local.UpdateRecord(ctx, "example.com", *www); err != nil {
+		t.Fatal(err)
+	}
+	if err := local.DeleteRecord(ctx, "example.com", mail.ID); err != nil {
+		t.Fatal(err)
+	}
+	mustCreateRecord(t, local, "example.com", provider.Record{Name: "api", Type: "CNAME", Value: "www.example.com", TTL: 300})
+
+	second, err := svc.Run(ctx, "diff", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Added != 1 || second.Removed != 1 || second.Changed != 1 || second.RecordCount != 2 {
+		t.Fatalf("second run = %+v", second)
+	}
+	want := "removed:example.com:@,added:example.com:api,changed:example.com:www"
+	if got := strings.Join(changeSummary(t, svc, second.ID), ","); got != want {
+		t.Fatalf("changes = %s, want %s", got, want)
+	}
+	run, _ := svc.GetByID(second.ID)
+	for _, change := range run.Changes {
+		if change.Action == model.SyncChangeChanged && (change.Before.Value != "192.0.2.1" || change.After.Value != "192.0.2.2") {
+			t.Fatalf("changed record = %+v -> %+v", change.Before, change.After)
+		}
+	}
+
+	// 没有变化时不产生变更明细
+	third, err := svc.Run(ctx, "diff", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if third.Added+third.Removed+third.Changed != 0 {
+		t.Fatalf("unchanged run = %+v", third)
+	}
+
+	// 区域从服务商消失时，其记录全部视为删除
+	other := newTestLocalProvider(t, "diff", "other.com")
+	mustCreateRecord(t, other, "other.com", provider.Record{Name: "www", Type: "A", Value: "198.51.100.1", TTL: 600})
+	fourth, err := svc.Run(ctx, "diff", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fourth.ZoneCount != 1 || fourth.Added != 1 || fourth.Removed != 2 || fourth.Changed != 0 {
+		t.Fatalf("fourth run = %+v", fourth)
+	}
+	want = "removed:example.com:api,removed:example.com:www,added:other.com:www"
+	if got := strings.Join(changeSummary(t, svc, fourth.ID), ","); got != want {
+		t.Fatalf("changes = %s, want %s", got, want)
+	}
+}
+
+func TestSyncRunProviderError(t *testing.T) {
+	svc := newTestSyncService(t)
+	ctx := context.Background()
+	local := newTestLocalProvider(t, "flaky", "example.com,example.net")
+	www := mustCreateRecord(t, local, "example.com", provider.Record{Name: "www", Type: "A", Value: "192.0.2.1", TTL: 600})
+
+	if _, err := svc.Run(ctx, "flaky", 0); err != nil {
+		t.Fatal(err)
+	}
+
+	www.TTL = 300
+	if _, err := local.UpdateRecord(ctx, "example.com", *www); err != nil {
+		t.Fatal(err)
+	}
+	provider.SetProvider("flaky", &failingProvider{LocalProvider: local, zone: "example.net"})
+
+	failed, err := svc.Run(ctx, "flaky", 0)
+	if err == nil || !strings.Contains(err.Error(), "example.net") {
+		t.Fatalf("err = %v, want record listing error for example.net", err)
+	}
+	if failed == nil || failed.Status != model.SyncRunStatusFailed || failed.FinishedAt == nil || !strings.Contains(failed.Error, "api rate limited") {
+		t.Fatalf("failed run = %+v", failed)
+	}
+	stored, err := svc.GetByID(failed.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status != model.SyncRunStatusFailed || len(stored.Changes) != 0 {
+		t.Fatalf("stored failed run = %+v", stored)
+	}
+
+	// 失败的任务不保存快照，恢复后仍与上一次成功的同步对比
+	provider.SetProvider("flaky", local)
+	recovered, err := svc.Run(ctx, "flaky", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recovered.Changed != 1 || recovered.Added != 0 || recovered.Removed != 0 {
+		t.Fatalf("recovered run = %+v", recovered)
+	}
+
+	if _, err := svc.Run(ctx, "missing", 0); err == nil {
+		t.Fatal("unknown account should fail")
+	}
+}
@@ -0,0 +1,504 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"domain-admin/internal/repository"
+	"domain-admin/model"
+	"domain-admin/pkg/config"
+	"domain-admin/pkg/logger"
+	"domain-admin/pkg/totp"
+	"domain-admin/pkg/utils"
+	"domain-admin/pkg/vault"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// totpIssuer 身份验证器应用中显示的发行方
+	totpIssuer = "Domain Admin"
+	// loginChallengeTTL 登录挑战的有效期
+	loginChallengeTTL = 5 * time.Minute
+	// loginChallengeMaxAttempts 每个登录挑战允许的验证失败次数
+	loginChallengeMaxAttempts = 5
+	// twoFactorMaxFailures 连续验证失败达到该次数后锁定
+	twoFactorMaxFailures = 5
+	// twoFactorLockDuration 验证锁定时长
+	twoFactorLockDuration = 15 * time.Minute
+	// recoveryCodeCount 每次生成的恢复码数量
+	recoveryCodeCount = 10
+)
+
+// ErrLoginChallengeInvalid 登录挑战无效，客户端需要重新输入密码
+var ErrLoginChallengeInvalid = errors.New("登录挑战无效或已过期，请重新登录")
+
+// TwoFactorService 两步验证服务接口
+type TwoFactorService interface {
+	Status(userID uint) (*model.TwoFactorStatus, error)
+	Setup(userID uint) (*model.TwoFactorSetupResult, error)
+	Confirm(userID uint, code string) ([]string, error)
+	Disable(userID uint, code string) error
+	RegenerateRecoveryCodes(userID uint, code string) ([]string, error)
+	Reset(userID uint) error
+	BeginLogin(user *model.UserResponse) (*model.LoginChallengeResult, error)
+	SetupWithChallenge(challengeToken string) (*model.TwoFactorSetupResult, error)
+	CompleteLogin(req *model.TwoFactorLoginRequest) (*model.UserResponse, []string, error)
+	ChallengeUserID(challengeToken string) (uint, error)
+	CompleteLoginWith(challengeToken string, verify func(userID uint) error) (*model.UserResponse, error)
+	RotateVaultKey(ctx context.Context) (*model.VaultRotateItem, error)
+}
+
+type twoFactorService struct {
+	twoFactorRepo repository.TwoFactorRepository
+	webAuthnRepo  repository.WebAuthnRepository
+	userRepo      repository.UserRepository
+	roleRepo      repository.RoleRepository
+	keyring       *vault.Keyring
+	keyErr        error
+}
+
+// NewTwoFactorService 创建两步验证服务实例，TOTP密钥使用 vault 主密钥加密；
+// 已注册 WebAuthn 凭据的用户也需要两步验证
+func NewTwoFactorService(twoFactorRepo repository.TwoFactorRepository, webAuthnRepo repository.WebAuthnRepository, userRepo repository.UserRepository, roleRepo repository.RoleRepository, vaultCfg config.VaultConfig) TwoFactorService {
+	s := &twoFactorService{
+		twoFactorRepo: twoFactorRepo,
+		webAuthnRepo:  webAuthnRepo,
+		userRepo:      userRepo,
+		roleRepo:      roleRepo,
+	}
+	s.keyring, s.keyErr = vault.Load(vaultCfg.KeyEnv, vaultCfg.KeyFile)
+	return s
+}
+
+// Status 获取用户的两步验证状态
+func (s *twoFactorService) Status(userID uint) (*model.TwoFactorStatus, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	status := &model.TwoFactorStatus{Required: s.roleRequires(user.Role)}
+	if record, err := s.twoFactorRepo.GetByUserID(userID); err == nil {
+		status.Enabled = record.ConfirmedAt != nil
+		status.Pending = record.ConfirmedAt == nil
+		status.ConfirmedAt = record.ConfirmedAt
+		status.RecoveryCodesRemaining = len(record.RecoveryCodes)
+	}
+	status.WebAuthnCredentials = s.webAuthnCount(userID)
+	return status, nil
+}
+
+// Setup 生成新的TOTP密钥，确认前不生效；已启用两步验证时需要先停用
+func (s *twoFactorService) Setup(userID uint) (*model.TwoFactorSetupResult, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if s.keyErr != nil {
+		return nil, s.keyError()
+	}
+
+	record, err := s.twoFactorRepo.GetByUserID(userID)
+	if err == nil && record.ConfirmedAt != nil {
+		return nil, errors.New("已启用两步验证，请先停用后再重新绑定")
+	}
+	if err != nil {
+		record = &model.UserTOTP{UserID: userID}
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, errors.New("生成两步验证密钥失败")
+	}
+	sealed, err := s.keyring.Seal([]byte(secret))
+	if err != nil {
+		logger.Errorf("加密两步验证密钥失败: %v", err)
+		return nil, errors.New("加密两步验证密钥失败")
+	}
+	record.SecretCiphertext = sealed.Ciphertext
+	record.SecretDataKey = sealed.DataKey
+	record.SecretKeyID = sealed.KeyID
+	record.LastStep = 0
+	record.RecoveryCodes = nil
+	record.FailedAttempts = 0
+	record.LockedUntil = nil
+	if err := s.twoFactorRepo.Save(record); err != nil {
+		logger.Errorf("保存两步验证密钥失败: %v", err)
+		return nil, errors.New("保存两步验证密钥失败")
+	}
+
+	return &model.TwoFactorSetupResult{
+		Secret: secret,
+		URI:    totp.URI(totpIssuer, user.Username, secret),
+	}, nil
+}
+
+// Confirm 使用验证器生成的第一个验证码确认绑定，返回一次性恢复码
+func (s *twoFactorService) Confirm(userID uint, code string) ([]string, error) {
+	record, err := s.twoFactorRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, errors.New("请先生成两步验证密钥")
+	}
+	if record.ConfirmedAt != nil {
+		return nil, errors.New("已启用两步验证")
+	}
+	if err := s.verify(record, code, false); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, errors.New("生成恢复码失败")
+	}
+	now := time.Now()
+	record.ConfirmedAt = &now
+	record.RecoveryCodes = hashes
+	if err := s.twoFactorRepo.Save(record); err != nil {
+		logger.Errorf("启用两步验证失败: %v", err)
+		return nil, errors.New("启用两步验证失败")
+	}
+
+	logger.Infof("用户ID %d 已启用两步验证", userID)
+	return codes, nil
+}
+
+// Disable 停用两步验证，需要验证码或恢复码；角色要求两步验证且没有注册安全密钥时不能停用
+func (s *twoFactorService) Disable(userID uint, code string) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if s.roleRequires(user.Role) && s.webAuthnCount(userID) == 0 {
+		return errors.New("当前角色要求启用两步验证，不能停用")
+	}
+	record, err := s.twoFactorRepo.GetByUserID(userID)
+	if err != nil || record.ConfirmedAt == nil {
+		return errors.New("未启用两步验证")
+	}
+	if err := s.verify(record, code, true); err != nil {
+		return err
+	}
+
+	if err := s.twoFactorRepo.DeleteByUserID(userID); err != nil {
+		logger.Errorf("停用两步验证失败: %v", err)
+		return errors.New("停用两步验证失败")
+	}
+	logger.Infof("用户 %s 已停用两步验证", user.Username)
+	return nil
+}
+
+// RegenerateRecoveryCodes 使用验证码重新生成恢复码，旧恢复码全部失效
+func (s *twoFactorService) RegenerateRecoveryCodes(userID uint, code string) ([]string, error) {
+	record, err := s.twoFactorRepo.GetByUserID(userID)
+	if err != nil || record.ConfirmedAt == nil {
+		return nil, errors.New("未启用两步验证")
+	}
+	if err := s.verify(record, code, false); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, errors.New("生成恢复码失败")
+	}
+	record.RecoveryCodes = hashes
+	if err := s.twoFactorRepo.Save(record); err != nil {
+		logger.Errorf("保存恢复码失败: %v", err)
+		return nil, errors.New("保存恢复码失败")
+	}
+	return codes, nil
+}
+
+// Reset 管理员重置用户的两步验证，同时删除TOTP配置和 WebAuthn 凭据，用户下次登录时按角色要求重新绑定
+func (s *twoFactorService) Reset(userID uint) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if err := s.twoFactorRepo.DeleteByUserID(userID); err != nil {
+		logger.Errorf("重置两步验证失败: %v", err)
+		return errors.New("重置两步验证失败")
+	}
+	if err := s.webAuthnRepo.DeleteByUserID(userID); err != nil {
+		logger.Errorf("删除WebAuthn凭据失败: %v", err)
+		return errors.New("重置两步验证失败")
+	}
+	logger.Infof("用户 %s 的两步验证已重置", user.Username)
+	return nil
+}
+
+// BeginLogin 密码校验通过后判断是否需要两步验证，需要时创建登录挑战，不需要时返回 nil
+func (s *twoFactorService) BeginLogin(user *model.UserResponse) (*model.LoginChallengeResult, error) {
+	var methods []string
+	if record, err := s.twoFactorRepo.GetByUserID(user.ID); err == nil && record.ConfirmedAt != nil {
+		methods = append(methods, model.TwoFactorMethodTOTP)
+	}
+	if s.webAuthnCount(user.ID) > 0 {
+		methods = append(methods, model.TwoFactorMethodWebAuthn)
+	}
+
+	purpose := ""
+	if len(methods) > 0 {
+		purpose = model.LoginChallengeVerify
+	} else if s.roleRequires(user.Role) {
+		if s.keyErr != nil {
+			logger.Errorf("角色 %s 要求两步验证，但未配置加密主密钥: %v", user.Role, s.keyErr)
+			return nil, errors.New("当前角色要求两步验证，但服务器未配置加密主密钥，请联系管理员")
+		}
+		purpose = model.LoginChallengeEnroll
+	}
+	if purpose == "" {
+		return nil, nil
+	}
+
+	token, err := randomHex(32)
+	if err != nil {
+		return nil, errors.New("创建登录挑战失败")
+	}
+	challenge := &model.LoginChallenge{
+		UserID:    user.ID,
+		TokenHash: utils.SHA256(token),
+		Purpose:   purpose,
+		ExpiresAt: time.Now().Add(loginChallengeTTL),
+	}
+	if err := s.twoFactorRepo.CreateChallenge(challenge); err != nil {
+		logger.Errorf("创建登录挑战失败: %v", err)
+		return nil, errors.New("创建登录挑战失败")
+	}
+
+	return &model.LoginChallengeResult{
+		MFARequired:    true,
+		EnrollRequired: purpose == model.LoginChallengeEnroll,
+		Methods:        methods,
+		ChallengeToken: token,
+		ExpiresIn:      int64(loginChallengeTTL / time.Second),
+	}, nil
+}
+
+// SetupWithChallenge 角色要求两步验证但尚未绑定时，使用登录挑战生成TOTP密钥
+func (s *twoFactorService) SetupWithChallenge(challengeToken string) (*model.TwoFactorSetupResult, error) {
+	challenge, err := s.challenge(challengeToken)
+	if err != nil {
+		return nil, err
+	}
+	if challenge.Purpose != model.LoginChallengeEnroll {
+		return nil, errors.New("已绑定两步验证，请直接输入验证码")
+	}
+	return s.Setup(challenge.UserID)
+}
+
+// CompleteLogin 校验登录挑战和验证码，成功时返回用户信息；
+// 绑定挑战在确认绑定后同时返回恢复码
+func (s *twoFactorService) CompleteLogin(req *model.TwoFactorLoginRequest) (*model.UserResponse, []string, error) {
+	challenge, err := s.challenge(req.ChallengeToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var codes []string
+	user, err := s.finishLogin(challenge, func(userID uint) error {
+		if challenge.Purpose == model.LoginChallengeEnroll {
+			var err error
+			codes, err = s.Confirm(userID, req.Code)
+			return err
+		}
+		record, err := s.twoFactorRepo.GetByUserID(userID)
+		if err != nil || record.ConfirmedAt == nil {
+			return errors.New("未启用TOTP两步验证，请使用安全密钥验证")
+		}
+		return s.verify(record, req.Code, true)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, codes, nil
+}
+
+// ChallengeUserID 返回待验证登录挑战对应的用户ID，用于发起安全密钥验证
+func (s *twoFactorService) ChallengeUserID(challengeToken string) (uint, error) {
+	challenge, err := s.challenge(challengeToken)
+	if err != nil {
+		return 0, err
+	}
+	if challenge.Purpose != model.LoginChallengeVerify {
+		return 0, errors.New("请先绑定两步验证")
+	}
+	return challenge.UserID, nil
+}
+
+// CompleteLoginWith 使用 verify 完成登录挑战，用于安全密钥等由其他服务校验的验证方式
+func (s *twoFactorService) CompleteLoginWith(challengeToken string, verify func(userID uint) error) (*model.UserResponse, error) {
+	challenge, err := s.challenge(challengeToken)
+	if err != nil {
+		return nil, err
+	}
+	if challenge.Purpose != model.LoginChallengeVerify {
+		return nil, errors.New("请先绑定两步验证")
+	}
+	return s.finishLogin(challenge, verify)
+}
+
+// finishLogin 校验第二步并将登录挑战标记为已使用，校验失败时累计挑战的失败次数
+func (s *twoFactorService) finishLogin(challenge *model.LoginChallenge, verify func(userID uint) error) (*model.UserResponse, error) {
+	if err := verify(challenge.UserID); err != nil {
+		if incErr := s.twoFactorRepo.IncrementChallengeAttempts(challenge.ID); incErr != nil {
+			logger.Warnf("记录登录挑战失败次数失败: %v", incErr)
+		}
+		return nil, err
+	}
+
+	used, err := s.twoFactorRepo.UseChallenge(challenge.ID, time.Now())
+	if err != nil || !used {
+		return nil, ErrLoginChallengeInvalid
+	}
+	user, err := s.userRepo.GetByID(challenge.UserID)
+	if err != nil || user.Status != 1 {
+		return nil, ErrLoginChallengeInvalid
+	}
+	return user.ToResponse(), nil
+}
+
+// challenge 获取未使用、未过期且未超过失败次数的登录挑战
+func (s *twoFactorService) challenge(token string) (*model.LoginChallenge, error) {
+	challenge, err := s.twoFactorRepo.GetChallengeByHash(utils.SHA256(token))
+	if err != nil {
+		return nil, ErrLoginChallengeInvalid
+	}
+	if challenge.UsedAt != nil || !time.Now().Before(challenge.ExpiresAt) || challenge.Attempts >= loginChallengeMaxAttempts {
+		return nil, ErrLoginChallengeInvalid
+	}
+	return challenge, nil
+}
+
+// verify 校验验证码，allowRecovery 为 true 时也接受恢复码（使用后作废）。
+// 连续失败 twoFactorMaxFailures 次后锁定 twoFactorLockDuration
+func (s *twoFactorService) verify(record *model.UserTOTP, code string, allowRecovery bool) error {
+	now := time.Now()
+	if record.LockedUntil != nil && now.Before(*record.LockedUntil) {
+		return fmt.Errorf("验证失败次数过多，请在 %s 后重试", record.LockedUntil.Format("15:04:05"))
+	}
+	if s.keyErr != nil {
+		return s.keyError()
+	}
+	secret, err := s.keyring.Open(&vault.Sealed{
+		KeyID:      record.SecretKeyID,
+		DataKey:    record.SecretDataKey,
+		Ciphertext: record.SecretCiphertext,
+	})
+	if err != nil {
+		logger.Errorf("解密两步验证密钥失败: %v", err)
+		return errors.New("解密两步验证密钥失败")
+	}
+
+	ok := false
+	if step, valid := totp.Validate(string(secret), code, now, record.LastStep); valid {
+		record.LastStep = step
+		ok = true
+	} else if allowRecovery {
+		hash := utils.SHA256(normalizeRecoveryCode(code))
+		for i, h := range record.RecoveryCodes {
+			if h == hash {
+				record.RecoveryCodes = append(record.RecoveryCodes[:i:i], record.RecoveryCodes[i+1:]...)
+				ok = true
+				logger.Infof("用户ID %d 使用了恢复码，剩余 %d 个", record.UserID, len(record.RecoveryCodes))
+				break
+			}
+		}
+	}
+
+	if ok {
+		record.FailedAttempts = 0
+		record.LockedUntil = nil
+	} else {
+		record.FailedAttempts++
+		if record.FailedAttempts >= twoFactorMaxFailures {
+			until := now.Add(twoFactorLockDuration)
+			record.LockedUntil = &until
+			record.FailedAttempts = 0
+			logger.Warnf("用户ID %d 两步验证连续失败，锁定至 %s", record.UserID, until.Format(time.RFC3339))
+		}
+	}
+	if err := s.twoFactorRepo.Save(record); err != nil {
+		logger.Errorf("保存两步验证状态失败: %v", err)
+		return errors.New("保存两步验证状态失败")
+	}
+	if !ok {
+		return errors.New("验证码错误")
+	}
+	return nil
+}
+
+// roleRequires 判断角色是否要求两步验证
+func (s *twoFactorService) roleRequires(roleName string) bool {
+	role, err := s.roleRepo.GetByName(roleName)
+	return err == nil && role.RequireMFA
+}
+
+// webAuthnCount 返回用户已注册的 WebAuthn 凭据数量，查询失败时按0处理
+func (s *twoFactorService) webAuthnCount(userID uint) int64 {
+	count, err := s.webAuthnRepo.CountByUserID(userID)
+	if err != nil {
+		logger.Warnf("查询WebAuthn凭据失败: %v", err)
+		return 0
+	}
+	return count
+}
+
+// RotateVaultKey 使用当前主密钥重新加密由其他主密钥加密的TOTP密钥
+func (s *twoFactorService) RotateVaultKey(ctx context.Context) (*model.VaultRotateItem, error) {
+	item := &model.VaultRotateItem{Name: "totp_secrets"}
+	records, err := s.twoFactorRepo.ListAll()
+	if err != nil {
+		return item, err
+	}
+	if len(records) > 0 && s.keyErr != nil {
+		return item, s.keyError()
+	}
+	for _, record := range records {
+		if err := ctx.Err(); err != nil {
+			return item, err
+		}
+		sealed := &vault.Sealed{KeyID: record.SecretKeyID, DataKey: record.SecretDataKey, Ciphertext: record.SecretCiphertext}
+		rotateSealed(s.keyring, item, fmt.Sprintf("用户 %d 的两步验证密钥", record.UserID), sealed, func(rotated *vault.Sealed) error {
+			record.SecretCiphertext = rotated.Ciphertext
+			record.SecretDataKey = rotated.DataKey
+			record.SecretKeyID = rotated.KeyID
+			return s.twoFactorRepo.UpdateSecret(record)
+		})
+	}
+	return item, nil
+}
+
+// keyError 返回主密钥不可用的错误信息
+func (s *twoFactorService) keyError() error {
+	if errors.Is(s.keyErr, vault.ErrNoKey) {
+		return errors.New("未配置两步验证密钥的加密主密钥")
+	}
+	return fmt.Errorf("加密主密钥无效: %w", s.keyErr)
+}
+
+// newRecoveryCodes 生成恢复码明文和哈希，格式为 xxxxx-xxxxx
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+	for i := 0; i < recoveryCodeCount; i++ {
+		buf := make([]byte, 7)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		raw := strings.ToLower(encoding.EncodeToString(buf))[:10]
+		code := raw[:5] + "-" + raw[5:]
+		codes = append(codes, code)
+		hashes = append(hashes, utils.SHA256(normalizeRecoveryCode(code)))
+	}
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode 忽略恢复码中的大小写、空格和连字符
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
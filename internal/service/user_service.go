@@ -5,12 +5,11 @@ import (
 	"domain-admin/internal/repository"
 	"domain-admin/model"
 	"domain-admin/pkg/cache"
-	"domain-admin/pkg/jwt"
 	"domain-admin/pkg/logger"
 	"domain-admin/pkg/pagination"
 	"errors"
 	"fmt"
-	"strconv"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 	"golang.org/x/crypto/bcrypt"
@@ -20,8 +19,7 @@ import (
 // UserService 用户服务接口
 type UserService interface {
 	Register(req *model.UserCreateRequest) (*model.UserResponse, error)
-	Login(req *model.UserLoginRequest) (string, *model.UserResponse, error)
-	Logout(userID uint) error
+	Login(req *model.UserLoginRequest) (*model.UserResponse, error)
 	GetProfile(userID uint) (*model.UserResponse, error)
 	UpdateProfile(userID uint, req *model.UserUpdateRequest) (*model.UserResponse, error)
 	GetUserList(page pagination.Pagination) (*pagination.PageResult, error)
@@ -34,13 +32,15 @@ type UserService interface {
 
 // userService 用户服务实现
 type userService struct {
-	userRepo repository.UserRepository
+	userRepo       repository.UserRepository
+	authenticators []PasswordAuthenticator
 }
 
-// NewUserService 创建用户服务实例
-func NewUserService(db *gorm.DB) UserService {
+// NewUserService 创建用户服务实例，authenticators 为登录时依次尝试的外部认证器（如LDAP）
+func NewUserService(db *gorm.DB, authenticators ...PasswordAuthenticator) UserService {
 	return &userService{
-		userRepo: repository.NewUserRepository(db),
+		userRepo:       repository.NewUserRepository(db),
+		authenticators: authenticators,
 	}
 }
 
@@ -89,43 +89,13 @@ func (s *userService) Register(req *model.UserCreateRequest) (*model.UserRespons
 	return user.ToResponse(), nil
 }
 
-// Login 用户登录
-func (s *userService) Login(req *model.UserLoginRequest) (string, *model.UserResponse, error) {
+// Login 用户登录，校验用户名和密码，访问令牌由登录会话签发
+func (s *userService) Login(req *model.UserLoginRequest) (*model.UserResponse, error) {
 	ctx := context.Background()
 
-	// 获取用户信息
-	user, err := s.userRepo.GetByUsername(req.Username)
+	user, err := s.authenticate(req.Username, req.Password)
 	if err != nil {
-		return "", nil, errors.New("用户名或密码错误")
-	}
-
-	// 检查用户状态
-	if user.Status == 0 {
-		return "", nil, errors.New("用户已被禁用")
-	}
-
-	// 验证密码
-	if PasswordErr := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); PasswordErr != nil {
-		return "", nil, errors.New("用户名或密码错误")
-	}
-
-	// 生成JWT token
-	token, tokenErr := jwt.GenerateToken(user.ID, user.Username, user.Role)
-	if tokenErr != nil {
-		logger.Errorf("生成token失败: %v", tokenErr)
-		return "", nil, errors.New("登录失败")
-	}
-
-	// 缓存用户会话信息
-	sessionInfo := map[string]interface{}{
-		"user_id":  user.ID,
-		"username": user.Username,
-		"role":     user.Role,
-		"status":   user.Status,
-	}
-	sessionKey := strconv.FormatUint(uint64(user.ID), 10)
-	if err := cache.SetSessionCache(ctx, sessionKey, sessionInfo); err != nil {
-		logger.Warnf("缓存会话信息失败: %v", err)
+		return nil, err
 	}
 
 	// 缓存用户信息
@@ -140,22 +110,41 @@ func (s *userService) Login(req *model.UserLoginRequest) (string, *model.UserRes
 	}
 
 	logger.Infof("用户登录成功: %s", user.Username)
-	return token, userResponse, nil
+	return userResponse, nil
 }
 
-// Logout 用户登出
-func (s *userService) Logout(userID uint) error {
-	ctx := context.Background()
+// authenticate 依次尝试外部认证器，都不管理该用户时校验本地密码
+func (s *userService) authenticate(username, password string) (*model.User, error) {
+	for _, authenticator := range s.authenticators {
+		user, err := authenticator.Authenticate(username, password)
+		if errors.Is(err, ErrNotExternalUser) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if user.Status == 0 {
+			return nil, errors.New("用户已被禁用")
+		}
+		return user, nil
+	}
 
-	// 删除会话缓存
-	sessionKey := strconv.FormatUint(uint64(userID), 10)
-	if err := cache.DelSessionCache(ctx, sessionKey); err != nil {
-		logger.Warnf("删除会话缓存失败: %v", err)
-		return errors.New("登出失败")
+	// 获取用户信息
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return nil, errors.New("用户名或密码错误")
 	}
 
-	logger.Infof("用户登出成功，用户ID: %d", userID)
-	return nil
+	// 检查用户状态
+	if user.Status == 0 {
+		return nil, errors.New("用户已被禁用")
+	}
+
+	// 验证密码
+	if PasswordErr := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); PasswordErr != nil {
+		return nil, errors.New("用户名或密码错误")
+	}
+	return user, nil
 }
 
 // GetProfile 获取用户资料
@@ -325,15 +314,17 @@ func (s *userService) CreateUser(req *model.UserCreateRequest) (*model.UserRespo
 		role = "user" // 默认为普通用户
 	}
 
-	// 创建用户
+	// 创建用户，管理员创建的用户视为已验证邮箱
+	now := time.Now()
 	user := &model.User{
-		Username: req.Username,
-		Email:    req.Email,
-		Password: string(hashedPassword),
-		Nickname: req.Nickname,
-		Phone:    req.Phone,
-		Role:     role,
-		Status:   1,
+		Username:        req.Username,
+		Email:           req.Email,
+		Password:        string(hashedPassword),
+		Nickname:        req.Nickname,
+		Phone:           req.Phone,
+		Role:            role,
+		Status:          1,
+		EmailVerifiedAt: &now,
 	}
 
 	if err := s.userRepo.Create(user); err != nil {
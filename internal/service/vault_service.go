@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"domain-admin/model"
+	"domain-admin/pkg/config"
+	"domain-admin/pkg/logger"
+	"domain-admin/pkg/vault"
+	"errors"
+	"fmt"
+)
+
+// VaultRotator 使用 vault 主密钥加密数据的服务实现此接口，参与主密钥轮换
+type VaultRotator interface {
+	// RotateVaultKey 使用当前主密钥重新加密由其他主密钥加密的数据
+	RotateVaultKey(ctx context.Context) (*model.VaultRotateItem, error)
+}
+
+// VaultService 主密钥服务接口
+type VaultService interface {
+	RotateKey(ctx context.Context) (*model.VaultRotateResult, error)
+}
+
+type vaultService struct {
+	cfg      config.VaultConfig
+	rotators []VaultRotator
+}
+
+// NewVaultService 创建主密钥服务实例，rotators 为所有使用主密钥加密数据的服务
+func NewVaultService(cfg config.VaultConfig, rotators ...VaultRotator) VaultService {
+	return &vaultService{cfg: cfg, rotators: rotators}
+}
+
+// RotateKey 依次轮换每个服务的加密数据
+// 轮换时将新主密钥放在密钥列表第一位并保留旧密钥；只有结果为 Complete 时才可以移除旧密钥
+func (s *vaultService) RotateKey(ctx context.Context) (*model.VaultRotateResult, error) {
+	keyring, err := vault.Load(s.cfg.KeyEnv, s.cfg.KeyFile)
+	if err != nil {
+		if errors.Is(err, vault.ErrNoKey) {
+			return nil, errors.New("未配置加密主密钥")
+		}
+		return nil, fmt.Errorf("加密主密钥无效: %w", err)
+	}
+
+	result := &model.VaultRotateResult{KeyID: keyring.Primary(), Complete: true}
+	for _, rotator := range s.rotators {
+		item, err := rotator.RotateVaultKey(ctx)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err != nil {
+			if item == nil {
+				item = &model.VaultRotateItem{}
+			}
+			item.Error = err.Error()
+			result.Complete = false
+		}
+		if item.Failed > 0 {
+			result.Complete = false
+		}
+		result.Rotated += item.Rotated
+		result.Failed += item.Failed
+		result.Items = append(result.Items, item)
+	}
+
+	if result.Complete {
+		logger.Infof("主密钥轮换完成: 主密钥 %s，重新加密 %d 条数据，可以移除旧主密钥", result.KeyID, result.Rotated)
+	} else {
+		logger.Warnf("主密钥轮换未完成: 主密钥 %s，成功 %d 条，失败 %d 条，请保留旧主密钥并重试", result.KeyID, result.Rotated, result.Failed)
+	}
+	return result, nil
+}
+
+// rotateSealed 使用当前主密钥重新加密一条数据并保存，已由当前主密钥加密时不做处理
+func rotateSealed(keyring *vault.Keyring, item *model.VaultRotateItem, label string, sealed *vault.Sealed, save func(*vault.Sealed) error) {
+	if sealed.Ciphertext == "" || !keyring.NeedsRotation(sealed) {
+		return
+	}
+	rotated, err := keyring.Rotate(sealed)
+	if err == nil {
+		err = save(rotated)
+	}
+	if err != nil {
+		logger.Errorf("轮换%s的主密钥失败: %v", label, err)
+		item.Failed++
+		return
+	}
+	item.Rotated++
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"domain-admin/internal/repository"
+	"domain-admin/model"
+	"domain-admin/pkg/config"
+	"domain-admin/pkg/vault"
+	"encoding/base64"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func newTestVaultKey(t *testing.T) string {
+	t.Helper()
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+// newTestVaultService 与接口相同，轮换所有使用主密钥加密数据的服务
+func newTestVaultService(db *gorm.DB, cfg config.VaultConfig) VaultService {
+	return NewVaultService(cfg,
+		NewProviderAccountService(repository.NewProviderAccountRepository(db), cfg),
+		NewTwoFactorService(
+			repository.NewTwoFactorRepository(db),
+			repository.NewWebAuthnRepository(db),
+			repository.NewUserRepository(db),
+			repository.NewRoleRepository(db),
+			cfg,
+		),
+		NewJWTKeyService(repository.NewJWTKeyRepository(db), config.JWTConfig{}, cfg),
+		NewAcmeService(repository.NewAcmeRepository(db), config.ACMEConfig{}, cfg),
+	)
+}
+
+// sealedRows 读取每张表中保存的加密数据
+func sealedRows(t *testing.T, db *gorm.DB) map[string]*vault.Sealed {
+	t.Helper()
+	var (
+		accounts []model.ProviderAccount
+		totps    []model.UserTOTP
+		jwtKeys  []model.JWTKey
+		acmeAccs []model.AcmeAccount
+		certs    []model.AcmeCertificate
+	)
+	for _, dest := range []any{&accounts, &totps, &jwtKeys, &acmeAccs, &certs} {
+		if err := db.Find(dest).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	rows := make(map[string]*vault.Sealed)
+	for _, r := range accounts {
+		rows["account:"+r.Name] = &vault.Sealed{KeyID: r.SecretKeyID, DataKey: r.SecretDataKey, Ciphertext: r.SecretCiphertext}
+	}
+	for _, r := range totps {
+		rows["totp"] = &vault.Sealed{KeyID: r.SecretKeyID, DataKey: r.SecretDataKey, Ciphertext: r.SecretCiphertext}
+	}
+	for _, r := range jwtKeys {
+		rows["jwt"] = &vault.Sealed{KeyID: r.PrivateKeyID, DataKey: r.PrivateDataKey, Ciphertext: r.PrivateCiphertext}
+	}
+	for _, r := range acmeAccs {
+		rows["acme_account"] = &vault.Sealed{KeyID: r.PrivateKeyID, DataKey: r.PrivateDataKey, Ciphertext: r.PrivateCiphertext}
+	}
+	for _, r := range certs {
+		rows["acme_cert"] = &vault.Sealed{KeyID: r.PrivateKeyID, DataKey: r.PrivateDataKey, Ciphertext: r.PrivateCiphertext}
+	}
+	return rows
+}
+
+func TestVaultRotateKey(t *testing.T) {
+	db := newTestDB(t, &model.ProviderAccount{}, &model.UserTOTP{}, &model.JWTKey{}, &model.AcmeAccount{}, &model.AcmeCertificate{})
+	ctx := context.Background()
+	oldKey, newKey := newTestVaultKey(t), newTestVaultKey(t)
+	old, err := vault.Parse("old:" + oldKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seal := func(plaintext string) *vault.Sealed {
+		sealed, err := old.Seal([]byte(plaintext))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sealed
+	}
+
+	s := seal("account")
+	db.Create(&model.ProviderAccount{Name: "account", Type: "local", SecretCiphertext: s.Ciphertext, SecretDataKey: s.DataKey, SecretKeyID: s.KeyID})
+	s = seal("totp")
+	db.Create(&model.UserTOTP{UserID: 1, SecretCiphertext: s.Ciphertext, SecretDataKey: s.DataKey, SecretKeyID: s.KeyID})
+	s = seal("jwt")
+	db.Create(&model.JWTKey{Kid: "k1", Algorithm: "ES256", PublicKey: "public", PrivateCiphertext: s.Ciphertext, PrivateDataKey: s.DataKey, PrivateKeyID: s.KeyID})
+	s = seal("acme_account")
+	db.Create(&model.AcmeAccount{DirectoryURL: "https://acme.example.com/directory", Email: "admin@example.com", PrivateCiphertext: s.Ciphertext, PrivateDataKey: s.DataKey, PrivateKeyID: s.KeyID})
+	s = seal("acme_cert")
+	db.Create(&model.AcmeCertificate{CommonName: "example.com", Certificate: "chain", PrivateCiphertext: s.Ciphertext, PrivateDataKey: s.DataKey, PrivateKeyID: s.KeyID})
+	// 未保存密钥的账号不参与轮换
+	db.Create(&model.ProviderAccount{Name: "nosecret", Type: "local"})
+
+	cfg := config.VaultConfig{KeyEnv: "VAULT_ROTATE_TEST_KEY"}
+	t.Setenv(cfg.KeyEnv, "new:"+newKey+",old:"+oldKey)
+	result, err := newTestVaultService(db, cfg).RotateKey(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Complete || result.KeyID != "new" || result.Rotated != 5 || result.Failed != 0 || len(result.Items) != 4 {
+		t.Fatalf("result = %+v", result)
+	}
+	for _, item := range result.Items {
+		if item.Error != "" {
+			t.Errorf("item %s: %s", item.Name, item.Error)
+		}
+	}
+
+	// 轮换完成后移除旧主密钥，全部数据仍能解密
+	only, _ := vault.Parse("new:" + newKey)
+	rows := sealedRows(t, db)
+	if len(rows) != 6 {
+		t.Fatalf("rows = %v", rows)
+	}
+	for name, sealed := range rows {
+		if name == "account:nosecret" {
+			if sealed.Ciphertext != "" {
+				t.Errorf("empty secret was sealed: %+v", sealed)
+			}
+			continue
+		}
+		plaintext, err := only.Open(sealed)
+		want := name
+		if name == "account:account" {
+			want = "account"
+		}
+		if err != nil || string(plaintext) != want {
+			t.Errorf("%s = %q, %v", name, plaintext, err)
+		}
+	}
+
+	// 再次轮换时没有需要处理的数据
+	again, err := newTestVaultService(db, cfg).RotateKey(ctx)
+	if err != nil || !again.Complete || again.Rotated != 0 {
+		t.Fatalf("second rotation = %+v, %v", again, err)
+	}
+}
+
+func TestVaultRotateKeyIncomplete(t *testing.T) {
+	db := newTestDB(t, &model.ProviderAccount{}, &model.UserTOTP{}, &model.JWTKey{}, &model.AcmeAccount{}, &model.AcmeCertificate{})
+	lost, _ := vault.Parse("lost:" + newTestVaultKey(t))
+	s, err := lost.Seal([]byte("totp"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Create(&model.UserTOTP{UserID: 1, SecretCiphertext: s.Ciphertext, SecretDataKey: s.DataKey, SecretKeyID: s.KeyID})
+
+	// 主密钥列表中缺少加密数据使用的密钥时不能报告轮换完成
+	cfg := config.VaultConfig{KeyEnv: "VAULT_ROTATE_TEST_KEY"}
+	t.Setenv(cfg.KeyEnv, "new:"+newTestVaultKey(t))
+	result, err := newTestVaultService(db, cfg).RotateKey(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Complete || result.Failed != 1 || result.Rotated != 0 {
+		t.Fatalf("result = %+v", result)
+	}
+
+	t.Setenv(cfg.KeyEnv, "")
+	if _, err := newTestVaultService(db, cfg).RotateKey(context.Background()); err == nil {
+		t.Fatal("rotation without master key should fail")
+	}
+}
@@ -0,0 +1,397 @@
+package service
+
+import (
+	"domain-admin/internal/repository"
+	"domain-admin/model"
+	"domain-admin/pkg/config"
+	"domain-admin/pkg/logger"
+	"domain-admin/pkg/utils"
+	"domain-admin/pkg/webauthn"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+var (
+	errWebAuthnDisabled  = errors.New("未配置WebAuthn，请设置 webauthn.rp_id")
+	errWebAuthnChallenge = errors.New("认证会话无效或已过期，请重试")
+	errWebAuthnFailed    = errors.New("安全密钥验证失败")
+)
+
+// WebAuthnService WebAuthn 安全密钥服务接口，凭据可用于无密码登录，也可作为密码登录后的第二步验证
+type WebAuthnService interface {
+	BeginRegistration(userID uint) (*webauthn.CreationOptions, error)
+	FinishRegistration(userID uint, name string, resp *webauthn.AttestationResponse) (*model.WebAuthnCredential, error)
+	ListCredentials(userID uint) ([]*model.WebAuthnCredential, error)
+	DeleteCredential(userID, id uint) error
+	BeginLogin(username string) (*webauthn.RequestOptions, error)
+	FinishLogin(resp *webauthn.AssertionResponse) (*model.UserResponse, error)
+	BeginSecondFactor(userID uint) (*webauthn.RequestOptions, error)
+	VerifySecondFactor(userID uint, resp *webauthn.AssertionResponse) error
+}
+
+type webAuthnService struct {
+	webAuthnRepo  repository.WebAuthnRepository
+	twoFactorRepo repository.TwoFactorRepository
+	userRepo      repository.UserRepository
+	roleRepo      repository.RoleRepository
+	rp            *webauthn.RelyingParty
+}
+
+// NewWebAuthnService 创建 WebAuthn 服务实例，未配置 webauthn.rp_id 时所有操作返回错误
+func NewWebAuthnService(webAuthnRepo repository.WebAuthnRepository, twoFactorRepo repository.TwoFactorRepository, userRepo repository.UserRepository, roleRepo repository.RoleRepository, cfg config.WebAuthnConfig) WebAuthnService {
+	s := &webAuthnService{
+		webAuthnRepo:  webAuthnRepo,
+		twoFactorRepo: twoFactorRepo,
+		userRepo:      userRepo,
+		roleRepo:      roleRepo,
+	}
+	if cfg.RPID != "" {
+		s.rp = &webauthn.RelyingParty{ID: cfg.RPID, Name: cfg.DisplayName(), Origins: cfg.Origins}
+	}
+	return s
+}
+
+// BeginRegistration 生成注册选项，已注册的凭据放入 excludeCredentials
+func (s *webAuthnService) BeginRegistration(userID uint) (*webauthn.CreationOptions, error) {
+	if s.rp == nil {
+		return nil, errWebAuthnDisabled
+	}
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	exclude, err := s.descriptors(userID)
+	if err != nil {
+		return nil, err
+	}
+	challenge, err := s.newChallenge(userID, model.WebAuthnPurposeRegister)
+	if err != nil {
+		return nil, err
+	}
+
+	displayName := user.Nickname
+	if displayName == "" {
+		displayName = user.Username
+	}
+	entity := webauthn.UserEntity{
+		ID:          webauthn.EncodeID(userHandle(user.ID)),
+		Name:        user.Username,
+		DisplayName: displayName,
+	}
+	return s.rp.CreationOptions(entity, challenge, exclude, webauthn.VerificationPreferred), nil
+}
+
+// FinishRegistration 校验注册结果并保存凭据
+func (s *webAuthnService) FinishRegistration(userID uint, name string, resp *webauthn.AttestationResponse) (*model.WebAuthnCredential, error) {
+	if s.rp == nil {
+		return nil, errWebAuthnDisabled
+	}
+	challenge, err := resp.Challenge()
+	if err != nil {
+		return nil, errWebAuthnChallenge
+	}
+	session, err := s.useChallenge(challenge, model.WebAuthnPurposeRegister)
+	if err != nil || session.UserID != userID {
+		return nil, errWebAuthnChallenge
+	}
+
+	cred, err := s.rp.VerifyRegistration(resp, challenge, false)
+	if err != nil {
+		logger.Warnf("用户ID %d 注册安全密钥失败: %v", userID, err)
+		return nil, errors.New("安全密钥注册失败")
+	}
+	credentialID := webauthn.EncodeID(cred.ID)
+	if _, err := s.webAuthnRepo.GetCredentialByHash(utils.SHA256(credentialID)); err == nil {
+		return nil, errors.New("该安全密钥已注册")
+	}
+
+	if name == "" {
+		count, _ := s.webAuthnRepo.CountByUserID(userID)
+		name = fmt.Sprintf("安全密钥 %d", count+1)
+	}
+	record := &model.WebAuthnCredential{
+		UserID:           userID,
+		Name:             name,
+		CredentialID:     credentialID,
+		CredentialIDHash: utils.SHA256(credentialID),
+		PublicKey:        cred.PublicKey,
+		Algorithm:        cred.Algorithm,
+		SignCount:        cred.SignCount,
+		AAGUID:           formatAAGUID(cred.AAGUID),
+		Transports:       cred.Transports,
+		BackupEligible:   cred.BackupEligible,
+		BackupState:      cred.BackupState,
+	}
+	if err := s.webAuthnRepo.CreateCredential(record); err != nil {
+		logger.Errorf("保存安全密钥失败: %v", err)
+		return nil, errors.New("保存安全密钥失败")
+	}
+
+	logger.Infof("用户ID %d 注册了安全密钥 %s", userID, name)
+	return record, nil
+}
+
+// ListCredentials 获取用户的安全密钥
+func (s *webAuthnService) ListCredentials(userID uint) ([]*model.WebAuthnCredential, error) {
+	return s.webAuthnRepo.ListByUserID(userID)
+}
+
+// DeleteCredential 删除安全密钥；角色要求两步验证时不能删除最后一个验证方式
+func (s *webAuthnService) DeleteCredential(userID, id uint) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	credentials, err := s.webAuthnRepo.ListByUserID(userID)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, credential := range credentials {
+		if credential.ID == id {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return errors.New("凭据不存在")
+	}
+
+	if len(credentials) == 1 && !s.totpEnabled(userID) {
+		role, err := s.roleRepo.GetByName(user.Role)
+		if err == nil && role.RequireMFA {
+			return errors.New("当前角色要求两步验证，不能删除最后一个安全密钥")
+		}
+	}
+
+	if err := s.webAuthnRepo.DeleteCredential(userID, id); err != nil {
+		return err
+	}
+	logger.Infof("用户 %s 删除了安全密钥 %d", user.Username, id)
+	return nil
+}
+
+// BeginLogin 生成无密码登录选项。指定用户名时只允许该用户的凭据，用户不存在时不报错，避免暴露用户名；
+// 未指定用户名时由认证器选择通行密钥
+func (s *webAuthnService) BeginLogin(username string) (*webauthn.RequestOptions, error) {
+	if s.rp == nil {
+		return nil, errWebAuthnDisabled
+	}
+	var userID uint
+	var allow []webauthn.CredentialDescriptor
+	if username != "" {
+		if user, err := s.userRepo.GetByUsername(username); err == nil && user.Status == 1 {
+			userID = user.ID
+			if allow, err = s.descriptors(user.ID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	challenge, err := s.newChallenge(userID, model.WebAuthnPurposeLogin)
+	if err != nil {
+		return nil, err
+	}
+	return s.rp.RequestOptions(challenge, allow, webauthn.VerificationRequired), nil
+}
+
+// FinishLogin 校验无密码登录结果，要求认证器完成用户验证（PIN或生物识别）
+func (s *webAuthnService) FinishLogin(resp *webauthn.AssertionResponse) (*model.UserResponse, error) {
+	if s.rp == nil {
+		return nil, errWebAuthnDisabled
+	}
+	challenge, err := resp.Challenge()
+	if err != nil {
+		return nil, errWebAuthnChallenge
+	}
+	session, err := s.useChallenge(challenge, model.WebAuthnPurposeLogin)
+	if err != nil {
+		return nil, err
+	}
+	record, err := s.credential(resp)
+	if err != nil {
+		return nil, err
+	}
+	if session.UserID != 0 && session.UserID != record.UserID {
+		return nil, errWebAuthnFailed
+	}
+
+	assertion, err := s.verify(record, resp, challenge, true)
+	if err != nil {
+		return nil, err
+	}
+	if assertion.UserHandle != nil && string(assertion.UserHandle) != string(userHandle(record.UserID)) {
+		logger.Warnf("安全密钥 %d 返回的用户句柄与凭据所属用户不一致", record.ID)
+		return nil, errWebAuthnFailed
+	}
+
+	user, err := s.userRepo.GetByID(record.UserID)
+	if err != nil {
+		return nil, errWebAuthnFailed
+	}
+	if user.Status == 0 {
+		return nil, errors.New("用户已被禁用")
+	}
+	if err := s.userRepo.UpdateLastLogin(user.ID); err != nil {
+		logger.Warnf("更新最后登录时间失败: %v", err)
+	}
+
+	logger.Infof("用户使用安全密钥登录成功: %s", user.Username)
+	return user.ToResponse(), nil
+}
+
+// BeginSecondFactor 为密码登录后的第二步验证生成认证选项
+func (s *webAuthnService) BeginSecondFactor(userID uint) (*webauthn.RequestOptions, error) {
+	if s.rp == nil {
+		return nil, errWebAuthnDisabled
+	}
+	allow, err := s.descriptors(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(allow) == 0 {
+		return nil, errors.New("未注册安全密钥")
+	}
+	challenge, err := s.newChallenge(userID, model.WebAuthnPurposeMFA)
+	if err != nil {
+		return nil, err
+	}
+	return s.rp.RequestOptions(challenge, allow, webauthn.VerificationDiscouraged), nil
+}
+
+// VerifySecondFactor 校验第二步验证结果，凭据必须属于该用户
+func (s *webAuthnService) VerifySecondFactor(userID uint, resp *webauthn.AssertionResponse) error {
+	if s.rp == nil {
+		return errWebAuthnDisabled
+	}
+	challenge, err := resp.Challenge()
+	if err != nil {
+		return errWebAuthnChallenge
+	}
+	session, err := s.useChallenge(challenge, model.WebAuthnPurposeMFA)
+	if err != nil || session.UserID != userID {
+		return errWebAuthnChallenge
+	}
+	record, err := s.credential(resp)
+	if err != nil {
+		return err
+	}
+	if record.UserID != userID {
+		return errWebAuthnFailed
+	}
+	_, err = s.verify(record, resp, challenge, false)
+	return err
+}
+
+// newChallenge 生成挑战并保存认证会话
+func (s *webAuthnService) newChallenge(userID uint, purpose string) (string, error) {
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		return "", errors.New("生成认证挑战失败")
+	}
+	session := &model.WebAuthnChallenge{
+		UserID:        userID,
+		ChallengeHash: utils.SHA256(challenge),
+		Purpose:       purpose,
+		ExpiresAt:     time.Now().Add(webauthn.Timeout),
+	}
+	if err := s.webAuthnRepo.CreateChallenge(session); err != nil {
+		logger.Errorf("保存认证会话失败: %v", err)
+		return "", errors.New("生成认证挑战失败")
+	}
+	return challenge, nil
+}
+
+// useChallenge 获取并作废认证会话，会话只能使用一次
+func (s *webAuthnService) useChallenge(challenge, purpose string) (*model.WebAuthnChallenge, error) {
+	now := time.Now()
+	session, err := s.webAuthnRepo.UseChallenge(utils.SHA256(challenge), now)
+	if err != nil || session.Purpose != purpose || !now.Before(session.ExpiresAt) {
+		return nil, errWebAuthnChallenge
+	}
+	return session, nil
+}
+
+// credential 根据认证结果中的凭据ID查找凭据
+func (s *webAuthnService) credential(resp *webauthn.AssertionResponse) (*model.WebAuthnCredential, error) {
+	id, err := resp.CredentialID()
+	if err != nil {
+		return nil, errWebAuthnFailed
+	}
+	record, err := s.webAuthnRepo.GetCredentialByHash(utils.SHA256(webauthn.EncodeID(id)))
+	if err != nil {
+		return nil, errors.New("安全密钥未注册")
+	}
+	return record, nil
+}
+
+// verify 校验签名和签名计数，成功后更新凭据的使用记录
+func (s *webAuthnService) verify(record *model.WebAuthnCredential, resp *webauthn.AssertionResponse, challenge string, requireUV bool) (*webauthn.Assertion, error) {
+	id, err := webauthn.DecodeID(record.CredentialID)
+	if err != nil {
+		return nil, errWebAuthnFailed
+	}
+	cred := &webauthn.Credential{
+		ID:        id,
+		PublicKey: record.PublicKey,
+		Algorithm: record.Algorithm,
+		SignCount: record.SignCount,
+	}
+	assertion, err := s.rp.VerifyAssertion(resp, challenge, cred, requireUV)
+	if err != nil {
+		switch {
+		case errors.Is(err, webauthn.ErrSignCount):
+			logger.Warnf("安全密钥 %d（用户ID %d）签名计数未增加，可能已被克隆", record.ID, record.UserID)
+		case errors.Is(err, webauthn.ErrUserVerification):
+			return nil, errors.New("安全密钥未完成用户验证，请使用PIN或生物识别")
+		default:
+			logger.Warnf("安全密钥 %d 验证失败: %v", record.ID, err)
+		}
+		return nil, errWebAuthnFailed
+	}
+
+	if err := s.webAuthnRepo.UpdateUsage(record.ID, assertion.SignCount, assertion.BackupState, time.Now()); err != nil {
+		logger.Warnf("更新安全密钥使用记录失败: %v", err)
+	}
+	return assertion, nil
+}
+
+// descriptors 返回用户已注册凭据的描述
+func (s *webAuthnService) descriptors(userID uint) ([]webauthn.CredentialDescriptor, error) {
+	credentials, err := s.webAuthnRepo.ListByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	descriptors := make([]webauthn.CredentialDescriptor, 0, len(credentials))
+	for _, credential := range credentials {
+		descriptors = append(descriptors, webauthn.CredentialDescriptor{
+			Type:       "public-key",
+			ID:         credential.CredentialID,
+			Transports: credential.Transports,
+		})
+	}
+	return descriptors, nil
+}
+
+// totpEnabled 用户是否已启用TOTP两步验证
+func (s *webAuthnService) totpEnabled(userID uint) bool {
+	record, err := s.twoFactorRepo.GetByUserID(userID)
+	return err == nil && record.ConfirmedAt != nil
+}
+
+// userHandle 返回写入凭据的用户句柄
+func userHandle(userID uint) []byte {
+	return []byte(strconv.FormatUint(uint64(userID), 10))
+}
+
+// formatAAGUID 将认证器型号标识格式化为UUID
+func formatAAGUID(b []byte) string {
+	if len(b) != 16 {
+		return ""
+	}
+	h := hex.EncodeToString(b)
+	return h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
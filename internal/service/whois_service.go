@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"domain-admin/pkg/cache"
+	"domain-admin/pkg/config"
+	"domain-admin/pkg/logger"
+	"domain-admin/pkg/whois"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// WhoisService 域名注册信息查询服务接口
+type WhoisService interface {
+	Lookup(ctx context.Context, domain string, refresh bool) (*whois.Result, error)
+}
+
+type whoisService struct {
+	client   *whois.Client
+	cacheTTL time.Duration
+}
+
+// NewWhoisService 创建域名注册信息查询服务实例
+func NewWhoisService(cfg config.WhoisConfig) WhoisService {
+	client := whois.NewClient()
+	client.Timeout = cfg.LookupTimeout()
+	client.HTTPClient = &http.Client{Timeout: client.Timeout}
+	client.RDAPServers = cfg.RDAPServers
+	client.WhoisServers = cfg.WhoisServers
+
+	return &whoisService{
+		client:   client,
+		cacheTTL: cfg.TTL(),
+	}
+}
+
+// Lookup 查询域名注册信息，refresh 为 true 时跳过缓存
+func (s *whoisService) Lookup(ctx context.Context, domain string, refresh bool) (*whois.Result, error) {
+	name, err := whois.Normalize(domain)
+	if err != nil {
+		return nil, errors.New("域名格式错误")
+	}
+
+	if !refresh {
+		var cached whois.Result
+		err := cache.GetWhoisCache(ctx, name, &cached)
+		if err == nil {
+			cached.Cached = true
+			return &cached, nil
+		}
+		if !errors.Is(err, redis.Nil) {
+			logger.Warnf("读取WHOIS缓存失败: %v", err)
+		}
+	}
+
+	result, err := s.client.Lookup(ctx, name)
+	if err != nil {
+		if errors.Is(err, whois.ErrNotFound) {
+			return nil, errors.New("域名未注册或不存在")
+		}
+		logger.Errorf("查询域名 %s 注册信息失败: %v", name, err)
+		if strings.Contains(err.Error(), "no whois server") {
+			return nil, errors.New("不支持查询该顶级域名")
+		}
+		return nil, errors.New("查询域名注册信息失败")
+	}
+
+	if err := cache.SetWhoisCache(ctx, name, result, s.cacheTTL); err != nil {
+		logger.Warnf("写入WHOIS缓存失败: %v", err)
+	}
+	return result, nil
+}
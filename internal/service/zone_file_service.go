@@ -0,0 +1,309 @@
+package service
+
+import (
+	"domain-admin/internal/repository"
+	"domain-admin/model"
+	"domain-admin/pkg/logger"
+	"domain-admin/pkg/validator"
+	"domain-admin/pkg/zonefile"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 导出时生成的SOA记录参数
+const (
+	soaRefresh = 3600
+	soaRetry   = 600
+	soaExpire  = 604800
+)
+
+// ZoneFileService 区域文件导入导出服务接口
+type ZoneFileService interface {
+	Import(zoneID uint, req *model.ZoneImportRequest) (*model.ZoneImportResult, error)
+	Export(zoneID uint) (*model.ZoneExportResult, error)
+}
+
+type zoneFileService struct {
+	zoneRepo   repository.DNSZoneRepository
+	recordRepo repository.DNSRecordRepository
+}
+
+// NewZoneFileService 创建区域文件服务实例
+func NewZoneFileService(zoneRepo repository.DNSZoneRepository, recordRepo repository.DNSRecordRepository) ZoneFileService {
+	return &zoneFileService{
+		zoneRepo:   zoneRepo,
+		recordRepo: recordRepo,
+	}
+}
+
+// Import 导入区域文件
+// 已存在的相同记录会被跳过；任一记录校验失败或冲突时不写入任何记录。
+// DryRun 为 true 时只返回预览结果
+func (s *zoneFileService) Import(zoneID uint, req *model.ZoneImportRequest) (*model.ZoneImportResult, error) {
+	zone, err := s.zoneRepo.GetByID(zoneID)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := zonefile.Parse(strings.NewReader(req.Content), zonefile.Options{
+		Origin:     zone.Name,
+		DefaultTTL: uint32(zone.DefaultTTL),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("解析区域文件失败: %w", err)
+	}
+
+	existing, err := s.recordRepo.ListAll(zone.ID)
+	if err != nil {
+		return nil, fmt.Errorf("获取区域记录失败: %w", err)
+	}
+	byName := make(map[string][]*model.DNSRecord)
+	for _, record := range existing {
+		byName[record.Name] = append(byName[record.Name], record)
+	}
+
+	result := &model.ZoneImportResult{
+		DryRun:  req.DryRun,
+		Total:   len(parsed),
+		Records: []*model.DNSRecord{},
+		Skipped: []model.ZoneImportIssue{},
+		Errors:  []model.ZoneImportIssue{},
+	}
+
+	origin := zonefile.Fqdn(zone.Name)
+	for _, item := range parsed {
+		issue := model.ZoneImportIssue{Name: item.Name, Type: item.Type, Value: item.RData()}
+
+		if item.Name != origin && !strings.HasSuffix(item.Name, "."+origin) {
+			issue.Reason = "记录不属于当前区域"
+			result.Skipped = append(result.Skipped, issue)
+			continue
+		}
+		if item.Type == "SOA" {
+			issue.Reason = "SOA记录由服务商维护，已忽略"
+			result.Skipped = append(result.Skipped, issue)
+			continue
+		}
+
+		record, err := recordFromZoneFile(zone, item)
+		if err != nil {
+			issue.Reason = err.Error()
+			result.Skipped = append(result.Skipped, issue)
+			continue
+		}
+		issue.Name = record.Name
+
+		if err := validateRecord(record); err != nil {
+			issue.Reason = err.Error()
+			result.Errors = append(result.Errors, issue)
+			continue
+		}
+
+		var conflict error
+		for _, other := range byName[record.Name] {
+			if conflict = recordConflict(record, other); conflict != nil {
+				break
+			}
+		}
+		switch {
+		case errors.Is(conflict, errDuplicateRecord):
+			issue.Reason = "记录已存在"
+			result.Skipped = append(result.Skipped, issue)
+		case conflict != nil:
+			issue.Reason = conflict.Error()
+			result.Errors = append(result.Errors, issue)
+		default:
+			byName[record.Name] = append(byName[record.Name], record)
+			result.Records = append(result.Records, record)
+		}
+	}
+
+	if req.DryRun || len(result.Records) == 0 {
+		return result, nil
+	}
+	if len(result.Errors) > 0 {
+		logger.Warnf("区域 %s 的导入内容存在 %d 条错误记录，未导入任何记录", zone.Name, len(result.Errors))
+		return result, nil
+	}
+
+	if err := s.recordRepo.CreateBatch(result.Records); err != nil {
+		logger.Errorf("导入记录失败: %v", err)
+		return nil, errors.New("导入记录失败")
+	}
+	result.Applied = true
+
+	logger.Infof("导入区域文件成功: %s, 新增记录 %d, 跳过 %d", zone.Name, len(result.Records), len(result.Skipped))
+	return result, nil
+}
+
+// Export 将区域导出为区域文件，SOA记录根据区域信息生成
+func (s *zoneFileService) Export(zoneID uint) (*model.ZoneExportResult, error) {
+	zone, err := s.zoneRepo.GetByID(zoneID)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := s.recordRepo.ListAll(zone.ID)
+	if err != nil {
+		return nil, fmt.Errorf("获取区域记录失败: %w", err)
+	}
+
+	origin := zonefile.Fqdn(zone.Name)
+	updated := zone.UpdatedAt
+	mname, hasNS := "ns1."+origin, false
+
+	items := make([]zonefile.Record, 0, len(records)+1)
+	for _, record := range records {
+		item, err := recordToZoneFile(origin, record)
+		if err != nil {
+			return nil, fmt.Errorf("导出记录 %s %s 失败: %w", record.Name, record.Type, err)
+		}
+		items = append(items, item)
+
+		if record.UpdatedAt.After(updated) {
+			updated = record.UpdatedAt
+		}
+		// 主名称服务器取区域顶点的第一条NS记录
+		if record.Type == model.DNSRecordTypeNS && record.Name == "@" && !hasNS {
+			mname, hasNS = item.Value, true
+		}
+	}
+
+	items = append(items, zonefile.Record{
+		Name:  origin,
+		TTL:   uint32(zone.DefaultTTL),
+		Class: "IN",
+		Type:  "SOA",
+		SOA: &zonefile.SOA{
+			MName:   mname,
+			RName:   "hostmaster." + origin,
+			Serial:  soaSerial(updated),
+			Refresh: soaRefresh,
+			Retry:   soaRetry,
+			Expire:  soaExpire,
+			Minimum: uint32(zone.DefaultTTL),
+		},
+	})
+
+	var buf strings.Builder
+	if err := zonefile.Render(&buf, origin, uint32(zone.DefaultTTL), items); err != nil {
+		return nil, fmt.Errorf("生成区域文件失败: %w", err)
+	}
+
+	return &model.ZoneExportResult{
+		ZoneID:      zone.ID,
+		Zone:        zone.Name,
+		Filename:    zone.Name + ".zone",
+		RecordCount: len(records),
+		Content:     buf.String(),
+	}, nil
+}
+
+// recordFromZoneFile 将区域文件中的记录转换为区域记录
+func recordFromZoneFile(zone *model.DNSZone, item zonefile.Record) (*model.DNSRecord, error) {
+	record := &model.DNSRecord{
+		ZoneID: zone.ID,
+		Name:   RelativeRecordName(zone.Name, item.Name),
+		Type:   item.Type,
+		Value:  item.Value,
+		TTL:    int(item.TTL),
+	}
+
+	switch item.Type {
+	case model.DNSRecordTypeA, model.DNSRecordTypeAAAA, model.DNSRecordTypeCNAME, model.DNSRecordTypeNS:
+	case model.DNSRecordTypeMX:
+		record.Priority = int(item.Priority)
+	case model.DNSRecordTypeSRV:
+		record.Priority = int(item.Priority)
+		record.Weight = int(item.Weight)
+		record.Port = int(item.Port)
+	case model.DNSRecordTypeTXT:
+		// 单个字符串直接保存，多个字符串保存为带引号的形式
+		if len(item.Texts) == 1 && !strings.HasPrefix(item.Texts[0], `"`) {
+			record.Value = item.Texts[0]
+		} else {
+			record.Value = item.RData()
+		}
+	case model.DNSRecordTypeCAA:
+		record.Value = item.RData()
+	default:
+		return nil, fmt.Errorf("不支持的记录类型: %s", item.Type)
+	}
+	return record, nil
+}
+
+// recordToZoneFile 将区域记录转换为区域文件中的记录
+// 记录值中的主机名视为绝对域名
+func recordToZoneFile(origin string, record *model.DNSRecord) (zonefile.Record, error) {
+	item := zonefile.Record{
+		Name:  origin,
+		TTL:   uint32(record.TTL),
+		Class: "IN",
+		Type:  record.Type,
+		Value: record.Value,
+	}
+	if record.Name != "@" {
+		item.Name = record.Name + "." + origin
+	}
+
+	switch record.Type {
+	case model.DNSRecordTypeCNAME, model.DNSRecordTypeNS:
+		item.Value = zonefile.Fqdn(record.Value)
+	case model.DNSRecordTypeMX:
+		item.Priority = uint16(record.Priority)
+		item.Value = zonefile.Fqdn(record.Value)
+	case model.DNSRecordTypeSRV:
+		item.Priority = uint16(record.Priority)
+		item.Weight = uint16(record.Weight)
+		item.Port = uint16(record.Port)
+		item.Value = zonefile.Fqdn(record.Value)
+	case model.DNSRecordTypeTXT:
+		texts, err := validator.SplitTXTStrings(record.Value)
+		if err != nil {
+			return item, err
+		}
+		for _, text := range texts {
+			item.Texts = append(item.Texts, splitTXTChunks(text)...)
+		}
+	case model.DNSRecordTypeCAA:
+		fields := strings.SplitN(strings.TrimSpace(record.Value), " ", 3)
+		if len(fields) != 3 {
+			return item, errors.New("CAA记录格式错误")
+		}
+		flags, err := strconv.ParseUint(fields[0], 10, 8)
+		if err != nil {
+			return item, errors.New("CAA记录格式错误")
+		}
+		values, err := validator.SplitTXTStrings(fields[2])
+		if err != nil || len(values) != 1 {
+			return item, errors.New("CAA记录格式错误")
+		}
+		item.Flags, item.Tag, item.Value = uint8(flags), fields[1], values[0]
+	}
+	return item, nil
+}
+
+// splitTXTChunks 将超过255字节的TXT字符串拆分为多段
+func splitTXTChunks(text string) []string {
+	if len(text) <= validator.MaxTXTStringLength {
+		return []string{text}
+	}
+	var chunks []string
+	for len(text) > validator.MaxTXTStringLength {
+		chunks = append(chunks, text[:validator.MaxTXTStringLength])
+		text = text[validator.MaxTXTStringLength:]
+	}
+	if text != "" {
+		chunks = append(chunks, text)
+	}
+	return chunks
+}
+
+// soaSerial 按 YYYYMMDDHH 格式生成SOA序列号
+func soaSerial(t time.Time) uint32 {
+	serial, _ := strconv.ParseUint(t.UTC().Format("2006010215"), 10, 32)
+	return uint32(serial)
+}
@@ -0,0 +1,73 @@
+package model
+
+import (
+	"time"
+)
+
+// ACME证书状态
+const (
+	AcmeCertificateStatusPending = "pending"
+	AcmeCertificateStatusIssuing = "issuing"
+	AcmeCertificateStatusIssued  = "issued"
+	AcmeCertificateStatusFailed  = "failed"
+)
+
+// AcmeAccount ACME账号，每个目录地址和邮箱对应一个账号
+type AcmeAccount struct {
+	ID           uint   `json:"id" gorm:"primarykey"`
+	DirectoryURL string `json:"directory_url" gorm:"uniqueIndex:idx_acme_account;size:255;not null;comment:ACME目录地址"`
+	Email        string `json:"email" gorm:"uniqueIndex:idx_acme_account;size:100;comment:联系邮箱"`
+	URL          string `json:"url" gorm:"size:255;comment:账号地址"`
+	// PrivateCiphertext 使用数据密钥加密的PEM账号私钥，PrivateDataKey 为主密钥加密的数据密钥
+	PrivateCiphertext string    `json:"-" gorm:"type:text;comment:账号私钥(加密)"`
+	PrivateDataKey    string    `json:"-" gorm:"type:text;comment:数据密钥(加密)"`
+	PrivateKeyID      string    `json:"-" gorm:"size:64;comment:加密数据密钥的主密钥ID"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// AcmeCertificate 通过ACME签发的证书，私钥使用信封加密保存
+type AcmeCertificate struct {
+	ID              uint       `json:"id" gorm:"primarykey"`
+	CommonName      string     `json:"common_name" gorm:"size:253;index;not null;comment:主域名"`
+	Domains         []string   `json:"domains" gorm:"serializer:json;type:text;comment:证书包含的域名"`
+	ProviderAccount string     `json:"provider_account" gorm:"size:100;comment:完成DNS验证的云服务商账号"`
+	AutoRenew       bool       `json:"auto_renew" gorm:"comment:是否自动续期"`
+	Status          string     `json:"status" gorm:"size:20;index;default:pending;comment:状态(pending,issuing,issued,failed)"`
+	Issuer          string     `json:"issuer" gorm:"size:512;comment:颁发者"`
+	SerialNumber    string     `json:"serial_number" gorm:"size:128;comment:序列号"`
+	NotBefore       *time.Time `json:"not_before" gorm:"comment:生效时间"`
+	NotAfter        *time.Time `json:"not_after" gorm:"index;comment:到期时间"`
+	Certificate     string     `json:"-" gorm:"type:text;comment:证书链PEM"`
+	// PrivateCiphertext 使用数据密钥加密的PEM证书私钥，PrivateDataKey 为主密钥加密的数据密钥
+	PrivateCiphertext string     `json:"-" gorm:"type:text;comment:证书私钥(加密)"`
+	PrivateDataKey    string     `json:"-" gorm:"type:text;comment:数据密钥(加密)"`
+	PrivateKeyID      string     `json:"-" gorm:"size:64;comment:加密数据密钥的主密钥ID"`
+	LastError         string     `json:"last_error" gorm:"type:text;comment:最近一次签发错误"`
+	IssuedAt          *time.Time `json:"issued_at" gorm:"comment:最近签发时间"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// AcmeCertificateCreateRequest 申请证书请求
+type AcmeCertificateCreateRequest struct {
+	Domains         []string `json:"domains" validate:"required,min=1,max=100,dive,required,max=253"`
+	ProviderAccount string   `json:"provider_account" validate:"max=100"`
+	AutoRenew       *bool    `json:"auto_renew"`
+}
+
+// AcmeCertificateUpdateRequest 更新证书设置请求
+type AcmeCertificateUpdateRequest struct {
+	ProviderAccount *string `json:"provider_account" validate:"omitempty,max=100"`
+	AutoRenew       *bool   `json:"auto_renew"`
+}
+
+// AcmeCertificateBundle 解密后的证书和私钥
+type AcmeCertificateBundle struct {
+	ID          uint       `json:"id"`
+	CommonName  string     `json:"common_name"`
+	Domains     []string   `json:"domains"`
+	NotAfter    *time.Time `json:"not_after"`
+	Certificate string     `json:"certificate"`
+	PrivateKey  string     `json:"private_key"`
+}
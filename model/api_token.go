@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+)
+
+// APIToken 个人访问令牌，只保存令牌的SHA-256哈希，权限为所属用户权限的子集
+type APIToken struct {
+	ID          uint       `json:"id" gorm:"primarykey"`
+	UserID      uint       `json:"user_id" gorm:"not null;index;comment:所属用户ID"`
+	Name        string     `json:"name" gorm:"size:100;not null;comment:令牌名称"`
+	Prefix      string     `json:"prefix" gorm:"size:16;comment:令牌前几位，用于识别"`
+	TokenHash   string     `json:"-" gorm:"uniqueIndex;size:64;not null;comment:令牌哈希"`
+	Permissions []string   `json:"permissions" gorm:"serializer:json;type:text;comment:允许使用的权限名称"`
+	ExpiresAt   time.Time  `json:"expires_at" gorm:"not null;comment:过期时间"`
+	RevokedAt   *time.Time `json:"revoked_at" gorm:"comment:吊销时间"`
+	LastUsedAt  *time.Time `json:"last_used_at" gorm:"comment:最近使用时间"`
+	LastUsedIP  string     `json:"last_used_ip" gorm:"size:64;comment:最近使用IP"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// APITokenCreateRequest 创建API令牌请求
+type APITokenCreateRequest struct {
+	Name        string   `json:"name" validate:"required,max=100"`
+	Permissions []string `json:"permissions" validate:"required,min=1,dive,required"`
+	// ExpiresInDays 有效天数，为0时默认30天
+	ExpiresInDays int `json:"expires_in_days" validate:"min=0,max=365"`
+}
+
+// APITokenCreateResult 创建API令牌结果，Token 仅在创建时返回一次
+type APITokenCreateResult struct {
+	*APIToken
+	Token string `json:"token"`
+}
@@ -0,0 +1,54 @@
+package model
+
+import (
+	"time"
+)
+
+// 证书状态，多个问题同时存在时取最严重的一项
+const (
+	CertificateStatusPending   = "pending"
+	CertificateStatusValid     = "valid"
+	CertificateStatusExpiring  = "expiring"
+	CertificateStatusExpired   = "expired"
+	CertificateStatusMismatch  = "mismatch"
+	CertificateStatusUntrusted = "untrusted"
+	CertificateStatusError     = "error"
+)
+
+// Certificate TLS证书模型
+// 每条记录对应一个探测目标 host:port，证书字段保存最近一次成功探测的结果
+type Certificate struct {
+	ID            uint       `json:"id" gorm:"primarykey"`
+	Host          string     `json:"host" gorm:"uniqueIndex:idx_certificate_endpoint;size:253;not null;comment:主机名"`
+	Port          int        `json:"port" gorm:"uniqueIndex:idx_certificate_endpoint;not null;default:443;comment:端口"`
+	Subject       string     `json:"subject" gorm:"size:512;comment:证书主题"`
+	Issuer        string     `json:"issuer" gorm:"size:512;comment:颁发者"`
+	SANs          []string   `json:"sans" gorm:"serializer:json;type:text;comment:备用名称"`
+	SerialNumber  string     `json:"serial_number" gorm:"size:128;comment:序列号"`
+	Fingerprint   string     `json:"fingerprint" gorm:"size:64;comment:SHA-256指纹"`
+	NotBefore     *time.Time `json:"not_before" gorm:"comment:生效时间"`
+	NotAfter      *time.Time `json:"not_after" gorm:"index;comment:到期时间"`
+	ChainValid    bool       `json:"chain_valid" gorm:"default:false;comment:证书链是否可信"`
+	ChainError    string     `json:"chain_error" gorm:"type:text;comment:证书链校验错误"`
+	HostnameMatch bool       `json:"hostname_match" gorm:"default:false;comment:证书是否匹配主机名"`
+	Status        string     `json:"status" gorm:"size:20;index;default:pending;comment:状态(pending,valid,expiring,expired,mismatch,untrusted,error)"`
+	LastError     string     `json:"last_error" gorm:"type:text;comment:最近一次探测错误"`
+	LastProbedAt  *time.Time `json:"last_probed_at" gorm:"comment:最近探测时间"`
+	Remark        string     `json:"remark" gorm:"size:255;comment:备注"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// CertificateCreateRequest 添加证书探测目标请求
+type CertificateCreateRequest struct {
+	Host   string `json:"host" validate:"required,max=253"`
+	Port   int    `json:"port" validate:"omitempty,min=1,max=65535"`
+	Remark string `json:"remark" validate:"max=255"`
+}
+
+// CertificateUpdateRequest 更新证书探测目标请求
+type CertificateUpdateRequest struct {
+	Host   string  `json:"host" validate:"omitempty,max=253"`
+	Port   int     `json:"port" validate:"omitempty,min=1,max=65535"`
+	Remark *string `json:"remark" validate:"omitempty,max=255"`
+}
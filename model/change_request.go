@@ -0,0 +1,54 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// 变更请求状态
+const (
+	ChangeStatusPending   = "pending"   // 待审批
+	ChangeStatusApproved  = "approved"  // 已批准，正在执行
+	ChangeStatusExecuted  = "executed"  // 已执行
+	ChangeStatusFailed    = "failed"    // 已批准但执行失败
+	ChangeStatusRejected  = "rejected"  // 已拒绝
+	ChangeStatusCancelled = "cancelled" // 提交人已撤回
+	ChangeStatusExpired   = "expired"   // 超过有效期未审批
+)
+
+// ChangeRequest 需要审批的变更请求，保存被拦截的原始请求，审批通过后以提交人身份执行
+type ChangeRequest struct {
+	ID            uint            `json:"id" gorm:"primarykey"`
+	Method        string          `json:"method" gorm:"size:10;not null;comment:请求方法"`
+	Route         string          `json:"route" gorm:"size:255;not null;index;comment:路由"`
+	Path          string          `json:"path" gorm:"size:255;not null;comment:请求路径"`
+	Query         string          `json:"query" gorm:"size:1024;comment:查询参数"`
+	Body          string          `json:"body" gorm:"type:text;comment:请求内容"`
+	Diff          json.RawMessage `json:"diff" gorm:"type:text;serializer:json;comment:变更前后的数据"`
+	Reason        string          `json:"reason" gorm:"size:500;comment:变更原因"`
+	Status        string          `json:"status" gorm:"size:20;not null;index;comment:状态"`
+	RequesterID   uint            `json:"requester_id" gorm:"not null;index;comment:提交人ID"`
+	RequesterName string          `json:"requester_name" gorm:"size:50;comment:提交人"`
+	APITokenID    *uint           `json:"api_token_id" gorm:"comment:提交时使用的API令牌ID"`
+	ApproverID    *uint           `json:"approver_id" gorm:"comment:审批人ID"`
+	ApproverName  string          `json:"approver_name" gorm:"size:50;comment:审批人"`
+	Comment       string          `json:"comment" gorm:"size:500;comment:审批意见"`
+	ResultCode    int             `json:"result_code" gorm:"comment:执行结果状态码"`
+	Result        string          `json:"result" gorm:"type:text;comment:执行结果"`
+	ExpiresAt     time.Time       `json:"expires_at" gorm:"comment:过期时间"`
+	DecidedAt     *time.Time      `json:"decided_at" gorm:"comment:审批时间"`
+	ExecutedAt    *time.Time      `json:"executed_at" gorm:"comment:执行时间"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+}
+
+// ChangeDiff 变更前后的数据
+type ChangeDiff struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// ChangeDecisionRequest 审批变更请求
+type ChangeDecisionRequest struct {
+	Comment string `json:"comment" validate:"max=500"`
+}
@@ -0,0 +1,117 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DNS 记录类型
+const (
+	DNSRecordTypeA     = "A"
+	DNSRecordTypeAAAA  = "AAAA"
+	DNSRecordTypeCNAME = "CNAME"
+	DNSRecordTypeMX    = "MX"
+	DNSRecordTypeTXT   = "TXT"
+	DNSRecordTypeSRV   = "SRV"
+	DNSRecordTypeCAA   = "CAA"
+	DNSRecordTypeNS    = "NS"
+)
+
+// DNSZone DNS区域模型
+type DNSZone struct {
+	ID              uint           `json:"id" gorm:"primarykey"`
+	Name            string         `json:"name" gorm:"uniqueIndex;size:253;not null;comment:区域名称"`
+	DomainID        uint           `json:"domain_id" gorm:"index;comment:关联域名ID"`
+	ProviderAccount string         `json:"provider_account" gorm:"size:100;index;comment:云服务商账号"`
+	DefaultTTL      int            `json:"default_ttl" gorm:"default:600;comment:默认TTL"`
+	Remark          string         `json:"remark" gorm:"size:255;comment:备注"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// 关联关系
+	Records []DNSRecord `json:"records,omitempty" gorm:"foreignKey:ZoneID"`
+}
+
+// DNSRecord DNS记录模型
+// Name 为相对区域的主机记录，"@" 表示区域顶点；
+// MX/SRV 的优先级、SRV 的权重和端口单独存储，Value 仅保存目标
+type DNSRecord struct {
+	ID        uint           `json:"id" gorm:"primarykey"`
+	ZoneID    uint           `json:"zone_id" gorm:"index;not null;comment:区域ID"`
+	Name      string         `json:"name" gorm:"size:253;not null;index;comment:主机记录"`
+	Type      string         `json:"type" gorm:"size:10;not null;comment:记录类型"`
+	Value     string         `json:"value" gorm:"type:text;not null;comment:记录值"`
+	TTL       int            `json:"ttl" gorm:"default:600;comment:TTL"`
+	Priority  int            `json:"priority" gorm:"default:0;comment:优先级(MX,SRV)"`
+	Weight    int            `json:"weight" gorm:"default:0;comment:权重(SRV)"`
+	Port      int            `json:"port" gorm:"default:0;comment:端口(SRV)"`
+	Remark    string         `json:"remark" gorm:"size:255;comment:备注"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// DNSZoneCreateRequest 创建区域请求
+type DNSZoneCreateRequest struct {
+	Name            string `json:"name" validate:"required,fqdn,max=253"`
+	DomainID        uint   `json:"domain_id"`
+	ProviderAccount string `json:"provider_account" validate:"max=100"`
+	DefaultTTL      int    `json:"default_ttl" validate:"omitempty,min=1,max=604800"`
+	Remark          string `json:"remark" validate:"max=255"`
+}
+
+// DNSZoneUpdateRequest 更新区域请求
+type DNSZoneUpdateRequest struct {
+	DomainID        *uint   `json:"domain_id"`
+	ProviderAccount *string `json:"provider_account" validate:"omitempty,max=100"`
+	DefaultTTL      int     `json:"default_ttl" validate:"omitempty,min=1,max=604800"`
+	Remark          *string `json:"remark" validate:"omitempty,max=255"`
+}
+
+// DNSRecordRequest 创建/更新记录请求
+type DNSRecordRequest struct {
+	Name     string `json:"name" validate:"required,max=253"`
+	Type     string `json:"type" validate:"required,oneof=A AAAA CNAME MX TXT SRV CAA NS"`
+	Value    string `json:"value" validate:"required"`
+	TTL      int    `json:"ttl" validate:"omitempty,min=1,max=604800"`
+	Priority int    `json:"priority"`
+	Weight   int    `json:"weight"`
+	Port     int    `json:"port"`
+	Remark   string `json:"remark" validate:"max=255"`
+}
+
+// ZoneImportRequest 导入区域文件请求
+type ZoneImportRequest struct {
+	Content string `json:"content" validate:"required,max=1048576"`
+	DryRun  bool   `json:"dry_run"`
+}
+
+// ZoneImportIssue 导入时被跳过或校验失败的记录
+type ZoneImportIssue struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Value  string `json:"value"`
+	Reason string `json:"reason"`
+}
+
+// ZoneImportResult 导入区域文件结果
+// 存在校验失败的记录时不会写入任何记录，Applied 为 false
+type ZoneImportResult struct {
+	DryRun  bool              `json:"dry_run"`
+	Applied bool              `json:"applied"`
+	Total   int               `json:"total"`
+	Records []*DNSRecord      `json:"records"`
+	Skipped []ZoneImportIssue `json:"skipped"`
+	Errors  []ZoneImportIssue `json:"errors"`
+}
+
+// ZoneExportResult 导出区域文件结果
+type ZoneExportResult struct {
+	ZoneID      uint   `json:"zone_id"`
+	Zone        string `json:"zone"`
+	Filename    string `json:"filename"`
+	RecordCount int    `json:"record_count"`
+	Content     string `json:"content"`
+}
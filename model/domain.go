@@ -0,0 +1,57 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 域名状态
+const (
+	DomainStatusActive    = "active"
+	DomainStatusPending   = "pending"
+	DomainStatusExpired   = "expired"
+	DomainStatusSuspended = "suspended"
+)
+
+// Domain 域名模型
+type Domain struct {
+	ID              uint           `json:"id" gorm:"primarykey"`
+	Name            string         `json:"name" gorm:"uniqueIndex;size:253;not null;comment:域名"`
+	Registrar       string         `json:"registrar" gorm:"size:100;comment:注册商"`
+	ProviderAccount string         `json:"provider_account" gorm:"size:100;index;comment:云服务商账号"`
+	OwnerID         *uint          `json:"owner_id" gorm:"index;comment:负责人用户ID，为空表示没有负责人"`
+	ExpiresAt       *time.Time     `json:"expires_at" gorm:"index;comment:到期时间"`
+	Status          string         `json:"status" gorm:"size:20;default:active;comment:状态(active,pending,expired,suspended)"`
+	Tags            []string       `json:"tags" gorm:"serializer:json;type:text;comment:标签"`
+	Remark          string         `json:"remark" gorm:"size:255;comment:备注"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// 关联关系
+	Owner *User `json:"owner,omitempty" gorm:"foreignKey:OwnerID"`
+}
+
+// DomainCreateRequest 创建域名请求
+type DomainCreateRequest struct {
+	Name            string     `json:"name" validate:"required,fqdn,max=253"`
+	Registrar       string     `json:"registrar" validate:"max=100"`
+	ProviderAccount string     `json:"provider_account" validate:"max=100"`
+	OwnerID         uint       `json:"owner_id"`
+	ExpiresAt       *time.Time `json:"expires_at"`
+	Status          string     `json:"status" validate:"omitempty,oneof=active pending expired suspended"`
+	Tags            []string   `json:"tags" validate:"max=20,dive,min=1,max=50"`
+	Remark          string     `json:"remark" validate:"max=255"`
+}
+
+// DomainUpdateRequest 更新域名请求
+type DomainUpdateRequest struct {
+	Registrar       *string    `json:"registrar" validate:"omitempty,max=100"`
+	ProviderAccount *string    `json:"provider_account" validate:"omitempty,max=100"`
+	OwnerID         *uint      `json:"owner_id"`
+	ExpiresAt       *time.Time `json:"expires_at"`
+	Status          string     `json:"status" validate:"omitempty,oneof=active pending expired suspended"`
+	Tags            []string   `json:"tags" validate:"omitempty,max=20,dive,min=1,max=50"`
+	Remark          *string    `json:"remark" validate:"omitempty,max=255"`
+}
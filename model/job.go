@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+)
+
+// JobRun 定时任务执行记录
+type JobRun struct {
+	ID          uint       `json:"id" gorm:"primarykey"`
+	JobName     string     `json:"job_name" gorm:"size:100;index;not null;comment:任务名称"`
+	Trigger     string     `json:"trigger" gorm:"size:20;comment:触发方式(schedule,manual)"`
+	TriggeredBy uint       `json:"triggered_by" gorm:"comment:触发用户ID，0表示系统"`
+	Instance    string     `json:"instance" gorm:"size:255;comment:执行实例"`
+	Status      string     `json:"status" gorm:"size:20;index;comment:状态(running,success,failed)"`
+	Error       string     `json:"error" gorm:"type:text;comment:错误信息"`
+	StartedAt   time.Time  `json:"started_at" gorm:"index"`
+	FinishedAt  *time.Time `json:"finished_at"`
+	Duration    int64      `json:"duration" gorm:"comment:耗时(毫秒)"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// JobState 定时任务状态，多个实例共享
+type JobState struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	JobName   string    `json:"job_name" gorm:"uniqueIndex;size:100;not null;comment:任务名称"`
+	Paused    bool      `json:"paused" gorm:"default:false;comment:是否暂停"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+)
+
+// JWTKey 保存在数据库中的JWT签名密钥，私钥使用信封加密保存，公钥明文保存用于发布JWKS
+type JWTKey struct {
+	ID        uint   `json:"id" gorm:"primarykey"`
+	Kid       string `json:"kid" gorm:"uniqueIndex;size:64;not null;comment:密钥ID"`
+	Algorithm string `json:"algorithm" gorm:"size:16;not null;comment:签名算法"`
+	PublicKey string `json:"public_key" gorm:"type:text;not null;comment:PEM公钥"`
+	// PrivateCiphertext 使用数据密钥加密的PEM私钥，PrivateDataKey 为主密钥加密的数据密钥
+	PrivateCiphertext string     `json:"-" gorm:"type:text;comment:私钥(加密)"`
+	PrivateDataKey    string     `json:"-" gorm:"type:text;comment:数据密钥(加密)"`
+	PrivateKeyID      string     `json:"-" gorm:"size:64;comment:加密数据密钥的主密钥ID"`
+	RetiredAt         *time.Time `json:"retired_at" gorm:"comment:停用时间，停用后不再用于验证"`
+	Signing           bool       `json:"signing" gorm:"-"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
+
+// JWTKeyRotateRequest 轮换签名密钥请求
+type JWTKeyRotateRequest struct {
+	// Algorithm 新密钥的签名算法，为空时默认ES256
+	Algorithm string `json:"algorithm" validate:"omitempty,oneof=RS256 ES256 EdDSA"`
+}
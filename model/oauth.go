@@ -0,0 +1,133 @@
+package model
+
+import (
+	"time"
+)
+
+// OAuth2 授权类型
+const (
+	OAuthGrantAuthorizationCode = "authorization_code"
+	OAuthGrantClientCredentials = "client_credentials"
+)
+
+// OAuth2 作用域，roles 在令牌和用户信息中返回角色及权限
+const (
+	OAuthScopeOpenID  = "openid"
+	OAuthScopeProfile = "profile"
+	OAuthScopeEmail   = "email"
+	OAuthScopeRoles   = "roles"
+)
+
+// OAuthClient 接入本系统登录的应用，只保存客户端密钥的SHA-256哈希
+type OAuthClient struct {
+	ID         uint   `json:"id" gorm:"primarykey"`
+	ClientID   string `json:"client_id" gorm:"uniqueIndex;size:64;not null;comment:客户端ID"`
+	Name       string `json:"name" gorm:"size:100;not null;comment:应用名称"`
+	SecretHash string `json:"-" gorm:"size:64;comment:客户端密钥哈希"`
+	// Public 公开客户端（单页应用、命令行工具）没有密钥，只能使用授权码+PKCE
+	Public       bool     `json:"public" gorm:"not null;default:false;comment:是否为公开客户端"`
+	RedirectURIs []string `json:"redirect_uris" gorm:"serializer:json;type:text;comment:允许的回调地址"`
+	GrantTypes   []string `json:"grant_types" gorm:"serializer:json;type:text;comment:允许的授权类型"`
+	Scopes       []string `json:"scopes" gorm:"serializer:json;type:text;comment:允许申请的作用域"`
+	// Role 客户端凭据模式下令牌使用的角色，决定返回的角色和权限声明
+	Role      string    `json:"role" gorm:"size:50;comment:客户端凭据模式的角色"`
+	Status    int       `json:"status" gorm:"default:1;comment:1-启用 0-禁用"`
+	CreatedBy uint      `json:"created_by" gorm:"comment:创建人ID"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// OAuthAuthorizationCode 授权码，只保存哈希，使用一次后失效
+type OAuthAuthorizationCode struct {
+	ID            uint       `json:"id" gorm:"primarykey"`
+	CodeHash      string     `json:"-" gorm:"uniqueIndex;size:64;not null;comment:授权码哈希"`
+	ClientID      string     `json:"client_id" gorm:"size:64;not null;index;comment:客户端ID"`
+	UserID        uint       `json:"user_id" gorm:"not null;comment:授权用户ID"`
+	RedirectURI   string     `json:"redirect_uri" gorm:"size:255;not null;comment:回调地址"`
+	Scope         string     `json:"scope" gorm:"size:255;comment:授权的作用域"`
+	Nonce         string     `json:"-" gorm:"size:255;comment:ID Token的nonce"`
+	CodeChallenge string     `json:"-" gorm:"size:128;not null;comment:PKCE挑战"`
+	ExpiresAt     time.Time  `json:"expires_at" gorm:"not null;comment:过期时间"`
+	UsedAt        *time.Time `json:"used_at" gorm:"comment:使用时间"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// OAuthAccessToken 颁发给应用的访问令牌，只保存哈希；客户端凭据模式的令牌没有用户
+type OAuthAccessToken struct {
+	ID                  uint       `json:"id" gorm:"primarykey"`
+	TokenHash           string     `json:"-" gorm:"uniqueIndex;size:64;not null;comment:令牌哈希"`
+	ClientID            string     `json:"client_id" gorm:"size:64;not null;index;comment:客户端ID"`
+	UserID              *uint      `json:"user_id" gorm:"index;comment:授权用户ID"`
+	Scope               string     `json:"scope" gorm:"size:255;comment:作用域"`
+	AuthorizationCodeID *uint      `json:"authorization_code_id" gorm:"index;comment:换取令牌的授权码"`
+	ExpiresAt           time.Time  `json:"expires_at" gorm:"not null;comment:过期时间"`
+	RevokedAt           *time.Time `json:"revoked_at" gorm:"comment:吊销时间"`
+	CreatedAt           time.Time  `json:"created_at"`
+}
+
+// OAuthClientRequest 创建或更新应用请求
+type OAuthClientRequest struct {
+	Name         string   `json:"name" validate:"required,max=100"`
+	Public       bool     `json:"public"`
+	RedirectURIs []string `json:"redirect_uris" validate:"max=20,dive,required,url,max=255"`
+	GrantTypes   []string `json:"grant_types" validate:"required,min=1,dive,oneof=authorization_code client_credentials"`
+	Scopes       []string `json:"scopes" validate:"dive,oneof=openid profile email roles"`
+	Role         string   `json:"role" validate:"max=50"`
+	Status       *int     `json:"status" validate:"omitempty,oneof=0 1"`
+}
+
+// OAuthClientSecretResult 创建应用或重置密钥的结果，ClientSecret 只返回一次
+type OAuthClientSecretResult struct {
+	*OAuthClient
+	ClientSecret string `json:"client_secret,omitempty"`
+}
+
+// OAuthAuthorizeRequest 授权请求参数，前端授权页原样提交 /oauth/authorize 的查询参数
+type OAuthAuthorizeRequest struct {
+	ResponseType        string `json:"response_type" form:"response_type"`
+	ClientID            string `json:"client_id" form:"client_id"`
+	RedirectURI         string `json:"redirect_uri" form:"redirect_uri"`
+	Scope               string `json:"scope" form:"scope"`
+	State               string `json:"state" form:"state"`
+	Nonce               string `json:"nonce" form:"nonce"`
+	CodeChallenge       string `json:"code_challenge" form:"code_challenge"`
+	CodeChallengeMethod string `json:"code_challenge_method" form:"code_challenge_method"`
+	// Approve 用户是否同意授权，只在提交授权时使用
+	Approve bool `json:"approve" form:"-"`
+}
+
+// OAuthAuthorizeInfo 授权页展示的应用和作用域
+type OAuthAuthorizeInfo struct {
+	ClientID   string   `json:"client_id"`
+	ClientName string   `json:"client_name"`
+	Scopes     []string `json:"scopes"`
+}
+
+// OAuthAuthorizeResult 授权结果，前端跳转到 RedirectTo
+type OAuthAuthorizeResult struct {
+	RedirectTo string `json:"redirect_to"`
+}
+
+// OAuthTokenResponse 令牌端点响应
+type OAuthTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+	Scope       string `json:"scope,omitempty"`
+	IDToken     string `json:"id_token,omitempty"`
+}
+
+// OAuthIntrospection 令牌自省结果，令牌无效时只返回 active=false
+type OAuthIntrospection struct {
+	Active      bool     `json:"active"`
+	Scope       string   `json:"scope,omitempty"`
+	ClientID    string   `json:"client_id,omitempty"`
+	Username    string   `json:"username,omitempty"`
+	TokenType   string   `json:"token_type,omitempty"`
+	Exp         int64    `json:"exp,omitempty"`
+	Iat         int64    `json:"iat,omitempty"`
+	Sub         string   `json:"sub,omitempty"`
+	Iss         string   `json:"iss,omitempty"`
+	Roles       []string `json:"roles,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+}
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+)
+
+// OIDCLoginState 单点登录授权请求的状态，按 state 哈希查找，回调时使用一次后失效
+type OIDCLoginState struct {
+	ID           uint       `json:"id" gorm:"primarykey"`
+	Provider     string     `json:"provider" gorm:"size:64;not null;comment:身份提供方"`
+	StateHash    string     `json:"-" gorm:"uniqueIndex;size:64;not null;comment:state哈希"`
+	Nonce        string     `json:"-" gorm:"size:64;not null;comment:ID Token的nonce"`
+	CodeVerifier string     `json:"-" gorm:"size:128;not null;comment:PKCE验证码"`
+	ExpiresAt    time.Time  `json:"expires_at" gorm:"not null;comment:过期时间"`
+	UsedAt       *time.Time `json:"used_at" gorm:"comment:使用时间"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// UserIdentity 用户与外部身份提供方账号的关联
+type UserIdentity struct {
+	ID          uint       `json:"id" gorm:"primarykey"`
+	UserID      uint       `json:"user_id" gorm:"not null;index;comment:用户ID"`
+	Provider    string     `json:"provider" gorm:"size:64;not null;uniqueIndex:idx_identity_provider_subject;comment:身份提供方"`
+	Subject     string     `json:"subject" gorm:"size:255;not null;uniqueIndex:idx_identity_provider_subject;comment:身份提供方的用户标识"`
+	Email       string     `json:"email" gorm:"size:100;comment:身份提供方返回的邮箱"`
+	Groups      []string   `json:"groups" gorm:"serializer:json;type:text;comment:最近一次登录的用户组"`
+	Provisioned bool       `json:"provisioned" gorm:"comment:用户是否由该身份提供方创建，只有这样的用户才按用户组同步角色"`
+	LastLoginAt *time.Time `json:"last_login_at" gorm:"comment:最近登录时间"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// OIDCProviderInfo 登录页展示的身份提供方
+type OIDCProviderInfo struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	LoginURL    string `json:"login_url"`
+}
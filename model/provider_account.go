@@ -0,0 +1,43 @@
+package model
+
+import (
+	"time"
+)
+
+// ProviderAccount 云服务商账号，AccessSecret 使用信封加密保存
+type ProviderAccount struct {
+	ID        uint              `json:"id" gorm:"primarykey"`
+	Name      string            `json:"name" gorm:"uniqueIndex;size:100;not null;comment:账号名称"`
+	Type      string            `json:"type" gorm:"size:50;not null;comment:服务商类型"`
+	AccessKey string            `json:"access_key" gorm:"size:255;comment:访问密钥ID"`
+	Options   map[string]string `json:"options" gorm:"serializer:json;type:text;comment:服务商特定参数"`
+	Remark    string            `json:"remark" gorm:"size:255;comment:备注"`
+	// SecretCiphertext 使用数据密钥加密的AccessSecret，SecretDataKey 为主密钥加密的数据密钥
+	SecretCiphertext string     `json:"-" gorm:"type:text;comment:访问密钥(加密)"`
+	SecretDataKey    string     `json:"-" gorm:"type:text;comment:数据密钥(加密)"`
+	SecretKeyID      string     `json:"secret_key_id" gorm:"size:64;index;comment:加密数据密钥的主密钥ID"`
+	HasSecret        bool       `json:"has_secret" gorm:"-"`
+	LastTestAt       *time.Time `json:"last_test_at" gorm:"comment:最近连接测试时间"`
+	LastTestOK       bool       `json:"last_test_ok" gorm:"comment:最近连接测试是否成功"`
+	LastTestError    string     `json:"last_test_error" gorm:"type:text;comment:最近连接测试错误"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// ProviderAccountCreateRequest 创建云服务商账号请求
+type ProviderAccountCreateRequest struct {
+	Name         string            `json:"name" validate:"required,max=100"`
+	Type         string            `json:"type" validate:"required,max=50"`
+	AccessKey    string            `json:"access_key" validate:"max=255"`
+	AccessSecret string            `json:"access_secret" validate:"max=4096"`
+	Options      map[string]string `json:"options"`
+	Remark       string            `json:"remark" validate:"max=255"`
+}
+
+// ProviderAccountUpdateRequest 更新云服务商账号请求，字段为空时不修改
+type ProviderAccountUpdateRequest struct {
+	AccessKey    *string           `json:"access_key" validate:"omitempty,max=255"`
+	AccessSecret *string           `json:"access_secret" validate:"omitempty,max=4096"`
+	Options      map[string]string `json:"options"`
+	Remark       *string           `json:"remark" validate:"omitempty,max=255"`
+}
@@ -12,7 +12,9 @@ type Role struct {
 	Name        string         `json:"name" gorm:"uniqueIndex;size:50;not null;comment:角色名称"`
 	DisplayName string         `json:"display_name" gorm:"size:100;not null;comment:角色显示名称"`
 	Description string         `json:"description" gorm:"size:255;comment:角色描述"`
+	Level       int            `json:"level" gorm:"default:0;comment:角色等级，数值越大级别越高"`
 	Status      int            `json:"status" gorm:"default:1;comment:状态 1:启用 0:禁用"`
+	RequireMFA  bool           `json:"require_mfa" gorm:"default:false;comment:是否要求两步验证"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
@@ -32,6 +34,7 @@ type Permission struct {
 	Resource    string         `json:"resource" gorm:"size:100;not null;comment:资源路径"`
 	Action      string         `json:"action" gorm:"size:20;not null;comment:操作类型(GET,POST,PUT,DELETE,*)"`
 	Type        string         `json:"type" gorm:"size:20;default:menu;comment:权限类型(menu,button,api)"`
+	Condition   string         `json:"condition" gorm:"column:attr_condition;size:20;comment:属性条件(self,lower_role)，为空时不限制目标对象"`
 	Sort        int            `json:"sort" gorm:"default:0;comment:排序"`
 	Status      int            `json:"status" gorm:"default:1;comment:状态 1:启用 0:禁用"`
 	CreatedAt   time.Time      `json:"created_at"`
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+)
+
+// RefreshToken 刷新令牌，只保存令牌的SHA-256哈希。每次刷新都会轮换为同一令牌族的新令牌，
+// 已轮换的令牌再次出现时视为泄露，整个令牌族被吊销
+type RefreshToken struct {
+	ID        uint       `json:"id" gorm:"primarykey"`
+	UserID    uint       `json:"user_id" gorm:"not null;index;comment:所属用户ID"`
+	FamilyID  string     `json:"family_id" gorm:"size:32;not null;index;comment:令牌族ID，登录时生成，轮换时继承"`
+	TokenHash string     `json:"-" gorm:"uniqueIndex;size:64;not null;comment:令牌哈希"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null;comment:过期时间"`
+	UsedAt    *time.Time `json:"used_at" gorm:"comment:轮换时间"`
+	RevokedAt *time.Time `json:"revoked_at" gorm:"comment:吊销时间"`
+	ClientIP  string     `json:"client_ip" gorm:"size:64;comment:签发时的客户端IP"`
+	UserAgent string     `json:"user_agent" gorm:"size:255;comment:签发时的User-Agent"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// RefreshTokenRequest 刷新令牌请求
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// TokenPair 访问令牌和刷新令牌，ExpiresIn 为访问令牌的有效秒数
+type TokenPair struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
@@ -0,0 +1,53 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// 修订记录的实体类型
+const (
+	RevisionEntityRole       = "role"
+	RevisionEntityPermission = "permission"
+)
+
+// 修订记录的操作类型
+const (
+	RevisionActionUpdate            = "update"
+	RevisionActionAssignPermissions = "assign_permissions"
+	RevisionActionDelete            = "delete"
+	RevisionActionRollback          = "rollback"
+)
+
+// Revision 实体修订记录，保存每次修改前后的快照，Rev 按实体从1开始递增
+type Revision struct {
+	ID         uint            `json:"id" gorm:"primarykey"`
+	EntityType string          `json:"entity_type" gorm:"size:50;not null;uniqueIndex:idx_revision_entity_rev;comment:实体类型"`
+	EntityID   uint            `json:"entity_id" gorm:"not null;uniqueIndex:idx_revision_entity_rev;comment:实体ID"`
+	Rev        int             `json:"rev" gorm:"not null;uniqueIndex:idx_revision_entity_rev;comment:修订号"`
+	Action     string          `json:"action" gorm:"size:50;not null;comment:操作类型"`
+	Before     json.RawMessage `json:"before" gorm:"type:text;serializer:json;comment:修改前快照"`
+	After      json.RawMessage `json:"after" gorm:"type:text;serializer:json;comment:修改后快照"`
+	ActorID    uint            `json:"actor_id" gorm:"index;comment:操作人ID"`
+	ActorName  string          `json:"actor_name" gorm:"size:50;comment:操作人"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// RoleSnapshot 角色快照，包含角色字段和权限集合
+type RoleSnapshot struct {
+	Name          string   `json:"name"`
+	DisplayName   string   `json:"display_name"`
+	Description   string   `json:"description"`
+	Level         int      `json:"level"`
+	Status        int      `json:"status"`
+	RequireMFA    bool     `json:"require_mfa"`
+	PermissionIDs []uint   `json:"permission_ids"`
+	Permissions   []string `json:"permissions"`
+}
+
+// RoleRollbackResult 角色回滚结果
+type RoleRollbackResult struct {
+	Role *Role `json:"role"`
+	// MissingPermissionIDs 快照中已被删除、无法恢复的权限
+	MissingPermissionIDs []uint `json:"missing_permission_ids"`
+}
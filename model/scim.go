@@ -0,0 +1,61 @@
+package model
+
+import (
+	"time"
+)
+
+// SCIMUser SCIM 用户资源，对应 User：userName 对应用户名，displayName 对应昵称，active 对应状态，
+// groups 为用户所属的角色（只读，通过 Group 的 members 修改）
+type SCIMUser struct {
+	Schemas      []string          `json:"schemas"`
+	ID           string            `json:"id,omitempty"`
+	ExternalID   string            `json:"externalId,omitempty"`
+	UserName     string            `json:"userName"`
+	Name         *SCIMName         `json:"name,omitempty"`
+	DisplayName  string            `json:"displayName,omitempty"`
+	Emails       []SCIMMultiValue  `json:"emails,omitempty"`
+	PhoneNumbers []SCIMMultiValue  `json:"phoneNumbers,omitempty"`
+	Active       *bool             `json:"active,omitempty"`
+	Password     string            `json:"password,omitempty"`
+	Groups       []SCIMMemberValue `json:"groups,omitempty"`
+	Meta         *SCIMMeta         `json:"meta,omitempty"`
+}
+
+// SCIMName 用户姓名
+type SCIMName struct {
+	Formatted  string `json:"formatted,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+}
+
+// SCIMMultiValue 邮箱、电话等多值属性的元素
+type SCIMMultiValue struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// SCIMGroup SCIM 用户组资源，对应 Role：displayName 对应角色名称，members 为该角色的用户
+type SCIMGroup struct {
+	Schemas     []string          `json:"schemas"`
+	ID          string            `json:"id,omitempty"`
+	ExternalID  string            `json:"externalId,omitempty"`
+	DisplayName string            `json:"displayName"`
+	Members     []SCIMMemberValue `json:"members,omitempty"`
+	Meta        *SCIMMeta         `json:"meta,omitempty"`
+}
+
+// SCIMMemberValue 用户组成员或用户所属的用户组
+type SCIMMemberValue struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+// SCIMMeta 资源元数据
+type SCIMMeta struct {
+	ResourceType string    `json:"resourceType"`
+	Created      time.Time `json:"created"`
+	LastModified time.Time `json:"lastModified"`
+	Location     string    `json:"location"`
+}
@@ -0,0 +1,79 @@
+package model
+
+import (
+	"time"
+)
+
+// 同步任务状态
+const (
+	SyncRunStatusRunning = "running"
+	SyncRunStatusSuccess = "success"
+	SyncRunStatusFailed  = "failed"
+)
+
+// 同步变更类型
+const (
+	SyncChangeAdded   = "added"
+	SyncChangeRemoved = "removed"
+	SyncChangeChanged = "changed"
+)
+
+// SyncRun 云服务商区域同步任务
+type SyncRun struct {
+	ID              uint       `json:"id" gorm:"primarykey"`
+	ProviderAccount string     `json:"provider_account" gorm:"size:100;index;not null;comment:云服务商账号"`
+	Status          string     `json:"status" gorm:"size:20;index;comment:状态(running,success,failed)"`
+	ZoneCount       int        `json:"zone_count" gorm:"comment:区域数量"`
+	RecordCount     int        `json:"record_count" gorm:"comment:记录数量"`
+	Added           int        `json:"added" gorm:"comment:新增记录数"`
+	Removed         int        `json:"removed" gorm:"comment:删除记录数"`
+	Changed         int        `json:"changed" gorm:"comment:变更记录数"`
+	Error           string     `json:"error" gorm:"type:text;comment:错误信息"`
+	TriggeredBy     uint       `json:"triggered_by" gorm:"comment:触发用户ID，0表示系统"`
+	StartedAt       time.Time  `json:"started_at"`
+	FinishedAt      *time.Time `json:"finished_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+
+	// 关联关系
+	Changes []SyncChange `json:"changes,omitempty" gorm:"foreignKey:RunID"`
+}
+
+// SnapshotRecord 快照中的DNS记录
+type SnapshotRecord struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Value    string `json:"value"`
+	TTL      int    `json:"ttl"`
+	Priority int    `json:"priority"`
+}
+
+// ZoneSnapshot 某次同步时区域的完整记录快照
+type ZoneSnapshot struct {
+	ID              uint             `json:"id" gorm:"primarykey"`
+	RunID           uint             `json:"run_id" gorm:"index;not null;comment:同步任务ID"`
+	ProviderAccount string           `json:"provider_account" gorm:"size:100;index;comment:云服务商账号"`
+	ZoneName        string           `json:"zone_name" gorm:"size:253;index;comment:区域名称"`
+	RecordCount     int              `json:"record_count" gorm:"comment:记录数量"`
+	Records         []SnapshotRecord `json:"records" gorm:"serializer:json;type:text;comment:记录快照"`
+	CreatedAt       time.Time        `json:"created_at"`
+}
+
+// SyncChange 同步时检测到的记录变更
+type SyncChange struct {
+	ID         uint            `json:"id" gorm:"primarykey"`
+	RunID      uint            `json:"run_id" gorm:"index;not null;comment:同步任务ID"`
+	ZoneName   string          `json:"zone_name" gorm:"size:253;comment:区域名称"`
+	Action     string          `json:"action" gorm:"size:20;comment:变更类型(added,removed,changed)"`
+	RecordName string          `json:"record_name" gorm:"size:253;comment:主机记录"`
+	RecordType string          `json:"record_type" gorm:"size:10;comment:记录类型"`
+	Before     *SnapshotRecord `json:"before" gorm:"serializer:json;type:text;comment:变更前"`
+	After      *SnapshotRecord `json:"after" gorm:"serializer:json;type:text;comment:变更后"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// SyncRunRequest 触发同步请求
+type SyncRunRequest struct {
+	ProviderAccount string `json:"provider_account" validate:"max=100"`
+}
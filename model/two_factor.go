@@ -0,0 +1,87 @@
+package model
+
+import (
+	"time"
+)
+
+// 登录挑战的用途
+const (
+	LoginChallengeVerify = "verify" // 已启用两步验证，需要输入验证码
+	LoginChallengeEnroll = "enroll" // 角色要求两步验证但尚未绑定，需要先完成绑定
+)
+
+// 登录第二步可用的验证方式
+const (
+	TwoFactorMethodTOTP     = "totp"     // 验证器应用的验证码或恢复码
+	TwoFactorMethodWebAuthn = "webauthn" // 安全密钥或通行密钥
+)
+
+// UserTOTP 用户的TOTP两步验证配置，密钥使用信封加密保存，恢复码只保存哈希
+type UserTOTP struct {
+	ID     uint `json:"id" gorm:"primarykey"`
+	UserID uint `json:"user_id" gorm:"uniqueIndex;not null;comment:用户ID"`
+	// SecretCiphertext 使用数据密钥加密的TOTP密钥，SecretDataKey 为主密钥加密的数据密钥
+	SecretCiphertext string     `json:"-" gorm:"type:text;not null;comment:TOTP密钥(加密)"`
+	SecretDataKey    string     `json:"-" gorm:"type:text;comment:数据密钥(加密)"`
+	SecretKeyID      string     `json:"-" gorm:"size:64;comment:加密数据密钥的主密钥ID"`
+	ConfirmedAt      *time.Time `json:"confirmed_at" gorm:"comment:绑定确认时间，为空表示尚未确认"`
+	LastStep         int64      `json:"-" gorm:"comment:最近使用的验证码时间步，防止重放"`
+	RecoveryCodes    []string   `json:"-" gorm:"serializer:json;type:text;comment:未使用的恢复码哈希"`
+	FailedAttempts   int        `json:"-" gorm:"default:0;comment:连续验证失败次数"`
+	LockedUntil      *time.Time `json:"-" gorm:"comment:验证锁定截止时间"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// LoginChallenge 密码校验通过后等待两步验证的登录挑战，只保存令牌哈希，使用一次后失效
+type LoginChallenge struct {
+	ID        uint       `json:"id" gorm:"primarykey"`
+	UserID    uint       `json:"user_id" gorm:"not null;index;comment:用户ID"`
+	TokenHash string     `json:"-" gorm:"uniqueIndex;size:64;not null;comment:挑战令牌哈希"`
+	Purpose   string     `json:"purpose" gorm:"size:16;not null;comment:用途 verify/enroll"`
+	Attempts  int        `json:"attempts" gorm:"default:0;comment:验证失败次数"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null;comment:过期时间"`
+	UsedAt    *time.Time `json:"used_at" gorm:"comment:使用时间"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TwoFactorStatus 当前用户的两步验证状态
+type TwoFactorStatus struct {
+	Enabled                bool       `json:"enabled"`
+	Pending                bool       `json:"pending"`  // 已生成密钥但尚未确认
+	Required               bool       `json:"required"` // 用户角色要求启用两步验证
+	ConfirmedAt            *time.Time `json:"confirmed_at"`
+	RecoveryCodesRemaining int        `json:"recovery_codes_remaining"`
+	WebAuthnCredentials    int64      `json:"webauthn_credentials"` // 已注册的安全密钥数量，也可用于两步验证
+}
+
+// TwoFactorSetupResult 绑定两步验证时返回的密钥，只在绑定时返回
+type TwoFactorSetupResult struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+// TwoFactorCodeRequest 两步验证码请求，code 可以是验证器中的6位验证码，部分接口也接受恢复码
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required,max=32"`
+}
+
+// TwoFactorChallengeRequest 使用登录挑战令牌的请求
+type TwoFactorChallengeRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+}
+
+// TwoFactorLoginRequest 登录第二步请求，code 为验证码或恢复码
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required,max=32"`
+}
+
+// LoginChallengeResult 需要两步验证时登录接口返回的挑战
+type LoginChallengeResult struct {
+	MFARequired    bool     `json:"mfa_required"`
+	EnrollRequired bool     `json:"enroll_required"`
+	Methods        []string `json:"methods"` // 可用的验证方式，绑定挑战为空
+	ChallengeToken string   `json:"challenge_token"`
+	ExpiresIn      int64    `json:"expires_in"`
+}
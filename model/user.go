@@ -8,19 +8,21 @@ import (
 
 // User 用户模型
 type User struct {
-	ID        uint           `json:"id" gorm:"primarykey"`
-	Username  string         `json:"username" gorm:"uniqueIndex;size:50;not null" validate:"required,min=3,max=50"`
-	Email     string         `json:"email" gorm:"uniqueIndex;size:100;not null" validate:"required,email"`
-	Password  string         `json:"-" gorm:"size:255;not null" validate:"required,min=6"`
-	Nickname  string         `json:"nickname" gorm:"size:50"`
-	Avatar    string         `json:"avatar" gorm:"size:255"`
-	Phone     string         `json:"phone" gorm:"size:20"`
-	Role      string         `json:"role" gorm:"size:20;default:user" validate:"required,oneof=admin user"`
-	Status    int            `json:"status" gorm:"default:1;comment:1-正常 0-禁用"`
-	LastLogin *time.Time     `json:"last_login"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+	ID        uint       `json:"id" gorm:"primarykey"`
+	Username  string     `json:"username" gorm:"uniqueIndex;size:50;not null" validate:"required,min=3,max=50"`
+	Email     string     `json:"email" gorm:"uniqueIndex;size:100;not null" validate:"required,email"`
+	Password  string     `json:"-" gorm:"size:255;not null" validate:"required,min=6"`
+	Nickname  string     `json:"nickname" gorm:"size:50"`
+	Avatar    string     `json:"avatar" gorm:"size:255"`
+	Phone     string     `json:"phone" gorm:"size:20"`
+	Role      string     `json:"role" gorm:"size:20;default:user" validate:"required,oneof=admin user"`
+	Status    int        `json:"status" gorm:"default:1;comment:1-正常 0-禁用"`
+	LastLogin *time.Time `json:"last_login"`
+	// EmailVerifiedAt 邮箱验证时间，管理员创建和外部身份提供方同步的用户视为已验证
+	EmailVerifiedAt *time.Time     `json:"email_verified_at" gorm:"comment:邮箱验证时间"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
 
@@ -52,32 +54,34 @@ type UserLoginRequest struct {
 
 // UserResponse 用户响应
 type UserResponse struct {
-	ID        uint       `json:"id"`
-	Username  string     `json:"username"`
-	Email     string     `json:"email"`
-	Nickname  string     `json:"nickname"`
-	Avatar    string     `json:"avatar"`
-	Phone     string     `json:"phone"`
-	Role      string     `json:"role"`
-	Status    int        `json:"status"`
-	LastLogin *time.Time `json:"last_login"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at"`
+	ID              uint       `json:"id"`
+	Username        string     `json:"username"`
+	Email           string     `json:"email"`
+	Nickname        string     `json:"nickname"`
+	Avatar          string     `json:"avatar"`
+	Phone           string     `json:"phone"`
+	Role            string     `json:"role"`
+	Status          int        `json:"status"`
+	LastLogin       *time.Time `json:"last_login"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
 }
 
 // ToResponse 转换为响应格式
 func (u *User) ToResponse() *UserResponse {
 	return &UserResponse{
-		ID:        u.ID,
-		Username:  u.Username,
-		Email:     u.Email,
-		Nickname:  u.Nickname,
-		Avatar:    u.Avatar,
-		Phone:     u.Phone,
-		Role:      u.Role,
-		Status:    u.Status,
-		LastLogin: u.LastLogin,
-		CreatedAt: u.CreatedAt,
-		UpdatedAt: u.UpdatedAt,
+		ID:              u.ID,
+		Username:        u.Username,
+		Email:           u.Email,
+		Nickname:        u.Nickname,
+		Avatar:          u.Avatar,
+		Phone:           u.Phone,
+		Role:            u.Role,
+		Status:          u.Status,
+		LastLogin:       u.LastLogin,
+		EmailVerifiedAt: u.EmailVerifiedAt,
+		CreatedAt:       u.CreatedAt,
+		UpdatedAt:       u.UpdatedAt,
 	}
 }
\ No newline at end of file
@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+)
+
+// UserSession 登录会话。JTI 写入访问令牌的 jti 声明，刷新后的访问令牌沿用同一 jti；
+// 会话的刷新令牌以 JTI 作为令牌族ID，吊销会话时一并吊销
+type UserSession struct {
+	ID         uint       `json:"id" gorm:"primarykey"`
+	UserID     uint       `json:"user_id" gorm:"not null;index;comment:所属用户ID"`
+	JTI        string     `json:"-" gorm:"uniqueIndex;size:32;not null;comment:访问令牌jti"`
+	Device     string     `json:"device" gorm:"size:100;comment:设备"`
+	ClientIP   string     `json:"client_ip" gorm:"size:64;comment:最近访问IP"`
+	UserAgent  string     `json:"user_agent" gorm:"size:255;comment:User-Agent"`
+	LastSeenAt time.Time  `json:"last_seen_at" gorm:"comment:最近访问时间"`
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"not null;comment:过期时间，刷新令牌时延长"`
+	RevokedAt  *time.Time `json:"revoked_at" gorm:"comment:吊销时间"`
+	Current    bool       `json:"current" gorm:"-"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
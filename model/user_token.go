@@ -0,0 +1,45 @@
+package model
+
+import (
+	"time"
+)
+
+// 一次性令牌的用途
+const (
+	UserTokenVerifyEmail   = "verify_email"
+	UserTokenResetPassword = "reset_password"
+)
+
+// UserToken 邮箱验证和重置密码的一次性令牌，只保存令牌的SHA-256哈希。
+// 令牌绑定签发时的邮箱，同一用途签发新令牌时旧令牌失效
+type UserToken struct {
+	ID        uint       `json:"id" gorm:"primarykey"`
+	UserID    uint       `json:"user_id" gorm:"not null;index;comment:所属用户ID"`
+	Purpose   string     `json:"purpose" gorm:"size:20;not null;comment:用途 verify_email/reset_password"`
+	TokenHash string     `json:"-" gorm:"uniqueIndex;size:64;not null;comment:令牌哈希"`
+	Email     string     `json:"email" gorm:"size:100;not null;comment:签发时的邮箱"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null;index;comment:过期时间"`
+	UsedAt    *time.Time `json:"used_at" gorm:"comment:使用或作废时间"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// ForgotPasswordRequest 忘记密码请求
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ResetPasswordRequest 重置密码请求
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=6"`
+}
+
+// VerifyEmailRequest 邮箱验证请求
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// ResendVerificationRequest 重新发送验证邮件请求
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
@@ -0,0 +1,19 @@
+package model
+
+// VaultRotateResult 主密钥轮换结果
+// Complete 为 true 表示全部加密数据都已使用当前主密钥加密，此时才可以移除旧主密钥
+type VaultRotateResult struct {
+	KeyID    string             `json:"key_id"`
+	Rotated  int                `json:"rotated"`
+	Failed   int                `json:"failed"`
+	Complete bool               `json:"complete"`
+	Items    []*VaultRotateItem `json:"items"`
+}
+
+// VaultRotateItem 一类加密数据的轮换结果
+type VaultRotateItem struct {
+	Name    string `json:"name"`
+	Rotated int    `json:"rotated"`
+	Failed  int    `json:"failed"`
+	Error   string `json:"error,omitempty"`
+}
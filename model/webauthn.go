@@ -0,0 +1,48 @@
+package model
+
+import (
+	"time"
+)
+
+// WebAuthn 认证会话的用途
+const (
+	WebAuthnPurposeRegister = "register" // 注册凭据
+	WebAuthnPurposeLogin    = "login"    // 无密码登录
+	WebAuthnPurposeMFA      = "mfa"      // 密码登录后的第二步验证
+)
+
+// WebAuthnCredential 用户注册的 WebAuthn 凭据（安全密钥或通行密钥）
+type WebAuthnCredential struct {
+	ID     uint   `json:"id" gorm:"primarykey"`
+	UserID uint   `json:"user_id" gorm:"not null;index;comment:用户ID"`
+	Name   string `json:"name" gorm:"size:64;comment:凭据名称"`
+	// CredentialID 为 base64url 编码的凭据ID，长度不固定，按 CredentialIDHash 查找
+	CredentialID     string     `json:"credential_id" gorm:"type:text;not null;comment:凭据ID"`
+	CredentialIDHash string     `json:"-" gorm:"uniqueIndex;size:64;not null;comment:凭据ID哈希"`
+	PublicKey        []byte     `json:"-" gorm:"not null;comment:COSE编码的公钥"`
+	Algorithm        int64      `json:"algorithm" gorm:"comment:COSE签名算法"`
+	SignCount        uint32     `json:"sign_count" gorm:"default:0;comment:签名计数"`
+	AAGUID           string     `json:"aaguid" gorm:"size:36;comment:认证器型号标识"`
+	Transports       []string   `json:"transports" gorm:"serializer:json;type:text;comment:认证器传输方式"`
+	BackupEligible   bool       `json:"backup_eligible" gorm:"default:false;comment:是否可同步备份"`
+	BackupState      bool       `json:"backup_state" gorm:"default:false;comment:是否已同步备份"`
+	LastUsedAt       *time.Time `json:"last_used_at" gorm:"comment:最近使用时间"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// WebAuthnChallenge WebAuthn 认证会话，按挑战哈希查找，使用一次后失效
+type WebAuthnChallenge struct {
+	ID            uint       `json:"id" gorm:"primarykey"`
+	UserID        uint       `json:"user_id" gorm:"index;comment:用户ID，未指定用户的无密码登录为0"`
+	ChallengeHash string     `json:"-" gorm:"uniqueIndex;size:64;not null;comment:挑战哈希"`
+	Purpose       string     `json:"purpose" gorm:"size:16;not null;comment:用途 register/login/mfa"`
+	ExpiresAt     time.Time  `json:"expires_at" gorm:"not null;comment:过期时间"`
+	UsedAt        *time.Time `json:"used_at" gorm:"comment:使用时间"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// WebAuthnLoginBeginRequest 无密码登录请求，username 为空时由认证器选择通行密钥
+type WebAuthnLoginBeginRequest struct {
+	Username string `json:"username" validate:"omitempty,max=50"`
+}
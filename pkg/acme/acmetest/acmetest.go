@@ -0,0 +1,343 @@
+// Package acmetest 提供测试用的ACME服务端，不依赖 Pebble 即可验证 DNS-01 签发流程
+package acmetest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	acmeapi "golang.org/x/crypto/acme"
+)
+
+// LookupFunc 返回 fqdn 上已发布的 TXT 记录
+type LookupFunc func(fqdn string) []string
+
+// Server 最小化的 RFC 8555 服务端，不校验 JWS 签名，
+// 通过 LookupFunc 读取验证记录完成 dns-01 验证
+type Server struct {
+	// CACert 签发证书使用的根证书
+	CACert *x509.Certificate
+
+	t      testing.TB
+	srv    *httptest.Server
+	lookup LookupFunc
+	caKey  *ecdsa.PrivateKey
+
+	mu       sync.Mutex
+	nextID   int
+	accounts map[string]string // JWK 指纹 -> 账号地址
+	orders   map[string]*fakeOrder
+	authzs   map[string]*fakeAuthz
+}
+
+type fakeOrder struct {
+	identifiers []string
+	authzs      []string
+	certPEM     []byte
+}
+
+type fakeAuthz struct {
+	domain     string
+	wildcard   bool
+	status     string
+	token      string
+	thumbprint string
+}
+
+type jws struct {
+	Protected string `json:"protected"`
+	Payload   string `json:"payload"`
+}
+
+// NewServer 启动测试用ACME服务端，测试结束时自动关闭
+func NewServer(t testing.TB, lookup LookupFunc) *Server {
+	t.Helper()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Fake ACME Root"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert, _ := x509.ParseCertificate(der)
+
+	ca := &Server{
+		CACert:   caCert,
+		t:        t,
+		lookup:   lookup,
+		caKey:    caKey,
+		accounts: make(map[string]string),
+		orders:   make(map[string]*fakeOrder),
+		authzs:   make(map[string]*fakeAuthz),
+	}
+	ca.srv = httptest.NewServer(http.HandlerFunc(ca.serve))
+	t.Cleanup(ca.srv.Close)
+	return ca
+}
+
+// DirectoryURL 返回ACME目录地址
+func (ca *Server) DirectoryURL() string {
+	return ca.url("/directory")
+}
+
+func (ca *Server) url(path string) string {
+	return ca.srv.URL + path
+}
+
+func (ca *Server) serve(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Replay-Nonce", fmt.Sprintf("nonce-%d", time.Now().UnixNano()))
+	if r.URL.Path == "/directory" {
+		writeJSON(w, http.StatusOK, map[string]string{
+			"newNonce":   ca.url("/nonce"),
+			"newAccount": ca.url("/account"),
+			"newOrder":   ca.url("/order"),
+		})
+		return
+	}
+	if r.URL.Path == "/nonce" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	var body jws
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	protected := decodeSegment(ca.t, body.Protected)
+	payload := decodeSegment(ca.t, body.Payload)
+
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch parts[0] {
+	case "account":
+		var header struct {
+			JWK json.RawMessage `json:"jwk"`
+		}
+		json.Unmarshal(protected, &header)
+		thumbprint := jwkThumbprint(ca.t, header.JWK)
+		if uri, ok := ca.accounts[thumbprint]; ok {
+			w.Header().Set("Location", uri)
+			writeJSON(w, http.StatusOK, map[string]string{"status": "valid"})
+			return
+		}
+		ca.nextID++
+		uri := ca.url(fmt.Sprintf("/accounts/%d", ca.nextID))
+		ca.accounts[thumbprint] = uri
+		w.Header().Set("Location", uri)
+		writeJSON(w, http.StatusCreated, map[string]string{"status": "valid"})
+
+	case "order":
+		if len(parts) == 1 {
+			ca.newOrder(w, payload, ca.accountThumbprint(protected))
+			return
+		}
+		order := ca.orders[parts[1]]
+		if len(parts) == 3 && parts[2] == "finalize" {
+			ca.finalize(w, parts[1], order, payload)
+			return
+		}
+		w.Header().Set("Location", ca.url("/order/"+parts[1]))
+		writeJSON(w, http.StatusOK, ca.orderJSON(parts[1], order))
+
+	case "authz":
+		writeJSON(w, http.StatusOK, ca.authzJSON(parts[1], ca.authzs[parts[1]]))
+
+	case "challenge":
+		authz := ca.authzs[parts[1]]
+		if authz.status == acmeapi.StatusPending {
+			sum := sha256.Sum256([]byte(authz.token + "." + authz.thumbprint))
+			expected := base64.RawURLEncoding.EncodeToString(sum[:])
+			authz.status = acmeapi.StatusInvalid
+			if slices.Contains(ca.lookup("_acme-challenge."+authz.domain), expected) {
+				authz.status = acmeapi.StatusValid
+			}
+		}
+		writeJSON(w, http.StatusOK, ca.authzJSON(parts[1], authz)["challenges"].([]map[string]string)[0])
+
+	case "cert":
+		w.Header().Set("Content-Type", "application/pem-certificate-chain")
+		w.Write(ca.orders[parts[1]].certPEM)
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (ca *Server) accountThumbprint(protected []byte) string {
+	var header struct {
+		KID string `json:"kid"`
+	}
+	json.Unmarshal(protected, &header)
+	for thumbprint, uri := range ca.accounts {
+		if uri == header.KID {
+			return thumbprint
+		}
+	}
+	ca.t.Errorf("request signed by unknown account %q", header.KID)
+	return ""
+}
+
+func (ca *Server) newOrder(w http.ResponseWriter, payload []byte, thumbprint string) {
+	var req struct {
+		Identifiers []struct {
+			Value string `json:"value"`
+		} `json:"identifiers"`
+	}
+	json.Unmarshal(payload, &req)
+
+	ca.nextID++
+	id := fmt.Sprint(ca.nextID)
+	order := &fakeOrder{}
+	for i, ident := range req.Identifiers {
+		authzID := fmt.Sprintf("%s-%d", id, i)
+		ca.authzs[authzID] = &fakeAuthz{
+			domain:     strings.TrimPrefix(ident.Value, "*."),
+			wildcard:   strings.HasPrefix(ident.Value, "*."),
+			status:     acmeapi.StatusPending,
+			token:      "token-" + authzID,
+			thumbprint: thumbprint,
+		}
+		order.identifiers = append(order.identifiers, ident.Value)
+		order.authzs = append(order.authzs, authzID)
+	}
+	ca.orders[id] = order
+
+	w.Header().Set("Location", ca.url("/order/"+id))
+	writeJSON(w, http.StatusCreated, ca.orderJSON(id, order))
+}
+
+func (ca *Server) finalize(w http.ResponseWriter, id string, order *fakeOrder, payload []byte) {
+	var req struct {
+		CSR string `json:"csr"`
+	}
+	json.Unmarshal(payload, &req)
+	der, _ := base64.RawURLEncoding.DecodeString(req.CSR)
+	csr, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !slices.Equal(csr.DNSNames, order.identifiers) {
+		ca.t.Errorf("csr names = %v, want %v", csr.DNSNames, order.identifiers)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: csr.DNSNames[0]},
+		DNSNames:     csr.DNSNames,
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(90 * 24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	leaf, err := x509.CreateCertificate(rand.Reader, template, ca.CACert, csr.PublicKey, ca.caKey)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	order.certPEM = append(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leaf}),
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.CACert.Raw})...)
+
+	w.Header().Set("Location", ca.url("/order/"+id))
+	writeJSON(w, http.StatusOK, ca.orderJSON(id, order))
+}
+
+func (ca *Server) orderJSON(id string, order *fakeOrder) map[string]interface{} {
+	status := acmeapi.StatusReady
+	var authzURLs []string
+	for _, authzID := range order.authzs {
+		authzURLs = append(authzURLs, ca.url("/authz/"+authzID))
+		switch ca.authzs[authzID].status {
+		case acmeapi.StatusInvalid:
+			status = acmeapi.StatusInvalid
+		case acmeapi.StatusPending:
+			if status != acmeapi.StatusInvalid {
+				status = acmeapi.StatusPending
+			}
+		}
+	}
+	result := map[string]interface{}{
+		"status":         status,
+		"authorizations": authzURLs,
+		"finalize":       ca.url("/order/" + id + "/finalize"),
+	}
+	if order.certPEM != nil {
+		result["status"] = acmeapi.StatusValid
+		result["certificate"] = ca.url("/cert/" + id)
+	}
+	return result
+}
+
+func (ca *Server) authzJSON(id string, authz *fakeAuthz) map[string]interface{} {
+	return map[string]interface{}{
+		"status":     authz.status,
+		"identifier": map[string]string{"type": "dns", "value": authz.domain},
+		"wildcard":   authz.wildcard,
+		"challenges": []map[string]string{{
+			"type":   "dns-01",
+			"url":    ca.url("/challenge/" + id),
+			"token":  authz.token,
+			"status": authz.status,
+		}},
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func decodeSegment(t testing.TB, s string) []byte {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		t.Errorf("decode jws segment: %v", err)
+	}
+	return data
+}
+
+func jwkThumbprint(t testing.TB, raw json.RawMessage) string {
+	var jwk struct {
+		X string `json:"x"`
+		Y string `json:"y"`
+	}
+	if err := json.Unmarshal(raw, &jwk); err != nil {
+		t.Fatalf("parse jwk: %v", err)
+	}
+	x, _ := base64.RawURLEncoding.DecodeString(jwk.X)
+	y, _ := base64.RawURLEncoding.DecodeString(jwk.Y)
+	pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+	thumbprint, err := acmeapi.JWKThumbprint(pub)
+	if err != nil {
+		t.Fatalf("jwk thumbprint: %v", err)
+	}
+	return thumbprint
+}
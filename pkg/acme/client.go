@@ -0,0 +1,262 @@
+package acme
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	acmeapi "golang.org/x/crypto/acme"
+)
+
+// LetsEncryptURL Let's Encrypt 生产环境目录地址
+const LetsEncryptURL = acmeapi.LetsEncryptURL
+
+// ErrNoDNSChallenge ACME 服务端没有为授权提供 dns-01 验证
+var ErrNoDNSChallenge = errors.New("acme: no dns-01 challenge offered")
+
+// Config ACME 客户端配置
+type Config struct {
+	// DirectoryURL ACME 目录地址，为空时使用 Let's Encrypt
+	DirectoryURL string
+	// Email 账号联系邮箱
+	Email string
+	// AccountKey 账号私钥，为空时自动生成 ECDSA P-256 私钥
+	AccountKey crypto.Signer
+	// AccountURL 已注册的账号地址，为空时在 Register 中获取
+	AccountURL string
+	HTTPClient *http.Client
+	Solver     Solver
+}
+
+// Client ACME 客户端，按 RFC 8555 注册账号、下单并通过 DNS-01 完成验证
+type Client struct {
+	client *acmeapi.Client
+	email  string
+	solver Solver
+}
+
+// Certificate 签发的证书
+type Certificate struct {
+	Domains []string
+	// CertificatePEM 叶子证书及中间证书
+	CertificatePEM []byte
+	// PrivateKeyPEM 证书私钥（PKCS#8）
+	PrivateKeyPEM []byte
+	Leaf          *x509.Certificate
+}
+
+// New 创建 ACME 客户端
+func New(cfg Config) (*Client, error) {
+	if cfg.Solver == nil {
+		return nil, errors.New("acme: solver is required")
+	}
+
+	key := cfg.AccountKey
+	if key == nil {
+		generated, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("acme: generate account key: %w", err)
+		}
+		key = generated
+	}
+
+	directory := cfg.DirectoryURL
+	if directory == "" {
+		directory = LetsEncryptURL
+	}
+
+	client := &acmeapi.Client{
+		Key:          key,
+		DirectoryURL: directory,
+		HTTPClient:   cfg.HTTPClient,
+		UserAgent:    "domain-admin",
+	}
+	if cfg.AccountURL != "" {
+		client.KID = acmeapi.KeyID(cfg.AccountURL)
+	}
+
+	return &Client{client: client, email: cfg.Email, solver: cfg.Solver}, nil
+}
+
+// AccountKey 返回账号私钥
+func (c *Client) AccountKey() crypto.Signer {
+	return c.client.Key
+}
+
+// Register 注册账号并同意服务条款，账号已存在时返回已有账号，返回账号地址
+func (c *Client) Register(ctx context.Context) (string, error) {
+	account := &acmeapi.Account{}
+	if c.email != "" {
+		account.Contact = []string{"mailto:" + c.email}
+	}
+
+	registered, err := c.client.Register(ctx, account, acmeapi.AcceptTOS)
+	if errors.Is(err, acmeapi.ErrAccountAlreadyExists) {
+		registered, err = c.client.GetReg(ctx, "")
+	}
+	if err != nil {
+		return "", fmt.Errorf("acme: register account: %w", err)
+	}
+	return registered.URI, nil
+}
+
+// Obtain 为域名下单、完成 DNS-01 验证并签发证书，每次签发都会生成新的证书私钥
+func (c *Client) Obtain(ctx context.Context, domains []string) (*Certificate, error) {
+	if len(domains) == 0 {
+		return nil, errors.New("acme: no domains")
+	}
+
+	order, err := c.client.AuthorizeOrder(ctx, acmeapi.DomainIDs(domains...))
+	if err != nil {
+		return nil, fmt.Errorf("acme: create order: %w", err)
+	}
+
+	for _, url := range order.AuthzURLs {
+		if err := c.authorize(ctx, url); err != nil {
+			return nil, err
+		}
+	}
+
+	order, err = c.client.WaitOrder(ctx, order.URI)
+	if err != nil {
+		return nil, fmt.Errorf("acme: wait order: %w", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("acme: generate certificate key: %w", err)
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: domains[0]},
+		DNSNames: domains,
+	}, key)
+	if err != nil {
+		return nil, fmt.Errorf("acme: create csr: %w", err)
+	}
+
+	chain, _, err := c.client.CreateOrderCert(ctx, order.FinalizeURL, csr, true)
+	if err != nil {
+		return nil, fmt.Errorf("acme: finalize order: %w", err)
+	}
+	if len(chain) == 0 {
+		return nil, errors.New("acme: empty certificate chain")
+	}
+
+	leaf, err := x509.ParseCertificate(chain[0])
+	if err != nil {
+		return nil, fmt.Errorf("acme: parse certificate: %w", err)
+	}
+
+	var certPEM []byte
+	for _, der := range chain {
+		certPEM = append(certPEM, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	}
+	keyPEM, err := MarshalPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Certificate{
+		Domains:        domains,
+		CertificatePEM: certPEM,
+		PrivateKeyPEM:  keyPEM,
+		Leaf:           leaf,
+	}, nil
+}
+
+// authorize 完成单个授权的 DNS-01 验证，已有效的授权直接跳过
+func (c *Client) authorize(ctx context.Context, url string) error {
+	authz, err := c.client.GetAuthorization(ctx, url)
+	if err != nil {
+		return fmt.Errorf("acme: get authorization: %w", err)
+	}
+	if authz.Status == acmeapi.StatusValid {
+		return nil
+	}
+
+	var challenge *acmeapi.Challenge
+	for _, ch := range authz.Challenges {
+		if ch.Type == "dns-01" {
+			challenge = ch
+			break
+		}
+	}
+	if challenge == nil {
+		return fmt.Errorf("%w for %s", ErrNoDNSChallenge, authz.Identifier.Value)
+	}
+
+	value, err := c.client.DNS01ChallengeRecord(challenge.Token)
+	if err != nil {
+		return fmt.Errorf("acme: compute dns-01 record: %w", err)
+	}
+
+	fqdn := ChallengeRecordName(authz.Identifier.Value)
+	if err := c.solver.Present(ctx, fqdn, value); err != nil {
+		return fmt.Errorf("acme: present %s: %w", fqdn, err)
+	}
+	defer func() {
+		// 验证完成后清理记录，使用独立的上下文避免请求取消后记录残留
+		cleanupCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		c.solver.CleanUp(cleanupCtx, fqdn, value)
+	}()
+
+	if _, err := c.client.Accept(ctx, challenge); err != nil {
+		return fmt.Errorf("acme: accept challenge for %s: %w", authz.Identifier.Value, err)
+	}
+	if _, err := c.client.WaitAuthorization(ctx, authz.URI); err != nil {
+		return fmt.Errorf("acme: authorization for %s: %w", authz.Identifier.Value, err)
+	}
+	return nil
+}
+
+// MarshalPrivateKey 将私钥编码为 PKCS#8 PEM
+func MarshalPrivateKey(key crypto.Signer) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("acme: marshal private key: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// ParsePrivateKey 解析 PKCS#8 PEM 私钥
+func ParsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("acme: invalid private key PEM")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("acme: parse private key: %w", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("acme: private key is not a signer")
+	}
+	return signer, nil
+}
+
+// NormalizeDomains 规范化域名列表（小写、去掉末尾的点并去重），保持原有顺序
+func NormalizeDomains(domains []string) []string {
+	seen := make(map[string]bool, len(domains))
+	result := make([]string, 0, len(domains))
+	for _, domain := range domains {
+		domain = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+		if domain == "" || seen[domain] {
+			continue
+		}
+		seen[domain] = true
+		result = append(result, domain)
+	}
+	return result
+}
@@ -0,0 +1,136 @@
+package acme
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"domain-admin/pkg/acme/acmetest"
+	"net/http"
+	"os"
+	"slices"
+	"testing"
+	"time"
+)
+
+// brokenSolver 不发布任何记录，用于验证失败的情况
+type brokenSolver struct{}
+
+func (brokenSolver) Present(ctx context.Context, fqdn, value string) error { return nil }
+func (brokenSolver) CleanUp(ctx context.Context, fqdn, value string) error { return nil }
+
+func TestObtainWithMemorySolver(t *testing.T) {
+	solver := NewMemorySolver()
+	ca := acmetest.NewServer(t, solver.Records)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	client, err := New(Config{DirectoryURL: ca.DirectoryURL(), Email: "admin@example.com", Solver: solver})
+	if err != nil {
+		t.Fatal(err)
+	}
+	accountURL, err := client.Register(ctx)
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	// 使用同一私钥再次注册时返回已有账号
+	again, _ := New(Config{DirectoryURL: ca.DirectoryURL(), AccountKey: client.AccountKey(), Solver: solver})
+	if url, err := again.Register(ctx); err != nil || url != accountURL {
+		t.Fatalf("second Register = %q, %v; want %q", url, err, accountURL)
+	}
+
+	domains := []string{"example.com", "*.example.com"}
+	cert, err := client.Obtain(ctx, domains)
+	if err != nil {
+		t.Fatalf("Obtain: %v", err)
+	}
+
+	if !slices.Equal(cert.Leaf.DNSNames, domains) {
+		t.Errorf("DNSNames = %v, want %v", cert.Leaf.DNSNames, domains)
+	}
+	if records := solver.Records("_acme-challenge.example.com"); len(records) != 0 {
+		t.Errorf("challenge records not cleaned up: %v", records)
+	}
+
+	pair, err := tls.X509KeyPair(cert.CertificatePEM, cert.PrivateKeyPEM)
+	if err != nil {
+		t.Fatalf("certificate and key do not match: %v", err)
+	}
+	if len(pair.Certificate) != 2 {
+		t.Errorf("chain length = %d, want 2", len(pair.Certificate))
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.CACert)
+	if _, err := cert.Leaf.Verify(x509.VerifyOptions{Roots: roots, DNSName: "www.example.com"}); err != nil {
+		t.Errorf("verify issued certificate: %v", err)
+	}
+}
+
+func TestObtainFailsWhenChallengeNotPresented(t *testing.T) {
+	ca := acmetest.NewServer(t, NewMemorySolver().Records)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	client, err := New(Config{DirectoryURL: ca.DirectoryURL(), Solver: brokenSolver{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Register(ctx); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if _, err := client.Obtain(ctx, []string{"example.org"}); err == nil {
+		t.Fatal("Obtain succeeded without a published challenge record")
+	}
+}
+
+func TestMemorySolverKeepsMultipleValues(t *testing.T) {
+	solver := NewMemorySolver()
+	ctx := context.Background()
+	fqdn := ChallengeRecordName("*.example.com")
+	if fqdn != "_acme-challenge.example.com" {
+		t.Fatalf("ChallengeRecordName = %q", fqdn)
+	}
+
+	solver.Present(ctx, fqdn, "a")
+	solver.Present(ctx, fqdn, "b")
+	solver.CleanUp(ctx, fqdn, "a")
+	if got := solver.Records(fqdn); !slices.Equal(got, []string{"b"}) {
+		t.Errorf("Records = %v, want [b]", got)
+	}
+}
+
+// TestObtainPebble 针对本地 Pebble 的端到端测试，未设置 ACME_PEBBLE_DIRECTORY 时跳过。
+// 设置 ACME_PEBBLE_CHALLTESTSRV 时通过 pebble-challtestsrv 发布验证记录，
+// 否则使用内存求解器，此时 Pebble 需以 PEBBLE_VA_ALWAYS_VALID=1 启动
+func TestObtainPebble(t *testing.T) {
+	directory := os.Getenv("ACME_PEBBLE_DIRECTORY")
+	if directory == "" {
+		t.Skip("ACME_PEBBLE_DIRECTORY not set")
+	}
+
+	var solver Solver = NewMemorySolver()
+	if addr := os.Getenv("ACME_PEBBLE_CHALLTESTSRV"); addr != "" {
+		solver = &ChallTestSrvSolver{BaseURL: addr}
+	}
+
+	// Pebble 使用自签名的 HTTPS 证书
+	httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	client, err := New(Config{DirectoryURL: directory, Email: "admin@example.com", HTTPClient: httpClient, Solver: solver})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	if _, err := client.Register(ctx); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	cert, err := client.Obtain(ctx, []string{"pebble.example.com"})
+	if err != nil {
+		t.Fatalf("Obtain: %v", err)
+	}
+	if !slices.Contains(cert.Leaf.DNSNames, "pebble.example.com") {
+		t.Errorf("DNSNames = %v", cert.Leaf.DNSNames)
+	}
+}
@@ -0,0 +1,107 @@
+package acme
+
+import (
+	"context"
+	"domain-admin/pkg/provider"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// providerRecordTTL 验证记录的 TTL
+const providerRecordTTL = 60
+
+// ProviderSolver 通过云服务商账号创建 TXT 记录完成 DNS-01 验证
+type ProviderSolver struct {
+	DNS provider.DNSProvider
+	// PropagationDelay 创建记录后等待生效的时间
+	PropagationDelay time.Duration
+
+	mu      sync.Mutex
+	created map[string]providerRecord
+}
+
+type providerRecord struct {
+	zone string
+	id   string
+}
+
+// NewProviderSolver 创建使用云服务商账号的求解器
+func NewProviderSolver(dns provider.DNSProvider, propagationDelay time.Duration) *ProviderSolver {
+	return &ProviderSolver{
+		DNS:              dns,
+		PropagationDelay: propagationDelay,
+		created:          make(map[string]providerRecord),
+	}
+}
+
+// Present 在 fqdn 所属区域中创建 TXT 记录
+func (s *ProviderSolver) Present(ctx context.Context, fqdn, value string) error {
+	zone, name, err := s.findZone(ctx, fqdn)
+	if err != nil {
+		return err
+	}
+
+	record, err := s.DNS.CreateRecord(ctx, zone, provider.Record{
+		Name:  name,
+		Type:  "TXT",
+		Value: value,
+		TTL:   providerRecordTTL,
+	})
+	if err != nil {
+		return fmt.Errorf("create TXT record %s: %w", fqdn, err)
+	}
+
+	s.mu.Lock()
+	s.created[fqdn+" "+value] = providerRecord{zone: zone, id: record.ID}
+	s.mu.Unlock()
+
+	if s.PropagationDelay > 0 {
+		timer := time.NewTimer(s.PropagationDelay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return nil
+}
+
+// CleanUp 删除 Present 创建的 TXT 记录
+func (s *ProviderSolver) CleanUp(ctx context.Context, fqdn, value string) error {
+	key := fqdn + " " + value
+	s.mu.Lock()
+	record, ok := s.created[key]
+	delete(s.created, key)
+	s.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	return s.DNS.DeleteRecord(ctx, record.zone, record.id)
+}
+
+// findZone 查找 fqdn 所属的最长匹配区域，返回区域名称和相对区域的主机记录
+func (s *ProviderSolver) findZone(ctx context.Context, fqdn string) (string, string, error) {
+	zones, err := s.DNS.ListZones(ctx)
+	if err != nil {
+		return "", "", fmt.Errorf("list zones: %w", err)
+	}
+
+	fqdn = provider.NormalizeZone(fqdn)
+	best := ""
+	for _, zone := range zones {
+		name := provider.NormalizeZone(zone.Name)
+		if (fqdn == name || strings.HasSuffix(fqdn, "."+name)) && len(name) > len(best) {
+			best = name
+		}
+	}
+	if best == "" {
+		return "", "", fmt.Errorf("no zone found for %s", fqdn)
+	}
+	if fqdn == best {
+		return best, "@", nil
+	}
+	return best, strings.TrimSuffix(fqdn, "."+best), nil
+}
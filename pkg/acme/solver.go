@@ -0,0 +1,116 @@
+package acme
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Solver DNS-01 验证求解器
+// fqdn 为完整的验证记录名（如 _acme-challenge.example.com），不带末尾的点；
+// value 为需要发布的 TXT 记录值。同一 fqdn 可能同时存在多个值（如同时申请 example.com 和 *.example.com）
+type Solver interface {
+	// Present 发布验证记录，返回后记录应当已可被 ACME 服务端查询
+	Present(ctx context.Context, fqdn, value string) error
+	// CleanUp 删除 Present 发布的验证记录
+	CleanUp(ctx context.Context, fqdn, value string) error
+}
+
+// ChallengeRecordName 返回域名对应的 DNS-01 验证记录名，通配符域名使用其父域名
+func ChallengeRecordName(domain string) string {
+	domain = strings.TrimSuffix(strings.TrimPrefix(domain, "*."), ".")
+	return "_acme-challenge." + domain
+}
+
+// MemorySolver 将验证记录保存在内存中，用于测试
+type MemorySolver struct {
+	mu      sync.Mutex
+	records map[string][]string
+}
+
+// NewMemorySolver 创建内存求解器
+func NewMemorySolver() *MemorySolver {
+	return &MemorySolver{records: make(map[string][]string)}
+}
+
+// Present 保存验证记录
+func (s *MemorySolver) Present(ctx context.Context, fqdn, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[fqdn] = append(s.records[fqdn], value)
+	return nil
+}
+
+// CleanUp 删除验证记录
+func (s *MemorySolver) CleanUp(ctx context.Context, fqdn, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	values := s.records[fqdn]
+	for i, v := range values {
+		if v == value {
+			values = append(values[:i], values[i+1:]...)
+			break
+		}
+	}
+	if len(values) == 0 {
+		delete(s.records, fqdn)
+	} else {
+		s.records[fqdn] = values
+	}
+	return nil
+}
+
+// Records 返回 fqdn 当前发布的验证记录
+func (s *MemorySolver) Records(fqdn string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.records[fqdn]...)
+}
+
+// ChallTestSrvSolver 通过 pebble-challtestsrv 的管理接口发布验证记录，
+// 与 Pebble 配合用于本地端到端测试
+type ChallTestSrvSolver struct {
+	// BaseURL 管理接口地址，如 http://localhost:8055
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+// Present 发布验证记录
+func (s *ChallTestSrvSolver) Present(ctx context.Context, fqdn, value string) error {
+	return s.post(ctx, "/set-txt", map[string]string{"host": fqdn + ".", "value": value})
+}
+
+// CleanUp 删除验证记录
+func (s *ChallTestSrvSolver) CleanUp(ctx context.Context, fqdn, value string) error {
+	return s.post(ctx, "/clear-txt", map[string]string{"host": fqdn + "."})
+}
+
+func (s *ChallTestSrvSolver) post(ctx context.Context, path string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(s.BaseURL, "/")+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := s.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("challtestsrv %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("challtestsrv %s: %s", path, resp.Status)
+	}
+	return nil
+}
@@ -17,13 +17,14 @@ const (
 	UserCachePrefix     = "user:"
 	UserListCachePrefix = "user_list:"
 	SessionCachePrefix  = "session:"
+	WhoisCachePrefix    = "whois:"
 )
 
 // 缓存过期时间
 const (
 	UserCacheExpire     = 30 * time.Minute  // 用户信息缓存30分钟
 	UserListCacheExpire = 5 * time.Minute   // 用户列表缓存5分钟
-	SessionCacheExpire  = 24 * time.Hour    // 会话缓存24小时
+	SessionCacheExpire  = 5 * time.Minute   // 会话状态缓存5分钟，吊销会话时删除
 )
 
 func InitCache(cfg config.RedisConfig) {
@@ -136,33 +137,51 @@ func DelUserListCache(ctx context.Context, pattern string) error {
 	return nil
 }
 
-// SetSessionCache 设置会话缓存
-func SetSessionCache(ctx context.Context, token string, userInfo interface{}) error {
+// SetSessionCache 设置会话缓存，以访问令牌的jti为键
+func SetSessionCache(ctx context.Context, sessionID string, userInfo interface{}) error {
 	if redisClient == nil {
 		return nil // Redis未初始化时静默返回
 	}
-	key := fmt.Sprintf("%s%s", SessionCachePrefix, token)
+	key := fmt.Sprintf("%s%s", SessionCachePrefix, sessionID)
 	return Set(ctx, key, userInfo, SessionCacheExpire)
 }
 
 // GetSessionCache 获取会话缓存
-func GetSessionCache(ctx context.Context, token string, dest interface{}) error {
+func GetSessionCache(ctx context.Context, sessionID string, dest interface{}) error {
 	if redisClient == nil {
 		return redis.Nil // Redis未初始化时返回未找到
 	}
-	key := fmt.Sprintf("%s%s", SessionCachePrefix, token)
+	key := fmt.Sprintf("%s%s", SessionCachePrefix, sessionID)
 	return Get(ctx, key, dest)
 }
 
 // DelSessionCache 删除会话缓存
-func DelSessionCache(ctx context.Context, token string) error {
+func DelSessionCache(ctx context.Context, sessionID string) error {
 	if redisClient == nil {
 		return nil // Redis未初始化时静默返回
 	}
-	key := fmt.Sprintf("%s%s", SessionCachePrefix, token)
+	key := fmt.Sprintf("%s%s", SessionCachePrefix, sessionID)
 	return Del(ctx, key)
 }
 
+// SetWhoisCache 设置域名注册信息缓存
+func SetWhoisCache(ctx context.Context, domain string, result interface{}, expiration time.Duration) error {
+	if redisClient == nil {
+		return nil // Redis未初始化时静默返回
+	}
+	key := fmt.Sprintf("%s%s", WhoisCachePrefix, domain)
+	return Set(ctx, key, result, expiration)
+}
+
+// GetWhoisCache 获取域名注册信息缓存
+func GetWhoisCache(ctx context.Context, domain string, dest interface{}) error {
+	if redisClient == nil {
+		return redis.Nil // Redis未初始化时返回未找到
+	}
+	key := fmt.Sprintf("%s%s", WhoisCachePrefix, domain)
+	return Get(ctx, key, dest)
+}
+
 func parseDuration(s string) time.Duration {
 	d, err := time.ParseDuration(s)
 	if err != nil {
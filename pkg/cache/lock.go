@@ -0,0 +1,116 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// LockCachePrefix 分布式锁键前缀
+const LockCachePrefix = "lock:"
+
+// releaseLockScript 仅当锁仍由当前持有者持有时才删除
+var releaseLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// renewLockScript 仅当锁仍由当前持有者持有时才延长有效期
+var renewLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// localLock 进程内的锁
+type localLock struct {
+	token     string
+	expiresAt time.Time
+}
+
+var (
+	localLocks   = make(map[string]*localLock)
+	localLocksMu sync.Mutex
+)
+
+// DistributedLock 锁是否在多个实例间共享
+// Redis未初始化时锁只在当前进程内有效，多实例部署的各个实例互不感知
+func DistributedLock() bool {
+	return redisClient != nil
+}
+
+// AcquireLock 获取锁，token 用于标识持有者
+// Redis未初始化时使用进程内锁
+func AcquireLock(ctx context.Context, name, token string, expiration time.Duration) (bool, error) {
+	key := fmt.Sprintf("%s%s", LockCachePrefix, name)
+	if redisClient == nil {
+		return acquireLocal(key, token, expiration), nil
+	}
+	return redisClient.SetNX(ctx, key, token, expiration).Result()
+}
+
+// RenewLock 延长锁的有效期，锁已过期或被其他持有者获取时返回 false
+func RenewLock(ctx context.Context, name, token string, expiration time.Duration) (bool, error) {
+	key := fmt.Sprintf("%s%s", LockCachePrefix, name)
+	if redisClient == nil {
+		return renewLocal(key, token, expiration), nil
+	}
+	renewed, err := renewLockScript.Run(ctx, redisClient, []string{key}, token, expiration.Milliseconds()).Int()
+	return renewed == 1, err
+}
+
+// ReleaseLock 释放锁，锁已过期或被其他持有者获取时不做处理
+func ReleaseLock(ctx context.Context, name, token string) error {
+	key := fmt.Sprintf("%s%s", LockCachePrefix, name)
+	if redisClient == nil {
+		releaseLocal(key, token)
+		return nil
+	}
+	return releaseLockScript.Run(ctx, redisClient, []string{key}, token).Err()
+}
+
+func acquireLocal(key, token string, expiration time.Duration) bool {
+	localLocksMu.Lock()
+	defer localLocksMu.Unlock()
+
+	now := time.Now()
+	if lock, ok := localLocks[key]; ok && now.Before(lock.expiresAt) {
+		return false
+	}
+	// 顺带清理已过期的锁
+	for k, lock := range localLocks {
+		if !now.Before(lock.expiresAt) {
+			delete(localLocks, k)
+		}
+	}
+	localLocks[key] = &localLock{token: token, expiresAt: now.Add(expiration)}
+	return true
+}
+
+func renewLocal(key, token string, expiration time.Duration) bool {
+	localLocksMu.Lock()
+	defer localLocksMu.Unlock()
+
+	now := time.Now()
+	lock, ok := localLocks[key]
+	if !ok || lock.token != token || !now.Before(lock.expiresAt) {
+		return false
+	}
+	lock.expiresAt = now.Add(expiration)
+	return true
+}
+
+func releaseLocal(key, token string) {
+	localLocksMu.Lock()
+	defer localLocksMu.Unlock()
+
+	if lock, ok := localLocks[key]; ok && lock.token == token {
+		delete(localLocks, key)
+	}
+}
@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RateLimitCachePrefix 限流计数键前缀
+const RateLimitCachePrefix = "ratelimit:"
+
+// localCounter 进程内的限流计数
+type localCounter struct {
+	count   int64
+	resetAt time.Time
+}
+
+var (
+	localCounters   = make(map[string]*localCounter)
+	localCountersMu sync.Mutex
+)
+
+// IncrRateLimit 在固定时间窗口内累加计数，返回当前计数和窗口剩余时间
+// Redis未初始化时使用进程内计数
+func IncrRateLimit(ctx context.Context, name string, window time.Duration) (int64, time.Duration, error) {
+	key := fmt.Sprintf("%s%s", RateLimitCachePrefix, name)
+	if redisClient == nil {
+		count, ttl := incrLocal(key, window)
+		return count, ttl, nil
+	}
+
+	count, err := redisClient.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, 0, err
+	}
+	if count == 1 {
+		if err := redisClient.PExpire(ctx, key, window).Err(); err != nil {
+			return 0, 0, err
+		}
+		return count, window, nil
+	}
+
+	ttl, err := redisClient.PTTL(ctx, key).Result()
+	if err != nil {
+		return 0, 0, err
+	}
+	// 设置过期时间失败的残留键，补设过期时间
+	if ttl < 0 {
+		redisClient.PExpire(ctx, key, window)
+		ttl = window
+	}
+	return count, ttl, nil
+}
+
+func incrLocal(key string, window time.Duration) (int64, time.Duration) {
+	localCountersMu.Lock()
+	defer localCountersMu.Unlock()
+
+	now := time.Now()
+	counter, ok := localCounters[key]
+	if !ok || now.After(counter.resetAt) {
+		// 顺带清理已过期的计数
+		for k, c := range localCounters {
+			if now.After(c.resetAt) {
+				delete(localCounters, k)
+			}
+		}
+		counter = &localCounter{resetAt: now.Add(window)}
+		localCounters[key] = counter
+	}
+	counter.count++
+	return counter.count, counter.resetAt.Sub(now)
+}
@@ -3,6 +3,8 @@ package config
 import (
 	"fmt"
 	"log"
+	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -15,6 +17,19 @@ type Config struct {
 	JWT           JWTConfig             `mapstructure:"jwt"`
 	CloudProvider []CloudProviderConfig `mapstructure:"cloudprovider"`
 	OTLP          OTLPConfig            `mapstructure:"otel"`
+	Scheduler     SchedulerConfig       `mapstructure:"scheduler"`
+	Whois         WhoisConfig           `mapstructure:"whois"`
+	Certificate   CertificateConfig     `mapstructure:"certificate"`
+	ACME          ACMEConfig            `mapstructure:"acme"`
+	DNSCheck      DNSCheckConfig        `mapstructure:"dns_check"`
+	Vault         VaultConfig           `mapstructure:"vault"`
+	Approval      ApprovalConfig        `mapstructure:"approval"`
+	WebAuthn      WebAuthnConfig        `mapstructure:"webauthn"`
+	OIDC          OIDCConfig            `mapstructure:"oidc"`
+	LDAP          LDAPConfig            `mapstructure:"ldap"`
+	OAuth         OAuthConfig           `mapstructure:"oauth"`
+	Mail          MailConfig            `mapstructure:"mail"`
+	Account       AccountConfig         `mapstructure:"account"`
 }
 
 type ServerConfig struct {
@@ -65,14 +80,28 @@ type LogConfig struct {
 }
 
 type JWTConfig struct {
-	Secret     string `mapstructure:"secret"`
-	Expiration string `mapstructure:"expiration"`
+	Secret            string `mapstructure:"secret"`             // HS256 签名密钥，未配置非对称签名密钥时必须设置
+	Expiration        string `mapstructure:"expiration"`         // 访问令牌有效期，默认15m
+	RefreshExpiration string `mapstructure:"refresh_expiration"` // 刷新令牌有效期，默认168h
+	KeyReload         string `mapstructure:"key_reload"`         // 从数据库重新加载签名密钥的间隔，默认1m
+	// SigningKey 签名密钥的kid，可以是 Keys 或数据库中的密钥；为空时使用数据库中最新的密钥，都没有时使用 HS256 和 Secret
+	SigningKey string         `mapstructure:"signing_key"`
+	Keys       []JWTKeyConfig `mapstructure:"keys"` // 从文件加载的签名和验证密钥
+}
+
+type JWTKeyConfig struct {
+	Kid            string `mapstructure:"kid"`
+	Algorithm      string `mapstructure:"algorithm"`        // RS256、ES256 或 EdDSA，为空时按密钥类型推断
+	PrivateKeyFile string `mapstructure:"private_key_file"` // PEM 私钥文件
+	PublicKeyFile  string `mapstructure:"public_key_file"`  // PEM 公钥文件，只用于验证，未配置私钥时使用
 }
 
 type CloudProviderConfig struct {
-	Type         string `mapstructure:"type"`
-	AccessKey    string `mapstructure:"access_key"`
-	AccessSecret string `mapstructure:"access_secret"`
+	Name         string            `mapstructure:"name"` // 账号名称，为空时使用 Type
+	Type         string            `mapstructure:"type"` // 服务商类型，如 local
+	AccessKey    string            `mapstructure:"access_key"`
+	AccessSecret string            `mapstructure:"access_secret"`
+	Options      map[string]string `mapstructure:"options"` // 服务商特定参数
 }
 
 type OTLPConfig struct {
@@ -82,6 +111,141 @@ type OTLPConfig struct {
 	ServiceInstanceID string `mapstructure:"service_instance_id"`
 }
 
+type SchedulerConfig struct {
+	Disabled         bool              `mapstructure:"disabled"`           // 是否禁用定时任务
+	Jobs             map[string]string `mapstructure:"jobs"`               // 按任务名称覆盖cron表达式，"-" 表示不注册该任务
+	RunRetentionDays int               `mapstructure:"run_retention_days"` // 任务执行记录保留天数，默认30天
+}
+
+type WhoisConfig struct {
+	CacheTTL     string            `mapstructure:"cache_ttl"`     // 查询结果缓存时间，默认24h
+	RateLimit    int               `mapstructure:"rate_limit"`    // 每个用户每分钟的查询次数，默认10
+	Timeout      string            `mapstructure:"timeout"`       // 单次查询超时时间，默认10s
+	RDAPServers  map[string]string `mapstructure:"rdap_servers"`  // 按顶级域名指定RDAP服务地址
+	WhoisServers map[string]string `mapstructure:"whois_servers"` // 按顶级域名指定WHOIS服务地址(host:port)
+}
+
+type CertificateConfig struct {
+	ExpiryDays int    `mapstructure:"expiry_days"` // 证书到期前多少天标记为即将到期，默认30天
+	Timeout    string `mapstructure:"timeout"`     // 单次探测超时时间，默认10s
+}
+
+type ACMEConfig struct {
+	DirectoryURL     string `mapstructure:"directory_url"`     // ACME目录地址，默认Let's Encrypt
+	Email            string `mapstructure:"email"`             // ACME账号联系邮箱
+	CACertFile       string `mapstructure:"ca_cert_file"`      // ACME服务端的CA证书(如Pebble)，为空时使用系统根证书
+	ChallTestSrvURL  string `mapstructure:"challtestsrv_url"`  // 设置后通过pebble-challtestsrv发布验证记录，仅用于测试
+	PropagationDelay string `mapstructure:"propagation_delay"` // 创建验证记录后等待生效的时间，默认30s
+	RenewBeforeDays  int    `mapstructure:"renew_before_days"` // 到期前多少天自动续期，默认30天
+	Timeout          string `mapstructure:"timeout"`           // 单次签发超时时间，默认10m
+}
+
+type DNSCheckConfig struct {
+	Resolvers []string `mapstructure:"resolvers"`  // 对比查询的递归解析器(host:port)，默认1.1.1.1、8.8.8.8、9.9.9.9
+	RateLimit int      `mapstructure:"rate_limit"` // 每个用户每分钟的查询次数，默认30
+	Timeout   string   `mapstructure:"timeout"`    // 单次查询超时时间，默认5s
+}
+
+type VaultConfig struct {
+	KeyEnv  string `mapstructure:"key_env"`  // 读取主密钥的环境变量，默认DOMAIN_ADMIN_VAULT_KEY
+	KeyFile string `mapstructure:"key_file"` // 主密钥文件，环境变量未设置时使用
+}
+
+type ApprovalConfig struct {
+	Routes []ApprovalRouteConfig `mapstructure:"routes"` // 需要审批的路由，为空时不启用审批
+	Expire string                `mapstructure:"expire"` // 变更请求的有效期，默认72h
+}
+
+type ApprovalRouteConfig struct {
+	Method string `mapstructure:"method"` // 请求方法，为空或*时匹配所有方法
+	Path   string `mapstructure:"path"`   // gin 完整路由，如 /api/roles/:id/permissions
+}
+
+type WebAuthnConfig struct {
+	RPID    string   `mapstructure:"rp_id"`   // 依赖方ID，通常为前端页面的域名，为空时不启用WebAuthn
+	RPName  string   `mapstructure:"rp_name"` // 认证器中显示的名称，默认Domain Admin
+	Origins []string `mapstructure:"origins"` // 允许的页面来源，如 https://admin.example.com，为空时为 https://<rp_id>
+}
+
+type OIDCConfig struct {
+	Providers []OIDCProviderConfig `mapstructure:"providers"` // 单点登录的身份提供方
+}
+
+type OIDCProviderConfig struct {
+	Name          string   `mapstructure:"name"`           // 标识，用于登录地址 /api/auth/oidc/<name>/login
+	DisplayName   string   `mapstructure:"display_name"`   // 登录页显示的名称，默认同 name
+	Issuer        string   `mapstructure:"issuer"`         // 签发方地址，从 <issuer>/.well-known/openid-configuration 读取发现文档
+	ClientID      string   `mapstructure:"client_id"`      // 客户端ID
+	ClientSecret  string   `mapstructure:"client_secret"`  // 客户端密钥，为空时作为公开客户端只使用PKCE
+	RedirectURL   string   `mapstructure:"redirect_url"`   // 回调地址，如 https://admin.example.com/api/auth/oidc/corp/callback
+	Scopes        []string `mapstructure:"scopes"`         // 为空时使用 openid profile email
+	UsernameClaim string   `mapstructure:"username_claim"` // 用户名声明，默认preferred_username，为空时使用邮箱前缀
+	GroupsClaim   string   `mapstructure:"groups_claim"`   // 用户组声明，默认groups
+	// RoleMappings 用户组到角色的映射，匹配多个时使用等级最高的角色；都不匹配时使用 DefaultRole。
+	// 只同步由该身份提供方创建的用户的角色，关联的已有本地账号保持原角色
+	RoleMappings []GroupRoleMapping `mapstructure:"role_mappings"`
+	DefaultRole  string             `mapstructure:"default_role"` // 没有匹配用户组时的角色，默认user
+	// LinkExistingUsers 首次登录时是否将身份提供方确认过的邮箱关联到同邮箱的已有本地账号，默认拒绝登录
+	LinkExistingUsers bool `mapstructure:"link_existing_users"`
+}
+
+// GroupRoleMapping 外部用户组到角色的映射
+type GroupRoleMapping struct {
+	Group string `mapstructure:"group"` // 身份提供方或目录服务的用户组
+	Role  string `mapstructure:"role"`  // 角色名称
+}
+
+type LDAPConfig struct {
+	URL                string `mapstructure:"url"`                  // ldap://host:389 或 ldaps://host:636，为空时不启用LDAP认证
+	StartTLS           bool   `mapstructure:"start_tls"`            // ldap:// 连接后是否使用StartTLS升级
+	CACertFile         string `mapstructure:"ca_cert_file"`         // 目录服务的CA证书，为空时使用系统根证书
+	InsecureSkipVerify bool   `mapstructure:"insecure_skip_verify"` // 跳过证书校验，仅用于测试
+	BindDN             string `mapstructure:"bind_dn"`              // 查询用户和用户组的服务账号，为空时匿名查询
+	BindPassword       string `mapstructure:"bind_password"`        // 服务账号密码
+	BaseDN             string `mapstructure:"base_dn"`              // 用户查询的根DN
+	// UserFilter 用户查询条件，{username} 替换为转义后的用户名，默认 (&(objectClass=person)(uid={username}))；
+	// Active Directory 可使用 (&(objectClass=user)(sAMAccountName={username}))
+	UserFilter         string `mapstructure:"user_filter"`
+	UsernameAttribute  string `mapstructure:"username_attribute"`   // 用户名属性，默认uid，AD为sAMAccountName
+	EmailAttribute     string `mapstructure:"email_attribute"`      // 邮箱属性，默认mail
+	NameAttribute      string `mapstructure:"name_attribute"`       // 昵称属性，默认displayName
+	GroupBaseDN        string `mapstructure:"group_base_dn"`        // 用户组查询的根DN，默认同 base_dn
+	GroupFilter        string `mapstructure:"group_filter"`         // 用户组查询条件，{dn} 替换为用户DN，默认 (|(member={dn})(uniqueMember={dn}))
+	GroupNameAttribute string `mapstructure:"group_name_attribute"` // 用户组名称属性，默认cn
+	Timeout            string `mapstructure:"timeout"`              // 单次请求超时时间，默认10s
+	// RoleMappings 用户组到角色的映射，匹配多个时使用等级最高的角色；都不匹配时使用 DefaultRole
+	RoleMappings []GroupRoleMapping `mapstructure:"role_mappings"`
+	DefaultRole  string             `mapstructure:"default_role"` // 没有匹配用户组时的角色，默认user
+}
+
+type OAuthConfig struct {
+	Issuer         string `mapstructure:"issuer"`           // 签发方地址，如 https://admin.example.com，为空时不启用OAuth2授权服务
+	ConsentURL     string `mapstructure:"consent_url"`      // 前端登录授权页地址，授权请求参数附加在查询字符串中，默认 <issuer>/oauth/consent
+	AccessTokenTTL string `mapstructure:"access_token_ttl"` // 访问令牌有效期，默认1h
+}
+
+type MailConfig struct {
+	Driver      string `mapstructure:"driver"`       // smtp、file 或 log，默认log：只把邮件写入日志，仅用于开发
+	Host        string `mapstructure:"host"`         // SMTP服务器
+	Port        int    `mapstructure:"port"`         // SMTP端口，默认587，encryption 为 tls 时默认465
+	Username    string `mapstructure:"username"`     // SMTP用户名，为空时不认证
+	Password    string `mapstructure:"password"`     // SMTP密码
+	Encryption  string `mapstructure:"encryption"`   // starttls（默认）、tls 或 none
+	From        string `mapstructure:"from"`         // 发件人，如 Domain Admin <noreply@example.com>
+	Dir         string `mapstructure:"dir"`          // file 方式保存 .eml 文件的目录，默认 data/mail
+	TemplateDir string `mapstructure:"template_dir"` // 自定义邮件模板目录，其中的 .tmpl 文件覆盖同名的内置模板
+	Timeout     string `mapstructure:"timeout"`      // 发送超时时间，默认10s
+}
+
+type AccountConfig struct {
+	// BaseURL 前端地址，邮件中的链接为 <base_url>/verify-email?token=... 和 <base_url>/reset-password?token=...
+	BaseURL                  string `mapstructure:"base_url"`
+	AppName                  string `mapstructure:"app_name"`                   // 邮件中显示的系统名称，默认Domain Admin
+	RequireEmailVerification bool   `mapstructure:"require_email_verification"` // 注册的用户验证邮箱后才能登录
+	VerifyTokenTTL           string `mapstructure:"verify_token_ttl"`           // 邮箱验证链接有效期，默认24h
+	ResetTokenTTL            string `mapstructure:"reset_token_ttl"`            // 重置密码链接有效期，默认1h
+}
+
 var cfg = &Config{}
 
 func InitConfig() *Config {
@@ -130,3 +294,244 @@ func (c DataBaseConfig) DSN() string {
 		return ""
 	}
 }
+
+// AccountName 返回云服务商账号名称，未配置名称时使用类型
+func (c CloudProviderConfig) AccountName() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.Type
+}
+
+// Limit 返回每个用户每分钟的WHOIS查询次数，未配置时默认10次
+func (c WhoisConfig) Limit() int {
+	if c.RateLimit > 0 {
+		return c.RateLimit
+	}
+	return 10
+}
+
+// TTL 返回WHOIS查询结果缓存时间，未配置或格式错误时默认24小时
+func (c WhoisConfig) TTL() time.Duration {
+	if d, err := time.ParseDuration(c.CacheTTL); err == nil && d > 0 {
+		return d
+	}
+	return 24 * time.Hour
+}
+
+// LookupTimeout 返回单次WHOIS查询超时时间，未配置或格式错误时默认10秒
+func (c WhoisConfig) LookupTimeout() time.Duration {
+	if d, err := time.ParseDuration(c.Timeout); err == nil && d > 0 {
+		return d
+	}
+	return 10 * time.Second
+}
+
+// ExpiryWindow 返回证书即将到期的判定窗口，未配置时默认30天
+func (c CertificateConfig) ExpiryWindow() time.Duration {
+	days := c.ExpiryDays
+	if days <= 0 {
+		days = 30
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// ProbeTimeout 返回单次证书探测超时时间，未配置或格式错误时默认10秒
+func (c CertificateConfig) ProbeTimeout() time.Duration {
+	if d, err := time.ParseDuration(c.Timeout); err == nil && d > 0 {
+		return d
+	}
+	return 10 * time.Second
+}
+
+// RenewWindow 返回证书自动续期的提前时间，未配置时默认30天
+func (c ACMEConfig) RenewWindow() time.Duration {
+	days := c.RenewBeforeDays
+	if days <= 0 {
+		days = 30
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// Propagation 返回验证记录的生效等待时间，未配置或格式错误时默认30秒
+func (c ACMEConfig) Propagation() time.Duration {
+	if d, err := time.ParseDuration(c.PropagationDelay); err == nil && d >= 0 {
+		return d
+	}
+	return 30 * time.Second
+}
+
+// IssueTimeout 返回单次签发超时时间，未配置或格式错误时默认10分钟
+func (c ACMEConfig) IssueTimeout() time.Duration {
+	if d, err := time.ParseDuration(c.Timeout); err == nil && d > 0 {
+		return d
+	}
+	return 10 * time.Minute
+}
+
+// Limit 返回每个用户每分钟的DNS诊断查询次数，未配置时默认30次
+func (c DNSCheckConfig) Limit() int {
+	if c.RateLimit > 0 {
+		return c.RateLimit
+	}
+	return 30
+}
+
+// QueryTimeout 返回单次DNS查询超时时间，未配置或格式错误时默认5秒
+func (c DNSCheckConfig) QueryTimeout() time.Duration {
+	if d, err := time.ParseDuration(c.Timeout); err == nil && d > 0 {
+		return d
+	}
+	return 5 * time.Second
+}
+
+// AccessTTL 返回访问令牌有效期，未配置或格式错误时默认15分钟
+func (c JWTConfig) AccessTTL() time.Duration {
+	if d, err := time.ParseDuration(c.Expiration); err == nil && d > 0 {
+		return d
+	}
+	return 15 * time.Minute
+}
+
+// RefreshTTL 返回刷新令牌有效期，未配置或格式错误时默认7天
+func (c JWTConfig) RefreshTTL() time.Duration {
+	if d, err := time.ParseDuration(c.RefreshExpiration); err == nil && d > 0 {
+		return d
+	}
+	return 7 * 24 * time.Hour
+}
+
+// KeyReloadInterval 返回重新加载签名密钥的间隔，未配置或格式错误时默认1分钟
+func (c JWTConfig) KeyReloadInterval() time.Duration {
+	if d, err := time.ParseDuration(c.KeyReload); err == nil && d > 0 {
+		return d
+	}
+	return time.Minute
+}
+
+// ExpireAfter 返回变更请求的有效期，未配置或格式错误时默认72小时
+func (c ApprovalConfig) ExpireAfter() time.Duration {
+	if d, err := time.ParseDuration(c.Expire); err == nil && d > 0 {
+		return d
+	}
+	return 72 * time.Hour
+}
+
+// DisplayName 返回认证器中显示的依赖方名称，未配置时默认Domain Admin
+func (c WebAuthnConfig) DisplayName() string {
+	if c.RPName != "" {
+		return c.RPName
+	}
+	return "Domain Admin"
+}
+
+// Label 返回登录页显示的身份提供方名称，未配置时使用 name
+func (c OIDCProviderConfig) Label() string {
+	if c.DisplayName != "" {
+		return c.DisplayName
+	}
+	return c.Name
+}
+
+// Role 返回没有匹配用户组时的角色，未配置时默认user
+func (c OIDCProviderConfig) Role() string {
+	if c.DefaultRole != "" {
+		return c.DefaultRole
+	}
+	return "user"
+}
+
+// Enabled 是否启用LDAP认证
+func (c LDAPConfig) Enabled() bool {
+	return c.URL != ""
+}
+
+// RequestTimeout 返回单次LDAP请求超时时间，未配置或格式错误时默认10秒
+func (c LDAPConfig) RequestTimeout() time.Duration {
+	if d, err := time.ParseDuration(c.Timeout); err == nil && d > 0 {
+		return d
+	}
+	return 10 * time.Second
+}
+
+// Role 返回没有匹配用户组时的角色，未配置时默认user
+func (c LDAPConfig) Role() string {
+	if c.DefaultRole != "" {
+		return c.DefaultRole
+	}
+	return "user"
+}
+
+// Enabled 是否启用OAuth2授权服务
+func (c OAuthConfig) Enabled() bool {
+	return c.Issuer != ""
+}
+
+// IssuerURL 返回去掉末尾斜杠的签发方地址
+func (c OAuthConfig) IssuerURL() string {
+	return strings.TrimRight(c.Issuer, "/")
+}
+
+// Consent 返回前端登录授权页地址，未配置时默认 <issuer>/oauth/consent
+func (c OAuthConfig) Consent() string {
+	if c.ConsentURL != "" {
+		return c.ConsentURL
+	}
+	return c.IssuerURL() + "/oauth/consent"
+}
+
+// AccessTTL 返回访问令牌有效期，未配置或格式错误时默认1小时
+func (c OAuthConfig) AccessTTL() time.Duration {
+	if d, err := time.ParseDuration(c.AccessTokenTTL); err == nil && d > 0 {
+		return d
+	}
+	return time.Hour
+}
+
+// Sender 返回发件人，未配置时默认 Domain Admin <noreply@localhost>
+func (c MailConfig) Sender() string {
+	if c.From != "" {
+		return c.From
+	}
+	return "Domain Admin <noreply@localhost>"
+}
+
+// Directory 返回 file 方式保存邮件的目录，未配置时默认 data/mail
+func (c MailConfig) Directory() string {
+	if c.Dir != "" {
+		return c.Dir
+	}
+	return "data/mail"
+}
+
+// SendTimeout 返回发送超时时间，未配置或格式错误时默认10秒
+func (c MailConfig) SendTimeout() time.Duration {
+	if d, err := time.ParseDuration(c.Timeout); err == nil && d > 0 {
+		return d
+	}
+	return 10 * time.Second
+}
+
+// Name 返回邮件中显示的系统名称，未配置时默认Domain Admin
+func (c AccountConfig) Name() string {
+	if c.AppName != "" {
+		return c.AppName
+	}
+	return "Domain Admin"
+}
+
+// VerifyTTL 返回邮箱验证链接有效期，未配置或格式错误时默认24小时
+func (c AccountConfig) VerifyTTL() time.Duration {
+	if d, err := time.ParseDuration(c.VerifyTokenTTL); err == nil && d > 0 {
+		return d
+	}
+	return 24 * time.Hour
+}
+
+// ResetTTL 返回重置密码链接有效期，未配置或格式错误时默认1小时
+func (c AccountConfig) ResetTTL() time.Duration {
+	if d, err := time.ParseDuration(c.ResetTokenTTL); err == nil && d > 0 {
+		return d
+	}
+	return time.Hour
+}
@@ -0,0 +1,288 @@
+package dnscheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// 应答来源
+const (
+	SourceResolver      = "resolver"
+	SourceAuthoritative = "authoritative"
+)
+
+// 默认配置
+const (
+	DefaultTimeout = 5 * time.Second
+	DefaultPort    = "53"
+	// maxNameServers 最多查询的权威服务器数量
+	maxNameServers = 8
+)
+
+// DefaultResolvers 默认查询的公共解析器
+var DefaultResolvers = []string{"1.1.1.1:53", "8.8.8.8:53", "9.9.9.9:53"}
+
+// ErrNoZone 无法通过解析器找到名称所在的区域
+var ErrNoZone = errors.New("zone not found")
+
+// Result 单个服务器的查询结果
+type Result struct {
+	// Server 查询的服务器地址(host:port)
+	Server string `json:"server"`
+	Source string `json:"source"`
+	// NameServer 权威服务器的主机名，仅 Source 为 authoritative 时有值
+	NameServer    string   `json:"name_server,omitempty"`
+	Rcode         string   `json:"rcode,omitempty"`
+	Authenticated bool     `json:"authenticated"`
+	Authoritative bool     `json:"authoritative"`
+	Answers       []Record `json:"answers"`
+	DurationMs    int64    `json:"duration_ms"`
+	Error         string   `json:"error,omitempty"`
+}
+
+// AnswerSet 一组相同的应答及返回该应答的服务器
+type AnswerSet struct {
+	Rcode   string   `json:"rcode"`
+	Values  []string `json:"values"`
+	Servers []string `json:"servers"`
+}
+
+// Report 多个服务器的查询结果对比
+type Report struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Zone 名称所在的区域，未找到时为空
+	Zone       string   `json:"zone"`
+	ZoneError  string   `json:"zone_error,omitempty"`
+	Results    []Result `json:"results"`
+	Consistent bool     `json:"consistent"`
+	// AnswerSets 按应答内容分组，Consistent 为 false 时可据此查看差异
+	AnswerSets []AnswerSet `json:"answer_sets"`
+	CheckedAt  time.Time   `json:"checked_at"`
+}
+
+// Checker 向多个解析器及区域的权威服务器查询同一名称并对比结果
+type Checker struct {
+	// Resolvers 递归解析器地址(host:port)，第一个可用的解析器同时用于查找权威服务器
+	Resolvers []string
+	// Timeout 单次查询超时时间
+	Timeout time.Duration
+	// AuthoritativePort 查询权威服务器使用的端口，默认53
+	AuthoritativePort string
+}
+
+// New 创建检查器，resolvers 为空时使用 DefaultResolvers
+func New(resolvers []string, timeout time.Duration) *Checker {
+	if len(resolvers) == 0 {
+		resolvers = DefaultResolvers
+	}
+	return &Checker{Resolvers: resolvers, Timeout: timeout}
+}
+
+// Check 查询 name 的 qtype 记录
+// 所有解析器和权威服务器并发查询，任一服务器查询失败或应答不一致时 Consistent 为 false
+func (c *Checker) Check(ctx context.Context, name string, qtype dnsmessage.Type) (*Report, error) {
+	name = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+	if name == "" {
+		return nil, errors.New("empty name")
+	}
+	if len(c.Resolvers) == 0 {
+		return nil, errors.New("no resolvers configured")
+	}
+
+	report := &Report{
+		Name:      name,
+		Type:      typeName(qtype),
+		CheckedAt: time.Now(),
+	}
+
+	type target struct {
+		server     string
+		source     string
+		nameServer string
+	}
+	var targets []target
+	for _, r := range c.Resolvers {
+		targets = append(targets, target{server: r, source: SourceResolver})
+	}
+
+	zone, servers, err := c.authoritativeServers(ctx, name)
+	if err != nil {
+		report.ZoneError = err.Error()
+	}
+	report.Zone = zone
+	for _, ns := range servers {
+		targets = append(targets, target{server: ns.addr, source: SourceAuthoritative, nameServer: ns.host})
+	}
+
+	report.Results = make([]Result, len(targets))
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		wg.Add(1)
+		go func(i int, t target) {
+			defer wg.Done()
+			result := Result{Server: t.server, Source: t.source, NameServer: t.nameServer}
+			resp, err := c.query(ctx, t.server, name, qtype, t.source == SourceResolver)
+			if err != nil {
+				result.Error = err.Error()
+			} else {
+				result.Rcode = resp.Rcode
+				result.Authenticated = resp.Authenticated
+				result.Authoritative = resp.Authoritative
+				result.Answers = resp.Answers
+				result.DurationMs = resp.RTT.Milliseconds()
+			}
+			report.Results[i] = result
+		}(i, t)
+	}
+	wg.Wait()
+
+	report.AnswerSets, report.Consistent = compare(report.Results)
+	return report, nil
+}
+
+// query 在超时时间内完成一次查询
+func (c *Checker) query(ctx context.Context, server, name string, qtype dnsmessage.Type, recursive bool) (*Response, error) {
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return Query(ctx, server, name, qtype, recursive)
+}
+
+// nameServer 权威服务器
+type nameServer struct {
+	host string
+	addr string
+}
+
+// authoritativeServers 通过解析器查找名称所在区域及其权威服务器地址
+// 从名称本身开始逐级向上查询 NS 记录，第一个拥有 NS 记录的名称即为区域顶点
+func (c *Checker) authoritativeServers(ctx context.Context, name string) (string, []nameServer, error) {
+	resolver, zone, hosts, err := c.findZone(ctx, name)
+	if err != nil {
+		return "", nil, err
+	}
+
+	port := c.AuthoritativePort
+	if port == "" {
+		port = DefaultPort
+	}
+	if len(hosts) > maxNameServers {
+		hosts = hosts[:maxNameServers]
+	}
+
+	var servers []nameServer
+	var lastErr error
+	for _, host := range hosts {
+		addrs, err := c.lookupAddrs(ctx, resolver, host)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		for _, addr := range addrs {
+			servers = append(servers, nameServer{host: host, addr: net.JoinHostPort(addr, port)})
+		}
+	}
+	if len(servers) == 0 {
+		if lastErr == nil {
+			lastErr = errors.New("no address for name servers")
+		}
+		return zone, nil, fmt.Errorf("resolve name servers of %s: %w", zone, lastErr)
+	}
+	return zone, servers, nil
+}
+
+// findZone 返回用于查找的解析器、区域名称及其 NS 主机名
+func (c *Checker) findZone(ctx context.Context, name string) (string, string, []string, error) {
+	var lastErr error
+	for _, resolver := range c.Resolvers {
+		failed := false
+		labels := strings.Split(name, ".")
+		for i := range labels {
+			candidate := strings.Join(labels[i:], ".")
+			resp, err := c.query(ctx, resolver, candidate, dnsmessage.TypeNS, true)
+			if err != nil {
+				// 当前解析器不可用，换下一个解析器重新查找
+				lastErr = err
+				failed = true
+				break
+			}
+			var hosts []string
+			for _, rr := range resp.Answers {
+				if rr.Type == "NS" && rr.Name == candidate {
+					hosts = append(hosts, rr.Value)
+				}
+			}
+			if len(hosts) > 0 {
+				sort.Strings(hosts)
+				return resolver, candidate, hosts, nil
+			}
+		}
+		if !failed {
+			return "", "", nil, ErrNoZone
+		}
+	}
+	return "", "", nil, fmt.Errorf("find zone: %w", lastErr)
+}
+
+// lookupAddrs 通过解析器查询主机的 IPv4 地址，没有 IPv4 地址时查询 IPv6 地址
+func (c *Checker) lookupAddrs(ctx context.Context, resolver, host string) ([]string, error) {
+	for _, qtype := range []dnsmessage.Type{dnsmessage.TypeA, dnsmessage.TypeAAAA} {
+		resp, err := c.query(ctx, resolver, host, qtype, true)
+		if err != nil {
+			return nil, err
+		}
+		var addrs []string
+		for _, rr := range resp.Answers {
+			if rr.Type == typeName(qtype) {
+				addrs = append(addrs, rr.Value)
+			}
+		}
+		if len(addrs) > 0 {
+			return addrs, nil
+		}
+	}
+	return nil, fmt.Errorf("no address for %s", host)
+}
+
+// compare 按应答码和记录值（忽略 TTL 和顺序）对结果分组
+func compare(results []Result) ([]AnswerSet, bool) {
+	consistent := true
+	var sets []AnswerSet
+	index := make(map[string]int)
+	for _, r := range results {
+		if r.Error != "" {
+			consistent = false
+			continue
+		}
+		values := make([]string, 0, len(r.Answers))
+		for _, rr := range r.Answers {
+			values = append(values, fmt.Sprintf("%s %s %s", rr.Name, rr.Type, rr.Value))
+		}
+		sort.Strings(values)
+		key := r.Rcode + "\n" + strings.Join(values, "\n")
+
+		i, ok := index[key]
+		if !ok {
+			i = len(sets)
+			index[key] = i
+			sets = append(sets, AnswerSet{Rcode: r.Rcode, Values: values})
+		}
+		sets[i].Servers = append(sets[i].Servers, r.Server)
+	}
+	if len(sets) != 1 {
+		consistent = false
+	}
+	return sets, consistent
+}
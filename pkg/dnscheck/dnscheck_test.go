@@ -0,0 +1,374 @@
+package dnscheck
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// rr 测试服务器的一条记录
+type rr struct {
+	name  string
+	ttl   uint32
+	body  dnsmessage.ResourceBody
+	qtype dnsmessage.Type
+}
+
+// testServer 进程内 DNS 服务器，同一端口同时监听 UDP 和 TCP
+type testServer struct {
+	addr    string
+	records []rr
+	// ad 是否在应答中设置 AD 标志；服务器已在运行，标志使用原子变量
+	ad atomic.Bool
+	// aa 是否在应答中设置 AA 标志
+	aa atomic.Bool
+	// truncate 为 true 时 UDP 应答只返回截断标志
+	truncate atomic.Bool
+	// queries 收到的查询数量
+	queries  atomic.Int32
+	tcpCount atomic.Int32
+	// sawDO 最近一次查询是否携带 EDNS0 DO 标志
+	sawDO atomic.Bool
+}
+
+func newTestServer(t *testing.T, records ...rr) *testServer {
+	t.Helper()
+	s := &testServer{records: records}
+
+	var udp net.PacketConn
+	var tcp net.Listener
+	for i := 0; i < 10; i++ {
+		var err error
+		udp, err = net.ListenPacket("udp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("listen udp: %v", err)
+		}
+		tcp, err = net.Listen("tcp", udp.LocalAddr().String())
+		if err == nil {
+			break
+		}
+		udp.Close()
+		udp = nil
+	}
+	if udp == nil {
+		t.Fatal("no free port for udp and tcp")
+	}
+	s.addr = udp.LocalAddr().String()
+	t.Cleanup(func() {
+		udp.Close()
+		tcp.Close()
+	})
+
+	go func() {
+		buf := make([]byte, 65535)
+		for {
+			n, peer, err := udp.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if resp := s.handle(buf[:n], true); resp != nil {
+				udp.WriteTo(resp, peer)
+			}
+		}
+	}()
+	go func() {
+		for {
+			conn, err := tcp.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				s.tcpCount.Add(1)
+				var length [2]byte
+				if _, err := io.ReadFull(conn, length[:]); err != nil {
+					return
+				}
+				msg := make([]byte, binary.BigEndian.Uint16(length[:]))
+				if _, err := io.ReadFull(conn, msg); err != nil {
+					return
+				}
+				resp := s.handle(msg, false)
+				out := make([]byte, 2+len(resp))
+				binary.BigEndian.PutUint16(out, uint16(len(resp)))
+				copy(out[2:], resp)
+				conn.Write(out)
+			}()
+		}
+	}()
+	return s
+}
+
+// handle 按记录表生成应答，名称存在但没有该类型记录时返回 NOERROR 空应答，否则返回 NXDOMAIN
+func (s *testServer) handle(msg []byte, udp bool) []byte {
+	s.queries.Add(1)
+	var p dnsmessage.Parser
+	h, err := p.Start(msg)
+	if err != nil {
+		return nil
+	}
+	q, err := p.Question()
+	if err != nil {
+		return nil
+	}
+	p.SkipAllQuestions()
+	p.SkipAllAnswers()
+	p.SkipAllAuthorities()
+	additionals, _ := p.AllAdditionals()
+	do := false
+	for _, a := range additionals {
+		if a.Header.Type == dnsmessage.TypeOPT && a.Header.DNSSECAllowed() {
+			do = true
+		}
+	}
+	s.sawDO.Store(do)
+
+	name := strings.ToLower(q.Name.String())
+	var answers []rr
+	known := false
+	for _, r := range s.records {
+		if r.name+"." != name {
+			continue
+		}
+		known = true
+		if r.qtype == q.Type {
+			answers = append(answers, r)
+		}
+	}
+
+	header := dnsmessage.Header{
+		ID:                 h.ID,
+		Response:           true,
+		Authoritative:      s.aa.Load(),
+		RecursionDesired:   h.RecursionDesired,
+		RecursionAvailable: true,
+		AuthenticData:      s.ad.Load(),
+	}
+	if !known {
+		header.RCode = dnsmessage.RCodeNameError
+	}
+	if udp && s.truncate.Load() {
+		header.Truncated = true
+		answers = nil
+	}
+
+	b := dnsmessage.NewBuilder(nil, header)
+	b.StartQuestions()
+	b.Question(q)
+	b.StartAnswers()
+	for _, r := range answers {
+		rh := dnsmessage.ResourceHeader{Name: q.Name, Class: dnsmessage.ClassINET, TTL: r.ttl}
+		switch body := r.body.(type) {
+		case *dnsmessage.AResource:
+			b.AResource(rh, *body)
+		case *dnsmessage.NSResource:
+			b.NSResource(rh, *body)
+		case *dnsmessage.MXResource:
+			b.MXResource(rh, *body)
+		case *dnsmessage.TXTResource:
+			b.TXTResource(rh, *body)
+		case *dnsmessage.UnknownResource:
+			rh.Type = body.Type
+			b.UnknownResource(rh, *body)
+		}
+	}
+	resp, _ := b.Finish()
+	return resp
+}
+
+func mustName(t *testing.T, s string) dnsmessage.Name {
+	t.Helper()
+	n, err := dnsmessage.NewName(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func aRecord(name string, ttl uint32, ip string) rr {
+	var a dnsmessage.AResource
+	copy(a.A[:], net.ParseIP(ip).To4())
+	return rr{name: name, ttl: ttl, qtype: dnsmessage.TypeA, body: &a}
+}
+
+// zoneRecords example.test 区域的记录，权威服务器 ns1.example.test 指向 127.0.0.1
+func zoneRecords(t *testing.T, wwwIP string, ttl uint32) []rr {
+	return []rr{
+		{name: "example.test", ttl: 3600, qtype: dnsmessage.TypeNS, body: &dnsmessage.NSResource{NS: mustName(t, "ns1.example.test.")}},
+		aRecord("ns1.example.test", 3600, "127.0.0.1"),
+		aRecord("www.example.test", ttl, wwwIP),
+	}
+}
+
+func TestQuery(t *testing.T) {
+	srv := newTestServer(t,
+		aRecord("www.example.test", 300, "192.0.2.1"),
+		aRecord("www.example.test", 300, "192.0.2.2"),
+		rr{name: "example.test", ttl: 60, qtype: dnsmessage.TypeMX, body: &dnsmessage.MXResource{Pref: 10, MX: mustName(t, "mail.example.test.")}},
+		rr{name: "example.test", ttl: 60, qtype: dnsmessage.TypeTXT, body: &dnsmessage.TXTResource{TXT: []string{"v=spf1 -all"}}},
+		rr{name: "example.test", ttl: 60, qtype: types["CAA"], body: &dnsmessage.UnknownResource{
+			Type: types["CAA"], Data: append([]byte{0, 5}, "issueletsencrypt.org"...),
+		}},
+	)
+	srv.ad.Store(true)
+	ctx := context.Background()
+
+	resp, err := Query(ctx, srv.addr, "WWW.example.test.", dnsmessage.TypeA, true)
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if resp.Rcode != "NOERROR" || !resp.Authenticated {
+		t.Errorf("rcode = %s, ad = %v", resp.Rcode, resp.Authenticated)
+	}
+	if !srv.sawDO.Load() {
+		t.Error("query did not set EDNS0 DO bit")
+	}
+	if len(resp.Answers) != 2 || resp.Answers[0] != (Record{Name: "www.example.test", Type: "A", TTL: 300, Value: "192.0.2.1"}) {
+		t.Errorf("answers = %+v", resp.Answers)
+	}
+
+	cases := map[string]string{
+		"MX":  "10 mail.example.test",
+		"TXT": `"v=spf1 -all"`,
+		"CAA": `0 issue "letsencrypt.org"`,
+	}
+	for typ, want := range cases {
+		qtype, err := ParseType(strings.ToLower(typ))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := Query(ctx, srv.addr, "example.test", qtype, true)
+		if err != nil {
+			t.Fatalf("Query %s: %v", typ, err)
+		}
+		if len(resp.Answers) != 1 || resp.Answers[0].Value != want || resp.Answers[0].Type != typ {
+			t.Errorf("%s answers = %+v, want %s", typ, resp.Answers, want)
+		}
+	}
+
+	resp, err = Query(ctx, srv.addr, "missing.example.test", dnsmessage.TypeA, true)
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if resp.Rcode != "NXDOMAIN" || len(resp.Answers) != 0 {
+		t.Errorf("missing name: rcode = %s, answers = %+v", resp.Rcode, resp.Answers)
+	}
+
+	if _, err := ParseType("HINFO"); err == nil {
+		t.Error("ParseType accepted unsupported type")
+	}
+}
+
+func TestQueryTCPFallback(t *testing.T) {
+	srv := newTestServer(t, aRecord("www.example.test", 300, "192.0.2.1"))
+	srv.truncate.Store(true)
+
+	resp, err := Query(context.Background(), srv.addr, "www.example.test", dnsmessage.TypeA, true)
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if srv.tcpCount.Load() != 1 {
+		t.Errorf("tcp queries = %d, want 1", srv.tcpCount.Load())
+	}
+	if len(resp.Answers) != 1 || resp.Answers[0].Value != "192.0.2.1" || resp.Truncated {
+		t.Errorf("answers = %+v, truncated = %v", resp.Answers, resp.Truncated)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	// 权威服务器和两个解析器都在 127.0.0.1 的不同端口上
+	auth := newTestServer(t, zoneRecords(t, "192.0.2.1", 300)...)
+	auth.aa.Store(true)
+	validating := newTestServer(t, zoneRecords(t, "192.0.2.1", 120)...)
+	validating.ad.Store(true)
+	stale := newTestServer(t, zoneRecords(t, "192.0.2.99", 60)...)
+
+	_, port, _ := net.SplitHostPort(auth.addr)
+	checker := New([]string{validating.addr, stale.addr}, time.Second)
+	checker.AuthoritativePort = port
+
+	report, err := checker.Check(context.Background(), "www.example.test.", dnsmessage.TypeA)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if report.Zone != "example.test" || report.ZoneError != "" {
+		t.Fatalf("zone = %q, zone error = %q", report.Zone, report.ZoneError)
+	}
+	if len(report.Results) != 3 {
+		t.Fatalf("results = %+v", report.Results)
+	}
+
+	byServer := make(map[string]Result)
+	for _, r := range report.Results {
+		byServer[r.Server] = r
+	}
+	if r := byServer[validating.addr]; r.Source != SourceResolver || !r.Authenticated || r.Answers[0].TTL != 120 {
+		t.Errorf("validating resolver = %+v", r)
+	}
+	if r := byServer[stale.addr]; r.Authenticated || r.Answers[0].Value != "192.0.2.99" {
+		t.Errorf("stale resolver = %+v", r)
+	}
+	r := byServer[auth.addr]
+	if r.Source != SourceAuthoritative || r.NameServer != "ns1.example.test" || !r.Authoritative || r.Answers[0].TTL != 300 {
+		t.Errorf("authoritative = %+v", r)
+	}
+
+	if report.Consistent {
+		t.Error("report with stale resolver is consistent")
+	}
+	if len(report.AnswerSets) != 2 {
+		t.Fatalf("answer sets = %+v", report.AnswerSets)
+	}
+	if set := report.AnswerSets[0]; len(set.Servers) != 2 || set.Values[0] != "www.example.test A 192.0.2.1" {
+		t.Errorf("answer set = %+v", set)
+	}
+
+	// 去掉过期的解析器后应答一致（TTL 不同不影响一致性）
+	checker.Resolvers = []string{validating.addr}
+	report, err = checker.Check(context.Background(), "www.example.test", dnsmessage.TypeA)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if !report.Consistent || len(report.AnswerSets) != 1 {
+		t.Errorf("consistent = %v, answer sets = %+v", report.Consistent, report.AnswerSets)
+	}
+}
+
+func TestCheckUnreachableResolver(t *testing.T) {
+	// 保留一个端口后关闭，向其发送的 UDP 查询会失败或超时
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dead := conn.LocalAddr().String()
+	conn.Close()
+
+	srv := newTestServer(t, zoneRecords(t, "192.0.2.1", 300)...)
+	_, port, _ := net.SplitHostPort(srv.addr)
+	checker := New([]string{dead, srv.addr}, 200*time.Millisecond)
+	checker.AuthoritativePort = port
+
+	report, err := checker.Check(context.Background(), "www.example.test", dnsmessage.TypeA)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	// 第一个解析器不可用时改用下一个解析器查找区域
+	if report.Zone != "example.test" {
+		t.Errorf("zone = %q, zone error = %q", report.Zone, report.ZoneError)
+	}
+	if report.Consistent {
+		t.Error("report with failed resolver is consistent")
+	}
+	for _, r := range report.Results {
+		if (r.Server == dead) != (r.Error != "") {
+			t.Errorf("result %s error = %q", r.Server, r.Error)
+		}
+	}
+}
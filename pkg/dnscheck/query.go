@@ -0,0 +1,324 @@
+package dnscheck
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// udpPayloadSize EDNS0 声明的 UDP 报文大小
+const udpPayloadSize = 1232
+
+// Record 一条应答记录
+type Record struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	TTL   uint32 `json:"ttl"`
+	Value string `json:"value"`
+}
+
+// Response 单个服务器的应答
+type Response struct {
+	Rcode string
+	// Authenticated 应答的 AD 标志，表示解析器已完成 DNSSEC 验证
+	Authenticated bool
+	// Authoritative 应答的 AA 标志
+	Authoritative bool
+	Truncated     bool
+	Answers       []Record
+	// Authorities 权威区中的记录，用于从引荐或 SOA 中识别区域
+	Authorities []Record
+	RTT         time.Duration
+}
+
+// types 支持查询的记录类型
+var types = map[string]dnsmessage.Type{
+	"A":     dnsmessage.TypeA,
+	"AAAA":  dnsmessage.TypeAAAA,
+	"CNAME": dnsmessage.TypeCNAME,
+	"MX":    dnsmessage.TypeMX,
+	"NS":    dnsmessage.TypeNS,
+	"TXT":   dnsmessage.TypeTXT,
+	"SRV":   dnsmessage.TypeSRV,
+	"SOA":   dnsmessage.TypeSOA,
+	"PTR":   dnsmessage.TypePTR,
+	"CAA":   dnsmessage.Type(257),
+}
+
+// ParseType 解析记录类型名称（不区分大小写）
+func ParseType(s string) (dnsmessage.Type, error) {
+	t, ok := types[strings.ToUpper(strings.TrimSpace(s))]
+	if !ok {
+		return 0, fmt.Errorf("unsupported record type %q", s)
+	}
+	return t, nil
+}
+
+// SupportedTypes 返回支持查询的记录类型名称
+func SupportedTypes() []string {
+	names := make([]string, 0, len(types))
+	for name := range types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// typeName 返回记录类型名称
+func typeName(t dnsmessage.Type) string {
+	for name, v := range types {
+		if v == t {
+			return name
+		}
+	}
+	return strings.TrimPrefix(t.String(), "Type")
+}
+
+// rcodeName 返回应答码名称，如 NOERROR、NXDOMAIN
+func rcodeName(rc dnsmessage.RCode) string {
+	switch rc {
+	case dnsmessage.RCodeSuccess:
+		return "NOERROR"
+	case dnsmessage.RCodeFormatError:
+		return "FORMERR"
+	case dnsmessage.RCodeServerFailure:
+		return "SERVFAIL"
+	case dnsmessage.RCodeNameError:
+		return "NXDOMAIN"
+	case dnsmessage.RCodeNotImplemented:
+		return "NOTIMP"
+	case dnsmessage.RCodeRefused:
+		return "REFUSED"
+	default:
+		return strings.ToUpper(strings.TrimPrefix(rc.String(), "RCode"))
+	}
+}
+
+// Query 向 server(host:port) 发送一次查询
+// 查询携带 EDNS0 DO 标志和 AD 标志以获取解析器的 DNSSEC 验证结果；
+// recursive 为 false 时不设置 RD 标志，用于查询权威服务器。
+// 先使用 UDP，应答被截断时改用 TCP 重试
+func Query(ctx context.Context, server, name string, qtype dnsmessage.Type, recursive bool) (*Response, error) {
+	id := uint16(rand.IntN(1 << 16))
+	msg, err := buildQuery(id, name, qtype, recursive)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	raw, err := exchange(ctx, "udp", server, msg)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := parseResponse(raw, id)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Truncated {
+		raw, err = exchange(ctx, "tcp", server, msg)
+		if err != nil {
+			return nil, err
+		}
+		if resp, err = parseResponse(raw, id); err != nil {
+			return nil, err
+		}
+	}
+	resp.RTT = time.Since(start)
+	return resp, nil
+}
+
+// buildQuery 构造查询报文
+func buildQuery(id uint16, name string, qtype dnsmessage.Type, recursive bool) ([]byte, error) {
+	qname, err := dnsmessage.NewName(fqdn(name))
+	if err != nil {
+		return nil, fmt.Errorf("invalid name %q: %w", name, err)
+	}
+
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{
+		ID:               id,
+		RecursionDesired: recursive,
+		AuthenticData:    true,
+	})
+	b.EnableCompression()
+	if err := b.StartQuestions(); err != nil {
+		return nil, err
+	}
+	if err := b.Question(dnsmessage.Question{Name: qname, Type: qtype, Class: dnsmessage.ClassINET}); err != nil {
+		return nil, err
+	}
+	if err := b.StartAdditionals(); err != nil {
+		return nil, err
+	}
+	var opt dnsmessage.ResourceHeader
+	if err := opt.SetEDNS0(udpPayloadSize, dnsmessage.RCodeSuccess, true); err != nil {
+		return nil, err
+	}
+	if err := b.OPTResource(opt, dnsmessage.OPTResource{}); err != nil {
+		return nil, err
+	}
+	return b.Finish()
+}
+
+// exchange 通过 UDP 或 TCP 发送报文并读取应答
+func exchange(ctx context.Context, network, server string, msg []byte) ([]byte, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, network, server)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if network == "tcp" {
+		buf := make([]byte, 2+len(msg))
+		binary.BigEndian.PutUint16(buf, uint16(len(msg)))
+		copy(buf[2:], msg)
+		if _, err := conn.Write(buf); err != nil {
+			return nil, err
+		}
+		var length [2]byte
+		if _, err := io.ReadFull(conn, length[:]); err != nil {
+			return nil, err
+		}
+		resp := make([]byte, binary.BigEndian.Uint16(length[:]))
+		if _, err := io.ReadFull(conn, resp); err != nil {
+			return nil, err
+		}
+		return resp, nil
+	}
+
+	if _, err := conn.Write(msg); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 65535)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		// 丢弃 ID 不匹配的报文，由 parseResponse 再次校验
+		if n >= 2 && binary.BigEndian.Uint16(buf) == binary.BigEndian.Uint16(msg) {
+			return buf[:n], nil
+		}
+	}
+}
+
+// parseResponse 解析应答报文
+func parseResponse(raw []byte, id uint16) (*Response, error) {
+	var p dnsmessage.Parser
+	h, err := p.Start(raw)
+	if err != nil {
+		return nil, fmt.Errorf("parse response: %w", err)
+	}
+	if h.ID != id || !h.Response {
+		return nil, errors.New("unexpected response")
+	}
+	if err := p.SkipAllQuestions(); err != nil {
+		return nil, fmt.Errorf("parse response: %w", err)
+	}
+
+	resp := &Response{
+		Rcode:         rcodeName(h.RCode),
+		Authenticated: h.AuthenticData,
+		Authoritative: h.Authoritative,
+		Truncated:     h.Truncated,
+	}
+	answers, err := p.AllAnswers()
+	if err != nil {
+		return nil, fmt.Errorf("parse answers: %w", err)
+	}
+	authorities, err := p.AllAuthorities()
+	if err != nil {
+		return nil, fmt.Errorf("parse authorities: %w", err)
+	}
+	for _, rr := range answers {
+		resp.Answers = append(resp.Answers, toRecord(rr))
+	}
+	for _, rr := range authorities {
+		resp.Authorities = append(resp.Authorities, toRecord(rr))
+	}
+	return resp, nil
+}
+
+// toRecord 将资源记录转换为可读形式
+func toRecord(rr dnsmessage.Resource) Record {
+	return Record{
+		Name:  strings.TrimSuffix(strings.ToLower(rr.Header.Name.String()), "."),
+		Type:  typeName(rr.Header.Type),
+		TTL:   rr.Header.TTL,
+		Value: formatBody(rr.Body),
+	}
+}
+
+// formatBody 按区域文件的写法格式化记录值
+func formatBody(body dnsmessage.ResourceBody) string {
+	switch r := body.(type) {
+	case *dnsmessage.AResource:
+		return net.IP(r.A[:]).String()
+	case *dnsmessage.AAAAResource:
+		return net.IP(r.AAAA[:]).String()
+	case *dnsmessage.CNAMEResource:
+		return hostName(r.CNAME)
+	case *dnsmessage.NSResource:
+		return hostName(r.NS)
+	case *dnsmessage.PTRResource:
+		return hostName(r.PTR)
+	case *dnsmessage.MXResource:
+		return fmt.Sprintf("%d %s", r.Pref, hostName(r.MX))
+	case *dnsmessage.SRVResource:
+		return fmt.Sprintf("%d %d %d %s", r.Priority, r.Weight, r.Port, hostName(r.Target))
+	case *dnsmessage.SOAResource:
+		return fmt.Sprintf("%s %s %d %d %d %d %d", hostName(r.NS), hostName(r.MBox),
+			r.Serial, r.Refresh, r.Retry, r.Expire, r.MinTTL)
+	case *dnsmessage.TXTResource:
+		parts := make([]string, len(r.TXT))
+		for i, s := range r.TXT {
+			parts[i] = strconv.Quote(s)
+		}
+		return strings.Join(parts, " ")
+	case *dnsmessage.UnknownResource:
+		if r.Type == types["CAA"] {
+			if v, ok := formatCAA(r.Data); ok {
+				return v
+			}
+		}
+		return fmt.Sprintf("\\# %d %x", len(r.Data), r.Data)
+	default:
+		return body.GoString()
+	}
+}
+
+// formatCAA 解析 CAA 记录（RFC 8659）：flags(1) tag-length(1) tag value
+func formatCAA(data []byte) (string, bool) {
+	if len(data) < 2 || len(data) < 2+int(data[1]) {
+		return "", false
+	}
+	tag := string(data[2 : 2+data[1]])
+	value := string(data[2+data[1]:])
+	return fmt.Sprintf("%d %s %s", data[0], tag, strconv.Quote(value)), true
+}
+
+// hostName 返回不带结尾点的小写主机名
+func hostName(n dnsmessage.Name) string {
+	return strings.TrimSuffix(strings.ToLower(n.String()), ".")
+}
+
+// fqdn 返回带结尾点的完整域名
+func fqdn(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
@@ -1,12 +1,20 @@
 package jwt
 
 import (
+	"errors"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-var SecretKey = []byte("secret")
+// SecretKey HS256 签名密钥，由 Init 设置
+var SecretKey []byte
+
+// ErrNoSecret 既没有 HS256 签名密钥也没有非对称签名密钥
+var ErrNoSecret = errors.New("jwt: no secret or asymmetric signing key configured")
+
+// Expiration 访问令牌有效期
+var Expiration = 15 * time.Minute
 
 type Claims struct {
 	UserID   uint
@@ -15,27 +23,60 @@ type Claims struct {
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(userID uint, username, role string) (string, error) {
+// Init 设置签名密钥和访问令牌有效期，secret 为空时只能使用非对称签名密钥
+func Init(secret string, expiration time.Duration) {
+	if secret != "" {
+		SecretKey = []byte(secret)
+	}
+	if expiration > 0 {
+		Expiration = expiration
+	}
+}
+
+// GenerateToken 使用默认密钥环签发访问令牌
+func GenerateToken(userID uint, username, role, sessionID string) (string, error) {
+	return keyring.GenerateToken(userID, username, role, sessionID)
+}
+
+// GenerateToken 签发访问令牌，sessionID 写入 jti 声明；配置了非对称签名密钥时使用该密钥并在令牌头中写入 kid
+func (r *Keyring) GenerateToken(userID uint, username, role, sessionID string) (string, error) {
+	now := time.Now()
 	claims := Claims{
 		UserID:   userID,
 		Username: username,
 		Role:     role,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(now.Add(Expiration)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ID:        sessionID,
 		},
 	}
+	if key := r.signingKey(); key != nil {
+		token := jwt.NewWithClaims(key.method(), claims)
+		token.Header["kid"] = key.ID
+		return token.SignedString(key.private)
+	}
+	if len(SecretKey) == 0 {
+		return "", ErrNoSecret
+	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(SecretKey)
 }
 
+// ParseToken 使用默认密钥环校验并解析访问令牌
 func ParseToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return SecretKey, nil
-	})
+	return keyring.ParseToken(tokenString)
+}
+
+// ParseToken 校验并解析访问令牌，按令牌头中的 kid 选择验证密钥
+func (r *Keyring) ParseToken(tokenString string) (*Claims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, r.verificationKey,
+		jwt.WithValidMethods([]string{AlgHS256, AlgRS256, AlgES256, AlgEdDSA}))
+	if err != nil {
+		return nil, err
+	}
 	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
 		return claims, nil
-	} else {
-		return nil, err
 	}
+	return nil, jwt.ErrTokenInvalidClaims
 }
@@ -0,0 +1,68 @@
+package jwt
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGenerateAndParseToken(t *testing.T) {
+	Init("test-secret", time.Minute)
+
+	token, err := GenerateToken(7, "alice", "user", "s1")
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+	claims, err := ParseToken(token)
+	if err != nil {
+		t.Fatalf("ParseToken: %v", err)
+	}
+	if claims.UserID != 7 || claims.Username != "alice" || claims.Role != "user" || claims.ID != "s1" {
+		t.Fatalf("unexpected claims: %+v", claims)
+	}
+	if ttl := time.Until(claims.ExpiresAt.Time); ttl > time.Minute || ttl < 50*time.Second {
+		t.Fatalf("unexpected expiry: %v", ttl)
+	}
+}
+
+func TestParseTokenRejectsOtherSecret(t *testing.T) {
+	Init("secret-a", time.Minute)
+	token, err := GenerateToken(1, "admin", "admin", "s1")
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+
+	Init("secret-b", time.Minute)
+	if _, err := ParseToken(token); err == nil {
+		t.Fatal("token signed with another secret should be rejected")
+	}
+}
+
+func TestParseTokenRejectsExpired(t *testing.T) {
+	Init("test-secret", time.Nanosecond)
+	token, err := GenerateToken(1, "admin", "admin", "s1")
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+	time.Sleep(time.Second)
+	if _, err := ParseToken(token); err == nil {
+		t.Fatal("expired token should be rejected")
+	}
+}
+
+func TestNoSecret(t *testing.T) {
+	Init("test-secret", time.Minute)
+	token, err := GenerateToken(1, "admin", "admin", "s1")
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+
+	// 没有签名密钥时不能签发令牌，也不接受空密钥签名的令牌
+	SecretKey = nil
+	t.Cleanup(func() { Init("test-secret", time.Minute) })
+	if _, err := GenerateToken(1, "admin", "admin", "s1"); err != ErrNoSecret {
+		t.Fatalf("GenerateToken without secret: %v", err)
+	}
+	if _, err := ParseToken(token); err == nil {
+		t.Fatal("token should be rejected without secret")
+	}
+}
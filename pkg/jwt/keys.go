@@ -0,0 +1,378 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// 支持的签名算法
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+	AlgEdDSA = "EdDSA"
+)
+
+// rsaKeyBits 生成RSA密钥的长度
+const rsaKeyBits = 2048
+
+// Key 非对称签名密钥，只有公钥的密钥只用于验证
+type Key struct {
+	ID        string
+	Algorithm string
+	private   crypto.Signer
+	public    crypto.PublicKey
+}
+
+// JWK 公钥的JSON Web Key表示
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKSet JSON Web Key Set
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// Keyring 当前可用的验证密钥和签名密钥，包级函数使用默认密钥环
+type Keyring struct {
+	mu      sync.RWMutex
+	keys    map[string]*Key
+	order   []string
+	signing *Key
+
+	// 遇到未知 kid 时重新加载密钥
+	reloadMu       sync.Mutex
+	reload         func() error
+	reloadInterval time.Duration
+	lastReload     time.Time
+}
+
+// NewKeyring 创建空的密钥环，未设置签名密钥时使用 HS256 和 SecretKey
+func NewKeyring() *Keyring {
+	return &Keyring{keys: map[string]*Key{}}
+}
+
+// keyring 包级函数使用的默认密钥环
+var keyring = NewKeyring()
+
+// Default 返回包级函数使用的默认密钥环
+func Default() *Keyring {
+	return keyring
+}
+
+// NewKey 创建密钥，key 为私钥时可用于签名，为公钥时只用于验证；alg 为空时按密钥类型推断
+func NewKey(kid, alg string, key interface{}) (*Key, error) {
+	if kid == "" {
+		return nil, errors.New("jwt: key id is required")
+	}
+	k := &Key{ID: kid}
+	if signer, ok := key.(crypto.Signer); ok {
+		k.private = signer
+		k.public = signer.Public()
+	} else {
+		k.public = key
+	}
+
+	var expected string
+	switch pub := k.public.(type) {
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < rsaKeyBits {
+			return nil, fmt.Errorf("jwt: rsa key %s must be at least %d bits", kid, rsaKeyBits)
+		}
+		expected = AlgRS256
+	case *ecdsa.PublicKey:
+		if pub.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("jwt: ecdsa key %s must use curve P-256", kid)
+		}
+		expected = AlgES256
+	case ed25519.PublicKey:
+		expected = AlgEdDSA
+	default:
+		return nil, fmt.Errorf("jwt: unsupported key type %T for key %s", k.public, kid)
+	}
+	if alg == "" {
+		alg = expected
+	}
+	if alg != expected {
+		return nil, fmt.Errorf("jwt: key %s cannot be used with algorithm %s", kid, alg)
+	}
+	k.Algorithm = alg
+	return k, nil
+}
+
+// ParseKeyPEM 解析PEM格式的私钥或公钥，支持 PKCS#8、PKCS#1、SEC 1 私钥和 PKIX 公钥
+func ParseKeyPEM(kid, alg string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("jwt: key %s is not PEM encoded", kid)
+	}
+
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("jwt: unsupported PEM block %q for key %s", block.Type, kid)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("jwt: parse key %s: %w", kid, err)
+	}
+	return NewKey(kid, alg, key)
+}
+
+// GenerateKey 生成指定算法的新私钥
+func GenerateKey(kid, alg string) (*Key, error) {
+	var key crypto.Signer
+	var err error
+	switch alg {
+	case AlgRS256:
+		key, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case AlgES256:
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case AlgEdDSA:
+		_, key, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("jwt: unsupported algorithm %s", alg)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return NewKey(kid, alg, key)
+}
+
+// CanSign 密钥是否包含私钥
+func (k *Key) CanSign() bool {
+	return k.private != nil
+}
+
+// PrivatePEM 返回 PKCS#8 编码的私钥
+func (k *Key) PrivatePEM() ([]byte, error) {
+	if k.private == nil {
+		return nil, fmt.Errorf("jwt: key %s has no private key", k.ID)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(k.private)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// PublicPEM 返回 PKIX 编码的公钥
+func (k *Key) PublicPEM() ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(k.public)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+// JWK 返回公钥的JWK表示
+func (k *Key) JWK() JWK {
+	jwk := JWK{Kid: k.ID, Use: "sig", Alg: k.Algorithm}
+	switch pub := k.public.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		jwk.Kty = "EC"
+		jwk.Crv = "P-256"
+		jwk.X = base64.RawURLEncoding.EncodeToString(pub.X.FillBytes(make([]byte, 32)))
+		jwk.Y = base64.RawURLEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, 32)))
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+	}
+	return jwk
+}
+
+// method 返回密钥对应的签名方法
+func (k *Key) method() jwt.SigningMethod {
+	switch k.Algorithm {
+	case AlgRS256:
+		return jwt.SigningMethodRS256
+	case AlgES256:
+		return jwt.SigningMethodES256
+	default:
+		return jwt.SigningMethodEdDSA
+	}
+}
+
+// SetKeys 替换默认密钥环的验证密钥和签名密钥
+func SetKeys(keys []*Key, signingKid string) error {
+	return keyring.SetKeys(keys, signingKid)
+}
+
+// SetKeys 替换验证密钥，signingKid 指定签名使用的密钥，为空时继续使用 HS256 和 SecretKey 签名。
+// 设置了非对称签名密钥后不再接受 HS256 令牌
+func (r *Keyring) SetKeys(keys []*Key, signingKid string) error {
+	byID := make(map[string]*Key, len(keys))
+	order := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if _, ok := byID[k.ID]; ok {
+			return fmt.Errorf("jwt: duplicate key id %s", k.ID)
+		}
+		byID[k.ID] = k
+		order = append(order, k.ID)
+	}
+
+	var signing *Key
+	if signingKid != "" {
+		signing = byID[signingKid]
+		if signing == nil {
+			return fmt.Errorf("jwt: signing key %s not found", signingKid)
+		}
+		if !signing.CanSign() {
+			return fmt.Errorf("jwt: signing key %s has no private key", signingKid)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys = byID
+	r.order = order
+	r.signing = signing
+	return nil
+}
+
+// SetReloader 设置验证令牌遇到未知 kid 时重新加载密钥的函数，多实例部署时其他实例轮换的新密钥由此立即生效；
+// 两次重新加载至少间隔 interval，防止携带伪造 kid 的令牌频繁触发加载
+func (r *Keyring) SetReloader(reload func() error, interval time.Duration) {
+	r.reloadMu.Lock()
+	defer r.reloadMu.Unlock()
+	r.reload = reload
+	r.reloadInterval = interval
+	r.lastReload = time.Time{}
+}
+
+// reloadOnMiss 重新加载密钥，距上次加载不足间隔时不做处理；并发的调用等待正在进行的加载完成
+func (r *Keyring) reloadOnMiss() {
+	r.reloadMu.Lock()
+	defer r.reloadMu.Unlock()
+	if r.reload == nil || time.Since(r.lastReload) < r.reloadInterval {
+		return
+	}
+	r.lastReload = time.Now()
+	_ = r.reload()
+}
+
+// SigningKeyID 返回默认密钥环当前签名密钥的kid
+func SigningKeyID() string {
+	return keyring.SigningKeyID()
+}
+
+// SigningKeyID 返回当前签名密钥的kid，使用 HS256 签名时返回空字符串
+func (r *Keyring) SigningKeyID() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.signing == nil {
+		return ""
+	}
+	return r.signing.ID
+}
+
+// JWKS 返回默认密钥环全部验证密钥的公钥
+func JWKS() *JWKSet {
+	return keyring.JWKS()
+}
+
+// JWKS 返回全部验证密钥的公钥
+func (r *Keyring) JWKS() *JWKSet {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	set := &JWKSet{Keys: make([]JWK, 0, len(r.order))}
+	for _, kid := range r.order {
+		set.Keys = append(set.Keys, r.keys[kid].JWK())
+	}
+	return set
+}
+
+// ErrNoSigningKey 没有配置非对称签名密钥
+var ErrNoSigningKey = errors.New("jwt: no asymmetric signing key configured")
+
+// Sign 使用当前非对称签名密钥签名任意声明并在令牌头中写入 kid，用于签发由其他服务通过 JWKS 验证的令牌（如 ID Token）；
+// 只使用 HS256 时返回 ErrNoSigningKey
+func Sign(claims jwt.Claims) (string, error) {
+	key := keyring.signingKey()
+	if key == nil {
+		return "", ErrNoSigningKey
+	}
+	token := jwt.NewWithClaims(key.method(), claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.private)
+}
+
+// signingKey 返回当前签名密钥，nil 表示使用 HS256
+func (r *Keyring) signingKey() *Key {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.signing
+}
+
+// lookup 根据 kid 查找验证密钥
+func (r *Keyring) lookup(kid string) (*Key, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	k, ok := r.keys[kid]
+	return k, ok
+}
+
+// verificationKey 根据令牌头中的 kid 和 alg 选择验证密钥，kid 未知时重新加载一次密钥
+func (r *Keyring) verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		if r.signingKey() != nil || token.Method.Alg() != AlgHS256 {
+			return nil, errors.New("jwt: token has no key id")
+		}
+		if len(SecretKey) == 0 {
+			return nil, ErrNoSecret
+		}
+		return SecretKey, nil
+	}
+
+	k, ok := r.lookup(kid)
+	if !ok {
+		r.reloadOnMiss()
+		k, ok = r.lookup(kid)
+	}
+	if !ok {
+		return nil, fmt.Errorf("jwt: unknown key id %s", kid)
+	}
+	if token.Method.Alg() != k.Algorithm {
+		return nil, fmt.Errorf("jwt: key %s does not use algorithm %s", kid, token.Method.Alg())
+	}
+	return k.public, nil
+}
@@ -0,0 +1,202 @@
+package jwt
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestSignWithKeyID(t *testing.T) {
+	defer SetKeys(nil, "")
+	Init("test-secret", time.Minute)
+
+	for _, alg := range []string{AlgRS256, AlgES256, AlgEdDSA} {
+		key, err := GenerateKey("k-"+alg, alg)
+		if err != nil {
+			t.Fatalf("GenerateKey(%s): %v", alg, err)
+		}
+		if err := SetKeys([]*Key{key}, key.ID); err != nil {
+			t.Fatalf("SetKeys: %v", err)
+		}
+
+		token, err := GenerateToken(3, "bob", "user", "s1")
+		if err != nil {
+			t.Fatalf("GenerateToken(%s): %v", alg, err)
+		}
+		parsed, _, err := jwt.NewParser().ParseUnverified(token, &Claims{})
+		if err != nil {
+			t.Fatalf("ParseUnverified: %v", err)
+		}
+		if parsed.Header["kid"] != key.ID || parsed.Header["alg"] != alg {
+			t.Fatalf("unexpected header: %v", parsed.Header)
+		}
+		if claims, err := ParseToken(token); err != nil || claims.UserID != 3 {
+			t.Fatalf("ParseToken(%s): %v", alg, err)
+		}
+	}
+}
+
+func TestSignClaims(t *testing.T) {
+	defer SetKeys(nil, "")
+	if _, err := Sign(jwt.MapClaims{"sub": "1"}); err != ErrNoSigningKey {
+		t.Fatalf("Sign without key err = %v", err)
+	}
+
+	key, _ := GenerateKey("id", AlgES256)
+	if err := SetKeys([]*Key{key}, key.ID); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := Sign(jwt.MapClaims{"sub": "1", "aud": "client"})
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	parsed, err := jwt.Parse(raw, keyring.verificationKey)
+	if err != nil || parsed.Header["kid"] != "id" {
+		t.Fatalf("parse signed claims: %v %v", err, parsed.Header)
+	}
+	if aud, _ := parsed.Claims.GetAudience(); len(aud) != 1 || aud[0] != "client" {
+		t.Fatalf("aud = %v", aud)
+	}
+}
+
+func TestRotationKeepsOldKeys(t *testing.T) {
+	defer SetKeys(nil, "")
+	Init("test-secret", time.Minute)
+
+	oldKey, _ := GenerateKey("old", AlgES256)
+	newKey, _ := GenerateKey("new", AlgEdDSA)
+	if err := SetKeys([]*Key{oldKey}, "old"); err != nil {
+		t.Fatal(err)
+	}
+	oldToken, _ := GenerateToken(1, "admin", "admin", "s1")
+
+	// 轮换后旧令牌仍然有效
+	if err := SetKeys([]*Key{newKey, oldKey}, "new"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseToken(oldToken); err != nil {
+		t.Fatalf("old token should still verify: %v", err)
+	}
+	if got := SigningKeyID(); got != "new" {
+		t.Fatalf("SigningKeyID = %s", got)
+	}
+
+	// 停用旧密钥后旧令牌失效
+	if err := SetKeys([]*Key{newKey}, "new"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseToken(oldToken); err == nil {
+		t.Fatal("token signed by a retired key should be rejected")
+	}
+}
+
+func TestRejectHMACWhenAsymmetric(t *testing.T) {
+	defer SetKeys(nil, "")
+	Init("test-secret", time.Minute)
+	SetKeys(nil, "")
+	hmacToken, _ := GenerateToken(1, "admin", "admin", "s1")
+
+	key, _ := GenerateKey("k1", AlgES256)
+	if err := SetKeys([]*Key{key}, "k1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseToken(hmacToken); err == nil {
+		t.Fatal("HS256 token should be rejected once an asymmetric signing key is set")
+	}
+}
+
+func TestRejectAlgorithmMismatch(t *testing.T) {
+	defer SetKeys(nil, "")
+	Init("test-secret", time.Minute)
+
+	key, _ := GenerateKey("k1", AlgES256)
+	if err := SetKeys([]*Key{key}, ""); err != nil {
+		t.Fatal(err)
+	}
+	// 使用 HS256 伪造 kid 为 k1 的令牌
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{UserID: 1})
+	forged.Header["kid"] = "k1"
+	token, _ := forged.SignedString([]byte("test-secret"))
+	if _, err := ParseToken(token); err == nil {
+		t.Fatal("token with mismatched algorithm should be rejected")
+	}
+}
+
+func TestParseKeyPEMAndJWKS(t *testing.T) {
+	defer SetKeys(nil, "")
+
+	key, _ := GenerateKey("k1", AlgRS256)
+	privatePEM, err := key.PrivatePEM()
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicPEM, _ := key.PublicPEM()
+
+	private, err := ParseKeyPEM("k1", "", privatePEM)
+	if err != nil || !private.CanSign() || private.Algorithm != AlgRS256 {
+		t.Fatalf("ParseKeyPEM(private): %v", err)
+	}
+	public, err := ParseKeyPEM("k2", "", publicPEM)
+	if err != nil || public.CanSign() {
+		t.Fatalf("ParseKeyPEM(public): %v", err)
+	}
+	if _, err := ParseKeyPEM("k3", AlgES256, privatePEM); err == nil {
+		t.Fatal("rsa key should not be accepted for ES256")
+	}
+	if err := SetKeys([]*Key{public}, "k2"); err == nil || !strings.Contains(err.Error(), "no private key") {
+		t.Fatalf("public key should not be used for signing: %v", err)
+	}
+
+	ed, _ := GenerateKey("k4", AlgEdDSA)
+	if err := SetKeys([]*Key{private, public, ed}, "k1"); err != nil {
+		t.Fatal(err)
+	}
+	set := JWKS()
+	if len(set.Keys) != 3 {
+		t.Fatalf("expected 3 keys, got %d", len(set.Keys))
+	}
+	if k := set.Keys[0]; k.Kty != "RSA" || k.Kid != "k1" || k.N == "" || k.E != "AQAB" {
+		t.Fatalf("unexpected rsa jwk: %+v", k)
+	}
+	if k := set.Keys[2]; k.Kty != "OKP" || k.Crv != "Ed25519" || k.X == "" {
+		t.Fatalf("unexpected ed25519 jwk: %+v", k)
+	}
+}
+
+func TestReloadOnUnknownKeyID(t *testing.T) {
+	signer, verifier := NewKeyring(), NewKeyring()
+	key, _ := GenerateKey("k1", AlgES256)
+	if err := signer.SetKeys([]*Key{key}, "k1"); err != nil {
+		t.Fatal(err)
+	}
+	token, _ := signer.GenerateToken(1, "admin", "admin", "s1")
+
+	// 重新加载后仍找不到 kid 时拒绝令牌，间隔内不再重复加载
+	reloads := 0
+	verifier.SetReloader(func() error {
+		reloads++
+		return nil
+	}, time.Hour)
+	for i := 0; i < 3; i++ {
+		if _, err := verifier.ParseToken(token); err == nil || !strings.Contains(err.Error(), "unknown key id") {
+			t.Fatalf("ParseToken err = %v", err)
+		}
+	}
+	if reloads != 1 {
+		t.Fatalf("reloads = %d, want 1", reloads)
+	}
+
+	// 其他实例轮换的密钥在重新加载后立即可用于验证
+	verifier.SetReloader(func() error {
+		reloads++
+		return verifier.SetKeys([]*Key{key}, "k1")
+	}, time.Hour)
+	if claims, err := verifier.ParseToken(token); err != nil || claims.UserID != 1 {
+		t.Fatalf("ParseToken after reload: %v", err)
+	}
+	if reloads != 2 || verifier.SigningKeyID() != "k1" {
+		t.Fatalf("reloads = %d, signing = %s", reloads, verifier.SigningKeyID())
+	}
+}
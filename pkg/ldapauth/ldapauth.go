@@ -0,0 +1,280 @@
+// Package ldapauth 实现 LDAP / Active Directory 的密码认证和用户组查询：
+// 先用服务账号按用户过滤条件查找用户DN，再用用户的DN和密码绑定校验密码
+package ldapauth
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// 默认查询参数，适用于 OpenLDAP 的 inetOrgPerson 和 groupOfNames
+const (
+	DefaultUserFilter  = "(&(objectClass=person)(uid={username}))"
+	DefaultGroupFilter = "(|(member={dn})(uniqueMember={dn}))"
+)
+
+// pageSize 列出全部用户和用户组时的分页大小，AD 默认单次最多返回1000条
+const pageSize = 500
+
+var (
+	// ErrInvalidCredentials 用户名或密码错误
+	ErrInvalidCredentials = errors.New("ldapauth: invalid credentials")
+	// ErrUserNotFound 目录中没有匹配的用户
+	ErrUserNotFound = errors.New("ldapauth: user not found")
+)
+
+// Config 目录服务连接和查询参数
+type Config struct {
+	URL                string      // ldap://host:389 或 ldaps://host:636
+	StartTLS           bool        // ldap:// 连接后使用 StartTLS 升级
+	TLSConfig          *tls.Config // ldaps 和 StartTLS 使用的TLS配置
+	BindDN             string      // 服务账号，为空时匿名查询
+	BindPassword       string
+	BaseDN             string
+	UserFilter         string // {username} 替换为转义后的用户名
+	UsernameAttribute  string
+	EmailAttribute     string
+	NameAttribute      string
+	GroupBaseDN        string
+	GroupFilter        string // {dn} 替换为转义后的用户DN
+	GroupNameAttribute string
+	Timeout            time.Duration
+}
+
+// Entry 目录中的用户
+type Entry struct {
+	DN       string
+	Username string
+	Email    string
+	Name     string
+	Groups   []string
+}
+
+// Client 目录服务客户端，每次操作使用独立的连接
+type Client struct {
+	cfg Config
+}
+
+// New 创建目录服务客户端，未设置的查询参数使用默认值
+func New(cfg Config) *Client {
+	if cfg.UserFilter == "" {
+		cfg.UserFilter = DefaultUserFilter
+	}
+	if cfg.UsernameAttribute == "" {
+		cfg.UsernameAttribute = "uid"
+	}
+	if cfg.EmailAttribute == "" {
+		cfg.EmailAttribute = "mail"
+	}
+	if cfg.NameAttribute == "" {
+		cfg.NameAttribute = "displayName"
+	}
+	if cfg.GroupBaseDN == "" {
+		cfg.GroupBaseDN = cfg.BaseDN
+	}
+	if cfg.GroupFilter == "" {
+		cfg.GroupFilter = DefaultGroupFilter
+	}
+	if cfg.GroupNameAttribute == "" {
+		cfg.GroupNameAttribute = "cn"
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	return &Client{cfg: cfg}
+}
+
+// Authenticate 校验用户名和密码并返回用户及其所属用户组。空密码直接拒绝，避免被当作匿名绑定
+func (c *Client) Authenticate(username, password string) (*Entry, error) {
+	if username == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+	conn, err := c.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	entries, err := c.searchUsers(conn, strings.ReplaceAll(c.cfg.UserFilter, "{username}", ldap.EscapeFilter(username)), 2)
+	if err != nil {
+		return nil, err
+	}
+	switch len(entries) {
+	case 0:
+		return nil, ErrUserNotFound
+	case 1:
+	default:
+		return nil, fmt.Errorf("ldapauth: filter matched multiple entries for %q", username)
+	}
+	user := c.entry(entries[0])
+
+	if err := conn.Bind(user.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("ldapauth: bind as user failed: %w", err)
+	}
+	// 用户账号可能没有读取用户组的权限，查询前切换回服务账号
+	if err := c.bindService(conn); err != nil {
+		return nil, err
+	}
+	groups, err := c.searchGroups(conn, strings.ReplaceAll(c.cfg.GroupFilter, "{dn}", ldap.EscapeFilter(user.DN)), false)
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range groups {
+		user.Groups = appendUnique(user.Groups, g.GetAttributeValue(c.cfg.GroupNameAttribute))
+	}
+	return user, nil
+}
+
+// Users 列出过滤条件匹配的全部用户及其所属用户组
+func (c *Client) Users() ([]*Entry, error) {
+	conn, err := c.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	entries, err := c.searchUsers(conn, strings.ReplaceAll(c.cfg.UserFilter, "{username}", "*"), 0)
+	if err != nil {
+		return nil, err
+	}
+	// 一次查出全部用户组，按成员DN建立索引，避免逐个用户查询
+	groups, err := c.searchGroups(conn, strings.ReplaceAll(c.cfg.GroupFilter, "{dn}", "*"), true)
+	if err != nil {
+		return nil, err
+	}
+	members := make(map[string][]string)
+	for _, g := range groups {
+		name := g.GetAttributeValue(c.cfg.GroupNameAttribute)
+		for _, attr := range []string{"member", "uniqueMember"} {
+			for _, dn := range g.GetAttributeValues(attr) {
+				key := normalizeDN(dn)
+				members[key] = appendUnique(members[key], name)
+			}
+		}
+	}
+
+	users := make([]*Entry, 0, len(entries))
+	for _, e := range entries {
+		user := c.entry(e)
+		for _, g := range members[normalizeDN(user.DN)] {
+			user.Groups = appendUnique(user.Groups, g)
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+// connect 建立连接，按配置升级 StartTLS 并绑定服务账号
+func (c *Client) connect() (*ldap.Conn, error) {
+	conn, err := ldap.DialURL(c.cfg.URL,
+		ldap.DialWithDialer(&net.Dialer{Timeout: c.cfg.Timeout}),
+		ldap.DialWithTLSConfig(c.cfg.TLSConfig))
+	if err != nil {
+		return nil, fmt.Errorf("ldapauth: connect failed: %w", err)
+	}
+	conn.SetTimeout(c.cfg.Timeout)
+	if c.cfg.StartTLS {
+		if err := conn.StartTLS(c.cfg.TLSConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("ldapauth: StartTLS failed: %w", err)
+		}
+	}
+	if err := c.bindService(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// bindService 绑定服务账号，未配置时使用匿名绑定
+func (c *Client) bindService(conn *ldap.Conn) error {
+	var err error
+	if c.cfg.BindDN == "" {
+		err = conn.UnauthenticatedBind("")
+	} else {
+		err = conn.Bind(c.cfg.BindDN, c.cfg.BindPassword)
+	}
+	if err != nil {
+		return fmt.Errorf("ldapauth: bind as service account failed: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) searchUsers(conn *ldap.Conn, filter string, sizeLimit int) ([]*ldap.Entry, error) {
+	req := ldap.NewSearchRequest(c.cfg.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, sizeLimit, int(c.cfg.Timeout.Seconds()), false,
+		filter, []string{c.cfg.UsernameAttribute, c.cfg.EmailAttribute, c.cfg.NameAttribute}, nil)
+	var (
+		result *ldap.SearchResult
+		err    error
+	)
+	if sizeLimit == 0 {
+		result, err = conn.SearchWithPaging(req, pageSize)
+	} else {
+		result, err = conn.Search(req)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ldapauth: search users failed: %w", err)
+	}
+	return result.Entries, nil
+}
+
+func (c *Client) searchGroups(conn *ldap.Conn, filter string, withMembers bool) ([]*ldap.Entry, error) {
+	attrs := []string{c.cfg.GroupNameAttribute}
+	if withMembers {
+		attrs = append(attrs, "member", "uniqueMember")
+	}
+	req := ldap.NewSearchRequest(c.cfg.GroupBaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, int(c.cfg.Timeout.Seconds()), false,
+		filter, attrs, nil)
+	result, err := conn.SearchWithPaging(req, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("ldapauth: search groups failed: %w", err)
+	}
+	return result.Entries, nil
+}
+
+func (c *Client) entry(e *ldap.Entry) *Entry {
+	return &Entry{
+		DN:       e.DN,
+		Username: e.GetAttributeValue(c.cfg.UsernameAttribute),
+		Email:    e.GetAttributeValue(c.cfg.EmailAttribute),
+		Name:     e.GetAttributeValue(c.cfg.NameAttribute),
+	}
+}
+
+// normalizeDN 规范化DN用于比较，无法解析时按小写比较
+func normalizeDN(dn string) string {
+	parsed, err := ldap.ParseDN(dn)
+	if err != nil {
+		return strings.ToLower(dn)
+	}
+	parts := make([]string, 0, len(parsed.RDNs))
+	for _, rdn := range parsed.RDNs {
+		attrs := make([]string, 0, len(rdn.Attributes))
+		for _, a := range rdn.Attributes {
+			attrs = append(attrs, strings.ToLower(a.Type)+"="+strings.ToLower(a.Value))
+		}
+		parts = append(parts, strings.Join(attrs, "+"))
+	}
+	return strings.Join(parts, ",")
+}
+
+func appendUnique(list []string, s string) []string {
+	if s == "" {
+		return list
+	}
+	for _, item := range list {
+		if item == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
@@ -0,0 +1,348 @@
+package ldapauth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"net"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+)
+
+const startTLSOID = "1.3.6.1.4.1.1466.20037"
+
+// testServer 进程内 LDAP 服务端，支持简单绑定、StartTLS 和常用过滤条件的查询，
+// requireTLS 时明文连接的绑定返回 confidentialityRequired
+type testServer struct {
+	ln         net.Listener
+	tlsConfig  *tls.Config
+	requireTLS bool
+	entries    map[string]map[string][]string // DN -> 属性
+	mu         sync.Mutex
+	binds      []string
+}
+
+func newTestServer(t *testing.T) (*testServer, *x509.CertPool) {
+	cert, pool := selfSignedCert(t)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &testServer{
+		ln:         ln,
+		tlsConfig:  &tls.Config{Certificates: []tls.Certificate{cert}},
+		requireTLS: true,
+		entries: map[string]map[string][]string{
+			"cn=svc,dc=example,dc=com": {"objectClass": {"person"}, "cn": {"svc"}, "userPassword": {"svc-secret"}},
+			"uid=alice,ou=people,dc=example,dc=com": {
+				"objectClass": {"person", "inetOrgPerson"}, "uid": {"alice"}, "mail": {"alice@example.com"},
+				"displayName": {"Alice"}, "userPassword": {"alice-pw"},
+			},
+			"uid=bob,ou=people,dc=example,dc=com": {
+				"objectClass": {"person"}, "uid": {"bob"}, "mail": {"bob@example.com"}, "userPassword": {"bob-pw"},
+			},
+			"cn=admins,ou=groups,dc=example,dc=com": {
+				"objectClass": {"groupOfNames"}, "cn": {"admins"}, "member": {"uid=alice,ou=people,dc=example,dc=com"},
+			},
+			"cn=devs,ou=groups,dc=example,dc=com": {
+				"objectClass": {"groupOfUniqueNames"}, "cn": {"devs"},
+				"uniqueMember": {"UID=Alice, OU=People, DC=example, DC=com", "uid=bob,ou=people,dc=example,dc=com"},
+			},
+		},
+	}
+	go s.serve()
+	t.Cleanup(func() { ln.Close() })
+	return s, pool
+}
+
+func (s *testServer) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *testServer) handle(conn net.Conn) {
+	defer func() { conn.Close() }()
+	secure := false
+	bound := ""
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil || len(packet.Children) < 2 {
+			return
+		}
+		id := packet.Children[0].Value.(int64)
+		op := packet.Children[1]
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			dn := op.Children[1].Data.String()
+			password := op.Children[2].Data.String()
+			code := uint16(ldap.LDAPResultSuccess)
+			switch {
+			case s.requireTLS && !secure:
+				code = ldap.LDAPResultConfidentialityRequired
+			case password == "":
+				// 与真实服务端一致：空密码视为匿名绑定
+				bound = ""
+			case s.entries[dn] == nil || s.entries[dn]["userPassword"][0] != password:
+				code = ldap.LDAPResultInvalidCredentials
+			default:
+				bound = dn
+				s.mu.Lock()
+				s.binds = append(s.binds, dn)
+				s.mu.Unlock()
+			}
+			s.reply(conn, id, ldap.ApplicationBindResponse, code)
+		case ldap.ApplicationUnbindRequest:
+			return
+		case ldap.ApplicationExtendedRequest:
+			if op.Children[0].Data.String() != startTLSOID || secure {
+				s.reply(conn, id, ldap.ApplicationExtendedResponse, ldap.LDAPResultProtocolError)
+				continue
+			}
+			s.reply(conn, id, ldap.ApplicationExtendedResponse, ldap.LDAPResultSuccess)
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn, secure = tlsConn, true
+		case ldap.ApplicationSearchRequest:
+			if bound == "" {
+				s.reply(conn, id, ldap.ApplicationSearchResultDone, ldap.LDAPResultInsufficientAccessRights)
+				continue
+			}
+			base := strings.ToLower(op.Children[0].Data.String())
+			sizeLimit := op.Children[3].Value.(int64)
+			var attrs []string
+			for _, a := range op.Children[7].Children {
+				attrs = append(attrs, a.Data.String())
+			}
+			var dns []string
+			for dn, entry := range s.entries {
+				if strings.HasSuffix(strings.ToLower(dn), base) && match(op.Children[6], entry) {
+					dns = append(dns, dn)
+				}
+			}
+			sort.Strings(dns)
+			if sizeLimit > 0 && int64(len(dns)) > sizeLimit {
+				dns = dns[:sizeLimit]
+			}
+			for _, dn := range dns {
+				conn.Write(message(id, entryPacket(dn, s.entries[dn], attrs)).Bytes())
+			}
+			s.reply(conn, id, ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess)
+		default:
+			return
+		}
+	}
+}
+
+func (s *testServer) reply(conn net.Conn, id int64, tag ber.Tag, code uint16) {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "")
+	op.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(code), ""))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", ""))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", ""))
+	conn.Write(message(id, op).Bytes())
+}
+
+func message(id int64, op *ber.Packet) *ber.Packet {
+	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, ""))
+	packet.AppendChild(op)
+	return packet
+}
+
+func entryPacket(dn string, entry map[string][]string, attrs []string) *ber.Packet {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "")
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, dn, ""))
+	list := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+	for _, name := range attrs {
+		values := lookup(entry, name)
+		if len(values) == 0 || strings.EqualFold(name, "userPassword") {
+			continue
+		}
+		attr := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+		attr.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, ""))
+		set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "")
+		for _, v := range values {
+			set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, v, ""))
+		}
+		attr.AppendChild(set)
+		list.AppendChild(attr)
+	}
+	op.AppendChild(list)
+	return op
+}
+
+// match 计算过滤条件，支持 and、or、not、相等和存在判断
+func match(filter *ber.Packet, entry map[string][]string) bool {
+	switch filter.Tag {
+	case ldap.FilterAnd:
+		for _, child := range filter.Children {
+			if !match(child, entry) {
+				return false
+			}
+		}
+		return true
+	case ldap.FilterOr:
+		for _, child := range filter.Children {
+			if match(child, entry) {
+				return true
+			}
+		}
+		return false
+	case ldap.FilterNot:
+		return !match(filter.Children[0], entry)
+	case ldap.FilterEqualityMatch:
+		name, want := filter.Children[0].Data.String(), filter.Children[1].Data.String()
+		// 成员属性按 distinguishedNameMatch 比较
+		isDN := strings.EqualFold(name, "member") || strings.EqualFold(name, "uniqueMember")
+		for _, v := range lookup(entry, name) {
+			if strings.EqualFold(v, want) || isDN && normalizeDN(v) == normalizeDN(want) {
+				return true
+			}
+		}
+		return false
+	case ldap.FilterPresent:
+		return len(lookup(entry, filter.Data.String())) > 0
+	default:
+		return false
+	}
+}
+
+func lookup(entry map[string][]string, name string) []string {
+	for k, v := range entry {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return nil
+}
+
+func selfSignedCert(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "ldap.test"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, _ := x509.ParseCertificate(der)
+	pool := x509.NewCertPool()
+	pool.AddCert(parsed)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, pool
+}
+
+func testClient(s *testServer, pool *x509.CertPool) *Client {
+	return New(Config{
+		URL:          "ldap://" + s.ln.Addr().String(),
+		StartTLS:     true,
+		TLSConfig:    &tls.Config{RootCAs: pool, ServerName: "127.0.0.1"},
+		BindDN:       "cn=svc,dc=example,dc=com",
+		BindPassword: "svc-secret",
+		BaseDN:       "dc=example,dc=com",
+		Timeout:      5 * time.Second,
+	})
+}
+
+func TestAuthenticate(t *testing.T) {
+	s, pool := newTestServer(t)
+	c := testClient(s, pool)
+
+	user, err := c.Authenticate("alice", "alice-pw")
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	sort.Strings(user.Groups)
+	want := &Entry{DN: "uid=alice,ou=people,dc=example,dc=com", Username: "alice", Email: "alice@example.com", Name: "Alice", Groups: []string{"admins", "devs"}}
+	if !reflect.DeepEqual(user, want) {
+		t.Fatalf("user = %+v, want %+v", user, want)
+	}
+	// 先用服务账号查找用户，校验密码后切换回服务账号查询用户组
+	s.mu.Lock()
+	binds := append([]string(nil), s.binds...)
+	s.mu.Unlock()
+	if wantBinds := []string{"cn=svc,dc=example,dc=com", want.DN, "cn=svc,dc=example,dc=com"}; !reflect.DeepEqual(binds, wantBinds) {
+		t.Errorf("binds = %v, want %v", binds, wantBinds)
+	}
+
+	cases := []struct {
+		username, password string
+		want               error
+	}{
+		{"alice", "wrong", ErrInvalidCredentials},
+		{"alice", "", ErrInvalidCredentials},
+		{"carol", "whatever", ErrUserNotFound},
+		{"*", "alice-pw", ErrUserNotFound},
+		{"alice)(uid=*", "alice-pw", ErrUserNotFound},
+	}
+	for _, tc := range cases {
+		if _, err := c.Authenticate(tc.username, tc.password); !errors.Is(err, tc.want) {
+			t.Errorf("Authenticate(%q, %q) err = %v, want %v", tc.username, tc.password, err, tc.want)
+		}
+	}
+}
+
+func TestConnectionErrors(t *testing.T) {
+	s, pool := newTestServer(t)
+
+	plain := testClient(s, pool)
+	plain.cfg.StartTLS = false
+	if _, err := plain.Authenticate("alice", "alice-pw"); err == nil || errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("plaintext bind err = %v", err)
+	}
+
+	untrusted := testClient(s, pool)
+	untrusted.cfg.TLSConfig = &tls.Config{ServerName: "127.0.0.1"}
+	if _, err := untrusted.Authenticate("alice", "alice-pw"); err == nil {
+		t.Error("untrusted certificate accepted")
+	}
+
+	badService := testClient(s, pool)
+	badService.cfg.BindPassword = "wrong"
+	if _, err := badService.Authenticate("alice", "alice-pw"); err == nil || errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("service bind err = %v", err)
+	}
+}
+
+func TestUsers(t *testing.T) {
+	s, pool := newTestServer(t)
+	users, err := testClient(s, pool).Users()
+	if err != nil {
+		t.Fatalf("Users: %v", err)
+	}
+	got := map[string][]string{}
+	for _, u := range users {
+		sort.Strings(u.Groups)
+		got[u.Username] = u.Groups
+	}
+	want := map[string][]string{"alice": {"admins", "devs"}, "bob": {"devs"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("users = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,289 @@
+// Package mailer 发送邮件：通过 SMTP 发送、写入 .eml 文件或只写入日志。
+// 邮件按 multipart/alternative 同时包含纯文本和 HTML 正文，内容由模板渲染（见 Templates）
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// SMTP 连接的加密方式
+const (
+	EncryptionStartTLS = "starttls"
+	EncryptionTLS      = "tls"
+	EncryptionNone     = "none"
+)
+
+// Message 待发送的邮件，Text 和 HTML 至少有一个
+type Message struct {
+	To      []string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Sender 邮件发送方式
+type Sender interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Build 生成 RFC 5322 格式的邮件，from 和 to 可以带显示名称，如 Domain Admin <noreply@example.com>
+func Build(from string, msg *Message, now time.Time) ([]byte, error) {
+	sender, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("mailer: invalid from address %q: %w", from, err)
+	}
+	if len(msg.To) == 0 {
+		return nil, errors.New("mailer: no recipients")
+	}
+	to := make([]string, 0, len(msg.To))
+	for _, addr := range msg.To {
+		rcpt, err := mail.ParseAddress(addr)
+		if err != nil {
+			return nil, fmt.Errorf("mailer: invalid recipient %q: %w", addr, err)
+		}
+		to = append(to, rcpt.String())
+	}
+	if msg.Text == "" && msg.HTML == "" {
+		return nil, errors.New("mailer: empty message body")
+	}
+
+	var buf bytes.Buffer
+	header := func(key, value string) {
+		buf.WriteString(key + ": " + value + "\r\n")
+	}
+	header("From", sender.String())
+	header("To", strings.Join(to, ", "))
+	// 主题中的换行会注入新的邮件头
+	subject := strings.NewReplacer("\r", "", "\n", " ").Replace(msg.Subject)
+	header("Subject", mime.QEncoding.Encode("utf-8", subject))
+	header("Date", now.Format(time.RFC1123Z))
+	header("Message-ID", "<"+messageID()+"@"+domainOf(sender.Address)+">")
+	header("MIME-Version", "1.0")
+
+	if msg.HTML == "" || msg.Text == "" {
+		contentType, body := "text/plain; charset=utf-8", msg.Text
+		if msg.Text == "" {
+			contentType, body = "text/html; charset=utf-8", msg.HTML
+		}
+		header("Content-Type", contentType)
+		header("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		if err := writeQuotedPrintable(&buf, body); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	parts := multipart.NewWriter(&buf)
+	header("Content-Type", "multipart/alternative; boundary="+parts.Boundary())
+	buf.WriteString("\r\n")
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeQuotedPrintable(w, part.body); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeQuotedPrintable(w interface{ Write([]byte) (int, error) }, body string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(strings.ReplaceAll(body, "\n", "\r\n"))); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+func messageID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b)
+}
+
+func domainOf(address string) string {
+	if i := strings.LastIndex(address, "@"); i >= 0 {
+		return address[i+1:]
+	}
+	return "localhost"
+}
+
+// SMTPConfig SMTP 服务器参数
+type SMTPConfig struct {
+	Host       string
+	Port       int    // 为0时 starttls 和 none 使用587，tls 使用465
+	Username   string // 为空时不认证
+	Password   string
+	From       string
+	Encryption string      // starttls（默认）、tls 或 none
+	TLSConfig  *tls.Config // 为空时按 Host 校验服务器证书
+	Timeout    time.Duration
+}
+
+type smtpSender struct {
+	cfg SMTPConfig
+}
+
+// NewSMTPSender 创建通过 SMTP 发送邮件的 Sender，每封邮件使用独立的连接
+func NewSMTPSender(cfg SMTPConfig) Sender {
+	if cfg.Encryption == "" {
+		cfg.Encryption = EncryptionStartTLS
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 587
+		if cfg.Encryption == EncryptionTLS {
+			cfg.Port = 465
+		}
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	return &smtpSender{cfg: cfg}
+}
+
+func (s *smtpSender) Send(ctx context.Context, msg *Message) error {
+	data, err := Build(s.cfg.From, msg, time.Now())
+	if err != nil {
+		return err
+	}
+	from, _ := mail.ParseAddress(s.cfg.From)
+
+	tlsConfig := s.cfg.TLSConfig
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{ServerName: s.cfg.Host}
+	}
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	dialer := &net.Dialer{Timeout: s.cfg.Timeout}
+	var conn net.Conn
+	switch s.cfg.Encryption {
+	case EncryptionTLS:
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	case EncryptionStartTLS, EncryptionNone:
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	default:
+		return fmt.Errorf("mailer: unsupported encryption %q", s.cfg.Encryption)
+	}
+	if err != nil {
+		return fmt.Errorf("mailer: connect %s: %w", addr, err)
+	}
+	deadline := time.Now().Add(s.cfg.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	_ = conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("mailer: %w", err)
+	}
+	defer client.Close()
+
+	if s.cfg.Encryption == EncryptionStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return errors.New("mailer: server does not support STARTTLS")
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("mailer: starttls: %w", err)
+		}
+	}
+	if s.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)); err != nil {
+			return fmt.Errorf("mailer: auth: %w", err)
+		}
+	}
+	if err := client.Mail(from.Address); err != nil {
+		return fmt.Errorf("mailer: mail from: %w", err)
+	}
+	for _, addr := range msg.To {
+		rcpt, _ := mail.ParseAddress(addr)
+		if err := client.Rcpt(rcpt.Address); err != nil {
+			return fmt.Errorf("mailer: rcpt %s: %w", rcpt.Address, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("mailer: data: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("mailer: data: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("mailer: data: %w", err)
+	}
+	return client.Quit()
+}
+
+type fileSender struct {
+	dir  string
+	from string
+	seq  atomic.Uint64
+}
+
+// NewFileSender 创建把邮件写入 dir 目录下 .eml 文件的 Sender，用于测试和开发环境
+func NewFileSender(dir, from string) Sender {
+	return &fileSender{dir: dir, from: from}
+}
+
+func (s *fileSender) Send(_ context.Context, msg *Message) error {
+	now := time.Now()
+	data, err := Build(s.from, msg, now)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return fmt.Errorf("mailer: %w", err)
+	}
+	name := fmt.Sprintf("%s-%d.eml", now.Format("20060102T150405.000000000"), s.seq.Add(1))
+	return os.WriteFile(filepath.Join(s.dir, name), data, 0o600)
+}
+
+type logSender struct {
+	logf func(format string, args ...interface{})
+}
+
+// NewLogSender 创建只把收件人、主题和纯文本正文写入日志的 Sender，正文中的链接含有一次性令牌，仅用于开发环境
+func NewLogSender(logf func(format string, args ...interface{})) Sender {
+	return &logSender{logf: logf}
+}
+
+func (s *logSender) Send(_ context.Context, msg *Message) error {
+	body := msg.Text
+	if body == "" {
+		body = msg.HTML
+	}
+	s.logf("邮件未发送（log 方式）收件人: %s 主题: %s\n%s", strings.Join(msg.To, ", "), msg.Subject, body)
+	return nil
+}
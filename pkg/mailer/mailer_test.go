@@ -0,0 +1,334 @@
+package mailer
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"io"
+	"math/big"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// testServer 进程内 SMTP 服务端，支持 AUTH PLAIN，设置 tlsConfig 时支持 STARTTLS
+type testServer struct {
+	ln        net.Listener
+	tlsConfig *tls.Config
+	mu        sync.Mutex
+	auth      string
+	from      string
+	rcpt      []string
+	data      string
+}
+
+func newTestServer(t *testing.T, tlsConfig *tls.Config) *testServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &testServer{ln: ln, tlsConfig: tlsConfig}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *testServer) port() int {
+	return s.ln.Addr().(*net.TCPAddr).Port
+}
+
+func (s *testServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+	reply("220 test ESMTP")
+	secure := false
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch cmd {
+		case "EHLO":
+			reply("250-test")
+			if s.tlsConfig != nil && !secure {
+				reply("250-STARTTLS")
+			}
+			reply("250 AUTH PLAIN")
+		case "STARTTLS":
+			reply("220 ready")
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn, r, secure = tlsConn, bufio.NewReader(tlsConn), true
+		case "AUTH":
+			decoded, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(line, "AUTH PLAIN "))
+			s.mu.Lock()
+			s.auth = string(decoded)
+			s.mu.Unlock()
+			reply("235 ok")
+		case "MAIL":
+			s.mu.Lock()
+			s.from = line
+			s.mu.Unlock()
+			reply("250 ok")
+		case "RCPT":
+			s.mu.Lock()
+			s.rcpt = append(s.rcpt, line)
+			s.mu.Unlock()
+			reply("250 ok")
+		case "DATA":
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(l, "."))
+			}
+			s.mu.Lock()
+			s.data = data.String()
+			s.mu.Unlock()
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 unknown")
+		}
+	}
+}
+
+func selfSigned(t *testing.T) (*tls.Config, *x509.CertPool) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}, pool
+}
+
+func testMessage() *Message {
+	return &Message{
+		To:      []string{"Alice <alice@example.com>"},
+		Subject: "重置密码\r\nBcc: evil@example.com",
+		Text:    "打开链接：\nhttps://admin.example.com/reset-password?token=abc",
+		HTML:    `<p><a href="https://admin.example.com/reset-password?token=abc">重置</a></p>`,
+	}
+}
+
+// parse 解析邮件并返回主题、纯文本和 HTML 正文
+func parse(t *testing.T, raw string) (*mail.Message, string, string) {
+	t.Helper()
+	msg, err := mail.ReadMessage(strings.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var text, html string
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := parts.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(part)
+		if strings.HasPrefix(part.Header.Get("Content-Type"), "text/html") {
+			html = string(body)
+		} else {
+			text = string(body)
+		}
+	}
+	return msg, text, html
+}
+
+func TestBuild(t *testing.T) {
+	raw, err := Build("Domain Admin <noreply@example.com>", testMessage(), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, text, html := parse(t, string(raw))
+	if msg.Header.Get("Bcc") != "" {
+		t.Fatal("subject newline injected a header")
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if subject != "重置密码 Bcc: evil@example.com" {
+		t.Fatalf("subject = %q", subject)
+	}
+	if to := msg.Header.Get("To"); to != `"Alice" <alice@example.com>` {
+		t.Fatalf("to = %q", to)
+	}
+	if !strings.HasSuffix(msg.Header.Get("Message-ID"), "@example.com>") {
+		t.Fatalf("message id = %q", msg.Header.Get("Message-ID"))
+	}
+	if text != "打开链接：\r\nhttps://admin.example.com/reset-password?token=abc" || !strings.Contains(html, "token=abc") {
+		t.Fatalf("text = %q, html = %q", text, html)
+	}
+
+	for _, bad := range []*Message{
+		{To: nil, Text: "x"},
+		{To: []string{"not an address"}, Text: "x"},
+		{To: []string{"a@example.com"}},
+	} {
+		if _, err := Build("noreply@example.com", bad, time.Now()); err == nil {
+			t.Errorf("Build(%+v) should fail", bad)
+		}
+	}
+	if _, err := Build("", testMessage(), time.Now()); err == nil {
+		t.Error("Build with empty from should fail")
+	}
+}
+
+func TestSMTPSender(t *testing.T) {
+	srv := newTestServer(t, nil)
+	sender := NewSMTPSender(SMTPConfig{
+		Host: "127.0.0.1", Port: srv.port(), Username: "mailer", Password: "secret",
+		From: "Domain Admin <noreply@example.com>", Encryption: EncryptionNone,
+	})
+	if err := sender.Send(context.Background(), testMessage()); err != nil {
+		t.Fatal(err)
+	}
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if srv.auth != "\x00mailer\x00secret" || srv.from != "MAIL FROM:<noreply@example.com>" || len(srv.rcpt) != 1 || srv.rcpt[0] != "RCPT TO:<alice@example.com>" {
+		t.Fatalf("auth = %q, from = %q, rcpt = %q", srv.auth, srv.from, srv.rcpt)
+	}
+	if _, text, _ := parse(t, srv.data); !strings.Contains(text, "token=abc") {
+		t.Fatalf("text = %q", text)
+	}
+}
+
+func TestSMTPSenderStartTLS(t *testing.T) {
+	serverTLS, pool := selfSigned(t)
+	srv := newTestServer(t, serverTLS)
+	cfg := SMTPConfig{
+		Host: "127.0.0.1", Port: srv.port(), From: "noreply@example.com",
+		TLSConfig: &tls.Config{RootCAs: pool, ServerName: "127.0.0.1"},
+	}
+	if err := NewSMTPSender(cfg).Send(context.Background(), testMessage()); err != nil {
+		t.Fatal(err)
+	}
+	srv.mu.Lock()
+	sent := srv.data != ""
+	srv.mu.Unlock()
+	if !sent {
+		t.Fatal("message not delivered over STARTTLS")
+	}
+
+	// 服务器证书不受信任时不能降级为明文
+	cfg.TLSConfig = nil
+	if err := NewSMTPSender(cfg).Send(context.Background(), testMessage()); err == nil {
+		t.Fatal("untrusted certificate should fail")
+	}
+	// 服务器不支持 STARTTLS 时拒绝发送
+	plain := newTestServer(t, nil)
+	cfg.Port = plain.port()
+	if err := NewSMTPSender(cfg).Send(context.Background(), testMessage()); err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Fatalf("err = %v, want STARTTLS unsupported", err)
+	}
+}
+
+func TestFileSender(t *testing.T) {
+	dir := t.TempDir()
+	sender := NewFileSender(dir, "noreply@example.com")
+	for i := 0; i < 2; i++ {
+		if err := sender.Send(context.Background(), testMessage()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if len(files) != 2 {
+		t.Fatalf("files = %v", files)
+	}
+	raw, _ := os.ReadFile(files[0])
+	if _, text, _ := parse(t, string(raw)); !strings.Contains(text, "token=abc") {
+		t.Fatalf("text = %q", text)
+	}
+}
+
+func TestTemplates(t *testing.T) {
+	data := map[string]string{"AppName": "Domain Admin", "Username": "<alice>", "Link": "https://admin.example.com/verify-email?token=a&b", "ExpiresIn": "24小时"}
+	msg, err := DefaultTemplates().Render("verify_email", data, "alice@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Subject != "Domain Admin 邮箱验证" || msg.To[0] != "alice@example.com" {
+		t.Fatalf("msg = %+v", msg)
+	}
+	if !strings.HasPrefix(msg.Text, "<alice>，您好") || !strings.Contains(msg.Text, "token=a&b") {
+		t.Fatalf("text = %q", msg.Text)
+	}
+	if !strings.Contains(msg.HTML, "&lt;alice&gt;") || !strings.Contains(msg.HTML, "token=a&amp;b") {
+		t.Fatalf("html = %q", msg.HTML)
+	}
+	if _, err := DefaultTemplates().Render("missing", data); err == nil {
+		t.Fatal("missing template should fail")
+	}
+
+	custom, err := ParseTemplates(fstest.MapFS{
+		"verify_email.tmpl": {Data: []byte(`{{define "verify_email.subject"}}请验证邮箱{{end}}{{define "verify_email.text"}}{{.Link}}{{end}}`)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err = custom.Render("verify_email", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Subject != "请验证邮箱" || msg.Text != "https://admin.example.com/verify-email?token=a&b\n" {
+		t.Fatalf("msg = %+v", msg)
+	}
+	if _, err := custom.Render("reset_password", data); err != nil {
+		t.Fatalf("builtin template should remain available: %v", err)
+	}
+}
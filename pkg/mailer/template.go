@@ -0,0 +1,89 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"strings"
+	texttemplate "text/template"
+)
+
+//go:embed templates/*.tmpl
+var builtinTemplates embed.FS
+
+// Templates 邮件模板。每种邮件在 .tmpl 文件中定义 <name>.subject、<name>.text 和 <name>.html 三个模板，
+// subject 和 text 按纯文本渲染，html 按 html/template 转义；html 可以省略
+type Templates struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+// DefaultTemplates 返回内置模板
+func DefaultTemplates() *Templates {
+	t, err := ParseTemplates(nil)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// ParseTemplates 解析内置模板和 fsys 中的全部 .tmpl 文件，fsys 中的模板覆盖同名的内置模板；fsys 为 nil 时只使用内置模板
+func ParseTemplates(fsys fs.FS) (*Templates, error) {
+	text, err := texttemplate.ParseFS(builtinTemplates, "templates/*.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("mailer: parse templates: %w", err)
+	}
+	html, err := htmltemplate.ParseFS(builtinTemplates, "templates/*.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("mailer: parse templates: %w", err)
+	}
+	if fsys != nil {
+		matches, err := fs.Glob(fsys, "*.tmpl")
+		if err != nil {
+			return nil, fmt.Errorf("mailer: %w", err)
+		}
+		if len(matches) > 0 {
+			if text, err = text.ParseFS(fsys, "*.tmpl"); err != nil {
+				return nil, fmt.Errorf("mailer: parse templates: %w", err)
+			}
+			if html, err = html.ParseFS(fsys, "*.tmpl"); err != nil {
+				return nil, fmt.Errorf("mailer: parse templates: %w", err)
+			}
+		}
+	}
+	return &Templates{text: text, html: html}, nil
+}
+
+// Render 渲染名为 name 的邮件
+func (t *Templates) Render(name string, data interface{}, to ...string) (*Message, error) {
+	subject, err := t.execText(name+".subject", data)
+	if err != nil {
+		return nil, err
+	}
+	text, err := t.execText(name+".text", data)
+	if err != nil {
+		return nil, err
+	}
+	msg := &Message{To: to, Subject: strings.TrimSpace(subject), Text: strings.TrimSpace(text) + "\n"}
+	if t.html.Lookup(name+".html") != nil {
+		var buf bytes.Buffer
+		if err := t.html.ExecuteTemplate(&buf, name+".html", data); err != nil {
+			return nil, fmt.Errorf("mailer: render %s.html: %w", name, err)
+		}
+		msg.HTML = buf.String()
+	}
+	return msg, nil
+}
+
+func (t *Templates) execText(name string, data interface{}) (string, error) {
+	if t.text.Lookup(name) == nil {
+		return "", fmt.Errorf("mailer: template %s not found", name)
+	}
+	var buf bytes.Buffer
+	if err := t.text.ExecuteTemplate(&buf, name, data); err != nil {
+		return "", fmt.Errorf("mailer: render %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,174 @@
+package middleware
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 权限策略的属性条件，对应模型中的 p.cond
+const (
+	// ConditionAny 不限制目标对象
+	ConditionAny = "*"
+	// ConditionSelf 只能操作属于自己的对象
+	ConditionSelf = "self"
+	// ConditionLowerRole 只能操作角色等级低于自己的对象
+	ConditionLowerRole = "lower_role"
+)
+
+// objectContextKey 目标对象属性在 gin.Context 中的键
+const objectContextKey = "rbac_object"
+
+// ObjectAttributes 请求操作的目标对象属性，由处理器提供
+type ObjectAttributes struct {
+	// OwnerID 对象所属用户ID，用户资源即用户自身的ID
+	OwnerID uint
+	// Role 对象关联的角色名称，用户资源即用户的角色
+	Role string
+}
+
+// Attributes 鉴权时传入模型的属性（r.attr）
+type Attributes struct {
+	SubjectID    uint
+	SubjectLevel int
+	// Object 为空表示请求没有目标对象或对象不存在，此时带条件的策略不生效
+	Object      *ObjectAttributes
+	ObjectLevel int
+}
+
+// ObjectResolver 根据请求加载目标对象属性，对象不存在时返回 nil
+type ObjectResolver func(c *gin.Context) (*ObjectAttributes, error)
+
+var (
+	rbacDB          *gorm.DB
+	objectResolvers = make(map[string]ObjectResolver)
+	resolverMux     sync.RWMutex
+)
+
+// ValidCondition 检查属性条件是否受支持，空字符串等同于 ConditionAny
+func ValidCondition(cond string) bool {
+	switch cond {
+	case "", ConditionAny, ConditionSelf, ConditionLowerRole:
+		return true
+	}
+	return false
+}
+
+// RegisterObjectResolver 为路由（gin 的完整路由，如 /api/users/:id）注册目标对象加载函数
+// RBACMiddleware 在鉴权前调用该函数，使带属性条件的策略可以生效
+func RegisterObjectResolver(route string, resolver ObjectResolver) {
+	resolverMux.Lock()
+	defer resolverMux.Unlock()
+	objectResolvers[route] = resolver
+}
+
+// GetObject 返回 RBACMiddleware 加载的目标对象属性
+func GetObject(c *gin.Context) *ObjectAttributes {
+	if v, ok := c.Get(objectContextKey); ok {
+		if obj, ok := v.(*ObjectAttributes); ok {
+			return obj
+		}
+	}
+	return nil
+}
+
+// Authorize 使用当前请求的角色、路径和方法对指定目标对象重新鉴权
+// 用于处理器在中间件之外校验请求体中的属性，如将用户修改为某个角色
+func Authorize(c *gin.Context, obj *ObjectAttributes) (bool, error) {
+	if !initialized || Enforcer == nil {
+		return false, fmt.Errorf("RBAC system not initialized")
+	}
+	role, _ := c.Get("role")
+	roleName, _ := role.(string)
+
+	attrs, err := buildAttributes(c, roleName, obj)
+	if err != nil {
+		return false, err
+	}
+
+	if !scopeAllows(c, c.Request.URL.Path, c.Request.Method, attrs) {
+		return false, nil
+	}
+
+	enforcerMux.RLock()
+	defer enforcerMux.RUnlock()
+	return Enforcer.Enforce(roleName, c.Request.URL.Path, c.Request.Method, attrs)
+}
+
+// resolveObject 调用路由注册的加载函数，未注册时返回 nil
+func resolveObject(c *gin.Context) (*ObjectAttributes, error) {
+	resolverMux.RLock()
+	resolver := objectResolvers[c.FullPath()]
+	resolverMux.RUnlock()
+	if resolver == nil {
+		return nil, nil
+	}
+	return resolver(c)
+}
+
+// buildAttributes 组装鉴权属性，角色等级从数据库读取
+func buildAttributes(c *gin.Context, role string, obj *ObjectAttributes) (*Attributes, error) {
+	attrs := &Attributes{Object: obj}
+	if id, ok := c.Get("userID"); ok {
+		attrs.SubjectID, _ = id.(uint)
+	}
+	if obj == nil {
+		return attrs, nil
+	}
+
+	levels, err := roleLevels(role, obj.Role)
+	if err != nil {
+		return nil, err
+	}
+	attrs.SubjectLevel = levels[role]
+	attrs.ObjectLevel = levels[obj.Role]
+	return attrs, nil
+}
+
+// roleLevels 查询角色等级，不存在的角色等级为0
+func roleLevels(names ...string) (map[string]int, error) {
+	levels := make(map[string]int, len(names))
+	if rbacDB == nil {
+		return levels, nil
+	}
+	var rows []struct {
+		Name  string
+		Level int
+	}
+	err := rbacDB.Table("domain_role").Where("name IN ? AND deleted_at IS NULL", names).
+		Select("name, level").Find(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("查询角色等级失败: %w", err)
+	}
+	for _, row := range rows {
+		levels[row.Name] = row.Level
+	}
+	return levels, nil
+}
+
+// attrMatch 模型中的 attrMatch(p.cond, r.attr) 函数
+// 未知条件一律拒绝；带条件的策略在没有目标对象时不生效
+func attrMatch(args ...interface{}) (interface{}, error) {
+	if len(args) != 2 {
+		return false, fmt.Errorf("attrMatch: expected 2 arguments, got %d", len(args))
+	}
+	cond, _ := args[0].(string)
+	if cond == ConditionAny {
+		return true, nil
+	}
+	attrs, ok := args[1].(*Attributes)
+	if !ok || attrs == nil || attrs.Object == nil {
+		return false, nil
+	}
+
+	switch cond {
+	case ConditionSelf:
+		return attrs.SubjectID != 0 && attrs.Object.OwnerID == attrs.SubjectID, nil
+	case ConditionLowerRole:
+		return attrs.ObjectLevel < attrs.SubjectLevel, nil
+	default:
+		return false, nil
+	}
+}
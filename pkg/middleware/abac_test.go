@@ -0,0 +1,230 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"domain-admin/model"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+// testUsers 测试路由中的用户，ID 即路径参数
+var testUsers = map[uint]*ObjectAttributes{
+	1: {OwnerID: 1, Role: "admin"},
+	2: {OwnerID: 2, Role: "manager"},
+	3: {OwnerID: 3, Role: "user"},
+	4: {OwnerID: 4, Role: "user"},
+}
+
+// newTestEnforcer 使用项目的模型文件初始化 Casbin，角色等级保存在临时数据库中
+func newTestEnforcer(t *testing.T, policies ...[]string) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger:         gormlogger.Discard,
+		NamingStrategy: schema.NamingStrategy{TablePrefix: "domain_", SingularTable: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&model.Role{}); err != nil {
+		t.Fatal(err)
+	}
+	for name, level := range map[string]int{"admin": 100, "manager": 50, "user": 10} {
+		if err := db.Create(&model.Role{Name: name, DisplayName: name, Level: level, Status: 1}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := InitRBAC(db, "../../configs/rbac_model.conf"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		Enforcer, rbacDB, initialized = nil, nil, false
+	})
+	for _, p := range policies {
+		if _, err := Enforcer.AddPolicy(p[0], p[1], p[2], p[3]); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// registerTestResolver 在测试期间为路由注册目标对象加载函数
+func registerTestResolver(t *testing.T, route string, resolver ObjectResolver) {
+	t.Helper()
+	RegisterObjectResolver(route, resolver)
+	t.Cleanup(func() {
+		resolverMux.Lock()
+		delete(objectResolvers, route)
+		resolverMux.Unlock()
+	})
+}
+
+func resolveTestUser(c *gin.Context) (*ObjectAttributes, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return nil, nil
+	}
+	return testUsers[uint(id)], nil
+}
+
+// newUserRouter 模拟用户管理路由，X-User 请求头指定当前登录的用户
+func newUserRouter() *gin.Engine {
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		id, _ := strconv.ParseUint(c.GetHeader("X-User"), 10, 32)
+		c.Set("userID", uint(id))
+		c.Set("role", testUsers[uint(id)].Role)
+	}, RBACMiddleware())
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	r.GET("/api/users/:id", ok)
+	r.PUT("/api/users/:id", ok)
+	r.DELETE("/api/users/:id", ok)
+	r.PUT("/api/users/:id/status", ok)
+	r.DELETE("/api/users/:id/2fa", func(c *gin.Context) {
+		// 与处理器相同，以目标用户的角色重新鉴权，不带所属用户
+		allowed, err := Authorize(c, &ObjectAttributes{Role: GetObject(c).Role})
+		if err != nil || !allowed {
+			c.Status(http.StatusForbidden)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+	return r
+}
+
+func serveAs(r *gin.Engine, user uint, method, path string) int {
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("X-User", strconv.Itoa(int(user)))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestAttrMatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		cond  string
+		attrs *Attributes
+		want  bool
+	}{
+		{"any without object", ConditionAny, &Attributes{}, true},
+		{"self on own object", ConditionSelf, &Attributes{SubjectID: 3, Object: &ObjectAttributes{OwnerID: 3}}, true},
+		{"self on other object", ConditionSelf, &Attributes{SubjectID: 3, Object: &ObjectAttributes{OwnerID: 4}}, false},
+		{"self without subject", ConditionSelf, &Attributes{Object: &ObjectAttributes{}}, false},
+		{"self without object", ConditionSelf, &Attributes{SubjectID: 3}, false},
+		{"lower role", ConditionLowerRole, &Attributes{SubjectLevel: 50, ObjectLevel: 10, Object: &ObjectAttributes{}}, true},
+		{"same role level", ConditionLowerRole, &Attributes{SubjectLevel: 50, ObjectLevel: 50, Object: &ObjectAttributes{}}, false},
+		{"higher role", ConditionLowerRole, &Attributes{SubjectLevel: 50, ObjectLevel: 100, Object: &ObjectAttributes{}}, false},
+		{"lower role without object", ConditionLowerRole, &Attributes{SubjectLevel: 50}, false},
+		{"unknown condition", "owner", &Attributes{SubjectID: 3, Object: &ObjectAttributes{OwnerID: 3}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := attrMatch(tt.cond, tt.attrs)
+			if err != nil || got != tt.want {
+				t.Fatalf("attrMatch(%q) = %v, %v; want %v", tt.cond, got, err, tt.want)
+			}
+		})
+	}
+
+	if _, err := attrMatch(ConditionSelf); err == nil {
+		t.Fatal("attrMatch accepted a single argument")
+	}
+}
+
+func TestUserRouteConditions(t *testing.T) {
+	newTestEnforcer(t,
+		[]string{"manager", "/api/users/:id", "GET", ConditionLowerRole},
+		[]string{"manager", "/api/users/:id", "DELETE", ConditionLowerRole},
+		[]string{"manager", "/api/users/:id/status", "PUT", ConditionLowerRole},
+		[]string{"manager", "/api/users/:id", "PUT", ConditionSelf},
+		[]string{"user", "/api/users/:id", "GET", ConditionSelf},
+		[]string{"user", "/api/users/:id", "PUT", ConditionSelf},
+		[]string{"user", "/api/users/:id/2fa", "DELETE", ConditionSelf},
+	)
+	registerTestResolver(t, "/api/users/:id", resolveTestUser)
+	registerTestResolver(t, "/api/users/:id/status", resolveTestUser)
+	registerTestResolver(t, "/api/users/:id/2fa", resolveTestUser)
+	r := newUserRouter()
+
+	tests := []struct {
+		name   string
+		user   uint
+		method string
+		path   string
+		want   int
+	}{
+		{"manager views lower user", 2, "GET", "/api/users/3", http.StatusOK},
+		{"manager views admin", 2, "GET", "/api/users/1", http.StatusForbidden},
+		{"manager deletes lower user", 2, "DELETE", "/api/users/3", http.StatusOK},
+		{"manager deletes admin", 2, "DELETE", "/api/users/1", http.StatusForbidden},
+		{"manager disables lower user", 2, "PUT", "/api/users/3/status", http.StatusOK},
+		{"manager updates self", 2, "PUT", "/api/users/2", http.StatusOK},
+		{"manager updates lower user", 2, "PUT", "/api/users/3", http.StatusForbidden},
+		{"delete permission does not cover 2fa", 2, "DELETE", "/api/users/3/2fa", http.StatusForbidden},
+		{"missing user", 2, "GET", "/api/users/99", http.StatusForbidden},
+		{"user views self", 3, "GET", "/api/users/3", http.StatusOK},
+		{"user views other user", 3, "GET", "/api/users/4", http.StatusForbidden},
+		{"user updates self", 3, "PUT", "/api/users/3", http.StatusOK},
+		{"update permission does not cover status", 3, "PUT", "/api/users/3/status", http.StatusForbidden},
+		{"self condition cannot reset own 2fa", 3, "DELETE", "/api/users/3/2fa", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := serveAs(r, tt.user, tt.method, tt.path); code != tt.want {
+				t.Fatalf("%s %s as %d = %d, want %d", tt.method, tt.path, tt.user, code, tt.want)
+			}
+		})
+	}
+}
+
+func TestObjectResolver(t *testing.T) {
+	newTestEnforcer(t,
+		[]string{"manager", "/api/users/:id", "GET", ConditionLowerRole},
+		[]string{"manager", "/api/users/:id/sessions", "GET", ConditionLowerRole},
+	)
+	var resolved *ObjectAttributes
+	registerTestResolver(t, "/api/users/:id", func(c *gin.Context) (*ObjectAttributes, error) {
+		if c.Param("id") == "500" {
+			return nil, errors.New("database unavailable")
+		}
+		return resolveTestUser(c)
+	})
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("userID", uint(2))
+		c.Set("role", "manager")
+	}, RBACMiddleware())
+	r.GET("/api/users/:id", func(c *gin.Context) {
+		resolved = GetObject(c)
+		c.Status(http.StatusOK)
+	})
+
+	// 处理器可以读取中间件加载的目标对象
+	if code := serveAs(r, 2, "GET", "/api/users/3"); code != http.StatusOK {
+		t.Fatalf("resolved user = %d", code)
+	}
+	if resolved == nil || resolved.OwnerID != 3 || resolved.Role != "user" {
+		t.Fatalf("GetObject = %+v", resolved)
+	}
+
+	if code := serveAs(r, 2, "GET", "/api/users/500"); code != http.StatusInternalServerError {
+		t.Fatalf("resolver error = %d", code)
+	}
+
+	// 未注册加载函数的路由没有目标对象，带条件的策略不生效
+	r.GET("/api/users/:id/sessions", func(c *gin.Context) { c.Status(http.StatusOK) })
+	if code := serveAs(r, 2, "GET", "/api/users/3/sessions"); code != http.StatusForbidden {
+		t.Fatalf("route without resolver = %d", code)
+	}
+}
@@ -0,0 +1,194 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"domain-admin/pkg/config"
+	"domain-admin/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PendingChange 被拦截等待审批的请求
+type PendingChange struct {
+	Method string
+	// Route 为 gin 的完整路由，如 /api/roles/:id/permissions
+	Route string
+	Path  string
+	Query string
+	Body  []byte
+	// Before、After 为变更前后的数据，由路由注册的 ChangeDiffer 提供
+	Before interface{}
+	After  interface{}
+	// Reason 为提交人通过 X-Change-Reason 请求头填写的变更原因
+	Reason string
+	// TokenID 为提交时使用的API令牌ID，使用登录会话提交时为0
+	TokenID uint
+}
+
+// Identity 执行已批准变更时使用的提交人身份
+type Identity struct {
+	UserID   uint
+	Username string
+	Role     string
+	// TokenID 不为0时重新加载该API令牌，执行仍受令牌的权限范围约束
+	TokenID uint
+}
+
+// ChangeSubmitter 保存待审批的变更并写入响应
+type ChangeSubmitter func(c *gin.Context, change *PendingChange)
+
+// ChangeDiffer 根据请求计算变更前后的数据，用于审批人查看
+type ChangeDiffer func(c *gin.Context, body []byte) (before, after interface{}, err error)
+
+// approvedChangeKey 已批准变更的身份在请求上下文中的键
+type approvedChangeKey struct{}
+
+var (
+	approvalRoutes  = make(map[string]bool)
+	approvalEngine  http.Handler
+	changeSubmitter ChangeSubmitter
+	changeDiffers   = make(map[string]ChangeDiffer)
+	approvalMux     sync.RWMutex
+)
+
+// InitChangeApproval 设置需要审批的路由、保存变更的函数以及重放已批准变更的路由引擎
+func InitChangeApproval(routes []config.ApprovalRouteConfig, engine http.Handler, submit ChangeSubmitter) {
+	approvalMux.Lock()
+	defer approvalMux.Unlock()
+
+	approvalRoutes = make(map[string]bool, len(routes))
+	for _, route := range routes {
+		method := strings.ToUpper(strings.TrimSpace(route.Method))
+		if method == "" {
+			method = "*"
+		}
+		approvalRoutes[method+" "+strings.TrimSpace(route.Path)] = true
+	}
+	approvalEngine = engine
+	changeSubmitter = submit
+	if len(routes) > 0 {
+		logger.Infof("已启用变更审批，需要审批的路由数: %d", len(routes))
+	}
+}
+
+// RegisterChangeDiffer 为路由注册变更前后数据的计算函数，未注册时只记录请求内容
+func RegisterChangeDiffer(method, route string, differ ChangeDiffer) {
+	approvalMux.Lock()
+	defer approvalMux.Unlock()
+	changeDiffers[strings.ToUpper(method)+" "+route] = differ
+}
+
+// RequiresApproval 判断请求方法和路由是否需要审批
+func RequiresApproval(method, route string) bool {
+	approvalMux.RLock()
+	defer approvalMux.RUnlock()
+	return approvalRoutes[strings.ToUpper(method)+" "+route] || approvalRoutes["* "+route]
+}
+
+// ChangeApproval Gin中间件：将配置为需要审批的请求保存为变更请求，审批通过后再执行
+// 需放在 JWTAuth 和 RBACMiddleware 之后，确保提交人本身有权执行该操作
+func ChangeApproval() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := approvedIdentity(c.Request.Context()); ok {
+			c.Next()
+			return
+		}
+		if !RequiresApproval(c.Request.Method, c.FullPath()) {
+			c.Next()
+			return
+		}
+
+		approvalMux.RLock()
+		submit := changeSubmitter
+		differ := changeDiffers[c.Request.Method+" "+c.FullPath()]
+		approvalMux.RUnlock()
+		if submit == nil {
+			logger.Error("Change approval not initialized")
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"code":    500,
+				"message": "变更审批未初始化",
+			})
+			return
+		}
+
+		var body []byte
+		if c.Request.Body != nil {
+			var err error
+			if body, err = io.ReadAll(c.Request.Body); err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+					"code":    400,
+					"message": "读取请求内容失败",
+				})
+				return
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		change := &PendingChange{
+			Method: c.Request.Method,
+			Route:  c.FullPath(),
+			Path:   c.Request.URL.Path,
+			Query:  c.Request.URL.RawQuery,
+			Body:   body,
+			Reason: c.GetHeader("X-Change-Reason"),
+		}
+		if token, ok := GetTokenIdentity(c); ok {
+			change.TokenID = token.TokenID
+		}
+		if differ != nil {
+			before, after, err := differ(c, body)
+			if err != nil {
+				logger.Warnf("计算变更内容失败: %v, path: %s", err, change.Path)
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+					"code":    400,
+					"message": err.Error(),
+				})
+				return
+			}
+			change.Before, change.After = before, after
+		}
+
+		submit(c, change)
+		c.Abort()
+	}
+}
+
+// ReplayChange 以提交人身份重新执行已批准的变更，返回处理器的响应状态码和内容
+// 重放的请求仍经过 RBACMiddleware，提交人在审批期间失去权限或使用的API令牌失效、超出权限范围时执行失败
+func ReplayChange(ctx context.Context, identity Identity, method, path, query string, body []byte) (int, []byte, error) {
+	approvalMux.RLock()
+	engine := approvalEngine
+	approvalMux.RUnlock()
+	if engine == nil {
+		return 0, nil, errors.New("change approval not initialized")
+	}
+
+	target := path
+	if query != "" {
+		target += "?" + query
+	}
+	req, err := http.NewRequestWithContext(context.WithValue(ctx, approvedChangeKey{}, identity), method, target, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "127.0.0.1:0"
+
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+	return rec.Code, rec.Body.Bytes(), nil
+}
+
+// approvedIdentity 返回重放已批准变更时的提交人身份，该值只能由 ReplayChange 设置
+func approvedIdentity(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(approvedChangeKey{}).(Identity)
+	return identity, ok
+}
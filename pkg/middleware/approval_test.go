@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"domain-admin/pkg/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newApprovalRouter 更新域名需要审批，提交的变更保存到 submitted
+func newApprovalRouter(t *testing.T, submitted *[]*PendingChange) *gin.Engine {
+	t.Helper()
+	withTokenAuthenticator(t, TokenScope{Resource: "/api/domains/:id", Action: "*"})
+	r := gin.New()
+	handler := func(c *gin.Context) { c.String(http.StatusOK, c.GetString("username")) }
+	r.PUT("/api/domains/:id", JWTAuth(), RequireTokenScope(), ChangeApproval(), handler)
+	r.GET("/api/domains/:id", JWTAuth(), RequireTokenScope(), ChangeApproval(), handler)
+
+	InitChangeApproval([]config.ApprovalRouteConfig{{Method: "put", Path: "/api/domains/:id"}}, r, func(c *gin.Context, change *PendingChange) {
+		*submitted = append(*submitted, change)
+		c.Status(http.StatusAccepted)
+	})
+	t.Cleanup(func() {
+		InitChangeApproval(nil, nil, nil)
+		SetTokenLoader(nil)
+	})
+	return r
+}
+
+func TestChangeApprovalRecordsToken(t *testing.T) {
+	var submitted []*PendingChange
+	r := newApprovalRouter(t, &submitted)
+
+	req := httptest.NewRequest("PUT", "/api/domains/3?force=1", strings.NewReader(`{"remark":"x"}`))
+	req.Header.Set("Authorization", "Bearer "+TokenPrefix+"valid")
+	req.Header.Set("X-Change-Reason", "续费")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusAccepted || len(submitted) != 1 {
+		t.Fatalf("code = %d, submitted = %d", w.Code, len(submitted))
+	}
+	change := submitted[0]
+	if change.Route != "/api/domains/:id" || change.Path != "/api/domains/3" || change.Query != "force=1" ||
+		string(change.Body) != `{"remark":"x"}` || change.Reason != "续费" || change.TokenID != 1 {
+		t.Fatalf("change = %+v", change)
+	}
+
+	// 未配置审批的请求直接执行
+	if code := serveWithToken(r, "GET", "/api/domains/3", TokenPrefix+"valid"); code != http.StatusOK || len(submitted) != 1 {
+		t.Fatalf("GET = %d, submitted = %d", code, len(submitted))
+	}
+}
+
+func TestReplayChangeReloadsToken(t *testing.T) {
+	var submitted []*PendingChange
+	newApprovalRouter(t, &submitted)
+	tokens := map[uint]*TokenIdentity{
+		1: {TokenID: 1, UserID: 7, Role: "user", Scopes: []TokenScope{{Resource: "/api/domains/:id", Action: "PUT"}}},
+		2: {TokenID: 2, UserID: 7, Role: "user", Scopes: []TokenScope{{Resource: "/api/domains", Action: "GET"}}},
+		3: {TokenID: 3, UserID: 8, Role: "user", Scopes: []TokenScope{{Resource: "/api/domains/:id", Action: "PUT"}}},
+	}
+	SetTokenLoader(func(tokenID uint) (*TokenIdentity, error) {
+		if token, ok := tokens[tokenID]; ok {
+			return token, nil
+		}
+		return nil, errors.New("API令牌已吊销")
+	})
+
+	tests := []struct {
+		name    string
+		tokenID uint
+		want    int
+	}{
+		{"session", 0, http.StatusOK},
+		{"token within scope", 1, http.StatusOK},
+		{"token outside scope", 2, http.StatusForbidden},
+		{"token of another user", 3, http.StatusUnauthorized},
+		{"revoked token", 4, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity := Identity{UserID: 7, Username: "alice", Role: "user", TokenID: tt.tokenID}
+			code, body, err := ReplayChange(context.Background(), identity, "PUT", "/api/domains/3", "", []byte(`{}`))
+			if err != nil || code != tt.want {
+				t.Fatalf("ReplayChange = %d, %v; want %d", code, err, tt.want)
+			}
+			if code == http.StatusOK && string(body) != "alice" {
+				t.Fatalf("replayed as %q", body)
+			}
+		})
+	}
+	// 重放的请求不会再次提交审批
+	if len(submitted) != 0 {
+		t.Fatalf("replayed change was submitted again: %d", len(submitted))
+	}
+}
@@ -1,17 +1,13 @@
 package middleware
 
 import (
-	"context"
-	"domain-admin/pkg/cache"
 	"domain-admin/pkg/jwt"
 	"domain-admin/pkg/logger"
 	"errors"
 	"net/http"
-	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/redis/go-redis/v9"
 )
 
 func JWTAuth() gin.HandlerFunc {
@@ -27,6 +23,29 @@ func JWTAuth() gin.HandlerFunc {
 			}
 		}()
 
+		// 审批通过后重放的变更请求，以提交人身份执行
+		if identity, ok := approvedIdentity(c.Request.Context()); ok {
+			// 使用API令牌提交的变更，执行时仍受令牌当前的权限范围约束
+			if identity.TokenID != 0 {
+				token, err := loadApprovedToken(identity)
+				if err != nil {
+					logger.Warnf("Approved change token validation failed: %v, path: %s", err, c.Request.URL.Path)
+					c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+						"code":    401,
+						"message": "Invalid or expired token",
+					})
+					return
+				}
+				c.Set(tokenContextKey, token)
+			}
+			c.Set("userID", identity.UserID)
+			c.Set("user_id", identity.UserID)
+			c.Set("role", identity.Role)
+			c.Set("username", identity.Username)
+			c.Next()
+			return
+		}
+
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
 			logger.Warnf("Authorization header missing, path: %s", c.Request.URL.Path)
@@ -58,6 +77,12 @@ func JWTAuth() gin.HandlerFunc {
 			return
 		}
 
+		// API令牌由令牌校验函数认证
+		if strings.HasPrefix(token, TokenPrefix) {
+			authenticateAPIToken(c, token)
+			return
+		}
+
 		claims, err := jwt.ParseToken(token)
 		if err != nil {
 			logger.Warnf("Token validation failed: %v, path: %s", err, c.Request.URL.Path)
@@ -68,31 +93,26 @@ func JWTAuth() gin.HandlerFunc {
 			return
 		}
 
-		// 验证会话缓存（如果Redis可用）
-		ctx := context.Background()
-		sessionKey := strconv.FormatUint(uint64(claims.UserID), 10)
-		var sessionData map[string]interface{}
+		// 校验登录会话，会话已吊销时令牌立即失效；Redis 不可用时由校验函数查询数据库
 		userID := claims.UserID
 		username := claims.Username
 		role := claims.Role
-		
-		if err := cache.GetSessionCache(ctx, sessionKey, &sessionData); err != nil {
-			if !errors.Is(err, redis.Nil) {
-				logger.Warnf("获取会话缓存失败: %v", err)
-				// Redis连接错误时，跳过缓存验证，继续使用JWT信息
-			} else {
-				// 会话缓存不存在，可能是Redis未启动或会话过期
-				// 在测试环境下允许继续，生产环境可以要求重新登录
-				logger.Warnf("会话已过期或不存在，用户ID: %d", claims.UserID)
-			}
-		} else {
-			// 从会话缓存中获取最新的用户信息
-			if cachedUsername, ok := sessionData["username"].(string); ok && cachedUsername != "" {
-				username = cachedUsername
-			}
-			if cachedRole, ok := sessionData["role"].(string); ok && cachedRole != "" {
-				role = cachedRole
-			}
+		session, err := validateSession(c, claims.ID, c.ClientIP())
+		if err == nil && session != nil && session.UserID != claims.UserID {
+			err = errors.New("session belongs to another user")
+		}
+		if err != nil {
+			logger.Warnf("Session validation failed: %v, user: %d, path: %s", err, claims.UserID, c.Request.URL.Path)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"code":    401,
+				"message": "Session revoked or expired",
+			})
+			return
+		}
+		if session != nil {
+			// 使用会话中最新的用户名和角色
+			username = session.Username
+			role = session.Role
 		}
 
 		// 记录用户信息到日志
@@ -102,6 +122,7 @@ func JWTAuth() gin.HandlerFunc {
 		c.Set("user_id", userID) // 兼容性
 		c.Set("role", role)
 		c.Set("username", username)
+		c.Set(sessionContextKey, claims.ID)
 		c.Next()
 	}
 }
@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"os"
+	"testing"
+
+	"domain-admin/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	logger.Log = zap.NewNop()
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"context"
+	"domain-admin/pkg/cache"
+	"domain-admin/pkg/logger"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimit Gin中间件：按用户限流，每个时间窗口内最多 limit 次请求
+// 需要放在 JWTAuth 之后，未登录的请求按客户端IP计数
+func RateLimit(scope string, limit int, window time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limit <= 0 {
+			c.Next()
+			return
+		}
+
+		subject := "ip:" + c.ClientIP()
+		if uid, ok := c.Get("userID"); ok {
+			subject = fmt.Sprintf("user:%v", uid)
+		}
+
+		count, ttl, err := cache.IncrRateLimit(context.Background(), scope+":"+subject, window)
+		if err != nil {
+			// 限流计数失败时放行，避免缓存故障影响正常使用
+			logger.Warnf("限流计数失败: %v", err)
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(limit))
+		c.Header("X-RateLimit-Remaining", strconv.FormatInt(max(int64(limit)-count, 0), 10))
+
+		if count > int64(limit) {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(ttl.Seconds()))))
+			logger.Warnf("请求过于频繁: %s %s, path: %s", scope, subject, c.Request.URL.Path)
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"code":    429,
+				"message": "请求过于频繁，请稍后再试",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
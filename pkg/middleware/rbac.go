@@ -36,6 +36,12 @@ func InitRBAC(db *gorm.DB, modelPath string) error {
 		return fmt.Errorf("failed to create adapter: %w", err)
 	}
 
+	// 升级前的策略没有属性条件字段，补充为不限制，否则加载时字段数与模型不一致
+	if err := db.Table("casbin_rule").Where("ptype = ? AND (v3 = '' OR v3 IS NULL)", "p").
+		Update("v3", ConditionAny).Error; err != nil {
+		return fmt.Errorf("failed to upgrade policies: %w", err)
+	}
+
 	// 创建 enforcer，加载模型和数据库策略
 	e, err := casbin.NewEnforcer(modelPath, adapter)
 	if err != nil {
@@ -43,6 +49,9 @@ func InitRBAC(db *gorm.DB, modelPath string) error {
 		return fmt.Errorf("failed to create enforcer: %w", err)
 	}
 
+	// 注册属性条件函数，供模型中的 attrMatch(p.cond, r.attr) 使用
+	e.AddFunction("attrMatch", attrMatch)
+
 	// 从数据库加载策略
 	if loadErr := e.LoadPolicy(); loadErr != nil {
 		logger.Errorf("Failed to load policy from database", "error", err)
@@ -50,6 +59,7 @@ func InitRBAC(db *gorm.DB, modelPath string) error {
 	}
 
 	Enforcer = e
+	rbacDB = db
 	initialized = true
 	policies, err := e.GetPolicy()
 	if err != nil {
@@ -95,10 +105,7 @@ func RBACMiddleware() gin.HandlerFunc {
 		path := c.Request.URL.Path
 		method := c.Request.Method
 
-		enforcerMux.RLock()
-		allowed, err := Enforcer.Enforce(role, path, method)
-		enforcerMux.RUnlock()
-
+		allowed, err := enforceRequest(c, role, path, method)
 		if err != nil {
 			logger.Errorf("RBAC enforcement error", "error", err, "role", role, "path", path, "method", method)
 			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
@@ -119,6 +126,28 @@ func RBACMiddleware() gin.HandlerFunc {
 	}
 }
 
+// enforceRequest 加载目标对象属性后执行鉴权，加载的对象保存到上下文供处理器使用
+func enforceRequest(c *gin.Context, role, path, method string) (bool, error) {
+	obj, err := resolveObject(c)
+	if err != nil {
+		return false, fmt.Errorf("加载目标对象失败: %w", err)
+	}
+	c.Set(objectContextKey, obj)
+
+	attrs, err := buildAttributes(c, role, obj)
+	if err != nil {
+		return false, err
+	}
+
+	if !scopeAllows(c, path, method, attrs) {
+		return false, nil
+	}
+
+	enforcerMux.RLock()
+	defer enforcerMux.RUnlock()
+	return Enforcer.Enforce(role, path, method, attrs)
+}
+
 // SyncRBACPolicies 同步RBAC策略到Casbin
 func SyncRBACPolicies(db *gorm.DB) error {
 	if !initialized || Enforcer == nil {
@@ -158,13 +187,14 @@ func syncRolePermissionPolicies(db *gorm.DB) error {
 		PermissionName string `gorm:"column:permission_name"`
 		Resource       string `gorm:"column:resource"`
 		Action         string `gorm:"column:action"`
+		Condition      string `gorm:"column:attr_condition"`
 	}
 
 	err := db.Table("domain_role_permissions rp").
 		Joins("JOIN domain_role r ON rp.role_id = r.id").
 		Joins("JOIN domain_permission p ON rp.permission_id = p.id").
 		Where("r.status = ? AND p.status = ?", 1, 1).
-		Select("r.name as role_name, p.name as permission_name, p.resource, p.action").
+		Select("r.name as role_name, p.name as permission_name, p.resource, p.action, p.attr_condition").
 		Find(&rolePermissions).Error
 
 	if err != nil {
@@ -176,14 +206,15 @@ func syncRolePermissionPolicies(db *gorm.DB) error {
 	defer enforcerMux.Unlock()
 
 	for _, rp := range rolePermissions {
-		// 添加策略: p, role, resource, action
-		success, err := Enforcer.AddPolicy(rp.RoleName, rp.Resource, rp.Action)
+		// 添加策略: p, role, resource, action, condition
+		cond := policyCondition(rp.Condition)
+		success, err := Enforcer.AddPolicy(rp.RoleName, rp.Resource, rp.Action, cond)
 		if err != nil {
 			logger.Errorf("添加策略失败", "role", rp.RoleName, "resource", rp.Resource, "action", rp.Action, "error", err)
 			continue
 		}
 		if success {
-			logger.Debugf("添加策略成功: %s, %s, %s", rp.RoleName, rp.Resource, rp.Action)
+			logger.Debugf("添加策略成功: %s, %s, %s, %s", rp.RoleName, rp.Resource, rp.Action, cond)
 		}
 	}
 
@@ -191,8 +222,17 @@ func syncRolePermissionPolicies(db *gorm.DB) error {
 	return nil
 }
 
-// AddRolePermissionPolicy 添加角色权限策略
-func AddRolePermissionPolicy(role, resource, action string) error {
+// policyCondition 将权限的属性条件转换为策略值
+// 空条件存为 "*"，避免适配器截掉末尾的空字段导致策略字段数与模型不一致
+func policyCondition(cond string) string {
+	if cond == "" {
+		return ConditionAny
+	}
+	return cond
+}
+
+// AddRolePermissionPolicy 添加角色权限策略，condition 为空时不限制目标对象
+func AddRolePermissionPolicy(role, resource, action, condition string) error {
 	if !initialized || Enforcer == nil {
 		return fmt.Errorf("RBAC system not initialized")
 	}
@@ -200,7 +240,7 @@ func AddRolePermissionPolicy(role, resource, action string) error {
 	enforcerMux.Lock()
 	defer enforcerMux.Unlock()
 
-	success, err := Enforcer.AddPolicy(role, resource, action)
+	success, err := Enforcer.AddPolicy(role, resource, action, policyCondition(condition))
 	if err != nil {
 		return fmt.Errorf("添加策略失败: %w", err)
 	}
@@ -217,7 +257,7 @@ func AddRolePermissionPolicy(role, resource, action string) error {
 }
 
 // RemoveRolePermissionPolicy 移除角色权限策略
-func RemoveRolePermissionPolicy(role, resource, action string) error {
+func RemoveRolePermissionPolicy(role, resource, action, condition string) error {
 	if !initialized || Enforcer == nil {
 		return fmt.Errorf("RBAC system not initialized")
 	}
@@ -225,7 +265,7 @@ func RemoveRolePermissionPolicy(role, resource, action string) error {
 	enforcerMux.Lock()
 	defer enforcerMux.Unlock()
 
-	success, err := Enforcer.RemovePolicy(role, resource, action)
+	success, err := Enforcer.RemovePolicy(role, resource, action, policyCondition(condition))
 	if err != nil {
 		return fmt.Errorf("移除策略失败: %w", err)
 	}
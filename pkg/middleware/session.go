@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sessionContextKey 登录会话ID（访问令牌的jti）在 gin.Context 中的键
+const sessionContextKey = "sessionID"
+
+// SessionInfo 登录会话当前的用户信息
+type SessionInfo struct {
+	UserID   uint
+	Username string
+	Role     string
+}
+
+// SessionValidator 校验访问令牌jti对应的登录会话，会话已吊销或过期时返回错误
+type SessionValidator func(ctx context.Context, sessionID, clientIP string) (*SessionInfo, error)
+
+var (
+	sessionValidator SessionValidator
+	sessionMux       sync.RWMutex
+)
+
+// SetSessionValidator 设置登录会话的校验函数，未设置时 JWTAuth 只校验令牌签名和有效期
+func SetSessionValidator(validator SessionValidator) {
+	sessionMux.Lock()
+	defer sessionMux.Unlock()
+	sessionValidator = validator
+}
+
+// GetSessionID 返回当前请求所属的登录会话ID，使用API令牌或审批重放的请求返回空字符串
+func GetSessionID(c *gin.Context) string {
+	return c.GetString(sessionContextKey)
+}
+
+// RequireSession 要求请求来自交互式登录会话，需在 JWTAuth 之后使用。
+// 会话管理、两步验证和安全密钥等账号安全操作不受API令牌的权限范围约束，不允许API令牌调用
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := GetTokenIdentity(c); ok || GetSessionID(c) == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"code":    403,
+				"message": "该操作需要登录会话，不能使用API令牌",
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// validateSession 调用会话校验函数，未设置时返回 nil
+func validateSession(ctx context.Context, sessionID, clientIP string) (*SessionInfo, error) {
+	sessionMux.RLock()
+	validate := sessionValidator
+	sessionMux.RUnlock()
+
+	if validate == nil {
+		return nil, nil
+	}
+	return validate(ctx, sessionID, clientIP)
+}
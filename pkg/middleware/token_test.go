@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// withTokenAuthenticator 在测试期间使用固定的API令牌身份
+func withTokenAuthenticator(t *testing.T, scopes ...TokenScope) {
+	t.Helper()
+	SetTokenAuthenticator(func(token, clientIP string) (*TokenIdentity, error) {
+		if token != TokenPrefix+"valid" {
+			return nil, errors.New("unknown token")
+		}
+		return &TokenIdentity{TokenID: 1, UserID: 7, Username: "alice", Role: "user", Scopes: scopes}, nil
+	})
+	t.Cleanup(func() { SetTokenAuthenticator(nil) })
+}
+
+func serveWithToken(r *gin.Engine, method, path, token string) int {
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestScopeAllows(t *testing.T) {
+	scopes := []TokenScope{
+		{Resource: "/api/domains", Action: "GET"},
+		{Resource: "/api/domains/:id", Action: "*"},
+		{Resource: "/api/users/:id", Action: "PUT", Condition: ConditionSelf},
+	}
+	own := &Attributes{SubjectID: 7, Object: &ObjectAttributes{OwnerID: 7}}
+	other := &Attributes{SubjectID: 7, Object: &ObjectAttributes{OwnerID: 8}}
+	tests := []struct {
+		name   string
+		path   string
+		method string
+		attrs  *Attributes
+		want   bool
+	}{
+		{"exact path", "/api/domains", "GET", nil, true},
+		{"other method", "/api/domains", "POST", nil, false},
+		{"path parameter with any method", "/api/domains/3", "DELETE", nil, true},
+		{"parameter does not match sub path", "/api/domains/3/records", "GET", nil, false},
+		{"self condition on own object", "/api/users/7", "PUT", own, true},
+		{"self condition on other object", "/api/users/8", "PUT", other, false},
+		{"condition without object", "/api/users/7", "PUT", nil, false},
+		{"out of scope", "/api/tokens", "GET", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Set(tokenContextKey, &TokenIdentity{UserID: 7, Scopes: scopes})
+			if got := scopeAllows(c, tt.path, tt.method, tt.attrs); got != tt.want {
+				t.Fatalf("scopeAllows(%s %s) = %v, want %v", tt.method, tt.path, got, tt.want)
+			}
+		})
+	}
+
+	// 登录会话不受令牌权限范围约束
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	if !scopeAllows(c, "/api/tokens", "GET", nil) {
+		t.Fatal("session request was limited by token scopes")
+	}
+}
+
+func TestRequireTokenScope(t *testing.T) {
+	withTokenAuthenticator(t, TokenScope{Resource: "/api/domains", Action: "GET"})
+	r := gin.New()
+	r.GET("/api/domains", JWTAuth(), RequireTokenScope(), func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/api/dashboard/stats", JWTAuth(), RequireTokenScope(), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	if code := serveWithToken(r, "GET", "/api/domains", TokenPrefix+"valid"); code != http.StatusOK {
+		t.Fatalf("in scope = %d", code)
+	}
+	if code := serveWithToken(r, "GET", "/api/dashboard/stats", TokenPrefix+"valid"); code != http.StatusForbidden {
+		t.Fatalf("out of scope = %d", code)
+	}
+	if code := serveWithToken(r, "GET", "/api/domains", TokenPrefix+"unknown"); code != http.StatusUnauthorized {
+		t.Fatalf("unknown token = %d", code)
+	}
+}
+
+func TestRequireSessionRejectsAPIToken(t *testing.T) {
+	withTokenAuthenticator(t, TokenScope{Resource: "/api/*", Action: "*"})
+	r := gin.New()
+	r.POST("/api/tokens", JWTAuth(), RequireSession(), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	// 令牌权限范围覆盖也不能调用账号安全操作
+	if code := serveWithToken(r, "POST", "/api/tokens", TokenPrefix+"valid"); code != http.StatusForbidden {
+		t.Fatalf("api token = %d", code)
+	}
+}
@@ -0,0 +1,136 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// jsonWebKey JWKS 中的公钥
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// key 返回 kid 对应的验证公钥，缓存中没有时重新获取 JWKS（至少间隔 keysMinRefresh）。
+// 令牌没有 kid 时只有 JWKS 中只有一个密钥才能使用
+func (p *Provider) key(ctx context.Context, jwksURI, kid string) (interface{}, error) {
+	p.mu.Lock()
+	k, ok := p.lookup(kid)
+	stale := time.Since(p.keysAt) >= keysMinRefresh
+	p.mu.Unlock()
+	if ok {
+		return k, nil
+	}
+	if !stale {
+		return nil, fmt.Errorf("oidc: unknown key id %q", kid)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := p.getJSON(ctx, jwksURI, "", &set); err != nil {
+		return nil, fmt.Errorf("oidc: fetch jwks failed: %w", err)
+	}
+	keys := make(map[string]interface{}, len(set.Keys))
+	list := make([]interface{}, 0, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		pub, err := jwk.publicKey()
+		if err != nil {
+			// 跳过不支持的密钥类型，不影响其他密钥
+			continue
+		}
+		keys[jwk.Kid] = pub
+		list = append(list, pub)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.keys = keys
+	p.keysList = list
+	p.keysAt = time.Now()
+	if k, ok := p.lookup(kid); ok {
+		return k, nil
+	}
+	return nil, fmt.Errorf("oidc: unknown key id %q", kid)
+}
+
+// lookup 在缓存中查找密钥，调用方需持有锁
+func (p *Provider) lookup(kid string) (interface{}, bool) {
+	if kid == "" {
+		if len(p.keysList) == 1 {
+			return p.keysList[0], true
+		}
+		return nil, false
+	}
+	k, ok := p.keys[kid]
+	return k, ok
+}
+
+// publicKey 将 JWK 转换为公钥
+func (k *jsonWebKey) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err1 := decodeB64(k.N)
+		e, err2 := decodeB64(k.E)
+		if err1 != nil || err2 != nil || len(e) == 0 || len(e) > 4 {
+			return nil, errors.New("oidc: invalid RSA key")
+		}
+		pub := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		if pub.N.BitLen() < 2048 {
+			return nil, errors.New("oidc: RSA key is too short")
+		}
+		return pub, nil
+	case "EC":
+		var curve elliptic.Curve
+		var size int
+		var check ecdh.Curve
+		switch k.Crv {
+		case "P-256":
+			curve, size, check = elliptic.P256(), 32, ecdh.P256()
+		case "P-384":
+			curve, size, check = elliptic.P384(), 48, ecdh.P384()
+		default:
+			return nil, fmt.Errorf("oidc: unsupported curve %s", k.Crv)
+		}
+		x, err1 := decodeB64(k.X)
+		y, err2 := decodeB64(k.Y)
+		if err1 != nil || err2 != nil || len(x) != size || len(y) != size {
+			return nil, errors.New("oidc: invalid EC key")
+		}
+		// 通过 ecdh 校验坐标在曲线上
+		if _, err := check.NewPublicKey(append(append([]byte{0x04}, x...), y...)); err != nil {
+			return nil, errors.New("oidc: invalid EC key")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	case "OKP":
+		x, err := decodeB64(k.X)
+		if k.Crv != "Ed25519" || err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("oidc: invalid OKP key")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("oidc: unsupported key type %s", k.Kty)
+	}
+}
+
+func decodeB64(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
@@ -0,0 +1,311 @@
+// Package oidc 实现 OpenID Connect 依赖方的授权码 + PKCE 登录：发现文档、授权地址、令牌交换、
+// 使用 JWKS 校验 ID Token 以及读取 UserInfo
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// metadataTTL 发现文档的缓存时间
+	metadataTTL = time.Hour
+	// keysMinRefresh 遇到未知 kid 时两次刷新 JWKS 的最小间隔
+	keysMinRefresh = time.Minute
+	// maxResponseSize 读取身份提供方响应的最大字节数
+	maxResponseSize = 1 << 20
+	// clockSkew 校验 ID Token 时间声明允许的时钟偏差
+	clockSkew = time.Minute
+)
+
+// signingMethods ID Token 允许的签名算法，不接受 none 和 HMAC
+var signingMethods = []string{"RS256", "RS384", "RS512", "PS256", "ES256", "ES384", "EdDSA"}
+
+// Metadata 身份提供方的发现文档
+type Metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Token 令牌端点的响应
+type Token struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// Claims ID Token 或 UserInfo 中的声明
+type Claims map[string]interface{}
+
+// Provider 身份提供方，发现文档和 JWKS 在首次使用时获取并缓存
+type Provider struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	HTTPClient   *http.Client
+
+	mu       sync.Mutex
+	meta     *Metadata
+	metaAt   time.Time
+	keys     map[string]interface{}
+	keysAt   time.Time
+	keysList []interface{}
+}
+
+// NewProvider 创建身份提供方，scopes 为空时使用 openid profile email
+func NewProvider(issuer, clientID, clientSecret, redirectURL string, scopes []string) *Provider {
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "profile", "email"}
+	}
+	return &Provider{
+		Issuer:       strings.TrimRight(issuer, "/"),
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  redirectURL,
+		Scopes:       scopes,
+		HTTPClient:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Discover 获取发现文档，发现文档中的 issuer 必须与配置一致
+func (p *Provider) Discover(ctx context.Context) (*Metadata, error) {
+	p.mu.Lock()
+	if p.meta != nil && time.Since(p.metaAt) < metadataTTL {
+		meta := p.meta
+		p.mu.Unlock()
+		return meta, nil
+	}
+	p.mu.Unlock()
+
+	var meta Metadata
+	if err := p.getJSON(ctx, p.Issuer+"/.well-known/openid-configuration", "", &meta); err != nil {
+		return nil, fmt.Errorf("oidc: discovery failed: %w", err)
+	}
+	if strings.TrimRight(meta.Issuer, "/") != p.Issuer {
+		return nil, fmt.Errorf("oidc: issuer mismatch: discovery returned %s", meta.Issuer)
+	}
+	if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" || meta.JWKSURI == "" {
+		return nil, errors.New("oidc: discovery document is missing required endpoints")
+	}
+
+	p.mu.Lock()
+	p.meta = &meta
+	p.metaAt = time.Now()
+	p.mu.Unlock()
+	return &meta, nil
+}
+
+// AuthCodeURL 返回授权地址，verifier 为 PKCE 验证码，只保存在服务端
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	meta, err := p.Discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	q := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.ClientID},
+		"redirect_uri":          {p.RedirectURL},
+		"scope":                 {strings.Join(p.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {CodeChallenge(verifier)},
+		"code_challenge_method": {"S256"},
+	}
+	sep := "?"
+	if strings.Contains(meta.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return meta.AuthorizationEndpoint + sep + q.Encode(), nil
+}
+
+// Exchange 使用授权码和 PKCE 验证码换取令牌，配置了客户端密钥时使用 client_secret_basic 认证
+func (p *Provider) Exchange(ctx context.Context, code, verifier string) (*Token, error) {
+	meta, err := p.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.RedirectURL},
+		"code_verifier": {verifier},
+	}
+	if p.ClientSecret == "" {
+		form.Set("client_id", p.ClientID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.ClientID), url.QueryEscape(p.ClientSecret))
+	}
+
+	var token Token
+	if err := p.do(req, &token); err != nil {
+		return nil, fmt.Errorf("oidc: token exchange failed: %w", err)
+	}
+	if token.IDToken == "" {
+		return nil, errors.New("oidc: token response has no id_token")
+	}
+	return &token, nil
+}
+
+// VerifyIDToken 校验 ID Token 的签名、签发方、受众、有效期和 nonce，返回其中的声明
+func (p *Provider) VerifyIDToken(ctx context.Context, raw, nonce string) (Claims, error) {
+	meta, err := p.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(raw, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.key(ctx, meta.JWKSURI, kid)
+	},
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithIssuer(meta.Issuer),
+		jwt.WithAudience(p.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(clockSkew),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: invalid id_token: %w", err)
+	}
+
+	// 多个受众时 azp 必须是本客户端
+	if aud, _ := claims.GetAudience(); len(aud) > 1 {
+		if azp, _ := claims["azp"].(string); azp != p.ClientID {
+			return nil, errors.New("oidc: id_token authorized party mismatch")
+		}
+	}
+	if got, _ := claims["nonce"].(string); nonce == "" || got != nonce {
+		return nil, errors.New("oidc: id_token nonce mismatch")
+	}
+	if sub, _ := claims["sub"].(string); sub == "" {
+		return nil, errors.New("oidc: id_token has no subject")
+	}
+	return Claims(claims), nil
+}
+
+// UserInfo 使用访问令牌读取 UserInfo，发现文档中没有 UserInfo 端点时返回 nil
+func (p *Provider) UserInfo(ctx context.Context, accessToken string) (Claims, error) {
+	meta, err := p.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if meta.UserinfoEndpoint == "" || accessToken == "" {
+		return nil, nil
+	}
+	var claims Claims
+	if err := p.getJSON(ctx, meta.UserinfoEndpoint, accessToken, &claims); err != nil {
+		return nil, fmt.Errorf("oidc: userinfo failed: %w", err)
+	}
+	return claims, nil
+}
+
+// String 返回字符串声明
+func (c Claims) String(name string) string {
+	s, _ := c[name].(string)
+	return s
+}
+
+// Bool 返回布尔声明，兼容部分身份提供方返回的 "true" 字符串
+func (c Claims) Bool(name string) bool {
+	switch v := c[name].(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	}
+	return false
+}
+
+// Strings 返回字符串数组声明，单个字符串按一个元素处理
+func (c Claims) Strings(name string) []string {
+	switch v := c[name].(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// RandomString 生成 base64url 编码的随机字符串，用于 state、nonce 和 PKCE 验证码
+func RandomString() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// CodeChallenge 返回 PKCE 验证码的 S256 挑战
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// getJSON 发送GET请求并解析JSON响应，bearer 不为空时作为访问令牌
+func (p *Provider) getJSON(ctx context.Context, endpoint, bearer string, dest interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+	return p.do(req, dest)
+}
+
+func (p *Provider) do(req *http.Request, dest interface{}) error {
+	resp, err := p.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var oauthErr struct {
+			Error       string `json:"error"`
+			Description string `json:"error_description"`
+		}
+		if json.Unmarshal(body, &oauthErr) == nil && oauthErr.Error != "" {
+			return fmt.Errorf("%s: %s", oauthErr.Error, oauthErr.Description)
+		}
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return json.Unmarshal(body, dest)
+}
@@ -0,0 +1,191 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// stubIdP 本地身份提供方，签发 ES256 的 ID Token
+type stubIdP struct {
+	*httptest.Server
+	key       *ecdsa.PrivateKey
+	issuer    string
+	codes     map[string]string // 授权码 -> PKCE 挑战
+	nonce     string
+	claims    jwt.MapClaims
+	basicAuth string
+}
+
+func newStubIdP(t *testing.T) *stubIdP {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &stubIdP{key: key, codes: map[string]string{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 s.issuer,
+			"authorization_endpoint": s.URL + "/authorize",
+			"token_endpoint":         s.URL + "/token",
+			"userinfo_endpoint":      s.URL + "/userinfo",
+			"jwks_uri":               s.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		enc := base64.RawURLEncoding
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "EC", "kid": "k1", "use": "sig", "crv": "P-256",
+			"x": enc.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+			"y": enc.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		challenge, ok := s.codes[r.PostForm.Get("code")]
+		if !ok || CodeChallenge(r.PostForm.Get("code_verifier")) != challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		user, pass, _ := r.BasicAuth()
+		s.basicAuth = user + ":" + pass
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "at", "token_type": "Bearer", "expires_in": 3600,
+			"id_token": s.sign(t, "k1", s.idClaims()),
+		})
+	})
+	s.Server = httptest.NewServer(mux)
+	s.issuer = s.URL
+	return s
+}
+
+func (s *stubIdP) idClaims() jwt.MapClaims {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss": s.issuer, "aud": "client", "sub": "u-123", "nonce": s.nonce,
+		"iat": now.Unix(), "exp": now.Add(time.Hour).Unix(),
+		"email": "alice@example.com", "groups": []string{"admins"},
+	}
+	for k, v := range s.claims {
+		claims[k] = v
+	}
+	return claims
+}
+
+func (s *stubIdP) sign(t *testing.T, kid string, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["kid"] = kid
+	raw, err := token.SignedString(s.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	idp := newStubIdP(t)
+	defer idp.Close()
+	p := NewProvider(idp.URL+"/", "client", "secret", "https://app.test/callback", nil)
+	ctx := context.Background()
+
+	state, _ := RandomString()
+	nonce, _ := RandomString()
+	verifier, _ := RandomString()
+	authURL, err := p.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	u, _ := url.Parse(authURL)
+	q := u.Query()
+	if !strings.HasPrefix(authURL, idp.URL+"/authorize?") || q.Get("state") != state || q.Get("nonce") != nonce ||
+		q.Get("code_challenge") != CodeChallenge(verifier) || q.Get("code_challenge_method") != "S256" ||
+		q.Get("scope") != "openid profile email" || q.Get("redirect_uri") != "https://app.test/callback" {
+		t.Fatalf("unexpected auth url %s", authURL)
+	}
+
+	idp.codes["code-1"] = q.Get("code_challenge")
+	idp.nonce = nonce
+	if _, err := p.Exchange(ctx, "code-1", "wrong-verifier"); err == nil || !strings.Contains(err.Error(), "invalid_grant") {
+		t.Fatalf("exchange with wrong verifier err = %v", err)
+	}
+	token, err := p.Exchange(ctx, "code-1", verifier)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if idp.basicAuth != "client:secret" {
+		t.Errorf("client authentication = %q", idp.basicAuth)
+	}
+
+	claims, err := p.VerifyIDToken(ctx, token.IDToken, nonce)
+	if err != nil {
+		t.Fatalf("VerifyIDToken: %v", err)
+	}
+	if claims.String("sub") != "u-123" || claims.String("email") != "alice@example.com" || claims.Strings("groups")[0] != "admins" {
+		t.Fatalf("unexpected claims %v", claims)
+	}
+	if _, err := p.VerifyIDToken(ctx, token.IDToken, "other-nonce"); err == nil {
+		t.Error("nonce mismatch accepted")
+	}
+}
+
+func TestVerifyIDTokenRejects(t *testing.T) {
+	idp := newStubIdP(t)
+	defer idp.Close()
+	p := NewProvider(idp.URL, "client", "", "https://app.test/callback", nil)
+	ctx := context.Background()
+	idp.nonce = "n"
+
+	cases := map[string]string{
+		"wrong audience": idp.sign(t, "k1", merge(idp.idClaims(), jwt.MapClaims{"aud": "other"})),
+		"wrong issuer":   idp.sign(t, "k1", merge(idp.idClaims(), jwt.MapClaims{"iss": "https://evil.test"})),
+		"expired":        idp.sign(t, "k1", merge(idp.idClaims(), jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()})),
+		"azp mismatch":   idp.sign(t, "k1", merge(idp.idClaims(), jwt.MapClaims{"aud": []string{"client", "other"}, "azp": "other"})),
+		"unknown kid":    idp.sign(t, "k2", idp.idClaims()),
+	}
+	hmac := jwt.NewWithClaims(jwt.SigningMethodHS256, idp.idClaims())
+	hmac.Header["kid"] = "k1"
+	cases["hmac"], _ = hmac.SignedString([]byte("client-secret"))
+	other, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	forged := jwt.NewWithClaims(jwt.SigningMethodES256, idp.idClaims())
+	forged.Header["kid"] = "k1"
+	cases["forged signature"], _ = forged.SignedString(other)
+
+	for name, raw := range cases {
+		if _, err := p.VerifyIDToken(ctx, raw, "n"); err == nil {
+			t.Errorf("%s: token accepted", name)
+		}
+	}
+	if _, err := p.VerifyIDToken(ctx, idp.sign(t, "k1", idp.idClaims()), "n"); err != nil {
+		t.Errorf("valid token rejected: %v", err)
+	}
+}
+
+func TestDiscoveryIssuerMismatch(t *testing.T) {
+	idp := newStubIdP(t)
+	defer idp.Close()
+	idp.issuer = "https://other.test"
+	p := NewProvider(idp.URL, "client", "", "https://app.test/callback", nil)
+	if _, err := p.Discover(context.Background()); err == nil {
+		t.Fatal("issuer mismatch accepted")
+	}
+}
+
+func merge(a, b jwt.MapClaims) jwt.MapClaims {
+	for k, v := range b {
+		a[k] = v
+	}
+	return a
+}
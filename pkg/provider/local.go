@@ -0,0 +1,216 @@
+package provider
+
+import (
+	"context"
+	"domain-admin/pkg/config"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// LocalType 本地服务商类型
+const LocalType = "local"
+
+func init() {
+	Register(LocalType, NewLocalProvider)
+	// path 会读写服务器上的任意文件，只能在配置文件中设置
+	AllowAPIOptions(LocalType, "zones")
+}
+
+// localState 本地服务商持久化数据
+type localState struct {
+	NextID int                 `json:"next_id"`
+	Zones  map[string][]Record `json:"zones"`
+}
+
+// LocalProvider 基于内存（可选文件持久化）的服务商实现，用于开发和测试
+//
+// 支持的 options:
+//   - path:  JSON 数据文件路径，为空时仅保存在内存中
+//   - zones: 逗号分隔的初始区域列表
+type LocalProvider struct {
+	mu    sync.Mutex
+	path  string
+	state localState
+}
+
+// NewLocalProvider 创建本地服务商
+func NewLocalProvider(cfg config.CloudProviderConfig) (DNSProvider, error) {
+	p := &LocalProvider{
+		path:  cfg.Options["path"],
+		state: localState{NextID: 1, Zones: make(map[string][]Record)},
+	}
+
+	if p.path != "" {
+		if err := p.load(); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, zone := range strings.Split(cfg.Options["zones"], ",") {
+		if zone = NormalizeZone(zone); zone != "" {
+			if err := p.CreateZone(zone); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return p, nil
+}
+
+// Type 返回服务商类型
+func (p *LocalProvider) Type() string {
+	return LocalType
+}
+
+// CreateZone 创建区域，区域已存在时不做任何操作
+func (p *LocalProvider) CreateZone(zone string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	zone = NormalizeZone(zone)
+	if _, ok := p.state.Zones[zone]; ok {
+		return nil
+	}
+	p.state.Zones[zone] = []Record{}
+	return p.save()
+}
+
+// ListZones 获取区域列表
+func (p *LocalProvider) ListZones(ctx context.Context) ([]Zone, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	zones := make([]Zone, 0, len(p.state.Zones))
+	for name := range p.state.Zones {
+		zones = append(zones, Zone{ID: name, Name: name})
+	}
+	sort.Slice(zones, func(i, j int) bool { return zones[i].Name < zones[j].Name })
+	return zones, nil
+}
+
+// ListRecords 获取区域下的记录列表
+func (p *LocalProvider) ListRecords(ctx context.Context, zone string) ([]Record, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	records, ok := p.state.Zones[NormalizeZone(zone)]
+	if !ok {
+		return nil, ErrZoneNotFound
+	}
+	result := make([]Record, len(records))
+	copy(result, records)
+	return result, nil
+}
+
+// CreateRecord 创建记录
+func (p *LocalProvider) CreateRecord(ctx context.Context, zone string, record Record) (*Record, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	zone = NormalizeZone(zone)
+	records, ok := p.state.Zones[zone]
+	if !ok {
+		return nil, ErrZoneNotFound
+	}
+
+	record.ID = strconv.Itoa(p.state.NextID)
+	p.state.NextID++
+	p.state.Zones[zone] = append(records, record)
+	if err := p.save(); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// UpdateRecord 更新记录
+func (p *LocalProvider) UpdateRecord(ctx context.Context, zone string, record Record) (*Record, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	records, ok := p.state.Zones[NormalizeZone(zone)]
+	if !ok {
+		return nil, ErrZoneNotFound
+	}
+	for i := range records {
+		if records[i].ID == record.ID {
+			records[i] = record
+			if err := p.save(); err != nil {
+				return nil, err
+			}
+			return &record, nil
+		}
+	}
+	return nil, ErrRecordNotFound
+}
+
+// DeleteRecord 删除记录
+func (p *LocalProvider) DeleteRecord(ctx context.Context, zone string, recordID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	zone = NormalizeZone(zone)
+	records, ok := p.state.Zones[zone]
+	if !ok {
+		return ErrZoneNotFound
+	}
+	for i := range records {
+		if records[i].ID == recordID {
+			p.state.Zones[zone] = append(records[:i], records[i+1:]...)
+			return p.save()
+		}
+	}
+	return ErrRecordNotFound
+}
+
+// load 从数据文件加载状态，文件不存在时使用空状态
+func (p *LocalProvider) load() error {
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("read local provider file: %w", err)
+	}
+
+	var state localState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("parse local provider file: %w", err)
+	}
+	if state.Zones == nil {
+		state.Zones = make(map[string][]Record)
+	}
+	if state.NextID < 1 {
+		state.NextID = 1
+	}
+	p.state = state
+	return nil
+}
+
+// save 将状态写入数据文件，调用方需持有锁
+func (p *LocalProvider) save() error {
+	if p.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(p.state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p.path), 0755); err != nil {
+		return err
+	}
+
+	// 先写临时文件再重命名，避免写入中断导致文件损坏
+	tmp := p.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, p.path)
+}
@@ -0,0 +1,158 @@
+package provider
+
+import (
+	"context"
+	"domain-admin/pkg/config"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newTestLocalProvider(t *testing.T, options map[string]string) *LocalProvider {
+	t.Helper()
+	client, err := New(config.CloudProviderConfig{Type: "LOCAL", Options: options})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client.(*LocalProvider)
+}
+
+func TestLocalProviderRecords(t *testing.T) {
+	ctx := context.Background()
+	p := newTestLocalProvider(t, map[string]string{"zones": "Example.com., example.org, "})
+
+	zones, err := p.ListZones(ctx)
+	if err != nil || len(zones) != 2 || zones[0].Name != "example.com" || zones[1].Name != "example.org" {
+		t.Fatalf("ListZones = %+v, %v", zones, err)
+	}
+
+	www, err := p.CreateRecord(ctx, "EXAMPLE.COM.", Record{Name: "www", Type: "A", Value: "192.0.2.1", TTL: 600})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mx, err := p.CreateRecord(ctx, "example.com", Record{Name: "@", Type: "MX", Value: "mail.example.com", TTL: 600, Priority: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if www.ID == "" || www.ID == mx.ID {
+		t.Fatalf("record IDs = %q, %q", www.ID, mx.ID)
+	}
+
+	www.Value = "192.0.2.2"
+	if _, err := p.UpdateRecord(ctx, "example.com", *www); err != nil {
+		t.Fatal(err)
+	}
+	records, err := p.ListRecords(ctx, "example.com")
+	if err != nil || len(records) != 2 || records[0].Value != "192.0.2.2" || records[1].Priority != 10 {
+		t.Fatalf("ListRecords = %+v, %v", records, err)
+	}
+	// 返回的是副本，修改不影响服务商中的数据
+	records[0].Value = "changed"
+	if records, _ := p.ListRecords(ctx, "example.com"); records[0].Value != "192.0.2.2" {
+		t.Fatalf("ListRecords returned shared slice: %+v", records)
+	}
+
+	if err := p.DeleteRecord(ctx, "example.com", www.ID); err != nil {
+		t.Fatal(err)
+	}
+	if records, _ := p.ListRecords(ctx, "example.com"); len(records) != 1 || records[0].ID != mx.ID {
+		t.Fatalf("records after delete = %+v", records)
+	}
+}
+
+func TestLocalProviderNotFound(t *testing.T) {
+	ctx := context.Background()
+	p := newTestLocalProvider(t, map[string]string{"zones": "example.com"})
+
+	if _, err := p.ListRecords(ctx, "example.net"); !errors.Is(err, ErrZoneNotFound) {
+		t.Fatalf("ListRecords: %v", err)
+	}
+	if _, err := p.CreateRecord(ctx, "example.net", Record{Name: "www", Type: "A", Value: "192.0.2.1"}); !errors.Is(err, ErrZoneNotFound) {
+		t.Fatalf("CreateRecord: %v", err)
+	}
+	if _, err := p.UpdateRecord(ctx, "example.com", Record{ID: "42"}); !errors.Is(err, ErrRecordNotFound) {
+		t.Fatalf("UpdateRecord: %v", err)
+	}
+	if err := p.DeleteRecord(ctx, "example.com", "42"); !errors.Is(err, ErrRecordNotFound) {
+		t.Fatalf("DeleteRecord: %v", err)
+	}
+}
+
+func TestLocalProviderPersistence(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "dns", "local.json")
+	p := newTestLocalProvider(t, map[string]string{"path": path, "zones": "example.com"})
+	first, err := p.CreateRecord(ctx, "example.com", Record{Name: "www", Type: "A", Value: "192.0.2.1", TTL: 600})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Fatalf("temporary file left behind: %v", err)
+	}
+
+	// 重新加载后保留已有记录，新记录的ID继续递增
+	reloaded := newTestLocalProvider(t, map[string]string{"path": path, "zones": "example.com,example.org"})
+	records, err := reloaded.ListRecords(ctx, "example.com")
+	if err != nil || len(records) != 1 || records[0] != *first {
+		t.Fatalf("reloaded records = %+v, %v", records, err)
+	}
+	second, err := reloaded.CreateRecord(ctx, "example.com", Record{Name: "api", Type: "A", Value: "192.0.2.3"})
+	if err != nil || second.ID == first.ID {
+		t.Fatalf("CreateRecord after reload = %+v, %v", second, err)
+	}
+	if zones, _ := reloaded.ListZones(ctx); len(zones) != 2 {
+		t.Fatalf("zones after reload = %+v", zones)
+	}
+
+	if err := os.WriteFile(path, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(config.CloudProviderConfig{Type: LocalType, Options: map[string]string{"path": path}}); err == nil {
+		t.Fatal("corrupted data file was loaded")
+	}
+}
+
+func TestInitProviders(t *testing.T) {
+	t.Cleanup(func() { _ = InitProviders(nil) })
+
+	err := InitProviders([]config.CloudProviderConfig{
+		{Type: LocalType},
+		{Name: "staging", Type: LocalType, Options: map[string]string{"zones": "example.com"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if accounts := Accounts(); len(accounts) != 2 || accounts[0] != LocalType || accounts[1] != "staging" {
+		t.Fatalf("Accounts = %v", accounts)
+	}
+	client, err := GetProvider("staging")
+	if err != nil || TestConnection(context.Background(), client) != nil {
+		t.Fatalf("GetProvider = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		cfgs []config.CloudProviderConfig
+	}{
+		{"duplicate account", []config.CloudProviderConfig{{Type: LocalType}, {Name: LocalType, Type: LocalType}}},
+		{"unsupported type", []config.CloudProviderConfig{{Type: "route53"}}},
+		{"empty account", []config.CloudProviderConfig{{}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := InitProviders(tt.cfgs); err == nil {
+				t.Fatal("InitProviders succeeded")
+			}
+			// 初始化失败时保留原有的客户端
+			if _, err := GetProvider("staging"); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+
+	// path 只能在配置文件中设置
+	if keys, ok := APIOptions(LocalType); !ok || len(keys) != 1 || keys[0] != "zones" {
+		t.Fatalf("APIOptions = %v, %v", keys, ok)
+	}
+}
@@ -0,0 +1,189 @@
+package provider
+
+import (
+	"context"
+	"domain-admin/pkg/config"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrZoneNotFound 区域不存在
+var ErrZoneNotFound = errors.New("zone not found")
+
+// ErrRecordNotFound 记录不存在
+var ErrRecordNotFound = errors.New("record not found")
+
+// Zone DNS 区域
+type Zone struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Record DNS 记录
+// Value 保存记录的数据部分，MX/SRV 的优先级单独放在 Priority 中
+type Record struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Value    string `json:"value"`
+	TTL      int    `json:"ttl"`
+	Priority int    `json:"priority"`
+}
+
+// DNSProvider 云 DNS 服务商接口
+// zone 参数均为区域名称（如 example.com），不带末尾的点
+type DNSProvider interface {
+	// Type 返回服务商类型
+	Type() string
+	ListZones(ctx context.Context) ([]Zone, error)
+	ListRecords(ctx context.Context, zone string) ([]Record, error)
+	CreateRecord(ctx context.Context, zone string, record Record) (*Record, error)
+	UpdateRecord(ctx context.Context, zone string, record Record) (*Record, error)
+	DeleteRecord(ctx context.Context, zone string, recordID string) error
+}
+
+// ConnectionTester 服务商可选实现的连接测试接口
+// 未实现时通过 ListZones 检查凭据是否可用
+type ConnectionTester interface {
+	TestConnection(ctx context.Context) error
+}
+
+// Factory 根据账号配置创建服务商客户端
+type Factory func(cfg config.CloudProviderConfig) (DNSProvider, error)
+
+var (
+	factories  = make(map[string]Factory)
+	apiOptions = make(map[string][]string)
+	clients    = make(map[string]DNSProvider)
+	lock       sync.RWMutex
+)
+
+// Register 注册服务商类型，通常在实现包的 init 中调用
+func Register(typ string, factory Factory) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	typ = strings.ToLower(typ)
+	if _, ok := factories[typ]; ok {
+		panic(fmt.Sprintf("dns provider type %s already registered", typ))
+	}
+	factories[typ] = factory
+}
+
+// AllowAPIOptions 允许通过接口创建该类型的账号，keys 为接口可以设置的 options
+// 读写本地文件等只应由运维人员设置的参数不要列出；未调用时该类型只能在配置文件中使用
+func AllowAPIOptions(typ string, keys ...string) {
+	lock.Lock()
+	defer lock.Unlock()
+	apiOptions[strings.ToLower(typ)] = append([]string{}, keys...)
+}
+
+// APIOptions 返回接口可以设置的 options，该类型不允许通过接口创建时 ok 为 false
+func APIOptions(typ string) ([]string, bool) {
+	lock.RLock()
+	defer lock.RUnlock()
+	keys, ok := apiOptions[strings.ToLower(typ)]
+	return append([]string{}, keys...), ok
+}
+
+// New 根据账号配置创建服务商客户端
+func New(cfg config.CloudProviderConfig) (DNSProvider, error) {
+	lock.RLock()
+	factory, ok := factories[strings.ToLower(cfg.Type)]
+	lock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported dns provider type: %s", cfg.Type)
+	}
+	return factory(cfg)
+}
+
+// Types 返回已注册的服务商类型
+func Types() []string {
+	lock.RLock()
+	defer lock.RUnlock()
+	types := make([]string, 0, len(factories))
+	for typ := range factories {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// TestConnection 测试服务商客户端的连接和凭据
+func TestConnection(ctx context.Context, client DNSProvider) error {
+	if tester, ok := client.(ConnectionTester); ok {
+		return tester.TestConnection(ctx)
+	}
+	_, err := client.ListZones(ctx)
+	return err
+}
+
+// InitProviders 为每个配置的云服务商账号创建客户端
+func InitProviders(cfgs []config.CloudProviderConfig) error {
+	built := make(map[string]DNSProvider, len(cfgs))
+	for _, cfg := range cfgs {
+		name := cfg.AccountName()
+		if name == "" {
+			return errors.New("cloud provider account name and type are both empty")
+		}
+		if _, ok := built[name]; ok {
+			return fmt.Errorf("duplicate cloud provider account: %s", name)
+		}
+
+		client, err := New(cfg)
+		if err != nil {
+			return fmt.Errorf("init cloud provider account %s: %w", name, err)
+		}
+		built[name] = client
+	}
+
+	lock.Lock()
+	clients = built
+	lock.Unlock()
+	return nil
+}
+
+// SetProvider 注册或替换一个账号客户端
+func SetProvider(name string, client DNSProvider) {
+	lock.Lock()
+	defer lock.Unlock()
+	clients[name] = client
+}
+
+// RemoveProvider 移除一个账号客户端
+func RemoveProvider(name string) {
+	lock.Lock()
+	defer lock.Unlock()
+	delete(clients, name)
+}
+
+// GetProvider 根据账号名称获取客户端
+func GetProvider(name string) (DNSProvider, error) {
+	lock.RLock()
+	defer lock.RUnlock()
+	client, ok := clients[name]
+	if !ok {
+		return nil, fmt.Errorf("cloud provider account %s not found", name)
+	}
+	return client, nil
+}
+
+// Accounts 返回已初始化的账号名称列表
+func Accounts() []string {
+	lock.RLock()
+	defer lock.RUnlock()
+	names := make([]string, 0, len(clients))
+	for name := range clients {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NormalizeZone 规范化区域名称（小写、去掉末尾的点）
+func NormalizeZone(zone string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(zone)), ".")
+}
@@ -48,3 +48,12 @@ func SuccessWithPagination(c *gin.Context, data interface{}, total int64, page i
 		"page":    page,
 	})
 }
+
+// Accepted 请求已受理但尚未执行，如等待审批的变更
+func Accepted(c *gin.Context, msg string, data interface{}) {
+	c.JSON(http.StatusAccepted, gin.H{
+		"code":    202,
+		"message": msg,
+		"data":    data,
+	})
+}
@@ -0,0 +1,546 @@
+package scheduler
+
+import (
+	"context"
+	"domain-admin/pkg/cache"
+	"domain-admin/pkg/logger"
+	"domain-admin/pkg/utils"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// 任务触发方式
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+)
+
+// 任务执行状态
+const (
+	StatusRunning = "running"
+	StatusSuccess = "success"
+	StatusFailed  = "failed"
+)
+
+// DefaultTimeout 任务未设置超时时间时使用
+const DefaultTimeout = 10 * time.Minute
+
+// lockLease 任务锁的有效期，执行期间每隔三分之一有效期续期一次，实例异常退出时锁在有效期后自动释放
+var lockLease = time.Minute
+
+var (
+	ErrJobNotFound   = errors.New("任务不存在")
+	ErrJobExists     = errors.New("任务已存在")
+	ErrJobRunning    = errors.New("任务正在执行中")
+	ErrJobLocked     = errors.New("任务正在其他实例上执行")
+	ErrNotStarted    = errors.New("调度器未启动")
+	ErrNotConfigured = errors.New("调度器未初始化")
+)
+
+// Job 定时任务
+type Job struct {
+	Name        string
+	Description string
+	// Spec 标准5段 cron 表达式，也支持 @hourly、@daily、@every 1h 等写法
+	Spec    string
+	Timeout time.Duration
+	Run     func(ctx context.Context) error
+}
+
+// Execution 任务执行记录
+type Execution struct {
+	ID          uint
+	Job         string
+	Trigger     string
+	TriggeredBy uint
+	Instance    string
+	Status      string
+	Error       string
+	StartedAt   time.Time
+	FinishedAt  *time.Time
+}
+
+// Store 任务暂停状态和执行记录的持久化接口
+// 暂停状态保存在数据库中，以便多个实例共享
+type Store interface {
+	IsPaused(job string) (bool, error)
+	SetPaused(job string, paused bool) error
+	CreateExecution(e *Execution) error
+	FinishExecution(e *Execution) error
+}
+
+// JobInfo 任务状态
+type JobInfo struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Spec        string     `json:"spec"`
+	Paused      bool       `json:"paused"`
+	Running     bool       `json:"running"`
+	NextRun     *time.Time `json:"next_run"`
+	LastRun     *time.Time `json:"last_run"`
+	LastStatus  string     `json:"last_status"`
+}
+
+type entry struct {
+	job        Job
+	schedule   cron.Schedule
+	next       time.Time
+	running    bool
+	lastRun    *time.Time
+	lastStatus string
+}
+
+// Scheduler 定时任务调度器
+// 同一任务在整个执行期间持有 Redis 锁 job:<name>，手动执行和定时调度互斥，多个实例同时只有一个在执行
+type Scheduler struct {
+	store    Store
+	instance string
+
+	mu      sync.Mutex
+	entries map[string]*entry
+	wake    chan struct{}
+	cancel  context.CancelFunc
+	ctx     context.Context
+	wg      sync.WaitGroup
+}
+
+var defaultScheduler *Scheduler
+
+// New 创建调度器
+func New(store Store) *Scheduler {
+	host, _ := os.Hostname()
+	return &Scheduler{
+		store:    store,
+		instance: fmt.Sprintf("%s-%d", host, os.Getpid()),
+		entries:  make(map[string]*entry),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Init 初始化默认调度器
+func Init(store Store) *Scheduler {
+	defaultScheduler = New(store)
+	return defaultScheduler
+}
+
+// Default 获取默认调度器
+func Default() (*Scheduler, error) {
+	if defaultScheduler == nil {
+		return nil, ErrNotConfigured
+	}
+	return defaultScheduler, nil
+}
+
+// ParseSpec 校验并解析 cron 表达式
+func ParseSpec(spec string) (cron.Schedule, error) {
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return nil, fmt.Errorf("无效的cron表达式 %q: %w", spec, err)
+	}
+	return schedule, nil
+}
+
+// Register 注册任务
+func (s *Scheduler) Register(job Job) error {
+	if job.Name == "" || job.Run == nil {
+		return errors.New("任务名称和执行函数不能为空")
+	}
+	schedule, err := ParseSpec(job.Spec)
+	if err != nil {
+		return err
+	}
+	if job.Timeout <= 0 {
+		job.Timeout = DefaultTimeout
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.entries[job.Name]; ok {
+		return fmt.Errorf("%w: %s", ErrJobExists, job.Name)
+	}
+	e := &entry{job: job, schedule: schedule}
+	if s.ctx != nil {
+		e.next = schedule.Next(time.Now())
+		s.notify()
+	}
+	s.entries[job.Name] = e
+	return nil
+}
+
+// Start 启动调度循环
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	if s.ctx != nil {
+		s.mu.Unlock()
+		return
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	now := time.Now()
+	for _, e := range s.entries {
+		e.next = e.schedule.Next(now)
+	}
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go s.loop()
+	logger.Infof("定时任务调度器已启动，实例 %s", s.instance)
+	if !cache.DistributedLock() {
+		logger.Warnf("未连接Redis，任务锁只在当前实例内有效，多实例部署时每个实例都会执行定时任务")
+	}
+}
+
+// Stop 停止调度并等待执行中的任务结束
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	s.wg.Wait()
+	logger.Info("定时任务调度器已停止")
+}
+
+// Jobs 获取全部任务状态，按名称排序
+func (s *Scheduler) Jobs() []JobInfo {
+	s.mu.Lock()
+	infos := make([]JobInfo, 0, len(s.entries))
+	for _, e := range s.entries {
+		info := JobInfo{
+			Name:        e.job.Name,
+			Description: e.job.Description,
+			Spec:        e.job.Spec,
+			Running:     e.running,
+			LastRun:     e.lastRun,
+			LastStatus:  e.lastStatus,
+		}
+		if !e.next.IsZero() {
+			next := e.next
+			info.NextRun = &next
+		}
+		infos = append(infos, info)
+	}
+	s.mu.Unlock()
+
+	for i := range infos {
+		paused, err := s.store.IsPaused(infos[i].Name)
+		if err != nil {
+			logger.Warnf("查询任务 %s 暂停状态失败: %v", infos[i].Name, err)
+		}
+		infos[i].Paused = paused
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+	return infos
+}
+
+// Trigger 立即异步执行任务，暂停中的任务也可以手动执行
+func (s *Scheduler) Trigger(name string, triggeredBy uint) (*Execution, error) {
+	s.mu.Lock()
+	e, ok := s.entries[name]
+	if !ok {
+		s.mu.Unlock()
+		return nil, ErrJobNotFound
+	}
+	if s.ctx == nil {
+		s.mu.Unlock()
+		return nil, ErrNotStarted
+	}
+	if e.running {
+		s.mu.Unlock()
+		return nil, ErrJobRunning
+	}
+	e.running = true
+	s.mu.Unlock()
+
+	lock, err := s.lock(name)
+	if err != nil {
+		s.setRunning(e, false)
+		return nil, err
+	}
+
+	exec, err := s.begin(e, TriggerManual, triggeredBy)
+	if err != nil {
+		s.setRunning(e, false)
+		s.unlock(lock)
+		return nil, err
+	}
+
+	// 执行过程中会修改 exec，先复制一份返回
+	copied := *exec
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.execute(e, exec, lock)
+	}()
+
+	return &copied, nil
+}
+
+// Pause 暂停任务的定时调度
+func (s *Scheduler) Pause(name string) error {
+	return s.setPaused(name, true)
+}
+
+// Resume 恢复任务的定时调度
+func (s *Scheduler) Resume(name string) error {
+	return s.setPaused(name, false)
+}
+
+func (s *Scheduler) setPaused(name string, paused bool) error {
+	s.mu.Lock()
+	_, ok := s.entries[name]
+	s.mu.Unlock()
+	if !ok {
+		return ErrJobNotFound
+	}
+	return s.store.SetPaused(name, paused)
+}
+
+// loop 调度主循环，按最近的下次执行时间休眠
+func (s *Scheduler) loop() {
+	defer s.wg.Done()
+
+	for {
+		s.mu.Lock()
+		var next time.Time
+		for _, e := range s.entries {
+			// 永远不会触发的表达式（如 2 月 30 日）下次执行时间为零值
+			if e.next.IsZero() {
+				continue
+			}
+			if next.IsZero() || e.next.Before(next) {
+				next = e.next
+			}
+		}
+		s.mu.Unlock()
+
+		wait := time.Hour
+		if !next.IsZero() {
+			wait = time.Until(next)
+		}
+		timer := time.NewTimer(wait)
+
+		select {
+		case <-s.ctx.Done():
+			timer.Stop()
+			return
+		case <-s.wake:
+			timer.Stop()
+			continue
+		case <-timer.C:
+		}
+
+		now := time.Now()
+		s.mu.Lock()
+		for _, e := range s.entries {
+			if e.next.IsZero() || e.next.After(now) {
+				continue
+			}
+			tick := e.next
+			e.next = e.schedule.Next(now)
+			s.wg.Add(1)
+			go func(e *entry, tick time.Time) {
+				defer s.wg.Done()
+				s.runScheduled(e, tick)
+			}(e, tick)
+		}
+		s.mu.Unlock()
+	}
+}
+
+// runScheduled 执行一次定时调度
+// 任务正在其他实例上执行或被手动执行时跳过本次调度
+func (s *Scheduler) runScheduled(e *entry, tick time.Time) {
+	name := e.job.Name
+
+	paused, err := s.store.IsPaused(name)
+	if err != nil {
+		logger.Errorf("查询任务 %s 暂停状态失败: %v", name, err)
+		return
+	}
+	if paused {
+		logger.Debugf("任务 %s 已暂停，跳过本次调度", name)
+		return
+	}
+
+	s.mu.Lock()
+	if e.running {
+		s.mu.Unlock()
+		logger.Warnf("任务 %s 上一次执行尚未结束，跳过本次调度", name)
+		return
+	}
+	e.running = true
+	s.mu.Unlock()
+
+	lock, err := s.lock(name)
+	if err != nil {
+		s.setRunning(e, false)
+		if errors.Is(err, ErrJobLocked) {
+			logger.Infof("任务 %s 正在执行中，跳过本次调度", name)
+		} else {
+			logger.Errorf("任务 %s %v", name, err)
+		}
+		return
+	}
+
+	// 各实例的时钟存在偏差，先执行完的实例释放任务锁后，其他实例仍可能为同一调度时间点获得锁。
+	// 调度时间点的标记执行后不释放，使同一时间点只执行一次
+	claimed, err := cache.AcquireLock(s.ctx, fmt.Sprintf("job:%s:%d", name, tick.Unix()), s.instance, e.job.Timeout)
+	if err != nil || !claimed {
+		s.unlock(lock)
+		s.setRunning(e, false)
+		if err != nil {
+			logger.Errorf("标记任务 %s 的调度时间点失败: %v", name, err)
+		}
+		return
+	}
+
+	exec, err := s.begin(e, TriggerSchedule, 0)
+	if err != nil {
+		s.unlock(lock)
+		s.setRunning(e, false)
+		logger.Errorf("创建任务 %s 的执行记录失败: %v", name, err)
+		return
+	}
+	s.execute(e, exec, lock)
+}
+
+// jobLock 任务执行期间持有的锁
+type jobLock struct {
+	key   string
+	token string
+}
+
+// lock 获取任务锁，手动执行和定时调度使用同一个锁，锁被持有时返回 ErrJobLocked
+func (s *Scheduler) lock(name string) (*jobLock, error) {
+	lock := &jobLock{key: "job:" + name, token: s.instance + ":" + utils.RandomString(16)}
+	locked, err := cache.AcquireLock(s.ctx, lock.key, lock.token, lockLease)
+	if err != nil {
+		return nil, fmt.Errorf("获取任务锁失败: %w", err)
+	}
+	if !locked {
+		return nil, ErrJobLocked
+	}
+	return lock, nil
+}
+
+func (s *Scheduler) unlock(lock *jobLock) {
+	if err := cache.ReleaseLock(context.Background(), lock.key, lock.token); err != nil {
+		logger.Warnf("释放任务锁 %s 失败: %v", lock.key, err)
+	}
+}
+
+// keepLock 定期续期任务锁直到 done 关闭
+// 锁已丢失时取消本次执行，避免与获得锁的其他实例同时执行
+func (s *Scheduler) keepLock(lock *jobLock, done <-chan struct{}, cancel context.CancelFunc) {
+	ticker := time.NewTicker(lockLease / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+		renewed, err := cache.RenewLock(context.Background(), lock.key, lock.token, lockLease)
+		if err != nil {
+			// 续期失败时锁在有效期内仍然有效，下次再试
+			logger.Warnf("续期任务锁 %s 失败: %v", lock.key, err)
+			continue
+		}
+		if !renewed {
+			logger.Errorf("任务锁 %s 已丢失，取消本次执行", lock.key)
+			cancel()
+			return
+		}
+	}
+}
+
+// begin 创建执行记录
+func (s *Scheduler) begin(e *entry, trigger string, triggeredBy uint) (*Execution, error) {
+	exec := &Execution{
+		Job:         e.job.Name,
+		Trigger:     trigger,
+		TriggeredBy: triggeredBy,
+		Instance:    s.instance,
+		Status:      StatusRunning,
+		StartedAt:   time.Now(),
+	}
+	if err := s.store.CreateExecution(exec); err != nil {
+		return nil, err
+	}
+	return exec, nil
+}
+
+// execute 执行任务并保存结果，任务中的 panic 视为执行失败
+// 执行期间续期任务锁，结束后释放
+func (s *Scheduler) execute(e *entry, exec *Execution, lock *jobLock) {
+	ctx, cancel := context.WithTimeout(s.ctx, e.job.Timeout)
+	defer cancel()
+
+	done := make(chan struct{})
+	var keeper sync.WaitGroup
+	keeper.Add(1)
+	go func() {
+		defer keeper.Done()
+		s.keepLock(lock, done, cancel)
+	}()
+
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}()
+		return e.job.Run(ctx)
+	}()
+
+	close(done)
+	keeper.Wait()
+	s.unlock(lock)
+
+	finished := time.Now()
+	exec.FinishedAt = &finished
+	exec.Status = StatusSuccess
+	if err != nil {
+		exec.Status = StatusFailed
+		exec.Error = err.Error()
+		logger.Errorf("任务 %s 执行失败: %v", exec.Job, err)
+	} else {
+		logger.Infof("任务 %s 执行完成，耗时 %s", exec.Job, finished.Sub(exec.StartedAt))
+	}
+
+	if err := s.store.FinishExecution(exec); err != nil {
+		logger.Errorf("保存任务 %s 的执行结果失败: %v", exec.Job, err)
+	}
+
+	s.mu.Lock()
+	e.running = false
+	started := exec.StartedAt
+	e.lastRun = &started
+	e.lastStatus = exec.Status
+	s.mu.Unlock()
+}
+
+func (s *Scheduler) setRunning(e *entry, running bool) {
+	s.mu.Lock()
+	e.running = running
+	s.mu.Unlock()
+}
+
+// notify 唤醒调度循环重新计算下次执行时间，调用方需持有锁
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,326 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"domain-admin/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	logger.Log = zap.NewNop()
+	m.Run()
+}
+
+// memStore 保存在内存中的 Store
+type memStore struct {
+	mu         sync.Mutex
+	paused     map[string]bool
+	executions []Execution
+}
+
+func newMemStore() *memStore {
+	return &memStore{paused: make(map[string]bool)}
+}
+
+func (m *memStore) IsPaused(job string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.paused[job], nil
+}
+
+func (m *memStore) SetPaused(job string, paused bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.paused[job] = paused
+	return nil
+}
+
+func (m *memStore) CreateExecution(e *Execution) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.executions = append(m.executions, *e)
+	e.ID = uint(len(m.executions))
+	return nil
+}
+
+func (m *memStore) FinishExecution(e *Execution) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.executions[e.ID-1] = *e
+	return nil
+}
+
+func (m *memStore) finished() []Execution {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []Execution
+	for _, e := range m.executions {
+		if e.FinishedAt != nil {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// blockingJob 执行时阻塞到 release 关闭或 ctx 取消
+type blockingJob struct {
+	started chan struct{}
+	release chan struct{}
+	runs    chan error
+}
+
+func newBlockingJob() *blockingJob {
+	return &blockingJob{started: make(chan struct{}, 10), release: make(chan struct{}), runs: make(chan error, 10)}
+}
+
+func (b *blockingJob) run(ctx context.Context) error {
+	b.started <- struct{}{}
+	var err error
+	select {
+	case <-b.release:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	b.runs <- err
+	return err
+}
+
+// newTestScheduler 创建并启动调度器，模拟一个实例；同一进程内的调度器共享进程内锁
+func newTestScheduler(t *testing.T, store Store, jobs ...Job) *Scheduler {
+	t.Helper()
+	s := New(store)
+	for _, job := range jobs {
+		if err := s.Register(job); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s.Start()
+	t.Cleanup(s.Stop)
+	return s
+}
+
+// setLockLease 缩短锁的有效期，在调度器停止后恢复
+func setLockLease(t *testing.T, lease time.Duration) {
+	t.Helper()
+	previous := lockLease
+	lockLease = lease
+	t.Cleanup(func() { lockLease = previous })
+}
+
+func waitFor(t *testing.T, ch <-chan struct{}) {
+	t.Helper()
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out")
+	}
+}
+
+func waitRun(t *testing.T, ch <-chan error) error {
+	t.Helper()
+	select {
+	case err := <-ch:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out")
+		return nil
+	}
+}
+
+// waitIdle 等待任务在调度器上执行结束
+func waitIdle(t *testing.T, s *Scheduler, name string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		s.mu.Lock()
+		running := s.entries[name].running
+		s.mu.Unlock()
+		if !running {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("job %s still running", name)
+}
+
+func TestManualAndScheduledShareLock(t *testing.T) {
+	job := newBlockingJob()
+	spec := Job{Name: "shared_lock", Spec: "@every 1h", Run: job.run}
+	storeA, storeB := newMemStore(), newMemStore()
+	a := newTestScheduler(t, storeA, spec)
+	b := newTestScheduler(t, storeB, spec)
+
+	if _, err := a.Trigger("shared_lock", 1); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, job.started)
+
+	// 同一实例上再次手动执行
+	if _, err := a.Trigger("shared_lock", 1); !errors.Is(err, ErrJobRunning) {
+		t.Fatalf("second trigger on same instance: err = %v", err)
+	}
+	// 其他实例的手动执行和定时调度都不能同时执行
+	if _, err := b.Trigger("shared_lock", 1); !errors.Is(err, ErrJobLocked) {
+		t.Fatalf("trigger on other instance: err = %v", err)
+	}
+	b.runScheduled(b.entries["shared_lock"], time.Now().Truncate(time.Hour))
+	if len(storeB.executions) != 0 {
+		t.Fatalf("scheduled run overlapped manual run: %+v", storeB.executions)
+	}
+
+	close(job.release)
+	if err := waitRun(t, job.runs); err != nil {
+		t.Fatal(err)
+	}
+	waitIdle(t, a, "shared_lock")
+
+	// 锁在执行结束后释放
+	exec, err := b.Trigger("shared_lock", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exec.Trigger != TriggerManual || exec.TriggeredBy != 2 {
+		t.Fatalf("exec = %+v", exec)
+	}
+	if err := waitRun(t, job.runs); err != nil {
+		t.Fatal(err)
+	}
+	waitIdle(t, b, "shared_lock")
+	if got := storeA.finished(); len(got) != 1 || got[0].Status != StatusSuccess {
+		t.Fatalf("executions on a = %+v", got)
+	}
+}
+
+func TestScheduledTickRunsOnce(t *testing.T) {
+	runs := make(chan error, 10)
+	spec := Job{Name: "tick_once", Spec: "@every 1h", Run: func(ctx context.Context) error {
+		runs <- nil
+		return nil
+	}}
+	storeA, storeB := newMemStore(), newMemStore()
+	a := newTestScheduler(t, storeA, spec)
+	b := newTestScheduler(t, storeB, spec)
+
+	// 调度时间点的标记在执行后保留，每次测试使用不同的时间点
+	tick := time.Unix(time.Now().UnixNano(), 0)
+	a.runScheduled(a.entries["tick_once"], tick)
+	// 时钟较慢的实例在 a 执行结束后才到达同一调度时间点
+	b.runScheduled(b.entries["tick_once"], tick)
+	if len(storeA.finished()) != 1 || len(storeB.executions) != 0 {
+		t.Fatalf("a = %+v, b = %+v", storeA.executions, storeB.executions)
+	}
+
+	b.runScheduled(b.entries["tick_once"], tick.Add(time.Hour))
+	if got := storeB.finished(); len(got) != 1 || got[0].Trigger != TriggerSchedule {
+		t.Fatalf("next tick on b = %+v", got)
+	}
+	if len(runs) != 2 {
+		t.Fatalf("job ran %d times, want 2", len(runs))
+	}
+}
+
+func TestLockRenewedDuringRun(t *testing.T) {
+	setLockLease(t, 60*time.Millisecond)
+
+	job := newBlockingJob()
+	spec := Job{Name: "long_running", Spec: "@every 1h", Run: job.run}
+	a := newTestScheduler(t, newMemStore(), spec)
+	b := newTestScheduler(t, newMemStore(), spec)
+
+	if _, err := a.Trigger("long_running", 0); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, job.started)
+
+	// 超过锁的有效期后锁仍由执行中的实例持有
+	time.Sleep(4 * lockLease)
+	if _, err := b.Trigger("long_running", 0); !errors.Is(err, ErrJobLocked) {
+		t.Fatalf("lock expired during run: err = %v", err)
+	}
+
+	close(job.release)
+	if err := waitRun(t, job.runs); err != nil {
+		t.Fatal(err)
+	}
+	waitIdle(t, a, "long_running")
+	if _, err := b.Trigger("long_running", 0); err != nil {
+		t.Fatalf("lock not released after run: %v", err)
+	}
+	if err := waitRun(t, job.runs); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLostLockCancelsRun(t *testing.T) {
+	setLockLease(t, 60*time.Millisecond)
+
+	job := newBlockingJob()
+	store := newMemStore()
+	s := newTestScheduler(t, store, Job{Name: "lost_lock", Spec: "@every 1h", Run: job.run})
+	e := s.entries["lost_lock"]
+	s.setRunning(e, true)
+
+	exec, err := s.begin(e, TriggerManual, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 持有的令牌与锁中保存的不一致，相当于锁已过期并被其他实例获取
+	stolen := &jobLock{key: "job:lost_lock", token: "stolen"}
+	go s.execute(e, exec, stolen)
+
+	if err := waitRun(t, job.runs); !errors.Is(err, context.Canceled) {
+		t.Fatalf("run err = %v, want canceled", err)
+	}
+	waitIdle(t, s, "lost_lock")
+	if got := store.finished(); len(got) != 1 || got[0].Status != StatusFailed {
+		t.Fatalf("executions = %+v", got)
+	}
+}
+
+func TestPausedAndFailingJobs(t *testing.T) {
+	var runs atomic.Int32
+	store := newMemStore()
+	s := newTestScheduler(t, store, Job{Name: "flaky", Spec: "@every 1h", Run: func(ctx context.Context) error {
+		runs.Add(1)
+		panic("boom")
+	}})
+
+	if err := s.Pause("flaky"); err != nil {
+		t.Fatal(err)
+	}
+	s.runScheduled(s.entries["flaky"], time.Now())
+	if runs.Load() != 0 {
+		t.Fatal("paused job ran on schedule")
+	}
+
+	// 暂停中的任务可以手动执行，panic 记为执行失败
+	if _, err := s.Trigger("flaky", 1); err != nil {
+		t.Fatal(err)
+	}
+	waitIdle(t, s, "flaky")
+	got := store.finished()
+	if len(got) != 1 || got[0].Status != StatusFailed || got[0].Error != "panic: boom" {
+		t.Fatalf("executions = %+v", got)
+	}
+	infos := s.Jobs()
+	if len(infos) != 1 || !infos[0].Paused || infos[0].LastStatus != StatusFailed {
+		t.Fatalf("jobs = %+v", infos)
+	}
+
+	if _, err := s.Trigger("missing", 1); !errors.Is(err, ErrJobNotFound) {
+		t.Fatalf("err = %v", err)
+	}
+	if err := s.Register(Job{Name: "flaky", Spec: "@every 1h", Run: func(context.Context) error { return nil }}); !errors.Is(err, ErrJobExists) {
+		t.Fatalf("err = %v", err)
+	}
+	if err := s.Register(Job{Name: "bad", Spec: "not cron", Run: func(context.Context) error { return nil }}); err == nil {
+		t.Fatal("invalid spec should fail")
+	}
+}
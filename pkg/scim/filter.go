@@ -0,0 +1,410 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Filter 解析后的过滤表达式，对资源的 JSON 表示求值
+type Filter interface {
+	Match(resource map[string]interface{}) bool
+}
+
+// ParseFilter 解析 RFC 7644 3.4.2.2 定义的过滤表达式，支持 and / or / not、括号、
+// eq ne co sw ew gt ge lt le pr 运算符和 emails[type eq "work"] 形式的值路径。
+// 属性名不区分大小写，字符串比较不区分大小写
+func ParseFilter(expr string) (Filter, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	f, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("scim: unexpected %q in filter", p.tokens[p.pos].text)
+	}
+	return f, nil
+}
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenString
+	tokenLParen
+	tokenRParen
+	tokenLBracket
+	tokenRBracket
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+func tokenize(expr string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(expr); {
+		switch c := expr[i]; c {
+		case ' ', '\t', '\n', '\r':
+			i++
+		case '(':
+			tokens = append(tokens, token{tokenLParen, "("})
+			i++
+		case ')':
+			tokens = append(tokens, token{tokenRParen, ")"})
+			i++
+		case '[':
+			tokens = append(tokens, token{tokenLBracket, "["})
+			i++
+		case ']':
+			tokens = append(tokens, token{tokenRBracket, "]"})
+			i++
+		case '"':
+			j := i + 1
+			for ; j < len(expr) && expr[j] != '"'; j++ {
+				if expr[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(expr) {
+				return nil, fmt.Errorf("scim: unterminated string in filter")
+			}
+			var s string
+			if err := json.Unmarshal([]byte(expr[i:j+1]), &s); err != nil {
+				return nil, fmt.Errorf("scim: invalid string %s in filter", expr[i:j+1])
+			}
+			tokens = append(tokens, token{tokenString, s})
+			i = j + 1
+		default:
+			j := i
+			for j < len(expr) && !strings.ContainsRune(" \t\n\r()[]\"", rune(expr[j])) {
+				j++
+			}
+			tokens = append(tokens, token{tokenWord, expr[i:j]})
+			i = j
+		}
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("scim: empty filter")
+	}
+	return tokens, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() *token {
+	if p.pos < len(p.tokens) {
+		return &p.tokens[p.pos]
+	}
+	return nil
+}
+
+func (p *parser) keyword(word string) bool {
+	t := p.peek()
+	if t != nil && t.kind == tokenWord && strings.EqualFold(t.text, word) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(kind tokenKind, text string) error {
+	t := p.peek()
+	if t == nil || t.kind != kind {
+		return fmt.Errorf("scim: expected %q in filter", text)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *parser) parseOr() (Filter, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orFilter{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Filter, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = andFilter{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (Filter, error) {
+	if t := p.peek(); t != nil && t.kind == tokenWord && strings.EqualFold(t.text, "not") &&
+		p.pos+1 < len(p.tokens) && p.tokens[p.pos+1].kind == tokenLParen {
+		p.pos++
+		inner, err := p.parseAtom()
+		if err != nil {
+			return nil, err
+		}
+		return notFilter{inner}, nil
+	}
+	return p.parseAtom()
+}
+
+func (p *parser) parseAtom() (Filter, error) {
+	t := p.peek()
+	if t == nil {
+		return nil, fmt.Errorf("scim: unexpected end of filter")
+	}
+	if t.kind == tokenLParen {
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return inner, p.expect(tokenRParen, ")")
+	}
+	if t.kind != tokenWord {
+		return nil, fmt.Errorf("scim: unexpected %q in filter", t.text)
+	}
+	p.pos++
+	path, err := parseAttrPath(t.text)
+	if err != nil {
+		return nil, err
+	}
+
+	if next := p.peek(); next != nil && next.kind == tokenLBracket {
+		if path.Sub != "" {
+			return nil, fmt.Errorf("scim: invalid value path %q", t.text)
+		}
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenRBracket, "]"); err != nil {
+			return nil, err
+		}
+		return valuePathFilter{attr: path.Attr, filter: inner}, nil
+	}
+
+	op := p.peek()
+	if op == nil || op.kind != tokenWord {
+		return nil, fmt.Errorf("scim: missing operator after %q", t.text)
+	}
+	p.pos++
+	operator := strings.ToLower(op.text)
+	if operator == "pr" {
+		return presentFilter{path}, nil
+	}
+	switch operator {
+	case "eq", "ne", "co", "sw", "ew", "gt", "ge", "lt", "le":
+	default:
+		return nil, fmt.Errorf("scim: unsupported operator %q", op.text)
+	}
+
+	v := p.peek()
+	if v == nil || (v.kind != tokenString && v.kind != tokenWord) {
+		return nil, fmt.Errorf("scim: missing value after %q", op.text)
+	}
+	p.pos++
+	value, err := parseValue(*v)
+	if err != nil {
+		return nil, err
+	}
+	return compareFilter{path: path, op: operator, value: value}, nil
+}
+
+func parseValue(t token) (interface{}, error) {
+	if t.kind == tokenString {
+		return t.text, nil
+	}
+	switch strings.ToLower(t.text) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+	n, err := strconv.ParseFloat(t.text, 64)
+	if err != nil {
+		return nil, fmt.Errorf("scim: invalid value %q in filter", t.text)
+	}
+	return n, nil
+}
+
+type andFilter struct{ left, right Filter }
+
+func (f andFilter) Match(r map[string]interface{}) bool { return f.left.Match(r) && f.right.Match(r) }
+
+type orFilter struct{ left, right Filter }
+
+func (f orFilter) Match(r map[string]interface{}) bool { return f.left.Match(r) || f.right.Match(r) }
+
+type notFilter struct{ inner Filter }
+
+func (f notFilter) Match(r map[string]interface{}) bool { return !f.inner.Match(r) }
+
+type presentFilter struct{ path AttrPath }
+
+func (f presentFilter) Match(r map[string]interface{}) bool {
+	for _, v := range f.path.values(r) {
+		if !isEmpty(v) {
+			return true
+		}
+	}
+	return false
+}
+
+type valuePathFilter struct {
+	attr   string
+	filter Filter
+}
+
+func (f valuePathFilter) Match(r map[string]interface{}) bool {
+	for _, item := range asSlice(lookup(r, f.attr)) {
+		if m, ok := item.(map[string]interface{}); ok && f.filter.Match(m) {
+			return true
+		}
+	}
+	return false
+}
+
+type compareFilter struct {
+	path  AttrPath
+	op    string
+	value interface{}
+}
+
+func (f compareFilter) Match(r map[string]interface{}) bool {
+	values := f.path.values(r)
+	if f.op == "ne" {
+		for _, v := range values {
+			if compare(v, "eq", f.value) {
+				return false
+			}
+		}
+		return true
+	}
+	if f.value == nil && f.op == "eq" {
+		for _, v := range values {
+			if !isEmpty(v) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, v := range values {
+		if compare(v, f.op, f.value) {
+			return true
+		}
+	}
+	return false
+}
+
+// compare 比较资源中的值和表达式中的值，类型不一致时不匹配
+func compare(actual interface{}, op string, expected interface{}) bool {
+	switch want := expected.(type) {
+	case string:
+		got, ok := actual.(string)
+		if !ok {
+			return false
+		}
+		got, want = strings.ToLower(got), strings.ToLower(want)
+		switch op {
+		case "eq":
+			return got == want
+		case "co":
+			return strings.Contains(got, want)
+		case "sw":
+			return strings.HasPrefix(got, want)
+		case "ew":
+			return strings.HasSuffix(got, want)
+		case "gt":
+			return got > want
+		case "ge":
+			return got >= want
+		case "lt":
+			return got < want
+		case "le":
+			return got <= want
+		}
+	case bool:
+		got, ok := actual.(bool)
+		return ok && op == "eq" && got == want
+	case float64:
+		got, ok := actual.(float64)
+		if !ok {
+			return false
+		}
+		switch op {
+		case "eq":
+			return got == want
+		case "gt":
+			return got > want
+		case "ge":
+			return got >= want
+		case "lt":
+			return got < want
+		case "le":
+			return got <= want
+		}
+	}
+	return false
+}
+
+func isEmpty(v interface{}) bool {
+	switch x := v.(type) {
+	case nil:
+		return true
+	case string:
+		return x == ""
+	case []interface{}:
+		return len(x) == 0
+	case map[string]interface{}:
+		return len(x) == 0
+	}
+	return false
+}
+
+func asSlice(v interface{}) []interface{} {
+	switch x := v.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		return x
+	}
+	return []interface{}{v}
+}
+
+// lookup 不区分大小写地读取属性
+func lookup(m map[string]interface{}, name string) interface{} {
+	if v, ok := m[name]; ok {
+		return v
+	}
+	for key, v := range m {
+		if strings.EqualFold(key, name) {
+			return v
+		}
+	}
+	return nil
+}
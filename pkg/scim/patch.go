@@ -0,0 +1,309 @@
+package scim
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AttrPath 属性路径，Sub 为子属性，如 name.givenName
+type AttrPath struct {
+	Attr string
+	Sub  string
+}
+
+// Path PATCH 操作的目标路径，Filter 不为空时只作用于多值属性中匹配的元素，如 emails[type eq "work"].value
+type Path struct {
+	AttrPath
+	Filter Filter
+}
+
+// PatchOperation PATCH 请求中的单个操作
+type PatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// PatchRequest PATCH 请求
+type PatchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations"`
+}
+
+// parseAttrPath 解析属性路径，去掉 schema URN 前缀
+func parseAttrPath(s string) (AttrPath, error) {
+	if i := strings.LastIndex(s, ":"); i >= 0 {
+		s = s[i+1:]
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) > 2 {
+		return AttrPath{}, fmt.Errorf("scim: invalid attribute path %q", s)
+	}
+	for _, part := range parts {
+		if !validAttrName(part) {
+			return AttrPath{}, fmt.Errorf("scim: invalid attribute path %q", s)
+		}
+	}
+	path := AttrPath{Attr: parts[0]}
+	if len(parts) == 2 {
+		path.Sub = parts[1]
+	}
+	return path, nil
+}
+
+func validAttrName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, c := range name {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '$':
+		case i > 0 && (c >= '0' && c <= '9' || c == '_' || c == '-'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// values 返回路径在资源中的值。多值复杂属性没有指定子属性时取各元素的 value
+func (p AttrPath) values(r map[string]interface{}) []interface{} {
+	var result []interface{}
+	for _, item := range asSlice(lookup(r, p.Attr)) {
+		m, isMap := item.(map[string]interface{})
+		switch {
+		case p.Sub != "" && isMap:
+			result = append(result, asSlice(lookup(m, p.Sub))...)
+		case p.Sub == "" && isMap:
+			if v := lookup(m, "value"); v != nil {
+				result = append(result, v)
+			}
+		case p.Sub == "":
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// ParsePath 解析 PATCH 操作的路径：attrPath 或 attrPath[valFilter] 及可选的 .subAttr
+func ParsePath(s string) (*Path, error) {
+	s = strings.TrimSpace(s)
+	open := strings.Index(s, "[")
+	if open < 0 {
+		attr, err := parseAttrPath(s)
+		if err != nil {
+			return nil, err
+		}
+		return &Path{AttrPath: attr}, nil
+	}
+
+	closing := strings.LastIndex(s, "]")
+	if closing < open {
+		return nil, fmt.Errorf("scim: invalid path %q", s)
+	}
+	attr, err := parseAttrPath(s[:open])
+	if err != nil || attr.Sub != "" {
+		return nil, fmt.Errorf("scim: invalid path %q", s)
+	}
+	filter, err := ParseFilter(s[open+1 : closing])
+	if err != nil {
+		return nil, err
+	}
+	path := &Path{AttrPath: attr, Filter: filter}
+	if rest := s[closing+1:]; rest != "" {
+		if !strings.HasPrefix(rest, ".") || !validAttrName(rest[1:]) {
+			return nil, fmt.Errorf("scim: invalid path %q", s)
+		}
+		path.Sub = rest[1:]
+	}
+	return path, nil
+}
+
+// Apply 对资源的 JSON 表示应用 PATCH 操作。没有 path 的 add / replace 按 value 中的每个属性分别处理
+func Apply(resource map[string]interface{}, op PatchOperation) error {
+	kind := strings.ToLower(op.Op)
+	switch kind {
+	case "add", "replace", "remove":
+	default:
+		return BadRequest(ErrInvalidSyntax, "不支持的PATCH操作 %q", op.Op)
+	}
+
+	if op.Path == "" {
+		if kind == "remove" {
+			return BadRequest(ErrNoTarget, "remove 操作必须指定 path")
+		}
+		values, ok := op.Value.(map[string]interface{})
+		if !ok {
+			return BadRequest(ErrInvalidValue, "没有 path 的 %s 操作的 value 必须是对象", op.Op)
+		}
+		for key, value := range values {
+			path, err := ParsePath(key)
+			if err != nil {
+				return BadRequest(ErrInvalidPath, "属性 %q 无效", key)
+			}
+			if err := applyPath(resource, kind, path, value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	path, err := ParsePath(op.Path)
+	if err != nil {
+		return BadRequest(ErrInvalidPath, "路径 %q 无效", op.Path)
+	}
+	if kind != "remove" && op.Value == nil {
+		return BadRequest(ErrInvalidValue, "%s 操作缺少 value", op.Op)
+	}
+	return applyPath(resource, kind, path, op.Value)
+}
+
+func applyPath(resource map[string]interface{}, kind string, path *Path, value interface{}) error {
+	key := findKey(resource, path.Attr)
+	if path.Filter != nil {
+		return applyFiltered(resource, key, kind, path, value)
+	}
+
+	if path.Sub != "" {
+		switch container := resource[key].(type) {
+		case map[string]interface{}:
+			setOrDelete(container, kind, path.Sub, value)
+		case []interface{}:
+			for _, item := range container {
+				if m, ok := item.(map[string]interface{}); ok {
+					setOrDelete(m, kind, path.Sub, value)
+				}
+			}
+		case nil:
+			if kind != "remove" {
+				resource[key] = map[string]interface{}{path.Sub: value}
+			}
+		default:
+			return BadRequest(ErrInvalidPath, "属性 %s 没有子属性", path.Attr)
+		}
+		return nil
+	}
+
+	existing := resource[key]
+	switch {
+	case kind == "remove":
+		// 部分身份提供方在 value 中列出要从多值属性中删除的元素
+		if items, ok := existing.([]interface{}); ok && value != nil {
+			resource[key] = removeItems(items, asSlice(value))
+		} else {
+			delete(resource, key)
+		}
+	case isMap(existing) && isMap(value):
+		// 复杂属性的 add 和 replace 都只修改提供的子属性
+		target := existing.(map[string]interface{})
+		for sub, v := range value.(map[string]interface{}) {
+			target[findKey(target, sub)] = v
+		}
+	case kind == "add" && isSlice(existing):
+		resource[key] = append(existing.([]interface{}), asSlice(value)...)
+	default:
+		resource[key] = value
+	}
+	return nil
+}
+
+// applyFiltered 修改多值属性中匹配过滤条件的元素
+func applyFiltered(resource map[string]interface{}, key, kind string, path *Path, value interface{}) error {
+	items := asSlice(resource[key])
+	matched := false
+	kept := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok || !path.Filter.Match(m) {
+			kept = append(kept, item)
+			continue
+		}
+		matched = true
+		switch {
+		case kind == "remove" && path.Sub == "":
+			continue
+		case path.Sub != "":
+			setOrDelete(m, kind, path.Sub, value)
+		case isMap(value):
+			for sub, v := range value.(map[string]interface{}) {
+				m[findKey(m, sub)] = v
+			}
+		default:
+			return BadRequest(ErrInvalidValue, "%s 的 value 必须是对象", path.Attr)
+		}
+		kept = append(kept, m)
+	}
+
+	if !matched && kind != "remove" {
+		// 目标元素不存在时，按 attr[type eq "work"].value 的条件创建元素
+		cmp, ok := path.Filter.(compareFilter)
+		if !ok || cmp.op != "eq" || cmp.path.Sub != "" || path.Sub == "" {
+			return BadRequest(ErrNoTarget, "%s 中没有匹配的元素", path.Attr)
+		}
+		kept = append(kept, map[string]interface{}{cmp.path.Attr: cmp.value, path.Sub: value})
+	}
+	resource[key] = kept
+	return nil
+}
+
+// removeItems 删除 value 与 targets 中任一元素的 value 相同的元素
+func removeItems(items, targets []interface{}) []interface{} {
+	var values []interface{}
+	for _, target := range targets {
+		if m, ok := target.(map[string]interface{}); ok {
+			values = append(values, lookup(m, "value"))
+		} else {
+			values = append(values, target)
+		}
+	}
+	kept := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		v := item
+		if m, ok := item.(map[string]interface{}); ok {
+			v = lookup(m, "value")
+		}
+		remove := false
+		for _, target := range values {
+			if compare(v, "eq", target) {
+				remove = true
+				break
+			}
+		}
+		if !remove {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+func setOrDelete(m map[string]interface{}, kind, name string, value interface{}) {
+	key := findKey(m, name)
+	if kind == "remove" {
+		delete(m, key)
+		return
+	}
+	m[key] = value
+}
+
+// findKey 返回不区分大小写匹配的已有属性名，不存在时返回 name
+func findKey(m map[string]interface{}, name string) string {
+	if _, ok := m[name]; ok {
+		return name
+	}
+	for key := range m {
+		if strings.EqualFold(key, name) {
+			return key
+		}
+	}
+	return name
+}
+
+func isMap(v interface{}) bool {
+	_, ok := v.(map[string]interface{})
+	return ok
+}
+
+func isSlice(v interface{}) bool {
+	_, ok := v.([]interface{})
+	return ok
+}
@@ -0,0 +1,173 @@
+// Package scim 实现 SCIM 2.0（RFC 7643 / RFC 7644）与资源无关的协议部分：错误和列表响应格式、
+// 过滤表达式的解析和求值、PATCH 路径的解析以及对 JSON 表示的资源应用 PATCH 操作
+package scim
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// 资源和消息的 schema
+const (
+	SchemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SchemaGroup                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SchemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SchemaPatchOp               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SchemaError                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+	SchemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	SchemaResourceType          = "urn:ietf:params:scim:schemas:core:2.0:ResourceType"
+)
+
+// ContentType SCIM 请求和响应的媒体类型
+const ContentType = "application/scim+json"
+
+// 错误的 scimType，见 RFC 7644 3.12
+const (
+	ErrInvalidFilter = "invalidFilter"
+	ErrInvalidPath   = "invalidPath"
+	ErrInvalidValue  = "invalidValue"
+	ErrInvalidSyntax = "invalidSyntax"
+	ErrNoTarget      = "noTarget"
+	ErrUniqueness    = "uniqueness"
+	ErrMutability    = "mutability"
+)
+
+// Error SCIM 错误响应
+type Error struct {
+	Status   int
+	ScimType string
+	Detail   string
+}
+
+func (e *Error) Error() string {
+	if e.ScimType != "" {
+		return e.ScimType + ": " + e.Detail
+	}
+	return e.Detail
+}
+
+// Body 返回错误响应体，status 按规范使用字符串
+func (e *Error) Body() map[string]interface{} {
+	body := map[string]interface{}{
+		"schemas": []string{SchemaError},
+		"status":  strconv.Itoa(e.Status),
+		"detail":  e.Detail,
+	}
+	if e.ScimType != "" {
+		body["scimType"] = e.ScimType
+	}
+	return body
+}
+
+// BadRequest 返回400错误
+func BadRequest(scimType, format string, args ...interface{}) *Error {
+	return &Error{Status: http.StatusBadRequest, ScimType: scimType, Detail: fmt.Sprintf(format, args...)}
+}
+
+// NotFound 返回404错误
+func NotFound(format string, args ...interface{}) *Error {
+	return &Error{Status: http.StatusNotFound, Detail: fmt.Sprintf(format, args...)}
+}
+
+// Conflict 返回409唯一性冲突错误
+func Conflict(format string, args ...interface{}) *Error {
+	return &Error{Status: http.StatusConflict, ScimType: ErrUniqueness, Detail: fmt.Sprintf(format, args...)}
+}
+
+// ListResponse 查询结果，StartIndex 从1开始
+type ListResponse struct {
+	Schemas      []string      `json:"schemas"`
+	TotalResults int           `json:"totalResults"`
+	StartIndex   int           `json:"startIndex"`
+	ItemsPerPage int           `json:"itemsPerPage"`
+	Resources    []interface{} `json:"Resources"`
+}
+
+// Page 分页参数
+type Page struct {
+	StartIndex int
+	Count      int
+}
+
+// ParsePage 解析 startIndex 和 count 查询参数，startIndex 小于1时按1处理，count 限制在 [0, max]
+func ParsePage(startIndex, count string, defaultCount, max int) Page {
+	page := Page{StartIndex: 1, Count: defaultCount}
+	if n, err := strconv.Atoi(startIndex); err == nil && n > 1 {
+		page.StartIndex = n
+	}
+	if n, err := strconv.Atoi(count); err == nil {
+		page.Count = n
+	}
+	if page.Count < 0 {
+		page.Count = 0
+	}
+	if page.Count > max {
+		page.Count = max
+	}
+	return page
+}
+
+// NewListResponse 按分页参数截取已过滤的资源
+func NewListResponse(resources []interface{}, page Page) *ListResponse {
+	start := page.StartIndex - 1
+	if start > len(resources) {
+		start = len(resources)
+	}
+	end := start + page.Count
+	if end > len(resources) {
+		end = len(resources)
+	}
+	items := resources[start:end]
+	if items == nil {
+		items = []interface{}{}
+	}
+	return &ListResponse{
+		Schemas:      []string{SchemaListResponse},
+		TotalResults: len(resources),
+		StartIndex:   page.StartIndex,
+		ItemsPerPage: len(items),
+		Resources:    items,
+	}
+}
+
+// Project 按 attributes 或 excludedAttributes 查询参数裁剪资源的顶层属性，id、schemas 和 meta 始终返回
+func Project(resource map[string]interface{}, attributes, excluded string) {
+	keep := func(key string) bool {
+		switch strings.ToLower(key) {
+		case "id", "schemas", "meta":
+			return true
+		}
+		return false
+	}
+	if attributes != "" {
+		wanted := attributeNames(attributes)
+		for key := range resource {
+			if !keep(key) && !wanted[strings.ToLower(key)] {
+				delete(resource, key)
+			}
+		}
+		return
+	}
+	if excluded != "" {
+		unwanted := attributeNames(excluded)
+		for key := range resource {
+			if !keep(key) && unwanted[strings.ToLower(key)] {
+				delete(resource, key)
+			}
+		}
+	}
+}
+
+// attributeNames 返回逗号分隔的属性列表中的顶层属性名（小写）
+func attributeNames(list string) map[string]bool {
+	names := map[string]bool{}
+	for _, item := range strings.Split(list, ",") {
+		path, err := ParsePath(strings.TrimSpace(item))
+		if err == nil {
+			names[strings.ToLower(path.Attr)] = true
+		}
+	}
+	return names
+}
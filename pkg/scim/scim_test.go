@@ -0,0 +1,180 @@
+package scim
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func resource(t *testing.T, raw string) map[string]interface{} {
+	t.Helper()
+	var m map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &m); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+const alice = `{
+	"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+	"id": "2",
+	"externalId": "00u1",
+	"userName": "Alice",
+	"name": {"formatted": "Alice Liddell", "givenName": "Alice"},
+	"emails": [{"value": "alice@example.com", "type": "work", "primary": true}],
+	"active": true,
+	"meta": {"lastModified": "2026-10-01T00:00:00Z"}
+}`
+
+func TestParseFilter(t *testing.T) {
+	r := resource(t, alice)
+	cases := []struct {
+		filter string
+		want   bool
+	}{
+		{`userName eq "alice"`, true},
+		{`USERNAME Eq "ALICE"`, true},
+		{`urn:ietf:params:scim:schemas:core:2.0:User:userName eq "alice"`, true},
+		{`userName ne "alice"`, false},
+		{`userName ne "bob"`, true},
+		{`userName sw "al" and active eq true`, true},
+		{`userName sw "al" and active eq false`, false},
+		{`userName eq "bob" or externalId eq "00u1"`, true},
+		{`not (userName eq "bob")`, true},
+		{`not(userName eq "alice")`, false},
+		{`name.givenName co "lic"`, true},
+		{`name.familyName pr`, false},
+		{`title pr`, false},
+		{`title eq null`, true},
+		{`emails eq "alice@example.com"`, true},
+		{`emails.value ew "@example.com"`, true},
+		{`emails[type eq "work" and value co "alice"]`, true},
+		{`emails[type eq "home"]`, false},
+		{`meta.lastModified gt "2026-09-01T00:00:00Z"`, true},
+		{`(userName eq "bob" or userName eq "alice") and not (active eq false)`, true},
+		{`userName eq "a\"b"`, false},
+	}
+	for _, tc := range cases {
+		f, err := ParseFilter(tc.filter)
+		if err != nil {
+			t.Errorf("ParseFilter(%q): %v", tc.filter, err)
+			continue
+		}
+		if got := f.Match(r); got != tc.want {
+			t.Errorf("%q matched %v, want %v", tc.filter, got, tc.want)
+		}
+	}
+
+	for _, bad := range []string{``, `userName`, `userName eq`, `userName like "a"`, `(userName eq "a"`, `userName eq "a" extra`, `userName eq "open`, `a.b.c eq "x"`} {
+		if _, err := ParseFilter(bad); err == nil {
+			t.Errorf("ParseFilter(%q) should fail", bad)
+		}
+	}
+}
+
+func TestApplyPatch(t *testing.T) {
+	r := resource(t, alice)
+	ops := []PatchOperation{
+		{Op: "Replace", Path: "active", Value: false},
+		{Op: "replace", Value: map[string]interface{}{"name.familyName": "Liddell", "displayName": "Alice L."}},
+		{Op: "replace", Path: `emails[type eq "work"].value`, Value: "al@example.com"},
+		{Op: "add", Path: `phoneNumbers[type eq "mobile"].value`, Value: "123"},
+		{Op: "add", Path: "name", Value: map[string]interface{}{"middleName": "P"}},
+		{Op: "remove", Path: "externalId"},
+	}
+	for _, op := range ops {
+		if err := Apply(r, op); err != nil {
+			t.Fatalf("Apply(%+v): %v", op, err)
+		}
+	}
+
+	want := resource(t, `{
+		"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+		"id": "2",
+		"userName": "Alice",
+		"displayName": "Alice L.",
+		"name": {"formatted": "Alice Liddell", "givenName": "Alice", "familyName": "Liddell", "middleName": "P"},
+		"emails": [{"value": "al@example.com", "type": "work", "primary": true}],
+		"phoneNumbers": [{"type": "mobile", "value": "123"}],
+		"active": false,
+		"meta": {"lastModified": "2026-10-01T00:00:00Z"}
+	}`)
+	if !reflect.DeepEqual(r, want) {
+		got, _ := json.Marshal(r)
+		t.Fatalf("patched resource = %s", got)
+	}
+}
+
+func TestApplyPatchMembers(t *testing.T) {
+	group := resource(t, `{"displayName": "ops", "members": [{"value": "1"}, {"value": "2"}]}`)
+	steps := []struct {
+		op   PatchOperation
+		want []string
+	}{
+		{PatchOperation{Op: "add", Path: "members", Value: []interface{}{map[string]interface{}{"value": "3"}}}, []string{"1", "2", "3"}},
+		{PatchOperation{Op: "remove", Path: `members[value eq "2"]`}, []string{"1", "3"}},
+		// Azure AD 在 value 中列出要删除的成员
+		{PatchOperation{Op: "Remove", Path: "members", Value: []interface{}{map[string]interface{}{"value": "1"}}}, []string{"3"}},
+		{PatchOperation{Op: "replace", Path: "members", Value: []interface{}{map[string]interface{}{"value": "4"}}}, []string{"4"}},
+		{PatchOperation{Op: "remove", Path: "members"}, nil},
+	}
+	for _, step := range steps {
+		if err := Apply(group, step.op); err != nil {
+			t.Fatalf("Apply(%+v): %v", step.op, err)
+		}
+		var got []string
+		for _, v := range (AttrPath{Attr: "members"}).values(group) {
+			got = append(got, v.(string))
+		}
+		if !reflect.DeepEqual(got, step.want) {
+			t.Fatalf("after %+v members = %v, want %v", step.op, got, step.want)
+		}
+	}
+}
+
+func TestApplyPatchErrors(t *testing.T) {
+	cases := []PatchOperation{
+		{Op: "move", Path: "active", Value: true},
+		{Op: "remove"},
+		{Op: "replace", Value: "x"},
+		{Op: "replace", Path: "emails[type eq", Value: "x"},
+		{Op: "replace", Path: `emails[type eq "home"]`, Value: map[string]interface{}{"value": "x"}},
+		{Op: "replace", Path: "userName.first", Value: "x"},
+		{Op: "add", Path: "active"},
+	}
+	for _, op := range cases {
+		err := Apply(resource(t, alice), op)
+		scimErr, ok := err.(*Error)
+		if !ok || scimErr.Status != 400 || scimErr.ScimType == "" {
+			t.Errorf("Apply(%+v) = %v, want a 400 SCIM error", op, err)
+		}
+	}
+}
+
+func TestListResponse(t *testing.T) {
+	items := []interface{}{1, 2, 3, 4, 5}
+	page := ParsePage("2", "2", 100, 200)
+	list := NewListResponse(items, page)
+	if list.TotalResults != 5 || list.StartIndex != 2 || list.ItemsPerPage != 2 || !reflect.DeepEqual(list.Resources, []interface{}{2, 3}) {
+		t.Fatalf("list = %+v", list)
+	}
+	list = NewListResponse(items, ParsePage("0", "500", 100, 200))
+	if list.StartIndex != 1 || list.ItemsPerPage != 5 {
+		t.Fatalf("list = %+v", list)
+	}
+	list = NewListResponse(items, ParsePage("9", "", 100, 200))
+	if list.ItemsPerPage != 0 || list.Resources == nil {
+		t.Fatalf("list = %+v", list)
+	}
+
+	r := resource(t, alice)
+	Project(r, "userName,name.givenName", "")
+	if len(r) != 5 || r["userName"] == nil || r["name"] == nil || r["id"] == nil {
+		t.Fatalf("projected resource = %v", r)
+	}
+	r = resource(t, alice)
+	Project(r, "", "emails,id")
+	if r["emails"] != nil || r["id"] == nil {
+		t.Fatalf("projected resource = %v", r)
+	}
+}
@@ -0,0 +1,80 @@
+package secretbox
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// prefix 密文格式版本，便于以后更换算法
+const prefix = "v1:"
+
+// ErrInvalidCiphertext 密文格式错误或校验失败
+var ErrInvalidCiphertext = errors.New("secretbox: invalid ciphertext")
+
+// Box 使用 AES-256-GCM 加密敏感数据，密文格式为 "v1:" + base64(nonce || ciphertext)
+type Box struct {
+	aead cipher.AEAD
+}
+
+// New 使用 32 字节密钥创建 Box
+func New(key []byte) (*Box, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("secretbox: key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Box{aead: aead}, nil
+}
+
+// ParseKey 解析 base64 编码的 32 字节随机密钥，不接受口令，可使用 openssl rand -base64 32 生成
+func ParseKey(s string) ([]byte, error) {
+	if s == "" {
+		return nil, errors.New("secretbox: key is empty")
+	}
+	key, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("secretbox: key must be base64-encoded (generate with: openssl rand -base64 32)")
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("secretbox: key must be 32 bytes, got %d (generate with: openssl rand -base64 32)", len(key))
+	}
+	return key, nil
+}
+
+// Seal 加密明文
+func (b *Box) Seal(plaintext []byte) (string, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := b.aead.Seal(nonce, nonce, plaintext, nil)
+	return prefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open 解密 Seal 生成的密文
+func (b *Box) Open(ciphertext string) ([]byte, error) {
+	if !strings.HasPrefix(ciphertext, prefix) {
+		return nil, ErrInvalidCiphertext
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(ciphertext, prefix))
+	if err != nil || len(data) < b.aead.NonceSize() {
+		return nil, ErrInvalidCiphertext
+	}
+	nonce, sealed := data[:b.aead.NonceSize()], data[b.aead.NonceSize():]
+	plaintext, err := b.aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+	return plaintext, nil
+}
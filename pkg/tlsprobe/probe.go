@@ -0,0 +1,125 @@
+package tlsprobe
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+)
+
+// DefaultTimeout 默认探测超时时间
+const DefaultTimeout = 10 * time.Second
+
+// Result 证书探测结果，只描述服务端返回的叶子证书
+type Result struct {
+	Subject      string
+	Issuer       string
+	SANs         []string
+	SerialNumber string
+	// Fingerprint 叶子证书的 SHA-256 指纹（十六进制）
+	Fingerprint string
+	NotBefore   time.Time
+	NotAfter    time.Time
+	// ChainValid 证书链能否校验到受信任的根证书（不含主机名校验）
+	ChainValid bool
+	ChainError string
+	// HostnameMatch 证书是否覆盖探测的主机名
+	HostnameMatch bool
+	// Address 实际连接的地址
+	Address string
+}
+
+// Prober TLS 证书探测器
+type Prober struct {
+	Timeout time.Duration
+	// Roots 校验证书链使用的根证书，为空时使用系统根证书
+	Roots *x509.CertPool
+}
+
+// New 创建证书探测器
+func New(timeout time.Duration) *Prober {
+	return &Prober{Timeout: timeout}
+}
+
+// Probe 连接 host:port 完成 TLS 握手并检查服务端证书
+// 握手时不校验证书，以便记录过期、自签名或主机名不匹配的证书
+func (p *Prober) Probe(ctx context.Context, host string, port int) (*Result, error) {
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	dialer := &tls.Dialer{
+		Config: &tls.Config{
+			ServerName:         host,
+			InsecureSkipVerify: true,
+		},
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		return nil, fmt.Errorf("tls handshake: %w", err)
+	}
+	defer conn.Close()
+
+	tlsConn := conn.(*tls.Conn)
+	certs := tlsConn.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return nil, errors.New("server did not present a certificate")
+	}
+
+	result := inspect(host, certs, p.Roots, time.Now())
+	result.Address = tlsConn.RemoteAddr().String()
+	return result, nil
+}
+
+// inspect 从证书链中提取叶子证书信息并校验
+func inspect(host string, certs []*x509.Certificate, roots *x509.CertPool, now time.Time) *Result {
+	leaf := certs[0]
+	sum := sha256.Sum256(leaf.Raw)
+
+	result := &Result{
+		Subject:      leaf.Subject.String(),
+		Issuer:       leaf.Issuer.String(),
+		SANs:         subjectAltNames(leaf),
+		SerialNumber: leaf.SerialNumber.Text(16),
+		Fingerprint:  hex.EncodeToString(sum[:]),
+		NotBefore:    leaf.NotBefore.UTC(),
+		NotAfter:     leaf.NotAfter.UTC(),
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+	})
+	if err != nil {
+		result.ChainError = err.Error()
+	} else {
+		result.ChainValid = true
+	}
+
+	result.HostnameMatch = leaf.VerifyHostname(host) == nil
+	return result
+}
+
+// subjectAltNames 返回证书的 DNS 和 IP 备用名称
+func subjectAltNames(cert *x509.Certificate) []string {
+	names := make([]string, 0, len(cert.DNSNames)+len(cert.IPAddresses))
+	names = append(names, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		names = append(names, ip.String())
+	}
+	return names
+}
@@ -0,0 +1,163 @@
+package tlsprobe
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"math/big"
+	"net"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCert 测试证书及其私钥
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// newTestCert 签发测试证书，parent 为空时自签名，没有 dnsNames 时生成 CA 证书
+func newTestCert(t *testing.T, name string, parent *testCert, notAfter time.Time, dnsNames ...string) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+		DNSNames:     dnsNames,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	if len(dnsNames) == 0 {
+		tmpl.IsCA, tmpl.BasicConstraintsValid = true, true
+		tmpl.KeyUsage |= x509.KeyUsageCertSign
+	}
+	signer, signerKey := tmpl, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCert{cert: cert, key: key}
+}
+
+// newTLSServer 在本地端口上提供 leaf 证书，返回监听的端口
+func newTLSServer(t *testing.T, leaf *testCert) int {
+	t.Helper()
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{leaf.cert.Raw}, PrivateKey: leaf.key}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.(*tls.Conn).Handshake()
+			conn.Close()
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+func TestProbe(t *testing.T) {
+	ca := newTestCert(t, "Test Root CA", nil, time.Now().Add(24*time.Hour))
+	leaf := newTestCert(t, "localhost", ca, time.Now().Add(time.Hour), "localhost", "www.example.com")
+	port := newTLSServer(t, leaf)
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	p := &Prober{Timeout: 5 * time.Second, Roots: roots}
+	result, err := p.Probe(context.Background(), "localhost", port)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(leaf.cert.Raw)
+	if result.Subject != "CN=localhost" || result.Issuer != "CN=Test Root CA" || result.Fingerprint != hex.EncodeToString(sum[:]) ||
+		!slices.Equal(result.SANs, []string{"localhost", "www.example.com"}) || !result.NotAfter.Equal(leaf.cert.NotAfter) {
+		t.Fatalf("result = %+v", result)
+	}
+	if !result.ChainValid || !result.HostnameMatch || result.Address == "" {
+		t.Fatalf("chain valid = %v (%s), hostname match = %v, address = %q", result.ChainValid, result.ChainError, result.HostnameMatch, result.Address)
+	}
+
+	// 握手不校验证书，不受信任的证书和不匹配的主机名也会返回结果
+	result, err = New(5*time.Second).Probe(context.Background(), "127.0.0.1", port)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ChainValid || result.ChainError == "" || result.HostnameMatch {
+		t.Fatalf("untrusted result = %+v", result)
+	}
+}
+
+func TestProbeConnectionError(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+
+	if _, err := New(time.Second).Probe(context.Background(), "127.0.0.1", port); err == nil || !strings.Contains(err.Error(), "tls handshake") {
+		t.Fatalf("Probe closed port: %v", err)
+	}
+}
+
+func TestInspect(t *testing.T) {
+	now := time.Now()
+	ca := newTestCert(t, "Test Root CA", nil, now.Add(48*time.Hour))
+	intermediate := newTestCert(t, "Test Intermediate CA", ca, now.Add(48*time.Hour))
+	leaf := newTestCert(t, "www.example.com", intermediate, now.Add(24*time.Hour), "*.example.com")
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	tests := []struct {
+		name      string
+		host      string
+		certs     []*x509.Certificate
+		now       time.Time
+		wantChain bool
+		wantMatch bool
+	}{
+		{"valid chain", "www.example.com", []*x509.Certificate{leaf.cert, intermediate.cert}, now, true, true},
+		{"wildcard does not cover apex", "example.com", []*x509.Certificate{leaf.cert, intermediate.cert}, now, true, false},
+		{"missing intermediate", "www.example.com", []*x509.Certificate{leaf.cert}, now, false, true},
+		{"expired", "www.example.com", []*x509.Certificate{leaf.cert, intermediate.cert}, now.Add(36 * time.Hour), false, true},
+		{"not yet valid", "www.example.com", []*x509.Certificate{leaf.cert, intermediate.cert}, now.Add(-2 * time.Hour), false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := inspect(tt.host, tt.certs, roots, tt.now)
+			if result.ChainValid != tt.wantChain || (result.ChainError == "") != tt.wantChain || result.HostnameMatch != tt.wantMatch {
+				t.Fatalf("inspect = chain %v (%s), match %v; want chain %v, match %v",
+					result.ChainValid, result.ChainError, result.HostnameMatch, tt.wantChain, tt.wantMatch)
+			}
+			if result.Issuer != "CN=Test Intermediate CA" || !slices.Equal(result.SANs, []string{"*.example.com"}) {
+				t.Fatalf("result = %+v", result)
+			}
+		})
+	}
+}
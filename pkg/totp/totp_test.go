@@ -0,0 +1,78 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// RFC 6238 附录B的测试向量（SHA-1），取后6位
+func TestCodeRFC6238(t *testing.T) {
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	cases := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tc := range cases {
+		got, err := Code(secret, Step(time.Unix(tc.unix, 0)))
+		if err != nil {
+			t.Fatalf("Code: %v", err)
+		}
+		if got != tc.code {
+			t.Errorf("Code(%d) = %s, want %s", tc.unix, got, tc.code)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1700000000, 0)
+	current := Step(now)
+
+	code, _ := Code(secret, current)
+	step, ok := Validate(secret, code, now, 0)
+	if !ok || step != current {
+		t.Fatalf("Validate current = %d, %v", step, ok)
+	}
+
+	// 同一验证码不能重复使用
+	if _, ok := Validate(secret, code, now, step); ok {
+		t.Fatal("replayed code should be rejected")
+	}
+
+	// 允许前后一个时间步的偏差
+	previous, _ := Code(secret, current-1)
+	if _, ok := Validate(secret, previous, now, 0); !ok {
+		t.Fatal("code from previous step should be accepted")
+	}
+	old, _ := Code(secret, current-2)
+	if _, ok := Validate(secret, old, now, 0); ok {
+		t.Fatal("code older than the skew should be rejected")
+	}
+	if _, ok := Validate(secret, "12345", now, 0); ok {
+		t.Fatal("short code should be rejected")
+	}
+}
+
+func TestURI(t *testing.T) {
+	uri := URI("Domain Admin", "alice@example.com", "JBSWY3DPEHPK3PXP")
+	if !strings.HasPrefix(uri, "otpauth://totp/Domain%20Admin:alice@example.com?") {
+		t.Fatalf("unexpected uri: %s", uri)
+	}
+	for _, part := range []string{"secret=JBSWY3DPEHPK3PXP", "issuer=Domain+Admin", "digits=6", "period=30"} {
+		if !strings.Contains(uri, part) {
+			t.Fatalf("uri %s missing %s", uri, part)
+		}
+	}
+}
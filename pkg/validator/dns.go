@@ -0,0 +1,283 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DNS 相关长度限制
+const (
+	MaxDNSNameLength   = 253
+	MaxDNSLabelLength  = 63
+	MaxTXTStringLength = 255
+	MaxTXTValueLength  = 4096
+	MinDNSRecordTTL    = 1
+	MaxDNSRecordTTL    = 604800
+	maxUint16          = 65535
+	maxCAAFlags        = 255
+)
+
+var (
+	dnsLabelRegexp = regexp.MustCompile(`^[A-Za-z0-9_]([A-Za-z0-9_-]*[A-Za-z0-9_])?$`)
+	caaTagRegexp   = regexp.MustCompile(`^[A-Za-z0-9]+$`)
+)
+
+// DNSRecord 待校验的DNS记录
+// Name 为相对区域的主机记录（"@" 表示区域顶点），Value 不包含 MX/SRV 的优先级等字段
+type DNSRecord struct {
+	Name     string
+	Type     string
+	Value    string
+	TTL      int
+	Priority int
+	Weight   int
+	Port     int
+}
+
+// recordValidators 各记录类型的校验函数
+var recordValidators = map[string]func(r DNSRecord) error{
+	"A":     validateA,
+	"AAAA":  validateAAAA,
+	"CNAME": validateCNAME,
+	"MX":    validateMX,
+	"TXT":   validateTXT,
+	"SRV":   validateSRV,
+	"CAA":   validateCAA,
+	"NS":    validateNS,
+}
+
+// ValidateDNSRecord 按记录类型校验DNS记录
+func ValidateDNSRecord(r DNSRecord) error {
+	check, ok := recordValidators[strings.ToUpper(r.Type)]
+	if !ok {
+		return fmt.Errorf("不支持的记录类型: %s", r.Type)
+	}
+
+	if err := ValidateRecordName(r.Name); err != nil {
+		return err
+	}
+
+	if r.TTL != 0 && (r.TTL < MinDNSRecordTTL || r.TTL > MaxDNSRecordTTL) {
+		return fmt.Errorf("TTL必须在%d到%d之间", MinDNSRecordTTL, MaxDNSRecordTTL)
+	}
+
+	return check(r)
+}
+
+// ValidateRecordName 校验相对主机记录，允许 "@" 和首标签为 "*" 的泛解析
+func ValidateRecordName(name string) error {
+	if name == "" {
+		return errors.New("主机记录不能为空")
+	}
+	if name == "@" {
+		return nil
+	}
+	if len(name) > MaxDNSNameLength {
+		return fmt.Errorf("主机记录长度不能超过%d", MaxDNSNameLength)
+	}
+
+	labels := strings.Split(name, ".")
+	for i, label := range labels {
+		if i == 0 && label == "*" {
+			continue
+		}
+		if err := validateLabel(label); err != nil {
+			return fmt.Errorf("主机记录格式错误: %w", err)
+		}
+	}
+	return nil
+}
+
+// ValidateHostname 校验记录值中的主机名（允许末尾的点）
+func ValidateHostname(host string) error {
+	host = strings.TrimSuffix(host, ".")
+	if host == "" {
+		return errors.New("主机名不能为空")
+	}
+	if len(host) > MaxDNSNameLength {
+		return fmt.Errorf("主机名长度不能超过%d", MaxDNSNameLength)
+	}
+	for _, label := range strings.Split(host, ".") {
+		if err := validateLabel(label); err != nil {
+			return fmt.Errorf("主机名格式错误: %w", err)
+		}
+	}
+	return nil
+}
+
+func validateLabel(label string) error {
+	if label == "" {
+		return errors.New("标签不能为空")
+	}
+	if len(label) > MaxDNSLabelLength {
+		return fmt.Errorf("标签长度不能超过%d", MaxDNSLabelLength)
+	}
+	if !dnsLabelRegexp.MatchString(label) {
+		return fmt.Errorf("标签 %q 包含非法字符", label)
+	}
+	return nil
+}
+
+func validateA(r DNSRecord) error {
+	ip := net.ParseIP(r.Value)
+	if ip == nil || ip.To4() == nil || strings.Contains(r.Value, ":") {
+		return errors.New("A记录的值必须是有效的IPv4地址")
+	}
+	return nil
+}
+
+func validateAAAA(r DNSRecord) error {
+	ip := net.ParseIP(r.Value)
+	if ip == nil || !strings.Contains(r.Value, ":") {
+		return errors.New("AAAA记录的值必须是有效的IPv6地址")
+	}
+	return nil
+}
+
+func validateCNAME(r DNSRecord) error {
+	if r.Name == "@" {
+		return errors.New("区域顶点不能设置CNAME记录")
+	}
+	if err := ValidateHostname(r.Value); err != nil {
+		return fmt.Errorf("CNAME记录的值无效: %w", err)
+	}
+	return nil
+}
+
+func validateNS(r DNSRecord) error {
+	if err := ValidateHostname(r.Value); err != nil {
+		return fmt.Errorf("NS记录的值无效: %w", err)
+	}
+	return nil
+}
+
+func validateMX(r DNSRecord) error {
+	if r.Priority < 0 || r.Priority > maxUint16 {
+		return fmt.Errorf("MX记录的优先级必须在0到%d之间", maxUint16)
+	}
+	// RFC 7505 空 MX
+	if r.Value == "." {
+		return nil
+	}
+	if err := ValidateHostname(r.Value); err != nil {
+		return fmt.Errorf("MX记录的值无效: %w", err)
+	}
+	return nil
+}
+
+func validateSRV(r DNSRecord) error {
+	labels := strings.Split(r.Name, ".")
+	if len(labels) < 2 || !strings.HasPrefix(labels[0], "_") || !strings.HasPrefix(labels[1], "_") {
+		return errors.New("SRV记录的主机记录必须为 _服务._协议 格式")
+	}
+	if r.Priority < 0 || r.Priority > maxUint16 {
+		return fmt.Errorf("SRV记录的优先级必须在0到%d之间", maxUint16)
+	}
+	if r.Weight < 0 || r.Weight > maxUint16 {
+		return fmt.Errorf("SRV记录的权重必须在0到%d之间", maxUint16)
+	}
+	if r.Port < 1 || r.Port > maxUint16 {
+		return fmt.Errorf("SRV记录的端口必须在1到%d之间", maxUint16)
+	}
+	// 目标为 "." 表示服务不可用
+	if r.Value == "." {
+		return nil
+	}
+	if err := ValidateHostname(r.Value); err != nil {
+		return fmt.Errorf("SRV记录的目标无效: %w", err)
+	}
+	return nil
+}
+
+func validateTXT(r DNSRecord) error {
+	if len(r.Value) > MaxTXTValueLength {
+		return fmt.Errorf("TXT记录的值长度不能超过%d", MaxTXTValueLength)
+	}
+
+	strs, err := SplitTXTStrings(r.Value)
+	if err != nil {
+		return err
+	}
+	for _, s := range strs {
+		if len(s) > MaxTXTStringLength {
+			return fmt.Errorf("TXT记录的单个字符串长度不能超过%d，长文本请拆分为多个带引号的字符串", MaxTXTStringLength)
+		}
+	}
+	return nil
+}
+
+func validateCAA(r DNSRecord) error {
+	fields := strings.SplitN(strings.TrimSpace(r.Value), " ", 3)
+	if len(fields) != 3 {
+		return errors.New("CAA记录的值必须为 flags tag value 格式")
+	}
+
+	flags, err := strconv.Atoi(fields[0])
+	if err != nil || flags < 0 || flags > maxCAAFlags {
+		return fmt.Errorf("CAA记录的flags必须在0到%d之间", maxCAAFlags)
+	}
+	if !caaTagRegexp.MatchString(fields[1]) {
+		return errors.New("CAA记录的tag只能包含字母和数字")
+	}
+	switch strings.ToLower(fields[1]) {
+	case "issue", "issuewild", "iodef":
+	default:
+		if flags&128 != 0 {
+			return fmt.Errorf("CAA记录的关键tag %s 不受支持", fields[1])
+		}
+	}
+
+	value := strings.TrimSpace(fields[2])
+	if len(value) < 2 || !strings.HasPrefix(value, `"`) || !strings.HasSuffix(value, `"`) {
+		return errors.New("CAA记录的value必须使用双引号包裹")
+	}
+	return nil
+}
+
+// SplitTXTStrings 将TXT记录值拆分为字符串列表
+// 值以双引号开头时按带引号的字符串解析，否则整个值视为单个字符串
+func SplitTXTStrings(value string) ([]string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, errors.New("TXT记录的值不能为空")
+	}
+	if !strings.HasPrefix(value, `"`) {
+		return []string{value}, nil
+	}
+
+	var (
+		result  []string
+		current strings.Builder
+		quoted  bool
+		escaped bool
+	)
+	for i := 0; i < len(value); i++ {
+		ch := value[i]
+		switch {
+		case escaped:
+			current.WriteByte(ch)
+			escaped = false
+		case quoted && ch == '\\':
+			escaped = true
+		case ch == '"':
+			if quoted {
+				result = append(result, current.String())
+				current.Reset()
+			}
+			quoted = !quoted
+		case quoted:
+			current.WriteByte(ch)
+		case ch == ' ' || ch == '\t':
+		default:
+			return nil, errors.New("TXT记录的带引号字符串之间只能包含空白")
+		}
+	}
+	if quoted {
+		return nil, errors.New("TXT记录的引号未闭合")
+	}
+	return result, nil
+}
@@ -0,0 +1,107 @@
+package validator
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestValidateDNSRecord(t *testing.T) {
+	longTXT := strings.Repeat("a", MaxTXTStringLength)
+	tests := []struct {
+		name   string
+		record DNSRecord
+		ok     bool
+	}{
+		// 通用字段
+		{"unsupported type", DNSRecord{Name: "www", Type: "PTR", Value: "example.com"}, false},
+		{"lower case type", DNSRecord{Name: "www", Type: "a", Value: "192.0.2.1"}, true},
+		{"empty name", DNSRecord{Type: "A", Value: "192.0.2.1"}, false},
+		{"wildcard name", DNSRecord{Name: "*.dev", Type: "A", Value: "192.0.2.1"}, true},
+		{"wildcard not first", DNSRecord{Name: "dev.*", Type: "A", Value: "192.0.2.1"}, false},
+		{"label too long", DNSRecord{Name: strings.Repeat("a", MaxDNSLabelLength+1), Type: "A", Value: "192.0.2.1"}, false},
+		{"invalid label", DNSRecord{Name: "-www", Type: "A", Value: "192.0.2.1"}, false},
+		{"ttl too large", DNSRecord{Name: "www", Type: "A", Value: "192.0.2.1", TTL: MaxDNSRecordTTL + 1}, false},
+		{"default ttl", DNSRecord{Name: "www", Type: "A", Value: "192.0.2.1", TTL: 0}, true},
+
+		// A / AAAA
+		{"A ipv4", DNSRecord{Name: "@", Type: "A", Value: "192.0.2.1"}, true},
+		{"A ipv6", DNSRecord{Name: "@", Type: "A", Value: "2001:db8::1"}, false},
+		{"A ipv4-mapped ipv6", DNSRecord{Name: "@", Type: "A", Value: "::ffff:192.0.2.1"}, false},
+		{"A hostname", DNSRecord{Name: "@", Type: "A", Value: "example.com"}, false},
+		{"AAAA ipv6", DNSRecord{Name: "@", Type: "AAAA", Value: "2001:db8::1"}, true},
+		{"AAAA ipv4", DNSRecord{Name: "@", Type: "AAAA", Value: "192.0.2.1"}, false},
+
+		// CNAME / NS
+		{"CNAME", DNSRecord{Name: "www", Type: "CNAME", Value: "example.com."}, true},
+		{"CNAME at apex", DNSRecord{Name: "@", Type: "CNAME", Value: "example.com"}, false},
+		{"CNAME invalid target", DNSRecord{Name: "www", Type: "CNAME", Value: "exa mple.com"}, false},
+		{"NS", DNSRecord{Name: "dev", Type: "NS", Value: "ns1.example.net"}, true},
+		{"NS empty", DNSRecord{Name: "dev", Type: "NS", Value: "."}, false},
+
+		// MX
+		{"MX", DNSRecord{Name: "@", Type: "MX", Value: "mail.example.com", Priority: 10}, true},
+		{"MX null", DNSRecord{Name: "@", Type: "MX", Value: ".", Priority: 0}, true},
+		{"MX priority too large", DNSRecord{Name: "@", Type: "MX", Value: "mail.example.com", Priority: 65536}, false},
+		{"MX negative priority", DNSRecord{Name: "@", Type: "MX", Value: "mail.example.com", Priority: -1}, false},
+
+		// SRV
+		{"SRV", DNSRecord{Name: "_sip._tcp", Type: "SRV", Value: "sip.example.com", Priority: 10, Weight: 5, Port: 5060}, true},
+		{"SRV unavailable", DNSRecord{Name: "_sip._tcp", Type: "SRV", Value: ".", Port: 1}, true},
+		{"SRV name without protocol", DNSRecord{Name: "_sip", Type: "SRV", Value: "sip.example.com", Port: 5060}, false},
+		{"SRV name without underscores", DNSRecord{Name: "sip.tcp", Type: "SRV", Value: "sip.example.com", Port: 5060}, false},
+		{"SRV priority too large", DNSRecord{Name: "_sip._tcp", Type: "SRV", Value: "sip.example.com", Priority: 65536, Port: 5060}, false},
+		{"SRV negative weight", DNSRecord{Name: "_sip._tcp", Type: "SRV", Value: "sip.example.com", Weight: -1, Port: 5060}, false},
+		{"SRV zero port", DNSRecord{Name: "_sip._tcp", Type: "SRV", Value: "sip.example.com"}, false},
+
+		// TXT
+		{"TXT plain", DNSRecord{Name: "@", Type: "TXT", Value: "v=spf1 -all"}, true},
+		{"TXT string at limit", DNSRecord{Name: "@", Type: "TXT", Value: longTXT}, true},
+		{"TXT string too long", DNSRecord{Name: "@", Type: "TXT", Value: longTXT + "a"}, false},
+		{"TXT split strings", DNSRecord{Name: "@", Type: "TXT", Value: `"` + longTXT + `" "` + longTXT + `"`}, true},
+		{"TXT value too long", DNSRecord{Name: "@", Type: "TXT", Value: strings.Repeat(`"`+longTXT+`"`, 16)}, false},
+		{"TXT empty", DNSRecord{Name: "@", Type: "TXT", Value: " "}, false},
+		{"TXT unclosed quote", DNSRecord{Name: "@", Type: "TXT", Value: `"abc`}, false},
+
+		// CAA
+		{"CAA issue", DNSRecord{Name: "@", Type: "CAA", Value: `0 issue "letsencrypt.org"`}, true},
+		{"CAA critical iodef", DNSRecord{Name: "@", Type: "CAA", Value: `128 iodef "mailto:security@example.com"`}, true},
+		{"CAA critical unknown tag", DNSRecord{Name: "@", Type: "CAA", Value: `128 future "x"`}, false},
+		{"CAA unknown tag", DNSRecord{Name: "@", Type: "CAA", Value: `0 future "x"`}, true},
+		{"CAA flags too large", DNSRecord{Name: "@", Type: "CAA", Value: `256 issue "letsencrypt.org"`}, false},
+		{"CAA unquoted value", DNSRecord{Name: "@", Type: "CAA", Value: `0 issue letsencrypt.org`}, false},
+		{"CAA missing value", DNSRecord{Name: "@", Type: "CAA", Value: `0 issue`}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateDNSRecord(tt.record)
+			if (err == nil) != tt.ok {
+				t.Fatalf("ValidateDNSRecord(%+v) = %v, want ok %v", tt.record, err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestSplitTXTStrings(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{"v=spf1 -all", []string{"v=spf1 -all"}},
+		{`"a b" "c"`, []string{"a b", "c"}},
+		{`"say \"hi\"" "back\\slash"`, []string{`say "hi"`, `back\slash`}},
+		{`""`, []string{""}},
+	}
+	for _, tt := range tests {
+		got, err := SplitTXTStrings(tt.value)
+		if err != nil || !slices.Equal(got, tt.want) {
+			t.Fatalf("SplitTXTStrings(%s) = %q, %v; want %q", tt.value, got, err, tt.want)
+		}
+	}
+
+	for _, value := range []string{"", `"a" b`, `"a" "b`} {
+		if got, err := SplitTXTStrings(value); err == nil {
+			t.Fatalf("SplitTXTStrings(%s) = %q", value, got)
+		}
+	}
+}
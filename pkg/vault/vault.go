@@ -0,0 +1,164 @@
+package vault
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"domain-admin/pkg/secretbox"
+)
+
+// DefaultKeyEnv 默认读取主密钥的环境变量
+const DefaultKeyEnv = "DOMAIN_ADMIN_VAULT_KEY"
+
+var (
+	// ErrNoKey 没有配置主密钥
+	ErrNoKey = errors.New("vault: no master key configured")
+	// ErrUnknownKey 密文使用的主密钥不在密钥环中
+	ErrUnknownKey = errors.New("vault: unknown master key")
+)
+
+// Sealed 信封加密的结果
+// 明文由随机生成的数据密钥加密，数据密钥再由主密钥加密，KeyID 标识所用的主密钥
+type Sealed struct {
+	KeyID      string
+	DataKey    string
+	Ciphertext string
+}
+
+// Keyring 主密钥环，第一个密钥为当前主密钥，其余密钥只用于解密轮换前的数据
+type Keyring struct {
+	primary string
+	order   []string
+	keys    map[string]*secretbox.Box
+}
+
+// Parse 解析主密钥列表，多个密钥以逗号或换行分隔，第一个为当前主密钥
+// 每个密钥的格式为 "id:key" 或 "key"，省略 id 时使用密钥指纹；
+// key 为 base64 编码的 32 字节密钥，与 secretbox.ParseKey 相同
+func Parse(s string) (*Keyring, error) {
+	k := &Keyring{keys: make(map[string]*secretbox.Box)}
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == '\n' || r == '\r' })
+	for _, field := range fields {
+		field = strings.TrimSpace(field)
+		if field == "" || strings.HasPrefix(field, "#") {
+			continue
+		}
+
+		id, raw := "", field
+		if i := strings.Index(field, ":"); i > 0 {
+			id, raw = strings.TrimSpace(field[:i]), strings.TrimSpace(field[i+1:])
+		}
+		key, err := secretbox.ParseKey(raw)
+		if err != nil {
+			return nil, err
+		}
+		if id == "" {
+			id = fingerprint(key)
+		}
+		if _, ok := k.keys[id]; ok {
+			return nil, fmt.Errorf("vault: duplicate key id %q", id)
+		}
+		box, err := secretbox.New(key)
+		if err != nil {
+			return nil, err
+		}
+		k.keys[id] = box
+		k.order = append(k.order, id)
+	}
+	if len(k.order) == 0 {
+		return nil, ErrNoKey
+	}
+	k.primary = k.order[0]
+	return k, nil
+}
+
+// Load 从环境变量或密钥文件读取主密钥，环境变量优先；env 为空时使用 DefaultKeyEnv
+func Load(env, file string) (*Keyring, error) {
+	if env == "" {
+		env = DefaultKeyEnv
+	}
+	if s := os.Getenv(env); strings.TrimSpace(s) != "" {
+		return Parse(s)
+	}
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("vault: read key file: %w", err)
+		}
+		return Parse(string(data))
+	}
+	return nil, ErrNoKey
+}
+
+// Primary 返回当前主密钥ID
+func (k *Keyring) Primary() string {
+	return k.primary
+}
+
+// KeyIDs 返回密钥环中的全部密钥ID，第一个为当前主密钥
+func (k *Keyring) KeyIDs() []string {
+	return append([]string(nil), k.order...)
+}
+
+// Seal 使用新的数据密钥加密明文，并用当前主密钥加密数据密钥
+func (k *Keyring) Seal(plaintext []byte) (*Sealed, error) {
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, err
+	}
+	box, err := secretbox.New(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := box.Seal(plaintext)
+	if err != nil {
+		return nil, err
+	}
+	wrapped, err := k.keys[k.primary].Seal(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Sealed{KeyID: k.primary, DataKey: wrapped, Ciphertext: ciphertext}, nil
+}
+
+// Open 解密 Seal 的结果
+func (k *Keyring) Open(s *Sealed) ([]byte, error) {
+	master, ok := k.keys[s.KeyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, s.KeyID)
+	}
+	dataKey, err := master.Open(s.DataKey)
+	if err != nil {
+		return nil, err
+	}
+	box, err := secretbox.New(dataKey)
+	if err != nil {
+		return nil, secretbox.ErrInvalidCiphertext
+	}
+	return box.Open(s.Ciphertext)
+}
+
+// NeedsRotation 密文是否不是由当前主密钥加密
+func (k *Keyring) NeedsRotation(s *Sealed) bool {
+	return s.KeyID != k.primary
+}
+
+// Rotate 解密后使用新的数据密钥和当前主密钥重新加密
+func (k *Keyring) Rotate(s *Sealed) (*Sealed, error) {
+	plaintext, err := k.Open(s)
+	if err != nil {
+		return nil, err
+	}
+	return k.Seal(plaintext)
+}
+
+// fingerprint 返回密钥的短指纹，用作默认密钥ID
+func fingerprint(key []byte) string {
+	sum := sha256.Sum256(append([]byte("domain-admin-vault:"), key...))
+	return hex.EncodeToString(sum[:4])
+}
@@ -0,0 +1,140 @@
+package vault
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newKey(t *testing.T) string {
+	t.Helper()
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+func TestSealOpen(t *testing.T) {
+	k, err := Parse("k1:" + newKey(t))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	a, err := k.Seal([]byte("secret"))
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	b, _ := k.Seal([]byte("secret"))
+	if a.KeyID != "k1" || a.DataKey == b.DataKey || a.Ciphertext == b.Ciphertext {
+		t.Errorf("sealed = %+v, %+v", a, b)
+	}
+
+	plaintext, err := k.Open(a)
+	if err != nil || string(plaintext) != "secret" {
+		t.Fatalf("Open = %q, %v", plaintext, err)
+	}
+
+	// 篡改数据密钥或密文都无法解密
+	tampered := *a
+	tampered.DataKey = b.DataKey
+	if _, err := k.Open(&tampered); err == nil {
+		t.Error("Open accepted a data key from another record")
+	}
+	tampered = *a
+	tampered.KeyID = "k2"
+	if _, err := k.Open(&tampered); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Open with unknown key id: %v", err)
+	}
+}
+
+func TestRotate(t *testing.T) {
+	oldKey, newKey := newKey(t), newKey(t)
+	before, _ := Parse("old:" + oldKey)
+	sealed, err := before.Seal([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 新密钥放在第一位成为主密钥，旧密钥保留用于解密
+	after, err := Parse("new:" + newKey + "\nold:" + oldKey)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if after.Primary() != "new" || !after.NeedsRotation(sealed) {
+		t.Fatalf("primary = %s, needs rotation = %v", after.Primary(), after.NeedsRotation(sealed))
+	}
+	rotated, err := after.Rotate(sealed)
+	if err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	if rotated.KeyID != "new" || after.NeedsRotation(rotated) {
+		t.Errorf("rotated = %+v", rotated)
+	}
+
+	// 移除旧密钥后仍能解密轮换后的数据
+	only, _ := Parse("new:" + newKey)
+	if plaintext, err := only.Open(rotated); err != nil || string(plaintext) != "secret" {
+		t.Errorf("Open rotated = %q, %v", plaintext, err)
+	}
+	if _, err := only.Open(sealed); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Open old data without old key: %v", err)
+	}
+}
+
+func TestParse(t *testing.T) {
+	if _, err := Parse(" , \n# comment\n"); !errors.Is(err, ErrNoKey) {
+		t.Errorf("Parse empty: %v", err)
+	}
+	key, other := newKey(t), newKey(t)
+	if _, err := Parse("a:" + key + ",a:" + other); err == nil || !strings.Contains(err.Error(), "duplicate") {
+		t.Errorf("Parse duplicate key ids: %v", err)
+	}
+
+	// 口令和长度不足的密钥都不能作为主密钥
+	short := base64.StdEncoding.EncodeToString([]byte("too short"))
+	for _, s := range []string{"passphrase", "k1:correct horse battery staple", short} {
+		if _, err := Parse(s); err == nil {
+			t.Errorf("Parse(%q) accepted a non-random key", s)
+		}
+	}
+
+	// 省略 id 时使用稳定的密钥指纹
+	k1, err := Parse(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k2, _ := Parse(key + ", " + other)
+	if k1.Primary() == "" || k1.Primary() != k2.Primary() || len(k2.KeyIDs()) != 2 {
+		t.Errorf("ids = %v, %v", k1.KeyIDs(), k2.KeyIDs())
+	}
+}
+
+func TestLoad(t *testing.T) {
+	const env = "VAULT_TEST_KEY"
+	file := filepath.Join(t.TempDir(), "vault.key")
+	if err := os.WriteFile(file, []byte("file:"+newKey(t)+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(env, "")
+	k, err := Load(env, file)
+	if err != nil || k.Primary() != "file" {
+		t.Fatalf("Load from file = %v, %v", k, err)
+	}
+
+	t.Setenv(env, "env:"+newKey(t))
+	k, err = Load(env, file)
+	if err != nil || k.Primary() != "env" {
+		t.Fatalf("Load from env = %v, %v", k, err)
+	}
+
+	t.Setenv(env, "")
+	if _, err := Load(env, ""); !errors.Is(err, ErrNoKey) {
+		t.Errorf("Load without key: %v", err)
+	}
+}
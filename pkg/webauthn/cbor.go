@@ -0,0 +1,153 @@
+package webauthn
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// maxCBORDepth 解码时允许的最大嵌套层数
+const maxCBORDepth = 16
+
+var errCBORTruncated = errors.New("webauthn: cbor data truncated")
+
+// decodeCBOR 解码一个CBOR数据项并返回剩余字节。
+// 只支持WebAuthn用到的确定长度编码：整数解码为 int64，字节串为 []byte，文本为 string，
+// 数组为 []interface{}，映射为 map[interface{}]interface{}，标签只保留内容
+func decodeCBOR(data []byte) (interface{}, []byte, error) {
+	return decodeItem(data, 0)
+}
+
+func decodeItem(data []byte, depth int) (interface{}, []byte, error) {
+	if depth > maxCBORDepth {
+		return nil, nil, errors.New("webauthn: cbor nesting too deep")
+	}
+	if len(data) == 0 {
+		return nil, nil, errCBORTruncated
+	}
+	major, info := data[0]>>5, data[0]&0x1f
+	data = data[1:]
+
+	if major == 7 {
+		return decodeSimple(info, data)
+	}
+
+	n, data, err := readArgument(info, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch major {
+	case 0:
+		if n > math.MaxInt64 {
+			return nil, nil, errors.New("webauthn: cbor integer overflow")
+		}
+		return int64(n), data, nil
+	case 1:
+		if n > math.MaxInt64 {
+			return nil, nil, errors.New("webauthn: cbor integer overflow")
+		}
+		return -1 - int64(n), data, nil
+	case 2, 3:
+		if n > uint64(len(data)) {
+			return nil, nil, errCBORTruncated
+		}
+		if major == 2 {
+			return append([]byte(nil), data[:n]...), data[n:], nil
+		}
+		return string(data[:n]), data[n:], nil
+	case 4:
+		// 每个元素至少占1字节，长度超过剩余字节时数据必然不完整
+		if n > uint64(len(data)) {
+			return nil, nil, errCBORTruncated
+		}
+		items := make([]interface{}, 0, n)
+		for i := uint64(0); i < n; i++ {
+			var item interface{}
+			item, data, err = decodeItem(data, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			items = append(items, item)
+		}
+		return items, data, nil
+	case 5:
+		if n > uint64(len(data))/2 {
+			return nil, nil, errCBORTruncated
+		}
+		m := make(map[interface{}]interface{}, n)
+		for i := uint64(0); i < n; i++ {
+			var key, value interface{}
+			key, data, err = decodeItem(data, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			switch key.(type) {
+			case int64, string:
+			default:
+				return nil, nil, fmt.Errorf("webauthn: unsupported cbor map key %T", key)
+			}
+			value, data, err = decodeItem(data, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			if _, ok := m[key]; ok {
+				return nil, nil, fmt.Errorf("webauthn: duplicate cbor map key %v", key)
+			}
+			m[key] = value
+		}
+		return m, data, nil
+	default: // 6: 标签
+		return decodeItem(data, depth+1)
+	}
+}
+
+// readArgument 读取数据项头部的参数（长度或整数值）
+func readArgument(info byte, data []byte) (uint64, []byte, error) {
+	switch {
+	case info < 24:
+		return uint64(info), data, nil
+	case info == 24:
+		if len(data) < 1 {
+			return 0, nil, errCBORTruncated
+		}
+		return uint64(data[0]), data[1:], nil
+	case info == 25:
+		if len(data) < 2 {
+			return 0, nil, errCBORTruncated
+		}
+		return uint64(binary.BigEndian.Uint16(data)), data[2:], nil
+	case info == 26:
+		if len(data) < 4 {
+			return 0, nil, errCBORTruncated
+		}
+		return uint64(binary.BigEndian.Uint32(data)), data[4:], nil
+	case info == 27:
+		if len(data) < 8 {
+			return 0, nil, errCBORTruncated
+		}
+		return binary.BigEndian.Uint64(data), data[8:], nil
+	default:
+		return 0, nil, errors.New("webauthn: indefinite-length cbor is not supported")
+	}
+}
+
+// decodeSimple 解码简单值和浮点数，浮点数只跳过不解析
+func decodeSimple(info byte, data []byte) (interface{}, []byte, error) {
+	switch info {
+	case 20:
+		return false, data, nil
+	case 21:
+		return true, data, nil
+	case 22, 23:
+		return nil, data, nil
+	case 25, 26, 27:
+		size := 1 << (info - 24)
+		if len(data) < size {
+			return nil, nil, errCBORTruncated
+		}
+		return nil, data[size:], nil
+	default:
+		return nil, nil, fmt.Errorf("webauthn: unsupported cbor simple value %d", info)
+	}
+}
@@ -0,0 +1,118 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// 支持的COSE签名算法
+const (
+	AlgES256 int64 = -7
+	AlgEdDSA int64 = -8
+	AlgRS256 int64 = -257
+)
+
+// COSE_Key 参数标签和取值（RFC 9053）
+const (
+	coseKty    = 1
+	coseAlg    = 3
+	coseCrv    = -1 // EC2/OKP 的曲线，RSA 的模数 n
+	coseX      = -2 // EC2/OKP 的 x 坐标，RSA 的指数 e
+	coseY      = -3
+	ktyOKP     = 1
+	ktyEC2     = 2
+	ktyRSA     = 3
+	crvP256    = 1
+	crvEd25519 = 6
+)
+
+// minRSABits RSA公钥的最小长度
+const minRSABits = 2048
+
+// parsePublicKey 解析 COSE_Key 编码的公钥，返回公钥和算法
+func parsePublicKey(coseKey []byte) (crypto.PublicKey, int64, error) {
+	item, rest, err := decodeCBOR(coseKey)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(rest) != 0 {
+		return nil, 0, errors.New("webauthn: trailing data after public key")
+	}
+	m, ok := item.(map[interface{}]interface{})
+	if !ok {
+		return nil, 0, errors.New("webauthn: public key is not a cbor map")
+	}
+	kty, _ := m[int64(coseKty)].(int64)
+	alg, _ := m[int64(coseAlg)].(int64)
+
+	switch {
+	case kty == ktyEC2 && alg == AlgES256:
+		crv, _ := m[int64(coseCrv)].(int64)
+		x, _ := m[int64(coseX)].([]byte)
+		y, _ := m[int64(coseY)].([]byte)
+		if crv != crvP256 || len(x) != 32 || len(y) != 32 {
+			return nil, 0, errors.New("webauthn: invalid P-256 public key")
+		}
+		// 通过 ecdh 校验坐标在曲线上
+		point := append(append([]byte{0x04}, x...), y...)
+		if _, err := ecdh.P256().NewPublicKey(point); err != nil {
+			return nil, 0, fmt.Errorf("webauthn: invalid P-256 public key: %w", err)
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, alg, nil
+	case kty == ktyOKP && alg == AlgEdDSA:
+		crv, _ := m[int64(coseCrv)].(int64)
+		x, _ := m[int64(coseX)].([]byte)
+		if crv != crvEd25519 || len(x) != ed25519.PublicKeySize {
+			return nil, 0, errors.New("webauthn: invalid Ed25519 public key")
+		}
+		return ed25519.PublicKey(x), alg, nil
+	case kty == ktyRSA && alg == AlgRS256:
+		n, _ := m[int64(coseCrv)].([]byte)
+		e, _ := m[int64(coseX)].([]byte)
+		if len(e) == 0 || len(e) > 4 {
+			return nil, 0, errors.New("webauthn: invalid RSA public exponent")
+		}
+		pub := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		if pub.N.BitLen() < minRSABits || pub.E < 3 || pub.E%2 == 0 {
+			return nil, 0, errors.New("webauthn: RSA public key is too weak")
+		}
+		return pub, alg, nil
+	default:
+		return nil, 0, fmt.Errorf("webauthn: unsupported public key type %d with algorithm %d", kty, alg)
+	}
+}
+
+// verifySignature 使用 COSE_Key 公钥校验签名
+func verifySignature(coseKey, signed, sig []byte) error {
+	pub, alg, err := parsePublicKey(coseKey)
+	if err != nil {
+		return err
+	}
+	return verifyWith(pub, alg, signed, sig)
+}
+
+func verifyWith(pub crypto.PublicKey, alg int64, signed, sig []byte) error {
+	ok := false
+	switch alg {
+	case AlgES256:
+		digest := sha256.Sum256(signed)
+		ok = ecdsa.VerifyASN1(pub.(*ecdsa.PublicKey), digest[:], sig)
+	case AlgEdDSA:
+		ok = ed25519.Verify(pub.(ed25519.PublicKey), signed, sig)
+	case AlgRS256:
+		digest := sha256.Sum256(signed)
+		ok = rsa.VerifyPKCS1v15(pub.(*rsa.PublicKey), crypto.SHA256, digest[:], sig) == nil
+	}
+	if !ok {
+		return ErrSignature
+	}
+	return nil
+}
@@ -0,0 +1,501 @@
+// Package webauthn 实现 WebAuthn 依赖方的注册和认证校验，支持 ES256、RS256 和 EdDSA 凭据。
+// 注册时请求 attestation=none，不校验认证器的证明证书链
+package webauthn
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Timeout 浏览器等待用户操作认证器的时间
+const Timeout = 5 * time.Minute
+
+// 用户验证要求
+const (
+	VerificationRequired    = "required"
+	VerificationPreferred   = "preferred"
+	VerificationDiscouraged = "discouraged"
+)
+
+// authenticatorData 的标志位
+const (
+	flagUserPresent      = 0x01
+	flagUserVerified     = 0x04
+	flagBackupEligible   = 0x08
+	flagBackupState      = 0x10
+	flagAttestedCredData = 0x40
+	flagExtensionData    = 0x80
+)
+
+var (
+	// ErrSignature 签名校验失败
+	ErrSignature = errors.New("webauthn: signature verification failed")
+	// ErrSignCount 签名计数没有增加，认证器可能被克隆
+	ErrSignCount = errors.New("webauthn: signature counter did not increase")
+	// ErrUserVerification 认证器未完成用户验证（PIN、指纹等）
+	ErrUserVerification = errors.New("webauthn: user verification required")
+)
+
+// RelyingParty 依赖方配置，Origins 为允许发起认证的页面来源，为空时只允许 https://<ID>
+type RelyingParty struct {
+	ID      string
+	Name    string
+	Origins []string
+}
+
+// UserEntity 注册时的用户信息，ID 为 base64url 编码的用户句柄
+type UserEntity struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+// CredentialDescriptor 凭据描述，ID 为 base64url 编码的凭据ID
+type CredentialDescriptor struct {
+	Type       string   `json:"type"`
+	ID         string   `json:"id"`
+	Transports []string `json:"transports,omitempty"`
+}
+
+// CredentialParameter 支持的凭据算法
+type CredentialParameter struct {
+	Type string `json:"type"`
+	Alg  int64  `json:"alg"`
+}
+
+// AuthenticatorSelection 认证器选择条件
+type AuthenticatorSelection struct {
+	ResidentKey      string `json:"residentKey"`
+	UserVerification string `json:"userVerification"`
+}
+
+// CreationOptions 注册选项，对应 PublicKeyCredentialCreationOptions 的JSON形式
+type CreationOptions struct {
+	RP struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"rp"`
+	User                   UserEntity             `json:"user"`
+	Challenge              string                 `json:"challenge"`
+	PubKeyCredParams       []CredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int64                  `json:"timeout"`
+	ExcludeCredentials     []CredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection AuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                 `json:"attestation"`
+}
+
+// RequestOptions 认证选项，对应 PublicKeyCredentialRequestOptions 的JSON形式
+type RequestOptions struct {
+	Challenge        string                 `json:"challenge"`
+	Timeout          int64                  `json:"timeout"`
+	RPID             string                 `json:"rpId"`
+	AllowCredentials []CredentialDescriptor `json:"allowCredentials"`
+	UserVerification string                 `json:"userVerification"`
+}
+
+// AttestationResponse 注册结果，对应 PublicKeyCredential.toJSON()，二进制字段为 base64url 编码
+type AttestationResponse struct {
+	ID       string `json:"id"`
+	RawID    string `json:"rawId"`
+	Type     string `json:"type"`
+	Response struct {
+		ClientDataJSON    string   `json:"clientDataJSON"`
+		AttestationObject string   `json:"attestationObject"`
+		Transports        []string `json:"transports"`
+	} `json:"response"`
+}
+
+// AssertionResponse 认证结果，对应 PublicKeyCredential.toJSON()，二进制字段为 base64url 编码
+type AssertionResponse struct {
+	ID       string `json:"id"`
+	RawID    string `json:"rawId"`
+	Type     string `json:"type"`
+	Response struct {
+		ClientDataJSON    string `json:"clientDataJSON"`
+		AuthenticatorData string `json:"authenticatorData"`
+		Signature         string `json:"signature"`
+		UserHandle        string `json:"userHandle"`
+	} `json:"response"`
+}
+
+// Credential 注册成功的凭据，PublicKey 为 COSE_Key 编码的公钥
+type Credential struct {
+	ID             []byte
+	PublicKey      []byte
+	Algorithm      int64
+	SignCount      uint32
+	AAGUID         []byte
+	Transports     []string
+	UserVerified   bool
+	BackupEligible bool
+	BackupState    bool
+}
+
+// Assertion 认证成功的结果，UserHandle 为认证器返回的用户句柄（可能为空）
+type Assertion struct {
+	SignCount    uint32
+	UserVerified bool
+	BackupState  bool
+	UserHandle   []byte
+}
+
+// clientData 浏览器生成的客户端数据
+type clientData struct {
+	Type        string `json:"type"`
+	Challenge   string `json:"challenge"`
+	Origin      string `json:"origin"`
+	CrossOrigin bool   `json:"crossOrigin"`
+}
+
+// authData 解析后的认证器数据
+type authData struct {
+	rpIDHash  []byte
+	flags     byte
+	signCount uint32
+	aaguid    []byte
+	credID    []byte
+	publicKey []byte
+}
+
+// NewChallenge 生成32字节随机挑战，返回 base64url 编码
+func NewChallenge() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return EncodeID(buf), nil
+}
+
+// EncodeID 将二进制数据编码为 base64url（无填充）
+func EncodeID(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeID 解码 base64url 数据，兼容带填充的编码
+func DecodeID(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+// CreationOptions 生成注册选项，exclude 为用户已注册的凭据，避免同一认证器重复注册
+func (rp *RelyingParty) CreationOptions(user UserEntity, challenge string, exclude []CredentialDescriptor, userVerification string) *CreationOptions {
+	opts := &CreationOptions{
+		User:      user,
+		Challenge: challenge,
+		PubKeyCredParams: []CredentialParameter{
+			{Type: "public-key", Alg: AlgES256},
+			{Type: "public-key", Alg: AlgEdDSA},
+			{Type: "public-key", Alg: AlgRS256},
+		},
+		Timeout:            Timeout.Milliseconds(),
+		ExcludeCredentials: exclude,
+		AuthenticatorSelection: AuthenticatorSelection{
+			ResidentKey:      "preferred",
+			UserVerification: userVerification,
+		},
+		Attestation: "none",
+	}
+	if opts.ExcludeCredentials == nil {
+		opts.ExcludeCredentials = []CredentialDescriptor{}
+	}
+	opts.RP.ID = rp.ID
+	opts.RP.Name = rp.Name
+	return opts
+}
+
+// RequestOptions 生成认证选项，allow 为空时由认证器选择可发现凭据（无用户名登录）
+func (rp *RelyingParty) RequestOptions(challenge string, allow []CredentialDescriptor, userVerification string) *RequestOptions {
+	if allow == nil {
+		allow = []CredentialDescriptor{}
+	}
+	return &RequestOptions{
+		Challenge:        challenge,
+		Timeout:          Timeout.Milliseconds(),
+		RPID:             rp.ID,
+		AllowCredentials: allow,
+		UserVerification: userVerification,
+	}
+}
+
+// Challenge 返回客户端数据中的挑战，用于查找对应的注册会话
+func (r *AttestationResponse) Challenge() (string, error) {
+	cd, _, err := parseClientData(r.Response.ClientDataJSON)
+	if err != nil {
+		return "", err
+	}
+	return cd.Challenge, nil
+}
+
+// Challenge 返回客户端数据中的挑战，用于查找对应的认证会话
+func (r *AssertionResponse) Challenge() (string, error) {
+	cd, _, err := parseClientData(r.Response.ClientDataJSON)
+	if err != nil {
+		return "", err
+	}
+	return cd.Challenge, nil
+}
+
+// CredentialID 返回解码后的凭据ID
+func (r *AssertionResponse) CredentialID() ([]byte, error) {
+	raw := r.RawID
+	if raw == "" {
+		raw = r.ID
+	}
+	id, err := DecodeID(raw)
+	if err != nil || len(id) == 0 {
+		return nil, errors.New("webauthn: invalid credential id")
+	}
+	return id, nil
+}
+
+// VerifyRegistration 校验注册结果，challenge 为本次注册生成的挑战
+func (rp *RelyingParty) VerifyRegistration(resp *AttestationResponse, challenge string, requireUV bool) (*Credential, error) {
+	if resp.Type != "public-key" {
+		return nil, errors.New("webauthn: credential type must be public-key")
+	}
+	cd, cdHash, err := parseClientData(resp.Response.ClientDataJSON)
+	if err != nil {
+		return nil, err
+	}
+	if err := rp.checkClientData(cd, "webauthn.create", challenge); err != nil {
+		return nil, err
+	}
+
+	raw, err := DecodeID(resp.Response.AttestationObject)
+	if err != nil {
+		return nil, errors.New("webauthn: invalid attestation object encoding")
+	}
+	item, _, err := decodeCBOR(raw)
+	if err != nil {
+		return nil, err
+	}
+	obj, ok := item.(map[interface{}]interface{})
+	if !ok {
+		return nil, errors.New("webauthn: attestation object is not a cbor map")
+	}
+	format, _ := obj["fmt"].(string)
+	stmt, _ := obj["attStmt"].(map[interface{}]interface{})
+	rawAuthData, _ := obj["authData"].([]byte)
+
+	ad, err := parseAuthData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+	if err := rp.checkAuthData(ad, requireUV); err != nil {
+		return nil, err
+	}
+	if ad.flags&flagAttestedCredData == 0 {
+		return nil, errors.New("webauthn: attested credential data missing")
+	}
+	pub, alg, err := parsePublicKey(ad.publicKey)
+	if err != nil {
+		return nil, err
+	}
+	if resp.RawID != "" {
+		rawID, err := DecodeID(resp.RawID)
+		if err != nil || !bytes.Equal(rawID, ad.credID) {
+			return nil, errors.New("webauthn: credential id mismatch")
+		}
+	}
+
+	switch format {
+	case "none":
+		if len(stmt) != 0 {
+			return nil, errors.New("webauthn: none attestation must have an empty statement")
+		}
+	case "packed":
+		// 只校验自证明；带证书链的证明不校验证书，按 none 处理
+		if _, hasX5C := stmt["x5c"]; !hasX5C {
+			stmtAlg, _ := stmt["alg"].(int64)
+			sig, _ := stmt["sig"].([]byte)
+			if stmtAlg != alg {
+				return nil, errors.New("webauthn: packed attestation algorithm mismatch")
+			}
+			if err := verifyWith(pub, alg, concat(rawAuthData, cdHash), sig); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &Credential{
+		ID:             ad.credID,
+		PublicKey:      ad.publicKey,
+		Algorithm:      alg,
+		SignCount:      ad.signCount,
+		AAGUID:         ad.aaguid,
+		Transports:     resp.Response.Transports,
+		UserVerified:   ad.flags&flagUserVerified != 0,
+		BackupEligible: ad.flags&flagBackupEligible != 0,
+		BackupState:    ad.flags&flagBackupState != 0,
+	}, nil
+}
+
+// VerifyAssertion 校验认证结果，cred 为注册时保存的凭据（SignCount 为上次的签名计数）。
+// 签名计数没有增加时返回 ErrSignCount，两次计数都为0表示认证器不支持计数
+func (rp *RelyingParty) VerifyAssertion(resp *AssertionResponse, challenge string, cred *Credential, requireUV bool) (*Assertion, error) {
+	if resp.Type != "public-key" {
+		return nil, errors.New("webauthn: credential type must be public-key")
+	}
+	credID, err := resp.CredentialID()
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(credID, cred.ID) {
+		return nil, errors.New("webauthn: credential id mismatch")
+	}
+	cd, cdHash, err := parseClientData(resp.Response.ClientDataJSON)
+	if err != nil {
+		return nil, err
+	}
+	if err := rp.checkClientData(cd, "webauthn.get", challenge); err != nil {
+		return nil, err
+	}
+
+	rawAuthData, err := DecodeID(resp.Response.AuthenticatorData)
+	if err != nil {
+		return nil, errors.New("webauthn: invalid authenticator data encoding")
+	}
+	ad, err := parseAuthData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+	if err := rp.checkAuthData(ad, requireUV); err != nil {
+		return nil, err
+	}
+
+	sig, err := DecodeID(resp.Response.Signature)
+	if err != nil {
+		return nil, errors.New("webauthn: invalid signature encoding")
+	}
+	if err := verifySignature(cred.PublicKey, concat(rawAuthData, cdHash), sig); err != nil {
+		return nil, err
+	}
+
+	if (ad.signCount != 0 || cred.SignCount != 0) && ad.signCount <= cred.SignCount {
+		return nil, ErrSignCount
+	}
+
+	var userHandle []byte
+	if resp.Response.UserHandle != "" {
+		if userHandle, err = DecodeID(resp.Response.UserHandle); err != nil {
+			return nil, errors.New("webauthn: invalid user handle encoding")
+		}
+	}
+	return &Assertion{
+		SignCount:    ad.signCount,
+		UserVerified: ad.flags&flagUserVerified != 0,
+		BackupState:  ad.flags&flagBackupState != 0,
+		UserHandle:   userHandle,
+	}, nil
+}
+
+// checkClientData 校验客户端数据的类型、挑战和来源
+func (rp *RelyingParty) checkClientData(cd *clientData, typ, challenge string) error {
+	if cd.Type != typ {
+		return fmt.Errorf("webauthn: client data type must be %s", typ)
+	}
+	if challenge == "" || subtle.ConstantTimeCompare([]byte(cd.Challenge), []byte(challenge)) != 1 {
+		return errors.New("webauthn: challenge mismatch")
+	}
+	if cd.CrossOrigin {
+		return errors.New("webauthn: cross-origin requests are not allowed")
+	}
+	origins := rp.Origins
+	if len(origins) == 0 {
+		origins = []string{"https://" + rp.ID}
+	}
+	for _, origin := range origins {
+		if cd.Origin == strings.TrimRight(origin, "/") {
+			return nil
+		}
+	}
+	return fmt.Errorf("webauthn: origin %s is not allowed", cd.Origin)
+}
+
+// checkAuthData 校验依赖方ID哈希和用户在场、用户验证标志
+func (rp *RelyingParty) checkAuthData(ad *authData, requireUV bool) error {
+	expected := sha256.Sum256([]byte(rp.ID))
+	if subtle.ConstantTimeCompare(ad.rpIDHash, expected[:]) != 1 {
+		return errors.New("webauthn: rp id hash mismatch")
+	}
+	if ad.flags&flagUserPresent == 0 {
+		return errors.New("webauthn: user not present")
+	}
+	if requireUV && ad.flags&flagUserVerified == 0 {
+		return ErrUserVerification
+	}
+	if ad.flags&flagBackupState != 0 && ad.flags&flagBackupEligible == 0 {
+		return errors.New("webauthn: invalid backup flags")
+	}
+	return nil
+}
+
+// parseClientData 解码客户端数据，同时返回其 SHA-256 哈希
+func parseClientData(encoded string) (*clientData, []byte, error) {
+	raw, err := DecodeID(encoded)
+	if err != nil {
+		return nil, nil, errors.New("webauthn: invalid client data encoding")
+	}
+	var cd clientData
+	if err := json.Unmarshal(raw, &cd); err != nil {
+		return nil, nil, errors.New("webauthn: invalid client data")
+	}
+	hash := sha256.Sum256(raw)
+	return &cd, hash[:], nil
+}
+
+// parseAuthData 解析认证器数据，包含凭据数据时同时解析凭据ID和公钥
+func parseAuthData(data []byte) (*authData, error) {
+	if len(data) < 37 {
+		return nil, errors.New("webauthn: authenticator data too short")
+	}
+	ad := &authData{
+		rpIDHash:  data[:32],
+		flags:     data[32],
+		signCount: binary.BigEndian.Uint32(data[33:37]),
+	}
+	rest := data[37:]
+	if ad.flags&flagAttestedCredData != 0 {
+		if len(rest) < 18 {
+			return nil, errors.New("webauthn: attested credential data too short")
+		}
+		ad.aaguid = rest[:16]
+		n := int(binary.BigEndian.Uint16(rest[16:18]))
+		rest = rest[18:]
+		if n == 0 || n > 1023 || len(rest) < n {
+			return nil, errors.New("webauthn: invalid credential id length")
+		}
+		ad.credID = rest[:n]
+		rest = rest[n:]
+		_, after, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, err
+		}
+		ad.publicKey = rest[:len(rest)-len(after)]
+		rest = after
+	}
+	if ad.flags&flagExtensionData != 0 {
+		_, after, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, err
+		}
+		rest = after
+	}
+	if len(rest) != 0 {
+		return nil, errors.New("webauthn: trailing data after authenticator data")
+	}
+	return ad, nil
+}
+
+func concat(a, b []byte) []byte {
+	out := make([]byte, 0, len(a)+len(b))
+	return append(append(out, a...), b...)
+}
@@ -0,0 +1,215 @@
+package webauthn
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// 测试用的CBOR编码，kv 保持映射的键顺序
+type kv struct{ k, v interface{} }
+
+func encodeCBOR(v interface{}) []byte {
+	head := func(major byte, n uint64) []byte {
+		switch {
+		case n < 24:
+			return []byte{major<<5 | byte(n)}
+		case n < 256:
+			return []byte{major<<5 | 24, byte(n)}
+		default:
+			b := []byte{major<<5 | 25, 0, 0}
+			binary.BigEndian.PutUint16(b[1:], uint16(n))
+			return b
+		}
+	}
+	switch x := v.(type) {
+	case int:
+		if x < 0 {
+			return head(1, uint64(-1-x))
+		}
+		return head(0, uint64(x))
+	case int64:
+		return encodeCBOR(int(x))
+	case []byte:
+		return append(head(2, uint64(len(x))), x...)
+	case string:
+		return append(head(3, uint64(len(x))), x...)
+	case []kv:
+		out := head(5, uint64(len(x)))
+		for _, p := range x {
+			out = append(out, encodeCBOR(p.k)...)
+			out = append(out, encodeCBOR(p.v)...)
+		}
+		return out
+	}
+	panic("unsupported")
+}
+
+// softAuthenticator 软件认证器，用于模拟浏览器和安全密钥
+type softAuthenticator struct {
+	rpID   string
+	origin string
+	credID []byte
+	key    *ecdsa.PrivateKey
+	count  uint32
+	flags  byte
+}
+
+func newSoftAuthenticator(t *testing.T, rpID, origin string) *softAuthenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := make([]byte, 16)
+	rand.Read(id)
+	return &softAuthenticator{rpID: rpID, origin: origin, credID: id, key: key, flags: flagUserPresent | flagUserVerified}
+}
+
+func (a *softAuthenticator) clientData(typ, challenge string) []byte {
+	b, _ := json.Marshal(map[string]interface{}{"type": typ, "challenge": challenge, "origin": a.origin})
+	return b
+}
+
+func (a *softAuthenticator) authData(flags byte, attested []byte) []byte {
+	hash := sha256.Sum256([]byte(a.rpID))
+	out := append(hash[:], flags, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(out[33:], a.count)
+	return append(out, attested...)
+}
+
+func (a *softAuthenticator) register(challenge string) *AttestationResponse {
+	x := a.key.PublicKey.X.FillBytes(make([]byte, 32))
+	y := a.key.PublicKey.Y.FillBytes(make([]byte, 32))
+	coseKey := encodeCBOR([]kv{{1, 2}, {3, -7}, {-1, 1}, {-2, x}, {-3, y}})
+	attested := make([]byte, 16, 16+2+len(a.credID)+len(coseKey))
+	attested = binary.BigEndian.AppendUint16(attested, uint16(len(a.credID)))
+	attested = append(append(attested, a.credID...), coseKey...)
+
+	obj := encodeCBOR([]kv{
+		{"fmt", "none"},
+		{"attStmt", []kv{}},
+		{"authData", a.authData(a.flags|flagAttestedCredData, attested)},
+	})
+	resp := &AttestationResponse{ID: EncodeID(a.credID), RawID: EncodeID(a.credID), Type: "public-key"}
+	resp.Response.ClientDataJSON = EncodeID(a.clientData("webauthn.create", challenge))
+	resp.Response.AttestationObject = EncodeID(obj)
+	return resp
+}
+
+func (a *softAuthenticator) assert(t *testing.T, challenge string) *AssertionResponse {
+	a.count++
+	cd := a.clientData("webauthn.get", challenge)
+	ad := a.authData(a.flags, nil)
+	cdHash := sha256.Sum256(cd)
+	digest := sha256.Sum256(append(append([]byte{}, ad...), cdHash[:]...))
+	sig, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := &AssertionResponse{ID: EncodeID(a.credID), RawID: EncodeID(a.credID), Type: "public-key"}
+	resp.Response.ClientDataJSON = EncodeID(cd)
+	resp.Response.AuthenticatorData = EncodeID(ad)
+	resp.Response.Signature = EncodeID(sig)
+	resp.Response.UserHandle = EncodeID([]byte("1"))
+	return resp
+}
+
+func TestRegisterAndAssert(t *testing.T) {
+	rp := &RelyingParty{ID: "example.com", Name: "Example", Origins: []string{"https://example.com"}}
+	auth := newSoftAuthenticator(t, "example.com", "https://example.com")
+
+	challenge, _ := NewChallenge()
+	cred, err := rp.VerifyRegistration(auth.register(challenge), challenge, true)
+	if err != nil {
+		t.Fatalf("VerifyRegistration: %v", err)
+	}
+	if cred.Algorithm != AlgES256 || string(cred.ID) != string(auth.credID) || !cred.UserVerified {
+		t.Fatalf("unexpected credential %+v", cred)
+	}
+
+	challenge, _ = NewChallenge()
+	resp := auth.assert(t, challenge)
+	assertion, err := rp.VerifyAssertion(resp, challenge, cred, true)
+	if err != nil {
+		t.Fatalf("VerifyAssertion: %v", err)
+	}
+	if assertion.SignCount != 1 || string(assertion.UserHandle) != "1" {
+		t.Fatalf("unexpected assertion %+v", assertion)
+	}
+
+	// 重放同一个认证结果：签名计数没有增加
+	cred.SignCount = assertion.SignCount
+	if _, err := rp.VerifyAssertion(resp, challenge, cred, true); !errors.Is(err, ErrSignCount) {
+		t.Fatalf("replayed assertion err = %v, want ErrSignCount", err)
+	}
+}
+
+func TestAssertionRejected(t *testing.T) {
+	rp := &RelyingParty{ID: "example.com", Origins: []string{"https://example.com"}}
+	auth := newSoftAuthenticator(t, "example.com", "https://example.com")
+	challenge, _ := NewChallenge()
+	cred, err := rp.VerifyRegistration(auth.register(challenge), challenge, false)
+	if err != nil {
+		t.Fatalf("VerifyRegistration: %v", err)
+	}
+
+	other, _ := NewChallenge()
+	if _, err := rp.VerifyAssertion(auth.assert(t, challenge), other, cred, false); err == nil {
+		t.Error("challenge mismatch accepted")
+	}
+
+	phishing := *auth
+	phishing.origin = "https://example.com.evil.test"
+	if _, err := rp.VerifyAssertion(phishing.assert(t, challenge), challenge, cred, false); err == nil {
+		t.Error("foreign origin accepted")
+	}
+
+	wrongRP := *auth
+	wrongRP.rpID = "evil.test"
+	if _, err := rp.VerifyAssertion(wrongRP.assert(t, challenge), challenge, cred, false); err == nil {
+		t.Error("foreign rp id accepted")
+	}
+
+	resp := auth.assert(t, challenge)
+	sig, _ := DecodeID(resp.Response.Signature)
+	sig[len(sig)-1] ^= 0xff
+	resp.Response.Signature = EncodeID(sig)
+	if _, err := rp.VerifyAssertion(resp, challenge, cred, false); err == nil {
+		t.Error("tampered signature accepted")
+	}
+
+	auth.flags = flagUserPresent
+	if _, err := rp.VerifyAssertion(auth.assert(t, challenge), challenge, cred, true); !errors.Is(err, ErrUserVerification) {
+		t.Errorf("missing user verification err = %v, want ErrUserVerification", err)
+	}
+}
+
+func TestParseEd25519Key(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	coseKey := encodeCBOR([]kv{{1, 1}, {3, -8}, {-1, 6}, {-2, []byte(pub)}})
+	key, alg, err := parsePublicKey(coseKey)
+	if err != nil {
+		t.Fatalf("parsePublicKey: %v", err)
+	}
+	if alg != AlgEdDSA || !pub.Equal(key) {
+		t.Fatalf("unexpected key %v alg %d", key, alg)
+	}
+}
+
+func TestDecodeCBORRejectsTruncated(t *testing.T) {
+	data := encodeCBOR([]kv{{"authData", make([]byte, 40)}})
+	for i := 0; i < len(data); i++ {
+		if _, _, err := decodeCBOR(data[:i]); err == nil {
+			t.Fatalf("decodeCBOR accepted %d of %d bytes", i, len(data))
+		}
+	}
+}
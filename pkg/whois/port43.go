@@ -0,0 +1,224 @@
+package whois
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// maxWhoisResponse WHOIS 响应的最大读取长度
+const maxWhoisResponse = 1 << 20
+
+// whoisFields 各注册局常见的字段名称
+var whoisFields = map[string][]string{
+	"registrar": {"registrar", "registrar name", "sponsoring registrar", "registrar organization"},
+	"created":   {"creation date", "created", "created on", "registered on", "registration time", "domain registration date", "registered"},
+	"updated":   {"updated date", "last updated", "last modified", "changed", "last update"},
+	"expires": {"registry expiry date", "registrar registration expiration date", "expiration date", "expiry date",
+		"expires", "expires on", "expire date", "paid-till", "expiration time", "renewal date"},
+	"nameserver": {"name server", "nserver", "nameserver", "name servers", "nameservers"},
+	"status":     {"domain status", "status", "state"},
+}
+
+func (c *Client) lookupWhois(ctx context.Context, domain string) (*Result, error) {
+	server, ok := c.WhoisServers[tld(domain)]
+	if !ok {
+		referral, err := c.referral(ctx, tld(domain))
+		if err != nil {
+			return nil, err
+		}
+		server = referral
+	}
+
+	text, err := c.query(ctx, server, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	result := parseWhois(domain, text)
+	if result == nil {
+		return nil, ErrNotFound
+	}
+
+	// 瘦注册局（如 .com）只返回注册商的 WHOIS 地址，继续查询一次注册商
+	if referral := whoisReferral(text); referral != "" && referral != server {
+		if detail, err := c.query(ctx, referral, domain); err == nil {
+			if more := parseWhois(domain, detail); more != nil {
+				mergeResult(result, more)
+			}
+		}
+	}
+	return result, nil
+}
+
+// referral 通过 IANA WHOIS 查询顶级域名的 WHOIS 服务
+func (c *Client) referral(ctx context.Context, tld string) (string, error) {
+	if c.WhoisServer == "" {
+		return "", fmt.Errorf("no whois server for TLD %q", tld)
+	}
+	text, err := c.query(ctx, c.WhoisServer, tld)
+	if err != nil {
+		return "", err
+	}
+	server := whoisReferral(text)
+	if server == "" {
+		return "", fmt.Errorf("no whois server for TLD %q", tld)
+	}
+	return server, nil
+}
+
+// query 向 WHOIS 服务发送查询并读取完整响应
+func (c *Client) query(ctx context.Context, server, q string) (string, error) {
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "43")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout())
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", server)
+	if err != nil {
+		return "", fmt.Errorf("connect whois server %s: %w", server, err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	} else {
+		conn.SetDeadline(time.Now().Add(c.timeout()))
+	}
+
+	if _, err := io.WriteString(conn, q+"\r\n"); err != nil {
+		return "", fmt.Errorf("write whois query: %w", err)
+	}
+	data, err := io.ReadAll(io.LimitReader(conn, maxWhoisResponse))
+	if err != nil {
+		return "", fmt.Errorf("read whois response: %w", err)
+	}
+	return string(data), nil
+}
+
+// whoisReferral 从响应中取引荐的 WHOIS 服务地址
+func whoisReferral(text string) string {
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		key, value, ok := splitWhoisLine(scanner.Text())
+		if !ok || value == "" {
+			continue
+		}
+		switch key {
+		case "refer", "whois", "registrar whois server", "whois server":
+			value = strings.TrimPrefix(value, "whois://")
+			return strings.TrimSuffix(strings.ToLower(value), "/")
+		}
+	}
+	return ""
+}
+
+// parseWhois 解析 WHOIS 文本，没有任何注册信息（如 "No match for"）时返回 nil
+func parseWhois(domain, text string) *Result {
+	result := &Result{
+		Domain:      domain,
+		Source:      SourceWhois,
+		NameServers: []string{},
+		Status:      []string{},
+		QueriedAt:   time.Now().UTC(),
+	}
+
+	found := false
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		key, value, ok := splitWhoisLine(scanner.Text())
+		if !ok || value == "" {
+			continue
+		}
+
+		switch fieldOf(key) {
+		case "registrar":
+			if result.Registrar == "" {
+				result.Registrar = value
+				found = true
+			}
+		case "created":
+			if result.CreatedAt == nil {
+				result.CreatedAt = parseTime(value)
+				found = found || result.CreatedAt != nil
+			}
+		case "updated":
+			if result.UpdatedAt == nil {
+				result.UpdatedAt = parseTime(value)
+			}
+		case "expires":
+			if result.ExpiresAt == nil {
+				result.ExpiresAt = parseTime(value)
+				found = found || result.ExpiresAt != nil
+			}
+		case "nameserver":
+			// 部分注册局在名称服务器后附带IP地址
+			ns := strings.Fields(value)[0]
+			result.NameServers = appendUnique(result.NameServers, strings.ToLower(strings.TrimSuffix(ns, ".")))
+			found = true
+		case "status":
+			// 如 "clientTransferProhibited https://icann.org/epp#clientTransferProhibited"
+			result.Status = appendUnique(result.Status, strings.Fields(value)[0])
+		}
+	}
+
+	if !found {
+		return nil
+	}
+	return result
+}
+
+// splitWhoisLine 拆分 "key: value" 格式的行，忽略注释行
+func splitWhoisLine(line string) (string, string, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "%") || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ">>>") {
+		return "", "", false
+	}
+	i := strings.Index(line, ":")
+	if i <= 0 {
+		return "", "", false
+	}
+	key := strings.ToLower(strings.TrimSpace(line[:i]))
+	value := strings.TrimSpace(line[i+1:])
+	return key, value, true
+}
+
+func fieldOf(key string) string {
+	for field, names := range whoisFields {
+		for _, name := range names {
+			if key == name {
+				return field
+			}
+		}
+	}
+	return ""
+}
+
+// mergeResult 用注册商返回的信息补充注册局的结果
+func mergeResult(dst, src *Result) {
+	if dst.Registrar == "" {
+		dst.Registrar = src.Registrar
+	}
+	if dst.CreatedAt == nil {
+		dst.CreatedAt = src.CreatedAt
+	}
+	if dst.UpdatedAt == nil {
+		dst.UpdatedAt = src.UpdatedAt
+	}
+	if dst.ExpiresAt == nil {
+		dst.ExpiresAt = src.ExpiresAt
+	}
+	for _, ns := range src.NameServers {
+		dst.NameServers = appendUnique(dst.NameServers, ns)
+	}
+	for _, status := range src.Status {
+		dst.Status = appendUnique(dst.Status, status)
+	}
+}
@@ -0,0 +1,227 @@
+package whois
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// bootstrapTTL 引导文件的内存缓存时间
+const bootstrapTTL = 24 * time.Hour
+
+// rdapDomain RDAP 域名查询响应中用到的字段
+type rdapDomain struct {
+	LDHName string   `json:"ldhName"`
+	Status  []string `json:"status"`
+	Events  []struct {
+		Action string `json:"eventAction"`
+		Date   string `json:"eventDate"`
+	} `json:"events"`
+	Nameservers []struct {
+		LDHName string `json:"ldhName"`
+	} `json:"nameservers"`
+	Entities []rdapEntity `json:"entities"`
+}
+
+type rdapEntity struct {
+	Roles      []string          `json:"roles"`
+	VCardArray []json.RawMessage `json:"vcardArray"`
+	Entities   []rdapEntity      `json:"entities"`
+}
+
+// rdapBootstrap IANA RDAP 引导文件
+type rdapBootstrap struct {
+	Services [][][]string `json:"services"`
+}
+
+func (c *Client) lookupRDAP(ctx context.Context, domain string) (*Result, error) {
+	base, err := c.rdapServer(ctx, tld(domain))
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout())
+	defer cancel()
+
+	url := strings.TrimSuffix(base, "/") + "/domain/" + domain
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/rdap+json, application/json")
+
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("rdap server returned %s", resp.Status)
+	}
+
+	var data rdapDomain
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 4<<20)).Decode(&data); err != nil {
+		return nil, fmt.Errorf("decode rdap response: %w", err)
+	}
+	return data.result(domain), nil
+}
+
+func (d *rdapDomain) result(domain string) *Result {
+	result := &Result{
+		Domain:      domain,
+		Source:      SourceRDAP,
+		NameServers: []string{},
+		Status:      []string{},
+		QueriedAt:   time.Now().UTC(),
+	}
+
+	for _, event := range d.Events {
+		switch strings.ToLower(event.Action) {
+		case "registration":
+			result.CreatedAt = parseTime(event.Date)
+		case "expiration":
+			result.ExpiresAt = parseTime(event.Date)
+		case "last changed":
+			result.UpdatedAt = parseTime(event.Date)
+		}
+	}
+	for _, ns := range d.Nameservers {
+		if ns.LDHName != "" {
+			result.NameServers = appendUnique(result.NameServers, strings.ToLower(strings.TrimSuffix(ns.LDHName, ".")))
+		}
+	}
+	for _, status := range d.Status {
+		result.Status = appendUnique(result.Status, status)
+	}
+	result.Registrar = findRegistrar(d.Entities)
+	return result
+}
+
+// findRegistrar 在实体中查找 registrar 角色的名称
+func findRegistrar(entities []rdapEntity) string {
+	for _, entity := range entities {
+		for _, role := range entity.Roles {
+			if role == "registrar" {
+				if name := vcardName(entity.VCardArray); name != "" {
+					return name
+				}
+			}
+		}
+		if name := findRegistrar(entity.Entities); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// vcardName 从 jCard 中取 fn 属性，格式为 ["vcard", [["fn", {}, "text", "名称"], ...]]
+func vcardName(vcard []json.RawMessage) string {
+	if len(vcard) < 2 {
+		return ""
+	}
+	var props [][]json.RawMessage
+	if err := json.Unmarshal(vcard[1], &props); err != nil {
+		return ""
+	}
+	for _, prop := range props {
+		if len(prop) < 4 {
+			continue
+		}
+		var name, value string
+		if json.Unmarshal(prop[0], &name) != nil || name != "fn" {
+			continue
+		}
+		if json.Unmarshal(prop[3], &value) == nil {
+			return value
+		}
+	}
+	return ""
+}
+
+// rdapServer 获取顶级域名对应的 RDAP 服务地址
+func (c *Client) rdapServer(ctx context.Context, tld string) (string, error) {
+	if server, ok := c.RDAPServers[tld]; ok {
+		return server, nil
+	}
+	if c.BootstrapURL == "" {
+		return "", ErrNoRDAPServer
+	}
+
+	c.bootstrapMu.Lock()
+	defer c.bootstrapMu.Unlock()
+
+	if c.bootstrap == nil || time.Since(c.bootstrapFetched) > bootstrapTTL {
+		services, err := c.fetchBootstrap(ctx)
+		if err != nil {
+			// 引导文件获取失败时继续使用旧数据
+			if c.bootstrap == nil {
+				return "", err
+			}
+		} else {
+			c.bootstrap, c.bootstrapFetched = services, time.Now()
+		}
+	}
+
+	server, ok := c.bootstrap[tld]
+	if !ok {
+		return "", ErrNoRDAPServer
+	}
+	return server, nil
+}
+
+func (c *Client) fetchBootstrap(ctx context.Context) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout())
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BootstrapURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch rdap bootstrap: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch rdap bootstrap: %s", resp.Status)
+	}
+
+	var data rdapBootstrap
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 4<<20)).Decode(&data); err != nil {
+		return nil, fmt.Errorf("decode rdap bootstrap: %w", err)
+	}
+
+	// 每个服务项为 [[tld...], [url...]]
+	services := make(map[string]string)
+	for _, service := range data.Services {
+		if len(service) < 2 || len(service[1]) == 0 {
+			continue
+		}
+		url := service[1][0]
+		for _, u := range service[1] {
+			if strings.HasPrefix(u, "https://") {
+				url = u
+				break
+			}
+		}
+		for _, t := range service[0] {
+			services[strings.ToLower(t)] = url
+		}
+	}
+	return services, nil
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
@@ -0,0 +1,176 @@
+package whois
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 查询来源
+const (
+	SourceRDAP  = "rdap"
+	SourceWhois = "whois"
+)
+
+// 默认服务地址
+const (
+	DefaultBootstrapURL = "https://data.iana.org/rdap/dns.json"
+	DefaultWhoisServer  = "whois.iana.org:43"
+	DefaultTimeout      = 10 * time.Second
+)
+
+var (
+	// ErrNotFound 域名未注册或注册局没有该域名的信息
+	ErrNotFound = errors.New("domain not found")
+	// ErrNoRDAPServer 顶级域名没有可用的 RDAP 服务
+	ErrNoRDAPServer = errors.New("no RDAP server for TLD")
+)
+
+// Result 域名注册信息
+type Result struct {
+	Domain      string     `json:"domain"`
+	Source      string     `json:"source"`
+	Registrar   string     `json:"registrar"`
+	CreatedAt   *time.Time `json:"created_at"`
+	UpdatedAt   *time.Time `json:"updated_at"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	NameServers []string   `json:"name_servers"`
+	Status      []string   `json:"status"`
+	QueriedAt   time.Time  `json:"queried_at"`
+	// Cached 结果是否来自缓存，由调用方设置
+	Cached bool `json:"cached"`
+}
+
+// Client RDAP/WHOIS 客户端
+// 优先使用 RDAP 查询，顶级域名没有 RDAP 服务或 RDAP 查询失败时回退到 WHOIS 43 端口
+type Client struct {
+	HTTPClient *http.Client
+	// BootstrapURL IANA RDAP 引导文件地址
+	BootstrapURL string
+	// RDAPServers 按顶级域名指定 RDAP 服务地址，优先于引导文件
+	RDAPServers map[string]string
+	// WhoisServers 按顶级域名指定 WHOIS 服务地址(host:port)，未指定时通过 WhoisServer 查询引荐
+	WhoisServers map[string]string
+	// WhoisServer 用于查询顶级域名引荐的 WHOIS 服务
+	WhoisServer string
+	Timeout     time.Duration
+
+	bootstrapMu      sync.Mutex
+	bootstrap        map[string]string
+	bootstrapFetched time.Time
+}
+
+// NewClient 创建使用默认服务地址的客户端
+func NewClient() *Client {
+	return &Client{
+		HTTPClient:   &http.Client{Timeout: DefaultTimeout},
+		BootstrapURL: DefaultBootstrapURL,
+		WhoisServer:  DefaultWhoisServer,
+		Timeout:      DefaultTimeout,
+	}
+}
+
+// Lookup 查询域名注册信息
+func (c *Client) Lookup(ctx context.Context, domain string) (*Result, error) {
+	domain, err := Normalize(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	result, rdapErr := c.lookupRDAP(ctx, domain)
+	if rdapErr == nil {
+		return result, nil
+	}
+	if errors.Is(rdapErr, ErrNotFound) {
+		return nil, rdapErr
+	}
+
+	result, whoisErr := c.lookupWhois(ctx, domain)
+	if whoisErr != nil {
+		if errors.Is(whoisErr, ErrNotFound) {
+			return nil, whoisErr
+		}
+		return nil, fmt.Errorf("rdap: %v; whois: %w", rdapErr, whoisErr)
+	}
+	return result, nil
+}
+
+// Normalize 校验并规范化域名
+func Normalize(domain string) (string, error) {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	domain = strings.TrimSuffix(domain, ".")
+	if domain == "" {
+		return "", errors.New("domain is empty")
+	}
+	if len(domain) > 253 || !strings.Contains(domain, ".") {
+		return "", fmt.Errorf("invalid domain %q", domain)
+	}
+	for _, label := range strings.Split(domain, ".") {
+		if label == "" || len(label) > 63 {
+			return "", fmt.Errorf("invalid domain %q", domain)
+		}
+		for _, ch := range label {
+			if !(ch >= 'a' && ch <= 'z' || ch >= '0' && ch <= '9' || ch == '-') {
+				return "", fmt.Errorf("invalid domain %q", domain)
+			}
+		}
+	}
+	return domain, nil
+}
+
+func (c *Client) timeout() time.Duration {
+	if c.Timeout > 0 {
+		return c.Timeout
+	}
+	return DefaultTimeout
+}
+
+func tld(domain string) string {
+	return domain[strings.LastIndex(domain, ".")+1:]
+}
+
+// parseTime 解析注册信息中常见的日期格式
+func parseTime(s string) *time.Time {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	layouts := []string{
+		time.RFC3339Nano,
+		time.RFC3339,
+		"2006-01-02T15:04:05Z",
+		"2006-01-02T15:04:05",
+		"2006-01-02 15:04:05 MST",
+		"2006-01-02 15:04:05",
+		"2006-01-02",
+		"02-Jan-2006",
+		"2006.01.02",
+		"2006/01/02",
+		"02.01.2006",
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			t = t.UTC()
+			return &t
+		}
+	}
+	// 去掉日期后的附加说明，如 "2025-01-01 (YYYY-MM-DD)"
+	if i := strings.IndexAny(s, " ("); i > 0 {
+		return parseTime(s[:i])
+	}
+	return nil
+}
+
+// appendUnique 追加不重复的值
+func appendUnique(list []string, value string) []string {
+	for _, v := range list {
+		if v == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
@@ -0,0 +1,194 @@
+package whois
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const rdapExample = `{
+  "objectClassName": "domain",
+  "ldhName": "EXAMPLE.COM",
+  "status": ["client delete prohibited", "client transfer prohibited"],
+  "events": [
+    {"eventAction": "registration", "eventDate": "1995-08-14T04:00:00Z"},
+    {"eventAction": "expiration", "eventDate": "2030-08-13T04:00:00Z"},
+    {"eventAction": "last changed", "eventDate": "2024-08-14T07:01:34Z"}
+  ],
+  "nameservers": [{"ldhName": "A.IANA-SERVERS.NET"}, {"ldhName": "B.IANA-SERVERS.NET"}],
+  "entities": [{
+    "roles": ["registrar"],
+    "vcardArray": ["vcard", [["version", {}, "text", "4.0"], ["fn", {}, "text", "RESERVED-Internet Assigned Numbers Authority"]]]
+  }]
+}`
+
+const registryWhois = `   Domain Name: EXAMPLE.NET
+   Registrar WHOIS Server: %s
+   Registrar: Example Registrar, Inc.
+   Creation Date: 1997-03-28T05:00:00Z
+   Registry Expiry Date: 2031-03-27T04:00:00Z
+   Domain Status: clientTransferProhibited https://icann.org/epp#clientTransferProhibited
+   Name Server: NS1.EXAMPLE.NET
+>>> Last update of whois database: 2026-10-16T00:00:00Z <<<
+`
+
+const registrarWhois = `Domain Name: example.net
+Updated Date: 2025-01-02T03:04:05Z
+Name Server: ns2.example.net 192.0.2.53
+Domain Status: clientDeleteProhibited https://icann.org/epp#clientDeleteProhibited
+`
+
+// fakeWhoisServer 按查询内容返回固定响应的 WHOIS 服务
+func fakeWhoisServer(t *testing.T, answer func(query string) string) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				query, _ := bufio.NewReader(conn).ReadString('\n')
+				fmt.Fprint(conn, answer(strings.TrimSpace(query)))
+			}(conn)
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func newTestClient(rdap map[string]string, whois map[string]string) *Client {
+	return &Client{
+		HTTPClient:   &http.Client{Timeout: 2 * time.Second},
+		RDAPServers:  rdap,
+		WhoisServers: whois,
+		Timeout:      2 * time.Second,
+	}
+}
+
+func TestLookupRDAP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/domain/example.com":
+			w.Header().Set("Content-Type", "application/rdap+json")
+			fmt.Fprint(w, rdapExample)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	client := newTestClient(map[string]string{"com": srv.URL}, nil)
+
+	result, err := client.Lookup(context.Background(), "Example.COM.")
+	if err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	if result.Source != SourceRDAP {
+		t.Errorf("source = %q, want %q", result.Source, SourceRDAP)
+	}
+	if result.Registrar != "RESERVED-Internet Assigned Numbers Authority" {
+		t.Errorf("registrar = %q", result.Registrar)
+	}
+	if result.CreatedAt == nil || result.CreatedAt.Year() != 1995 {
+		t.Errorf("created = %v", result.CreatedAt)
+	}
+	if result.ExpiresAt == nil || !result.ExpiresAt.Equal(time.Date(2030, 8, 13, 4, 0, 0, 0, time.UTC)) {
+		t.Errorf("expires = %v", result.ExpiresAt)
+	}
+	if strings.Join(result.NameServers, ",") != "a.iana-servers.net,b.iana-servers.net" {
+		t.Errorf("name servers = %v", result.NameServers)
+	}
+	if len(result.Status) != 2 {
+		t.Errorf("status = %v", result.Status)
+	}
+
+	if _, err := client.Lookup(context.Background(), "missing.com"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("missing domain error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestLookupFallsBackToWhois(t *testing.T) {
+	rdap := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer rdap.Close()
+
+	registrar := fakeWhoisServer(t, func(string) string { return registrarWhois })
+	registry := fakeWhoisServer(t, func(query string) string {
+		if query != "example.net" {
+			return "No match for \"" + strings.ToUpper(query) + "\".\n"
+		}
+		return fmt.Sprintf(registryWhois, registrar)
+	})
+
+	client := newTestClient(map[string]string{"net": rdap.URL}, map[string]string{"net": registry})
+
+	result, err := client.Lookup(context.Background(), "example.net")
+	if err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	if result.Source != SourceWhois {
+		t.Errorf("source = %q, want %q", result.Source, SourceWhois)
+	}
+	if result.Registrar != "Example Registrar, Inc." {
+		t.Errorf("registrar = %q", result.Registrar)
+	}
+	if result.ExpiresAt == nil || result.ExpiresAt.Year() != 2031 {
+		t.Errorf("expires = %v", result.ExpiresAt)
+	}
+	if result.UpdatedAt == nil || result.UpdatedAt.Year() != 2025 {
+		t.Errorf("updated = %v, want value from registrar referral", result.UpdatedAt)
+	}
+	if strings.Join(result.NameServers, ",") != "ns1.example.net,ns2.example.net" {
+		t.Errorf("name servers = %v", result.NameServers)
+	}
+	if strings.Join(result.Status, ",") != "clientTransferProhibited,clientDeleteProhibited" {
+		t.Errorf("status = %v", result.Status)
+	}
+
+	if _, err := client.Lookup(context.Background(), "nothing.net"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("unregistered domain error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestLookupWhoisReferralFromIANA(t *testing.T) {
+	registry := fakeWhoisServer(t, func(string) string {
+		return "domain: EXAMPLE.ORG\nregistrar: Org Registrar\nexpires: 2029-01-15\nnserver: ns.example.org\n"
+	})
+	iana := fakeWhoisServer(t, func(query string) string {
+		return "% IANA WHOIS server\ndomain: " + strings.ToUpper(query) + "\nrefer: " + registry + "\n"
+	})
+
+	client := newTestClient(nil, nil)
+	client.WhoisServer = iana
+
+	result, err := client.Lookup(context.Background(), "example.org")
+	if err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	if result.Registrar != "Org Registrar" || result.ExpiresAt == nil || result.ExpiresAt.Year() != 2029 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	for _, bad := range []string{"", "localhost", "bad_name.com", "a..com", strings.Repeat("a", 64) + ".com"} {
+		if _, err := Normalize(bad); err == nil {
+			t.Errorf("Normalize(%q) succeeded, want error", bad)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package zonefile
+
+import (
+	"fmt"
+	"strings"
+)
+
+// token 词法单元
+type token struct {
+	text   string
+	quoted bool
+}
+
+// line 逻辑行，括号内的多行内容会合并为一行
+type line struct {
+	tokens []token
+	// blank 表示行首为空白，即省略了所有者名称
+	blank  bool
+	number int
+}
+
+// lex 将主文件内容拆分为逻辑行
+func lex(input string) ([]line, error) {
+	var (
+		lines   []line
+		current line
+		buf     strings.Builder
+		inToken bool
+		depth   int
+		lineNo  = 1
+	)
+
+	current.number = lineNo
+	startOfLine := true
+
+	flush := func() {
+		if inToken {
+			current.tokens = append(current.tokens, token{text: buf.String()})
+			buf.Reset()
+			inToken = false
+		}
+	}
+	endLine := func() {
+		flush()
+		if len(current.tokens) > 0 {
+			lines = append(lines, current)
+		}
+		current = line{number: lineNo}
+		startOfLine = true
+	}
+
+	for i := 0; i < len(input); i++ {
+		ch := input[i]
+
+		if startOfLine && depth == 0 {
+			current.blank = ch == ' ' || ch == '\t'
+			current.number = lineNo
+		}
+		startOfLine = false
+
+		switch {
+		case ch == '\n':
+			lineNo++
+			if depth == 0 {
+				endLine()
+			} else {
+				flush()
+			}
+		case ch == '\r':
+			flush()
+		case ch == ';':
+			flush()
+			for i+1 < len(input) && input[i+1] != '\n' {
+				i++
+			}
+		case ch == ' ' || ch == '\t':
+			flush()
+		case ch == '(':
+			flush()
+			depth++
+		case ch == ')':
+			flush()
+			if depth == 0 {
+				return nil, fmt.Errorf("line %d: unbalanced ')'", lineNo)
+			}
+			depth--
+		case ch == '"':
+			flush()
+			text, next, newlines, err := readQuoted(input, i+1)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			current.tokens = append(current.tokens, token{text: text, quoted: true})
+			lineNo += newlines
+			i = next
+		case ch == '\\' && i+1 < len(input):
+			// 非引号内容中的转义字符原样保留，由记录解析时处理
+			buf.WriteByte(ch)
+			buf.WriteByte(input[i+1])
+			inToken = true
+			i++
+		default:
+			buf.WriteByte(ch)
+			inToken = true
+		}
+	}
+
+	if depth != 0 {
+		return nil, fmt.Errorf("line %d: unbalanced '('", lineNo)
+	}
+	endLine()
+	return lines, nil
+}
+
+// readQuoted 读取引号字符串，返回反转义后的内容和结束引号的位置
+func readQuoted(input string, start int) (string, int, int, error) {
+	var (
+		buf      strings.Builder
+		newlines int
+	)
+	for i := start; i < len(input); i++ {
+		ch := input[i]
+		switch ch {
+		case '"':
+			return buf.String(), i, newlines, nil
+		case '\\':
+			if i+1 >= len(input) {
+				return "", 0, 0, fmt.Errorf("unterminated escape")
+			}
+			// \DDD 十进制转义
+			if i+3 < len(input) && isDigit(input[i+1]) && isDigit(input[i+2]) && isDigit(input[i+3]) {
+				v := int(input[i+1]-'0')*100 + int(input[i+2]-'0')*10 + int(input[i+3]-'0')
+				if v > 255 {
+					return "", 0, 0, fmt.Errorf("invalid escape \\%s", input[i+1:i+4])
+				}
+				buf.WriteByte(byte(v))
+				i += 3
+				continue
+			}
+			buf.WriteByte(input[i+1])
+			i++
+		case '\n':
+			newlines++
+			buf.WriteByte(ch)
+		default:
+			buf.WriteByte(ch)
+		}
+	}
+	return "", 0, 0, fmt.Errorf("unterminated quoted string")
+}
+
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
@@ -0,0 +1,262 @@
+package zonefile
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// 默认值
+const (
+	DefaultTTL      = 3600
+	maxIncludeDepth = 8
+)
+
+// ErrIncludeNotAllowed 未配置 Include 时遇到 $INCLUDE 指令
+var ErrIncludeNotAllowed = errors.New("$INCLUDE is not allowed")
+
+// Options 解析选项
+type Options struct {
+	// Origin 初始 $ORIGIN，可以不带末尾的点
+	Origin string
+	// DefaultTTL 文件中未出现 $TTL 且记录未指定TTL时使用，为0时使用 DefaultTTL
+	DefaultTTL uint32
+	// Include 打开 $INCLUDE 引用的文件，为 nil 时禁止 $INCLUDE
+	Include func(path string) (io.ReadCloser, error)
+}
+
+// parser 单个主文件的解析状态
+type parser struct {
+	opts       Options
+	origin     string
+	ttl        uint32
+	hasTTL     bool
+	lastOwner  string
+	lastTTL    uint32
+	hasLastTTL bool
+	depth      int
+}
+
+// Parse 解析 RFC 1035 主文件
+// 支持 $ORIGIN、$TTL、$INCLUDE 指令，括号续行、注释、相对名称和省略所有者名称的写法
+func Parse(r io.Reader, opts Options) ([]Record, error) {
+	p := &parser{opts: opts}
+	if opts.Origin != "" {
+		p.origin = Fqdn(opts.Origin)
+	}
+	return p.parse(r)
+}
+
+func (p *parser) parse(r io.Reader) ([]Record, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := lex(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	var records []Record
+	for _, l := range lines {
+		if !l.blank && strings.HasPrefix(l.tokens[0].text, "$") && !l.tokens[0].quoted {
+			included, err := p.directive(l)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", l.number, err)
+			}
+			records = append(records, included...)
+			continue
+		}
+
+		record, err := p.record(l)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", l.number, err)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// directive 处理以 $ 开头的控制指令
+func (p *parser) directive(l line) ([]Record, error) {
+	name := strings.ToUpper(l.tokens[0].text)
+	args := l.tokens[1:]
+
+	switch name {
+	case "$ORIGIN":
+		if len(args) != 1 {
+			return nil, errors.New("$ORIGIN requires one argument")
+		}
+		origin, err := p.absolute(args[0].text)
+		if err != nil {
+			return nil, err
+		}
+		p.origin = origin
+	case "$TTL":
+		if len(args) != 1 {
+			return nil, errors.New("$TTL requires one argument")
+		}
+		ttl, err := ParseTTL(args[0].text)
+		if err != nil {
+			return nil, err
+		}
+		p.ttl, p.hasTTL = ttl, true
+	case "$INCLUDE":
+		return p.include(args)
+	default:
+		return nil, fmt.Errorf("unsupported directive %s", l.tokens[0].text)
+	}
+	return nil, nil
+}
+
+// include 解析 $INCLUDE 引用的文件
+// 被引用文件中的 $ORIGIN 不会影响当前文件，与 RFC 1035 一致
+func (p *parser) include(args []token) ([]Record, error) {
+	if p.opts.Include == nil {
+		return nil, ErrIncludeNotAllowed
+	}
+	if len(args) < 1 || len(args) > 2 {
+		return nil, errors.New("$INCLUDE requires a file name and an optional origin")
+	}
+	if p.depth >= maxIncludeDepth {
+		return nil, errors.New("$INCLUDE nested too deeply")
+	}
+
+	child := &parser{
+		opts:   p.opts,
+		origin: p.origin,
+		ttl:    p.ttl,
+		hasTTL: p.hasTTL,
+		depth:  p.depth + 1,
+	}
+	if len(args) == 2 {
+		origin, err := p.absolute(args[1].text)
+		if err != nil {
+			return nil, err
+		}
+		child.origin = origin
+	}
+
+	f, err := p.opts.Include(args[0].text)
+	if err != nil {
+		return nil, fmt.Errorf("$INCLUDE %s: %w", args[0].text, err)
+	}
+	defer f.Close()
+
+	records, err := child.parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("$INCLUDE %s: %w", args[0].text, err)
+	}
+	return records, nil
+}
+
+// record 解析资源记录行: [owner] [ttl] [class] type rdata
+// TTL 和类别的顺序可以互换
+func (p *parser) record(l line) (Record, error) {
+	var record Record
+	tokens := l.tokens
+
+	if l.blank {
+		if p.lastOwner == "" {
+			return record, errors.New("record without owner name")
+		}
+		record.Name = p.lastOwner
+	} else {
+		owner, err := p.absolute(tokens[0].text)
+		if err != nil {
+			return record, err
+		}
+		record.Name = owner
+		tokens = tokens[1:]
+	}
+
+	hasTTL := false
+	for len(tokens) > 0 && !tokens[0].quoted {
+		text := tokens[0].text
+		if !hasTTL && isDigit(text[0]) {
+			ttl, err := ParseTTL(text)
+			if err != nil {
+				return record, err
+			}
+			record.TTL, hasTTL = ttl, true
+		} else if record.Class == "" && isClass(text) {
+			record.Class = strings.ToUpper(text)
+		} else {
+			break
+		}
+		tokens = tokens[1:]
+	}
+
+	if len(tokens) == 0 {
+		return record, errors.New("missing record type")
+	}
+	record.Type = strings.ToUpper(tokens[0].text)
+	if record.Class == "" {
+		record.Class = "IN"
+	}
+
+	switch {
+	case hasTTL:
+		p.lastTTL, p.hasLastTTL = record.TTL, true
+	case p.hasTTL:
+		record.TTL = p.ttl
+	case p.hasLastTTL:
+		record.TTL = p.lastTTL
+	case p.opts.DefaultTTL > 0:
+		record.TTL = p.opts.DefaultTTL
+	default:
+		record.TTL = DefaultTTL
+	}
+
+	if err := p.parseRData(&record, tokens[1:]); err != nil {
+		return record, err
+	}
+
+	// 未指定 $TTL 时，SOA 的 minimum 作为后续记录的默认TTL
+	if record.Type == "SOA" && !p.hasTTL && !hasTTL && !p.hasLastTTL {
+		record.TTL = record.SOA.Minimum
+		p.lastTTL, p.hasLastTTL = record.TTL, true
+	}
+
+	p.lastOwner = record.Name
+	return record, nil
+}
+
+// absolute 将名称转换为带末尾点的绝对域名，"@" 表示当前 $ORIGIN
+func (p *parser) absolute(name string) (string, error) {
+	if name == "@" {
+		if p.origin == "" {
+			return "", errors.New("'@' used without $ORIGIN")
+		}
+		return p.origin, nil
+	}
+	if strings.HasSuffix(name, ".") && !strings.HasSuffix(name, `\.`) {
+		return strings.ToLower(name), nil
+	}
+	if p.origin == "" {
+		return "", fmt.Errorf("relative name %q used without $ORIGIN", name)
+	}
+	if p.origin == "." {
+		return strings.ToLower(name) + ".", nil
+	}
+	return strings.ToLower(name) + "." + p.origin, nil
+}
+
+func isClass(s string) bool {
+	switch strings.ToUpper(s) {
+	case "IN", "CH", "CS", "HS":
+		return true
+	}
+	return false
+}
+
+// Fqdn 返回带末尾点的小写域名
+func Fqdn(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
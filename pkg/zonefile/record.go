@@ -0,0 +1,288 @@
+package zonefile
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Record 主文件中的一条资源记录
+// Name 为带末尾点的绝对域名；按类型不同，RDATA 拆分到对应字段：
+//   - A/AAAA: Value 为地址
+//   - CNAME/NS/PTR: Value 为绝对域名
+//   - MX: Priority + Value
+//   - SRV: Priority + Weight + Port + Value
+//   - TXT: Texts 为字符串列表
+//   - CAA: Flags + Tag + Value
+//   - SOA: SOA
+//
+// 其他类型的 RDATA 原样保存在 Value 中
+type Record struct {
+	Name     string   `json:"name"`
+	TTL      uint32   `json:"ttl"`
+	Class    string   `json:"class"`
+	Type     string   `json:"type"`
+	Value    string   `json:"value,omitempty"`
+	Priority uint16   `json:"priority,omitempty"`
+	Weight   uint16   `json:"weight,omitempty"`
+	Port     uint16   `json:"port,omitempty"`
+	Flags    uint8    `json:"flags,omitempty"`
+	Tag      string   `json:"tag,omitempty"`
+	Texts    []string `json:"texts,omitempty"`
+	SOA      *SOA     `json:"soa,omitempty"`
+}
+
+// SOA 起始授权记录
+type SOA struct {
+	MName   string `json:"mname"`
+	RName   string `json:"rname"`
+	Serial  uint32 `json:"serial"`
+	Refresh uint32 `json:"refresh"`
+	Retry   uint32 `json:"retry"`
+	Expire  uint32 `json:"expire"`
+	Minimum uint32 `json:"minimum"`
+}
+
+// RData 返回记录的主文件格式 RDATA
+func (r Record) RData() string {
+	switch r.Type {
+	case "MX":
+		return fmt.Sprintf("%d %s", r.Priority, r.Value)
+	case "SRV":
+		return fmt.Sprintf("%d %d %d %s", r.Priority, r.Weight, r.Port, r.Value)
+	case "TXT", "SPF":
+		parts := make([]string, 0, len(r.Texts))
+		for _, text := range r.Texts {
+			parts = append(parts, quote(text))
+		}
+		return strings.Join(parts, " ")
+	case "CAA":
+		return fmt.Sprintf("%d %s %s", r.Flags, r.Tag, quote(r.Value))
+	case "SOA":
+		if r.SOA == nil {
+			return ""
+		}
+		return fmt.Sprintf("%s %s %d %d %d %d %d", r.SOA.MName, r.SOA.RName,
+			r.SOA.Serial, r.SOA.Refresh, r.SOA.Retry, r.SOA.Expire, r.SOA.Minimum)
+	default:
+		return r.Value
+	}
+}
+
+// parseRData 按记录类型解析 RDATA 字段
+func (p *parser) parseRData(r *Record, fields []token) error {
+	switch r.Type {
+	case "A", "AAAA":
+		if err := expectFields(r.Type, fields, 1); err != nil {
+			return err
+		}
+		r.Value = fields[0].text
+	case "CNAME", "NS", "PTR", "DNAME":
+		if err := expectFields(r.Type, fields, 1); err != nil {
+			return err
+		}
+		name, err := p.absolute(fields[0].text)
+		if err != nil {
+			return err
+		}
+		r.Value = name
+	case "MX":
+		if err := expectFields(r.Type, fields, 2); err != nil {
+			return err
+		}
+		priority, err := parseUint16("preference", fields[0].text)
+		if err != nil {
+			return err
+		}
+		name, err := p.absolute(fields[1].text)
+		if err != nil {
+			return err
+		}
+		r.Priority, r.Value = priority, name
+	case "SRV":
+		if err := expectFields(r.Type, fields, 4); err != nil {
+			return err
+		}
+		var values [3]uint16
+		for i, label := range []string{"priority", "weight", "port"} {
+			v, err := parseUint16(label, fields[i].text)
+			if err != nil {
+				return err
+			}
+			values[i] = v
+		}
+		name, err := p.absolute(fields[3].text)
+		if err != nil {
+			return err
+		}
+		r.Priority, r.Weight, r.Port, r.Value = values[0], values[1], values[2], name
+	case "TXT", "SPF":
+		if len(fields) == 0 {
+			return fmt.Errorf("%s record requires at least one string", r.Type)
+		}
+		for _, field := range fields {
+			text := field.text
+			if !field.quoted {
+				text = unescape(text)
+			}
+			r.Texts = append(r.Texts, text)
+		}
+	case "CAA":
+		if err := expectFields(r.Type, fields, 3); err != nil {
+			return err
+		}
+		flags, err := strconv.ParseUint(fields[0].text, 10, 8)
+		if err != nil {
+			return fmt.Errorf("invalid CAA flags %q", fields[0].text)
+		}
+		r.Flags, r.Tag, r.Value = uint8(flags), fields[1].text, fields[2].text
+	case "SOA":
+		return p.parseSOA(r, fields)
+	default:
+		parts := make([]string, 0, len(fields))
+		for _, field := range fields {
+			if field.quoted {
+				parts = append(parts, quote(field.text))
+			} else {
+				parts = append(parts, field.text)
+			}
+		}
+		r.Value = strings.Join(parts, " ")
+	}
+	return nil
+}
+
+func (p *parser) parseSOA(r *Record, fields []token) error {
+	if err := expectFields(r.Type, fields, 7); err != nil {
+		return err
+	}
+	mname, err := p.absolute(fields[0].text)
+	if err != nil {
+		return err
+	}
+	rname, err := p.absolute(fields[1].text)
+	if err != nil {
+		return err
+	}
+	serial, err := strconv.ParseUint(fields[2].text, 10, 32)
+	if err != nil {
+		return fmt.Errorf("invalid SOA serial %q", fields[2].text)
+	}
+
+	soa := &SOA{MName: mname, RName: rname, Serial: uint32(serial)}
+	for i, target := range []*uint32{&soa.Refresh, &soa.Retry, &soa.Expire, &soa.Minimum} {
+		v, err := ParseTTL(fields[3+i].text)
+		if err != nil {
+			return fmt.Errorf("invalid SOA timer %q", fields[3+i].text)
+		}
+		*target = v
+	}
+	r.SOA = soa
+	return nil
+}
+
+func expectFields(typ string, fields []token, n int) error {
+	if len(fields) != n {
+		return fmt.Errorf("%s record requires %d fields, got %d", typ, n, len(fields))
+	}
+	return nil
+}
+
+func parseUint16(label, s string) (uint16, error) {
+	v, err := strconv.ParseUint(s, 10, 16)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q", label, s)
+	}
+	return uint16(v), nil
+}
+
+// ParseTTL 解析TTL，支持纯数字秒数和 BIND 风格的单位（如 1h30m、2d、1w）
+func ParseTTL(s string) (uint32, error) {
+	if s == "" {
+		return 0, fmt.Errorf("empty TTL")
+	}
+	if v, err := strconv.ParseUint(s, 10, 32); err == nil {
+		return uint32(v), nil
+	}
+
+	var total, current uint64
+	hasDigit := false
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if isDigit(ch) {
+			current = current*10 + uint64(ch-'0')
+			hasDigit = true
+			continue
+		}
+		if !hasDigit {
+			return 0, fmt.Errorf("invalid TTL %q", s)
+		}
+		switch ch {
+		case 's', 'S':
+		case 'm', 'M':
+			current *= 60
+		case 'h', 'H':
+			current *= 3600
+		case 'd', 'D':
+			current *= 86400
+		case 'w', 'W':
+			current *= 604800
+		default:
+			return 0, fmt.Errorf("invalid TTL %q", s)
+		}
+		total += current
+		current, hasDigit = 0, false
+		if total > 1<<32-1 {
+			return 0, fmt.Errorf("TTL %q out of range", s)
+		}
+	}
+	if hasDigit {
+		return 0, fmt.Errorf("invalid TTL %q", s)
+	}
+	return uint32(total), nil
+}
+
+// quote 将字符串转换为带引号的主文件格式
+func quote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case ch == '"' || ch == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(ch)
+		case ch < 0x20 || ch >= 0x7f:
+			fmt.Fprintf(&b, "\\%03d", ch)
+		default:
+			b.WriteByte(ch)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// unescape 处理非引号内容中的转义字符
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 >= len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i+3 < len(s) && isDigit(s[i+1]) && isDigit(s[i+2]) && isDigit(s[i+3]) {
+			v := int(s[i+1]-'0')*100 + int(s[i+2]-'0')*10 + int(s[i+3]-'0')
+			if v <= 255 {
+				b.WriteByte(byte(v))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i+1])
+		i++
+	}
+	return b.String()
+}
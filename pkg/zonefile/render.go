@@ -0,0 +1,98 @@
+package zonefile
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// typeOrder 渲染时同名记录的类型顺序
+var typeOrder = map[string]int{
+	"SOA":   0,
+	"NS":    1,
+	"A":     2,
+	"AAAA":  3,
+	"CNAME": 4,
+	"MX":    5,
+	"TXT":   6,
+	"SRV":   7,
+	"CAA":   8,
+}
+
+// Render 将记录渲染为主文件
+// 输出 $ORIGIN 和 $TTL，SOA 记录置顶并按多行格式输出，其余记录按名称和类型排序，
+// 区域内的名称输出为相对名称
+func Render(w io.Writer, origin string, ttl uint32, records []Record) error {
+	origin = Fqdn(origin)
+	if ttl == 0 {
+		ttl = DefaultTTL
+	}
+
+	sorted := make([]Record, len(records))
+	copy(sorted, records)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if (a.Type == "SOA") != (b.Type == "SOA") {
+			return a.Type == "SOA"
+		}
+		if a.Name != b.Name {
+			return nameLess(origin, a.Name, b.Name)
+		}
+		return typeRank(a.Type) < typeRank(b.Type)
+	})
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "$ORIGIN %s\n", origin)
+	fmt.Fprintf(bw, "$TTL %d\n", ttl)
+
+	for _, r := range sorted {
+		class := r.Class
+		if class == "" {
+			class = "IN"
+		}
+		owner := relative(origin, r.Name)
+
+		if r.Type == "SOA" && r.SOA != nil {
+			fmt.Fprintf(bw, "%s\t%d\t%s\tSOA\t%s %s (\n", owner, r.TTL, class, r.SOA.MName, r.SOA.RName)
+			fmt.Fprintf(bw, "\t\t\t\t%d\t; serial\n", r.SOA.Serial)
+			fmt.Fprintf(bw, "\t\t\t\t%d\t; refresh\n", r.SOA.Refresh)
+			fmt.Fprintf(bw, "\t\t\t\t%d\t; retry\n", r.SOA.Retry)
+			fmt.Fprintf(bw, "\t\t\t\t%d\t; expire\n", r.SOA.Expire)
+			fmt.Fprintf(bw, "\t\t\t\t%d )\t; minimum\n", r.SOA.Minimum)
+			continue
+		}
+
+		fmt.Fprintf(bw, "%s\t%d\t%s\t%s\t%s\n", owner, r.TTL, class, r.Type, r.RData())
+	}
+	return bw.Flush()
+}
+
+// relative 将区域内的绝对名称转换为相对名称，区域顶点输出 "@"
+func relative(origin, name string) string {
+	name = Fqdn(name)
+	if name == origin {
+		return "@"
+	}
+	if strings.HasSuffix(name, "."+origin) {
+		return strings.TrimSuffix(name, "."+origin)
+	}
+	return name
+}
+
+// nameLess 区域顶点排在最前，其余按名称排序
+func nameLess(origin, a, b string) bool {
+	ra, rb := relative(origin, a), relative(origin, b)
+	if ra == "@" || rb == "@" {
+		return ra == "@" && rb != "@"
+	}
+	return ra < rb
+}
+
+func typeRank(t string) int {
+	if rank, ok := typeOrder[t]; ok {
+		return rank
+	}
+	return len(typeOrder)
+}
@@ -0,0 +1,228 @@
+package zonefile
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+const exampleZone = `$ORIGIN example.com.
+$TTL 1h
+; 区域顶点
+@	IN	SOA	ns1 hostmaster (
+		2024010101 ; serial
+		2h         ; refresh
+		15m        ; retry
+		2w         ; expire
+		300 )      ; minimum
+	IN	NS	ns1
+	IN	NS	ns2.example.net.
+	IN	MX	10 mail
+	IN	TXT	"v=spf1 include:_spf.example.net -all"
+	IN	CAA	0 issue "letsencrypt.org"
+ns1	IN	A	192.0.2.53
+www	600	IN	A	192.0.2.1
+WWW	IN	AAAA	2001:db8::1
+api	IN	CNAME	www
+_sip._tcp	86400	IN	SRV	10 60 5060 sip
+quoted	IN	TXT	"say \"hi\"" "back\\slash" "caf\195\169" "semi;colon"
+bare	IN	TXT	hello\ world
+host	IN	HINFO	"Intel x86" Linux
+$ORIGIN sub.example.com.
+deep	IN	A	198.51.100.7
+`
+
+func mustParse(t *testing.T, input string, opts Options) []Record {
+	t.Helper()
+	records, err := Parse(strings.NewReader(input), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return records
+}
+
+func mustRender(t *testing.T, origin string, ttl uint32, records []Record) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Render(&buf, origin, ttl, records); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+// byName 按名称、类型和 RDATA 索引记录，便于比较渲染前后的记录集合
+func byName(records []Record) map[string]Record {
+	m := make(map[string]Record, len(records))
+	for _, r := range records {
+		m[r.Name+" "+r.Type+" "+r.RData()] = r
+	}
+	return m
+}
+
+func TestParse(t *testing.T) {
+	records := byName(mustParse(t, exampleZone, Options{}))
+	if len(records) != 15 {
+		t.Fatalf("parsed %d records", len(records))
+	}
+
+	soa, ok := records["example.com. SOA ns1.example.com. hostmaster.example.com. 2024010101 7200 900 1209600 300"]
+	if !ok || soa.TTL != 3600 || soa.Class != "IN" {
+		t.Fatalf("soa = %+v", soa)
+	}
+	checks := map[string]Record{
+		"example.com. NS ns2.example.net.":        {Name: "example.com.", TTL: 3600, Class: "IN", Type: "NS", Value: "ns2.example.net."},
+		"example.com. MX 10 mail.example.com.":    {Name: "example.com.", TTL: 3600, Class: "IN", Type: "MX", Value: "mail.example.com.", Priority: 10},
+		"www.example.com. A 192.0.2.1":            {Name: "www.example.com.", TTL: 600, Class: "IN", Type: "A", Value: "192.0.2.1"},
+		"www.example.com. AAAA 2001:db8::1":       {Name: "www.example.com.", TTL: 3600, Class: "IN", Type: "AAAA", Value: "2001:db8::1"},
+		"api.example.com. CNAME www.example.com.": {Name: "api.example.com.", TTL: 3600, Class: "IN", Type: "CNAME", Value: "www.example.com."},
+		"_sip._tcp.example.com. SRV 10 60 5060 sip.example.com.": {
+			Name: "_sip._tcp.example.com.", TTL: 86400, Class: "IN", Type: "SRV", Value: "sip.example.com.", Priority: 10, Weight: 60, Port: 5060,
+		},
+		`example.com. CAA 0 issue "letsencrypt.org"`: {Name: "example.com.", TTL: 3600, Class: "IN", Type: "CAA", Value: "letsencrypt.org", Tag: "issue"},
+		`host.example.com. HINFO "Intel x86" Linux`:  {Name: "host.example.com.", TTL: 3600, Class: "IN", Type: "HINFO", Value: `"Intel x86" Linux`},
+		"deep.sub.example.com. A 198.51.100.7":       {Name: "deep.sub.example.com.", TTL: 3600, Class: "IN", Type: "A", Value: "198.51.100.7"},
+		`bare.example.com. TXT "hello world"`:        {Name: "bare.example.com.", TTL: 3600, Class: "IN", Type: "TXT", Texts: []string{"hello world"}},
+		`quoted.example.com. TXT "say \"hi\"" "back\\slash" "caf\195\169" "semi;colon"`: {
+			Name: "quoted.example.com.", TTL: 3600, Class: "IN", Type: "TXT", Texts: []string{`say "hi"`, `back\slash`, "café", "semi;colon"},
+		},
+	}
+	for key, want := range checks {
+		if got, ok := records[key]; !ok || !reflect.DeepEqual(got, want) {
+			t.Errorf("%s = %+v, want %+v", key, got, want)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	records := mustParse(t, exampleZone, Options{})
+	rendered := mustRender(t, "example.com", 3600, records)
+
+	if !strings.HasPrefix(rendered, "$ORIGIN example.com.\n$TTL 3600\n@\t3600\tIN\tSOA\tns1.example.com. hostmaster.example.com. (\n") {
+		t.Fatalf("rendered header:\n%s", rendered)
+	}
+	for _, line := range []string{
+		"www\t600\tIN\tA\t192.0.2.1\n",
+		"_sip._tcp\t86400\tIN\tSRV\t10 60 5060 sip.example.com.\n",
+		"deep.sub\t3600\tIN\tA\t198.51.100.7\n",
+		"@\t3600\tIN\tNS\tns2.example.net.\n",
+	} {
+		if !strings.Contains(rendered, line) {
+			t.Errorf("rendered zone missing %q:\n%s", line, rendered)
+		}
+	}
+
+	reparsed := mustParse(t, rendered, Options{})
+	if !reflect.DeepEqual(byName(reparsed), byName(records)) {
+		t.Fatalf("round trip changed records:\n%s", rendered)
+	}
+	if again := mustRender(t, "example.com", 3600, reparsed); again != rendered {
+		t.Fatalf("render is not stable:\n%s\n---\n%s", rendered, again)
+	}
+}
+
+func TestParseDefaults(t *testing.T) {
+	// 没有 $TTL 时使用上一条记录的TTL，再没有时使用 SOA 的 minimum
+	records := mustParse(t, `@ SOA ns1 hostmaster 1 7200 900 1209600 120
+www A 192.0.2.1
+ftp 60 A 192.0.2.2
+mail A 192.0.2.3
+`, Options{Origin: "Example.COM"})
+	ttls := []uint32{120, 120, 60, 60}
+	for i, r := range records {
+		if r.TTL != ttls[i] {
+			t.Errorf("%s TTL = %d, want %d", r.Name, r.TTL, ttls[i])
+		}
+	}
+	if records[0].Name != "example.com." {
+		t.Errorf("origin not normalized: %s", records[0].Name)
+	}
+
+	records = mustParse(t, "www.example.com. A 192.0.2.1\n", Options{DefaultTTL: 900})
+	if records[0].TTL != 900 {
+		t.Errorf("TTL = %d, want DefaultTTL", records[0].TTL)
+	}
+	records = mustParse(t, "www.example.com. IN 300 A 192.0.2.1\n", Options{})
+	if records[0].TTL != 300 || records[0].Class != "IN" {
+		t.Errorf("class before TTL: %+v", records[0])
+	}
+}
+
+func TestInclude(t *testing.T) {
+	files := fstest.MapFS{
+		"hosts.zone":  {Data: []byte("www A 192.0.2.1\n$ORIGIN other.com.\nftp A 192.0.2.2\n")},
+		"nested.zone": {Data: []byte("$INCLUDE nested.zone\n")},
+	}
+	opts := Options{Origin: "example.com", Include: func(path string) (io.ReadCloser, error) { return files.Open(path) }}
+
+	records := mustParse(t, "$INCLUDE hosts.zone\nmail A 192.0.2.3\n$INCLUDE hosts.zone lab.example.com.\n", opts)
+	var names []string
+	for _, r := range records {
+		names = append(names, r.Name)
+	}
+	// 被引用文件中的 $ORIGIN 不影响当前文件
+	want := "www.example.com. ftp.other.com. mail.example.com. www.lab.example.com. ftp.other.com."
+	if got := strings.Join(names, " "); got != want {
+		t.Fatalf("names = %s, want %s", got, want)
+	}
+
+	if _, err := Parse(strings.NewReader("$INCLUDE nested.zone\n"), opts); err == nil || !strings.Contains(err.Error(), "nested too deeply") {
+		t.Errorf("recursive include: err = %v", err)
+	}
+	if _, err := Parse(strings.NewReader("$INCLUDE missing.zone\n"), opts); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("missing include: err = %v", err)
+	}
+	if _, err := Parse(strings.NewReader("$INCLUDE hosts.zone\n"), Options{Origin: "example.com"}); !errors.Is(err, ErrIncludeNotAllowed) {
+		t.Errorf("include without opener: err = %v", err)
+	}
+}
+
+func TestParseMalformed(t *testing.T) {
+	cases := []struct {
+		name, input, err string
+	}{
+		{"unbalanced open", "@ SOA ns1 hostmaster ( 1 2 3 4 5\n", "unbalanced '('"},
+		{"unbalanced close", "www A 192.0.2.1 )\n", "line 1: unbalanced ')'"},
+		{"unterminated quote", "www TXT \"abc\n", "unterminated quoted string"},
+		{"bad escape", "www TXT \"\\999\"\n", `invalid escape \999`},
+		{"at without origin", "@ A 192.0.2.1\n", "'@' used without $ORIGIN"},
+		{"relative without origin", "www A 192.0.2.1\n", `relative name "www" used without $ORIGIN`},
+		{"leading blank owner", "\tA 192.0.2.1\n", "record without owner name"},
+		{"missing type", "$ORIGIN example.com.\nwww 300 IN\n", "line 2: missing record type"},
+		{"bad ttl directive", "$TTL 1x\n", `invalid TTL "1x"`},
+		{"ttl out of range", "$TTL 9999999999\n", "invalid TTL"},
+		{"origin without argument", "$ORIGIN\n", "$ORIGIN requires one argument"},
+		{"unknown directive", "$GENERATE 1-10 host$ A 192.0.2.$\n", "unsupported directive $GENERATE"},
+		{"bad mx preference", "$ORIGIN example.com.\n@ MX ten mail\n", `invalid preference "ten"`},
+		{"srv field count", "$ORIGIN example.com.\n_sip._tcp SRV 10 60 sip\n", "SRV record requires 4 fields, got 3"},
+		{"bad soa serial", "$ORIGIN example.com.\n@ SOA ns1 hostmaster serial 1 2 3 4\n", `invalid SOA serial "serial"`},
+		{"bad soa timer", "$ORIGIN example.com.\n@ SOA ns1 hostmaster 1 2 3 4 soon\n", `invalid SOA timer "soon"`},
+		{"empty txt", "$ORIGIN example.com.\nwww TXT\n", "TXT record requires at least one string"},
+		{"bad caa flags", "$ORIGIN example.com.\n@ CAA 256 issue \"ca\"\n", `invalid CAA flags "256"`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tc.input), Options{})
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Fatalf("err = %v, want %q", err, tc.err)
+			}
+		})
+	}
+}
+
+func TestParseTTL(t *testing.T) {
+	for s, want := range map[string]uint32{"0": 0, "300": 300, "30s": 30, "5m": 300, "1h30m": 5400, "2D": 172800, "1w1d": 691200} {
+		if got, err := ParseTTL(s); err != nil || got != want {
+			t.Errorf("ParseTTL(%q) = %d, %v, want %d", s, got, err, want)
+		}
+	}
+	for _, s := range []string{"", "h", "1y", "1h2", "-1", "7000000w"} {
+		if _, err := ParseTTL(s); err == nil {
+			t.Errorf("ParseTTL(%q) should fail", s)
+		}
+	}
+}